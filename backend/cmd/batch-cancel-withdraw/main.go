@@ -10,6 +10,7 @@ import (
 
 	"go-backend/internal/config"
 	"go-backend/internal/db"
+	"go-backend/internal/logging"
 	"go-backend/internal/models"
 	"go-backend/internal/repository"
 	"go-backend/internal/services"
@@ -21,6 +22,7 @@ func main() {
 		payoutStatus  = flag.String("payout-status", "", "Filter by payout_status (e.g., pending)")
 		proofStatus   = flag.String("proof-status", "", "Filter by proof_status (e.g., failed)")
 		requestIDs    = flag.String("ids", "", "Comma-separated list of request IDs to cancel")
+		pageSize      = flag.Int("page-size", 500, "Number of requests to load per query page")
 		dryRun        = flag.Bool("dry-run", false, "Only show what would be cancelled, don't actually cancel")
 		configPath    = flag.String("config", "config.yaml", "Path to config file")
 	)
@@ -47,81 +49,60 @@ func main() {
 	withdrawService := services.NewWithdrawRequestService(
 		withdrawRepo,
 		allocationRepo,
-		nil, // zkvmClient not needed for cancellation
-		nil, // blockchainService not needed for cancellation
+		nil, // checkbookRepo not needed for cancellation
+		nil, // queueRootRepo not needed for cancellation
+		repository.NewTransactor(database),
+		logging.New(config.GetLogFormat()),
 	)
 
-	var requestsToCancel []*models.WithdrawRequest
-
-	// If specific IDs provided, use those
+	// Query by status filters (all given statuses must match) unless specific IDs are given
+	var filter repository.StatusFilter
+	var ids []string
 	if *requestIDs != "" {
-		ids := strings.Split(*requestIDs, ",")
-		for _, id := range ids {
-			id = strings.TrimSpace(id)
-			if id == "" {
-				continue
+		for _, id := range strings.Split(*requestIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
 			}
-			request, err := withdrawRepo.GetByID(ctx, id)
-			if err != nil {
-				log.Printf("⚠️  Failed to get request %s: %v", id, err)
-				continue
-			}
-			requestsToCancel = append(requestsToCancel, request)
 		}
 	} else {
-		// Query by status filters
-		var allRequests []*models.WithdrawRequest
-
 		if *executeStatus != "" {
-			requests, err := withdrawRepo.FindByExecuteStatus(ctx, models.ExecuteStatus(*executeStatus))
-			if err != nil {
-				log.Fatalf("Failed to query by execute_status: %v", err)
-			}
-			allRequests = requests
-		} else if *payoutStatus != "" {
-			requests, err := withdrawRepo.FindByPayoutStatus(ctx, models.PayoutStatus(*payoutStatus))
-			if err != nil {
-				log.Fatalf("Failed to query by payout_status: %v", err)
-			}
-			allRequests = requests
-		} else if *proofStatus != "" {
-			requests, err := withdrawRepo.FindByProofStatus(ctx, models.ProofStatus(*proofStatus))
-			if err != nil {
-				log.Fatalf("Failed to query by proof_status: %v", err)
-			}
-			allRequests = requests
-		} else {
+			status := models.ExecuteStatus(*executeStatus)
+			filter.ExecuteStatus = &status
+		}
+		if *payoutStatus != "" {
+			status := models.PayoutStatus(*payoutStatus)
+			filter.PayoutStatus = &status
+		}
+		if *proofStatus != "" {
+			status := models.ProofStatus(*proofStatus)
+			filter.ProofStatus = &status
+		}
+		if filter.ExecuteStatus == nil && filter.PayoutStatus == nil && filter.ProofStatus == nil {
 			log.Fatal("Please specify either -ids, -execute-status, -payout-status, or -proof-status")
 		}
-
-		// Apply additional filters
-		for _, req := range allRequests {
-			include := true
-
-			if *executeStatus != "" && string(req.ExecuteStatus) != *executeStatus {
-				include = false
-			}
-			if *payoutStatus != "" && string(req.PayoutStatus) != *payoutStatus {
-				include = false
-			}
-			if *proofStatus != "" && string(req.ProofStatus) != *proofStatus {
-				include = false
-			}
-
-			// Only include requests that can be cancelled
-			if include && req.CanCancel() {
-				requestsToCancel = append(requestsToCancel, req)
-			}
+		if *pageSize <= 0 {
+			log.Fatal("-page-size must be positive")
 		}
 	}
 
-	if len(requestsToCancel) == 0 {
-		log.Println("No requests found to cancel")
-		return
+	if *dryRun {
+		log.Println("🔍 DRY RUN MODE - requests are only listed, not cancelled")
+	} else {
+		// Requests are cancelled as their page is read, so confirm before reading any
+		fmt.Print("\n⚠️  Are you sure you want to cancel every cancellable request matching the selection? (yes/no): ")
+		var confirmation string
+		fmt.Scanln(&confirmation)
+		if confirmation != "yes" {
+			log.Println("Cancelled by user")
+			return
+		}
 	}
 
-	log.Printf("Found %d requests to cancel:\n", len(requestsToCancel))
-	for _, req := range requestsToCancel {
+	foundCount := 0
+	successCount := 0
+	failCount := 0
+	cancel := func(req *models.WithdrawRequest) {
+		foundCount++
 		log.Printf("  - ID: %s, Owner: %s, Status: %s, ExecuteStatus: %s, PayoutStatus: %s, ProofStatus: %s",
 			req.ID,
 			req.OwnerAddress.Data,
@@ -130,29 +111,12 @@ func main() {
 			req.PayoutStatus,
 			req.ProofStatus,
 		)
-	}
-
-	if *dryRun {
-		log.Println("\n🔍 DRY RUN MODE - No requests were actually cancelled")
-		return
-	}
-
-	// Confirm before proceeding
-	fmt.Print("\n⚠️  Are you sure you want to cancel these requests? (yes/no): ")
-	var confirmation string
-	fmt.Scanln(&confirmation)
-	if confirmation != "yes" {
-		log.Println("Cancelled by user")
-		return
-	}
+		if *dryRun {
+			return
+		}
 
-	// Cancel each request
-	successCount := 0
-	failCount := 0
-	for _, req := range requestsToCancel {
-		log.Printf("\n🔄 Cancelling request %s...", req.ID)
-		err := withdrawService.CancelWithdrawRequest(ctx, req.ID)
-		if err != nil {
+		log.Printf("🔄 Cancelling request %s...", req.ID)
+		if err := withdrawService.CancelWithdrawRequest(ctx, req.ID); err != nil {
 			log.Printf("❌ Failed to cancel request %s: %v", req.ID, err)
 			failCount++
 		} else {
@@ -163,9 +127,47 @@ func main() {
 		time.Sleep(100 * time.Millisecond)
 	}
 
+	if len(ids) > 0 {
+		for _, id := range ids {
+			request, err := withdrawRepo.GetByID(ctx, id)
+			if err != nil {
+				log.Printf("⚠️  Failed to get request %s: %v", id, err)
+				continue
+			}
+			cancel(request)
+		}
+	} else {
+		// Keyset pages (id > last ID seen): cancelling a request cannot shift the pages still to be read
+		lastID := ""
+		for {
+			requests, err := withdrawRepo.FindByStatusesAfter(ctx, filter, lastID, *pageSize)
+			if err != nil {
+				log.Fatalf("Failed to query requests after %q: %v", lastID, err)
+			}
+			for _, req := range requests {
+				lastID = req.ID
+				// Only include requests that can be cancelled
+				if req.CanCancel() {
+					cancel(req)
+				}
+			}
+			if len(requests) < *pageSize {
+				break
+			}
+		}
+	}
+
+	if foundCount == 0 {
+		log.Println("No requests found to cancel")
+		return
+	}
+	if *dryRun {
+		log.Printf("\n🔍 DRY RUN MODE - %d requests would be cancelled", foundCount)
+		return
+	}
+
 	log.Printf("\n📊 Summary:")
 	log.Printf("  ✅ Successfully cancelled: %d", successCount)
 	log.Printf("  ❌ Failed: %d", failCount)
-	log.Printf("  📝 Total processed: %d", len(requestsToCancel))
+	log.Printf("  📝 Total processed: %d", foundCount)
 }
-
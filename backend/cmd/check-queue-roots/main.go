@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+// Checks the previous_root links of stored queue roots.
+// A missed CommitmentRootUpdated event leaves a root whose previous_root is not stored; commitments
+// after that gap cannot be positioned and the chain no longer matches the contract queue.
+// Exits with status 1 when any chain has problems.
+
+func main() {
+	var chainID int64
+
+	flag.Int64Var(&chainID, "chain-id", 0, "Chain ID to check (default: all chains with queue roots)")
+	flag.Parse()
+
+	fmt.Println("🔗 Queue Root Integrity Check")
+	fmt.Println(strings.Repeat("=", 60))
+	if chainID > 0 {
+		fmt.Printf("Chain ID: %d\n", chainID)
+	} else {
+		fmt.Printf("Chain ID: all\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	chainIDs := []int64{chainID}
+	if chainID <= 0 {
+		chainIDs = nil
+		if err := db.DB.Model(&models.QueueRoot{}).Distinct().Order("chain_id ASC").Pluck("chain_id", &chainIDs).Error; err != nil {
+			log.Fatalf("❌ Failed to load chain IDs: %v", err)
+		}
+	}
+	if len(chainIDs) == 0 {
+		fmt.Println("ℹ️  No queue roots stored")
+		return
+	}
+
+	repo := repository.NewQueueRootRepository(db.DB)
+	broken := 0
+	for _, id := range chainIDs {
+		report, err := repo.ValidateChain(context.Background(), id)
+		if err != nil {
+			log.Fatalf("❌ Failed to validate chain %d: %v", id, err)
+		}
+		printReport(report)
+		if !report.OK() {
+			broken++
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	if broken > 0 {
+		fmt.Printf("❌ %d / %d chain(s) have broken queue root chains\n", broken, len(chainIDs))
+		fmt.Println("   Backfill missing roots from CommitmentRootUpdated events (reprocess-events), then run again")
+		os.Exit(1)
+	}
+	fmt.Printf("✅ All %d chain(s) OK\n", len(chainIDs))
+}
+
+// printReport prints the findings for one chain
+func printReport(report *repository.QueueRootChainReport) {
+	fmt.Printf("⛓️  Chain %d: %d root(s)\n", report.ChainID, report.Total)
+	if report.OK() {
+		fmt.Println("   ✅ Chain is intact")
+		fmt.Println()
+		return
+	}
+
+	if len(report.Genesis) == 0 && report.Total > 0 {
+		fmt.Println("   ⚠️  No genesis root (previous_root = 0x0) stored")
+	}
+	if len(report.Genesis) > 1 {
+		fmt.Printf("   ⚠️  %d genesis roots (expected 1):\n", len(report.Genesis))
+		for _, root := range report.Genesis {
+			fmt.Printf("     - %s\n", root)
+		}
+	}
+	if len(report.Gaps) > 0 {
+		fmt.Printf("   ⚠️  %d gap(s) (previous_root not stored):\n", len(report.Gaps))
+		for _, gap := range report.Gaps {
+			fmt.Printf("     - blocks %d..%d: missing %s (before %s)\n", gap.PreviousBlock, gap.NextBlock, gap.MissingRoot, gap.NextRoot)
+		}
+	}
+	if len(report.Duplicates) > 0 {
+		fmt.Printf("   ⚠️  %d duplicate root(s):\n", len(report.Duplicates))
+		for _, root := range report.Duplicates {
+			fmt.Printf("     - %s\n", root)
+		}
+	}
+	if len(report.Forks) > 0 {
+		fmt.Printf("   ⚠️  %d root(s) with more than one successor:\n", len(report.Forks))
+		for _, root := range report.Forks {
+			fmt.Printf("     - %s\n", root)
+		}
+	}
+	if len(report.Cycles) > 0 {
+		fmt.Printf("   ⚠️  %d cycle(s):\n", len(report.Cycles))
+		for _, cycle := range report.Cycles {
+			fmt.Printf("     - %s\n", strings.Join(cycle, " -> "))
+		}
+	}
+	if len(report.Orphans) > 0 {
+		fmt.Printf("   ⚠️  %d orphan root(s) not reachable from genesis:\n", len(report.Orphans))
+		for _, root := range report.Orphans {
+			fmt.Printf("     - %s\n", root)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// Exports withdraw requests of a period to CSV for accounting.
+// Requests are selected by completion time (payout_completed_at), or creation time for requests that never
+// completed, and written in that order. Rows are streamed from the database, so any range can be exported.
+// Amounts are converted from management units (18 decimals) to the token's native decimals on the target chain.
+
+// dateLayout layout of the -from / -to flags (UTC days)
+const dateLayout = "2006-01-02"
+
+// csvHeader columns of the export
+var csvHeader = []string{"id", "owner", "recipient", "token", "amount", "status", "payout_tx_hash", "payout_chain_id", "completed_at"}
+
+// defaultStatuses statuses exported when -status is not set: every request whose payout completed
+var defaultStatuses = []string{string(models.WithdrawStatusCompleted), string(models.WithdrawStatusCompletedWithHookFailed)}
+
+func main() {
+	var from, to, status, out string
+
+	flag.StringVar(&from, "from", "", "First day to export, YYYY-MM-DD UTC (required)")
+	flag.StringVar(&to, "to", "", "Last day to export, inclusive, YYYY-MM-DD UTC (required)")
+	flag.StringVar(&status, "status", strings.Join(defaultStatuses, ","), "Comma-separated main statuses to export")
+	flag.StringVar(&out, "out", "", "Output CSV path (default: stdout)")
+	flag.Parse()
+
+	start, end, err := parseDateRange(from, to)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	statuses := splitStatuses(status)
+	if len(statuses) == 0 {
+		log.Fatalf("❌ -status must list at least one status")
+	}
+
+	// The CSV may go to stdout, so progress is logged to stderr
+	log.Println("📤 Withdraw Export")
+	log.Println(strings.Repeat("=", 60))
+	log.Printf("Period: %s to %s (UTC)\n", start.Format(dateLayout), end.AddDate(0, 0, -1).Format(dateLayout))
+	log.Printf("Status: %s\n", strings.Join(statuses, ", "))
+	if out != "" {
+		log.Printf("Output: %s\n", out)
+	} else {
+		log.Printf("Output: stdout\n")
+	}
+	log.Println(strings.Repeat("=", 60))
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var writer io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			log.Fatalf("❌ Failed to create %s: %v", out, err)
+		}
+		defer file.Close()
+		writer = file
+	}
+	buffered := bufio.NewWriter(writer)
+
+	converter := utils.NewDecimalConverter()
+	if len(config.AppConfig.Tokens.ChainDecimals) > 0 {
+		converter = utils.NewDecimalConverterWithConfig(config.AppConfig.Tokens.ChainDecimals)
+	}
+
+	count, err := exportWithdraws(db.DB, buffered, converter, start, end, statuses)
+	if err != nil {
+		log.Fatalf("❌ Export failed after %d rows: %v", count, err)
+	}
+	if err := buffered.Flush(); err != nil {
+		log.Fatalf("❌ Failed to write CSV: %v", err)
+	}
+
+	log.Println(strings.Repeat("=", 60))
+	log.Printf("✅ Exported %d withdraw request(s)\n", count)
+}
+
+// parseDateRange parses the inclusive -from / -to days into a [start, end) UTC range
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	if from == "" || to == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("-from and -to are required (YYYY-MM-DD)")
+	}
+	start, err := time.ParseInLocation(dateLayout, from, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid -from date %q: %w", from, err)
+	}
+	last, err := time.ParseInLocation(dateLayout, to, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid -to date %q: %w", to, err)
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("-to %s is before -from %s", to, from)
+	}
+	return start, last.AddDate(0, 0, 1), nil
+}
+
+// splitStatuses parses the comma-separated -status flag
+func splitStatuses(value string) []string {
+	var statuses []string
+	for _, status := range strings.Split(value, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// exportWithdraws streams the matching requests as CSV rows and returns how many were written
+func exportWithdraws(database *gorm.DB, w io.Writer, converter *utils.DecimalConverter, start, end time.Time, statuses []string) (int, error) {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return 0, err
+	}
+
+	rows, err := database.Model(&models.WithdrawRequest{}).
+		Where("status IN ?", statuses).
+		Where("COALESCE(payout_completed_at, created_at) >= ? AND COALESCE(payout_completed_at, created_at) < ?", start, end).
+		Order("COALESCE(payout_completed_at, created_at) ASC, id ASC").
+		Rows()
+	if err != nil {
+		return 0, fmt.Errorf("query withdraw requests failed: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var request models.WithdrawRequest
+		if err := database.ScanRows(rows, &request); err != nil {
+			return count, fmt.Errorf("scan withdraw request failed: %w", err)
+		}
+		if err := csvWriter.Write(withdrawRow(converter, &request)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("read withdraw requests failed: %w", err)
+	}
+
+	csvWriter.Flush()
+	return count, csvWriter.Error()
+}
+
+// withdrawRow one CSV row, columns as csvHeader
+func withdrawRow(converter *utils.DecimalConverter, request *models.WithdrawRequest) []string {
+	token := request.TokenIdentifier
+	if request.IntentType == models.IntentTypeAssetToken {
+		token = request.AssetID
+	}
+
+	payoutChainID := ""
+	if request.PayoutChainID != nil {
+		payoutChainID = strconv.FormatUint(uint64(*request.PayoutChainID), 10)
+	}
+	completedAt := ""
+	if request.PayoutCompletedAt != nil {
+		completedAt = request.PayoutCompletedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		request.ID,
+		formatUniversalAddress(request.OwnerAddress),
+		formatUniversalAddress(request.Recipient),
+		token,
+		nativeAmount(converter, request),
+		string(request.Status),
+		request.PayoutTxHash,
+		payoutChainID,
+		completedAt,
+	}
+}
+
+// nativeAmount the request amount in the token's native decimals on the target chain
+// AssetToken requests use the token ID encoded in AssetID, RawToken requests token ID 0. Falls back to the
+// management amount when the conversion fails, so the row is still exported.
+func nativeAmount(converter *utils.DecimalConverter, request *models.WithdrawRequest) string {
+	if request.Amount == "" {
+		return ""
+	}
+	tokenID := uint16(0)
+	if request.IntentType == models.IntentTypeAssetToken && request.AssetID != "" {
+		if id, err := utils.GetTokenIDFromAssetID(request.AssetID); err == nil {
+			tokenID = id
+		}
+	}
+	amount, err := converter.ConvertFromManagementAmount(request.Amount, int64(request.TargetSLIP44ChainID), int(tokenID))
+	if err != nil {
+		log.Printf("⚠️ Request %s: amount conversion failed (chain=%d, token=%d), exporting management amount %s: %v",
+			request.ID, request.TargetSLIP44ChainID, tokenID, request.Amount, err)
+		return request.Amount
+	}
+	return amount
+}
+
+// formatUniversalAddress "slip44ChainId:data", empty when unset
+func formatUniversalAddress(address models.UniversalAddress) string {
+	if address.Data == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d:%s", address.SLIP44ChainID, address.Data)
+}
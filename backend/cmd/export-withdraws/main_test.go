@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+)
+
+func TestExportWithdrawsWritesPeriodInCompletionOrder(t *testing.T) {
+	database := dbtest.Open(t)
+
+	at := func(day, hour int) *time.Time {
+		completed := time.Date(2026, 1, day, hour, 0, 0, 0, time.UTC)
+		return &completed
+	}
+	bsc := uint32(714)
+	requests := []models.WithdrawRequest{
+		// Same completion time as wr-b: ordered by ID
+		{ID: "wr-c", Status: string(models.WithdrawStatusCompleted), PayoutCompletedAt: at(2, 10), Amount: "2500000000000000000"},
+		{ID: "wr-b", Status: string(models.WithdrawStatusCompleted), PayoutCompletedAt: at(2, 10), Amount: "1000000000000000000",
+			PayoutTxHash: "0xpayout-b", PayoutChainID: &bsc},
+		{ID: "wr-a", Status: string(models.WithdrawStatusCompletedWithHookFailed), PayoutCompletedAt: at(1, 8), Amount: "1500000000000000000",
+			OwnerAddress: models.UniversalAddress{SLIP44ChainID: 60, Data: "0xowner"}, Recipient: models.UniversalAddress{SLIP44ChainID: 714, Data: "0xrecipient"},
+			TokenIdentifier: "0xusdt", PayoutTxHash: "0xpayout-a", PayoutChainID: &bsc},
+		// Completed after -to, and a request that never completed
+		{ID: "wr-late", Status: string(models.WithdrawStatusCompleted), PayoutCompletedAt: at(4, 0), Amount: "1"},
+		{ID: "wr-open", Status: string(models.WithdrawStatusCreated), Amount: "1"},
+	}
+	for i := range requests {
+		requests[i].WithdrawNullifier = "0xnullifier-" + requests[i].ID
+		requests[i].TargetSLIP44ChainID = 714
+		requests[i].ProofStatus = models.ProofStatusCompleted
+		requests[i].ExecuteStatus = models.ExecuteStatusSuccess
+		requests[i].PayoutStatus = models.PayoutStatusCompleted
+		requests[i].HookStatus = models.HookStatusNotRequired
+		requests[i].Version = 1
+		if err := database.Create(&requests[i]).Error; err != nil {
+			t.Fatalf("create %s: %v", requests[i].ID, err)
+		}
+	}
+
+	start, end, err := parseDateRange("2026-01-01", "2026-01-03")
+	if err != nil {
+		t.Fatalf("parseDateRange: %v", err)
+	}
+	var out bytes.Buffer
+	converter := utils.NewDecimalConverterWithConfig(map[int]map[int]int{714: {0: 6}})
+	count, err := exportWithdraws(database, &out, converter, start, end, defaultStatuses)
+	if err != nil {
+		t.Fatalf("exportWithdraws: %v", err)
+	}
+
+	want := "id,owner,recipient,token,amount,status,payout_tx_hash,payout_chain_id,completed_at\n" +
+		"wr-a,60:0xowner,714:0xrecipient,0xusdt,1500000,completed_with_hook_failed,0xpayout-a,714,2026-01-01T08:00:00Z\n" +
+		"wr-b,,,,1000000,completed,0xpayout-b,714,2026-01-02T10:00:00Z\n" +
+		"wr-c,,,,2500000,completed,,,2026-01-02T10:00:00Z\n"
+	if out.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", out.String(), want)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	start, end, err := parseDateRange("2026-03-31", "2026-03-31")
+	if err != nil {
+		t.Fatalf("parseDateRange: %v", err)
+	}
+	if want := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %v, want %v (the -to day is inclusive)", end, want)
+	}
+
+	for _, tt := range [][2]string{{"", "2026-01-01"}, {"2026-01-02", "2026-01-01"}, {"01/01/2026", "2026-01-02"}} {
+		if _, _, err := parseDateRange(tt[0], tt[1]); err == nil {
+			t.Errorf("parseDateRange(%q, %q) succeeded, want an error", tt[0], tt[1])
+		}
+	}
+}
@@ -2,50 +2,35 @@ package main
 
 import (
 	"fmt"
-	"time"
+	"log"
 
-	"github.com/golang-jwt/jwt/v5"
+	"go-backend/internal/config"
+	"go-backend/internal/handlers"
 )
 
-// JWTClaims represents JWT claims for zkpay
-type JWTClaims struct {
-	UserAddress      string `json:"user_address"`
-	UniversalAddress string `json:"universal_address"`
-	ChainID          int    `json:"chain_id"`
-	jwt.RegisteredClaims
-}
-
 func main() {
-	// Configuration from auth_handler.go
-	jwtSecret := []byte("zkpay-jwt-secret-key-2025")
+	// Same secret, issuer, audience and TTL as the backend (jwt section of the config, JWT_* env overrides)
+	if err := config.LoadConfig(""); err != nil {
+		log.Printf("⚠️ Failed to load config, using JWT defaults and JWT_* env only: %v", err)
+	}
+	jwtConfig := config.GetJWTConfig()
 
 	// Test user configuration
 	userAddress := "0x742d35Cc6634C0532925a3b0F26750C66d78EB66"
 	chainID := 714
 	universalAddress := fmt.Sprintf("%d:%s", chainID, userAddress)
 
-	// Create JWT claims
-	now := time.Now()
-	claims := JWTClaims{
-		UserAddress:      userAddress,
-		UniversalAddress: universalAddress,
-		ChainID:          chainID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "zkpay-backend",
-			Subject:   userAddress,
-		},
-	}
-
 	// Generate token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := handlers.SignJWTToken(jwtConfig, userAddress, universalAddress, chainID)
 	if err != nil {
 		fmt.Printf("Error generating token: %v\n", err)
 		return
 	}
+	claims, err := handlers.ValidateJWTTokenWithConfig(jwtConfig, tokenString)
+	if err != nil {
+		fmt.Printf("Error validating generated token: %v\n", err)
+		return
+	}
 
 	fmt.Println("============================================================")
 	fmt.Println("JWT Token Generated for Testing")
@@ -58,6 +43,10 @@ func main() {
 	fmt.Printf("  User Address: %s\n", userAddress)
 	fmt.Printf("  Chain ID: %d\n", chainID)
 	fmt.Printf("  Universal Address: %s\n", universalAddress)
+	fmt.Printf("  Issuer: %s\n", claims.Issuer)
+	if jwtConfig.Audience != "" {
+		fmt.Printf("  Audience: %s\n", jwtConfig.Audience)
+	}
 	fmt.Printf("  Expires: %s\n", claims.ExpiresAt.Time)
 	fmt.Println()
 	fmt.Println("============================================================")
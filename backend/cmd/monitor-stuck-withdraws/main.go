@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/logging"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/internal/services"
+)
+
+func main() {
+	var (
+		status     = flag.String("status", "", "Filter by main status (e.g., submitted). Empty = all non-terminal statuses")
+		olderThan  = flag.Duration("older-than", 30*time.Minute, "Report requests not updated for longer than this")
+		interval   = flag.Duration("interval", 0, "Check interval (e.g., 5m). 0 = run once and exit")
+		retrigger  = flag.Bool("retrigger", false, "Re-trigger ExecuteWithdraw for stuck requests that can be resubmitted")
+		configPath = flag.String("config", "config.yaml", "Path to config file")
+	)
+	flag.Parse()
+
+	fmt.Println("🩺 Stuck Withdraw Request Monitor")
+	fmt.Println(strings.Repeat("=", 60))
+	if *status != "" {
+		fmt.Printf("Status: %s\n", *status)
+	} else {
+		fmt.Printf("Status: ALL (non-terminal)\n")
+	}
+	fmt.Printf("Older Than: %s\n", *olderThan)
+	if *interval > 0 {
+		fmt.Printf("Interval: %s\n", *interval)
+	} else {
+		fmt.Printf("Interval: run once\n")
+	}
+	fmt.Printf("Re-trigger ExecuteWithdraw: %v\n", *retrigger)
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load configuration
+	if err := config.LoadConfig(*configPath); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+	database := db.DB
+
+	withdrawRepo := repository.NewWithdrawRequestRepository(database)
+
+	var withdrawService *services.WithdrawRequestService
+	if *retrigger {
+		withdrawService = services.NewWithdrawRequestService(
+			withdrawRepo,
+			repository.NewAllocationRepository(database),
+			repository.NewCheckbookRepository(database),
+			repository.NewQueueRootRepository(database),
+			repository.NewTransactor(database),
+			logging.New(config.GetLogFormat()),
+		)
+
+		// Blockchain service is required to resubmit executeWithdraw
+		keyMgmtService := services.NewKeyManagementService(config.AppConfig, database)
+		blockchainService := services.NewBlockchainTransactionService(keyMgmtService)
+		if err := blockchainService.InitializeClients(); err != nil {
+			log.Fatalf("❌ Failed to initialize blockchain clients: %v", err)
+		}
+		withdrawService.SetBlockchainService(blockchainService)
+	}
+
+	ctx := context.Background()
+	checkStuck(ctx, withdrawRepo, withdrawService, *status, *olderThan)
+	if *interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	for {
+		select {
+		case <-sigCh:
+			log.Println("🛑 Stuck withdraw monitor stopped")
+			return
+		case <-ticker.C:
+			checkStuck(ctx, withdrawRepo, withdrawService, *status, *olderThan)
+		}
+	}
+}
+
+// checkStuck logs stuck requests and optionally re-triggers ExecuteWithdraw
+func checkStuck(
+	ctx context.Context,
+	withdrawRepo repository.WithdrawRequestRepository,
+	withdrawService *services.WithdrawRequestService,
+	status string,
+	olderThan time.Duration,
+) {
+	requests, err := withdrawRepo.FindStuck(ctx, status, olderThan)
+	if err != nil {
+		log.Printf("❌ Failed to query stuck withdraw requests: %v", err)
+		return
+	}
+
+	if len(requests) == 0 {
+		log.Printf("✅ No stuck withdraw requests (older than %s)", olderThan)
+		return
+	}
+
+	log.Printf("⚠️ Found %d stuck withdraw request(s):", len(requests))
+	for _, req := range requests {
+		log.Printf("  - ID: %s, Status: %s, ProofStatus: %s, ExecuteStatus: %s, PayoutStatus: %s, UpdatedAt: %s (%s ago)",
+			req.ID,
+			req.Status,
+			req.ProofStatus,
+			req.ExecuteStatus,
+			req.PayoutStatus,
+			req.UpdatedAt.Format(time.RFC3339),
+			time.Since(req.UpdatedAt).Round(time.Second),
+		)
+	}
+
+	if withdrawService == nil {
+		return
+	}
+
+	for _, req := range requests {
+		if !canRetrigger(req) {
+			log.Printf("⏭️  Skipping %s: execute_status=%s, proof_status=%s (not resubmittable, needs manual review)",
+				req.ID, req.ExecuteStatus, req.ProofStatus)
+			continue
+		}
+
+		log.Printf("🔄 Re-triggering ExecuteWithdraw for %s...", req.ID)
+		if err := withdrawService.ExecuteWithdraw(ctx, req.ID); err != nil {
+			log.Printf("❌ Failed to re-trigger ExecuteWithdraw for %s: %v", req.ID, err)
+			continue
+		}
+		log.Printf("✅ ExecuteWithdraw re-triggered for %s", req.ID)
+	}
+}
+
+// canRetrigger only resubmits requests whose proof is ready and whose executeWithdraw TX
+// was never sent or failed to send. Submitted TXs are left alone to avoid double submission.
+func canRetrigger(req *models.WithdrawRequest) bool {
+	if req.ProofStatus != models.ProofStatusCompleted {
+		return false
+	}
+	return req.ExecuteStatus == models.ExecuteStatusPending || req.ExecuteStatus == models.ExecuteStatusSubmitFailed
+}
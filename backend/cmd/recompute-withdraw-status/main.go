@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// Recomputes every WithdrawRequest's main status from its sub-statuses (WithdrawRequest.UpdateMainStatus) and
+// stores it where it differs from the stored one. Run after the status computation rules change, so existing
+// rows follow the new rules. Only the status column is written (repository UpdateStatus, never Save), so proof
+// and public_values are left untouched.
+
+func main() {
+	var status string
+	var batchSize int
+	var dryRun bool
+
+	flag.StringVar(&status, "status", "", "Only recompute requests currently in this status (optional)")
+	flag.IntVar(&batchSize, "batch-size", 500, "Requests loaded per page")
+	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode (report differences without updating)")
+	flag.Parse()
+
+	if batchSize <= 0 {
+		log.Fatalf("❌ -batch-size must be positive")
+	}
+
+	fmt.Println("🧮 Withdraw Request Status Recompute")
+	fmt.Println(strings.Repeat("=", 60))
+	if status != "" {
+		fmt.Printf("Status: %s\n", status)
+	} else {
+		fmt.Printf("Status: ALL\n")
+	}
+	fmt.Printf("Batch Size: %d\n", batchSize)
+	if dryRun {
+		fmt.Printf("Mode: DRY RUN (no changes will be made)\n")
+	} else {
+		fmt.Printf("Mode: LIVE (stale statuses will be updated)\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	counts, err := recomputeStatuses(context.Background(), db.DB, status, batchSize, dryRun)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("📋 Checked: %d, stale: %d\n", counts.checked, counts.changed)
+	if dryRun {
+		fmt.Printf("🔍 DRY RUN: %d status(es) would be updated\n", counts.changed)
+		fmt.Println("   Run without --dry-run flag to actually update the database")
+		return
+	}
+	fmt.Printf("✅ Updated: %d / %d (failed: %d)\n", counts.updated, counts.changed, counts.failed)
+}
+
+// recomputeCounts totals of one recompute run
+type recomputeCounts struct {
+	checked, changed, updated, failed int
+}
+
+// recomputeStatuses recomputes the requests (only those in status when set) page by page, updating stale
+// statuses unless dryRun
+func recomputeStatuses(ctx context.Context, database *gorm.DB, status string, batchSize int, dryRun bool) (recomputeCounts, error) {
+	var counts recomputeCounts
+	withdrawRepo := repository.NewWithdrawRequestRepository(database)
+
+	lastID := ""
+	for {
+		// Keyset pagination by id: rows whose status is rewritten do not shift later pages
+		var page []models.WithdrawRequest
+		query := database.Model(&models.WithdrawRequest{}).Where("id > ?", lastID)
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if err := query.Order("id ASC").Limit(batchSize).Find(&page).Error; err != nil {
+			return counts, fmt.Errorf("failed to query withdraw requests after id %q: %w", lastID, err)
+		}
+		if len(page) == 0 {
+			return counts, nil
+		}
+		lastID = page[len(page)-1].ID
+
+		for _, request := range page {
+			counts.checked++
+			computed, stale := recompute(request)
+			if !stale {
+				continue
+			}
+			counts.changed++
+			fmt.Printf("  - %s: %s → %s [proof=%s execute=%s payout=%s hook=%s fallback_transferred=%v]\n",
+				request.ID, request.Status, computed, request.ProofStatus, request.ExecuteStatus,
+				request.PayoutStatus, request.HookStatus, request.FallbackTransferred)
+			if dryRun {
+				continue
+			}
+
+			if err := withdrawRepo.UpdateStatus(ctx, request.ID, computed); err != nil {
+				log.Printf("❌ Failed to update request %s: %v", request.ID, err)
+				counts.failed++
+				continue
+			}
+			counts.updated++
+		}
+	}
+}
+
+// recompute applies UpdateMainStatus to a copy of the request, stale=false when the stored status is current
+func recompute(request models.WithdrawRequest) (computed string, stale bool) {
+	copied := request
+	copied.UpdateMainStatus()
+	return copied.Status, copied.Status != request.Status
+}
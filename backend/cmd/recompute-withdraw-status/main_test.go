@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// createRequest stores a request with the given sub-statuses and a stored main status that may be stale
+func createRequest(t *testing.T, database *gorm.DB, id, status string, proof models.ProofStatus, execute models.ExecuteStatus, payout models.PayoutStatus) {
+	t.Helper()
+	request := models.WithdrawRequest{
+		ID:                id,
+		WithdrawNullifier: "0xnullifier-" + id,
+		Status:            status,
+		ProofStatus:       proof,
+		ExecuteStatus:     execute,
+		PayoutStatus:      payout,
+		HookStatus:        models.HookStatusNotRequired,
+		Proof:             "0xproof-" + id,
+		PublicValues:      "0xpublic-" + id,
+		Version:           1,
+	}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create %s: %v", id, err)
+	}
+}
+
+func loadRequest(t *testing.T, database *gorm.DB, id string) models.WithdrawRequest {
+	t.Helper()
+	var request models.WithdrawRequest
+	if err := database.First(&request, "id = ?", id).Error; err != nil {
+		t.Fatalf("load %s: %v", id, err)
+	}
+	return request
+}
+
+func TestRecomputeStatusesRepairsStaleRows(t *testing.T) {
+	database := dbtest.Open(t)
+	// wr-stale finished its payout but still says created; the others agree with their sub-statuses
+	createRequest(t, database, "wr-stale", string(models.WithdrawStatusCreated),
+		models.ProofStatusCompleted, models.ExecuteStatusSuccess, models.PayoutStatusCompleted)
+	createRequest(t, database, "wr-current", string(models.WithdrawStatusCreated),
+		models.ProofStatusPending, models.ExecuteStatusPending, models.PayoutStatusPending)
+	createRequest(t, database, "wr-done", string(models.WithdrawStatusCompleted),
+		models.ProofStatusCompleted, models.ExecuteStatusSuccess, models.PayoutStatusCompleted)
+
+	// Batches of one, so every row is on its own page
+	counts, err := recomputeStatuses(context.Background(), database, "", 1, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if counts != (recomputeCounts{checked: 3, changed: 1}) {
+		t.Errorf("dry run counts = %+v, want 3 checked, 1 changed, none updated", counts)
+	}
+	if got := loadRequest(t, database, "wr-stale").Status; got != string(models.WithdrawStatusCreated) {
+		t.Errorf("dry run updated wr-stale to %s", got)
+	}
+
+	counts, err = recomputeStatuses(context.Background(), database, "", 1, false)
+	if err != nil {
+		t.Fatalf("live run: %v", err)
+	}
+	if counts != (recomputeCounts{checked: 3, changed: 1, updated: 1}) {
+		t.Errorf("live run counts = %+v, want 3 checked, 1 changed, 1 updated", counts)
+	}
+	stale := loadRequest(t, database, "wr-stale")
+	if stale.Status != string(models.WithdrawStatusCompleted) {
+		t.Errorf("wr-stale status = %s, want completed", stale.Status)
+	}
+	if stale.Proof != "0xproof-wr-stale" || stale.PublicValues != "0xpublic-wr-stale" {
+		t.Errorf("wr-stale proof = %q, public_values = %q, want them untouched", stale.Proof, stale.PublicValues)
+	}
+	if got := loadRequest(t, database, "wr-current").Status; got != string(models.WithdrawStatusCreated) {
+		t.Errorf("wr-current status = %s, want created", got)
+	}
+
+	// A second run finds nothing left to repair
+	if counts, err := recomputeStatuses(context.Background(), database, "", 1, false); err != nil || counts.changed != 0 {
+		t.Errorf("rerun = %+v, %v, want nothing changed", counts, err)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Reconciles DepositInfo.Used against DepositUsed events.
+// A dropped NATS message leaves DepositInfo.Used=false although the deposit was used on-chain,
+// which would allow it to be committed again.
+//
+// Sources compared for one chain:
+//   - on-chain: DepositUsed events indexed by the BlockScanner API
+//   - local:    event_deposit_used rows
+//   - state:    deposit_infos.used
+
+// mismatch kinds
+const (
+	mismatchMissedOnChainEvent = "used on-chain, DepositInfo.Used=false"
+	mismatchLocalEventNotFlag  = "event_deposit_used row exists, DepositInfo.Used=false"
+	mismatchNotUsedOnChain     = "DepositInfo.Used=true, no DepositUsed event on-chain"
+)
+
+// mismatch one deposit whose Used flag disagrees with the events
+type mismatch struct {
+	localDepositID uint64
+	kind           string
+	fixable        bool // Used=false -> true; the reverse is only reported (scanner may lag)
+}
+
+func main() {
+	var chainID int64
+	var pageSize int
+	var dryRun bool
+
+	flag.Int64Var(&chainID, "chain-id", 0, "SLIP-44 chain ID to reconcile (required)")
+	flag.IntVar(&pageSize, "page-size", 500, "Number of events to fetch per BlockScanner API page")
+	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode (report mismatches without fixing them)")
+	flag.Parse()
+
+	if chainID <= 0 {
+		fmt.Println("❌ -chain-id is required")
+		os.Exit(1)
+	}
+	if pageSize <= 0 {
+		fmt.Println("❌ -page-size must be positive")
+		os.Exit(1)
+	}
+
+	fmt.Println("🔎 Deposit Reconciliation Script")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Chain ID: %d\n", chainID)
+	if dryRun {
+		fmt.Printf("Mode: DRY RUN (no changes will be made)\n")
+	} else {
+		fmt.Printf("Mode: LIVE (DepositInfo.Used will be set for used deposits)\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var deposits []models.DepositInfo
+	if err := db.DB.Where("slip44_chain_id = ?", chainID).Order("local_deposit_id ASC").Find(&deposits).Error; err != nil {
+		log.Fatalf("❌ Failed to load deposit infos: %v", err)
+	}
+	fmt.Printf("📦 DepositInfo rows: %d\n", len(deposits))
+
+	localUsed, err := loadLocalDepositUsed(db.DB, chainID)
+	if err != nil {
+		log.Fatalf("❌ Failed to load event_deposit_used rows: %v", err)
+	}
+	fmt.Printf("📝 Local DepositUsed events: %d\n", len(localUsed))
+
+	scanner := clients.NewBlockScannerAPIClient(config.GetScannerURL())
+	onChainUsed, err := loadOnChainDepositUsed(scanner, chainID, pageSize)
+	if err != nil {
+		log.Fatalf("❌ Failed to query DepositUsed events from BlockScanner: %v", err)
+	}
+	fmt.Printf("⛓️  On-chain DepositUsed events: %d\n", len(onChainUsed))
+	fmt.Println()
+
+	mismatches := findMismatches(deposits, localUsed, onChainUsed)
+	if len(mismatches) == 0 {
+		fmt.Println("✅ No mismatches found")
+		return
+	}
+
+	fixable := 0
+	fmt.Printf("⚠️  Found %d mismatch(es):\n", len(mismatches))
+	for _, m := range mismatches {
+		fmt.Printf("  - LocalDepositID=%d: %s\n", m.localDepositID, m.kind)
+		if m.fixable {
+			fixable++
+		}
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Printf("🔍 DRY RUN: %d deposit(s) would be marked as used\n", fixable)
+		fmt.Println("   Run without --dry-run flag to actually fix them")
+		return
+	}
+
+	fixed := 0
+	for _, m := range mismatches {
+		if !m.fixable {
+			continue
+		}
+		result := db.DB.Model(&models.DepositInfo{}).
+			Where("slip44_chain_id = ? AND local_deposit_id = ? AND used = ?", chainID, m.localDepositID, false).
+			Update("used", true)
+		if result.Error != nil {
+			log.Printf("❌ Failed to mark deposit %d as used: %v", m.localDepositID, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			fixed++
+			log.Printf("✅ Marked deposit %d as used", m.localDepositID)
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("✅ Fixed: %d / %d\n", fixed, fixable)
+	if fixed > 0 {
+		fmt.Println("   Checkbook statuses are not changed; replay with reprocess-events -type deposit-used if needed")
+	}
+	if fixable < len(mismatches) {
+		fmt.Printf("   %d mismatch(es) reported only, investigate manually\n", len(mismatches)-fixable)
+	}
+}
+
+// findMismatches compares each DepositInfo against the local and on-chain DepositUsed sets
+func findMismatches(deposits []models.DepositInfo, localUsed, onChainUsed map[uint64]bool) []mismatch {
+	var result []mismatch
+	known := make(map[uint64]bool, len(deposits))
+	for _, deposit := range deposits {
+		id := deposit.LocalDepositID
+		known[id] = true
+		switch {
+		case !deposit.Used && onChainUsed[id]:
+			result = append(result, mismatch{localDepositID: id, kind: mismatchMissedOnChainEvent, fixable: true})
+		case !deposit.Used && localUsed[id]:
+			result = append(result, mismatch{localDepositID: id, kind: mismatchLocalEventNotFlag, fixable: true})
+		case deposit.Used && !onChainUsed[id]:
+			result = append(result, mismatch{localDepositID: id, kind: mismatchNotUsedOnChain})
+		}
+	}
+
+	// Used on-chain but no DepositInfo at all (DepositReceived was missed too)
+	var unknown []uint64
+	for id := range onChainUsed {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+	for _, id := range unknown {
+		result = append(result, mismatch{localDepositID: id, kind: "used on-chain, no DepositInfo row"})
+	}
+	return result
+}
+
+// loadLocalDepositUsed returns the local_deposit_ids with an event_deposit_used row
+func loadLocalDepositUsed(database *gorm.DB, chainID int64) (map[uint64]bool, error) {
+	var ids []uint64
+	if err := database.Model(&models.EventDepositUsed{}).
+		Where("chain_id = ?", chainID).
+		Distinct().
+		Pluck("local_deposit_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	used := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		used[id] = true
+	}
+	return used, nil
+}
+
+// loadOnChainDepositUsed pages through DepositUsed events from the BlockScanner API
+func loadOnChainDepositUsed(scanner *clients.BlockScannerAPIClient, chainID int64, pageSize int) (map[uint64]bool, error) {
+	used := make(map[uint64]bool)
+	fetched := 0
+	for page := 1; ; page++ {
+		response, err := scanner.QueryEvents(clients.EventQueryParams{
+			ChainID:   chainID,
+			EventType: "DepositUsed",
+			Page:      page,
+			Limit:     pageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		if !response.Success {
+			return nil, fmt.Errorf("page %d: scanner returned unsuccessful response: %s", page, response.Message)
+		}
+
+		for _, event := range response.Events {
+			id, ok := parseLocalDepositID(event)
+			if !ok {
+				log.Printf("⚠️ DepositUsed event without localDepositId: %v", event)
+				continue
+			}
+			used[id] = true
+		}
+
+		fetched += len(response.Events)
+		if len(response.Events) < pageSize || int64(fetched) >= response.TotalCount {
+			break
+		}
+	}
+	return used, nil
+}
+
+// parseLocalDepositID reads localDepositId from a scanner event (number or decimal string, camelCase or snake_case)
+func parseLocalDepositID(event map[string]interface{}) (uint64, bool) {
+	for _, key := range []string{"localDepositId", "local_deposit_id"} {
+		switch v := event[key].(type) {
+		case float64:
+			return uint64(v), true
+		case string:
+			if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+				return id, true
+			}
+		}
+	}
+	return 0, false
+}
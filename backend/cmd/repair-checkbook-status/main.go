@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// Derives each Checkbook's status from its stored events and advances it when it lags behind.
+// Unlike update-checkbook-status, the target status is not given by the operator: it follows the
+// event processor's rules (services.DeriveCheckbookStatus) and is only applied when it is further
+// along the status progression than the current one, so statuses never move backwards.
+//
+// Events consulted for one checkbook:
+//   - event_deposit_recordeds        by (chain_id, local_deposit_id) -> ready_for_commitment
+//   - event_deposit_useds            by (chain_id, local_deposit_id) -> with_checkbook
+//   - event_commitment_root_updateds by commitment                  -> with_checkbook
+
+// repair one checkbook whose derived status is ahead of its current status
+type repair struct {
+	checkbook models.Checkbook
+	evidence  services.CheckbookEventEvidence
+	target    models.CheckbookStatus
+}
+
+func main() {
+	var checkbookID string
+	var status string
+	var dryRun bool
+
+	flag.StringVar(&checkbookID, "id", "", "Specific checkbook ID to repair (optional, if empty, checks every checkbook not yet with_checkbook)")
+	flag.StringVar(&status, "status", "", "Only check checkbooks in this status (optional)")
+	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode (show derived statuses without updating)")
+	flag.Parse()
+
+	fmt.Println("🩺 Checkbook Status Repair Script")
+	fmt.Println(strings.Repeat("=", 60))
+	if checkbookID != "" {
+		fmt.Printf("Checkbook ID: %s\n", checkbookID)
+	} else {
+		fmt.Printf("Checkbook ID: ALL (every checkbook not yet %s)\n", models.CheckbookStatusWithCheckbook)
+	}
+	if status != "" {
+		fmt.Printf("Status: %s\n", status)
+	}
+	if dryRun {
+		fmt.Printf("Mode: DRY RUN (no changes will be made)\n")
+	} else {
+		fmt.Printf("Mode: LIVE (lagging statuses will be advanced)\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var checkbooks []models.Checkbook
+	query := db.DB.Model(&models.Checkbook{})
+	if checkbookID != "" {
+		query = query.Where("id = ?", checkbookID)
+	} else {
+		query = query.Where("status NOT IN ?", []models.CheckbookStatus{models.CheckbookStatusWithCheckbook, models.CheckbookStatusDeleted})
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at ASC").Find(&checkbooks).Error; err != nil {
+		log.Fatalf("❌ Failed to query checkbooks: %v", err)
+	}
+	fmt.Printf("📋 Checkbooks to check: %d\n", len(checkbooks))
+	fmt.Println()
+
+	var repairs []repair
+	for _, checkbook := range checkbooks {
+		evidence, err := loadEvidence(db.DB, checkbook)
+		if err != nil {
+			log.Printf("❌ Failed to load events for checkbook %s: %v", checkbook.ID, err)
+			continue
+		}
+		target := services.DeriveCheckbookStatus(evidence)
+		if target == "" || !services.IsCheckbookStatusAhead(checkbook.Status, target) {
+			continue
+		}
+		repairs = append(repairs, repair{checkbook: checkbook, evidence: evidence, target: target})
+	}
+
+	if len(repairs) == 0 {
+		fmt.Println("✅ No lagging checkbook statuses found")
+		return
+	}
+
+	fmt.Printf("⚠️  Found %d checkbook(s) behind their events:\n", len(repairs))
+	for _, r := range repairs {
+		fmt.Printf("  - %s (chain=%d, local_deposit_id=%d): %s → %s [recorded=%v used=%v root_updated=%v]\n",
+			r.checkbook.ID, r.checkbook.SLIP44ChainID, r.checkbook.LocalDepositID, r.checkbook.Status, r.target,
+			r.evidence.DepositRecorded, r.evidence.DepositUsed, r.evidence.CommitmentRootUpdated)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Printf("🔍 DRY RUN: %d checkbook(s) would be advanced\n", len(repairs))
+		fmt.Println("   Run without --dry-run flag to actually update the database")
+		return
+	}
+
+	repaired := 0
+	for _, r := range repairs {
+		updates := map[string]interface{}{
+			"status":     r.target,
+			"updated_at": time.Now(),
+		}
+		// Version-guarded so a checkbook the processor advanced meanwhile is left alone
+		err := repository.UpdateIfVersion(db.DB, &models.Checkbook{}, r.checkbook.ID, r.checkbook.Version, updates)
+		if err != nil {
+			log.Printf("❌ Failed to advance checkbook %s: %v", r.checkbook.ID, err)
+			continue
+		}
+		repaired++
+		log.Printf("✅ Checkbook %s: %s → %s", r.checkbook.ID, r.checkbook.Status, r.target)
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("✅ Repaired: %d / %d\n", repaired, len(repairs))
+}
+
+// loadEvidence checks which events are stored for a checkbook's deposit and commitment
+func loadEvidence(database *gorm.DB, checkbook models.Checkbook) (services.CheckbookEventEvidence, error) {
+	var evidence services.CheckbookEventEvidence
+
+	var count int64
+	if err := database.Model(&models.EventDepositRecorded{}).
+		Where("chain_id = ? AND local_deposit_id = ?", checkbook.SLIP44ChainID, checkbook.LocalDepositID).
+		Count(&count).Error; err != nil {
+		return evidence, fmt.Errorf("failed to count DepositRecorded events: %w", err)
+	}
+	evidence.DepositRecorded = count > 0
+
+	var used []models.EventDepositUsed
+	if err := database.
+		Where("chain_id = ? AND local_deposit_id = ?", checkbook.SLIP44ChainID, checkbook.LocalDepositID).
+		Find(&used).Error; err != nil {
+		return evidence, fmt.Errorf("failed to load DepositUsed events: %w", err)
+	}
+	evidence.DepositUsed = len(used) > 0
+
+	// The commitment comes from the checkbook, or from its DepositUsed event when the checkbook never stored it
+	commitments := make([]string, 0, len(used)+1)
+	if checkbook.Commitment != nil && *checkbook.Commitment != "" {
+		commitments = append(commitments, *checkbook.Commitment)
+	}
+	for _, event := range used {
+		if event.Commitment != "" {
+			commitments = append(commitments, event.Commitment)
+		}
+	}
+	if len(commitments) > 0 {
+		if err := database.Model(&models.EventCommitmentRootUpdated{}).
+			Where("commitment IN ?", commitments).
+			Count(&count).Error; err != nil {
+			return evidence, fmt.Errorf("failed to count CommitmentRootUpdated events: %w", err)
+		}
+		evidence.CommitmentRootUpdated = count > 0
+	}
+
+	return evidence, nil
+}
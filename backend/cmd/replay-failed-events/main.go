@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/logging"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// Replays dead-lettered NATS events (failed_events) through the event processor.
+// Events land there after nats.max_process_attempts failed attempts; run this once the cause is fixed.
+
+func main() {
+	var id uint64
+	var eventType string
+	var limit int
+	var dryRun bool
+
+	flag.Uint64Var(&id, "id", 0, "Replay a single failed event by ID (optional)")
+	flag.StringVar(&eventType, "type", "", "Only replay events of this type, e.g. DepositRecorded (optional)")
+	flag.IntVar(&limit, "limit", 100, "Maximum number of events to replay")
+	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode (list pending events without replaying them)")
+	flag.Parse()
+
+	if limit <= 0 {
+		fmt.Println("❌ -limit must be positive")
+		os.Exit(1)
+	}
+
+	fmt.Println("📮 Failed Event Replay Script")
+	fmt.Println(strings.Repeat("=", 60))
+	if id != 0 {
+		fmt.Printf("Event ID: %d\n", id)
+	}
+	if eventType != "" {
+		fmt.Printf("Event Type: %s\n", eventType)
+	} else {
+		fmt.Printf("Event Type: ALL\n")
+	}
+	if dryRun {
+		fmt.Printf("Mode: DRY RUN (no changes will be made)\n")
+	} else {
+		fmt.Printf("Mode: LIVE (events will be replayed through the processor)\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	failedEvents, err := loadPendingFailedEvents(db.DB, id, eventType, limit)
+	if err != nil {
+		log.Fatalf("❌ Failed to load failed events: %v", err)
+	}
+	if len(failedEvents) == 0 {
+		fmt.Println("✅ No pending failed events")
+		return
+	}
+	fmt.Printf("📦 Pending failed events: %d\n", len(failedEvents))
+
+	if dryRun {
+		for _, failed := range failedEvents {
+			fmt.Printf("  - #%d %s (subject=%s, attempts=%d): %s\n",
+				failed.ID, failed.EventType, failed.Subject, failed.Attempts, failed.LastError)
+		}
+		fmt.Println()
+		fmt.Printf("🔍 DRY RUN: %d event(s) would be replayed\n", len(failedEvents))
+		fmt.Println("   Run without --dry-run flag to actually replay them")
+		return
+	}
+
+	// No WebSocket push in CLI mode
+	processor := services.NewBlockchainEventProcessor(db.DB, nil, nil, logging.New(config.GetLogFormat()))
+
+	replayed := 0
+	for _, failed := range failedEvents {
+		if err := replayFailedEvent(db.DB, processor, failed); err != nil {
+			log.Printf("❌ #%d %s replay failed: %v", failed.ID, failed.EventType, err)
+			continue
+		}
+		replayed++
+		log.Printf("✅ #%d %s replayed", failed.ID, failed.EventType)
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("✅ Replayed: %d / %d\n", replayed, len(failedEvents))
+	if replayed < len(failedEvents) {
+		fmt.Printf("❌ Still failing: %d (kept as pending)\n", len(failedEvents)-replayed)
+		os.Exit(1)
+	}
+}
+
+// loadPendingFailedEvents returns pending failed events, oldest first
+func loadPendingFailedEvents(database *gorm.DB, id uint64, eventType string, limit int) ([]models.FailedEvent, error) {
+	query := database.Where("status = ?", models.FailedEventStatusPending)
+	if id != 0 {
+		query = query.Where("id = ?", id)
+	}
+	if eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var failedEvents []models.FailedEvent
+	err := query.Order("id ASC").Limit(limit).Find(&failedEvents).Error
+	return failedEvents, err
+}
+
+// replayFailedEvent dispatches one failed event and records the outcome on its row
+func replayFailedEvent(database *gorm.DB, processor *services.BlockchainEventProcessor, failed models.FailedEvent) error {
+	processErr := processor.Dispatch(failed.Subject, []byte(failed.Payload))
+
+	updates := map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"),
+	}
+	if processErr != nil {
+		updates["last_error"] = processErr.Error()
+	} else {
+		now := time.Now()
+		updates["status"] = models.FailedEventStatusReplayed
+		updates["replayed_at"] = &now
+	}
+	if err := database.Model(&models.FailedEvent{}).Where("id = ?", failed.ID).Updates(updates).Error; err != nil {
+		log.Printf("⚠️ Failed to update failed event #%d: %v", failed.ID, err)
+	}
+	return processErr
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-backend/internal/clients"
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/logging"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gorm.io/gorm"
+)
+
+// Supported event types
+var eventTypes = []string{
+	"deposit-received",
+	"deposit-recorded",
+	"deposit-used",
+	"commitment-root-updated",
+	"withdraw-requested",
+	"withdraw-executed",
+}
+
+// reprocessOptions holds the row filters shared by all event types
+type reprocessOptions struct {
+	chainID int64
+	from    time.Time
+	to      time.Time
+	dryRun  bool
+}
+
+// stats counts reprocessing results
+type stats struct {
+	total     int
+	succeeded int
+	failed    int
+}
+
+func main() {
+	var eventType string
+	var chainID int64
+	var fromStr string
+	var toStr string
+	var dryRun bool
+
+	flag.StringVar(&eventType, "type", "", "Event type to reprocess: "+strings.Join(eventTypes, ", "))
+	flag.Int64Var(&chainID, "chain-id", 0, "SLIP-44 chain ID filter (optional, 0 = all chains)")
+	flag.StringVar(&fromStr, "from", "", "Only events with block_timestamp >= from (RFC3339, optional)")
+	flag.StringVar(&toStr, "to", "", "Only events with block_timestamp < to (RFC3339, optional)")
+	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode (show what would be reprocessed without calling the processor)")
+	flag.Parse()
+
+	if !isSupportedEventType(eventType) {
+		fmt.Printf("❌ Unsupported event type: %q\n", eventType)
+		fmt.Printf("   Supported types: %s\n", strings.Join(eventTypes, ", "))
+		os.Exit(1)
+	}
+
+	opts := reprocessOptions{chainID: chainID, dryRun: dryRun}
+	if fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			log.Fatalf("❌ Invalid -from time %q: %v", fromStr, err)
+		}
+		opts.from = t
+	}
+	if toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			log.Fatalf("❌ Invalid -to time %q: %v", toStr, err)
+		}
+		opts.to = t
+	}
+
+	fmt.Println("🔁 Event Reprocessing Script")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Event Type: %s\n", eventType)
+	if chainID != 0 {
+		fmt.Printf("Chain ID: %d\n", chainID)
+	} else {
+		fmt.Printf("Chain ID: ALL\n")
+	}
+	fmt.Printf("From: %s\n", displayTime(opts.from))
+	fmt.Printf("To: %s\n", displayTime(opts.to))
+	if dryRun {
+		fmt.Printf("Mode: DRY RUN (no changes will be made)\n")
+	} else {
+		fmt.Printf("Mode: LIVE (events will be replayed through the processor)\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	// No WebSocket push in CLI mode
+	processor := services.NewBlockchainEventProcessor(db.DB, nil, nil, logging.New(config.GetLogFormat()))
+
+	var result stats
+	var err error
+	switch eventType {
+	case "deposit-received":
+		result, err = reprocessDepositReceived(processor, opts)
+	case "deposit-recorded":
+		result, err = reprocessDepositRecorded(processor, opts)
+	case "deposit-used":
+		result, err = reprocessDepositUsed(processor, opts)
+	case "commitment-root-updated":
+		result, err = reprocessCommitmentRootUpdated(processor, opts)
+	case "withdraw-requested":
+		result, err = reprocessWithdrawRequested(processor, opts)
+	case "withdraw-executed":
+		result, err = reprocessWithdrawExecuted(processor, opts)
+	}
+	if err != nil {
+		log.Fatalf("❌ Failed to reprocess %s events: %v", eventType, err)
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	if dryRun {
+		fmt.Printf("🔍 DRY RUN: %d event(s) would be reprocessed\n", result.total)
+		fmt.Println("   Run without --dry-run flag to actually reprocess")
+		return
+	}
+	fmt.Printf("✅ Reprocessing finished: total=%d, succeeded=%d, failed=%d\n", result.total, result.succeeded, result.failed)
+}
+
+func isSupportedEventType(eventType string) bool {
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func displayTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// eventQuery applies chain/time filters and orders rows in chain order
+func eventQuery(opts reprocessOptions) *gorm.DB {
+	query := db.DB
+	if opts.chainID != 0 {
+		query = query.Where("chain_id = ?", opts.chainID)
+	}
+	if !opts.from.IsZero() {
+		query = query.Where("block_timestamp >= ?", opts.from)
+	}
+	if !opts.to.IsZero() {
+		query = query.Where("block_timestamp < ?", opts.to)
+	}
+	return query.Order("block_number ASC, log_index ASC")
+}
+
+// replayReplacing replays an event whose processor method inserts the event row unconditionally.
+// The stored row is deleted and the event processed again in one transaction, by a processor bound to it,
+// so a replay that fails leaves the original row and every state change rolled back.
+func replayReplacing[T any](processor *services.BlockchainEventProcessor, original *T, process func(processor *services.BlockchainEventProcessor) error) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(original).Error; err != nil {
+			return fmt.Errorf("failed to delete stored event: %w", err)
+		}
+		return process(processor.WithDB(tx))
+	})
+}
+
+func record(result *stats, label string, err error) {
+	if err != nil {
+		result.failed++
+		fmt.Printf("   ❌ %s: %v\n", label, err)
+		return
+	}
+	result.succeeded++
+	fmt.Printf("   ✅ %s\n", label)
+}
+
+func reprocessDepositReceived(processor *services.BlockchainEventProcessor, opts reprocessOptions) (stats, error) {
+	var rows []models.EventDepositReceived
+	if err := eventQuery(opts).Find(&rows).Error; err != nil {
+		return stats{}, err
+	}
+	fmt.Printf("📋 Found %d DepositReceived event(s)\n", len(rows))
+
+	result := stats{total: len(rows)}
+	for i, row := range rows {
+		label := fmt.Sprintf("%d. chain=%d, local_deposit_id=%d, tx=%s", i+1, row.ChainID, row.LocalDepositId, row.TransactionHash)
+		if opts.dryRun {
+			fmt.Printf("   %s\n", label)
+			continue
+		}
+
+		event := &clients.EventDepositReceivedResponse{
+			ChainID:         row.ChainID,
+			ContractAddress: row.ContractAddress,
+			EventName:       row.EventName,
+			BlockNumber:     row.BlockNumber,
+			TransactionHash: row.TransactionHash,
+			LogIndex:        row.LogIndex,
+			BlockTimestamp:  row.BlockTimestamp,
+		}
+		event.EventData.Depositor = row.Depositor
+		event.EventData.Token = row.Token
+		event.EventData.Amount = row.Amount
+		event.EventData.LocalDepositId = row.LocalDepositId
+		event.EventData.ChainId = row.EventChainId
+		event.EventData.PromoteCode = row.PromoteCode
+
+		// ProcessDepositReceived upserts the event row
+		record(&result, label, processor.ProcessDepositReceived(event))
+	}
+	return result, nil
+}
+
+func reprocessDepositRecorded(processor *services.BlockchainEventProcessor, opts reprocessOptions) (stats, error) {
+	var rows []models.EventDepositRecorded
+	if err := eventQuery(opts).Find(&rows).Error; err != nil {
+		return stats{}, err
+	}
+	fmt.Printf("📋 Found %d DepositRecorded event(s)\n", len(rows))
+
+	result := stats{total: len(rows)}
+	for i, row := range rows {
+		label := fmt.Sprintf("%d. chain=%d, local_deposit_id=%d, tx=%s", i+1, row.ChainID, row.LocalDepositId, row.TransactionHash)
+		if opts.dryRun {
+			fmt.Printf("   %s\n", label)
+			continue
+		}
+
+		event := &clients.EventDepositRecordedResponse{
+			ChainID:         row.ChainID,
+			ContractAddress: row.ContractAddress,
+			EventName:       row.EventName,
+			BlockNumber:     row.BlockNumber,
+			TransactionHash: row.TransactionHash,
+			LogIndex:        row.LogIndex,
+			BlockTimestamp:  row.BlockTimestamp,
+		}
+		event.EventData.LocalDepositId = row.LocalDepositId
+		event.EventData.TokenKey = tokenKeyHashForDeposit(row.ChainID, row.LocalDepositId)
+		event.EventData.TokenId = row.TokenId
+		event.EventData.Owner.ChainId = row.OwnerChainId
+		event.EventData.Owner.Data = row.OwnerData
+		event.EventData.GrossAmount = row.GrossAmount
+		event.EventData.FeeTotalLocked = row.FeeTotalLocked
+		event.EventData.AllocatableAmount = row.AllocatableAmount
+		event.EventData.PromoteCode = row.PromoteCode
+		event.EventData.AddressRank = row.AddressRank
+		event.EventData.DepositTxHash = row.DepositTxHash
+		event.EventData.BlockNumber = row.EventBlockNumber
+		event.EventData.Timestamp = row.EventTimestamp
+
+		// ProcessDepositRecorded upserts the event row
+		record(&result, label, processor.ProcessDepositRecorded(event))
+	}
+	return result, nil
+}
+
+// tokenKeyHashForDeposit rebuilds the indexed tokenKey hash (not stored in the event table) from the Checkbook
+func tokenKeyHashForDeposit(chainID int64, localDepositID uint64) string {
+	var checkbook models.Checkbook
+	err := db.DB.Where("chain_id = ? AND local_deposit_id = ?", chainID, localDepositID).First(&checkbook).Error
+	if err != nil || checkbook.TokenKey == "" {
+		log.Printf("⚠️ TokenKey not found for deposit (chain=%d, local_deposit_id=%d), token key will be empty", chainID, localDepositID)
+		return ""
+	}
+	return crypto.Keccak256Hash([]byte(checkbook.TokenKey)).Hex()
+}
+
+func reprocessDepositUsed(processor *services.BlockchainEventProcessor, opts reprocessOptions) (stats, error) {
+	var rows []models.EventDepositUsed
+	if err := eventQuery(opts).Find(&rows).Error; err != nil {
+		return stats{}, err
+	}
+	fmt.Printf("📋 Found %d DepositUsed event(s)\n", len(rows))
+
+	result := stats{total: len(rows)}
+	for i := range rows {
+		row := &rows[i]
+		label := fmt.Sprintf("%d. chain=%d, local_deposit_id=%d, commitment=%s", i+1, row.ChainID, row.LocalDepositId, row.Commitment)
+		if opts.dryRun {
+			fmt.Printf("   %s\n", label)
+			continue
+		}
+
+		event := &clients.EventDepositUsedResponse{
+			ChainID:         row.ChainID,
+			ContractAddress: row.ContractAddress,
+			EventName:       row.EventName,
+			BlockNumber:     row.BlockNumber,
+			TransactionHash: row.TransactionHash,
+			LogIndex:        row.LogIndex,
+			BlockTimestamp:  row.BlockTimestamp,
+		}
+		event.EventData.ChainId = row.EventChainId
+		event.EventData.LocalDepositId = row.LocalDepositId
+		event.EventData.Commitment = row.Commitment
+		event.EventData.PromoteCode = row.PromoteCode
+
+		err := replayReplacing(processor, row, func(processor *services.BlockchainEventProcessor) error {
+			return processor.ProcessDepositUsed(event)
+		})
+		record(&result, label, err)
+	}
+	return result, nil
+}
+
+func reprocessCommitmentRootUpdated(processor *services.BlockchainEventProcessor, opts reprocessOptions) (stats, error) {
+	var rows []models.EventCommitmentRootUpdated
+	if err := eventQuery(opts).Find(&rows).Error; err != nil {
+		return stats{}, err
+	}
+	fmt.Printf("📋 Found %d CommitmentRootUpdated event(s)\n", len(rows))
+
+	result := stats{total: len(rows)}
+	for i := range rows {
+		row := &rows[i]
+		label := fmt.Sprintf("%d. chain=%d, commitment=%s, new_root=%s", i+1, row.ChainID, row.Commitment, row.NewRoot)
+		if opts.dryRun {
+			fmt.Printf("   %s\n", label)
+			continue
+		}
+
+		event := &clients.EventCommitmentRootUpdatedResponse{
+			ChainID:         row.ChainID,
+			ContractAddress: row.ContractAddress,
+			EventName:       row.EventName,
+			BlockNumber:     row.BlockNumber,
+			TransactionHash: row.TransactionHash,
+			LogIndex:        row.LogIndex,
+			BlockTimestamp:  row.BlockTimestamp,
+		}
+		event.EventData.OldRoot = row.OldRoot
+		event.EventData.Commitment = row.Commitment
+		event.EventData.NewRoot = row.NewRoot
+
+		err := replayReplacing(processor, row, func(processor *services.BlockchainEventProcessor) error {
+			return processor.ProcessCommitmentRootUpdated(event)
+		})
+		record(&result, label, err)
+	}
+	return result, nil
+}
+
+func reprocessWithdrawRequested(processor *services.BlockchainEventProcessor, opts reprocessOptions) (stats, error) {
+	var rows []models.EventWithdrawRequested
+	if err := eventQuery(opts).Find(&rows).Error; err != nil {
+		return stats{}, err
+	}
+	fmt.Printf("📋 Found %d WithdrawRequested event(s)\n", len(rows))
+
+	result := stats{total: len(rows)}
+	for i := range rows {
+		row := &rows[i]
+		label := fmt.Sprintf("%d. chain=%d, request_id=%s, tx=%s", i+1, row.ChainID, row.RequestId, row.TransactionHash)
+		if opts.dryRun {
+			fmt.Printf("   %s\n", label)
+			continue
+		}
+
+		event := &clients.EventWithdrawRequestedResponse{
+			ChainID:         row.ChainID,
+			ContractAddress: row.ContractAddress,
+			EventName:       row.EventName,
+			BlockNumber:     row.BlockNumber,
+			TransactionHash: row.TransactionHash,
+			LogIndex:        row.LogIndex,
+			BlockTimestamp:  row.BlockTimestamp,
+		}
+		event.EventData.RequestId = row.RequestId
+		event.EventData.Recipient = row.RecipientData
+		event.EventData.TokenId = row.TokenId
+		event.EventData.Amount = row.Amount
+
+		err := replayReplacing(processor, row, func(processor *services.BlockchainEventProcessor) error {
+			return processor.ProcessWithdrawRequested(event)
+		})
+		record(&result, label, err)
+	}
+	return result, nil
+}
+
+func reprocessWithdrawExecuted(processor *services.BlockchainEventProcessor, opts reprocessOptions) (stats, error) {
+	var rows []models.EventWithdrawExecuted
+	if err := eventQuery(opts).Find(&rows).Error; err != nil {
+		return stats{}, err
+	}
+	fmt.Printf("📋 Found %d WithdrawExecuted event(s)\n", len(rows))
+
+	result := stats{total: len(rows)}
+	for i := range rows {
+		row := &rows[i]
+		label := fmt.Sprintf("%d. chain=%d, request_id=%s, tx=%s", i+1, row.ChainID, row.RequestId, row.TransactionHash)
+		if opts.dryRun {
+			fmt.Printf("   %s\n", label)
+			continue
+		}
+
+		event := &clients.EventWithdrawExecutedResponse{
+			ChainID:         row.ChainID,
+			ContractAddress: row.ContractAddress,
+			EventName:       row.EventName,
+			BlockNumber:     row.BlockNumber,
+			TransactionHash: row.TransactionHash,
+			LogIndex:        row.LogIndex,
+			BlockTimestamp:  row.BlockTimestamp,
+		}
+		event.EventData.Recipient = row.Recipient
+		event.EventData.Token = row.Token
+		event.EventData.Amount = row.Amount
+		event.EventData.RequestId = row.RequestId
+
+		err := replayReplacing(processor, row, func(processor *services.BlockchainEventProcessor) error {
+			return processor.ProcessWithdrawExecuted(event)
+		})
+		record(&result, label, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/internal/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Cross-checks configured token decimals against the token contracts' decimals().
+// A wrong value makes every deposit of that token convert to a wrong management amount without any error.
+//
+// Tokens checked per chain:
+//   - every address in tokens.tokenAddressDecimals
+//   - the network's usdtContract when it is not in that table (it then falls back to tokenId 0 of tokens.chainDecimals)
+//
+// In live mode mismatches are written to token_decimals_overrides, which the event processor applies on top of
+// the config at startup.
+
+// decimalsSelector first 4 bytes of keccak256("decimals()")
+var decimalsSelector = common.FromHex("0x313ce567")
+
+// rpcTimeout timeout of one decimals() call
+const rpcTimeout = 15 * time.Second
+
+// tokenCheck one configured token to verify
+type tokenCheck struct {
+	chainID    int
+	address    string // canonical (utils.CanonicalTokenAddress)
+	configured *int   // nil when the token is not in tokens.tokenAddressDecimals
+	source     string
+}
+
+// mismatch a token whose on-chain decimals differ from the configured ones
+type mismatch struct {
+	check    tokenCheck
+	onChain  int
+	fallback *int // tokens.chainDecimals[chain][0], used when configured is nil
+}
+
+func main() {
+	var chainID int
+	var dryRun bool
+
+	flag.IntVar(&chainID, "chain", 0, "Only verify tokens of this SLIP-44 chain ID (optional, 0 = all configured chains)")
+	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode (report mismatches without writing token_decimals_overrides)")
+	flag.Parse()
+
+	fmt.Println("🔢 Token Decimals Verification")
+	fmt.Println(strings.Repeat("=", 60))
+	if chainID != 0 {
+		fmt.Printf("Chain ID: %d\n", chainID)
+	} else {
+		fmt.Printf("Chain ID: ALL\n")
+	}
+	if dryRun {
+		fmt.Printf("Mode: DRY RUN (no changes will be made)\n")
+	} else {
+		fmt.Printf("Mode: LIVE (mismatches will be written to token_decimals_overrides)\n")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	checks := collectTokenChecks(chainID)
+	if len(checks) == 0 {
+		fmt.Println("ℹ️  No token addresses configured (tokens.tokenAddressDecimals / usdtContract)")
+		return
+	}
+	fmt.Printf("📋 Tokens to verify: %d\n", len(checks))
+	fmt.Println()
+
+	clients := make(map[int]*ethclient.Client)
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+
+	var mismatches []mismatch
+	failed := 0
+	for _, check := range checks {
+		client, ok := clients[check.chainID]
+		if !ok {
+			var err error
+			client, err = dialChain(check.chainID)
+			if err != nil {
+				log.Printf("❌ Chain %d: %v", check.chainID, err)
+			}
+			clients[check.chainID] = client
+		}
+		if client == nil {
+			failed++
+			continue
+		}
+
+		onChain, err := readDecimals(client, check.address)
+		if err != nil {
+			log.Printf("❌ Chain %d token %s (%s): %v", check.chainID, check.address, check.source, err)
+			failed++
+			continue
+		}
+
+		m := compareDecimals(check, onChain)
+		switch {
+		case m == nil && check.configured != nil:
+			fmt.Printf("  ✅ chain=%d %s (%s): %d\n", check.chainID, check.address, check.source, onChain)
+		case m == nil:
+			fmt.Printf("  ✅ chain=%d %s (%s): %d (not in tokenAddressDecimals, tokenId 0 fallback matches)\n",
+				check.chainID, check.address, check.source, onChain)
+		case check.configured != nil:
+			fmt.Printf("  ❌ chain=%d %s (%s): configured %d, on-chain %d\n", check.chainID, check.address, check.source, *check.configured, onChain)
+			mismatches = append(mismatches, *m)
+		default:
+			fmt.Printf("  ❌ chain=%d %s (%s): not in tokenAddressDecimals, tokenId 0 fallback %s, on-chain %d\n",
+				check.chainID, check.address, check.source, formatDecimals(m.fallback), onChain)
+			mismatches = append(mismatches, *m)
+		}
+	}
+	fmt.Println()
+
+	if len(mismatches) == 0 {
+		fmt.Printf("✅ No decimals mismatches found (%d token(s) could not be verified)\n", failed)
+		return
+	}
+
+	fmt.Printf("⚠️  Found %d token(s) whose configured decimals differ from the chain:\n", len(mismatches))
+	for _, m := range mismatches {
+		configured := formatDecimals(m.check.configured)
+		if m.check.configured == nil {
+			configured = formatDecimals(m.fallback) + " (tokenId 0 fallback)"
+		}
+		fmt.Printf("  - chain=%d %s: %s → %d\n", m.check.chainID, m.check.address, configured, m.onChain)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Printf("🔍 DRY RUN: %d override(s) would be written\n", len(mismatches))
+		fmt.Println("   Run without --dry-run flag to write token_decimals_overrides (or fix tokens.tokenAddressDecimals)")
+		return
+	}
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	repo := repository.NewTokenDecimalsOverrideRepository(db.DB)
+	written := 0
+	for _, m := range mismatches {
+		override := &models.TokenDecimalsOverride{
+			ChainID:            m.check.chainID,
+			TokenAddress:       m.check.address,
+			Decimals:           m.onChain,
+			ConfiguredDecimals: m.check.configured,
+		}
+		if err := repo.Upsert(context.Background(), override); err != nil {
+			log.Printf("❌ Failed to write override for chain %d token %s: %v", m.check.chainID, m.check.address, err)
+			continue
+		}
+		written++
+		log.Printf("✅ Override written: chain=%d %s decimals=%d", m.check.chainID, m.check.address, m.onChain)
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("✅ Overrides written: %d / %d (applied by the event processor on its next start)\n", written, len(mismatches))
+}
+
+// collectTokenChecks lists the configured token addresses, sorted by chain and address
+func collectTokenChecks(onlyChainID int) []tokenCheck {
+	seen := make(map[string]bool)
+	var checks []tokenCheck
+	add := func(chainID int, address string, configured *int, source string) {
+		if onlyChainID != 0 && chainID != onlyChainID {
+			return
+		}
+		canonical := utils.CanonicalTokenAddress(address, chainID)
+		key := fmt.Sprintf("%d:%s", chainID, canonical)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		checks = append(checks, tokenCheck{chainID: chainID, address: canonical, configured: configured, source: source})
+	}
+
+	for chainID, tokens := range config.AppConfig.Tokens.TokenAddressDecimals {
+		for address, decimals := range tokens {
+			decimals := decimals
+			add(chainID, address, &decimals, "tokenAddressDecimals")
+		}
+	}
+	for name, network := range config.AppConfig.Blockchain.Networks {
+		if network.Enabled && network.USDTContract != "" {
+			add(network.ChainID, network.USDTContract, nil, name+".usdtContract")
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].chainID != checks[j].chainID {
+			return checks[i].chainID < checks[j].chainID
+		}
+		return checks[i].address < checks[j].address
+	})
+	return checks
+}
+
+// compareDecimals returns the mismatch of a token whose on-chain decimals differ from the configured ones,
+// or from the tokenId 0 fallback when the token is not in tokenAddressDecimals; nil when they agree
+func compareDecimals(check tokenCheck, onChain int) *mismatch {
+	if check.configured != nil {
+		if *check.configured == onChain {
+			return nil
+		}
+		return &mismatch{check: check, onChain: onChain}
+	}
+	fallback := chainDecimalsFallback(check.chainID)
+	if fallback != nil && *fallback == onChain {
+		return nil
+	}
+	return &mismatch{check: check, onChain: onChain, fallback: fallback}
+}
+
+// chainDecimalsFallback tokens.chainDecimals[chain][0], the decimals used for tokens missing from tokenAddressDecimals
+func chainDecimalsFallback(chainID int) *int {
+	decimals, ok := config.AppConfig.Tokens.ChainDecimals[chainID][0]
+	if !ok {
+		return nil
+	}
+	return &decimals
+}
+
+// dialChain connects to the first reachable RPC endpoint of a chain's network config
+func dialChain(chainID int) (*ethclient.Client, error) {
+	networkConfig, err := config.GetNetworkConfigByChainID(chainID)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, endpoint := range networkConfig.RPCEndpoints {
+		client, err := ethclient.Dial(endpoint)
+		if err == nil {
+			return client, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("no rpcEndpoints configured")
+	}
+	return nil, fmt.Errorf("no reachable RPC endpoint: %w", lastErr)
+}
+
+// readDecimals calls decimals() on an ERC20/TRC20 token
+func readDecimals(client *ethclient.Client, address string) (int, error) {
+	if !utils.IsEvmAddress(address) {
+		return 0, fmt.Errorf("not a 20-byte token address: %s", address)
+	}
+	token := common.HexToAddress(address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: decimalsSelector}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("decimals() call failed: %w", err)
+	}
+	if len(result) != 32 {
+		return 0, fmt.Errorf("unexpected decimals() result length %d (not a token contract?)", len(result))
+	}
+	decimals := new(big.Int).SetBytes(result)
+	if !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return 0, fmt.Errorf("decimals() returned out-of-range value %s", decimals.String())
+	}
+	return int(decimals.Uint64()), nil
+}
+
+// formatDecimals prints optional decimals
+func formatDecimals(decimals *int) string {
+	if decimals == nil {
+		return "unset"
+	}
+	return fmt.Sprintf("%d", *decimals)
+}
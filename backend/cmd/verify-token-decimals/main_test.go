@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	tokenA = "0x00000000000000000000000000000000000000a1"
+	tokenB = "0x00000000000000000000000000000000000000b2"
+	tokenC = "0x00000000000000000000000000000000000000c3"
+)
+
+// uint256Result ABI-encodes a decimals() return value
+func uint256Result(value int64) string {
+	return hexutil.Encode(new(big.Int).SetInt64(value).FillBytes(make([]byte, 32)))
+}
+
+// newStubTokenRPC an RPC endpoint answering eth_call by contract address: a hex result, or a JSON-RPC error
+// for addresses that are not in results
+func newStubTokenRPC(t *testing.T, results map[string]string) *ethclient.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		var call struct {
+			To string `json:"to"`
+		}
+		if req.Method == "eth_call" && len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &call)
+		}
+		if result, ok := results[strings.ToLower(call.To)]; ok {
+			response["result"] = result
+		} else {
+			response["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+		}
+		body, _ := json.Marshal(response)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial stub: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestReadDecimals(t *testing.T) {
+	client := newStubTokenRPC(t, map[string]string{
+		tokenA: uint256Result(18),
+		tokenB: "0x12", // not ABI-encoded
+		tokenC: uint256Result(256),
+	})
+	tests := []struct {
+		name    string
+		address string
+		want    int
+		wantErr string
+	}{
+		{"ERC20 decimals", tokenA, 18, ""},
+		{"short result", tokenB, 0, "unexpected decimals() result length"},
+		{"out of range", tokenC, 0, "out-of-range"},
+		{"call reverts", "0x00000000000000000000000000000000000000d4", 0, "decimals() call failed"},
+		{"not an address", "0xnot-a-token", 0, "not a 20-byte token address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readDecimals(client, tt.address)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readDecimals: %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("readDecimals = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyConfiguredTokensReportsMismatches(t *testing.T) {
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{
+		Tokens: config.TokenDecimalConfig{
+			ChainDecimals: map[int]map[int]int{714: {0: 18}},
+			TokenAddressDecimals: map[int]map[string]int{
+				714: {strings.ToUpper(tokenA[2:]): 18, tokenB: 6},
+				60:  {tokenA: 6},
+			},
+		},
+		Blockchain: config.BlockchainConfig{Networks: map[string]config.NetworkConfig{
+			"bsc":      {ChainID: 714, Enabled: true, USDTContract: tokenC},
+			"disabled": {ChainID: 714, Enabled: false, USDTContract: "0x00000000000000000000000000000000000000e5"},
+		}},
+	}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	checks := collectTokenChecks(714)
+	var addresses []string
+	for _, check := range checks {
+		addresses = append(addresses, check.address)
+	}
+	if got, want := strings.Join(addresses, ","), strings.Join([]string{tokenA, tokenB, tokenC}, ","); got != want {
+		t.Fatalf("checked tokens = %s, want %s (chain 714 only, sorted, disabled networks skipped)", got, want)
+	}
+
+	client := newStubTokenRPC(t, map[string]string{
+		tokenA: uint256Result(18), // matches tokenAddressDecimals
+		tokenB: uint256Result(18), // configured 6
+		tokenC: uint256Result(6),  // usdtContract, falls back to chainDecimals tokenId 0 = 18
+	})
+	var reported []string
+	for _, check := range checks {
+		onChain, err := readDecimals(client, check.address)
+		if err != nil {
+			t.Fatalf("readDecimals %s: %v", check.address, err)
+		}
+		if m := compareDecimals(check, onChain); m != nil {
+			expected := formatDecimals(m.check.configured)
+			if m.check.configured == nil {
+				expected = "fallback " + formatDecimals(m.fallback)
+			}
+			reported = append(reported, fmt.Sprintf("%s %s->%d", m.check.address, expected, m.onChain))
+		}
+	}
+	want := []string{tokenB + " 6->18", tokenC + " fallback 18->6"}
+	if strings.Join(reported, "; ") != strings.Join(want, "; ") {
+		t.Errorf("mismatches = %q, want %q", reported, want)
+	}
+}
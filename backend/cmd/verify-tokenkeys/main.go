@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+)
+
+// Verifies the tokenKey hash mapping used by DepositRecorded processing.
+// DepositRecorded carries keccak256(tokenKey); a hash that no known key maps to ends up stored as the
+// Checkbook's token_key. This lists the known keys with their hashes and reports checkbooks whose
+// token_key is an unresolved hash or a key that is not known.
+
+// tokenKeyUsage distinct checkbooks.token_key value with its row count
+type tokenKeyUsage struct {
+	TokenKey string
+	Count    int64
+}
+
+func main() {
+	var hash string
+	flag.StringVar(&hash, "hash", "", "Only resolve this tokenKey hash (no database access)")
+	flag.Parse()
+
+	// Load config
+	if err := config.LoadConfig(""); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	utils.RegisterTokenKeys(config.GetTokenKeys())
+
+	if hash != "" {
+		if key, ok := utils.LookupTokenKey(hash); ok {
+			fmt.Printf("✅ %s -> %s\n", hash, key)
+			return
+		}
+		fmt.Printf("❌ %s does not map to a known token key\n", hash)
+		os.Exit(1)
+	}
+
+	fmt.Println("🔑 Token Key Verification")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println("Configured token keys (tokens.tokenKeys):")
+	if len(config.GetTokenKeys()) == 0 {
+		fmt.Println("  (none, only the built-in map is used)")
+	}
+	for _, key := range config.GetTokenKeys() {
+		fmt.Printf("  %-10s %s\n", key, utils.TokenKeyHash(key))
+	}
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Println()
+
+	// Initialize database
+	db.InitDB()
+	defer func() {
+		sqlDB, err := db.DB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var usages []tokenKeyUsage
+	if err := db.DB.Model(&models.Checkbook{}).
+		Select("token_key, COUNT(*) AS count").
+		Group("token_key").
+		Order("token_key").
+		Scan(&usages).Error; err != nil {
+		log.Fatalf("❌ Failed to query checkbook token keys: %v", err)
+	}
+
+	problems := 0
+	for _, usage := range usages {
+		switch {
+		case utils.IsTokenKeyHash(usage.TokenKey):
+			// Stored as a hash: DepositRecorded could not map it back
+			if key, ok := utils.LookupTokenKey(usage.TokenKey); ok {
+				fmt.Printf("⚠️  %s (%d checkbooks) is a hash of known key %q, fix token_key manually\n", usage.TokenKey, usage.Count, key)
+			} else {
+				fmt.Printf("❌ %s (%d checkbooks) does not map to any known token key\n", usage.TokenKey, usage.Count)
+			}
+			problems++
+		case usage.TokenKey == "":
+			fmt.Printf("❌ empty token_key (%d checkbooks)\n", usage.Count)
+			problems++
+		default:
+			if _, ok := utils.LookupTokenKey(utils.TokenKeyHash(usage.TokenKey)); !ok {
+				fmt.Printf("⚠️  %q (%d checkbooks) is not a known token key, its DepositRecorded hash %s would not resolve\n",
+					usage.TokenKey, usage.Count, utils.TokenKeyHash(usage.TokenKey))
+				problems++
+				continue
+			}
+			fmt.Printf("✅ %-10s %s (%d checkbooks)\n", usage.TokenKey, utils.TokenKeyHash(usage.TokenKey), usage.Count)
+		}
+	}
+
+	fmt.Println()
+	if problems > 0 {
+		fmt.Printf("❌ %d problem(s) found; add missing keys to tokens.tokenKeys\n", problems)
+		os.Exit(1)
+	}
+	fmt.Println("✅ All checkbook token keys map to known keys")
+}
@@ -1,9 +1,11 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"go-backend/internal/clients"
 	"go-backend/internal/config"
@@ -50,6 +52,9 @@ type ServiceContainer struct {
 
 	// Withdraw Services
 	WithdrawTimeoutService *services.WithdrawTimeoutService
+	WithdrawReorgService   *services.WithdrawReorgService
+	WithdrawExpiryService  *services.WithdrawExpiryService  // set by the router with the WithdrawRequestService
+	WithdrawRequestService *services.WithdrawRequestService // set by the router, drained on Shutdown
 
 	// Scanner Services
 	UniversalScannerClient *clients.UniversalScannerClient
@@ -64,6 +69,9 @@ type ServiceContainer struct {
 	// Proof Generation Service
 	ProofGenerationService *services.ProofGenerationService
 
+	// TRON Transaction Service
+	TronTxService *services.TronTransactionService
+
 	// Initialization flags
 	natsOnce             sync.Once
 	eventProcessorOnce   sync.Once
@@ -82,6 +90,16 @@ func InitializeContainer() (*ServiceContainer, error) {
 	containerOnce.Do(func() {
 		log.Println("🚀 Initializing Service Container...")
 
+		// 0. Refuse to start with a placeholder JWT secret (unless jwt.allowDevSecret) or an invalid default hook
+		if err := config.ValidateJWTConfig(); err != nil {
+			initErr = err
+			return
+		}
+		if err := config.ValidateWithdrawConfig(); err != nil {
+			initErr = err
+			return
+		}
+
 		container := &ServiceContainer{
 			DB: db.DB,
 		}
@@ -168,6 +186,13 @@ func (c *ServiceContainer) initCoreServices() error {
 		clientCount := c.BlockchainTxService.GetClientCount()
 		log.Printf("✅ [ServiceContainer] Blockchain clients initialized: %d client(s)", clientCount)
 	}
+	// Reconnect RPC clients that stop answering to the network's other endpoints
+	c.BlockchainTxService.StartHealthWatcher(30 * time.Second)
+	// Alert before a signing address runs out of gas (networks with lowBalanceThresholdWei)
+	c.BlockchainTxService.StartBalanceMonitor(config.GetBalanceCheckInterval())
+
+	// TRON Transaction Service (shares ZKPay ABI encoding with BlockchainTxService)
+	c.TronTxService = services.NewTronTransactionService(c.KeyManagementService, c.BlockchainTxService)
 
 	// Transaction Queue Service (must be created after BlockchainTxService)
 	c.TransactionQueueService = services.NewTransactionQueueService(c.DB, c.BlockchainTxService)
@@ -200,6 +225,7 @@ func (c *ServiceContainer) initCoreServices() error {
 		c.ZKVMClient,
 		c.BlockchainTxService, // Pass service container instance
 	)
+	c.CheckbookService.SetTronTransactionService(c.TronTxService)
 
 	// Queue Root Manager
 	c.QueueRootManager = services.NewQueueRootManager(c.DB, c.BlockscannerAPIClient)
@@ -218,6 +244,10 @@ func (c *ServiceContainer) initCoreServices() error {
 	c.WithdrawTimeoutService = services.NewWithdrawTimeoutService(c.DB, withdrawRepo)
 	c.WithdrawTimeoutService.Start()
 
+	// Withdraw Reorg Service (re-checks confirmed executeWithdraw transactions)
+	c.WithdrawReorgService = services.NewWithdrawReorgService(c.DB, withdrawRepo, repository.NewTransactor(c.DB), c.BlockchainTxService)
+	c.WithdrawReorgService.Start()
+
 	// Monitoring Service (requires blockchain clients)
 	c.MonitoringService = services.NewMonitoringService(
 		c.DB,
@@ -303,6 +333,19 @@ func (c *ServiceContainer) GetDatabaseWithPushService() *services.DatabaseWithPu
 	return c.DatabaseWithPushService
 }
 
+// Shutdown drains in-flight background work (proof generation) until ctx expires, then cleans up
+// Call on SIGTERM before closing the database.
+func (c *ServiceContainer) Shutdown(ctx context.Context) error {
+	var err error
+	if c.WithdrawRequestService != nil {
+		if err = c.WithdrawRequestService.Shutdown(ctx); err != nil {
+			log.Printf("⚠️ WithdrawRequestService did not drain before timeout: %v", err)
+		}
+	}
+	c.Cleanup()
+	return err
+}
+
 // Cleanup
 func (c *ServiceContainer) Cleanup() {
 	log.Println("🧹 Cleaning up Service Container...")
@@ -318,6 +361,16 @@ func (c *ServiceContainer) Cleanup() {
 	if c.WithdrawTimeoutService != nil {
 		c.WithdrawTimeoutService.Stop()
 	}
+	if c.WithdrawReorgService != nil {
+		c.WithdrawReorgService.Stop()
+	}
+	if c.WithdrawExpiryService != nil {
+		c.WithdrawExpiryService.Stop()
+	}
+	if c.BlockchainTxService != nil {
+		c.BlockchainTxService.StopHealthWatcher()
+		c.BlockchainTxService.StopBalanceMonitor()
+	}
 
 	log.Println("✅ Service Container cleaned up")
 }
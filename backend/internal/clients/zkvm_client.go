@@ -2,6 +2,7 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -50,6 +51,27 @@ func NewZKVMClient(baseURL string) *ZKVMClient {
 	return client
 }
 
+// Ping checks that the ZKVM service is reachable via GET /health
+// The request is bound to ctx, so callers control the timeout (the client timeout is sized for proof generation)
+func (c *ZKVMClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to ZKVM service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ZKVM health check returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
 // BuildCommitmentRequest Build commitment request - Based on latest API format
 // Updated to use token_key instead of token_id per latest ZKVM service API
 type BuildCommitmentRequest struct {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
@@ -43,6 +44,11 @@ type Config struct {
 	Admin      AdminConfig        `yaml:"admin"`      // Admin API access control configuration
 	Subgraph   SubgraphConfig     `yaml:"subgraph"`   // Subgraph sync configuration
 	Statistics StatisticsConfig   `yaml:"statistics"` // Statistics API configuration
+	Withdraw   WithdrawConfig     `yaml:"withdraw"`   // Withdraw request retry limits
+	Logging    LoggingConfig      `yaml:"logging"`    // Service logging configuration
+	Polling    PollingConfig      `yaml:"polling"`    // Polling task worker pool
+	WebSocket  WebSocketConfig    `yaml:"websocket"`  // WebSocket push heartbeat
+	JWT        JWTConfig          `yaml:"jwt"`        // User JWT signing and validation
 }
 
 // ServerConfig server configuration
@@ -65,6 +71,8 @@ type NATSConfig struct {
 	MaxReconnects   int                     `yaml:"max_reconnects"`
 	EnableJetStream bool                    `yaml:"enable_jetstream"`
 	Subscriptions   NATSSubscriptionsConfig `yaml:"subscriptions"`
+	// MaxProcessAttempts processing attempts per message before it is dead-lettered into failed_events
+	MaxProcessAttempts int `yaml:"max_process_attempts"`
 }
 
 // NATSSubscriptionsConfig NATSSubscription configuration
@@ -95,6 +103,20 @@ type BlockchainConfig struct {
 	// Global ZKPay contract address (same for all chains)
 	ZKPayProxy string `yaml:"zkpay_proxy"` // Global ZKPay Proxy contract address
 
+	// Management chain (SLIP-44) where commitments and withdraws are submitted, default 714 (BSC)
+	ManagementChainID int `yaml:"management_chain_id"`
+
+	// Submission routing per transaction type (see GetSubmitChainRouting)
+	CommitmentSubmitChain string `yaml:"commitment_submit_chain"` // "management" (default) | "deposit": the deposit's own chain
+	WithdrawSubmitChain   string `yaml:"withdraw_submit_chain"`   // "management" (default) | "beneficiary": the withdraw's target chain
+
+	// SLIP-44 chains whose events are processed; events of other chains are ignored (empty = every chain)
+	SupportedChains []int `yaml:"supported_chains"`
+
+	// Signer balance monitor, alerting below each network's lowBalanceThresholdWei
+	BalanceCheckInterval int    `yaml:"balance_check_interval"`  // Seconds between signer balance checks (default 300)
+	LowBalanceWebhookURL string `yaml:"low_balance_webhook_url"` // Optional URL a JSON alert is POSTed to (empty = log only)
+
 	Networks map[string]NetworkConfig `yaml:"networks"`
 }
 
@@ -108,6 +130,20 @@ type TokenConfig struct {
 type TokenDecimalConfig struct {
 	ManagementDecimals int                 `yaml:"managementDecimals"` // Management chain decimals (fixed at 18)
 	ChainDecimals      map[int]map[int]int `yaml:"chainDecimals"`      // Decimals for each token on each chain chainId->tokenId->decimals
+
+	// Decimals by token address chainId->token address->decimals (DepositReceived only carries the token address)
+	TokenAddressDecimals map[int]map[string]int `yaml:"tokenAddressDecimals"`
+
+	// Token keys (e.g. "USDT") whose keccak256 is mapped back in DepositRecorded, on top of the built-in ones
+	TokenKeys []string `yaml:"tokenKeys"`
+}
+
+// GetTokenKeys Get the configured token keys
+func GetTokenKeys() []string {
+	if AppConfig == nil {
+		return nil
+	}
+	return AppConfig.Tokens.TokenKeys
 }
 
 // NetworkConfig NetworkConfiguration
@@ -138,6 +174,31 @@ type NetworkConfig struct {
 	TokenConfigs      map[string]TokenConfig `yaml:"tokenConfigs"`      // token configuration mapping
 	ContractAddresses map[string]string      `yaml:"contractAddresses"` // Contract address mapping
 	Enabled           bool                   `yaml:"enabled"`
+
+	// Auto gas price (used when gasPrice is empty or "auto")
+	GasPriceMultiplier   float64 `yaml:"gasPriceMultiplier"`   // Multiplier on the suggested gas price (default 1.2)
+	GasPriceMaxGwei      float64 `yaml:"gasPriceMaxGwei"`      // Ceiling in Gwei, sending is rejected above it (0 = no ceiling)
+	GasPriceFallbackGwei float64 `yaml:"gasPriceFallbackGwei"` // Used when the suggested gas price cannot be fetched (default 5)
+
+	// Blocks required on top of a transaction's block before it counts as final (default 1)
+	Confirmations int `yaml:"confirmations"`
+
+	// Recent blocks in which confirmed executeWithdraw transactions are re-checked for reorgs (default 64)
+	ReorgWindowBlocks int `yaml:"reorgWindowBlocks"`
+
+	// Blocks a missing executeWithdraw receipt must stay missing, while the transaction is still known to the node,
+	// before it counts as reorged (default 12)
+	ReorgMissingBlocks int `yaml:"reorgMissingBlocks"`
+
+	// ZKPay contract ABI version executeWithdraw / executeCommitment are encoded for (0 = latest)
+	ContractABIVersion int `yaml:"contractAbiVersion"`
+
+	// Signer balance (wei) below which a low-balance alert fires (empty = not monitored)
+	LowBalanceThresholdWei string `yaml:"lowBalanceThresholdWei"`
+
+	// EVM chain ID the RPC must report before transactions are submitted to this network
+	// (0 = derived from chainId via the SLIP-44 mapping)
+	EVMChainID int `yaml:"evmChainId"`
 }
 
 // ZKVMConfig ZKVMservice configuration
@@ -192,6 +253,80 @@ type StatisticsConfig struct {
 	WhitelistIPs []string `yaml:"whitelistIPs"` // List of IP addresses or CIDR ranges allowed to access statistics without JWT
 }
 
+// WithdrawConfig Withdraw request configuration
+type WithdrawConfig struct {
+	MaxPayoutRetries   int `yaml:"max_payout_retries"`   // Max payout retries (default 5)
+	MaxHookRetries     int `yaml:"max_hook_retries"`     // Max hook purchase retries (default 5)
+	MaxFallbackRetries int `yaml:"max_fallback_retries"` // Max fallback transfer retries (default 5)
+
+	// executeWithdraw confirmation
+	QuickCheckDelays      []int `yaml:"quick_check_delays"`       // Receipt quick-check delays in seconds (default [2, 5, 10], [] = skip quick check)
+	ExecutePollMaxRetries int   `yaml:"execute_poll_max_retries"` // Polling task max retries (default 180)
+	ExecutePollInterval   int   `yaml:"execute_poll_interval"`    // Polling task interval in seconds (default 10)
+
+	// ExecuteSimulateFirst simulates executeWithdraw via eth_call before sending; a revert marks the request
+	// verify_failed without spending gas (default false)
+	ExecuteSimulateFirst bool `yaml:"execute_simulate_first"`
+
+	// Size caps on a single withdraw, each allocation and checkbook adds proof input (ZKVM limits)
+	MaxAllocationsPerWithdraw int `yaml:"max_allocations_per_withdraw"` // Max allocations per withdraw request (default 50)
+	MaxCheckbooksPerWithdraw  int `yaml:"max_checkbooks_per_withdraw"`  // Max distinct checkbooks (CommitmentGroups) per withdraw request (default 10)
+
+	// StrictNullifierCheck fails proof generation when the nullifiers returned by ZKVM differ from the allocations'
+	// nullifiers in the DB, instead of saving a proof whose on-chain events will not match (default false)
+	StrictNullifierCheck bool `yaml:"strict_nullifier_check"`
+
+	// WithdrawRequestTTL seconds a request may wait for its proof before it is cancelled and its allocations
+	// released (default 1800, negative = never expire)
+	WithdrawRequestTTL int `yaml:"withdraw_request_ttl"`
+
+	// MatchByDeprecatedRequestID also matches withdraw events to requests and checks by the deprecated request_id
+	// when withdraw_nullifier / check nullifier find nothing. Only for migrating data created before
+	// withdraw_nullifier existed (default false)
+	MatchByDeprecatedRequestID bool `yaml:"match_by_deprecated_request_id"`
+
+	// ProofGenerationTimeout seconds an async withdraw proof task may spend processing (from when a worker picked
+	// it up) before it and its request are failed and the allocations released (default 900, negative = never)
+	ProofGenerationTimeout int `yaml:"proof_generation_timeout"`
+
+	// CreateRequestsPerMinute withdraw requests one owner may create per minute, further creates are rejected
+	// until the owner's token bucket refills (default 10, negative = unlimited)
+	CreateRequestsPerMinute int `yaml:"create_requests_per_minute"`
+
+	// DefaultHookEnabled gives withdraw requests whose Intent carries no hookCalldata the DefaultHookCalldata hook,
+	// e.g. a deployment that always routes payouts through a yield hook (default false)
+	DefaultHookEnabled bool `yaml:"default_hook_enabled"`
+
+	// DefaultHookCalldata 0x-prefixed hook calldata used when DefaultHookEnabled, checked by ValidateWithdrawConfig
+	DefaultHookCalldata string `yaml:"default_hook_calldata"`
+}
+
+// PollingConfig Polling task worker pool configuration
+type PollingConfig struct {
+	MaxConcurrent        int            `yaml:"max_concurrent"`          // Max polling tasks executed at once (default 10)
+	MaxConcurrentPerType map[string]int `yaml:"max_concurrent_per_type"` // Cap per task type, e.g. withdraw_execute: 4 (unset = max_concurrent)
+}
+
+// WebSocketConfig WebSocket push heartbeat configuration
+type WebSocketConfig struct {
+	PingInterval int `yaml:"pingInterval"` // Seconds between protocol-level pings (default 54)
+	PongWait     int `yaml:"pongWait"`     // Seconds without a pong before a connection is reaped (default 60)
+}
+
+// JWTConfig user JWT configuration (admin tokens use ADMIN_JWT_SECRET)
+type JWTConfig struct {
+	Secret            string `yaml:"secret"`            // HMAC signing secret, must be set outside development
+	Issuer            string `yaml:"issuer"`            // iss claim issued and required (default "zkpay-backend")
+	Audience          string `yaml:"audience"`          // aud claim issued and required (empty = not checked)
+	AccessTokenExpiry int    `yaml:"accessTokenExpiry"` // Token TTL in seconds (default 86400)
+	AllowDevSecret    bool   `yaml:"allowDevSecret"`    // Accept a placeholder secret, local development only (never with GIN_MODE=release)
+}
+
+// LoggingConfig Logging configuration
+type LoggingConfig struct {
+	Format string `yaml:"format"` // "text" (default, human-readable) or "json" (structured, for log aggregators)
+}
+
 var AppConfig *Config
 
 // LoadConfig Load configuration file
@@ -354,6 +489,18 @@ func overrideFromEnv(config *Config) {
 		}
 	}
 
+	// Management chain configuration
+	if managementChainID := os.Getenv("MANAGEMENT_CHAIN_ID"); managementChainID != "" {
+		if id, err := strconv.Atoi(managementChainID); err == nil {
+			config.Blockchain.ManagementChainID = id
+		}
+	}
+
+	// Logging configuration
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
+		config.Logging.Format = logFormat
+	}
+
 	// blockchainNetworkconfiguration
 	for networkName, networkConfig := range config.Blockchain.Networks {
 		// KMSconfigurationRead
@@ -480,6 +627,25 @@ func overrideFromEnv(config *Config) {
 		}
 	}
 
+	// JWT Configuration
+	if jwtSecret := os.Getenv("JWT_SECRET"); jwtSecret != "" {
+		config.JWT.Secret = jwtSecret
+	}
+	if jwtIssuer := os.Getenv("JWT_ISSUER"); jwtIssuer != "" {
+		config.JWT.Issuer = jwtIssuer
+	}
+	if jwtAudience := os.Getenv("JWT_AUDIENCE"); jwtAudience != "" {
+		config.JWT.Audience = jwtAudience
+	}
+	if jwtExpiry := os.Getenv("JWT_ACCESS_TOKEN_EXPIRY"); jwtExpiry != "" {
+		if expiry, err := strconv.Atoi(jwtExpiry); err == nil {
+			config.JWT.AccessTokenExpiry = expiry
+		}
+	}
+	if allowDevSecret := os.Getenv("JWT_ALLOW_DEV_SECRET"); allowDevSecret != "" {
+		config.JWT.AllowDevSecret = allowDevSecret == "true"
+	}
+
 	// KYT Oracle Configuration
 	if kytOracleURL := os.Getenv("KYT_ORACLE_BASE_URL"); kytOracleURL != "" {
 		config.KYTOracle.BaseURL = kytOracleURL
@@ -504,6 +670,330 @@ func GetNetworkConfig(networkName string) (*NetworkConfig, error) {
 	return &network, nil
 }
 
+// DefaultManagementChainID Default management chain (SLIP-44 714 = BSC)
+const DefaultManagementChainID = 714
+
+// GetManagementChainID Get management chain SLIP-44 ID - falls back to DefaultManagementChainID
+func GetManagementChainID() int {
+	if AppConfig == nil || AppConfig.Blockchain.ManagementChainID <= 0 {
+		return DefaultManagementChainID
+	}
+	return AppConfig.Blockchain.ManagementChainID
+}
+
+// IsChainSupported reports whether events of a SLIP-44 chain are processed (blockchain.supported_chains)
+// An empty or unset list supports every chain.
+func IsChainSupported(chainID int) bool {
+	if AppConfig == nil || len(AppConfig.Blockchain.SupportedChains) == 0 {
+		return true
+	}
+	for _, supported := range AppConfig.Blockchain.SupportedChains {
+		if supported == chainID {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultBalanceCheckInterval Default seconds between signer balance checks
+const DefaultBalanceCheckInterval = 300
+
+// GetBalanceCheckInterval Get the interval of the signer balance monitor - defaults to 5 minutes
+func GetBalanceCheckInterval() time.Duration {
+	if AppConfig == nil || AppConfig.Blockchain.BalanceCheckInterval <= 0 {
+		return DefaultBalanceCheckInterval * time.Second
+	}
+	return time.Duration(AppConfig.Blockchain.BalanceCheckInterval) * time.Second
+}
+
+// Submission chain routing values
+const (
+	SubmitChainManagement  = "management"  // always the management chain
+	SubmitChainDeposit     = "deposit"     // commitments: the chain the deposit was made on
+	SubmitChainBeneficiary = "beneficiary" // withdraws: the beneficiary (target) chain
+)
+
+// GetSubmitChainRouting Get commitment and withdraw submission routing - unset or unknown values fall back to "management"
+func GetSubmitChainRouting() (commitment string, withdraw string) {
+	commitment, withdraw = SubmitChainManagement, SubmitChainManagement
+	if AppConfig == nil {
+		return
+	}
+	switch value := strings.ToLower(AppConfig.Blockchain.CommitmentSubmitChain); value {
+	case SubmitChainDeposit:
+		commitment = value
+	case "", SubmitChainManagement:
+	default:
+		log.Printf("⚠️ Unknown commitment_submit_chain %q, using %q", value, SubmitChainManagement)
+	}
+	switch value := strings.ToLower(AppConfig.Blockchain.WithdrawSubmitChain); value {
+	case SubmitChainBeneficiary:
+		withdraw = value
+	case "", SubmitChainManagement:
+	default:
+		log.Printf("⚠️ Unknown withdraw_submit_chain %q, using %q", value, SubmitChainManagement)
+	}
+	return
+}
+
+// GetLogFormat Get log format ("text" or "json") - defaults to "text"
+func GetLogFormat() string {
+	if AppConfig == nil || AppConfig.Logging.Format == "" {
+		return "text"
+	}
+	return AppConfig.Logging.Format
+}
+
+// Default auto gas price settings (per network)
+const (
+	DefaultGasPriceMultiplier   = 1.2
+	DefaultGasPriceFallbackGwei = 5.0
+)
+
+// GetGasPriceMultiplier Get the suggested gas price multiplier - defaults to 1.2
+func (n *NetworkConfig) GetGasPriceMultiplier() float64 {
+	if n.GasPriceMultiplier <= 0 {
+		return DefaultGasPriceMultiplier
+	}
+	return n.GasPriceMultiplier
+}
+
+// GetGasPriceFallbackGwei Get the fallback gas price (Gwei) - defaults to 5
+func (n *NetworkConfig) GetGasPriceFallbackGwei() float64 {
+	if n.GasPriceFallbackGwei <= 0 {
+		return DefaultGasPriceFallbackGwei
+	}
+	return n.GasPriceFallbackGwei
+}
+
+// DefaultConfirmations Default confirmation depth (per network)
+const DefaultConfirmations = 1
+
+// GetConfirmations Get the confirmation depth required before a transaction counts as final - defaults to 1
+func (n *NetworkConfig) GetConfirmations() uint64 {
+	if n.Confirmations <= 0 {
+		return DefaultConfirmations
+	}
+	return uint64(n.Confirmations)
+}
+
+// DefaultReorgWindowBlocks Default reorg re-check window (per network)
+const DefaultReorgWindowBlocks = 64
+
+// GetReorgWindowBlocks Get how many recent blocks are re-checked for reorged executeWithdraw transactions - defaults to 64
+func (n *NetworkConfig) GetReorgWindowBlocks() uint64 {
+	if n.ReorgWindowBlocks <= 0 {
+		return DefaultReorgWindowBlocks
+	}
+	return uint64(n.ReorgWindowBlocks)
+}
+
+// DefaultReorgMissingBlocks Default number of blocks a receipt may stay missing before it counts as reorged (per network)
+const DefaultReorgMissingBlocks = 12
+
+// GetReorgMissingBlocks Get how many blocks a missing receipt is waited for while its transaction is still pending - defaults to 12
+func (n *NetworkConfig) GetReorgMissingBlocks() uint64 {
+	if n.ReorgMissingBlocks <= 0 {
+		return DefaultReorgMissingBlocks
+	}
+	return uint64(n.ReorgMissingBlocks)
+}
+
+// DefaultMaxWithdrawRetries Default retry cap for each withdraw stage (payout, hook, fallback)
+const DefaultMaxWithdrawRetries = 5
+
+// Default executeWithdraw confirmation settings
+const (
+	DefaultExecutePollMaxRetries = 180 // 30 minutes (180 * 10 seconds)
+	DefaultExecutePollInterval   = 10  // seconds
+)
+
+// DefaultWithdrawRequestTTL Default seconds a withdraw request may wait for its proof before it expires
+const DefaultWithdrawRequestTTL = 30 * 60
+
+// DefaultProofGenerationTimeout Default seconds an async withdraw proof task may take before it is failed
+const DefaultProofGenerationTimeout = 15 * 60
+
+// DefaultCreateRequestsPerMinute Default withdraw requests one owner may create per minute
+const DefaultCreateRequestsPerMinute = 10
+
+// Default withdraw size caps
+const (
+	DefaultMaxAllocationsPerWithdraw = 50
+	DefaultMaxCheckbooksPerWithdraw  = 10
+)
+
+// DefaultQuickCheckDelays Default executeWithdraw receipt quick-check delays (seconds)
+var DefaultQuickCheckDelays = []int{2, 5, 10}
+
+// GetWithdrawConfig Get withdraw configuration - unset (zero) values fall back to defaults
+func GetWithdrawConfig() WithdrawConfig {
+	cfg := WithdrawConfig{}
+	if AppConfig != nil {
+		cfg = AppConfig.Withdraw
+	}
+	if cfg.MaxPayoutRetries == 0 {
+		cfg.MaxPayoutRetries = DefaultMaxWithdrawRetries
+	}
+	if cfg.MaxHookRetries == 0 {
+		cfg.MaxHookRetries = DefaultMaxWithdrawRetries
+	}
+	if cfg.MaxFallbackRetries == 0 {
+		cfg.MaxFallbackRetries = DefaultMaxWithdrawRetries
+	}
+	if cfg.QuickCheckDelays == nil {
+		cfg.QuickCheckDelays = DefaultQuickCheckDelays
+	}
+	if cfg.ExecutePollMaxRetries <= 0 {
+		cfg.ExecutePollMaxRetries = DefaultExecutePollMaxRetries
+	}
+	if cfg.ExecutePollInterval <= 0 {
+		cfg.ExecutePollInterval = DefaultExecutePollInterval
+	}
+	if cfg.MaxAllocationsPerWithdraw <= 0 {
+		cfg.MaxAllocationsPerWithdraw = DefaultMaxAllocationsPerWithdraw
+	}
+	if cfg.MaxCheckbooksPerWithdraw <= 0 {
+		cfg.MaxCheckbooksPerWithdraw = DefaultMaxCheckbooksPerWithdraw
+	}
+	if cfg.WithdrawRequestTTL == 0 {
+		cfg.WithdrawRequestTTL = DefaultWithdrawRequestTTL
+	}
+	if cfg.ProofGenerationTimeout == 0 {
+		cfg.ProofGenerationTimeout = DefaultProofGenerationTimeout
+	}
+	if cfg.CreateRequestsPerMinute == 0 {
+		cfg.CreateRequestsPerMinute = DefaultCreateRequestsPerMinute
+	}
+	return cfg
+}
+
+// DefaultPollingMaxConcurrent Default number of polling tasks executed at once
+const DefaultPollingMaxConcurrent = 10
+
+// GetPollingConfig Get polling worker pool configuration - unset values fall back to defaults
+func GetPollingConfig() PollingConfig {
+	cfg := PollingConfig{}
+	if AppConfig != nil {
+		cfg = AppConfig.Polling
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultPollingMaxConcurrent
+	}
+	return cfg
+}
+
+// DefaultNATSMaxProcessAttempts default processing attempts per NATS message before dead-lettering
+const DefaultNATSMaxProcessAttempts = 3
+
+// GetNATSMaxProcessAttempts Get processing attempts per NATS message - falls back to the default when unset
+func GetNATSMaxProcessAttempts() int {
+	if AppConfig != nil && AppConfig.NATS.MaxProcessAttempts > 0 {
+		return AppConfig.NATS.MaxProcessAttempts
+	}
+	return DefaultNATSMaxProcessAttempts
+}
+
+// Default WebSocket heartbeat settings (seconds)
+const (
+	DefaultWebSocketPingInterval = 54
+	DefaultWebSocketPongWait     = 60
+)
+
+// GetWebSocketConfig Get WebSocket heartbeat configuration - unset values fall back to defaults
+// pongWait is raised above pingInterval so a live client always has time to answer.
+func GetWebSocketConfig() WebSocketConfig {
+	cfg := WebSocketConfig{}
+	if AppConfig != nil {
+		cfg = AppConfig.WebSocket
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = DefaultWebSocketPingInterval
+	}
+	if cfg.PongWait <= 0 {
+		cfg.PongWait = DefaultWebSocketPongWait
+	}
+	if cfg.PongWait <= cfg.PingInterval {
+		cfg.PongWait = cfg.PingInterval + cfg.PingInterval/2 + 1
+	}
+	return cfg
+}
+
+// Default user JWT settings
+const (
+	// DefaultJWTSecret development placeholder, refused by ValidateJWTConfig unless jwt.allowDevSecret is set
+	DefaultJWTSecret            = "zkpay-jwt-secret-key-2025"
+	DefaultJWTIssuer            = "zkpay-backend"
+	DefaultJWTAccessTokenExpiry = 24 * 60 * 60
+)
+
+// GetJWTConfig Get user JWT configuration - unset values fall back to defaults
+func GetJWTConfig() JWTConfig {
+	cfg := JWTConfig{}
+	if AppConfig != nil {
+		cfg = AppConfig.JWT
+	}
+	if cfg.Secret == "" {
+		cfg.Secret = DefaultJWTSecret
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = DefaultJWTIssuer
+	}
+	if cfg.AccessTokenExpiry <= 0 {
+		cfg.AccessTokenExpiry = DefaultJWTAccessTokenExpiry
+	}
+	return cfg
+}
+
+// TokenTTL Get user JWT lifetime
+func (c JWTConfig) TokenTTL() time.Duration {
+	return time.Duration(c.AccessTokenExpiry) * time.Second
+}
+
+// jwtPlaceholderSecrets the built-in default and the secrets shipped in the example config files
+var jwtPlaceholderSecrets = map[string]bool{
+	DefaultJWTSecret: true,
+	"your-secret-key-change-this-in-production":    true,
+	"change-this-to-a-random-secret-in-production": true,
+}
+
+// ValidateJWTConfig refuses a placeholder JWT secret unless jwt.allowDevSecret (JWT_ALLOW_DEV_SECRET=true) is set,
+// and refuses that flag in release mode (GIN_MODE=release)
+func ValidateJWTConfig() error {
+	cfg := GetJWTConfig()
+	if cfg.AllowDevSecret && os.Getenv("GIN_MODE") == "release" {
+		return fmt.Errorf("jwt.allowDevSecret (or JWT_ALLOW_DEV_SECRET) must not be set in release mode")
+	}
+	if jwtPlaceholderSecrets[cfg.Secret] && !cfg.AllowDevSecret {
+		return fmt.Errorf("jwt.secret (or JWT_SECRET) must be set to a real secret, the placeholder secret is only allowed with jwt.allowDevSecret")
+	}
+	return nil
+}
+
+// ValidateWithdrawConfig refuses retry caps below 1 and an enabled default hook whose calldata is not
+// non-empty 0x-prefixed hex
+func ValidateWithdrawConfig() error {
+	cfg := GetWithdrawConfig()
+	for name, limit := range map[string]int{
+		"max_payout_retries":   cfg.MaxPayoutRetries,
+		"max_hook_retries":     cfg.MaxHookRetries,
+		"max_fallback_retries": cfg.MaxFallbackRetries,
+	} {
+		if limit < 1 {
+			return fmt.Errorf("withdraw.%s must be >= 1, got %d", name, limit)
+		}
+	}
+	if !cfg.DefaultHookEnabled {
+		return nil
+	}
+	calldata := strings.TrimSpace(cfg.DefaultHookCalldata)
+	raw, hasPrefix := strings.CutPrefix(strings.ToLower(calldata), "0x")
+	if decoded, err := hex.DecodeString(raw); !hasPrefix || err != nil || len(decoded) == 0 {
+		return fmt.Errorf("withdraw.default_hook_calldata must be 0x-prefixed hex bytes when default_hook_enabled is set, got %q", calldata)
+	}
+	return nil
+}
+
 // GetNetworkConfigByChainID chain IDGetNetworkconfiguration
 func GetNetworkConfigByChainID(chainID int) (*NetworkConfig, error) {
 	if AppConfig == nil {
@@ -0,0 +1,115 @@
+package config
+
+import "testing"
+
+func TestGetManagementChainID(t *testing.T) {
+	previous := AppConfig
+	t.Cleanup(func() { AppConfig = previous })
+
+	AppConfig = nil
+	if got := GetManagementChainID(); got != DefaultManagementChainID {
+		t.Errorf("without config = %d, want default %d", got, DefaultManagementChainID)
+	}
+
+	AppConfig = &Config{}
+	if got := GetManagementChainID(); got != DefaultManagementChainID {
+		t.Errorf("unset management_chain_id = %d, want default %d", got, DefaultManagementChainID)
+	}
+
+	AppConfig = &Config{Blockchain: BlockchainConfig{ManagementChainID: 60}}
+	if got := GetManagementChainID(); got != 60 {
+		t.Errorf("configured management_chain_id = %d, want 60", got)
+	}
+
+	t.Setenv("MANAGEMENT_CHAIN_ID", "195")
+	overrideFromEnv(AppConfig)
+	if got := GetManagementChainID(); got != 195 {
+		t.Errorf("MANAGEMENT_CHAIN_ID override = %d, want 195", got)
+	}
+}
+
+func TestValidateJWTConfig(t *testing.T) {
+	previous := AppConfig
+	t.Cleanup(func() { AppConfig = previous })
+	t.Setenv("GIN_MODE", "")
+
+	tests := []struct {
+		name    string
+		release bool
+		jwt     JWTConfig
+		wantErr bool
+	}{
+		{"configured secret", false, JWTConfig{Secret: "3f9c1e7a-configured-secret"}, false},
+		{"configured secret in release mode", true, JWTConfig{Secret: "3f9c1e7a-configured-secret"}, false},
+		{"unset secret falls back to the default", false, JWTConfig{}, true},
+		{"default secret", false, JWTConfig{Secret: DefaultJWTSecret}, true},
+		{"example config secret", false, JWTConfig{Secret: "your-secret-key-change-this-in-production"}, true},
+		{"example env secret", false, JWTConfig{Secret: "change-this-to-a-random-secret-in-production"}, true},
+		{"default secret with dev flag", false, JWTConfig{AllowDevSecret: true}, false},
+		{"dev flag in release mode", true, JWTConfig{AllowDevSecret: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.release {
+				t.Setenv("GIN_MODE", "release")
+			}
+			AppConfig = &Config{JWT: tt.jwt}
+			if err := ValidateJWTConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJWTConfig() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsChainSupported(t *testing.T) {
+	previous := AppConfig
+	t.Cleanup(func() { AppConfig = previous })
+
+	AppConfig = nil
+	if !IsChainSupported(714) {
+		t.Error("without config, chain 714 unsupported, want every chain supported")
+	}
+
+	AppConfig = &Config{}
+	if !IsChainSupported(714) {
+		t.Error("empty supported_chains, chain 714 unsupported, want every chain supported")
+	}
+
+	AppConfig = &Config{Blockchain: BlockchainConfig{SupportedChains: []int{60, 195}}}
+	if !IsChainSupported(195) {
+		t.Error("chain 195 unsupported, want supported (listed)")
+	}
+	if IsChainSupported(714) {
+		t.Error("chain 714 supported, want unsupported (not listed)")
+	}
+}
+
+func TestValidateWithdrawConfig(t *testing.T) {
+	previous := AppConfig
+	t.Cleanup(func() { AppConfig = previous })
+
+	tests := []struct {
+		name     string
+		withdraw WithdrawConfig
+		wantErr  bool
+	}{
+		{"default hook disabled", WithdrawConfig{DefaultHookCalldata: "not hex"}, false},
+		{"valid calldata", WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "0xABCD"}, false},
+		{"missing calldata", WithdrawConfig{DefaultHookEnabled: true}, true},
+		{"missing 0x prefix", WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "abcd"}, true},
+		{"not hex", WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "0xzz"}, true},
+		{"odd length", WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "0xabc"}, true},
+		{"unset retry caps use the default", WithdrawConfig{}, false},
+		{"negative payout retries", WithdrawConfig{MaxPayoutRetries: -1}, true},
+		{"negative hook retries", WithdrawConfig{MaxHookRetries: -1}, true},
+		{"negative fallback retries", WithdrawConfig{MaxFallbackRetries: -3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AppConfig = &Config{Withdraw: tt.withdraw}
+			if err := ValidateWithdrawConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWithdrawConfig() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
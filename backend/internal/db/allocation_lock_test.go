@@ -0,0 +1,74 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// createIdleAllocations stores idle allocations of one checkbook with the given IDs
+func createIdleAllocations(t *testing.T, database *gorm.DB, ids ...string) {
+	t.Helper()
+	for i, id := range ids {
+		allocation := models.Check{
+			ID:          id,
+			CheckbookID: "cb-lock",
+			Seq:         uint8(i),
+			Amount:      "100",
+			Status:      models.AllocationStatusIdle,
+			Nullifier:   "0xnullifier-" + id,
+		}
+		if err := database.Create(&allocation).Error; err != nil {
+			t.Fatalf("create allocation %s: %v", id, err)
+		}
+	}
+}
+
+func assertAllocationLock(t *testing.T, database *gorm.DB, id string, status models.AllocationStatus, requestID string) {
+	t.Helper()
+	var allocation models.Check
+	if err := database.First(&allocation, "id = ?", id).Error; err != nil {
+		t.Fatalf("load allocation %s: %v", id, err)
+	}
+	locker := ""
+	if allocation.WithdrawRequestID != nil {
+		locker = *allocation.WithdrawRequestID
+	}
+	if allocation.Status != status || locker != requestID {
+		t.Errorf("allocation %s: status=%s request=%q, want status=%s request=%q", id, allocation.Status, locker, status, requestID)
+	}
+}
+
+func TestLockForWithdrawal(t *testing.T) {
+	database := dbtest.Open(t)
+	createIdleAllocations(t, database, "a1", "a2", "a3")
+	repo := repository.NewAllocationRepository(database)
+	ctx := context.Background()
+
+	// Clean lock: idle allocations become pending for the request
+	if err := repo.LockForWithdrawal(ctx, []string{"a1", "a2"}, "req-1"); err != nil {
+		t.Fatalf("clean lock: %v", err)
+	}
+	assertAllocationLock(t, database, "a1", models.AllocationStatusPending, "req-1")
+	assertAllocationLock(t, database, "a2", models.AllocationStatusPending, "req-1")
+
+	// Same-request re-lock: a retried create succeeds over its own earlier lock
+	if err := repo.LockForWithdrawal(ctx, []string{"a1", "a2"}, "req-1"); err != nil {
+		t.Fatalf("re-lock by the same request: %v", err)
+	}
+	assertAllocationLock(t, database, "a1", models.AllocationStatusPending, "req-1")
+
+	// Foreign-request conflict: nothing is locked, not even the idle allocation in the batch
+	err := repo.LockForWithdrawal(ctx, []string{"a3", "a2"}, "req-2")
+	if !errors.Is(err, repository.ErrAllocationLockConflict) {
+		t.Fatalf("lock by another request: err = %v, want ErrAllocationLockConflict", err)
+	}
+	assertAllocationLock(t, database, "a2", models.AllocationStatusPending, "req-1")
+	assertAllocationLock(t, database, "a3", models.AllocationStatusIdle, "")
+}
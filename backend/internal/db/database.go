@@ -26,14 +26,7 @@ func InitDB() {
 	dsn := config.AppConfig.Database.DSN
 	log.Printf("Connecting to database: %s", dsn)
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		DisableForeignKeyConstraintWhenMigrating: true,
-		SkipDefaultTransaction:                   true,
-		DisableAutomaticPing:                     true,
-		PrepareStmt:                              true,
-		CreateBatchSize:                          1000,
-		Logger:                                   logger.Default.LogMode(logger.Silent),
-	})
+	DB, err = Open(dsn)
 	if err != nil {
 		log.Fatalf("Failed to connect database: %v", err)
 	}
@@ -58,10 +51,46 @@ func InitDB() {
 		log.Println("⚠️ Attempting to continue with migration anyway...")
 	}
 
+	// checkbooks.gross_amount becomes NOT NULL DEFAULT '0' (migration 000051), backfill before AutoMigrate applies it
+	if err := fixEmptyCheckbookGrossAmounts(DB); err != nil {
+		log.Printf("⚠️ Failed to backfill empty checkbook gross_amount values: %v", err)
+	}
+
 	// Auto migrate all models
 	log.Println("🚀 Starting database schema migration with GORM AutoMigrate...")
 
-	if err := DB.AutoMigrate(
+	if err := Migrate(DB); err != nil {
+		log.Fatalf("AutoMigrate failed: %v", err)
+	}
+
+	// Initialize default global config if not exists
+	initGlobalConfig(DB)
+
+	log.Println("✅ Database schema migrated successfully")
+}
+
+// Open connects to dsn with the application's gorm settings and callbacks (optimistic locking)
+func Open(dsn string) (*gorm.DB, error) {
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		SkipDefaultTransaction:                   true,
+		DisableAutomaticPing:                     true,
+		PrepareStmt:                              true,
+		CreateBatchSize:                          1000,
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := registerOptimisticLock(database); err != nil {
+		return nil, fmt.Errorf("failed to register optimistic lock callback: %w", err)
+	}
+	return database, nil
+}
+
+// Migrate creates or updates the schema of every model, plus the indexes gorm tags cannot express
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
 		&models.EventDepositReceived{},
 		&models.EventDepositRecorded{},
 		&models.EventDepositUsed{},
@@ -88,14 +117,27 @@ func InitDB() {
 		&models.PendingTransaction{},          // Transaction queue table
 		&models.ProofGenerationTask{},         // Proof generation task table
 		&models.WithdrawProofGenerationTask{}, // Withdraw proof generation task table
+		&models.StatusTransition{},            // Status transition audit log
+		&models.FailedEvent{},                 // Dead-lettered NATS events
+		&models.TokenDecimalsOverride{},       // On-chain token decimals overriding the config
 	); err != nil {
-		log.Fatalf("AutoMigrate failed: %v", err)
+		return err
 	}
+	return ensureWithdrawRequestIdempotencyIndex(db)
+}
 
-	// Initialize default global config if not exists
-	initGlobalConfig(DB)
-
-	log.Println("✅ Database schema migrated successfully")
+// ensureWithdrawRequestIdempotencyIndex makes idempotency keys unique per owner (migration 000060)
+// Replaces the global idx_withdraw_requests_idempotency_key; built here because gorm tags cannot put the embedded
+// owner columns into the index.
+func ensureWithdrawRequestIdempotencyIndex(db *gorm.DB) error {
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_withdraw_requests_idempotency_key`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_withdraw_requests_owner_idempotency_key
+		ON withdraw_requests(owner_chain_id, owner_data, idempotency_key)
+		WHERE deleted_at IS NULL AND idempotency_key IS NOT NULL
+	`).Error
 }
 
 // initGlobalConfig initializes default global configuration if not exists
@@ -310,6 +352,21 @@ func fixNullTokenKeys(db *gorm.DB) error {
 	return nil
 }
 
+// fixEmptyCheckbookGrossAmounts sets NULL or empty checkbooks.gross_amount to '0'
+func fixEmptyCheckbookGrossAmounts(db *gorm.DB) error {
+	if !db.Migrator().HasTable("checkbooks") {
+		return nil
+	}
+	result := db.Exec(`UPDATE checkbooks SET gross_amount = '0' WHERE gross_amount IS NULL OR gross_amount = ''`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ Backfilled %d empty checkbook gross_amount values with '0'", result.RowsAffected)
+	}
+	return nil
+}
+
 // fixTokenKeyColumn fixes token_key column size to VARCHAR(50) for original tokenKey strings
 func fixTokenKeyColumn(db *gorm.DB, tableName, columnName, comment string) error {
 	// Check if table exists
@@ -0,0 +1,68 @@
+// Package dbtest opens throwaway Postgres schemas for tests that need a real database
+package dbtest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// DSNEnv names the environment variable holding the Postgres DSN tests run against
+const DSNEnv = "TEST_DATABASE_DSN"
+
+// Open returns a connection to a fresh, fully migrated schema, dropped when the test ends
+// The test is skipped when TEST_DATABASE_DSN is not set.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set, skipping test against Postgres", DSNEnv)
+	}
+
+	admin, err := db.Open(dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec(`CREATE SCHEMA ` + schema).Error; err != nil {
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+
+	database, err := db.Open(withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("failed to connect to schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("failed to migrate schema %s: %v", schema, err)
+	}
+	return database
+}
+
+// withSearchPath adds search_path to a URL (postgres://...) or key=value DSN
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		return dsn + separator + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
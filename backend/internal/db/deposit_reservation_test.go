@@ -0,0 +1,62 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestConcurrentReservationsCannotOverdrawDeposit(t *testing.T) {
+	database := dbtest.Open(t)
+	deposit := models.DepositInfo{
+		SLIP44ChainID:        714,
+		ChainID:              714,
+		LocalDepositID:       7,
+		GrossAmount:          "1000",
+		FeeTotalLocked:       "0",
+		AllocatableAmount:    "1000",
+		AllocatableRemaining: "1000",
+	}
+	if err := database.Create(&deposit).Error; err != nil {
+		t.Fatalf("create deposit: %v", err)
+	}
+	repo := repository.NewDepositInfoRepository(database)
+
+	// Two reservations of 600 race for 1000: exactly one may succeed
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = repo.ReserveFromDeposit(context.Background(), 714, 7, "600")
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, repository.ErrInsufficientAllocatable):
+			t.Fatalf("reservation failed with %v, want ErrInsufficientAllocatable", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d of 2 reservations succeeded, want exactly 1", succeeded)
+	}
+
+	stored, err := repo.GetByLocalID(context.Background(), 714, 7)
+	if err != nil {
+		t.Fatalf("GetByLocalID: %v", err)
+	}
+	if stored.AllocatableRemaining != "400" {
+		t.Errorf("allocatable_remaining = %s, want 400", stored.AllocatableRemaining)
+	}
+}
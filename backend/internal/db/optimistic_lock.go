@@ -0,0 +1,73 @@
+package db
+
+import (
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// versionColumn optimistic locking column of models.OptimisticLocked models
+	versionColumn = "version"
+	// versionSetKey marks a statement whose SET clause was built by incrementVersion
+	versionSetKey = "app:optimistic_lock_set"
+)
+
+// registerOptimisticLock makes every UPDATE of a models.OptimisticLocked model set version = version + 1
+// Conditional updates (WHERE version = ?) then detect concurrent writers, see repository.UpdateIfVersion.
+func registerOptimisticLock(database *gorm.DB) error {
+	if err := database.Callback().Update().Before("gorm:update").Register("app:optimistic_lock_version", incrementVersion); err != nil {
+		return err
+	}
+	return database.Callback().Update().After("gorm:update").Register("app:optimistic_lock_cleanup", clearVersionSet)
+}
+
+// incrementVersion adds "version" = "version" + 1 to the SET clause of the update
+func incrementVersion(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	if _, ok := tx.Statement.Model.(models.OptimisticLocked); !ok {
+		if _, ok := tx.Statement.Dest.(models.OptimisticLocked); !ok {
+			return
+		}
+	}
+	if tx.Statement.Schema.LookUpField(versionColumn) == nil {
+		return
+	}
+	// SET clause already built by an earlier callback, leave it alone
+	if _, ok := tx.Statement.Clauses["SET"]; ok {
+		return
+	}
+
+	set := callbacks.ConvertToAssignments(tx.Statement)
+	if len(set) == 0 {
+		return
+	}
+
+	increment := clause.Assignment{
+		Column: clause.Column{Name: versionColumn},
+		Value:  clause.Expr{SQL: "? + 1", Vars: []interface{}{clause.Column{Name: versionColumn}}},
+	}
+	replaced := false
+	for i := range set {
+		if set[i].Column.Name == versionColumn {
+			set[i] = increment
+			replaced = true
+		}
+	}
+	if !replaced {
+		set = append(set, increment)
+	}
+	tx.Statement.AddClause(set)
+	tx.InstanceSet(versionSetKey, true)
+}
+
+// clearVersionSet removes the SET clause added by incrementVersion, like gorm:update does for its own
+func clearVersionSet(tx *gorm.DB) {
+	if _, ok := tx.InstanceGet(versionSetKey); ok {
+		delete(tx.Statement.Clauses, "SET")
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingPool a gorm.ConnPool answering every Exec with rowsAffected and recording the SQL, no database needed
+type recordingPool struct {
+	rowsAffected int64
+	execs        []string
+}
+
+type recordedResult int64
+
+func (r recordedResult) LastInsertId() (int64, error) { return 0, nil }
+func (r recordedResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func (p *recordingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("recordingPool: prepare not supported")
+}
+
+func (p *recordingPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.execs = append(p.execs, query)
+	return recordedResult(p.rowsAffected), nil
+}
+
+func (p *recordingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("recordingPool: query not supported")
+}
+
+func (p *recordingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+// openRecording opens gorm with the application's update callbacks on top of a recordingPool
+func openRecording(t *testing.T, rowsAffected int64) (*gorm.DB, *recordingPool) {
+	t.Helper()
+	pool := &recordingPool{rowsAffected: rowsAffected}
+	database, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := registerOptimisticLock(database); err != nil {
+		t.Fatalf("registerOptimisticLock: %v", err)
+	}
+	return database, pool
+}
+
+func TestUpdateIfVersionIncrementsAndChecksVersion(t *testing.T) {
+	database, pool := openRecording(t, 1)
+
+	err := repository.UpdateIfVersion(database, &models.WithdrawRequest{}, "wr1", 7,
+		map[string]interface{}{"proof_status": models.ProofStatusCompleted})
+	if err != nil {
+		t.Fatalf("UpdateIfVersion: %v", err)
+	}
+	if len(pool.execs) != 1 {
+		t.Fatalf("%d statements executed, want 1", len(pool.execs))
+	}
+	query := pool.execs[0]
+	for _, want := range []string{`"version"="version" + 1`, `version = $`} {
+		if !strings.Contains(query, want) {
+			t.Errorf("update %q does not contain %q", query, want)
+		}
+	}
+}
+
+func TestUpdateIfVersionReportsConflictWhenNoRowMatches(t *testing.T) {
+	database, _ := openRecording(t, 0)
+
+	err := repository.UpdateIfVersion(database, &models.WithdrawRequest{}, "wr1", 7,
+		map[string]interface{}{"proof_status": models.ProofStatusCompleted})
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+}
+
+func TestIncrementVersionSkipsModelsWithoutVersion(t *testing.T) {
+	database, pool := openRecording(t, 1)
+
+	if err := database.Model(&models.GlobalConfig{}).Where("id = ?", 1).Updates(map[string]interface{}{"config_value": "x"}).Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if len(pool.execs) != 1 || strings.Contains(pool.execs[0], `"version"`) {
+		t.Errorf("update of a model without optimistic lock = %v, want no version column", pool.execs)
+	}
+}
@@ -0,0 +1,104 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestOptimisticLockOneOfConcurrentUpdatesWins(t *testing.T) {
+	database := dbtest.Open(t)
+	checkbook := models.Checkbook{
+		ID:             "cb-lock",
+		SLIP44ChainID:  714,
+		LocalDepositID: 1,
+		TokenKey:       "USDT",
+		Amount:         "100",
+		Status:         models.CheckbookStatusUnsigned,
+		Version:        1,
+	}
+	if err := database.Create(&checkbook).Error; err != nil {
+		t.Fatalf("create checkbook: %v", err)
+	}
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = repository.UpdateIfVersion(database, &models.Checkbook{}, checkbook.ID, checkbook.Version,
+				map[string]interface{}{"promote_code": strconv.Itoa(i)})
+		}(i)
+	}
+	wg.Wait()
+
+	won := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, repository.ErrVersionConflict):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("%d writers updated version %d, want exactly 1", won, checkbook.Version)
+	}
+
+	var stored models.Checkbook
+	if err := database.First(&stored, "id = ?", checkbook.ID).Error; err != nil {
+		t.Fatalf("reload checkbook: %v", err)
+	}
+	if stored.Version != checkbook.Version+1 {
+		t.Errorf("version = %d, want %d", stored.Version, checkbook.Version+1)
+	}
+}
+
+func TestCheckbookRepositoryUpdateRejectsStaleVersion(t *testing.T) {
+	database := dbtest.Open(t)
+	repo := repository.NewCheckbookRepository(database)
+	ctx := context.Background()
+	checkbook := &models.Checkbook{
+		ID:             "cb-stale",
+		SLIP44ChainID:  714,
+		LocalDepositID: 2,
+		TokenKey:       "USDT",
+		Amount:         "100",
+		Status:         models.CheckbookStatusUnsigned,
+		Version:        1,
+	}
+	if err := repo.Create(ctx, checkbook); err != nil {
+		t.Fatalf("create checkbook: %v", err)
+	}
+
+	first, err := repo.GetByID(ctx, checkbook.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	second := *first
+
+	first.Status = models.CheckbookStatusReadyForCommitment
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	second.PromoteCode = "LATE"
+	if err := repo.Update(ctx, &second); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("stale update err = %v, want ErrVersionConflict", err)
+	}
+
+	stored, err := repo.GetByID(ctx, checkbook.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != models.CheckbookStatusReadyForCommitment || stored.PromoteCode != "" {
+		t.Errorf("stored status=%s promote_code=%q, want the first update only", stored.Status, stored.PromoteCode)
+	}
+}
@@ -0,0 +1,68 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestGetCommitmentsAfterCoversLongChain(t *testing.T) {
+	const chainLength = 2000
+	database := dbtest.Open(t)
+	repo := repository.NewQueueRootRepository(database)
+	ctx := context.Background()
+
+	queueRoot := func(i int) *models.QueueRoot {
+		// Root i is i+1 as bytes32, so the first root's previous_root is the zero root
+		return &models.QueueRoot{
+			ID:                  fmt.Sprintf("qr-%d", i),
+			Root:                fmt.Sprintf("0x%064x", i+1),
+			PreviousRoot:        fmt.Sprintf("0x%064x", i),
+			CreatedByCommitment: fmt.Sprintf("0xc%063x", i+1),
+			ChainID:             714,
+		}
+	}
+
+	// Stored without positions, as after a backfill: the first lookup indexes the whole chain
+	roots := make([]*models.QueueRoot, 0, chainLength)
+	for i := 0; i < chainLength; i++ {
+		roots = append(roots, queueRoot(i))
+	}
+	if err := database.CreateInBatches(roots, 500).Error; err != nil {
+		t.Fatalf("create queue roots: %v", err)
+	}
+
+	commitments, err := repo.GetCommitmentsAfter(ctx, roots[0].Root)
+	if err != nil {
+		t.Fatalf("GetCommitmentsAfter: %v", err)
+	}
+	if len(commitments) != chainLength-1 {
+		t.Fatalf("got %d commitments after the first root, want %d", len(commitments), chainLength-1)
+	}
+	for i, commitment := range commitments {
+		if want := roots[i+1].CreatedByCommitment; commitment != want {
+			t.Fatalf("commitment %d = %s, want %s", i, commitment, want)
+		}
+	}
+
+	// A root appended by CommitmentRootUpdated is positioned incrementally
+	next := queueRoot(chainLength)
+	if err := repo.Create(ctx, next); err != nil {
+		t.Fatalf("create appended root: %v", err)
+	}
+	if err := repo.AssignPositions(ctx, next.Root, next.ChainID); err != nil {
+		t.Fatalf("AssignPositions: %v", err)
+	}
+	commitments, err = repo.GetCommitmentsAfter(ctx, roots[chainLength/2].Root)
+	if err != nil {
+		t.Fatalf("GetCommitmentsAfter middle root: %v", err)
+	}
+	if len(commitments) != chainLength/2 || commitments[len(commitments)-1] != next.CreatedByCommitment {
+		t.Errorf("after the middle root got %d commitments ending %v, want %d ending with the appended %s",
+			len(commitments), commitments[len(commitments)-1:], chainLength/2, next.CreatedByCommitment)
+	}
+}
@@ -0,0 +1,56 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestWithdrawRequestStatusUpdatesRecordTransitions(t *testing.T) {
+	database := dbtest.Open(t)
+	request := models.WithdrawRequest{
+		ID:                "wr-transitions",
+		WithdrawNullifier: "0x01",
+		QueueRoot:         "0x02",
+		Amount:            "100",
+		Status:            string(models.WithdrawStatusCreated),
+		ProofStatus:       models.ProofStatusPending,
+		ExecuteStatus:     models.ExecuteStatusPending,
+		PayoutStatus:      models.PayoutStatusPending,
+		HookStatus:        models.HookStatusNotRequired,
+		Version:           1,
+	}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create withdraw request: %v", err)
+	}
+	repo := repository.NewWithdrawRequestRepository(database)
+	ctx := repository.WithTransitionTrigger(context.Background(), "ExecuteWithdraw")
+
+	if err := repo.UpdateProofStatus(ctx, request.ID, models.ProofStatusInProgress, "", "", ""); err != nil {
+		t.Fatalf("UpdateProofStatus: %v", err)
+	}
+	if _, err := repo.Modify(context.Background(), request.ID, func(r *models.WithdrawRequest) error {
+		r.UpdateMainStatus()
+		return nil
+	}); err != nil {
+		t.Fatalf("Modify: %v", err)
+	}
+
+	transitions, err := repository.NewStatusTransitionRepository(database).
+		FindByEntity(context.Background(), models.StatusTransitionEntityWithdrawRequest, request.ID)
+	if err != nil {
+		t.Fatalf("FindByEntity: %v", err)
+	}
+	if len(transitions) != 2 {
+		t.Fatalf("got %d transitions, want the proof sub-status and the main status", len(transitions))
+	}
+	if got := transitions[0]; got.FromStatus != "proof:pending" || got.ToStatus != "proof:in_progress" || got.TriggerContext != "ExecuteWithdraw" {
+		t.Errorf("first transition = %s → %s (%s), want proof:pending → proof:in_progress (ExecuteWithdraw)", got.FromStatus, got.ToStatus, got.TriggerContext)
+	}
+	if got := transitions[1]; got.FromStatus != string(models.WithdrawStatusCreated) || got.ToStatus != string(models.WithdrawStatusProving) || got.TriggerContext != "Modify" {
+		t.Errorf("second transition = %s → %s (%s), want created → proving (Modify)", got.FromStatus, got.ToStatus, got.TriggerContext)
+	}
+}
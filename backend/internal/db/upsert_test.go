@@ -0,0 +1,120 @@
+package db_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// depositReceivedRow a DepositReceived event row for log 0 of txHash
+func depositReceivedRow(txHash, amount string) *models.EventDepositReceived {
+	return &models.EventDepositReceived{
+		ChainID:         714,
+		SLIP44ChainID:   714,
+		ContractAddress: "0xtreasury",
+		EventName:       "DepositReceived",
+		BlockNumber:     100,
+		TransactionHash: txHash,
+		BlockTimestamp:  time.Now(),
+		Depositor:       "0xdepositor",
+		Token:           "0xtoken",
+		Amount:          amount,
+		LocalDepositId:  1,
+	}
+}
+
+// loadDepositReceived returns every stored row of txHash
+func loadDepositReceived(t *testing.T, database *gorm.DB, txHash string) []models.EventDepositReceived {
+	t.Helper()
+	var rows []models.EventDepositReceived
+	if err := database.Where("transaction_hash = ?", txHash).Find(&rows).Error; err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	return rows
+}
+
+func TestUpsertInsertsThenUpdates(t *testing.T) {
+	database := dbtest.Open(t)
+
+	first := depositReceivedRow("0xupsert", "100")
+	if err := repository.Upsert(database, repository.EventLogConflictColumns, first, []string{"amount", "updated_at"}); err != nil {
+		t.Fatalf("Upsert insert: %v", err)
+	}
+	if first.ID == 0 {
+		t.Fatal("inserted record has no ID")
+	}
+
+	redelivered := depositReceivedRow("0xupsert", "250")
+	redelivered.ContractAddress = "0xother" // not an update column
+	if err := repository.Upsert(database, repository.EventLogConflictColumns, redelivered, []string{"amount", "updated_at"}); err != nil {
+		t.Fatalf("Upsert update: %v", err)
+	}
+
+	rows := loadDepositReceived(t, database, "0xupsert")
+	if len(rows) != 1 {
+		t.Fatalf("%d rows for the log, want 1", len(rows))
+	}
+	if rows[0].ID != first.ID || rows[0].Amount != "250" || rows[0].ContractAddress != "0xtreasury" {
+		t.Errorf("stored id=%d amount=%s contract=%s, want id=%d amount=250 contract=0xtreasury (only update columns change)",
+			rows[0].ID, rows[0].Amount, rows[0].ContractAddress, first.ID)
+	}
+}
+
+func TestCreateIfAbsentKeepsExistingRow(t *testing.T) {
+	database := dbtest.Open(t)
+
+	created, err := repository.CreateIfAbsent(database, repository.EventLogConflictColumns, depositReceivedRow("0xabsent", "100"))
+	if err != nil || !created {
+		t.Fatalf("CreateIfAbsent first = %v, %v, want created", created, err)
+	}
+	created, err = repository.CreateIfAbsent(database, repository.EventLogConflictColumns, depositReceivedRow("0xabsent", "999"))
+	if err != nil || created {
+		t.Fatalf("CreateIfAbsent duplicate = %v, %v, want not created and no error", created, err)
+	}
+
+	rows := loadDepositReceived(t, database, "0xabsent")
+	if len(rows) != 1 || rows[0].Amount != "100" {
+		t.Errorf("stored rows = %+v, want the first insert only", rows)
+	}
+}
+
+func TestUpsertConcurrentInsertsLeaveOneRow(t *testing.T) {
+	database := dbtest.Open(t)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = repository.Upsert(database, repository.EventLogConflictColumns,
+				depositReceivedRow("0xconcurrent", fmt.Sprint(100+i)), []string{"amount", "updated_at"})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("writer %d: %v, want no duplicate-key error", i, err)
+		}
+	}
+	rows := loadDepositReceived(t, database, "0xconcurrent")
+	if len(rows) != 1 {
+		t.Fatalf("%d rows for the log, want 1", len(rows))
+	}
+	written := make(map[string]bool)
+	for i := 0; i < writers; i++ {
+		written[fmt.Sprint(100+i)] = true
+	}
+	if !written[rows[0].Amount] {
+		t.Errorf("stored amount %s, want one of the writers' amounts", rows[0].Amount)
+	}
+}
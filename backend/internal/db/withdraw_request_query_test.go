@@ -0,0 +1,69 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestFindByOwnerOrBeneficiaryListsEachRequestOnce(t *testing.T) {
+	database := dbtest.Open(t)
+	repo := repository.NewWithdrawRequestRepository(database)
+	ctx := context.Background()
+
+	user := "0x" + fmt.Sprintf("%064x", 0xaa)
+	other := "0x" + fmt.Sprintf("%064x", 0xbb)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, roles := range []struct{ id, owner, recipient string }{
+		{"wr-own", user, other},
+		{"wr-receive", other, user},
+		{"wr-both", user, user}, // matches both roles
+		{"wr-unrelated", other, other},
+	} {
+		request := models.WithdrawRequest{
+			ID:                roles.id,
+			WithdrawNullifier: fmt.Sprintf("0x%064x", i+1),
+			OwnerAddress:      models.UniversalAddress{SLIP44ChainID: 714, Data: roles.owner},
+			Recipient:         models.UniversalAddress{SLIP44ChainID: 714, Data: roles.recipient},
+			Amount:            "1000",
+			CreatedAt:         created.Add(time.Duration(i) * time.Minute),
+			Version:           1,
+		}
+		if err := database.Create(&request).Error; err != nil {
+			t.Fatalf("create %s: %v", roles.id, err)
+		}
+	}
+
+	requests, total, err := repo.FindByOwnerOrBeneficiary(ctx, 714, user, 1, 10)
+	if err != nil {
+		t.Fatalf("FindByOwnerOrBeneficiary: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("total = %d, want 3 (the request matching both roles counted once)", total)
+	}
+	var ids []string
+	for _, request := range requests {
+		ids = append(ids, request.ID)
+	}
+	if fmt.Sprint(ids) != "[wr-both wr-receive wr-own]" {
+		t.Errorf("requests = %v, want [wr-both wr-receive wr-own]", ids)
+	}
+
+	// A page boundary falling on the shared request neither repeats nor drops it
+	page2, _, err := repo.FindByOwnerOrBeneficiary(ctx, 714, user, 2, 1)
+	if err != nil {
+		t.Fatalf("FindByOwnerOrBeneficiary page 2: %v", err)
+	}
+	if len(page2) != 1 || page2[0].ID != "wr-receive" {
+		t.Errorf("page 2 = %v, want [wr-receive]", page2)
+	}
+
+	if _, total, err := repo.FindByOwnerOrBeneficiary(ctx, 60, user, 1, 10); err != nil || total != 0 {
+		t.Errorf("other chain: total = %d, %v, want 0", total, err)
+	}
+}
@@ -0,0 +1,81 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestAppendRetryHistoryAccumulatesAcrossReloads(t *testing.T) {
+	database := dbtest.Open(t)
+	repo := repository.NewWithdrawRequestRepository(database)
+	ctx := context.Background()
+
+	request := models.WithdrawRequest{ID: "wr-retries", WithdrawNullifier: "0xnullifier-retries", Amount: "1000", Version: 1}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	var stale models.WithdrawRequest
+	if err := database.First(&stale, "id = ?", "wr-retries").Error; err != nil {
+		t.Fatalf("load request: %v", err)
+	}
+	if history, err := stale.ParseRetryHistory(); err != nil || len(history) != 0 {
+		t.Fatalf("history of a new request = %v, %v, want empty", history, err)
+	}
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	want := []models.RetryHistoryEntry{
+		{Stage: models.RetryStagePayout, Attempt: 1, At: at, Error: "insufficient liquidity", Trigger: "PayoutFailed"},
+		{Stage: models.RetryStagePayout, Attempt: 2, At: at.Add(time.Minute), Error: "insufficient liquidity", Trigger: "RetryPayout"},
+		{Stage: models.RetryStageHook, Attempt: 1, At: at.Add(2 * time.Minute), Error: "hook reverted", Trigger: "HookFailed"},
+	}
+	for i, entry := range want {
+		if err := repo.AppendRetryHistory(ctx, "wr-retries", entry); err != nil {
+			t.Fatalf("append entry %d: %v", i, err)
+		}
+	}
+
+	// A full-row save of a copy loaded before the appends must not drop them
+	stale.PayoutError = "insufficient liquidity"
+	if err := database.Save(&stale).Error; err != nil {
+		t.Fatalf("save stale copy: %v", err)
+	}
+
+	reloaded, err := repo.GetByID(ctx, "wr-retries")
+	if err != nil {
+		t.Fatalf("reload request: %v", err)
+	}
+	history, err := reloaded.ParseRetryHistory()
+	if err != nil {
+		t.Fatalf("parse history: %v", err)
+	}
+	if len(history) != len(want) {
+		t.Fatalf("%d history entries, want %d: %+v", len(history), len(want), history)
+	}
+	for i, entry := range history {
+		if entry.Stage != want[i].Stage || entry.Attempt != want[i].Attempt || entry.Error != want[i].Error ||
+			entry.Trigger != want[i].Trigger || !entry.At.Equal(want[i].At) {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+
+	// An entry without a timestamp is stamped when appended
+	if err := repo.AppendRetryHistory(ctx, "wr-retries", models.RetryHistoryEntry{
+		Stage: models.RetryStageFallback, Attempt: 1, Trigger: "RetryFallback",
+	}); err != nil {
+		t.Fatalf("append unstamped entry: %v", err)
+	}
+	if reloaded, err = repo.GetByID(ctx, "wr-retries"); err != nil {
+		t.Fatalf("reload request: %v", err)
+	}
+	if history, err = reloaded.ParseRetryHistory(); err != nil {
+		t.Fatalf("parse history: %v", err)
+	}
+	if len(history) != 4 || history[3].Stage != models.RetryStageFallback || history[3].At.IsZero() {
+		t.Errorf("history after fallback retry = %+v, want 4 entries ending with a stamped fallback entry", history)
+	}
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deadLetterRetryDelay delay before the next attempt, multiplied by the attempt number
+var deadLetterRetryDelay = 500 * time.Millisecond
+
+// processWithDeadLetter runs process up to the configured attempt limit (nats.max_process_attempts)
+// If every attempt fails, the event is stored in failed_events so the subscription moves on instead of
+// losing it; cmd/replay-failed-events reprocesses it after a fix. Returns the last error, nil on success.
+func processWithDeadLetter(eventType, subject string, event interface{}, process func() error) error {
+	maxAttempts := config.GetNATSMaxProcessAttempts()
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = process(); err == nil {
+			return nil
+		}
+		log.Printf("⚠️ [DeadLetter] %s processing attempt %d/%d failed: %v", eventType, attempt, maxAttempts, err)
+		if attempt < maxAttempts {
+			time.Sleep(time.Duration(attempt) * deadLetterRetryDelay)
+		}
+	}
+
+	if db.DB == nil {
+		log.Printf("❌ [DeadLetter] Database not initialized, dropping %s event (subject=%s)", eventType, subject)
+		return err
+	}
+	if dlqErr := storeFailedEvent(db.DB, eventType, subject, event, maxAttempts, err); dlqErr != nil {
+		log.Printf("❌ [DeadLetter] Failed to store %s event (subject=%s): %v", eventType, subject, dlqErr)
+	} else {
+		log.Printf("📮 [DeadLetter] %s event stored in failed_events after %d attempt(s) (subject=%s)", eventType, maxAttempts, subject)
+	}
+	return err
+}
+
+// storeFailedEvent upserts a failed_events row for the message
+// The same message failing again (e.g. a redelivery) adds to the attempt count and resets it to pending.
+func storeFailedEvent(database *gorm.DB, eventType, subject string, event interface{}, attempts int, processErr error) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	failed := models.FailedEvent{
+		MessageKey: failedEventMessageKey(subject, payload),
+		Subject:    subject,
+		EventType:  eventType,
+		Payload:    string(payload),
+		Attempts:   attempts,
+		LastError:  processErr.Error(),
+		Status:     models.FailedEventStatusPending,
+	}
+	return database.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "message_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attempts":   gorm.Expr("failed_events.attempts + ?", attempts),
+			"last_error": failed.LastError,
+			"status":     models.FailedEventStatusPending,
+			"updated_at": gorm.Expr("NOW()"),
+		}),
+	}).Create(&failed).Error
+}
+
+// failedEventMessageKey identifies a message by its subject and payload
+func failedEventMessageKey(subject string, payload []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(subject))
+	hash.Write([]byte{0})
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+)
+
+// useDeadLetterDB points processWithDeadLetter at a test database with the given attempt limit and no retry delay
+func useDeadLetterDB(t *testing.T, maxAttempts int) {
+	t.Helper()
+	database := dbtest.Open(t)
+
+	previousDB, previousConfig, previousDelay := db.DB, config.AppConfig, deadLetterRetryDelay
+	t.Cleanup(func() { db.DB, config.AppConfig, deadLetterRetryDelay = previousDB, previousConfig, previousDelay })
+
+	db.DB = database
+	config.AppConfig = &config.Config{NATS: config.NATSConfig{MaxProcessAttempts: maxAttempts}}
+	deadLetterRetryDelay = 0
+}
+
+func TestProcessWithDeadLetterStoresEventAfterAttemptLimit(t *testing.T) {
+	useDeadLetterDB(t, 3)
+	const subject = "zkpay.bsc.EnclavePay.DepositRecorded"
+	event := map[string]string{"commitment": "0xabc"}
+	processErr := errors.New("checkbook not found")
+
+	calls := 0
+	err := processWithDeadLetter("DepositRecorded", subject, event, func() error {
+		calls++
+		return processErr
+	})
+	if !errors.Is(err, processErr) {
+		t.Fatalf("processWithDeadLetter: %v, want the last processing error", err)
+	}
+	if calls != 3 {
+		t.Errorf("process called %d times, want 3", calls)
+	}
+
+	var stored []models.FailedEvent
+	if err := db.DB.Find(&stored).Error; err != nil {
+		t.Fatalf("load failed_events: %v", err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("failed_events rows = %d, want 1", len(stored))
+	}
+	got := stored[0]
+	if got.EventType != "DepositRecorded" || got.Subject != subject || got.Payload != `{"commitment":"0xabc"}` {
+		t.Errorf("stored event = %s %s %s", got.EventType, got.Subject, got.Payload)
+	}
+	if got.Attempts != 3 || got.LastError != processErr.Error() || got.Status != models.FailedEventStatusPending {
+		t.Errorf("attempts = %d, last_error = %q, status = %s, want 3, %q, pending", got.Attempts, got.LastError, got.Status, processErr)
+	}
+
+	// A redelivery of the same message failing again adds to the existing row
+	processWithDeadLetter("DepositRecorded", subject, event, func() error { return errors.New("still missing") })
+	if err := db.DB.Find(&stored).Error; err != nil {
+		t.Fatalf("reload failed_events: %v", err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("failed_events rows after redelivery = %d, want 1", len(stored))
+	}
+	if stored[0].Attempts != 6 || stored[0].LastError != "still missing" {
+		t.Errorf("after redelivery attempts = %d, last_error = %q, want 6, %q", stored[0].Attempts, stored[0].LastError, "still missing")
+	}
+}
+
+func TestProcessWithDeadLetterRecoversBeforeAttemptLimit(t *testing.T) {
+	useDeadLetterDB(t, 3)
+
+	calls := 0
+	err := processWithDeadLetter("DepositUsed", "zkpay.bsc.EnclavePay.DepositUsed", map[string]string{}, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("processWithDeadLetter = %v after %d calls, want nil after 3", err, calls)
+	}
+
+	var count int64
+	if err := db.DB.Model(&models.FailedEvent{}).Count(&count).Error; err != nil {
+		t.Fatalf("count failed_events: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("failed_events rows = %d, want 0 for an event that eventually succeeded", count)
+	}
+}
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -11,6 +12,7 @@ import (
 	"go-backend/internal/clients"
 	"go-backend/internal/config"
 	"go-backend/internal/db"
+	"go-backend/internal/logging"
 	"go-backend/internal/models"
 	"go-backend/internal/services"
 	"go-backend/internal/utils"
@@ -83,6 +85,8 @@ func InitNATSServices() error {
 }
 
 // subscribeToEvents SubscriptionNATSevent
+// The client decodes each message (scanner, configurable-processor or plain format); every handler then
+// goes through dispatchEvent, so processing is routed by BlockchainEventProcessor.Dispatch.
 func SubscribeToEvents() error {
 	if natsClient == nil {
 		return fmt.Errorf("NATS client not initialized")
@@ -166,43 +170,49 @@ func SubscribeToEvents() error {
 	return nil
 }
 
-// handleDepositReceivedEvent processdepositevent
-func handleDepositReceivedEvent(depositReceived *clients.EventDepositReceivedResponse, subject string) {
+// dispatchEvent hands a decoded event to BlockchainEventProcessor.Dispatch, the same entrypoint
+// cmd/replay-failed-events uses, so live and replayed messages take one path
+// The payload is the event as stored in failed_events; failures are retried and dead-lettered.
+func dispatchEvent(eventType, subject string, event interface{}) error {
 	startTime := time.Now()
-	eventType := "DepositReceived"
-
-	// 记录 metrics
 	services.RecordNATSMessageReceived(eventType)
 
-	// NATSSubjectParseSLIP-44 ChainID
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
+	processor := GetEventProcessor()
+	if processor == nil {
+		services.RecordNATSMessageFailed(eventType, "processor_nil")
+		services.RecordEventListenerError(eventType, "processor_nil")
+		return fmt.Errorf("event processor not initialized")
+	}
+
+	payload, err := json.Marshal(event)
 	if err != nil {
-		log.Printf("❌ [NATS] Subject %s Parsechain ID: %v", subject, err)
-		// Useeventchain ID
-		chainID = utils.EvmToSlip44(int(depositReceived.ChainID))
-		log.Printf("⚠️ [NATS] useeventchain ID，Convertchain ID: %d", chainID)
+		services.RecordNATSMessageFailed(eventType, "marshal_error")
+		services.RecordEventListenerError(eventType, "marshal_error")
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
 	}
 
-	log.Printf("🎉🏦 [NATS] DepositReceivedevent - LocalDepositId=%d, amount=%s, chain ID=%d (SLIP-44)",
-		depositReceived.EventData.LocalDepositId, depositReceived.EventData.Amount, chainID)
+	if err := processWithDeadLetter(eventType, subject, event, func() error { return processor.Dispatch(subject, payload) }); err != nil {
+		services.RecordNATSMessageFailed(eventType, "process_error")
+		services.RecordEventListenerError(eventType, "process_error")
+		return err
+	}
+
+	services.RecordNATSMessageProcessed(eventType)
+	services.RecordEventProcessingDuration(eventType, time.Since(startTime))
+	return nil
+}
+
+// handleDepositReceivedEvent processdepositevent
+func handleDepositReceivedEvent(depositReceived *clients.EventDepositReceivedResponse, subject string) {
+	log.Printf("🎉🏦 [NATS] DepositReceivedevent - LocalDepositId=%d, amount=%s, Subject=%s",
+		depositReceived.EventData.LocalDepositId, depositReceived.EventData.Amount, subject)
 	log.Printf("   deposit: %s, Token=%s", depositReceived.EventData.Depositor, depositReceived.EventData.Token)
 
 	// saveDatabaseandCreateCheckbook - BlockchainEventProcessor
-	processor := GetEventProcessor()
-	if err := processor.ProcessDepositReceived(depositReceived); err != nil {
+	if err := dispatchEvent("DepositReceived", subject, depositReceived); err != nil {
 		log.Printf("❌ [NATS] processDepositReceivedeventfailed: %v", err)
-		// 记录 metrics
-		errorType := "process_error"
-		services.RecordNATSMessageFailed(eventType, errorType)
-		services.RecordEventListenerError(eventType, errorType)
 		return
 	}
-
-	// 记录 metrics
-	duration := time.Since(startTime)
-	services.RecordNATSMessageProcessed(eventType)
-	services.RecordEventProcessingDuration(eventType, duration)
-
 	log.Printf("📈 DepositReceivedeventprocesscompleted")
 }
 
@@ -214,79 +224,33 @@ func handleDepositRecordedEvent(depositRecorded *clients.EventDepositRecordedRes
 		}
 	}()
 
-	log.Printf("🚀 [handleDepositRecordedEvent] Function called! LocalDepositId=%d", depositRecorded.EventData.LocalDepositId)
-
-	// DepositRecordedeventUseeventdataChainID（Info）
-	// depositRecorded.ChainID is already SLIP-44 Chain ID from ConvertScannerEventToDepositRecorded
-	// Use SmartToSlip44 to handle both EVM Chain ID and SLIP-44 ChainID (smart conversion)
-	log.Printf("🔍 [handleDepositRecordedEvent] Before SmartToSlip44, ChainID=%d", depositRecorded.ChainID)
-	chainID := utils.SmartToSlip44(int(depositRecorded.ChainID))
-	log.Printf("📋 [NATS] DepositRecordedeventuseeventdataSLIP44ChainID: %d -> %d (SLIP-44)", depositRecorded.ChainID, chainID)
-
-	// Ensure tokenKey mapper is initialized
-	log.Printf("🔍 [handleDepositRecordedEvent] Before InitTokenKeyHashMap")
-	utils.InitTokenKeyHashMap()
-	log.Printf("🔍 [handleDepositRecordedEvent] After InitTokenKeyHashMap")
-
 	// Convert tokenKey hash to original string for logging
-	log.Printf("🔍 [handleDepositRecordedEvent] Converting tokenKey hash: %s", depositRecorded.EventData.TokenKey)
-	originalTokenKey := utils.GetTokenKeyFromHash(depositRecorded.EventData.TokenKey)
-	log.Printf("🔍 [handleDepositRecordedEvent] Converted tokenKey: %s", originalTokenKey)
+	utils.RegisterTokenKeys(config.GetTokenKeys())
+	originalTokenKey, known := utils.LookupTokenKey(depositRecorded.EventData.TokenKey)
 
-	log.Printf("🎉📋 [NATS] DepositRecordedevent - LocalDepositId=%d, GrossAmount=%s, chain ID=%d (SLIP-44)",
-		depositRecorded.EventData.LocalDepositId, depositRecorded.EventData.GrossAmount, chainID)
-	log.Printf("   : %d:%s, TokenKey=%s, AllocatableAmount=%s, FeeTotalLocked=%s",
+	log.Printf("🎉📋 [NATS] DepositRecordedevent - LocalDepositId=%d, GrossAmount=%s, chain ID=%d",
+		depositRecorded.EventData.LocalDepositId, depositRecorded.EventData.GrossAmount, depositRecorded.ChainID)
+	log.Printf("   : %d:%s, TokenKey=%s (known=%v), AllocatableAmount=%s, FeeTotalLocked=%s",
 		depositRecorded.EventData.Owner.ChainId, depositRecorded.EventData.Owner.Data,
-		originalTokenKey, depositRecorded.EventData.AllocatableAmount, depositRecorded.EventData.FeeTotalLocked)
-
-	// 记录 metrics
-	startTime := time.Now()
-	eventType := "DepositRecorded"
-	services.RecordNATSMessageReceived(eventType)
+		originalTokenKey, known, depositRecorded.EventData.AllocatableAmount, depositRecorded.EventData.FeeTotalLocked)
 
 	// saveDatabaseandUpdateCheckbook - BlockchainEventProcessor
-	log.Printf("🔄 [NATS] Calling ProcessDepositRecorded...")
-	processor := GetEventProcessor()
-	if processor == nil {
-		log.Printf("❌ [NATS] EventProcessor is nil!")
-		services.RecordNATSMessageFailed(eventType, "processor_nil")
-		services.RecordEventListenerError(eventType, "processor_nil")
-		return
-	}
-	log.Printf("✅ [NATS] EventProcessor obtained, calling ProcessDepositRecorded...")
-	if err := processor.ProcessDepositRecorded(depositRecorded); err != nil {
+	if err := dispatchEvent("DepositRecorded", subject, depositRecorded); err != nil {
 		log.Printf("❌ [NATS] processDepositRecordedeventfailed: %v", err)
-		// 记录 metrics
-		errorType := "process_error"
-		services.RecordNATSMessageFailed(eventType, errorType)
-		services.RecordEventListenerError(eventType, errorType)
 		return
 	}
-
-	// 记录 metrics
-	duration := time.Since(startTime)
-	services.RecordNATSMessageProcessed(eventType)
-	services.RecordEventProcessingDuration(eventType, duration)
-
 	log.Printf("📈 DepositRecordedeventprocesscompleted")
 }
 
 // handleDepositUsedEvent processdepositUseevent
 func handleDepositUsedEvent(depositUsed *clients.EventDepositUsedResponse, subject string) {
-	// DepositUsedeventUseeventdataChainID（Info）
-	chainID := utils.EvmToSlip44(int(depositUsed.ChainID))
-	log.Printf("🔗 [NATS] DepositUsedeventuseeventdataSLIP44ChainID: %d -> %d (SLIP-44)", depositUsed.ChainID, chainID)
-
-	log.Printf("🎉🔗 [NATS] DepositUsedevent - LocalDepositId=%d, Commitment=%s, chain ID=%d (SLIP-44)",
-		depositUsed.EventData.LocalDepositId, depositUsed.EventData.Commitment, chainID)
+	log.Printf("🎉🔗 [NATS] DepositUsedevent - LocalDepositId=%d, Commitment=%s, chain ID=%d",
+		depositUsed.EventData.LocalDepositId, depositUsed.EventData.Commitment, depositUsed.ChainID)
 
-	// eventprocessprocess
-	processor := GetEventProcessor()
-	if err := processor.ProcessDepositUsed(depositUsed); err != nil {
+	if err := dispatchEvent("DepositUsed", subject, depositUsed); err != nil {
 		log.Printf("❌ [NATS] processDepositUsedeventfailed: %v", err)
 		return
 	}
-
 	log.Printf("📈 DepositUsedeventprocesscompleted")
 }
 
@@ -303,72 +267,23 @@ func handleDepositEvent_DEPRECATED(deposit interface{}, subject string) {
 
 // handleCommitmentRootUpdateEvent processUpdateevent (CommitmentRootUpdated)
 func handleCommitmentRootUpdateEvent(queueRoot *clients.EventCommitmentRootUpdatedResponse, subject string) {
-	startTime := time.Now()
-	eventType := "CommitmentRootUpdated"
-
-	// 记录 metrics
-	services.RecordNATSMessageReceived(eventType)
-
-	// CommitmentRootUpdatedeventUseeventdataChainID（Info）
-	// Use SmartToSlip44 to handle both EVM Chain ID and SLIP-44 ChainID
-	chainID := utils.SmartToSlip44(int(queueRoot.ChainID))
-	log.Printf("🌳 [NATS] CommitmentRootUpdatedeventuseeventdataSLIP44ChainID: %d -> %d (SLIP-44)", queueRoot.ChainID, chainID)
-
-	log.Printf("🌳 queue rootUpdateevent: Subject=%s, ChainID=%d (SLIP-44), =%d, TxHash=%s",
-		subject, chainID, queueRoot.BlockNumber, queueRoot.TransactionHash)
+	log.Printf("🌳 queue rootUpdateevent: Subject=%s, ChainID=%d, =%d, TxHash=%s",
+		subject, queueRoot.ChainID, queueRoot.BlockNumber, queueRoot.TransactionHash)
 	log.Printf("   eventdata: OldRoot=%s, Commitment=%s, NewRoot=%s",
 		queueRoot.EventData.OldRoot, queueRoot.EventData.Commitment, queueRoot.EventData.NewRoot)
 
-	// 🎯 1. ：Useeventprocessprocess
-	processor := GetEventProcessor()
-	if err := processor.ProcessCommitmentRootUpdated(queueRoot); err != nil {
+	if err := dispatchEvent("CommitmentRootUpdated", subject, queueRoot); err != nil {
 		log.Printf("❌ eventprocessprocessfailed: %v", err)
-		// 记录 metrics
-		errorType := "process_error"
-		services.RecordNATSMessageFailed(eventType, errorType)
-		services.RecordEventListenerError(eventType, errorType)
-	} else {
-		// 记录 metrics
-		duration := time.Since(startTime)
-		services.RecordNATSMessageProcessed(eventType)
-		services.RecordEventProcessingDuration(eventType, duration)
-	}
-
-	// 🏦 2. saveQueueRootV2Database
-	if err := saveQueueRootToDatabase(queueRoot, chainID); err != nil {
-		log.Printf("❌ savequeue rootDatabasefailed: %v", err)
-	} else {
-		log.Printf("✅ queue rootalreadysaveDatabase: NewRoot=%s, ChainID=%d", queueRoot.EventData.NewRoot, chainID)
 	}
 }
 
 // handleWithdrawRequestedEvent processwithdrawrequestevent
 func handleWithdrawRequestedEvent(withdrawRequested *clients.EventWithdrawRequestedResponse, subject string) {
-	startTime := time.Now()
-	eventType := "WithdrawRequested"
-
-	// 记录 metrics
-	services.RecordNATSMessageReceived(eventType)
-
-	// WithdrawRequestedeventUseeventdataChainID（Info）
-	// Try to get chain ID from subject first, fallback to ChainID field
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil || chainID == 0 {
-		// Fallback to ChainID field and convert
-		chainID = utils.SmartToSlip44(int(withdrawRequested.ChainID))
-		log.Printf("💰 [NATS] WithdrawRequestedeventuseeventdataSLIP44ChainID: %d -> %d (SLIP-44)", withdrawRequested.ChainID, chainID)
-	} else {
-		log.Printf("💰 [NATS] WithdrawRequestedeventuseSubjectSLIP44ChainID: %d (SLIP-44)", chainID)
-	}
-
-	log.Printf("🎉💰 [NATS] WithdrawRequestedevent: RequestId=%s, amount=%s, chain ID=%d (SLIP-44)",
-		withdrawRequested.EventData.RequestId, withdrawRequested.EventData.Amount, chainID)
+	log.Printf("🎉💰 [NATS] WithdrawRequestedevent: RequestId=%s, amount=%s, Subject=%s",
+		withdrawRequested.EventData.RequestId, withdrawRequested.EventData.Amount, subject)
 	log.Printf("   address(hash): %s, TokenId=%d",
 		withdrawRequested.EventData.Recipient, withdrawRequested.EventData.TokenId)
 
-	// Step 1: Update Check status (handled by updateCheckStatusOnWithdrawRequested)
-	// Step 2: Update WithdrawRequest status (handled by ProcessWithdrawRequested)
-
 	// Step 1: Update Check status
 	if err := updateCheckStatusOnWithdrawRequested(withdrawRequested); err != nil {
 		log.Printf("❌ [NATS] WithdrawRequested Check update failed: %v", err)
@@ -378,60 +293,25 @@ func handleWithdrawRequestedEvent(withdrawRequested *clients.EventWithdrawReques
 	}
 
 	// Step 2: Update WithdrawRequest status (proof_status=completed, execute_status=success, payout_status=pending)
-	processor := GetEventProcessor()
-	if processor != nil {
-		if err := processor.ProcessWithdrawRequested(withdrawRequested); err != nil {
-			log.Printf("❌ [NATS] ProcessWithdrawRequested failed: %v", err)
-			// 记录 metrics
-			errorType := "process_error"
-			services.RecordNATSMessageFailed(eventType, errorType)
-			services.RecordEventListenerError(eventType, errorType)
-		} else {
-			log.Printf("✅ [NATS] ProcessWithdrawRequested success")
-			// 记录 metrics
-			duration := time.Since(startTime)
-			services.RecordNATSMessageProcessed(eventType)
-			services.RecordEventProcessingDuration(eventType, duration)
-		}
-	} else {
-		log.Printf("⚠️ [NATS] EventProcessor not initialized, skipping WithdrawRequest status update")
-		services.RecordNATSMessageFailed(eventType, "processor_nil")
-		services.RecordEventListenerError(eventType, "processor_nil")
+	if err := dispatchEvent("WithdrawRequested", subject, withdrawRequested); err != nil {
+		log.Printf("❌ [NATS] ProcessWithdrawRequested failed: %v", err)
+		return
 	}
-
 	log.Printf("📈 WithdrawRequestedeventprocesscompleted")
 }
 
 // handleWithdrawExecutedEvent processwithdrawevent
 func handleWithdrawExecutedEvent(withdrawExecuted *clients.EventWithdrawExecutedResponse, subject string) {
-	startTime := time.Now()
-	eventType := "WithdrawExecuted"
-
-	// 记录 metrics
-	services.RecordNATSMessageReceived(eventType)
-
-	// 🎯 NATSSubjectParseSLIP-44 ChainID
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		log.Printf("❌ [NATS] Subject %s Parsechain ID: %v", subject, err)
-		// ，UseeventdatachainIDattemptConvert
-		chainID = utils.EvmToSlip44(int(withdrawExecuted.ChainID))
-		log.Printf("⚠️ [NATS] use，Convertchain ID: %d", chainID)
+	// The subject's chain wins over the payload's, which may be an EVM chain ID
+	if chainID, err := utils.GetSlip44ChainIDFromSubject(subject); err == nil {
+		withdrawExecuted.ChainID = int64(chainID)
 	} else {
-		log.Printf("✅ [NATS] SubjectsuccessParsechain ID: %s -> %d (SLIP-44)", subject, chainID)
+		withdrawExecuted.ChainID = int64(utils.SmartToSlip44(int(withdrawExecuted.ChainID)))
 	}
 
-	// 🔧 eventdataChainIDDatabasestorage
-	originalChainID := withdrawExecuted.ChainID
-	withdrawExecuted.ChainID = int64(chainID)
-
 	log.Printf("🎉💸 [NATS] WithdrawExecutedevent: RequestId=%s, amount=%s, chain ID=%d (SLIP-44)",
-		withdrawExecuted.EventData.RequestId, withdrawExecuted.EventData.Amount, chainID)
+		withdrawExecuted.EventData.RequestId, withdrawExecuted.EventData.Amount, withdrawExecuted.ChainID)
 	log.Printf("   address: %s, Token=%s", withdrawExecuted.EventData.Recipient, withdrawExecuted.EventData.Token)
-	log.Printf("   🔧 ChainID: %d -> %d (SLIP-44)", originalChainID, withdrawExecuted.ChainID)
-
-	// Step 1: Update Check status to 'used' (handled by updateCheckStatusOnWithdrawExecuted)
-	// Step 2: Update WithdrawRequest status (handled by ProcessWithdrawExecuted)
 
 	// Step 1: Update Check status
 	if err := updateCheckStatusOnWithdrawExecuted(withdrawExecuted); err != nil {
@@ -442,231 +322,119 @@ func handleWithdrawExecutedEvent(withdrawExecuted *clients.EventWithdrawExecuted
 	}
 
 	// Step 2: Update WithdrawRequest status (execute_status=success, payout_status=completed)
-	processor := GetEventProcessor()
-	if processor != nil {
-		if err := processor.ProcessWithdrawExecuted(withdrawExecuted); err != nil {
-			log.Printf("❌ [NATS] ProcessWithdrawExecuted failed: %v", err)
-			// 记录 metrics
-			errorType := "process_error"
-			services.RecordNATSMessageFailed(eventType, errorType)
-			services.RecordEventListenerError(eventType, errorType)
-		} else {
-			log.Printf("✅ [NATS] ProcessWithdrawExecuted success")
-		}
-	} else {
-		log.Printf("⚠️ [NATS] EventProcessor not initialized, skipping WithdrawRequest status update")
+	if err := dispatchEvent("WithdrawExecuted", subject, withdrawExecuted); err != nil {
+		log.Printf("❌ [NATS] ProcessWithdrawExecuted failed: %v", err)
+		return
 	}
-
-	// 记录 metrics
-	duration := time.Since(startTime)
-	services.RecordNATSMessageProcessed(eventType)
-	services.RecordEventProcessingDuration(eventType, duration)
-
 	log.Printf("📈 WithdrawExecutedeventprocesscompleted")
 }
 
 // handleIntentManagerWithdrawExecutedEvent processIntentManager.WithdrawExecuted event
 // This event indicates that payout (Stage 3) has completed
 func handleIntentManagerWithdrawExecutedEvent(intentManagerWithdrawExecuted *clients.EventIntentManagerWithdrawExecutedResponse, subject string) {
-	// 🎯 NATS Subject Parse SLIP-44 ChainID
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		log.Printf("❌ [NATS] Subject %s Parse chain ID: %v", subject, err)
-		// ，Use event data chainID attempt Convert
-		chainID = utils.EvmToSlip44(int(intentManagerWithdrawExecuted.ChainID))
-		log.Printf("⚠️ [NATS] use，Convert chain ID: %d", chainID)
+	// The subject's chain wins over the payload's, which may be an EVM chain ID
+	if chainID, err := utils.GetSlip44ChainIDFromSubject(subject); err == nil {
+		intentManagerWithdrawExecuted.ChainID = int64(chainID)
 	} else {
-		log.Printf("✅ [NATS] Subject success Parse chain ID: %s -> %d (SLIP-44)", subject, chainID)
+		intentManagerWithdrawExecuted.ChainID = int64(utils.SmartToSlip44(int(intentManagerWithdrawExecuted.ChainID)))
 	}
 
-	// 🔧 event data ChainID Database storage
-	originalChainID := intentManagerWithdrawExecuted.ChainID
-	intentManagerWithdrawExecuted.ChainID = int64(chainID)
-
 	log.Printf("🎉💸 [NATS] IntentManager.WithdrawExecuted event: WorkerType=%d, Success=%v, chain ID=%d (SLIP-44)",
-		intentManagerWithdrawExecuted.EventData.WorkerType, intentManagerWithdrawExecuted.EventData.Success, chainID)
+		intentManagerWithdrawExecuted.EventData.WorkerType, intentManagerWithdrawExecuted.EventData.Success, intentManagerWithdrawExecuted.ChainID)
 	log.Printf("   TransactionHash=%s, Message=%s", intentManagerWithdrawExecuted.TransactionHash, intentManagerWithdrawExecuted.EventData.Message)
-	log.Printf("   🔧 ChainID: %d -> %d (SLIP-44)", originalChainID, intentManagerWithdrawExecuted.ChainID)
 
 	// Process event - update WithdrawRequest payout status
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessIntentManagerWithdrawExecuted(intentManagerWithdrawExecuted); err != nil {
-			log.Printf("❌ [NATS] IntentManager.WithdrawExecuted process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] IntentManager.WithdrawExecuted process success")
-		}
-	} else {
-		log.Printf("⚠️ [NATS] EventProcessor not initialized, skipping IntentManager.WithdrawExecuted processing")
+	if err := dispatchEvent("IntentManagerWithdrawExecuted", subject, intentManagerWithdrawExecuted); err != nil {
+		log.Printf("❌ [NATS] IntentManager.WithdrawExecuted process failed: %v", err)
+		return
 	}
-
 	log.Printf("📈 IntentManager.WithdrawExecuted event process completed")
 }
 
 // handlePayoutExecutedEvent processes Treasury.PayoutExecuted event
 func handlePayoutExecutedEvent(event *clients.EventPayoutExecutedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-		log.Printf("⚠️ [NATS] Use event data chain ID: %d", chainID)
-	}
-
-	log.Printf("🎉💰 [NATS] PayoutExecuted event: RequestId=%s, WorkerType=%d, chain ID=%d",
-		event.EventData.RequestId, event.EventData.WorkerType, chainID)
+	log.Printf("🎉💰 [NATS] PayoutExecuted event: RequestId=%s, WorkerType=%d, Subject=%s",
+		event.EventData.RequestId, event.EventData.WorkerType, subject)
 
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessPayoutExecuted(event); err != nil {
-			log.Printf("❌ [NATS] PayoutExecuted process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] PayoutExecuted process success")
-		}
+	if err := dispatchEvent("PayoutExecuted", subject, event); err != nil {
+		log.Printf("❌ [NATS] PayoutExecuted process failed: %v", err)
 	}
 }
 
 // handlePayoutFailedEvent processes Treasury.PayoutFailed event
 func handlePayoutFailedEvent(event *clients.EventPayoutFailedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
+	log.Printf("⚠️💰 [NATS] PayoutFailed event: RequestId=%s, WorkerType=%d, Error=%s, Subject=%s",
+		event.EventData.RequestId, event.EventData.WorkerType, event.EventData.ErrorReason, subject)
 
-	log.Printf("⚠️💰 [NATS] PayoutFailed event: RequestId=%s, WorkerType=%d, Error=%s, chain ID=%d",
-		event.EventData.RequestId, event.EventData.WorkerType, event.EventData.ErrorReason, chainID)
-
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessPayoutFailed(event); err != nil {
-			log.Printf("❌ [NATS] PayoutFailed process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] PayoutFailed process success")
-		}
+	if err := dispatchEvent("PayoutFailed", subject, event); err != nil {
+		log.Printf("❌ [NATS] PayoutFailed process failed: %v", err)
 	}
 }
 
 // handleHookExecutedEvent processes IntentManager.HookExecuted event
 func handleHookExecutedEvent(event *clients.EventHookExecutedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
-
-	log.Printf("🎉🎣 [NATS] HookExecuted event: RequestId=%s, chain ID=%d",
-		event.EventData.RequestId, chainID)
+	log.Printf("🎉🎣 [NATS] HookExecuted event: RequestId=%s, Subject=%s", event.EventData.RequestId, subject)
 
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessHookExecuted(event); err != nil {
-			log.Printf("❌ [NATS] HookExecuted process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] HookExecuted process success")
-		}
+	if err := dispatchEvent("HookExecuted", subject, event); err != nil {
+		log.Printf("❌ [NATS] HookExecuted process failed: %v", err)
 	}
 }
 
 // handleHookFailedEvent processes IntentManager.HookFailed event
 func handleHookFailedEvent(event *clients.EventHookFailedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
-
-	log.Printf("⚠️🎣 [NATS] HookFailed event: RequestId=%s, chain ID=%d",
-		event.EventData.RequestId, chainID)
+	log.Printf("⚠️🎣 [NATS] HookFailed event: RequestId=%s, Subject=%s", event.EventData.RequestId, subject)
 
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessHookFailed(event); err != nil {
-			log.Printf("❌ [NATS] HookFailed process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] HookFailed process success")
-		}
+	if err := dispatchEvent("HookFailed", subject, event); err != nil {
+		log.Printf("❌ [NATS] HookFailed process failed: %v", err)
 	}
 }
 
 // handleFallbackTransferredEvent processes IntentManager.FallbackTransferred event
 func handleFallbackTransferredEvent(event *clients.EventFallbackTransferredResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
+	log.Printf("✅🔄 [NATS] FallbackTransferred event: RequestId=%s, Subject=%s", event.EventData.RequestId, subject)
 
-	log.Printf("✅🔄 [NATS] FallbackTransferred event: RequestId=%s, chain ID=%d",
-		event.EventData.RequestId, chainID)
-
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessFallbackTransferred(event); err != nil {
-			log.Printf("❌ [NATS] FallbackTransferred process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] FallbackTransferred process success")
-		}
+	if err := dispatchEvent("FallbackTransferred", subject, event); err != nil {
+		log.Printf("❌ [NATS] FallbackTransferred process failed: %v", err)
 	}
 }
 
 // handleFallbackFailedEvent processes IntentManager.FallbackFailed event
 func handleFallbackFailedEvent(event *clients.EventFallbackFailedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
-
-	log.Printf("⚠️🔄 [NATS] FallbackFailed event: RequestId=%s, Error=%s, chain ID=%d",
-		event.EventData.RequestId, event.EventData.ErrorReason, chainID)
+	log.Printf("⚠️🔄 [NATS] FallbackFailed event: RequestId=%s, Error=%s, Subject=%s",
+		event.EventData.RequestId, event.EventData.ErrorReason, subject)
 
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessFallbackFailed(event); err != nil {
-			log.Printf("❌ [NATS] FallbackFailed process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] FallbackFailed process success")
-		}
+	if err := dispatchEvent("FallbackFailed", subject, event); err != nil {
+		log.Printf("❌ [NATS] FallbackFailed process failed: %v", err)
 	}
 }
 
 // handlePayoutRetryRecordCreatedEvent processes Treasury.PayoutRetryRecordCreated event
 func handlePayoutRetryRecordCreatedEvent(event *clients.EventPayoutRetryRecordCreatedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
+	log.Printf("📝🔄 [NATS] PayoutRetryRecordCreated event: RecordId=%s, RequestId=%s, Subject=%s",
+		event.EventData.RecordId, event.EventData.RequestId, subject)
 
-	log.Printf("📝🔄 [NATS] PayoutRetryRecordCreated event: RecordId=%s, RequestId=%s, chain ID=%d",
-		event.EventData.RecordId, event.EventData.RequestId, chainID)
-
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessPayoutRetryRecordCreated(event); err != nil {
-			log.Printf("❌ [NATS] PayoutRetryRecordCreated process failed: %v", err)
-		}
+	if err := dispatchEvent("PayoutRetryRecordCreated", subject, event); err != nil {
+		log.Printf("❌ [NATS] PayoutRetryRecordCreated process failed: %v", err)
 	}
 }
 
 // handleFallbackRetryRecordCreatedEvent processes Treasury.FallbackRetryRecordCreated event
 func handleFallbackRetryRecordCreatedEvent(event *clients.EventFallbackRetryRecordCreatedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
+	log.Printf("📝🔄 [NATS] FallbackRetryRecordCreated event: RecordId=%s, RequestId=%s, Subject=%s",
+		event.EventData.RecordId, event.EventData.RequestId, subject)
 
-	log.Printf("📝🔄 [NATS] FallbackRetryRecordCreated event: RecordId=%s, RequestId=%s, chain ID=%d",
-		event.EventData.RecordId, event.EventData.RequestId, chainID)
-
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessFallbackRetryRecordCreated(event); err != nil {
-			log.Printf("❌ [NATS] FallbackRetryRecordCreated process failed: %v", err)
-		}
+	if err := dispatchEvent("FallbackRetryRecordCreated", subject, event); err != nil {
+		log.Printf("❌ [NATS] FallbackRetryRecordCreated process failed: %v", err)
 	}
 }
 
 // handleManuallyResolvedEvent processes ZKPayProxy.ManuallyResolved event
 func handleManuallyResolvedEvent(event *clients.EventManuallyResolvedResponse, subject string) {
-	chainID, err := utils.GetSlip44ChainIDFromSubject(subject)
-	if err != nil {
-		chainID = utils.EvmToSlip44(int(event.ChainID))
-	}
+	log.Printf("✅🔧 [NATS] ManuallyResolved event: RequestId=%s, Resolver=%s, Note=%s, Subject=%s",
+		event.EventData.RequestId, event.EventData.Resolver, event.EventData.Note, subject)
 
-	log.Printf("✅🔧 [NATS] ManuallyResolved event: RequestId=%s, Resolver=%s, Note=%s, chain ID=%d",
-		event.EventData.RequestId, event.EventData.Resolver, event.EventData.Note, chainID)
-
-	if eventProcessor != nil {
-		if err := eventProcessor.ProcessManuallyResolved(event); err != nil {
-			log.Printf("❌ [NATS] ManuallyResolved process failed: %v", err)
-		} else {
-			log.Printf("✅ [NATS] ManuallyResolved process success")
-		}
+	if err := dispatchEvent("ManuallyResolved", subject, event); err != nil {
+		log.Printf("❌ [NATS] ManuallyResolved process failed: %v", err)
 	}
 }
 
@@ -716,8 +484,8 @@ func saveDepositToDatabase(deposit interface{}, owner, amount string, chainID in
 		newStatus := existingCheckbook.Status
 		statusReason := ""
 
-		// whether（BSC，ChainID=714）DepositRecordedevent
-		if chainID == 714 {
+		// whether the management chain (blockchain.management_chain_id) DepositRecordedevent
+		if chainID == config.GetManagementChainID() {
 			// DepositRecordedeventready_for_commitment
 			if shouldPromoteToReadyForCommitment(existingCheckbook.Status) {
 				newStatus = models.CheckbookStatusReadyForCommitment
@@ -862,14 +630,6 @@ func shouldPromoteToReadyForCommitment(currentStatus models.CheckbookStatus) boo
 	return currentOrder < readyForCommitmentOrder
 }
 
-// saveQueueRootToDatabase savequeue rootUpdateeventDatabase
-func saveQueueRootToDatabase(queueRoot interface{}, chainID int) error {
-	// note：alreadyBlockchainEventProcessor
-	// ，savealreadyeventprocesscompleted
-	log.Printf("💾 [DB] savequeue rootrecord - chain ID=%d (alreadyBlockchainEventProcessorprocess)", chainID)
-	return nil
-}
-
 // shouldPromoteToUnsigned whetherunsignedstatus
 func shouldPromoteToUnsigned(currentStatus models.CheckbookStatus) bool {
 	// pendingstatusunsigned
@@ -908,7 +668,7 @@ func GetEventProcessor() *services.BlockchainEventProcessor {
 		// pushservicealreadyInitialize
 		pushSvc := GetPushService()
 		dbWithPushSvc := GetDatabaseWithPushService()
-		eventProcessor = services.NewBlockchainEventProcessor(db.DB, pushSvc, dbWithPushSvc)
+		eventProcessor = services.NewBlockchainEventProcessor(db.DB, pushSvc, dbWithPushSvc, logging.New(config.GetLogFormat()))
 		log.Printf("✅ NATSinitializeblockchaineventprocess（WebSocketpush）")
 	})
 	return eventProcessor
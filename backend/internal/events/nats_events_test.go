@@ -0,0 +1,39 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+)
+
+// useEventProcessor makes GetEventProcessor return processor for the test
+func useEventProcessor(t *testing.T, processor *services.BlockchainEventProcessor) {
+	t.Helper()
+	eventProcessorOnce.Do(func() {})
+	previous := eventProcessor
+	t.Cleanup(func() { eventProcessor = previous })
+	eventProcessor = processor
+}
+
+func TestDispatchEventRoutesThroughProcessorDispatch(t *testing.T) {
+	useDeadLetterDB(t, 1)
+	useEventProcessor(t, &services.BlockchainEventProcessor{})
+
+	const subject = "zkpay.bsc.EnclavePay.SomethingNew"
+	err := dispatchEvent("SomethingNew", subject, map[string]string{"requestId": "0x01"})
+	if !errors.Is(err, services.ErrUnknownEvent) {
+		t.Fatalf("dispatchEvent = %v, want ErrUnknownEvent from Dispatch", err)
+	}
+
+	// The dead-lettered payload is what Dispatch received, so a replay takes the same path
+	var stored models.FailedEvent
+	if err := db.DB.First(&stored).Error; err != nil {
+		t.Fatalf("load failed_events: %v", err)
+	}
+	if stored.Subject != subject || stored.Payload != `{"requestId":"0x01"}` {
+		t.Errorf("stored %s %s, want %s with the dispatched payload", stored.Subject, stored.Payload, subject)
+	}
+}
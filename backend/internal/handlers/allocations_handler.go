@@ -596,6 +596,18 @@ func CreateAllocationsHandler(c *gin.Context) {
 		}
 	}()
 
+	// Amount reserved by the checks being replaced, released back to the deposit below
+	previousTotal, err := checkAmountTotal(tx, checkbook.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "DatabaseError",
+			"message": err.Error(),
+		})
+		return
+	}
+
 	// Delete existing checks (if any)
 	log.Printf("🗑️ Deleting existing checks for checkbook_id=%s", checkbook.ID)
 	if err := tx.Where("checkbook_id = ?", checkbook.ID).Delete(&models.Check{}).Error; err != nil {
@@ -704,6 +716,19 @@ func CreateAllocationsHandler(c *gin.Context) {
 		log.Printf("   [%d] ID=%s, Seq=%d, Amount=%s, Recipient=%s, Nullifier=%s", i+1, check.ID, check.Seq, amount, ownerAddress, nullifier)
 	}
 
+	// Reserve the new allocations from the deposit (guards against over-allocation)
+	if err := reserveDepositAllocations(tx, &checkbook, previousTotal, checks); err != nil {
+		tx.Rollback()
+		log.Printf("❌ Failed to reserve allocations from deposit: %v", err)
+		status, code := reservationErrorResponse(err)
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   code,
+			"message": "Failed to reserve allocations: " + err.Error(),
+		})
+		return
+	}
+
 	// Save checks to database
 	// If nullifier is empty string, it will be NULL in database (due to unique constraint)
 	if err := tx.Create(&checks).Error; err != nil {
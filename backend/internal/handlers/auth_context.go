@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authContextKey gin context key of the *AuthContext set by the auth middleware
+const authContextKey = "auth_context"
+
+// AuthContext authenticated user resolved from validated JWT claims
+type AuthContext struct {
+	UserAddress      string // wallet address as signed in (EVM hex or TRON Base58)
+	ChainID          uint32 // SLIP-44 chain ID
+	UniversalAddress string // canonical 32-byte Universal Address: 0x + 64 lowercase hex chars
+}
+
+// NewAuthContext validates the universal_address claim and normalizes it to the canonical Universal Address
+// The claim may be "slip44ChainId:address" or a bare address; the chain prefix, when present, must match chain_id.
+// The address may be a 32-byte Universal Address, a 20-byte EVM address or a TRON Base58 address; an empty claim
+// falls back to user_address. Anything else is rejected.
+func NewAuthContext(claims *JWTClaims) (*AuthContext, error) {
+	if claims.ChainID <= 0 {
+		return nil, fmt.Errorf("invalid chain_id claim: %d", claims.ChainID)
+	}
+
+	address := strings.TrimSpace(claims.UniversalAddress)
+	if prefix, rest, found := strings.Cut(address, ":"); found {
+		prefixChainID, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain prefix in universal_address claim: %q", claims.UniversalAddress)
+		}
+		if prefixChainID != claims.ChainID {
+			return nil, fmt.Errorf("universal_address claim chain %d does not match chain_id claim %d", prefixChainID, claims.ChainID)
+		}
+		address = rest
+	}
+	if address == "" {
+		address = strings.TrimSpace(claims.UserAddress)
+	}
+
+	universalAddress, err := canonicalUniversalAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid universal_address claim: %w", err)
+	}
+
+	return &AuthContext{
+		UserAddress:      claims.UserAddress,
+		ChainID:          uint32(claims.ChainID),
+		UniversalAddress: universalAddress,
+	}, nil
+}
+
+// canonicalUniversalAddress converts a Universal, EVM or TRON address to 0x + 64 lowercase hex chars
+func canonicalUniversalAddress(address string) (string, error) {
+	var universal string
+	var err error
+	switch {
+	case address == "":
+		return "", fmt.Errorf("empty address")
+	case utils.IsTronAddress(address):
+		universal, err = utils.TronToUniversalAddress(address)
+	case utils.IsUniversalAddress(address):
+		universal, err = utils.PadToUniversalHex(address)
+	case utils.IsEvmAddress(address):
+		if _, err = utils.PadToUniversalHex(address); err == nil {
+			universal, err = utils.EvmToUniversalAddress(address)
+		}
+	default:
+		return "", fmt.Errorf("unsupported address format: %q", address)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(universal), nil
+}
+
+// OwnerAddress the authenticated user as the owner address stored on checkbooks and withdraw requests
+func (a *AuthContext) OwnerAddress() models.UniversalAddress {
+	return models.UniversalAddress{SLIP44ChainID: a.ChainID, Data: a.UniversalAddress}
+}
+
+// Owns reports whether owner (e.g. a withdraw request's OwnerAddress) is the authenticated user
+func (a *AuthContext) Owns(owner models.UniversalAddress) bool {
+	if owner.SLIP44ChainID != a.ChainID {
+		return false
+	}
+	ownerData, err := canonicalUniversalAddress(strings.TrimSpace(owner.Data))
+	return err == nil && ownerData == a.UniversalAddress
+}
+
+// SetAuthContext stores the authenticated user on the request, along with the legacy
+// user_address / universal_address / chain_id keys read by existing handlers
+func SetAuthContext(c *gin.Context, auth *AuthContext) {
+	c.Set(authContextKey, auth)
+	c.Set("user_address", auth.UserAddress)
+	c.Set("universal_address", auth.UniversalAddress)
+	c.Set("chain_id", int(auth.ChainID))
+}
+
+// GetAuthContext returns the authenticated user set by the auth middleware, ok=false when unauthenticated
+func GetAuthContext(c *gin.Context) (*AuthContext, bool) {
+	value, exists := c.Get(authContextKey)
+	if !exists {
+		return nil, false
+	}
+	auth, ok := value.(*AuthContext)
+	return auth, ok && auth != nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestNewAuthContextNormalizesClaims(t *testing.T) {
+	evmUniversal := "0x" + strings.Repeat("0", 24) + "52908400098527886e0f7030069857d2e4169ee7"
+	tronUniversal := "0x" + strings.Repeat("0", 24) + "a614f803b6fd780986a42c78ec9c7f77e6ded13c"
+
+	tests := []struct {
+		name   string
+		claims JWTClaims
+		want   string
+	}{
+		{"EVM chain-prefixed claim", JWTClaims{
+			UserAddress: "0x52908400098527886E0F7030069857D2E4169EE7", ChainID: 60,
+			UniversalAddress: "60:0x52908400098527886E0F7030069857D2E4169EE7",
+		}, evmUniversal},
+		{"EVM 32-byte claim", JWTClaims{
+			UserAddress: "0x52908400098527886E0F7030069857D2E4169EE7", ChainID: 714,
+			UniversalAddress: "0x" + strings.ToUpper(evmUniversal[2:]),
+		}, evmUniversal},
+		{"EVM claim falls back to user_address", JWTClaims{
+			UserAddress: "0x52908400098527886E0F7030069857D2E4169EE7", ChainID: 714,
+		}, evmUniversal},
+		{"TRON chain-prefixed claim", JWTClaims{
+			UserAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", ChainID: 195,
+			UniversalAddress: "195:TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		}, tronUniversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := NewAuthContext(&tt.claims)
+			if err != nil {
+				t.Fatalf("NewAuthContext: %v", err)
+			}
+			if auth.UniversalAddress != tt.want {
+				t.Errorf("universal address = %s, want %s", auth.UniversalAddress, tt.want)
+			}
+			if !auth.Owns(models.UniversalAddress{SLIP44ChainID: uint32(tt.claims.ChainID), Data: "0x" + strings.ToUpper(tt.want[2:])}) {
+				t.Errorf("auth context does not own its address written in upper case")
+			}
+			if auth.Owns(models.UniversalAddress{SLIP44ChainID: uint32(tt.claims.ChainID) + 1, Data: tt.want}) {
+				t.Errorf("auth context owns its address on another chain")
+			}
+		})
+	}
+}
+
+func TestNewAuthContextRejectsMalformedClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims JWTClaims
+	}{
+		{"not an address", JWTClaims{ChainID: 60, UniversalAddress: "60:not-an-address"}},
+		{"chain prefix mismatch", JWTClaims{ChainID: 60, UniversalAddress: "714:0x52908400098527886E0F7030069857D2E4169EE7"}},
+		{"non-numeric chain prefix", JWTClaims{ChainID: 60, UniversalAddress: "eth:0x52908400098527886E0F7030069857D2E4169EE7"}},
+		{"truncated hex", JWTClaims{ChainID: 60, UniversalAddress: "0x5290840009852788"}},
+		{"missing chain", JWTClaims{UniversalAddress: "0x52908400098527886E0F7030069857D2E4169EE7"}},
+		{"empty claims", JWTClaims{ChainID: 60}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if auth, err := NewAuthContext(&tt.claims); err == nil {
+				t.Errorf("NewAuthContext accepted %+v as %s", tt.claims, auth.UniversalAddress)
+			}
+		})
+	}
+}
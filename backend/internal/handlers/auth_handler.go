@@ -10,6 +10,7 @@ import (
 
 	"log"
 
+	"go-backend/internal/config"
 	"go-backend/internal/dto"
 	"go-backend/internal/utils"
 
@@ -17,9 +18,6 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWT - configuration
-var jwtSecret = []byte("zkpay-jwt-secret-key-2025") // use
-
 // AuthHandler process
 type AuthHandler struct{}
 
@@ -175,25 +173,33 @@ func (h *AuthHandler) evmToSlip44(evmChainID int) int {
 
 // JWT Token
 func (h *AuthHandler) generateJWTToken(userAddress, universalAddress string, chainID int) (string, error) {
-	// Claims
+	return SignJWTToken(config.GetJWTConfig(), userAddress, universalAddress, chainID)
+}
+
+// SignJWTToken signs a user token with the secret, issuer, audience and TTL of cfg
+func SignJWTToken(cfg config.JWTConfig, userAddress, universalAddress string, chainID int) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
 		UserAddress:      userAddress,
 		UniversalAddress: universalAddress,
 		ChainID:          chainID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "zkpay-backend",
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.TokenTTL())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    cfg.Issuer,
 			Subject:   userAddress,
 		},
 	}
+	if cfg.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{cfg.Audience}
+	}
 
 	// createtoken
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// token
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := token.SignedString([]byte(cfg.Secret))
 	if err != nil {
 		return "", fmt.Errorf("tokenfailed: %w", err)
 	}
@@ -203,13 +209,23 @@ func (h *AuthHandler) generateJWTToken(userAddress, universalAddress string, cha
 
 // ValidateJWTToken verifyJWT Token（use）
 func ValidateJWTToken(tokenString string) (*JWTClaims, error) {
+	return ValidateJWTTokenWithConfig(config.GetJWTConfig(), tokenString)
+}
+
+// ValidateJWTTokenWithConfig verifies a user token against the secret, issuer and audience of cfg
+func ValidateJWTTokenWithConfig(cfg config.JWTConfig, tokenString string) (*JWTClaims, error) {
+	options := []jwt.ParserOption{jwt.WithIssuer(cfg.Issuer)}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// verify
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf(": %v", token.Header["alg"])
 		}
-		return jwtSecret, nil
-	})
+		return []byte(cfg.Secret), nil
+	}, options...)
 
 	if err != nil {
 		return nil, fmt.Errorf("tokenfailed: %w", err)
@@ -445,7 +445,7 @@ func GetCheckbookByIDHandler(c *gin.Context) {
 
 	// Build response with token info and calculated remaining amount
 	responseData := gin.H{
-		"checkbook":        checkbook,
+		"checkbook":        newCheckbookResponse(newDecimalConverter(), checkbook),
 		"checks":           checks,
 		"checks_count":     len(checks),
 		"token":            tokenInfo,
@@ -494,7 +494,7 @@ func GetCheckbookHandler(c *gin.Context) {
 
 	// response， checkbook and checks
 	response := gin.H{
-		"checkbook": checkbook,
+		"checkbook": newCheckbookResponse(newDecimalConverter(), checkbook),
 		"checks":    checks,
 	}
 
@@ -813,7 +813,7 @@ func GetCheckbooksListHandler(c *gin.Context) {
 
 	// Prepare response
 	response := gin.H{
-		"data": checkbooks,
+		"data": newCheckbookResponses(checkbooks),
 		"pagination": gin.H{
 			"page":  page,
 			"size":  size,
@@ -912,7 +912,7 @@ func GetCheckbookByDepositHandler(c *gin.Context) {
 	// Respond
 	c.JSON(http.StatusOK, gin.H{
 		"success":   true,
-		"checkbook": checkbook,
+		"checkbook": newCheckbookResponse(newDecimalConverter(), checkbook),
 		"token":     tokenInfo,
 	})
 }
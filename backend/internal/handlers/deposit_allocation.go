@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// checkAmountTotal sums the amounts of a checkbook's current checks
+func checkAmountTotal(tx *gorm.DB, checkbookID string) (*big.Int, error) {
+	var amounts []string
+	if err := tx.Model(&models.Check{}).Where("checkbook_id = ?", checkbookID).Pluck("amount", &amounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load existing check amounts: %w", err)
+	}
+	return sumAmounts(amounts)
+}
+
+// reserveDepositAllocations moves the deposit's reservation from the replaced checks to the new ones
+// Must run in the transaction that replaces the checks; fails with repository.ErrInsufficientAllocatable on over-allocation.
+func reserveDepositAllocations(tx *gorm.DB, checkbook *models.Checkbook, released *big.Int, checks []models.Check) error {
+	amounts := make([]string, 0, len(checks))
+	for _, check := range checks {
+		amounts = append(amounts, check.Amount)
+	}
+	reserved, err := sumAmounts(amounts)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	depositRepo := repository.NewDepositInfoRepository(tx)
+	chainID := int64(checkbook.SLIP44ChainID)
+	if released.Sign() > 0 {
+		if err := depositRepo.ReleaseToDeposit(ctx, chainID, checkbook.LocalDepositID, released.String()); err != nil {
+			return err
+		}
+	}
+	return depositRepo.ReserveFromDeposit(ctx, chainID, checkbook.LocalDepositID, reserved.String())
+}
+
+// reservationErrorResponse maps a reservation error to an HTTP status and error code
+func reservationErrorResponse(err error) (int, string) {
+	if errors.Is(err, repository.ErrInsufficientAllocatable) {
+		return http.StatusBadRequest, "InsufficientAllocatable"
+	}
+	return http.StatusInternalServerError, "DatabaseError"
+}
+
+// sumAmounts adds decimal amount strings
+func sumAmounts(amounts []string) (*big.Int, error) {
+	total := new(big.Int)
+	for _, amount := range amounts {
+		value, ok := new(big.Int).SetString(amount, 10)
+		if !ok || value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid allocation amount: %q", amount)
+		}
+		total.Add(total, value)
+	}
+	return total, nil
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-backend/internal/repository"
+)
+
+func TestSumAmounts(t *testing.T) {
+	total, err := sumAmounts([]string{"1000000000000000000", "2500000000000000000", "0"})
+	if err != nil || total.String() != "3500000000000000000" {
+		t.Errorf("sumAmounts = %v, %v, want 3500000000000000000", total, err)
+	}
+	if total, err := sumAmounts(nil); err != nil || total.Sign() != 0 {
+		t.Errorf("sumAmounts(nil) = %v, %v, want 0", total, err)
+	}
+	for _, bad := range []string{"", "-1", "1.5", "0x10"} {
+		if _, err := sumAmounts([]string{"100", bad}); err == nil {
+			t.Errorf("sumAmounts accepted %q", bad)
+		}
+	}
+}
+
+func TestReservationErrorResponse(t *testing.T) {
+	wrapped := fmt.Errorf("%w: deposit 714/7 has 400, requested 600", repository.ErrInsufficientAllocatable)
+	if status, code := reservationErrorResponse(wrapped); status != http.StatusBadRequest || code != "InsufficientAllocatable" {
+		t.Errorf("insufficient allocatable = %d %s, want 400 InsufficientAllocatable", status, code)
+	}
+	if status, code := reservationErrorResponse(errors.New("connection reset")); status != http.StatusInternalServerError || code != "DatabaseError" {
+		t.Errorf("database error = %d %s, want 500 DatabaseError", status, code)
+	}
+}
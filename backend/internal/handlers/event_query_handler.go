@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventQueryHandler admin endpoint listing raw stored events
+type EventQueryHandler struct {
+	eventQueryService *services.EventQueryService
+}
+
+// NewEventQueryHandler creates a new EventQueryHandler
+func NewEventQueryHandler(eventQueryService *services.EventQueryService) *EventQueryHandler {
+	return &EventQueryHandler{eventQueryService: eventQueryService}
+}
+
+// ListEventsHandler lists events of a chain within a block range, ordered by block_number, log_index
+// GET /api/admin/events?chain_id=714&from_block=100&to_block=200&event_name=DepositReceived&page=1&page_size=50
+// event_name is optional (all event types when empty); from_block/to_block are inclusive.
+func (h *EventQueryHandler) ListEventsHandler(c *gin.Context) {
+	chainID, err := strconv.ParseInt(c.Query("chain_id"), 10, 64)
+	if err != nil || chainID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "chain_id is required and must be a positive integer"})
+		return
+	}
+	fromBlock, err := strconv.ParseUint(c.Query("from_block"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from_block is required and must be a non-negative integer"})
+		return
+	}
+	toBlock, err := strconv.ParseUint(c.Query("to_block"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to_block is required and must be a non-negative integer"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	result, err := h.eventQueryService.FindEventsInRange(c.Request.Context(), services.EventRangeQuery{
+		ChainID:   chainID,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		EventName: c.Query("event_name"),
+		Page:      page,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result.Events,
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       result.Total,
+			"total_pages": (result.Total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
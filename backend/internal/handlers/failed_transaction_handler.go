@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FailedTransactionHandler admin endpoints for browsing and retrying failed on-chain submissions
+type FailedTransactionHandler struct {
+	repo         repository.FailedTransactionRepository
+	retryService *services.FailedTransactionRetryService // nil when no blockchain service is available
+}
+
+// NewFailedTransactionHandler creates a new FailedTransactionHandler
+func NewFailedTransactionHandler(repo repository.FailedTransactionRepository, retryService *services.FailedTransactionRetryService) *FailedTransactionHandler {
+	return &FailedTransactionHandler{
+		repo:         repo,
+		retryService: retryService,
+	}
+}
+
+// ListFailedTransactionsHandler lists failed transactions, newest first
+// GET /api/admin/failed-transactions?status=pending&tx_type=withdraw&page=1&page_size=20
+func (h *FailedTransactionHandler) ListFailedTransactionsHandler(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	filter := repository.FailedTransactionFilter{
+		Status: models.FailedTransactionStatus(c.Query("status")),
+		TxType: models.FailedTransactionType(c.Query("tx_type")),
+	}
+	switch filter.Status {
+	case "", models.FailedTransactionStatusPending, models.FailedTransactionStatusRetrying,
+		models.FailedTransactionStatusRecovered, models.FailedTransactionStatusAbandoned:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, must be one of pending, retrying, recovered, abandoned"})
+		return
+	}
+	switch filter.TxType {
+	case "", models.FailedTransactionTypeWithdraw, models.FailedTransactionTypeCommitment:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tx_type, must be one of withdraw, commitment"})
+		return
+	}
+
+	failedTxs, total, err := h.repo.FindAll(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch failed transactions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    failedTxs,
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       total,
+			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// RetryFailedTransactionHandler re-submits one failed transaction now
+// POST /api/admin/failed-transactions/:id/retry
+func (h *FailedTransactionHandler) RetryFailedTransactionHandler(c *gin.Context) {
+	if h.retryService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Blockchain transaction service not available"})
+		return
+	}
+
+	failedTx, err := h.retryService.RetryFailedTransaction(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed transaction not found"})
+	case errors.Is(err, services.ErrFailedTransactionNotRetryable), errors.Is(err, services.ErrFailedTransactionMaxRetries):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "data": failedTx})
+	case err != nil && failedTx == nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry transaction", "details": err.Error()})
+	case err != nil:
+		// The attempt ran and failed; the record carries the new retry count and error
+		c.JSON(http.StatusBadGateway, gin.H{"success": false, "error": err.Error(), "data": failedTx})
+	default:
+		c.JSON(http.StatusOK, gin.H{"success": true, "data": failedTx})
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"log"
+
+	"go-backend/internal/db"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/internal/utils"
+)
+
+// Amounts are stored in management units (18 decimals). Responses keep the stored fields unchanged
+// and add native_* fields converted to the token's native decimals (e.g. USDT on TRON = 6).
+
+// checkbookResponse Checkbook with amounts in the token's native decimals
+type checkbookResponse struct {
+	models.Checkbook
+	NativeAmount            string `json:"native_amount"`
+	NativeGrossAmount       string `json:"native_gross_amount"`
+	NativeAllocatableAmount string `json:"native_allocatable_amount"`
+}
+
+// withdrawRequestResponse WithdrawRequest with amount in the token's native decimals
+type withdrawRequestResponse struct {
+	models.WithdrawRequest
+	NativeAmount string `json:"native_amount"`
+}
+
+// newDecimalConverter creates the converter deposit amounts were stored with (see services.NewTokenDecimalConverter)
+func newDecimalConverter() *utils.TokenAddressDecimalConverter {
+	return services.NewTokenDecimalConverter(db.DB)
+}
+
+// toNativeAmount converts a management amount to native decimals, returns the management amount if conversion fails
+func toNativeAmount(converter *utils.TokenAddressDecimalConverter, managementAmount string, chainID uint32, tokenID uint16) string {
+	if managementAmount == "" {
+		return ""
+	}
+	nativeAmount, err := converter.ConvertFromManagementAmount(managementAmount, int64(chainID), int(tokenID))
+	if err != nil {
+		log.Printf("⚠️ [NativeAmount] Convert failed (chain=%d, token=%d, amount=%s): %v", chainID, tokenID, managementAmount, err)
+		return managementAmount
+	}
+	return nativeAmount
+}
+
+// toNativeAmountByAddress converts a management amount to native decimals by token address,
+// returns the management amount if conversion fails
+func toNativeAmountByAddress(converter *utils.TokenAddressDecimalConverter, managementAmount string, chainID uint32, tokenAddress string) string {
+	if managementAmount == "" {
+		return ""
+	}
+	nativeAmount, err := converter.ConvertFromManagementAmountByAddress(managementAmount, int64(chainID), tokenAddress)
+	if err != nil {
+		log.Printf("⚠️ [NativeAmount] Convert failed (chain=%d, token=%s, amount=%s): %v", chainID, tokenAddress, managementAmount, err)
+		return managementAmount
+	}
+	return nativeAmount
+}
+
+// newCheckbookResponse builds a checkbook response
+// Checkbook amounts are converted back by token address, the same decimals DepositReceived stored them with
+func newCheckbookResponse(converter *utils.TokenAddressDecimalConverter, checkbook models.Checkbook) checkbookResponse {
+	return checkbookResponse{
+		Checkbook:               checkbook,
+		NativeAmount:            toNativeAmountByAddress(converter, checkbook.Amount, checkbook.SLIP44ChainID, checkbook.TokenAddress),
+		NativeGrossAmount:       toNativeAmountByAddress(converter, checkbook.GrossAmount, checkbook.SLIP44ChainID, checkbook.TokenAddress),
+		NativeAllocatableAmount: toNativeAmountByAddress(converter, checkbook.AllocatableAmount, checkbook.SLIP44ChainID, checkbook.TokenAddress),
+	}
+}
+
+// newCheckbookResponses builds checkbook responses for a list
+func newCheckbookResponses(checkbooks []models.Checkbook) []checkbookResponse {
+	converter := newDecimalConverter()
+	responses := make([]checkbookResponse, 0, len(checkbooks))
+	for _, checkbook := range checkbooks {
+		responses = append(responses, newCheckbookResponse(converter, checkbook))
+	}
+	return responses
+}
+
+// newWithdrawRequestResponse builds a withdraw request response
+// Amount is converted on the target chain; AssetToken requests use the token ID encoded in AssetID
+func newWithdrawRequestResponse(converter *utils.TokenAddressDecimalConverter, request models.WithdrawRequest) withdrawRequestResponse {
+	tokenID := uint16(0)
+	if request.IntentType == models.IntentTypeAssetToken && request.AssetID != "" {
+		if id, err := utils.GetTokenIDFromAssetID(request.AssetID); err == nil {
+			tokenID = id
+		}
+	}
+	return withdrawRequestResponse{
+		WithdrawRequest: request,
+		NativeAmount:    toNativeAmount(converter, request.Amount, request.TargetSLIP44ChainID, tokenID),
+	}
+}
+
+// newWithdrawRequestResponses builds withdraw request responses for a list
+func newWithdrawRequestResponses(requests []models.WithdrawRequest) []withdrawRequestResponse {
+	converter := newDecimalConverter()
+	responses := make([]withdrawRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		responses = append(responses, newWithdrawRequestResponse(converter, request))
+	}
+	return responses
+}
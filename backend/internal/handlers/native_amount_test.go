@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+)
+
+func TestCheckbookResponseConvertsByTokenAddress(t *testing.T) {
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{Tokens: config.TokenDecimalConfig{TokenAddressDecimals: map[int]map[string]int{
+		60: {"0xdAC17F958D2ee523a2206206994597C13D831ec7": 6},
+	}}}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	responses := newCheckbookResponses([]models.Checkbook{
+		{
+			ID:                "cb-usdt",
+			SLIP44ChainID:     60,
+			TokenAddress:      "0xdac17f958d2ee523a2206206994597c13d831ec7",
+			Amount:            "1500000000000000000",
+			GrossAmount:       "1600000000000000000",
+			AllocatableAmount: "1500000123456789012",
+		},
+		{ID: "cb-unknown", SLIP44ChainID: 714, TokenAddress: "0x0000000000000000000000000000000000000001", Amount: "1000"},
+	})
+
+	body, err := json.Marshal(responses[0])
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	want := map[string]string{
+		"amount":                    "1500000000000000000",
+		"native_amount":             "1500000",
+		"native_gross_amount":       "1600000",
+		"native_allocatable_amount": "1500000", // below one native unit is truncated
+	}
+	for field, value := range want {
+		if got[field] != value {
+			t.Errorf("%s = %v, want %s", field, got[field], value)
+		}
+	}
+
+	if got := responses[1].NativeAmount; got != "1000" {
+		t.Errorf("native_amount of an unconfigured 18-decimal token = %s, want 1000", got)
+	}
+}
@@ -296,6 +296,19 @@ func BuildCommitmentHandler(c *gin.Context) {
 	// - submission_failed → signaturing (retry after submission failure, regenerate commitment)
 	log.Printf("ℹ️ Starting commitment creation process, current status='%s', deposit_id=%s", checkbook.Status, req.DepositID)
 
+	// Amount reserved by the checks being replaced, released back to the deposit below
+	previousTotal, err := checkAmountTotal(tx, checkbook.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":   false,
+			"error":     "DatabaseError",
+			"message":   err.Error(),
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
+		return
+	}
+
 	// 5. deletedata（！SupportCreate）
 	log.Printf("🗑️ deletecheckbook_id=%s", checkbook.ID)
 	deleteResult := tx.Where("checkbook_id = ?", checkbook.ID).Delete(&models.Check{})
@@ -412,6 +425,20 @@ func BuildCommitmentHandler(c *gin.Context) {
 		log.Printf("    %d: ID=%s, Seq=%d, RecipientAddress=%s, Amount=%s, Nullifier=%s", i+1, check.ID, check.Seq, allocation.RecipientAddress, allocation.Amount, nullifier)
 	}
 
+	// Reserve the new allocations from the deposit (guards against over-allocation)
+	if err := reserveDepositAllocations(tx, &checkbook, previousTotal, checks); err != nil {
+		log.Printf("❌ Failed to reserve allocations from deposit: %v", err)
+		tx.Rollback()
+		status, code := reservationErrorResponse(err)
+		c.JSON(status, gin.H{
+			"success":   false,
+			"error":     code,
+			"message":   "Failed to reserve allocations: " + err.Error(),
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
+		return
+	}
+
 	// Create checks
 	// If nullifier is empty string, use Omit to set it to NULL in database (to avoid unique constraint violation)
 	if hasCommitment {
@@ -487,28 +514,16 @@ func BuildCommitmentHandler(c *gin.Context) {
 	// recipient 和 token 信息在 commitment 级别，不在 allocation 级别
 	var zkvmAllocations []clients.CommitmentAllocationRequest
 	for i, alloc := range req.Allocations {
-		// 将 amount 转换为 32 字节 HEX 格式（如果还不是）
-		amountHex := alloc.Amount
-		if !strings.HasPrefix(amountHex, "0x") {
-			// 假设是十进制字符串，转换为 HEX
-			amountBig, ok := new(big.Int).SetString(amountHex, 10)
-			if !ok {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"success":   false,
-					"error":     "ValidationError",
-					"message":   fmt.Sprintf("Invalid amount format for allocation %d: %s", i, alloc.Amount),
-					"timestamp": time.Now().Format(time.RFC3339),
-				})
-				return
-			}
-			// 转换为 32 字节 HEX（64 个十六进制字符）
-			amountHex = fmt.Sprintf("%064x", amountBig)
-		} else {
-			// 移除 0x 前缀，确保是 64 个字符
-			amountHex = strings.TrimPrefix(amountHex, "0x")
-			if len(amountHex) < 64 {
-				amountHex = strings.Repeat("0", 64-len(amountHex)) + amountHex
-			}
+		// 将 amount 转换为 32 字节 HEX 格式（十进制或 0x 十六进制，不超过 uint256）
+		amountHex, err := services.AllocationAmountHex(alloc.Amount)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":   false,
+				"error":     "ValidationError",
+				"message":   fmt.Sprintf("Invalid amount for allocation %d: %v", i, err),
+				"timestamp": time.Now().Format(time.RFC3339),
+			})
+			return
 		}
 
 		// ZKVM commitment allocations 只需要 seq 和 amount
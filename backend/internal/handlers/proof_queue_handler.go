@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProofQueueHandler exposes proof generation queue statistics
+type ProofQueueHandler struct {
+	proofGenerationService *services.ProofGenerationService
+}
+
+// NewProofQueueHandler creates a new ProofQueueHandler
+func NewProofQueueHandler(proofGenerationService *services.ProofGenerationService) *ProofQueueHandler {
+	return &ProofQueueHandler{
+		proofGenerationService: proofGenerationService,
+	}
+}
+
+// GetQueueStatsHandler returns pending/in-progress counts and average wait/generation times
+// GET /api/admin/proof-queue/stats
+func (h *ProofQueueHandler) GetQueueStatsHandler(c *gin.Context) {
+	if h.proofGenerationService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Proof generation service not available"})
+		return
+	}
+
+	stats, err := h.proofGenerationService.GetQueueStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get proof queue stats", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
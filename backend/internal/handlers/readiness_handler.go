@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readinessCheckTimeout timeout for each dependency check (checks run concurrently)
+const readinessCheckTimeout = 2 * time.Second
+
+// rpcPinger RPC clients checked by the readiness probe (implemented by services.BlockchainTransactionService)
+type rpcPinger interface {
+	GetClientCount() int
+	GetAllClientIDs() []int
+	PingClient(ctx context.Context, chainID int) (uint64, error)
+}
+
+// zkvmPinger ZKVM service checked by the readiness probe (implemented by clients.ZKVMClient)
+type zkvmPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ReadinessHandler readiness probe for k8s
+// Returns 200 only when the database, every RPC client and the ZKVM service respond
+type ReadinessHandler struct {
+	db      *gorm.DB
+	rpc     rpcPinger
+	zkvm    zkvmPinger
+	timeout time.Duration
+}
+
+// NewReadinessHandler creates a readiness handler, nil dependencies are reported as not configured
+func NewReadinessHandler(db *gorm.DB, rpc rpcPinger, zkvm zkvmPinger) *ReadinessHandler {
+	return &ReadinessHandler{
+		db:      db,
+		rpc:     rpc,
+		zkvm:    zkvm,
+		timeout: readinessCheckTimeout,
+	}
+}
+
+// DependencyStatus status of a single dependency
+type DependencyStatus struct {
+	Status string `json:"status"` // ok / error
+	Error  string `json:"error,omitempty"`
+	Block  uint64 `json:"block,omitempty"` // latest block (RPC clients only)
+}
+
+// ReadinessResponse readiness probe response
+type ReadinessResponse struct {
+	Status   string                      `json:"status"` // ok / unavailable
+	Reason   string                      `json:"reason,omitempty"`
+	Database DependencyStatus            `json:"database"`
+	RPC      map[string]DependencyStatus `json:"rpc"` // chainID -> status
+	ZKVM     DependencyStatus            `json:"zkvm"`
+}
+
+// ReadinessHandler checks all dependencies
+// GET /api/health/ready
+func (h *ReadinessHandler) ReadinessHandler(c *gin.Context) {
+	response := h.check(c.Request.Context())
+	if response.Status != "ok" {
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// check runs all dependency checks concurrently, each with its own timeout
+func (h *ReadinessHandler) check(ctx context.Context) ReadinessResponse {
+	response := ReadinessResponse{RPC: make(map[string]DependencyStatus)}
+
+	var chainIDs []int
+	if h.rpc != nil {
+		chainIDs = h.rpc.GetAllClientIDs()
+		sort.Ints(chainIDs)
+	}
+	rpcResults := make([]DependencyStatus, len(chainIDs))
+
+	var wg sync.WaitGroup
+	wg.Add(2 + len(chainIDs))
+	go func() {
+		defer wg.Done()
+		response.Database = h.checkDatabase(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		response.ZKVM = h.checkZKVM(ctx)
+	}()
+	for i, chainID := range chainIDs {
+		go func(i, chainID int) {
+			defer wg.Done()
+			rpcResults[i] = h.checkRPC(ctx, chainID)
+		}(i, chainID)
+	}
+	wg.Wait()
+
+	// First failing reason in a fixed order: database, rpc (by chain ID), zkvm
+	var reasons []string
+	if response.Database.Status != "ok" {
+		reasons = append(reasons, "database: "+response.Database.Error)
+	}
+	if h.rpc == nil || h.rpc.GetClientCount() == 0 {
+		reasons = append(reasons, "rpc: no RPC clients initialized")
+	}
+	for i, chainID := range chainIDs {
+		response.RPC[strconv.Itoa(chainID)] = rpcResults[i]
+		if rpcResults[i].Status != "ok" {
+			reasons = append(reasons, fmt.Sprintf("rpc %d: %s", chainID, rpcResults[i].Error))
+		}
+	}
+	if response.ZKVM.Status != "ok" {
+		reasons = append(reasons, "zkvm: "+response.ZKVM.Error)
+	}
+
+	response.Status = "ok"
+	if len(reasons) > 0 {
+		response.Status = "unavailable"
+		response.Reason = reasons[0]
+	}
+	return response
+}
+
+func (h *ReadinessHandler) checkDatabase(ctx context.Context) DependencyStatus {
+	if h.db == nil {
+		return dependencyError(fmt.Errorf("not configured"))
+	}
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return dependencyError(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return dependencyError(err)
+	}
+	return DependencyStatus{Status: "ok"}
+}
+
+func (h *ReadinessHandler) checkRPC(ctx context.Context, chainID int) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	blockNumber, err := h.rpc.PingClient(ctx, chainID)
+	if err != nil {
+		return dependencyError(err)
+	}
+	return DependencyStatus{Status: "ok", Block: blockNumber}
+}
+
+func (h *ReadinessHandler) checkZKVM(ctx context.Context) DependencyStatus {
+	if h.zkvm == nil {
+		return dependencyError(fmt.Errorf("not configured"))
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	if err := h.zkvm.Ping(ctx); err != nil {
+		return dependencyError(err)
+	}
+	return DependencyStatus{Status: "ok"}
+}
+
+func dependencyError(err error) DependencyStatus {
+	return DependencyStatus{Status: "error", Error: err.Error()}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// stubRPC RPC clients by chain ID; a chain in errs fails its ping, a chain in hang blocks until the ctx is done
+type stubRPC struct {
+	blocks map[int]uint64
+	errs   map[int]error
+	hang   map[int]bool
+}
+
+func (s *stubRPC) GetClientCount() int { return len(s.blocks) }
+
+func (s *stubRPC) GetAllClientIDs() []int {
+	ids := make([]int, 0, len(s.blocks))
+	for id := range s.blocks {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (s *stubRPC) PingClient(ctx context.Context, chainID int) (uint64, error) {
+	if s.hang[chainID] {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	if err := s.errs[chainID]; err != nil {
+		return 0, err
+	}
+	return s.blocks[chainID], nil
+}
+
+type stubZKVM struct{ err error }
+
+func (s stubZKVM) Ping(ctx context.Context) error { return s.err }
+
+// pingConnector a database/sql connector whose connections only answer pings, with err
+type pingConnector struct{ err error }
+
+func (c pingConnector) Connect(context.Context) (driver.Conn, error) { return pingConn(c), nil }
+func (c pingConnector) Driver() driver.Driver                        { return pingDriver{} }
+
+type pingDriver struct{}
+
+func (pingDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("pingDriver: use sql.OpenDB")
+}
+
+type pingConn struct{ err error }
+
+func (c pingConn) Ping(context.Context) error { return c.err }
+func (c pingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("pingConn: queries not supported")
+}
+func (c pingConn) Close() error { return nil }
+func (c pingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("pingConn: transactions not supported")
+}
+
+func openPingDB(t *testing.T, pingErr error) *gorm.DB {
+	t.Helper()
+	sqlDB := sql.OpenDB(pingConnector{err: pingErr})
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	return db
+}
+
+func serveReadiness(t *testing.T, h *ReadinessHandler) (int, ReadinessResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/health/ready", nil)
+	h.ReadinessHandler(c)
+
+	var response ReadinessResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response %s: %v", recorder.Body.String(), err)
+	}
+	return recorder.Code, response
+}
+
+func TestReadinessHealthy(t *testing.T) {
+	h := NewReadinessHandler(openPingDB(t, nil), &stubRPC{blocks: map[int]uint64{56: 100, 714: 200}}, stubZKVM{})
+
+	code, response := serveReadiness(t, h)
+	if code != http.StatusOK || response.Status != "ok" || response.Reason != "" {
+		t.Fatalf("code=%d status=%s reason=%q, want 200 ok", code, response.Status, response.Reason)
+	}
+	if response.RPC["56"].Block != 100 || response.RPC["714"].Block != 200 {
+		t.Errorf("rpc = %+v, want blocks 100 and 200", response.RPC)
+	}
+}
+
+func TestReadinessDegraded(t *testing.T) {
+	tests := []struct {
+		name       string
+		db         func(t *testing.T) *gorm.DB
+		rpc        rpcPinger
+		zkvm       zkvmPinger
+		wantReason string
+	}{
+		{
+			name:       "database down",
+			db:         func(t *testing.T) *gorm.DB { return openPingDB(t, errors.New("connection refused")) },
+			rpc:        &stubRPC{blocks: map[int]uint64{56: 100}},
+			zkvm:       stubZKVM{},
+			wantReason: "database: connection refused",
+		},
+		{
+			name:       "database not configured",
+			db:         func(t *testing.T) *gorm.DB { return nil },
+			rpc:        &stubRPC{blocks: map[int]uint64{56: 100}},
+			zkvm:       stubZKVM{},
+			wantReason: "database: not configured",
+		},
+		{
+			name:       "no RPC clients",
+			db:         func(t *testing.T) *gorm.DB { return openPingDB(t, nil) },
+			rpc:        &stubRPC{},
+			zkvm:       stubZKVM{},
+			wantReason: "rpc: no RPC clients initialized",
+		},
+		{
+			name:       "one RPC client failing",
+			db:         func(t *testing.T) *gorm.DB { return openPingDB(t, nil) },
+			rpc:        &stubRPC{blocks: map[int]uint64{56: 100, 714: 200}, errs: map[int]error{714: errors.New("502 bad gateway")}},
+			zkvm:       stubZKVM{},
+			wantReason: "rpc 714: 502 bad gateway",
+		},
+		{
+			name:       "RPC client hanging",
+			db:         func(t *testing.T) *gorm.DB { return openPingDB(t, nil) },
+			rpc:        &stubRPC{blocks: map[int]uint64{56: 100}, hang: map[int]bool{56: true}},
+			zkvm:       stubZKVM{},
+			wantReason: "rpc 56: " + context.DeadlineExceeded.Error(),
+		},
+		{
+			name:       "ZKVM down",
+			db:         func(t *testing.T) *gorm.DB { return openPingDB(t, nil) },
+			rpc:        &stubRPC{blocks: map[int]uint64{56: 100}},
+			zkvm:       stubZKVM{err: errors.New("zkvm unreachable")},
+			wantReason: "zkvm: zkvm unreachable",
+		},
+		{
+			name:       "ZKVM not configured",
+			db:         func(t *testing.T) *gorm.DB { return openPingDB(t, nil) },
+			rpc:        &stubRPC{blocks: map[int]uint64{56: 100}},
+			zkvm:       nil,
+			wantReason: "zkvm: not configured",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewReadinessHandler(tt.db(t), tt.rpc, tt.zkvm)
+			h.timeout = 50 * time.Millisecond
+
+			code, response := serveReadiness(t, h)
+			if code != http.StatusServiceUnavailable || response.Status != "unavailable" {
+				t.Errorf("code=%d status=%s, want 503 unavailable", code, response.Status)
+			}
+			if !strings.HasPrefix(response.Reason, tt.wantReason) {
+				t.Errorf("reason = %q, want %q", response.Reason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Entity types accepted by ResendPushHandler
+const (
+	resendPushEntityCheckbook       = "checkbook"
+	resendPushEntityWithdrawRequest = "withdraw_request"
+)
+
+// ResendPushHandler re-broadcasts the current state of an entity to its WebSocket/SSE subscribers
+// Used when a status change happened while the push service was down and the frontend missed it.
+// WebSocket connections live inside the API server process, so this is an admin endpoint rather than a CLI.
+type ResendPushHandler struct {
+	db          *gorm.DB
+	pushService *services.WebSocketPushService
+}
+
+// NewResendPushHandler creates a new ResendPushHandler
+func NewResendPushHandler(db *gorm.DB, pushService *services.WebSocketPushService) *ResendPushHandler {
+	return &ResendPushHandler{
+		db:          db,
+		pushService: pushService,
+	}
+}
+
+// ResendPushRequest entity to re-broadcast
+type ResendPushRequest struct {
+	EntityType string `json:"entity_type" binding:"required"` // checkbook | withdraw_request
+	ID         string `json:"id" binding:"required"`
+}
+
+// ResendPushHandler loads the entity and pushes its current state
+// POST /api/admin/resend-push
+func (h *ResendPushHandler) ResendPushHandler(c *gin.Context) {
+	var req ResendPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if h.pushService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Push service not available"})
+		return
+	}
+
+	var status string
+	switch req.EntityType {
+	case resendPushEntityCheckbook:
+		var checkbook models.Checkbook
+		if err := h.db.First(&checkbook, "id = ?", req.ID).Error; err != nil {
+			h.respondLoadError(c, "Checkbook", err)
+			return
+		}
+		status = string(checkbook.Status)
+		h.pushService.PushCheckbookStatusUpdateDirect(&checkbook, status, "AdminResendPush")
+	case resendPushEntityWithdrawRequest:
+		var request models.WithdrawRequest
+		if err := h.db.First(&request, "id = ?", req.ID).Error; err != nil {
+			h.respondLoadError(c, "Withdraw request", err)
+			return
+		}
+		status = request.Status
+		h.pushService.PushWithdrawRequestStatusUpdateDirect(&request, status, "AdminResendPush")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid entity_type, expected checkbook or withdraw_request",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"entity_type": req.EntityType,
+		"id":          req.ID,
+		"status":      status,
+	})
+}
+
+// respondLoadError maps an entity lookup error to 404 / 500
+func (h *ResendPushHandler) respondLoadError(c *gin.Context, entity string, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": entity + " not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load " + entity, "details": err.Error()})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// serviceErrorStatuses HTTP status for each services.ServiceError code
+var serviceErrorStatuses = map[string]int{
+	services.CodeInvalidAllocations:           http.StatusBadRequest,
+	services.CodeAllocationsDifferentUser:     http.StatusBadRequest,
+	services.CodeAllocationNotInRequest:       http.StatusBadRequest,
+	services.CodeInvalidAllocationAmount:      http.StatusBadRequest,
+	services.CodeInvalidIntent:                http.StatusBadRequest,
+	services.CodeInvalidOverrideNullifier:     http.StatusBadRequest,
+	services.CodeTooManyAllocations:           http.StatusBadRequest,
+	services.CodeTooManyCheckbooks:            http.StatusBadRequest,
+	services.CodeMixedTokenAllocations:        http.StatusBadRequest,
+	services.CodeInvalidEventQuery:            http.StatusBadRequest,
+	services.CodeAllocationsNotIdle:           http.StatusConflict,
+	services.CodeAllocationsExceedAllocatable: http.StatusConflict,
+	services.CodeCannotCancel:                 http.StatusConflict,
+	services.CodeCannotRetry:                  http.StatusConflict,
+	services.CodeCannotManuallyResolve:        http.StatusConflict,
+	services.CodeRequestClosed:                http.StatusConflict,
+	services.CodeSignatureNotStored:           http.StatusConflict,
+	services.CodeProofNotCompleted:            http.StatusConflict,
+	services.CodeAlreadyExecuted:              http.StatusConflict,
+	services.CodeVerificationFailed:           http.StatusConflict,
+	services.CodeExecuteNotSuccessful:         http.StatusConflict,
+	services.CodePayoutNotCompleted:           http.StatusConflict,
+	services.CodePayoutAlreadyCompleted:       http.StatusConflict,
+	services.CodeAlreadyProcessing:            http.StatusConflict,
+	services.CodeHookNotFailed:                http.StatusConflict,
+	services.CodeHookCalldataMissing:          http.StatusConflict,
+	services.CodeIdempotencyKeyReused:         http.StatusUnprocessableEntity,
+	services.CodeMaxRetriesExceeded:           http.StatusTooManyRequests,
+	services.CodeRateLimited:                  http.StatusTooManyRequests,
+	services.CodeMalformedAllocationIDs:       http.StatusInternalServerError,
+	services.CodeBlockchainUnavailable:        http.StatusServiceUnavailable,
+}
+
+// ServiceErrorStatus maps a service error to an HTTP status
+// Unknown records map to 404; errors without a known ServiceError code get fallback.
+func ServiceErrorStatus(err error, fallback int) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	if status, ok := serviceErrorStatuses[services.ErrorCode(err)]; ok {
+		return status
+	}
+	return fallback
+}
+
+// respondServiceError writes err with the status mapped from its code, including the code when there is one
+func respondServiceError(c *gin.Context, err error, fallback int) {
+	body := gin.H{"error": err.Error()}
+	if code := services.ErrorCode(err); code != "" {
+		body["code"] = code
+	}
+	c.JSON(ServiceErrorStatus(err, fallback), body)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestServiceErrorStatusMapsEachCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{services.ErrInvalidAllocations, http.StatusBadRequest},
+		{services.ErrAllocationsDifferentUser, http.StatusBadRequest},
+		{services.ErrAllocationNotInRequest, http.StatusBadRequest},
+		{services.ErrInvalidAllocationAmount, http.StatusBadRequest},
+		{services.ErrInvalidIntent, http.StatusBadRequest},
+		{services.ErrInvalidOverrideNullifier, http.StatusBadRequest},
+		{services.ErrTooManyAllocations, http.StatusBadRequest},
+		{services.ErrTooManyCheckbooks, http.StatusBadRequest},
+		{services.ErrMixedTokenAllocations, http.StatusBadRequest},
+		{services.ErrInvalidEventQuery, http.StatusBadRequest},
+		{services.ErrAllocationsNotIdle, http.StatusConflict},
+		{services.ErrAllocationsExceedAllocatable, http.StatusConflict},
+		{services.ErrCannotCancel, http.StatusConflict},
+		{services.ErrCannotPartialCancel, http.StatusConflict},
+		{services.ErrCannotRetryProof, http.StatusConflict},
+		{services.ErrCannotRetryPayout, http.StatusConflict},
+		{services.ErrCannotRetryHook, http.StatusConflict},
+		{services.ErrCannotResign, http.StatusConflict},
+		{services.ErrCannotManuallyResolve, http.StatusConflict},
+		{services.ErrWithdrawRequestClosed, http.StatusConflict},
+		{services.ErrSignatureNotStored, http.StatusConflict},
+		{services.ErrProofNotCompleted, http.StatusConflict},
+		{services.ErrAlreadyExecuted, http.StatusConflict},
+		{services.ErrVerificationFailed, http.StatusConflict},
+		{services.ErrExecuteNotSuccessful, http.StatusConflict},
+		{services.ErrPayoutNotCompleted, http.StatusConflict},
+		{services.ErrPayoutAlreadyCompleted, http.StatusConflict},
+		{services.ErrAlreadyProcessing, http.StatusConflict},
+		{services.ErrHookNotFailed, http.StatusConflict},
+		{services.ErrHookCalldataMissing, http.StatusConflict},
+		{services.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity},
+		{services.ErrMaxRetriesExceeded, http.StatusTooManyRequests},
+		{services.ErrRateLimited, http.StatusTooManyRequests},
+		{services.ErrMalformedAllocationIDs, http.StatusInternalServerError},
+		{services.ErrBlockchainUnavailable, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(services.ErrorCode(tt.err), func(t *testing.T) {
+			wrapped := fmt.Errorf("request wr1: %w", tt.err)
+			if got := ServiceErrorStatus(wrapped, http.StatusTeapot); got != tt.want {
+				t.Errorf("ServiceErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceErrorStatusFallbacks(t *testing.T) {
+	if got := ServiceErrorStatus(fmt.Errorf("load: %w", gorm.ErrRecordNotFound), http.StatusBadRequest); got != http.StatusNotFound {
+		t.Errorf("record not found = %d, want 404", got)
+	}
+	if got := ServiceErrorStatus(errors.New("boom"), http.StatusBadGateway); got != http.StatusBadGateway {
+		t.Errorf("uncoded error = %d, want the fallback 502", got)
+	}
+}
+
+func TestRespondServiceErrorIncludesCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	respondServiceError(c, fmt.Errorf("cancel wr1: %w", services.ErrCannotCancel), http.StatusBadRequest)
+
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", recorder.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["code"] != services.CodeCannotCancel || body["error"] == "" {
+		t.Errorf("body = %v, want code %s and the error message", body, services.CodeCannotCancel)
+	}
+}
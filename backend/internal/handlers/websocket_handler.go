@@ -98,6 +98,7 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 		Conn:        conn,
 		Send:        make(chan []byte, 256),
 		LastPing:    time.Now(),
+		PushVersion: services.PushVersionFromRequest(r),
 	}
 	// Only register connection mapping, don't let pushService manage the connection
 	// This avoids double read/write goroutines that cause connection conflicts
@@ -137,8 +138,10 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 
 		// Set up pong handler for WebSocket protocol-level pong messages
+		// The push service reaps connections without a pong for pongWait
 		conn.SetPongHandler(func(string) error {
 			conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			pushConnection.Touch()
 			log.Printf("🏓 [WebSocket] Received WebSocket protocol-level pong from client %s", clientID)
 			return nil
 		})
@@ -215,6 +218,7 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 			if msgType, ok := rawMsg["type"].(string); ok && msgType == "ping" {
 				// Log ping received for debugging
 				log.Printf("📡 [WebSocket] Received ping from client %s", clientID)
+				pushConnection.Touch()
 
 				// Send pong response through channel to avoid concurrent write issues
 				// This ensures all writes go through the main write loop
@@ -279,7 +283,7 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 	// 3. Pong responses (pongChan)
 	// All writes go through this single loop to ensure thread safety
 	// Also send WebSocket protocol-level ping messages to keep connection alive
-	pingTicker := time.NewTicker(54 * time.Second)
+	pingTicker := time.NewTicker(h.pushService.PingInterval())
 	defer pingTicker.Stop()
 
 	log.Printf("✍️ [WebSocket] Write loop started for client %s", clientID)
@@ -343,6 +347,17 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 func (h *WebSocketHandler) handleSubscriptionMessage(clientID, userAddress string, msg *SubscriptionMessage) {
 	switch msg.Action {
 	case "subscribe":
+		// Address-based subscriptions are limited to the authenticated (JWT) address
+		if msg.Type != services.SubscriptionTypePrice {
+			if msg.Address == "" {
+				msg.Address = userAddress
+			}
+			if err := h.pushService.Subscribe(clientID, msg.Address); err != nil {
+				log.Printf("❌ Subscription rejected for %s: %v", clientID, err)
+				return
+			}
+		}
+
 		filter := &services.SubscriptionFilter{
 			Type:      msg.Type,
 			Address:   msg.Address,
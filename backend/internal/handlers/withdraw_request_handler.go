@@ -5,6 +5,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-backend/internal/db"
 	"go-backend/internal/models"
@@ -62,6 +63,138 @@ func NewWithdrawRequestHandler(repo repository.WithdrawRequestRepository, servic
 // User Withdraw Request Queries ()
 // ============================================================================
 
+// ListRecipientMismatchesHandler lists withdraw requests flagged with recipient_mismatch (admin, for investigation)
+// GET /api/admin/withdraw-requests/recipient-mismatches?page=1&page_size=20
+func (h *WithdrawRequestHandler) ListRecipientMismatchesHandler(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	requests, total, err := h.repo.FindRecipientMismatches(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch withdraw requests", "details": err.Error()})
+		return
+	}
+
+	results := make([]models.WithdrawRequest, 0, len(requests))
+	for _, req := range requests {
+		if req != nil {
+			results = append(results, *req)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    newWithdrawRequestResponses(results),
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       total,
+			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// ListPayoutManualReviewsHandler lists withdraw requests flagged with payout_needs_manual_review (admin)
+// GET /api/admin/withdraw-requests/payout-manual-review?page=1&page_size=20
+func (h *WithdrawRequestHandler) ListPayoutManualReviewsHandler(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	requests, total, err := h.repo.FindNeedsManualReview(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch withdraw requests", "details": err.Error()})
+		return
+	}
+
+	results := make([]models.WithdrawRequest, 0, len(requests))
+	for _, req := range requests {
+		if req != nil {
+			results = append(results, *req)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    newWithdrawRequestResponses(results),
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       total,
+			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetRetryHistoryHandler returns the payout / hook / fallback retries and failures of a withdraw request (admin)
+// GET /api/admin/withdraw-requests/:id/retry-history
+func (h *WithdrawRequestHandler) GetRetryHistoryHandler(c *gin.Context) {
+	history, err := h.withdrawService.GetRetryHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Withdraw request not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch retry history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    history,
+	})
+}
+
+// ManuallyResolveRequest body of ManuallyResolveHandler
+type ManuallyResolveRequest struct {
+	Note string `json:"note" binding:"required"` // Reason for closing out the request
+}
+
+// ManuallyResolveHandler forces a stuck withdraw request into manually_resolved (admin, no on-chain event)
+// POST /api/admin/withdraw-requests/:id/manually-resolve
+func (h *WithdrawRequestHandler) ManuallyResolveHandler(c *gin.Context) {
+	var req ManuallyResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A non-empty note is required"})
+		return
+	}
+
+	resolver := c.GetString("admin_username")
+	if resolver == "" {
+		resolver = "admin"
+	}
+
+	request, err := h.withdrawService.ManuallyResolve(c.Request.Context(), c.Param("id"), resolver, strings.TrimSpace(req.Note))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Withdraw request not found"})
+		case errors.Is(err, services.ErrCannotManuallyResolve):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve withdraw request", "details": err.Error()})
+		}
+		return
+	}
+
+	log.Printf("🛠️ [ManuallyResolve] %s resolved withdraw request %s: %s", resolver, request.ID, request.ResolutionNote)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    newWithdrawRequestResponse(newDecimalConverter(), *request),
+	})
+}
+
 // ListMyWithdrawRequestsHandler lists withdraw requests created by the authenticated user
 // GET /api/v2/my/withdraw-requests
 func (h *WithdrawRequestHandler) ListMyWithdrawRequestsHandler(c *gin.Context) {
@@ -91,30 +224,8 @@ func (h *WithdrawRequestHandler) ListMyWithdrawRequestsHandler(c *gin.Context) {
 		return
 	}
 
-	// Use universal_address from JWT if available (32-byte format), otherwise convert user_address
 	// WithdrawRequest stores owner_data as 32-byte Universal Address, so we need to match that format
-	var ownerData string
-	universalAddress, hasUniversal := c.Get("universal_address")
-	if hasUniversal {
-		// Middleware already parsed universal_address to pure address format (0x...)
-		if universalAddrStr, ok := universalAddress.(string); ok && universalAddrStr != "" {
-			ownerData = strings.ToLower(universalAddrStr)
-		}
-	}
-
-	// Fallback: convert 20-byte EVM address to 32-byte Universal Address format
-	if ownerData == "" {
-		userAddrStr := userAddress.(string)
-		// Convert 20-byte address to 32-byte Universal Address format
-		// Format: 0x + 12 zeros + 20-byte address = 0x000000000000000000000000 + address[2:]
-		if strings.HasPrefix(userAddrStr, "0x") && len(userAddrStr) == 42 {
-			// 20-byte address: pad with 12 zeros (24 hex chars) to make 32-byte
-			ownerData = "0x000000000000000000000000" + userAddrStr[2:]
-		} else {
-			// Use as-is if format is unexpected
-			ownerData = strings.ToLower(userAddrStr)
-		}
-	}
+	ownerData := myOwnerData(c, userAddress.(string))
 
 	requests, total, err := h.repo.FindByOwner(ctx, chainIDUint, ownerData, page, pageSize)
 	if err != nil {
@@ -137,7 +248,7 @@ func (h *WithdrawRequestHandler) ListMyWithdrawRequestsHandler(c *gin.Context) {
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"data":    filtered,
+			"data":    newWithdrawRequestResponses(filtered),
 			"pagination": gin.H{
 				"page":        page,
 				"page_size":   pageSize,
@@ -161,7 +272,7 @@ func (h *WithdrawRequestHandler) ListMyWithdrawRequestsHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    results,
+		"data":    newWithdrawRequestResponses(results),
 		"pagination": gin.H{
 			"page":        page,
 			"page_size":   pageSize,
@@ -174,22 +285,13 @@ func (h *WithdrawRequestHandler) ListMyWithdrawRequestsHandler(c *gin.Context) {
 // GetMyWithdrawRequestHandler gets a single withdraw request by ID
 // GET /api/v2/my/withdraw-requests/:id
 func (h *WithdrawRequestHandler) GetMyWithdrawRequestHandler(c *gin.Context) {
-	// Get authenticated user from context
-	userAddress, exists := c.Get("user_address")
-	if !exists {
+	// Get authenticated user from context (universal address already normalized by the auth middleware)
+	auth, ok := GetAuthContext(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	// Try to get universal_address from context (preferred for comparison)
-	universalAddress, hasUniversal := c.Get("universal_address")
-
-	chainID, exists := c.Get("chain_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Chain ID not found in auth context"})
-		return
-	}
-
 	requestID := c.Param("id")
 
 	// use Repository
@@ -200,64 +302,12 @@ func (h *WithdrawRequestHandler) GetMyWithdrawRequestHandler(c *gin.Context) {
 		return
 	}
 
-	chainIDUint, err := convertChainIDToUint32(chainID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain ID", "details": err.Error()})
-		return
-	}
-
-	// Compare chain ID
-	if request.OwnerAddress.SLIP44ChainID != chainIDUint {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Withdraw request not found or access denied"})
-		return
-	}
-
-	// Compare address: prefer universal_address if available, otherwise use user_address
-	// request.OwnerAddress.Data is 32-byte Universal Address (from checkbook.UserAddress)
-	// universalAddress from context is already parsed by middleware (pure address without chainId prefix)
-	// userAddress from JWT is 20-byte EVM address (needs conversion)
-	var addressMatch bool
-	if hasUniversal {
-		// Use universal_address for comparison (both are 32-byte Universal Address)
-		// Middleware already extracted the pure address part, so we can use it directly
-		universalAddrStr := universalAddress.(string)
-
-		// Normalize: remove 0x prefix and compare lowercase
-		normalizedUniversal := strings.ToLower(strings.TrimPrefix(universalAddrStr, "0x"))
-		normalizedOwnerData := strings.ToLower(strings.TrimPrefix(request.OwnerAddress.Data, "0x"))
-
-		// Debug logging
-		log.Printf("🔍 [GetMyWithdrawRequest] Address comparison:")
-		log.Printf("   JWT universal_address (from context): %s", universalAddrStr)
-		log.Printf("   Request OwnerAddress.Data: %s", request.OwnerAddress.Data)
-		log.Printf("   Normalized JWT: %s", normalizedUniversal)
-		log.Printf("   Normalized Owner: %s", normalizedOwnerData)
-
-		addressMatch = normalizedUniversal == normalizedOwnerData
-		log.Printf("   Match result: %v", addressMatch)
-	} else {
-		// Fallback: convert user_address to Universal Address format for comparison
-		// This should not happen in normal flow, but handle it for backward compatibility
-		userAddrStr := userAddress.(string)
-		normalizedUserAddr := strings.ToLower(strings.TrimPrefix(userAddrStr, "0x"))
-		normalizedOwnerData := strings.ToLower(strings.TrimPrefix(request.OwnerAddress.Data, "0x"))
-
-		// Debug logging
-		log.Printf("🔍 [GetMyWithdrawRequest] Address comparison (fallback):")
-		log.Printf("   JWT user_address: %s", userAddrStr)
-		log.Printf("   Request OwnerAddress.Data: %s", request.OwnerAddress.Data)
-		log.Printf("   Normalized JWT: %s", normalizedUserAddr)
-		log.Printf("   Normalized Owner: %s", normalizedOwnerData)
-
-		// Check if owner data ends with user address (32-byte Universal Address has 20-byte EVM address at the end)
-		addressMatch = strings.HasSuffix(normalizedOwnerData, normalizedUserAddr)
-		log.Printf("   Match result (suffix check): %v", addressMatch)
-	}
-
-	if !addressMatch {
+	// request.OwnerAddress is the 32-byte Universal Address of the checkbook owner
+	if !auth.Owns(request.OwnerAddress) {
 		log.Printf("❌ [GetMyWithdrawRequest] Address mismatch - request not found or access denied")
 		log.Printf("   Request ID: %s", requestID)
-		log.Printf("   Owner Chain ID: %d, JWT Chain ID: %d", request.OwnerAddress.SLIP44ChainID, chainIDUint)
+		log.Printf("   Owner: %d:%s, JWT: %d:%s", request.OwnerAddress.SLIP44ChainID, request.OwnerAddress.Data,
+			auth.ChainID, auth.UniversalAddress)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Withdraw request not found or access denied"})
 		return
 	}
@@ -266,7 +316,7 @@ func (h *WithdrawRequestHandler) GetMyWithdrawRequestHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    request,
+		"data":    newWithdrawRequestResponse(newDecimalConverter(), *request),
 	})
 }
 
@@ -308,7 +358,7 @@ func (h *WithdrawRequestHandler) GetMyWithdrawRequestByNullifierHandler(c *gin.C
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    request,
+		"data":    newWithdrawRequestResponse(newDecimalConverter(), *request),
 	})
 }
 
@@ -464,7 +514,7 @@ func (h *WithdrawRequestHandler) DeleteMyWithdrawRequestHandler(c *gin.Context)
 		// For pending/failed requests, use CancelWithdrawRequest service to properly release allocations
 		// This ensures Allocation status is correctly updated (pending -> idle)
 		if err := h.withdrawService.CancelWithdrawRequest(ctx, requestID); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			respondServiceError(c, err, http.StatusBadRequest)
 			return
 		}
 		// After cancelling, delete the request
@@ -484,6 +534,66 @@ func (h *WithdrawRequestHandler) DeleteMyWithdrawRequestHandler(c *gin.Context)
 	})
 }
 
+// GetMyWithdrawableBalanceHandler returns the authenticated user's withdrawable balance per token key
+// GET /api/my/withdraw-requests/withdrawable-balance
+// Sums idle allocations only; amounts are in management units and native decimals.
+func (h *WithdrawRequestHandler) GetMyWithdrawableBalanceHandler(c *gin.Context) {
+	userAddress, exists := c.Get("user_address")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	chainID, exists := c.Get("chain_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Chain ID not found in auth context"})
+		return
+	}
+	chainIDUint, err := convertChainIDToUint32(chainID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain ID", "details": err.Error()})
+		return
+	}
+
+	balances, err := h.withdrawService.GetWithdrawableBalance(c.Request.Context(), chainIDUint, myOwnerData(c, userAddress.(string)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get withdrawable balance", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    balances,
+	})
+}
+
+// myOwnerData returns the authenticated user's 32-byte Universal Address data
+// Uses universal_address from JWT if available (32-byte format), otherwise converts user_address.
+func myOwnerData(c *gin.Context, userAddrStr string) string {
+	var ownerData string
+	universalAddress, hasUniversal := c.Get("universal_address")
+	if hasUniversal {
+		// Middleware already parsed universal_address to pure address format (0x...)
+		if universalAddrStr, ok := universalAddress.(string); ok && universalAddrStr != "" {
+			ownerData = strings.ToLower(universalAddrStr)
+		}
+	}
+
+	// Fallback: convert 20-byte EVM address to 32-byte Universal Address format
+	if ownerData == "" {
+		// Convert 20-byte address to 32-byte Universal Address format
+		// Format: 0x + 12 zeros + 20-byte address = 0x000000000000000000000000 + address[2:]
+		if strings.HasPrefix(userAddrStr, "0x") && len(userAddrStr) == 42 {
+			// 20-byte address: pad with 12 zeros (24 hex chars) to make 32-byte
+			ownerData = "0x000000000000000000000000" + userAddrStr[2:]
+		} else {
+			// Use as-is if format is unexpected
+			ownerData = strings.ToLower(userAddrStr)
+		}
+	}
+	return ownerData
+}
+
 // ============================================================================
 // Statistics ()
 // ============================================================================
@@ -565,6 +675,9 @@ type CreateWithdrawRequestIntent struct {
 
 	// Common field (used for both RawToken and AssetToken):
 	TokenSymbol string `json:"tokenSymbol" binding:"required"` // Token symbol (RawToken: e.g., "USDT", AssetToken: e.g., "aUSDT")
+
+	// Optional Stage 4 hook calldata (0x-prefixed hex), executed via IntentManager after payout
+	HookCalldata string `json:"hookCalldata"`
 }
 
 // CreateWithdrawRequestRequest request body for creating a withdraw request
@@ -574,11 +687,22 @@ type CreateWithdrawRequestRequest struct {
 	Intent        CreateWithdrawRequestIntent `json:"intent" binding:"required"`
 	Signature     string                      `json:"signature" binding:"required"` // User signature for ZKVM proof generation
 	ChainID       uint32                      `json:"chainId" binding:"required"`   // Chain ID for signature (SLIP-44)
+	Lang          uint8                       `json:"lang"`                         // Optional ZKVM message language (default 0 = English)
 }
 
+// maxIdempotencyKeyLength matches the withdraw_requests.idempotency_key column size
+const maxIdempotencyKeyLength = 128
+
 // CreateWithdrawRequestHandler creates a new withdraw request (Intent system)
 // POST /api/v1/withdrawals
+// An optional Idempotency-Key header makes retries return the request created by the first attempt.
 func (h *WithdrawRequestHandler) CreateWithdrawRequestHandler(c *gin.Context) {
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength)})
+		return
+	}
+
 	var req CreateWithdrawRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		// Log detailed error for debugging
@@ -610,17 +734,21 @@ func (h *WithdrawRequestHandler) CreateWithdrawRequestHandler(c *gin.Context) {
 		},
 		TokenSymbol: req.Intent.TokenSymbol, // Common: token symbol (RawToken: "USDT", AssetToken: "aUSDT")
 		AssetID:     req.Intent.AssetID,     // For AssetToken
+
+		HookCalldata: req.Intent.HookCalldata, // Optional Stage 4 hook
 	}
 
 	// Create withdraw request
 	request, err := h.withdrawService.CreateWithdrawRequest(c.Request.Context(), &services.CreateWithdrawRequestInput{
-		AllocationIDs: req.AllocationIDs,
-		Intent:        intent,
-		Signature:     req.Signature,
-		ChainID:       req.ChainID,
+		AllocationIDs:  req.AllocationIDs,
+		Intent:         intent,
+		Signature:      req.Signature,
+		ChainID:        req.ChainID,
+		Lang:           req.Lang,
+		IdempotencyKey: idempotencyKey,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -630,6 +758,33 @@ func (h *WithdrawRequestHandler) CreateWithdrawRequestHandler(c *gin.Context) {
 	})
 }
 
+// PreviewCommitmentGroupsRequest request body for previewing commitment groups
+type PreviewCommitmentGroupsRequest struct {
+	AllocationIDs []string `json:"allocation_ids" binding:"required,min=1"`
+}
+
+// PreviewCommitmentGroupsHandler returns the CommitmentGroups a withdraw of these allocations would prove
+// POST /api/withdraws/preview-commitments
+// Nothing is created or locked; the UI shows the result before the user signs.
+func (h *WithdrawRequestHandler) PreviewCommitmentGroupsHandler(c *gin.Context) {
+	var req PreviewCommitmentGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commitmentGroups, err := h.withdrawService.PreviewCommitmentGroups(c.Request.Context(), req.AllocationIDs)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    commitmentGroups,
+	})
+}
+
 // SubmitProofRequest request body for submitting proof
 type SubmitProofRequest struct {
 	Proof        string `json:"proof" binding:"required"`
@@ -655,7 +810,7 @@ func (h *WithdrawRequestHandler) SubmitProofHandler(c *gin.Context) {
 	}
 
 	if err := h.withdrawService.SubmitProof(c.Request.Context(), requestID, req.Proof, req.PublicValues); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -685,7 +840,7 @@ func (h *WithdrawRequestHandler) ExecuteWithdrawHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.ExecuteWithdraw(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -701,7 +856,7 @@ func (h *WithdrawRequestHandler) RetryPayoutHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.RetryPayout(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -711,13 +866,61 @@ func (h *WithdrawRequestHandler) RetryPayoutHandler(c *gin.Context) {
 	})
 }
 
+// ResignWithdrawRequestHandler stores a new signature for the caller's request and restarts proof generation
+// POST /api/v2/my/withdraw-requests/:id/signature
+// Needed after a partial cancel, which clears the signature of the reduced request
+func (h *WithdrawRequestHandler) ResignWithdrawRequestHandler(c *gin.Context) {
+	userAddress, exists := c.Get("user_address")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	chainID, exists := c.Get("chain_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Chain ID not found in auth context"})
+		return
+	}
+
+	chainIDUint, err := convertChainIDToUint32(chainID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain ID", "details": err.Error()})
+		return
+	}
+
+	var req struct {
+		Signature string `json:"signature" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	requestID := c.Param("id")
+	request, err := h.repo.GetByID(c.Request.Context(), requestID)
+	if err != nil || request.OwnerAddress.SLIP44ChainID != chainIDUint || request.OwnerAddress.Data != userAddress.(string) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Withdraw request not found or access denied"})
+		return
+	}
+
+	if err := h.withdrawService.ResignWithdrawRequest(c.Request.Context(), requestID, req.Signature, chainIDUint); err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Signature updated, proof generation restarted",
+	})
+}
+
 // RetryHookHandler manually retries Hook purchase
 // POST /api/v1/withdrawals/:id/retry-hook
 func (h *WithdrawRequestHandler) RetryHookHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.RetryHook(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -733,7 +936,7 @@ func (h *WithdrawRequestHandler) RetryFallbackHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.RetryFallback(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -845,7 +1048,7 @@ func (h *WithdrawRequestHandler) CancelWithdrawRequestHandler(c *gin.Context) {
 
 	// Cancel the request (for pending/failed requests)
 	if err := h.withdrawService.CancelWithdrawRequest(ctx, requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -923,7 +1126,7 @@ func (h *WithdrawRequestHandler) RequestPayoutExecutionHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.RequestPayoutExecution(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -939,7 +1142,7 @@ func (h *WithdrawRequestHandler) ClaimTimeoutHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.ClaimTimeout(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -955,7 +1158,7 @@ func (h *WithdrawRequestHandler) RequestHookPurchaseHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.RequestHookPurchase(c.Request.Context(), requestID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -971,11 +1174,11 @@ func (h *WithdrawRequestHandler) WithdrawOriginalTokensHandler(c *gin.Context) {
 	requestID := c.Param("id")
 
 	if err := h.withdrawService.WithdrawOriginalTokens(c.Request.Context(), requestID); err != nil {
-		statusCode := http.StatusBadRequest
 		if err.Error() == "only beneficiary can withdraw original tokens" {
-			statusCode = http.StatusForbidden
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
 		}
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -1012,6 +1215,6 @@ func (h *WithdrawRequestHandler) GetWithdrawRequestHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    request,
+		"data":    newWithdrawRequestResponse(newDecimalConverter(), *request),
 	})
 }
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger structured logger with key-value fields
+// Usage: logger.Info("[ExecuteWithdraw] Transaction submitted", "request_id", id, "tx_hash", txHash)
+type Logger interface {
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New creates a logger for the given format ("text" or "json"), unknown formats fall back to text
+func New(format string) Logger {
+	if strings.EqualFold(format, FormatJSON) {
+		return NewJSONLogger()
+	}
+	return NewTextLogger()
+}
+
+// textLogger writes human-readable lines via the standard log package (same output as log.Printf)
+type textLogger struct{}
+
+// NewTextLogger creates a text logger: "<icon> <msg> key=value ..."
+func NewTextLogger() Logger {
+	return textLogger{}
+}
+
+func (textLogger) Info(msg string, keysAndValues ...interface{}) {
+	log.Printf("✅ %s%s", msg, formatFields(keysAndValues))
+}
+
+func (textLogger) Warn(msg string, keysAndValues ...interface{}) {
+	log.Printf("⚠️ %s%s", msg, formatFields(keysAndValues))
+}
+
+func (textLogger) Error(msg string, keysAndValues ...interface{}) {
+	log.Printf("❌ %s%s", msg, formatFields(keysAndValues))
+}
+
+// formatFields formats key-value pairs as " key=value key2=value2"
+func formatFields(keysAndValues []interface{}) string {
+	if len(keysAndValues) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 >= len(keysAndValues) {
+			fmt.Fprintf(&b, " %s=<missing>", key)
+			break
+		}
+		fmt.Fprintf(&b, " %s=%v", key, keysAndValues[i+1])
+	}
+	return b.String()
+}
+
+// jsonLogger writes one JSON object per line via logrus
+type jsonLogger struct {
+	logger *logrus.Logger
+}
+
+// NewJSONLogger creates a JSON logger: {"level":"info","msg":"...","request_id":"...",...}
+func NewJSONLogger() Logger {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	return &jsonLogger{logger: logger}
+}
+
+func (l *jsonLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.WithFields(toFields(keysAndValues)).Info(msg)
+}
+
+func (l *jsonLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.WithFields(toFields(keysAndValues)).Warn(msg)
+}
+
+func (l *jsonLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.WithFields(toFields(keysAndValues)).Error(msg)
+}
+
+// toFields converts key-value pairs to logrus fields, a trailing key without value is kept as "<missing>"
+func toFields(keysAndValues []interface{}) logrus.Fields {
+	fields := make(logrus.Fields, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 >= len(keysAndValues) {
+			fields[key] = "<missing>"
+			break
+		}
+		value := keysAndValues[i+1]
+		if err, ok := value.(error); ok {
+			value = err.Error() // errors marshal to {} otherwise
+		}
+		fields[key] = value
+	}
+	return fields
+}
@@ -116,6 +116,38 @@ var (
 		},
 		[]string{"chain", "address"},
 	)
+
+	// ============================================
+	// 证明生成队列指标
+	// ============================================
+	ProofQueueTasks = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backend_proof_queue_tasks",
+			Help: "Number of proof generation tasks by queue, status and attempt (fresh/retried)",
+		},
+		[]string{"queue", "status", "attempt"},
+	)
+
+	ProofQueueAvgWaitSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backend_proof_queue_avg_wait_seconds",
+			Help: "Average time between task creation and processing start in seconds",
+		},
+		[]string{"queue", "attempt"},
+	)
+
+	ProofQueueAvgGenerationSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backend_proof_queue_avg_generation_seconds",
+			Help: "Average ZKVM proof generation time in seconds",
+		},
+		[]string{"queue", "attempt"},
+	)
+
+	ProofQueueInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "backend_proof_queue_in_flight",
+		Help: "Number of proof generation tasks currently held by worker goroutines",
+	})
 )
 
 
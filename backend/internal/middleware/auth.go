@@ -97,29 +97,34 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Parse universal_address format: "chainId:0x..." or "0x..."
-		// Extract pure address part (without chainId prefix) for consistent usage
-		universalAddressData := claims.UniversalAddress
-		if strings.Contains(universalAddressData, ":") {
-			// Format: "chainId:0x..." - extract the address part after ":"
-			parts := strings.SplitN(universalAddressData, ":", 2)
-			if len(parts) == 2 {
-				universalAddressData = parts[1] // Get the part after ":"
-			}
+		// Normalize universal_address ("chainId:address" or bare address) to the canonical 32-byte Universal Address
+		auth, err := handlers.NewAuthContext(claims)
+		if err != nil {
+			a.logger.WithFields(logrus.Fields{
+				"path":   c.Request.URL.Path,
+				"method": c.Request.Method,
+				"error":  err.Error(),
+			}).Warn("JWTfailed - malformed claims")
+
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Invalid token claims",
+				"message": err.Error(),
+				"code":    "INVALID_TOKEN_CLAIMS",
+			})
+			c.Abort()
+			return
 		}
-		// If format is "0x...", use as is
 
 		// userstorage
-		c.Set("user_address", claims.UserAddress)
-		c.Set("universal_address", universalAddressData) // Store pure address (without chainId prefix)
-		c.Set("chain_id", claims.ChainID)
+		handlers.SetAuthContext(c, auth)
 
 		a.logger.WithFields(logrus.Fields{
 			"path":              c.Request.URL.Path,
 			"method":            c.Request.Method,
-			"user_address":      claims.UserAddress,
-			"universal_address": universalAddressData, // Log parsed address
-			"chain_id":          claims.ChainID,
+			"user_address":      auth.UserAddress,
+			"universal_address": auth.UniversalAddress,
+			"chain_id":          auth.ChainID,
 		}).Debug("JWTsuccess")
 
 		c.Next()
@@ -165,29 +170,28 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Parse universal_address format: "chainId:0x..." or "0x..."
-		// Extract pure address part (without chainId prefix) for consistent usage
-		universalAddressData := claims.UniversalAddress
-		if strings.Contains(universalAddressData, ":") {
-			// Format: "chainId:0x..." - extract the address part after ":"
-			parts := strings.SplitN(universalAddressData, ":", 2)
-			if len(parts) == 2 {
-				universalAddressData = parts[1] // Get the part after ":"
-			}
+		// Normalize universal_address ("chainId:address" or bare address) to the canonical 32-byte Universal Address
+		auth, err := handlers.NewAuthContext(claims)
+		if err != nil {
+			// malformed claims，continueuser
+			a.logger.WithFields(logrus.Fields{
+				"path":   c.Request.URL.Path,
+				"method": c.Request.Method,
+				"error":  err.Error(),
+			}).Debug("JWTfailed - malformed claims")
+			c.Next()
+			return
 		}
-		// If format is "0x...", use as is
 
 		// userstorage
-		c.Set("user_address", claims.UserAddress)
-		c.Set("universal_address", universalAddressData) // Store pure address (without chainId prefix)
-		c.Set("chain_id", claims.ChainID)
+		handlers.SetAuthContext(c, auth)
 
 		a.logger.WithFields(logrus.Fields{
 			"path":              c.Request.URL.Path,
 			"method":            c.Request.Method,
-			"user_address":      claims.UserAddress,
-			"universal_address": universalAddressData, // Log parsed address
-			"chain_id":          claims.ChainID,
+			"user_address":      auth.UserAddress,
+			"universal_address": auth.UniversalAddress,
+			"chain_id":          auth.ChainID,
 		}).Debug("JWTsuccess")
 
 		c.Next()
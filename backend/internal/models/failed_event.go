@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Failed event statuses
+const (
+	FailedEventStatusPending  = "pending"  // Waiting for replay
+	FailedEventStatusReplayed = "replayed" // Reprocessed successfully
+)
+
+// FailedEvent a NATS event that kept failing processing (dead letter)
+// Payload is the decoded event JSON (clients.Event*Response), replayable through BlockchainEventProcessor.Dispatch.
+type FailedEvent struct {
+	ID         uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageKey string     `json:"message_key" gorm:"size:64;not null;uniqueIndex"`        // sha256(subject, payload), one row per message
+	Subject    string     `json:"subject" gorm:"size:255;not null"`                       // e.g. zkpay.bsc.EnclavePay.DepositRecorded
+	EventType  string     `json:"event_type" gorm:"size:100;not null;index"`              // e.g. DepositRecorded
+	Payload    string     `json:"payload" gorm:"type:text;not null"`                      // event JSON
+	Attempts   int        `json:"attempts" gorm:"not null;default:0"`                     // processing attempts so far (live + replays)
+	LastError  string     `json:"last_error" gorm:"type:text"`                            // error of the last attempt
+	Status     string     `json:"status" gorm:"size:20;not null;default:'pending';index"` // 'pending' | 'replayed'
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (FailedEvent) TableName() string {
+	return "failed_events"
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math/rand"
 	"time"
 )
 
@@ -53,20 +54,35 @@ type FailedTransaction struct {
 	ResolvedAt *time.Time `json:"resolved_at"` // （Successor）
 }
 
-// next timeretry（）
-func (ft *FailedTransaction) CalculateNextRetryTime() time.Time {
-	// ：10seconds
-	baseDelay := 10 * time.Second
-
-	// ：10seconds、20seconds、40seconds、80seconds...10minutes
-	delay := baseDelay * time.Duration(1<<uint(ft.RetryCount))
-	maxDelay := 10 * time.Minute
+// Retry backoff: 10s, 20s, 40s, 80s... capped at 10 minutes, minus up to 20% jitter
+const (
+	failedTxRetryBaseDelay = 10 * time.Second
+	failedTxRetryMaxDelay  = 10 * time.Minute
+	failedTxRetryJitter    = 0.2
+)
 
-	if delay > maxDelay {
-		delay = maxDelay
+// nextRetryDelay backoff delay before retry number retryCount+1
+// Jitter only shortens the delay, so it never exceeds failedTxRetryMaxDelay and retries of
+// transactions that failed together spread out instead of hitting the RPC at the same time.
+func nextRetryDelay(retryCount int) time.Duration {
+	delay := failedTxRetryMaxDelay
+	// 10s << 6 = 640s already exceeds the cap; also keeps the shift from overflowing
+	if retryCount >= 0 && retryCount < 6 {
+		delay = failedTxRetryBaseDelay << uint(retryCount)
+		if delay > failedTxRetryMaxDelay {
+			delay = failedTxRetryMaxDelay
+		}
+	} else if retryCount < 0 {
+		delay = failedTxRetryBaseDelay
 	}
 
-	return time.Now().Add(delay)
+	jitter := time.Duration(rand.Float64() * failedTxRetryJitter * float64(delay))
+	return delay - jitter
+}
+
+// next timeretry（）
+func (ft *FailedTransaction) CalculateNextRetryTime() time.Time {
+	return time.Now().Add(nextRetryDelay(ft.RetryCount))
 }
 
 // Checkwhetherretry
@@ -89,6 +105,14 @@ func (ft *FailedTransaction) IncrementRetry(errorMsg string) {
 	}
 }
 
+// MarkAsAbandoned stops retrying (permanent failure, e.g. contract revert)
+func (ft *FailedTransaction) MarkAsAbandoned(errorMsg string) {
+	ft.LastError = errorMsg
+	ft.Status = FailedTransactionStatusAbandoned
+	now := time.Now()
+	ft.ResolvedAt = &now
+}
+
 // recovered
 func (ft *FailedTransaction) MarkAsRecovered(actualTxHash string) {
 	ft.Status = FailedTransactionStatusRecovered
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextRetryDelayCurveAndCap(t *testing.T) {
+	tests := []struct {
+		retryCount int
+		base       time.Duration // delay before jitter
+	}{
+		{-1, 10 * time.Second},
+		{0, 10 * time.Second},
+		{1, 20 * time.Second},
+		{2, 40 * time.Second},
+		{3, 80 * time.Second},
+		{4, 160 * time.Second},
+		{5, 320 * time.Second},
+		{6, 10 * time.Minute}, // 640s is over the cap
+		{9, 10 * time.Minute},
+		{64, 10 * time.Minute}, // would overflow the shift
+		{1 << 20, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		minDelay := tt.base - time.Duration(failedTxRetryJitter*float64(tt.base))
+		// Jitter is random: sample enough times to see both ends of the range
+		for i := 0; i < 200; i++ {
+			delay := nextRetryDelay(tt.retryCount)
+			if delay > tt.base || delay < minDelay {
+				t.Fatalf("nextRetryDelay(%d) = %s, want within [%s, %s]", tt.retryCount, delay, minDelay, tt.base)
+			}
+			if delay > failedTxRetryMaxDelay {
+				t.Fatalf("nextRetryDelay(%d) = %s exceeds the cap", tt.retryCount, delay)
+			}
+		}
+	}
+}
+
+func TestIncrementRetryAbandonsAtMaxRetries(t *testing.T) {
+	ft := &FailedTransaction{Status: FailedTransactionStatusPending, MaxRetries: 2}
+
+	before := time.Now()
+	ft.IncrementRetry("timeout")
+	if ft.Status != FailedTransactionStatusPending || ft.ResolvedAt != nil {
+		t.Fatalf("after 1 of 2 retries status = %s, want still pending", ft.Status)
+	}
+	// Retry 2 waits 20s minus at most 20% jitter
+	if wait := ft.NextRetryAt.Sub(before); wait < 16*time.Second || wait > 21*time.Second {
+		t.Errorf("next retry in %s, want about 20s", wait)
+	}
+
+	ft.IncrementRetry("timeout")
+	if ft.Status != FailedTransactionStatusAbandoned || ft.ResolvedAt == nil {
+		t.Errorf("after 2 of 2 retries status = %s, want abandoned and resolved", ft.Status)
+	}
+}
@@ -27,8 +27,8 @@ type PendingTransaction struct {
 	ID      string                   `json:"id" gorm:"primaryKey"` // UUID
 	Type    PendingTransactionType   `json:"type" gorm:"not null"`
 	Status  PendingTransactionStatus `json:"status" gorm:"not null;default:pending;index"`
-	Address string                   `json:"address" gorm:"not null;index:idx_address_chain;size:42"`
-	ChainID uint32                   `json:"chain_id" gorm:"not null;index:idx_address_chain"`
+	Address string                   `json:"address" gorm:"not null;index:idx_pending_transactions_address_chain;size:42"`
+	ChainID uint32                   `json:"chain_id" gorm:"not null;index:idx_pending_transactions_address_chain"`
 	Nonce   uint64                   `json:"nonce" gorm:"not null;index"` // 分配的 nonce
 
 	// 交易信息
@@ -0,0 +1,9 @@
+package models
+
+// OptimisticLocked models with a `version` column
+// Every UPDATE of these models increments version (registered as a GORM callback in the db package),
+// so a conditional update "WHERE id = ? AND version = ?" fails when another writer changed the row
+// since it was read. See repository.UpdateIfVersion.
+type OptimisticLocked interface {
+	optimisticLock()
+}
@@ -114,6 +114,9 @@ type BlockchainClientInterface interface {
 
 	// getchain ID
 	GetChainID() uint32
+
+	// get current block number (for confirmation depth)
+	GetBlockNumber() (uint64, error)
 }
 
 // status
@@ -123,6 +126,10 @@ type TransactionStatus struct {
 	Success     bool   `json:"success"`
 	BlockNumber uint64 `json:"block_number"`
 	ErrorReason string `json:"error_reason,omitempty"`
+
+	// Receipt gas cost, zero/empty when the client does not report it
+	GasUsed           uint64 `json:"gas_used,omitempty"`
+	EffectiveGasPrice string `json:"effective_gas_price,omitempty"` // wei
 }
 
 // Commitmentstatus
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Status transition entity types
+const (
+	StatusTransitionEntityCheckbook       = "checkbook"
+	StatusTransitionEntityCheck           = "check"
+	StatusTransitionEntityWithdrawRequest = "withdraw_request"
+)
+
+// StatusTransition records a single status change of an entity (audit log)
+type StatusTransition struct {
+	ID             uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	EntityType     string    `json:"entity_type" gorm:"size:50;not null;index:idx_status_transitions_entity"` // 'checkbook' | 'check' | 'withdraw_request'
+	EntityID       string    `json:"entity_id" gorm:"size:255;not null;index:idx_status_transitions_entity"`  // checkbook_id / check_id / withdraw_request_id
+	FromStatus     string    `json:"from_status" gorm:"size:50"`                                              // status before the transition
+	ToStatus       string    `json:"to_status" gorm:"size:50;not null"`                                       // status after the transition
+	TriggerContext string    `json:"trigger_context" gorm:"size:100"`                                         // e.g. "DepositUsed", "CommitmentRootUpdated"
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name
+func (StatusTransition) TableName() string {
+	return "status_transitions"
+}
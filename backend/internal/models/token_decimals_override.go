@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// TokenDecimalsOverride on-chain decimals() of a token, applied on top of tokens.tokenAddressDecimals
+// Written by cmd/verify-token-decimals when the configured decimals disagree with the token contract,
+// so deposits are converted to management amounts with the decimals the chain actually uses.
+type TokenDecimalsOverride struct {
+	ID                 uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChainID            int       `json:"chain_id" gorm:"not null;uniqueIndex:idx_token_decimals_overrides_chain_token"`              // SLIP-44 chain ID
+	TokenAddress       string    `json:"token_address" gorm:"size:66;not null;uniqueIndex:idx_token_decimals_overrides_chain_token"` // canonical (utils.CanonicalTokenAddress)
+	Decimals           int       `json:"decimals" gorm:"not null"`                                                                   // decimals() read from the token contract
+	ConfiguredDecimals *int      `json:"configured_decimals,omitempty"`                                                              // configured value it replaces, nil when none was configured
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (TokenDecimalsOverride) TableName() string {
+	return "token_decimals_overrides"
+}
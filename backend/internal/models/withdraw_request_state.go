@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSubStatuses WithdrawRequest sub-statuses form an impossible combination, the row must not be persisted
+var ErrInvalidSubStatuses = errors.New("invalid withdraw request sub-status combination")
+
+// ValidateSubStatuses checks that the sub-statuses form a legal combination
+// The four stages run strictly in order, so a later stage may only leave its initial state once the
+// previous stage has succeeded. Empty values are treated as the initial state (rows created before the column).
+//
+//	proof_status     execute_status                      payout_status                      hook_status
+//	---------------  ----------------------------------  ---------------------------------  ---------------------------------------------
+//	pending          pending                             pending                            not_required | pending
+//	in_progress      pending                             pending                            not_required | pending
+//	failed           pending                             pending                            not_required | pending
+//	completed        pending | submitted |               pending                            not_required | pending
+//	                 submit_failed | verify_failed
+//	completed        success                             pending | processing | failed      not_required | pending
+//	completed        success                             completed                          any
+func (w *WithdrawRequest) ValidateSubStatuses() error {
+	proof := w.ProofStatus
+	if proof == "" {
+		proof = ProofStatusPending
+	}
+	execute := w.ExecuteStatus
+	if execute == "" {
+		execute = ExecuteStatusPending
+	}
+	payout := w.PayoutStatus
+	if payout == "" {
+		payout = PayoutStatusPending
+	}
+	hook := w.HookStatus
+	if hook == "" {
+		hook = HookStatusNotRequired
+	}
+
+	switch proof {
+	case ProofStatusPending, ProofStatusInProgress, ProofStatusCompleted, ProofStatusFailed:
+	default:
+		return fmt.Errorf("%w: unknown proof_status %q", ErrInvalidSubStatuses, proof)
+	}
+	switch execute {
+	case ExecuteStatusPending, ExecuteStatusSubmitted, ExecuteStatusSuccess, ExecuteStatusSubmitFailed, ExecuteStatusVerifyFailed:
+	default:
+		return fmt.Errorf("%w: unknown execute_status %q", ErrInvalidSubStatuses, execute)
+	}
+	switch payout {
+	case PayoutStatusPending, PayoutStatusProcessing, PayoutStatusCompleted, PayoutStatusFailed:
+	default:
+		return fmt.Errorf("%w: unknown payout_status %q", ErrInvalidSubStatuses, payout)
+	}
+	switch hook {
+	case HookStatusNotRequired, HookStatusPending, HookStatusProcessing, HookStatusCompleted, HookStatusFailed, HookStatusAbandoned:
+	default:
+		return fmt.Errorf("%w: unknown hook_status %q", ErrInvalidSubStatuses, hook)
+	}
+
+	// Stage 2 requires a generated proof
+	if execute != ExecuteStatusPending && proof != ProofStatusCompleted {
+		return fmt.Errorf("%w: execute_status=%s requires proof_status=completed (got %s)", ErrInvalidSubStatuses, execute, proof)
+	}
+	// Stage 3 requires the withdraw to be executed on-chain
+	if payout != PayoutStatusPending && execute != ExecuteStatusSuccess {
+		return fmt.Errorf("%w: payout_status=%s requires execute_status=success (got %s)", ErrInvalidSubStatuses, payout, execute)
+	}
+	// Stage 4 requires the payout to be completed (pending only marks the hook as required)
+	if hook != HookStatusNotRequired && hook != HookStatusPending && payout != PayoutStatusCompleted {
+		return fmt.Errorf("%w: hook_status=%s requires payout_status=completed (got %s)", ErrInvalidSubStatuses, hook, payout)
+	}
+	return nil
+}
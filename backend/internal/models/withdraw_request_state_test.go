@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSubStatusesMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		proof   ProofStatus
+		execute ExecuteStatus
+		payout  PayoutStatus
+		hook    HookStatus
+		valid   bool
+	}{
+		{"empty columns", "", "", "", "", true},
+		{"new request", ProofStatusPending, ExecuteStatusPending, PayoutStatusPending, HookStatusNotRequired, true},
+		{"hook required before payout", ProofStatusPending, ExecuteStatusPending, PayoutStatusPending, HookStatusPending, true},
+		{"proving", ProofStatusInProgress, ExecuteStatusPending, PayoutStatusPending, HookStatusNotRequired, true},
+		{"proof failed", ProofStatusFailed, ExecuteStatusPending, PayoutStatusPending, HookStatusNotRequired, true},
+		{"proof generated", ProofStatusCompleted, ExecuteStatusPending, PayoutStatusPending, HookStatusNotRequired, true},
+		{"execute submitted", ProofStatusCompleted, ExecuteStatusSubmitted, PayoutStatusPending, HookStatusNotRequired, true},
+		{"execute submit failed", ProofStatusCompleted, ExecuteStatusSubmitFailed, PayoutStatusPending, HookStatusNotRequired, true},
+		{"execute verify failed", ProofStatusCompleted, ExecuteStatusVerifyFailed, PayoutStatusPending, HookStatusNotRequired, true},
+		{"executed", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusPending, HookStatusNotRequired, true},
+		{"payout processing", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusProcessing, HookStatusPending, true},
+		{"payout failed", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusFailed, HookStatusNotRequired, true},
+		{"paid out", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusCompleted, HookStatusNotRequired, true},
+		{"hook processing", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusCompleted, HookStatusProcessing, true},
+		{"hook completed", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusCompleted, HookStatusCompleted, true},
+		{"hook failed", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusCompleted, HookStatusFailed, true},
+		{"hook abandoned", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusCompleted, HookStatusAbandoned, true},
+
+		{"unknown proof status", "done", ExecuteStatusPending, PayoutStatusPending, HookStatusNotRequired, false},
+		{"unknown execute status", ProofStatusCompleted, "mined", PayoutStatusPending, HookStatusNotRequired, false},
+		{"unknown payout status", ProofStatusCompleted, ExecuteStatusSuccess, "sent", HookStatusNotRequired, false},
+		{"unknown hook status", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusCompleted, "bought", false},
+		{"submitted without proof", ProofStatusInProgress, ExecuteStatusSubmitted, PayoutStatusPending, HookStatusNotRequired, false},
+		{"executed after failed proof", ProofStatusFailed, ExecuteStatusSuccess, PayoutStatusPending, HookStatusNotRequired, false},
+		{"payout before execute", ProofStatusCompleted, ExecuteStatusSubmitted, PayoutStatusProcessing, HookStatusNotRequired, false},
+		{"payout after verify failed", ProofStatusCompleted, ExecuteStatusVerifyFailed, PayoutStatusCompleted, HookStatusNotRequired, false},
+		{"hook before payout completed", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusProcessing, HookStatusProcessing, false},
+		{"hook completed without payout", ProofStatusCompleted, ExecuteStatusSuccess, PayoutStatusFailed, HookStatusCompleted, false},
+		{"hook failed before execute", ProofStatusCompleted, ExecuteStatusPending, PayoutStatusPending, HookStatusFailed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &WithdrawRequest{
+				ProofStatus:   tt.proof,
+				ExecuteStatus: tt.execute,
+				PayoutStatus:  tt.payout,
+				HookStatus:    tt.hook,
+			}
+			err := request.ValidateSubStatuses()
+			if tt.valid && err != nil {
+				t.Errorf("ValidateSubStatuses() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSubStatuses) {
+				t.Errorf("ValidateSubStatuses() = %v, want ErrInvalidSubStatuses", err)
+			}
+		})
+	}
+}
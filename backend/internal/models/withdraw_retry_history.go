@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RetryStage withdraw stage a retry history entry belongs to
+type RetryStage string
+
+const (
+	RetryStagePayout   RetryStage = "payout"
+	RetryStageHook     RetryStage = "hook"
+	RetryStageFallback RetryStage = "fallback"
+)
+
+// RetryHistoryEntry one retry or failure of a withdraw stage, stored in WithdrawRequest.RetryHistory
+// A failed attempt and the manual retry that follows it are separate entries: the retry carries the next attempt
+// number and the error being retried.
+type RetryHistoryEntry struct {
+	Stage   RetryStage `json:"stage"`
+	Attempt int        `json:"attempt"`         // 1-based attempt of the stage the entry is about
+	At      time.Time  `json:"at"`              // When the retry was requested or the failure was recorded
+	Error   string     `json:"error,omitempty"` // Failure reason (for a retry: the error of the previous attempt)
+	Trigger string     `json:"trigger"`         // What recorded the entry, e.g. "RetryPayout", "HookFailed"
+}
+
+// ParseRetryHistory decodes RetryHistory, oldest entry first (empty for requests without retries)
+func (w *WithdrawRequest) ParseRetryHistory() ([]RetryHistoryEntry, error) {
+	history := []RetryHistoryEntry{}
+	if w.RetryHistory == "" {
+		return history, nil
+	}
+	if err := json.Unmarshal([]byte(w.RetryHistory), &history); err != nil {
+		return nil, fmt.Errorf("invalid retry_history of withdraw request %s: %w", w.ID, err)
+	}
+	return history, nil
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"go-backend/internal/utils"
+
+	"gorm.io/gorm"
 )
 
 // ============ blockchain event table ============
@@ -13,14 +15,14 @@ import (
 // EventDepositReceived deposit received event table (Treasury.DepositReceived)
 type EventDepositReceived struct {
 	ID              uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
-	ChainID         int64     `json:"chain_id" gorm:"column:chain_id;index;not null;default:714"`      // unified Chain ID field
-	SLIP44ChainID   int64     `json:"slip44_chain_id" gorm:"column:slip44_chain_id;index;default:714"` // SLIP-44 Chain ID (compatible with legacy code)
-	EVMChainID      *int64    `json:"evm_chain_id,omitempty" gorm:"index"`                             // EVM Chain ID -
+	ChainID         int64     `json:"chain_id" gorm:"column:chain_id;index;uniqueIndex:idx_event_deposit_receiveds_chain_tx_log;not null;default:714"` // unified Chain ID field
+	SLIP44ChainID   int64     `json:"slip44_chain_id" gorm:"column:slip44_chain_id;index;default:714"`                                                 // SLIP-44 Chain ID (compatible with legacy code)
+	EVMChainID      *int64    `json:"evm_chain_id,omitempty" gorm:"index"`                                                                             // EVM Chain ID -
 	ContractAddress string    `json:"contract_address" gorm:"not null"`
 	EventName       string    `json:"event_name" gorm:"not null"`
 	BlockNumber     uint64    `json:"block_number" gorm:"index;not null"`
-	TransactionHash string    `json:"transaction_hash" gorm:"index;not null"`
-	LogIndex        uint      `json:"log_index" gorm:"not null"`
+	TransactionHash string    `json:"transaction_hash" gorm:"index;uniqueIndex:idx_event_deposit_receiveds_chain_tx_log;not null"`
+	LogIndex        uint      `json:"log_index" gorm:"uniqueIndex:idx_event_deposit_receiveds_chain_tx_log;not null"`
 	BlockTimestamp  time.Time `json:"block_timestamp" gorm:"not null"`
 
 	// Event Data
@@ -38,29 +40,30 @@ type EventDepositReceived struct {
 // EventDepositRecorded depositrecordevent (ZKPayProxy.DepositRecorded)
 type EventDepositRecorded struct {
 	ID              uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
-	ChainID         int64     `json:"chain_id" gorm:"column:chain_id;index;not null;default:714"`      // unified Chain ID field
-	SLIP44ChainID   int64     `json:"slip44_chain_id" gorm:"column:slip44_chain_id;index;default:714"` // SLIP-44 Chain ID (compatible with legacy code)
-	EVMChainID      *int64    `json:"evm_chain_id,omitempty" gorm:"index"`                             // EVM Chain ID -
+	ChainID         int64     `json:"chain_id" gorm:"column:chain_id;index;uniqueIndex:idx_event_deposit_recordeds_chain_tx_log;not null;default:714"` // unified Chain ID field
+	SLIP44ChainID   int64     `json:"slip44_chain_id" gorm:"column:slip44_chain_id;index;default:714"`                                                 // SLIP-44 Chain ID (compatible with legacy code)
+	EVMChainID      *int64    `json:"evm_chain_id,omitempty" gorm:"index"`                                                                             // EVM Chain ID -
 	ContractAddress string    `json:"contract_address" gorm:"not null"`
 	EventName       string    `json:"event_name" gorm:"not null"`
 	BlockNumber     uint64    `json:"block_number" gorm:"index;not null"`
-	TransactionHash string    `json:"transaction_hash" gorm:"index;not null"`
-	LogIndex        uint      `json:"log_index" gorm:"not null"`
+	TransactionHash string    `json:"transaction_hash" gorm:"index;uniqueIndex:idx_event_deposit_recordeds_chain_tx_log;not null"`
+	LogIndex        uint      `json:"log_index" gorm:"uniqueIndex:idx_event_deposit_recordeds_chain_tx_log;not null"`
 	BlockTimestamp  time.Time `json:"block_timestamp" gorm:"not null"`
 
 	// Event Data
-	LocalDepositId    uint64 `json:"local_deposit_id" gorm:"index;not null"` // uint64 indexed localDepositId
-	TokenId           uint16 `json:"token_id" gorm:"not null"`               // uint16 tokenId
-	OwnerChainId      uint16 `json:"owner_chain_id" gorm:"not null"`         // UniversalAddress.chainId
-	OwnerData         string `json:"owner_data" gorm:"not null"`             // UniversalAddress.data
-	GrossAmount       string `json:"gross_amount" gorm:"not null"`           // uint256 grossAmount
-	FeeTotalLocked    string `json:"fee_total_locked" gorm:"not null"`       // uint256 feeTotalLocked
-	AllocatableAmount string `json:"allocatable_amount" gorm:"not null"`     // uint256 allocatableAmount
-	PromoteCode       string `json:"promote_code" gorm:"not null"`           // bytes6 promoteCode
-	AddressRank       uint8  `json:"address_rank" gorm:"not null"`           // uint8 addressRank
-	DepositTxHash     string `json:"deposit_tx_hash" gorm:"index;not null"`  // bytes32 depositTxHash
-	EventBlockNumber  uint64 `json:"event_block_number" gorm:"not null"`     // uint64 blockNumber (from event)
-	EventTimestamp    uint64 `json:"event_timestamp" gorm:"not null"`        // uint256 timestamp (from event)
+	LocalDepositId    uint64 `json:"local_deposit_id" gorm:"index;not null"`       // uint64 indexed localDepositId
+	TokenId           uint16 `json:"token_id" gorm:"not null"`                     // uint16 tokenId
+	TokenKey          string `json:"token_key" gorm:"size:50;not null;default:''"` // token key resolved from the indexed tokenKey hash (e.g. "USDT")
+	OwnerChainId      uint16 `json:"owner_chain_id" gorm:"not null"`               // UniversalAddress.chainId
+	OwnerData         string `json:"owner_data" gorm:"not null"`                   // UniversalAddress.data
+	GrossAmount       string `json:"gross_amount" gorm:"not null"`                 // uint256 grossAmount
+	FeeTotalLocked    string `json:"fee_total_locked" gorm:"not null"`             // uint256 feeTotalLocked
+	AllocatableAmount string `json:"allocatable_amount" gorm:"not null"`           // uint256 allocatableAmount
+	PromoteCode       string `json:"promote_code" gorm:"not null"`                 // bytes6 promoteCode
+	AddressRank       uint8  `json:"address_rank" gorm:"not null"`                 // uint8 addressRank
+	DepositTxHash     string `json:"deposit_tx_hash" gorm:"index;not null"`        // bytes32 depositTxHash
+	EventBlockNumber  uint64 `json:"event_block_number" gorm:"not null"`           // uint64 blockNumber (from event)
+	EventTimestamp    uint64 `json:"event_timestamp" gorm:"not null"`              // uint256 timestamp (from event)
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -69,14 +72,14 @@ type EventDepositRecorded struct {
 // EventDepositUsed depositUseevent (ZKPayProxy.DepositUsed)
 type EventDepositUsed struct {
 	ID              uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
-	ChainID         int64     `json:"chain_id" gorm:"column:chain_id;index;not null;default:714"`      // unified Chain ID field
-	SLIP44ChainID   int64     `json:"slip44_chain_id" gorm:"column:slip44_chain_id;index;default:714"` // SLIP-44 Chain ID (compatible with legacy code)
-	EVMChainID      *int64    `json:"evm_chain_id,omitempty" gorm:"index"`                             // EVM Chain ID -
+	ChainID         int64     `json:"chain_id" gorm:"column:chain_id;index;uniqueIndex:idx_event_deposit_useds_chain_tx_log;not null;default:714"` // unified Chain ID field
+	SLIP44ChainID   int64     `json:"slip44_chain_id" gorm:"column:slip44_chain_id;index;default:714"`                                             // SLIP-44 Chain ID (compatible with legacy code)
+	EVMChainID      *int64    `json:"evm_chain_id,omitempty" gorm:"index"`                                                                         // EVM Chain ID -
 	ContractAddress string    `json:"contract_address" gorm:"not null"`
 	EventName       string    `json:"event_name" gorm:"not null"`
 	BlockNumber     uint64    `json:"block_number" gorm:"index;not null"`
-	TransactionHash string    `json:"transaction_hash" gorm:"index;not null"`
-	LogIndex        uint      `json:"log_index" gorm:"not null"`
+	TransactionHash string    `json:"transaction_hash" gorm:"index;uniqueIndex:idx_event_deposit_useds_chain_tx_log;not null"`
+	LogIndex        uint      `json:"log_index" gorm:"uniqueIndex:idx_event_deposit_useds_chain_tx_log;not null"`
 	BlockTimestamp  time.Time `json:"block_timestamp" gorm:"not null"`
 
 	// Event Data
@@ -184,6 +187,9 @@ type DepositInfo struct {
 	FeeTotalLocked    string           `json:"fee_total_locked" gorm:"not null"`
 	AllocatableAmount string           `json:"allocatable_amount" gorm:"not null"` // amount
 
+	// Not yet allocated to checks, initialized to AllocatableAmount on DepositRecorded
+	AllocatableRemaining string `json:"allocatable_remaining" gorm:"type:numeric(78,0);not null;default:0"`
+
 	// Info
 	PromoteCode   string `json:"promote_code" gorm:"size:14"`    //  (bytes6 as hex, 0x prefix)
 	AddressRank   uint8  `json:"address_rank"`                   // address
@@ -254,6 +260,9 @@ type Intent struct {
 	Beneficiary UniversalAddress `json:"beneficiary"` // Target beneficiary address
 	TokenSymbol string           `json:"tokenSymbol"` // Token symbol (RawToken: e.g., "USDT", AssetToken: e.g., "aUSDT")
 	AssetID     string           `json:"assetId"`     // For AssetToken: 32-byte asset identifier
+
+	// Optional Stage 4 hook: calldata IntentManager executes after payout (0x-prefixed hex, empty = no hook)
+	HookCalldata string `json:"hookCalldata,omitempty"`
 }
 
 // WithdrawRequestStatus represents the main status of a withdrawal request
@@ -333,13 +342,53 @@ const (
 	HookStatusAbandoned   HookStatus = "abandoned"    // User gave up, withdrew original tokens
 )
 
+// Sub-status field names (column names) accepted by IsTerminalSubStatus
+const (
+	SubStatusFieldProof   = "proof_status"
+	SubStatusFieldExecute = "execute_status"
+	SubStatusFieldPayout  = "payout_status"
+	SubStatusFieldHook    = "hook_status"
+)
+
+// TerminalWithdrawStatuses main statuses after which a withdraw request no longer progresses
+var TerminalWithdrawStatuses = map[WithdrawRequestStatus]bool{
+	WithdrawStatusCompleted:               true,
+	WithdrawStatusCompletedWithHookFailed: true,
+	WithdrawStatusFailedPermanent:         true,
+	WithdrawStatusManuallyResolved:        true,
+	WithdrawStatusCancelled:               true,
+}
+
+// TerminalSubStatuses per sub-status field, the values whose stage is finished and must not be overwritten
+// Failure values are not listed: a later on-chain event for the stage is authoritative and may still apply.
+var TerminalSubStatuses = map[string]map[string]bool{
+	SubStatusFieldProof:   {string(ProofStatusCompleted): true},
+	SubStatusFieldExecute: {string(ExecuteStatusSuccess): true},
+	SubStatusFieldPayout:  {string(PayoutStatusCompleted): true},
+	SubStatusFieldHook:    {string(HookStatusCompleted): true, string(HookStatusAbandoned): true},
+}
+
+// IsTerminalWithdrawStatus checks if a main withdraw status is terminal
+func IsTerminalWithdrawStatus(status WithdrawRequestStatus) bool {
+	return TerminalWithdrawStatuses[status]
+}
+
+// IsTerminalSubStatus checks if a sub-status value is terminal for its field (unknown fields are never terminal)
+func IsTerminalSubStatus(field, value string) bool {
+	return TerminalSubStatuses[field][value]
+}
+
 // WithdrawRequest represents a withdrawal request (Intent-driven, two-stage lifecycle)
 type WithdrawRequest struct {
 	ID string `json:"id" gorm:"primaryKey"` // UUID
 
 	// On-chain tracking ID (= nullifiers[0])
-	WithdrawNullifier string `json:"withdraw_nullifier" gorm:"size:66;uniqueIndex;not null"` // requestID = nullifiers[0] (also called OnChainRequestID)
-	QueueRoot         string `json:"queue_root" gorm:"size:66;not null"`                     // Queue root (for proof verification)
+	WithdrawNullifier string `json:"withdraw_nullifier" gorm:"size:66;uniqueIndex:idx_withdraw_requests_withdraw_nullifier,where:deleted_at IS NULL;not null"` // requestID = nullifiers[0] (also called OnChainRequestID), unique among non-deleted requests
+	QueueRoot         string `json:"queue_root" gorm:"size:66;not null"`                                                                                       // Queue root (for proof verification)
+
+	// Client key for retried creates (NULL when not given), unique per owner among non-deleted requests
+	// (idx_withdraw_requests_owner_idempotency_key, created by db.InitDB: the owner columns come from an embedded struct)
+	IdempotencyKey *string `json:"idempotency_key,omitempty" gorm:"size:128"`
 
 	// User Info
 	OwnerAddress UniversalAddress `json:"owner_address" gorm:"embedded;embeddedPrefix:owner_"` // User's universal address
@@ -358,11 +407,21 @@ type WithdrawRequest struct {
 	AllocationIDs string `json:"allocation_ids" gorm:"type:json"` // JSON array of allocation UUIDs
 
 	// Stage 1: Proof Generation
-	ProofStatus      ProofStatus `json:"proof_status" gorm:"not null;default:'pending'"` // Proof generation status
-	Proof            string      `json:"proof" gorm:"type:text"`                         // ZKVM proof data
-	PublicValues     string      `json:"public_values" gorm:"type:text"`                 // ZKVM public values
-	ProofGeneratedAt *time.Time  `json:"proof_generated_at"`                             // Proof generation time
-	ProofError       string      `json:"proof_error" gorm:"type:text"`                   // Proof generation error message
+	ProofStatus       ProofStatus `json:"proof_status" gorm:"not null;default:'pending'"`   // Proof generation status
+	Proof             string      `json:"proof" gorm:"type:text"`                           // ZKVM proof data
+	PublicValues      string      `json:"public_values" gorm:"type:text"`                   // ZKVM public values
+	ProofGeneratedAt  *time.Time  `json:"proof_generated_at"`                               // Proof generation time
+	ProofError        string      `json:"proof_error" gorm:"type:text"`                     // Proof generation error message
+	NullifierMismatch bool        `json:"nullifier_mismatch" gorm:"not null;default:false"` // ZKVM nullifiers differed from the allocations' nullifiers (monitoring)
+
+	// Deadline for the request to get its proof; past it the request is cancelled by WithdrawExpiryService
+	// unless it has started executing (NULL for requests created before expiry existed: never expire)
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"`
+
+	// User signature for proof generation (stored so proof generation can be retried after a restart, never exposed via API)
+	Signature        string `json:"-" gorm:"type:text"` // User signature passed to ZKVM
+	SignatureChainID uint32 `json:"-"`                  // Chain ID the signature was produced on
+	ProofLang        uint8  `json:"-" gorm:"default:0"` // ZKVM message language (lang), from the create request
 
 	// Stage 2: On-chain Verification
 	ExecuteStatus      ExecuteStatus `json:"execute_status" gorm:"not null;default:'pending'"` // Execute status
@@ -371,6 +430,15 @@ type WithdrawRequest struct {
 	ExecuteBlockNumber *uint64       `json:"execute_block_number"`                             // Execute block number
 	ExecutedAt         *time.Time    `json:"executed_at"`                                      // Execute confirmation time
 	ExecuteError       string        `json:"execute_error" gorm:"type:text"`                   // Execute error message
+	ExecuteGasUsed     *uint64       `json:"execute_gas_used"`                                 // Gas used by executeWithdraw (from receipt)
+	ExecuteGasPrice    string        `json:"execute_gas_price" gorm:"size:78"`                 // Effective gas price in wei (from receipt)
+
+	// Set when the WithdrawRequested event's decoded recipient differs from Recipient (needs investigation)
+	RecipientMismatch bool `json:"recipient_mismatch" gorm:"default:false;index"`
+
+	// Set when a WithdrawExecuted event without a transaction hash was received: the payout is left uncompleted
+	// instead of storing an empty payout_tx_hash, until an operator checks it
+	PayoutNeedsManualReview bool `json:"payout_needs_manual_review" gorm:"default:false;index"`
 
 	// Route Constraints (user-defined constraints for payout execution)
 	MaxSlippageBps  *uint16    `json:"max_slippage_bps"`  // Maximum slippage in basis points (0-10000)
@@ -390,6 +458,9 @@ type WithdrawRequest struct {
 	WorkerParams      string       `json:"worker_params" gorm:"type:text"`                  // Worker parameters (JSON encoded)
 	ActualOutput      string       `json:"actual_output"`                                   // Actual output amount after execution
 
+	// IntentManager execution tracking (separate from Treasury.payout TX hash)
+	IntentManagerTxHash string `json:"intent_manager_tx_hash" gorm:"size:66;index"` // IntentManager.WithdrawExecuted TX hash
+
 	// Bridge/Cross-chain tracking (for cross-chain scenarios)
 	BridgeType           string     `json:"bridge_type"`                   // Bridge type: "deBridge", "LiFi", etc.
 	BridgeSubmissionId   string     `json:"bridge_submission_id"`          // Bridge submission ID
@@ -407,11 +478,12 @@ type WithdrawRequest struct {
 	HookLastRetryAt *time.Time `json:"hook_last_retry_at"`                                 // Last Hook retry time
 
 	// Hook CallData (from WithdrawRequested event)
-	HookIntentType      *uint8  `json:"hook_intent_type"`       // Hook intent type: 0=RawToken, 1=AssetToken
-	HookChainID         *uint32 `json:"hook_chain_id"`          // Hook target chain ID (SLIP44)
-	HookTokenID         *uint16 `json:"hook_token_id"`          // Hook target token ID
-	HookWorkerID        *uint16 `json:"hook_worker_id"`         // Hook worker ID (for AssetToken)
-	HookMinOutputAmount string  `json:"hook_min_output_amount"` // Hook minimum output amount
+	HookIntentType      *uint8  `json:"hook_intent_type"`                         // Hook intent type: 0=RawToken, 1=AssetToken
+	HookChainID         *uint32 `json:"hook_chain_id"`                            // Hook target chain ID (SLIP44)
+	HookTokenID         *uint16 `json:"hook_token_id"`                            // Hook target token ID
+	HookWorkerID        *uint16 `json:"hook_worker_id"`                           // Hook worker ID (for AssetToken)
+	HookMinOutputAmount string  `json:"hook_min_output_amount"`                   // Hook minimum output amount
+	HookCalldata        string  `json:"hook_calldata,omitempty" gorm:"type:text"` // Calldata executed via IntentManager (0x hex), empty when no hook
 
 	// Fallback Transfer (when Worker/Hook fails)
 	FallbackTransferred bool       `json:"fallback_transferred" gorm:"default:false"` // Whether fallback transfer succeeded
@@ -419,20 +491,37 @@ type WithdrawRequest struct {
 	FallbackRetryCount  int        `json:"fallback_retry_count" gorm:"default:0"`     // Fallback retry count
 	FallbackLastRetryAt *time.Time `json:"fallback_last_retry_at"`                    // Last fallback retry time
 
+	// Payout / hook / fallback retries and failures, oldest first (JSON array of RetryHistoryEntry, see ParseRetryHistory)
+	// Read-only for gorm saves, so a full-row save with a stale copy never drops entries; written only by
+	// repository.AppendWithdrawRetryHistory.
+	RetryHistory string `json:"-" gorm:"type:jsonb;not null;default:'[]';<-:false"`
+
 	// Main Status (computed from sub-statuses)
 	Status string `json:"status" gorm:"not null;default:'created';index"` // Main status
 
+	// Manual resolution (status = manually_resolved), by admin API or ManuallyResolved event
+	ResolvedBy     string     `json:"resolved_by,omitempty" gorm:"size:100"`      // Admin username or on-chain resolver address
+	ResolutionNote string     `json:"resolution_note,omitempty" gorm:"type:text"` // Reason for the resolution
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`                      // Resolution time
+
 	// Legacy fields (for backward compatibility)
 	RequestID        string  `json:"request_id" gorm:"size:66"`       // DEPRECATED: use WithdrawNullifier
 	TokenID          uint16  `json:"token_id"`                        // DEPRECATED: use IntentType/TokenIdentifier
 	ExecuteTimestamp *uint64 `json:"execute_timestamp"`               // DEPRECATED: use ExecutedAt
 	TransactionHash  string  `json:"transaction_hash" gorm:"size:66"` // DEPRECATED: use ExecuteTxHash
 
+	// Optimistic locking: incremented on every update (see OptimisticLocked)
+	Version int64 `json:"version" gorm:"not null;default:1"`
+
 	// Timestamps
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"` // Soft delete: replaced requests are kept for audits
 }
 
+// optimisticLock marks WithdrawRequest as OptimisticLocked
+func (WithdrawRequest) optimisticLock() {}
+
 // GetIntent returns the Intent object from flattened fields
 func (w *WithdrawRequest) GetIntent() Intent {
 	return Intent{
@@ -481,6 +570,23 @@ func (w *WithdrawRequest) CanCancel() bool {
 	return true
 }
 
+// IsExpired checks if the request is past ExpiresAt without having started executing
+// Only requests still waiting for (or having failed) proof generation and never submitted on-chain expire;
+// anything that reached execute_status=success or submitted is never auto-cancelled.
+func (w *WithdrawRequest) IsExpired(now time.Time) bool {
+	if w.ExpiresAt == nil || !now.After(*w.ExpiresAt) {
+		return false
+	}
+	switch WithdrawRequestStatus(w.Status) {
+	case WithdrawStatusCancelled, WithdrawStatusManuallyResolved, WithdrawStatusFailedPermanent:
+		return false
+	}
+	if w.ExecuteStatus != ExecuteStatusPending {
+		return false
+	}
+	return w.ProofStatus == ProofStatusPending || w.ProofStatus == ProofStatusFailed
+}
+
 // CanRetryExecute checks if on-chain execution can be retried
 // Only submit_failed can be retried (RPC/network errors)
 // verify_failed cannot be retried (proof invalid, must cancel)
@@ -489,43 +595,60 @@ func (w *WithdrawRequest) CanRetryExecute() bool {
 }
 
 // CanRetryPayout checks if payout can be retried
-// ⭐ Simplified design: Payout failures are not retried automatically, require manual resolution
+// Payout failures are not retried automatically: the request waits in failed_permanent for a manual RetryPayout
+// Rule: execute_status = success AND payout_status = failed, on a request that was not closed
 func (w *WithdrawRequest) CanRetryPayout() bool {
-	// According to simplified design, payout failures are not retried automatically
-	// They are marked as failed_permanent and require manual resolution
-	return false
+	return !w.isClosed() && w.ExecuteStatus == ExecuteStatusSuccess && w.PayoutStatus == PayoutStatusFailed
 }
 
-// CanRetryHook checks if Hook purchase can be retried
-// Note: According to simplified design, Hook itself is not retried
+// CanRetryHook checks if Hook purchase can be retried (manual RetryHook only)
+// Rule: payout_status = completed AND hook_status = failed, on a request that was not closed
 func (w *WithdrawRequest) CanRetryHook() bool {
-	// Hook is not retried according to simplified design
-	return false
+	return !w.isClosed() && w.PayoutStatus == PayoutStatusCompleted && w.HookStatus == HookStatusFailed
 }
 
-// CanRetryFallback checks if fallback transfer can be retried
-// Note: According to simplified design, fallback is not retried automatically
+// CanRetryFallback checks if fallback transfer can be retried (manual RetryFallback only)
+// Rule: hook_status = failed AND the fallback transfer failed (fallback_error set, not transferred)
 func (w *WithdrawRequest) CanRetryFallback() bool {
-	// Fallback is not retried automatically, requires manual intervention
-	return false
+	return !w.isClosed() && w.HookStatus == HookStatusFailed && !w.FallbackTransferred && w.FallbackError != ""
+}
+
+// isClosed reports whether the request was cancelled or resolved by hand, so nothing is retried anymore
+func (w *WithdrawRequest) isClosed() bool {
+	status := WithdrawRequestStatus(w.Status)
+	return status == WithdrawStatusCancelled || status == WithdrawStatusManuallyResolved
 }
 
 // IsTerminal checks if the request is in a terminal state
 func (w *WithdrawRequest) IsTerminal() bool {
-	switch WithdrawRequestStatus(w.Status) {
-	case WithdrawStatusCompleted,
-		WithdrawStatusCompletedWithHookFailed,
-		WithdrawStatusFailedPermanent,
-		WithdrawStatusManuallyResolved,
-		WithdrawStatusCancelled:
-		return true
-	default:
-		return false
-	}
+	return IsTerminalWithdrawStatus(WithdrawRequestStatus(w.Status))
+}
+
+// IsExecuteFinal checks if executeWithdraw is confirmed on-chain (execute_status must not be overwritten)
+func (w *WithdrawRequest) IsExecuteFinal() bool {
+	return IsTerminalSubStatus(SubStatusFieldExecute, string(w.ExecuteStatus))
+}
+
+// IsPayoutFinal checks if the payout has completed (payout_status must not be overwritten)
+func (w *WithdrawRequest) IsPayoutFinal() bool {
+	return IsTerminalSubStatus(SubStatusFieldPayout, string(w.PayoutStatus))
+}
+
+// IsHookFinal checks if the Hook stage is finished (completed or abandoned)
+func (w *WithdrawRequest) IsHookFinal() bool {
+	return IsTerminalSubStatus(SubStatusFieldHook, string(w.HookStatus))
 }
 
 // UpdateMainStatus updates the main status based on sub-statuses
+// Cancelled and manually resolved requests were closed out deliberately and keep their status whatever the
+// sub-statuses say, so a late event or a recompute cannot reopen them.
 func (w *WithdrawRequest) UpdateMainStatus() {
+	switch WithdrawRequestStatus(w.Status) {
+	case WithdrawStatusCancelled, WithdrawStatusManuallyResolved:
+		log.Printf("🧮 [UpdateMainStatus] Status %s is final, keeping it (proof=%s, execute=%s, payout=%s, hook=%s)",
+			w.Status, w.ProofStatus, w.ExecuteStatus, w.PayoutStatus, w.HookStatus)
+		return
+	}
 
 	// Stage 1: Proof Generation
 	if w.ProofStatus == ProofStatusPending {
@@ -652,6 +775,7 @@ type QueueRoot struct {
 	CreatedByCommitment string `json:"created_by_commitment" gorm:"size:66"` // Create
 	BlockNumber         uint64 `json:"block_number"`                         // Create
 	ChainID             int64  `json:"chain_id" gorm:"index"`                // Chain ID for querying
+	Position            *int64 `json:"position,omitempty"`                   // position in the chain's queue (1 = first root), nil until linked to a positioned root
 
 	// timestamp
 	CreatedAt time.Time `json:"created_at"`
@@ -712,10 +836,10 @@ type Checkbook struct {
 	TokenAddress string `json:"token_address"`                           // Token contract address (optional)
 
 	// Amounts from DepositRecorded event
-	GrossAmount       string `json:"gross_amount"`       // Gross amount before fees
-	AllocatableAmount string `json:"allocatable_amount"` // Amount available for allocation
-	FeeTotalLocked    string `json:"fee_total_locked"`   // Total fees locked
-	PromoteCode       string `json:"promote_code"`       // Promotion code
+	GrossAmount       string `json:"gross_amount" gorm:"not null;default:'0'"` // Gross amount before fees ("0" when unknown, never empty)
+	AllocatableAmount string `json:"allocatable_amount"`                       // Amount available for allocation
+	FeeTotalLocked    string `json:"fee_total_locked"`                         // Total fees locked
+	PromoteCode       string `json:"promote_code"`                             // Promotion code
 
 	// Status and Commitment
 	Status     CheckbookStatus `json:"status" gorm:"not null;index"`                    // Checkbook status (using existing enum for compatibility)
@@ -737,11 +861,17 @@ type Checkbook struct {
 	WithdrawQueueRoot string           `json:"withdraw_queue_root,omitempty"`                                                   // DEPRECATED
 	WithdrawNullifier string           `json:"withdraw_nullifier,omitempty"`                                                    // DEPRECATED
 
+	// Optimistic locking: incremented on every update (see OptimisticLocked)
+	Version int64 `json:"version" gorm:"not null;default:1"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// optimisticLock marks Checkbook as OptimisticLocked
+func (Checkbook) optimisticLock() {}
+
 // IsCompleted checks if all allocations are used (calculated property)
 func (c *Checkbook) IsCompleted() bool {
 	if len(c.Allocations) == 0 {
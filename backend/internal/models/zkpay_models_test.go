@@ -0,0 +1,108 @@
+package models
+
+import "testing"
+
+func TestUpdateMainStatusKeepsDeliberateClosures(t *testing.T) {
+	for _, status := range []WithdrawRequestStatus{WithdrawStatusCancelled, WithdrawStatusManuallyResolved} {
+		t.Run(string(status), func(t *testing.T) {
+			request := &WithdrawRequest{
+				Status:        string(status),
+				ProofStatus:   ProofStatusCompleted,
+				ExecuteStatus: ExecuteStatusSuccess,
+				PayoutStatus:  PayoutStatusCompleted,
+				HookStatus:    HookStatusNotRequired,
+			}
+			request.UpdateMainStatus()
+			if request.Status != string(status) {
+				t.Errorf("status = %s, want %s kept", request.Status, status)
+			}
+		})
+	}
+}
+
+func TestUpdateMainStatusFollowsSubStatuses(t *testing.T) {
+	request := &WithdrawRequest{
+		Status:        string(WithdrawStatusProving),
+		ProofStatus:   ProofStatusFailed,
+		ExecuteStatus: ExecuteStatusPending,
+		PayoutStatus:  PayoutStatusPending,
+	}
+	request.UpdateMainStatus()
+	if request.Status != string(WithdrawStatusProofFailed) {
+		t.Errorf("status = %s, want proof_failed", request.Status)
+	}
+}
+
+func TestTerminalWithdrawStatuses(t *testing.T) {
+	terminal := map[WithdrawRequestStatus]bool{
+		WithdrawStatusCompleted:               true,
+		WithdrawStatusCompletedWithHookFailed: true,
+		WithdrawStatusFailedPermanent:         true,
+		WithdrawStatusManuallyResolved:        true,
+		WithdrawStatusCancelled:               true,
+	}
+	all := []WithdrawRequestStatus{
+		WithdrawStatusCreated, WithdrawStatusProving, WithdrawStatusProofGenerated, WithdrawStatusProofFailed,
+		WithdrawStatusSubmitting, WithdrawStatusSubmitted, WithdrawStatusExecuteConfirmed, WithdrawStatusSubmitFailed,
+		WithdrawStatusWaitingForPayout, WithdrawStatusPayoutProcessing, WithdrawStatusPayoutCompleted, WithdrawStatusPayoutFailed,
+		WithdrawStatusHookProcessing, WithdrawStatusHookFailed, WithdrawStatusCompleted, WithdrawStatusCompletedWithHookFailed,
+		WithdrawStatusFailedPermanent, WithdrawStatusManuallyResolved, WithdrawStatusCancelled,
+	}
+	for _, status := range all {
+		if got := IsTerminalWithdrawStatus(status); got != terminal[status] {
+			t.Errorf("IsTerminalWithdrawStatus(%s) = %v, want %v", status, got, terminal[status])
+		}
+		if got := (&WithdrawRequest{Status: string(status)}).IsTerminal(); got != terminal[status] {
+			t.Errorf("IsTerminal() with status %s = %v, want %v", status, got, terminal[status])
+		}
+	}
+	if len(TerminalWithdrawStatuses) != len(terminal) {
+		t.Errorf("TerminalWithdrawStatuses has %d entries, want %d", len(TerminalWithdrawStatuses), len(terminal))
+	}
+}
+
+func TestTerminalSubStatuses(t *testing.T) {
+	tests := []struct {
+		field    string
+		values   []string
+		terminal map[string]bool
+	}{
+		{SubStatusFieldProof,
+			[]string{string(ProofStatusPending), string(ProofStatusInProgress), string(ProofStatusCompleted), string(ProofStatusFailed)},
+			map[string]bool{string(ProofStatusCompleted): true}},
+		{SubStatusFieldExecute,
+			[]string{string(ExecuteStatusPending), string(ExecuteStatusSubmitted), string(ExecuteStatusSuccess), string(ExecuteStatusSubmitFailed), string(ExecuteStatusVerifyFailed)},
+			map[string]bool{string(ExecuteStatusSuccess): true}},
+		{SubStatusFieldPayout,
+			[]string{string(PayoutStatusPending), string(PayoutStatusProcessing), string(PayoutStatusCompleted), string(PayoutStatusFailed)},
+			map[string]bool{string(PayoutStatusCompleted): true}},
+		{SubStatusFieldHook,
+			[]string{string(HookStatusNotRequired), string(HookStatusPending), string(HookStatusProcessing), string(HookStatusCompleted), string(HookStatusFailed), string(HookStatusAbandoned)},
+			map[string]bool{string(HookStatusCompleted): true, string(HookStatusAbandoned): true}},
+	}
+	for _, tt := range tests {
+		for _, value := range tt.values {
+			if got := IsTerminalSubStatus(tt.field, value); got != tt.terminal[value] {
+				t.Errorf("IsTerminalSubStatus(%s, %s) = %v, want %v", tt.field, value, got, tt.terminal[value])
+			}
+		}
+		if len(TerminalSubStatuses[tt.field]) != len(tt.terminal) {
+			t.Errorf("TerminalSubStatuses[%s] has %d entries, want %d", tt.field, len(TerminalSubStatuses[tt.field]), len(tt.terminal))
+		}
+	}
+	if len(TerminalSubStatuses) != len(tests) {
+		t.Errorf("TerminalSubStatuses has %d fields, want %d", len(TerminalSubStatuses), len(tests))
+	}
+	if IsTerminalSubStatus("status", string(WithdrawStatusCompleted)) {
+		t.Error("an unknown field reported a terminal value")
+	}
+
+	final := &WithdrawRequest{ExecuteStatus: ExecuteStatusSuccess, PayoutStatus: PayoutStatusCompleted, HookStatus: HookStatusAbandoned}
+	if !final.IsExecuteFinal() || !final.IsPayoutFinal() || !final.IsHookFinal() {
+		t.Error("finished stages not reported final")
+	}
+	failed := &WithdrawRequest{ExecuteStatus: ExecuteStatusVerifyFailed, PayoutStatus: PayoutStatusFailed, HookStatus: HookStatusFailed}
+	if failed.IsExecuteFinal() || failed.IsPayoutFinal() || failed.IsHookFinal() {
+		t.Error("failed stages reported final, a later on-chain event must still apply")
+	}
+}
@@ -2,17 +2,23 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
+// ErrAllocationLockConflict an allocation to lock is neither idle nor already pending for the locking request
+var ErrAllocationLockConflict = errors.New("allocation is not idle or is locked by another withdraw request")
+
 // AllocationRepository defines the interface for Allocation (Check) data access
 type AllocationRepository interface {
 	// Basic CRUD operations
 	Create(ctx context.Context, allocation *models.Check) error
 	CreateBatch(ctx context.Context, allocations []*models.Check) error
 	GetByID(ctx context.Context, id string) (*models.Check, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Check, error) // input order, error if any ID is missing
 	GetByNullifier(ctx context.Context, nullifier string) (*models.Check, error)
 	Update(ctx context.Context, allocation *models.Check) error
 
@@ -27,6 +33,10 @@ type AllocationRepository interface {
 	LockForWithdrawal(ctx context.Context, ids []string, withdrawRequestID string) error   // idle -> pending
 	MarkAsUsed(ctx context.Context, ids []string) error                                     // pending -> used
 	ReleaseAllocations(ctx context.Context, ids []string) error                             // pending -> idle (only if execute_status != success)
+	ReleaseByWithdrawRequest(ctx context.Context, withdrawRequestID string) (int64, error)  // every non-used allocation of a request -> idle
+
+	// Reorg recovery
+	RestoreUsedByWithdrawRequest(ctx context.Context, withdrawRequestID string) (int64, error) // used -> pending (executeWithdraw reorged)
 	
 	// Legacy methods (for backward compatibility)
 	MarkAsCommitted(ctx context.Context, ids []string) error
@@ -66,6 +76,34 @@ func (r *allocationRepository) GetByID(ctx context.Context, id string) (*models.
 	return &allocation, nil
 }
 
+// GetByIDs retrieves allocations by IDs in a single query, preserving input order
+// Returns an error wrapping gorm.ErrRecordNotFound if any ID is missing
+func (r *allocationRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Check, error) {
+	if len(ids) == 0 {
+		return []*models.Check{}, nil
+	}
+
+	var allocations []*models.Check
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&allocations).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*models.Check, len(allocations))
+	for _, allocation := range allocations {
+		byID[allocation.ID] = allocation
+	}
+
+	result := make([]*models.Check, 0, len(ids))
+	for _, id := range ids {
+		allocation, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("allocation %s: %w", id, gorm.ErrRecordNotFound)
+		}
+		result = append(result, allocation)
+	}
+	return result, nil
+}
+
 // GetByNullifier retrieves an allocation by nullifier
 func (r *allocationRepository) GetByNullifier(ctx context.Context, nullifier string) (*models.Check, error) {
 	var allocation models.Check
@@ -125,14 +163,51 @@ func (r *allocationRepository) UpdateStatusBatch(ctx context.Context, ids []stri
 }
 
 // LockForWithdrawal locks allocations for a withdrawal request (idle -> pending)
+// Idempotent: allocations already pending for withdrawRequestID count as locked, so a retried create does not
+// fail on its own earlier partial lock. Fails, locking nothing, with ErrAllocationLockConflict when an allocation
+// is pending for another request, or when an allocation is missing or in any other status.
 func (r *allocationRepository) LockForWithdrawal(ctx context.Context, ids []string, withdrawRequestID string) error {
-	return r.db.WithContext(ctx).
-		Model(&models.Check{}).
-		Where("id IN ? AND status = ?", ids, models.AllocationStatusIdle).
-		Updates(map[string]interface{}{
-			"status":              models.AllocationStatusPending,
-			"withdraw_request_id": withdrawRequestID,
-		}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Check{}).
+			Where("id IN ? AND status = ?", ids, models.AllocationStatusIdle).
+			Updates(map[string]interface{}{
+				"status":              models.AllocationStatusPending,
+				"withdraw_request_id": withdrawRequestID,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		var allocations []*models.Check
+		if err := tx.Where("id IN ?", ids).Find(&allocations).Error; err != nil {
+			return err
+		}
+		return checkAllocationsLocked(ids, allocations, withdrawRequestID)
+	})
+}
+
+// checkAllocationsLocked verifies every allocation in ids is pending for withdrawRequestID after the lock update
+func checkAllocationsLocked(ids []string, allocations []*models.Check, withdrawRequestID string) error {
+	byID := make(map[string]*models.Check, len(allocations))
+	for _, allocation := range allocations {
+		byID[allocation.ID] = allocation
+	}
+	for _, id := range ids {
+		allocation, ok := byID[id]
+		switch {
+		case !ok:
+			return fmt.Errorf("allocation %s: %w", id, gorm.ErrRecordNotFound)
+		case allocation.Status != models.AllocationStatusPending:
+			return fmt.Errorf("%w: allocation %s is %s", ErrAllocationLockConflict, id, allocation.Status)
+		case allocation.WithdrawRequestID == nil || *allocation.WithdrawRequestID != withdrawRequestID:
+			owner := "no request"
+			if allocation.WithdrawRequestID != nil {
+				owner = "withdraw request " + *allocation.WithdrawRequestID
+			}
+			return fmt.Errorf("%w: allocation %s is pending for %s", ErrAllocationLockConflict, id, owner)
+		}
+	}
+	return nil
 }
 
 // MarkAsUsed marks allocations as used (pending -> used, nullifier consumed on-chain)
@@ -155,6 +230,29 @@ func (r *allocationRepository) ReleaseAllocations(ctx context.Context, ids []str
 		}).Error
 }
 
+// ReleaseByWithdrawRequest releases every allocation still linked to a withdraw request back to idle
+// Allocations already used on-chain are never released. Returns the number of allocations released.
+func (r *allocationRepository) ReleaseByWithdrawRequest(ctx context.Context, withdrawRequestID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Check{}).
+		Where("withdraw_request_id = ? AND status <> ?", withdrawRequestID, models.AllocationStatusUsed).
+		Updates(map[string]interface{}{
+			"status":              models.AllocationStatusIdle,
+			"withdraw_request_id": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// RestoreUsedByWithdrawRequest puts a withdraw request's used allocations back to pending
+// Used when the executeWithdraw transaction that consumed their nullifiers was reorged out. Returns the number restored.
+func (r *allocationRepository) RestoreUsedByWithdrawRequest(ctx context.Context, withdrawRequestID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Check{}).
+		Where("withdraw_request_id = ? AND status = ?", withdrawRequestID, models.AllocationStatusUsed).
+		Update("status", models.AllocationStatusPending)
+	return result.RowsAffected, result.Error
+}
+
 // MarkAsCommitted marks allocations as committed
 func (r *allocationRepository) MarkAsCommitted(ctx context.Context, ids []string) error {
 	return r.UpdateStatusBatch(ctx, ids, "committed")
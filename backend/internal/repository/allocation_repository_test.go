@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func TestCheckAllocationsLocked(t *testing.T) {
+	lockedBy := func(id string, status models.AllocationStatus, requestID string) *models.Check {
+		allocation := &models.Check{ID: id, Status: status}
+		if requestID != "" {
+			allocation.WithdrawRequestID = &requestID
+		}
+		return allocation
+	}
+
+	tests := []struct {
+		name        string
+		allocations []*models.Check
+		wantErr     error
+	}{
+		// Whether the update just locked them or an earlier, partial attempt of the same request did
+		{"all pending for the request", []*models.Check{
+			lockedBy("a1", models.AllocationStatusPending, "req-1"),
+			lockedBy("a2", models.AllocationStatusPending, "req-1"),
+		}, nil},
+		{"left idle", []*models.Check{
+			lockedBy("a1", models.AllocationStatusPending, "req-1"),
+			lockedBy("a2", models.AllocationStatusIdle, ""),
+		}, ErrAllocationLockConflict},
+		{"foreign-request conflict", []*models.Check{
+			lockedBy("a1", models.AllocationStatusPending, "req-1"),
+			lockedBy("a2", models.AllocationStatusPending, "req-2"),
+		}, ErrAllocationLockConflict},
+		{"pending without request", []*models.Check{
+			lockedBy("a1", models.AllocationStatusPending, "req-1"),
+			lockedBy("a2", models.AllocationStatusPending, ""),
+		}, ErrAllocationLockConflict},
+		{"used allocation", []*models.Check{
+			lockedBy("a1", models.AllocationStatusPending, "req-1"),
+			lockedBy("a2", models.AllocationStatusUsed, "req-0"),
+		}, ErrAllocationLockConflict},
+		{"missing allocation", []*models.Check{
+			lockedBy("a1", models.AllocationStatusPending, "req-1"),
+		}, gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAllocationsLocked([]string{"a1", "a2"}, tt.allocations, "req-1")
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("checkAllocationsLocked: %v, want nil", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkAllocationsLocked: %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllocationGetByIDsMixedPresence(t *testing.T) {
+	database := dbtest.Open(t)
+	repo := NewAllocationRepository(database)
+	ctx := context.Background()
+	for i, id := range []string{"a1", "a2", "a3"} {
+		allocation := &models.Check{ID: id, CheckbookID: "cb1", Seq: uint8(i), Amount: "100", Status: models.AllocationStatusIdle, Nullifier: "0x" + id}
+		if err := database.Create(allocation).Error; err != nil {
+			t.Fatalf("create allocation %s: %v", id, err)
+		}
+	}
+
+	// All present: returned in input order, not table order
+	got, err := repo.GetByIDs(ctx, []string{"a3", "a1"})
+	if err != nil {
+		t.Fatalf("GetByIDs(a3, a1): %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "a3" || got[1].ID != "a1" {
+		t.Errorf("GetByIDs(a3, a1) = %v, want a3, a1", allocationIDs(got))
+	}
+
+	// Any absent ID fails the whole lookup and names the missing one
+	for _, ids := range [][]string{{"a1", "missing", "a2"}, {"missing"}, {"a2", "a2", "gone"}} {
+		got, err := repo.GetByIDs(ctx, ids)
+		if !errors.Is(err, gorm.ErrRecordNotFound) || got != nil {
+			t.Errorf("GetByIDs(%v) = %v, %v, want nil and ErrRecordNotFound", ids, allocationIDs(got), err)
+		}
+	}
+
+	if got, err := repo.GetByIDs(ctx, nil); err != nil || len(got) != 0 {
+		t.Errorf("GetByIDs(nil) = %v, %v, want an empty result", allocationIDs(got), err)
+	}
+}
+
+func allocationIDs(allocations []*models.Check) []string {
+	ids := make([]string, 0, len(allocations))
+	for _, allocation := range allocations {
+		ids = append(ids, allocation.ID)
+	}
+	return ids
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"go-backend/internal/models"
 
 	"gorm.io/gorm"
@@ -13,7 +14,9 @@ type CheckbookRepository interface {
 	// Basic CRUD operations
 	Create(ctx context.Context, checkbook *models.Checkbook) error
 	GetByID(ctx context.Context, id string) (*models.Checkbook, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Checkbook, error) // input order, error if any ID is missing
 	GetByDepositID(ctx context.Context, chainID uint32, depositID uint64) (*models.Checkbook, error)
+	GetByCommitment(ctx context.Context, commitment string) (*models.Checkbook, error)
 	Update(ctx context.Context, checkbook *models.Checkbook) error
 	Delete(ctx context.Context, id string) error
 
@@ -52,6 +55,34 @@ func (r *checkbookRepository) GetByID(ctx context.Context, id string) (*models.C
 	return &checkbook, nil
 }
 
+// GetByIDs retrieves checkbooks by IDs in a single query, preserving input order
+// Returns an error wrapping gorm.ErrRecordNotFound if any ID is missing
+func (r *checkbookRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Checkbook, error) {
+	if len(ids) == 0 {
+		return []*models.Checkbook{}, nil
+	}
+
+	var checkbooks []*models.Checkbook
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&checkbooks).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*models.Checkbook, len(checkbooks))
+	for _, checkbook := range checkbooks {
+		byID[checkbook.ID] = checkbook
+	}
+
+	result := make([]*models.Checkbook, 0, len(ids))
+	for _, id := range ids {
+		checkbook, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("checkbook %s: %w", id, gorm.ErrRecordNotFound)
+		}
+		result = append(result, checkbook)
+	}
+	return result, nil
+}
+
 // GetByDepositID retrieves a checkbook by chain ID and deposit ID
 func (r *checkbookRepository) GetByDepositID(ctx context.Context, chainID uint32, depositID uint64) (*models.Checkbook, error) {
 	var checkbook models.Checkbook
@@ -64,9 +95,32 @@ func (r *checkbookRepository) GetByDepositID(ctx context.Context, chainID uint32
 	return &checkbook, nil
 }
 
+// GetByCommitment retrieves a checkbook by its commitment hash (unique)
+func (r *checkbookRepository) GetByCommitment(ctx context.Context, commitment string) (*models.Checkbook, error) {
+	var checkbook models.Checkbook
+	err := r.db.WithContext(ctx).Where("commitment = ?", commitment).First(&checkbook).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkbook, nil
+}
+
 // Update updates a checkbook
+// Returns ErrVersionConflict if the checkbook was modified since it was read; on success checkbook.Version is advanced.
 func (r *checkbookRepository) Update(ctx context.Context, checkbook *models.Checkbook) error {
-	return r.db.WithContext(ctx).Save(checkbook).Error
+	version := checkbook.Version
+	result := r.db.WithContext(ctx).Model(checkbook).
+		Where("version = ?", version).
+		Select("*").
+		Updates(checkbook)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: checkbook %s, version=%d", ErrVersionConflict, checkbook.ID, version)
+	}
+	checkbook.Version = version + 1
+	return nil
 }
 
 // Delete deletes a checkbook
@@ -74,11 +128,11 @@ func (r *checkbookRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Checkbook{}).Error
 }
 
-// FindByOwner finds checkbooks by owner
+// FindByOwner finds checkbooks by owner (the depositor, stored in the user_ embedded address columns)
 func (r *checkbookRepository) FindByOwner(ctx context.Context, ownerChainID uint32, ownerData string) ([]*models.Checkbook, error) {
 	var checkbooks []*models.Checkbook
 	err := r.db.WithContext(ctx).
-		Where("owner_chain_id = ? AND owner_data = ?", ownerChainID, ownerData).
+		Where("user_chain_id = ? AND user_data = ?", ownerChainID, ownerData).
 		Order("created_at DESC").
 		Find(&checkbooks).Error
 	return checkbooks, err
@@ -132,7 +186,7 @@ func (r *checkbookRepository) CountByOwner(ctx context.Context, ownerChainID uin
 	var count int64
 	err := r.db.WithContext(ctx).
 		Model(&models.Checkbook{}).
-		Where("owner_chain_id = ? AND owner_data = ?", ownerChainID, ownerData).
+		Where("user_chain_id = ? AND user_data = ?", ownerChainID, ownerData).
 		Count(&count).Error
 	return count, err
 }
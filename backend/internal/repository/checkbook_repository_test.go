@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func TestCheckbookGetByIDsMixedPresence(t *testing.T) {
+	database := dbtest.Open(t)
+	repo := NewCheckbookRepository(database)
+	ctx := context.Background()
+	for i, id := range []string{"cb1", "cb2", "cb3"} {
+		checkbook := &models.Checkbook{ID: id, SLIP44ChainID: 714, LocalDepositID: uint64(i + 1), Status: models.CheckbookStatusWithCheckbook}
+		if err := database.Create(checkbook).Error; err != nil {
+			t.Fatalf("create checkbook %s: %v", id, err)
+		}
+	}
+
+	// All present: returned in input order, not table order
+	got, err := repo.GetByIDs(ctx, []string{"cb3", "cb1"})
+	if err != nil {
+		t.Fatalf("GetByIDs(cb3, cb1): %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "cb3" || got[1].ID != "cb1" {
+		t.Errorf("GetByIDs(cb3, cb1) = %v, want cb3, cb1", checkbookIDs(got))
+	}
+
+	// Any absent ID fails the whole lookup
+	for _, ids := range [][]string{{"cb1", "missing", "cb2"}, {"missing"}, {"cb2", "cb2", "gone"}} {
+		got, err := repo.GetByIDs(ctx, ids)
+		if !errors.Is(err, gorm.ErrRecordNotFound) || got != nil {
+			t.Errorf("GetByIDs(%v) = %v, %v, want nil and ErrRecordNotFound", ids, checkbookIDs(got), err)
+		}
+	}
+
+	if got, err := repo.GetByIDs(ctx, nil); err != nil || len(got) != 0 {
+		t.Errorf("GetByIDs(nil) = %v, %v, want an empty result", checkbookIDs(got), err)
+	}
+}
+
+func checkbookIDs(checkbooks []*models.Checkbook) []string {
+	ids := make([]string, 0, len(checkbooks))
+	for _, checkbook := range checkbooks {
+		ids = append(ids, checkbook.ID)
+	}
+	return ids
+}
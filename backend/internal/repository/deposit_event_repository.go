@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"go-backend/internal/models"
+	"go-backend/internal/utils"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +16,7 @@ type DepositEventRepository interface {
 	FindDepositReceivedByChain(ctx context.Context, chainID int64, page, limit int) ([]*models.EventDepositReceived, int64, error)
 	FindDepositReceivedByDepositor(ctx context.Context, chainID int64, depositor string, page, limit int) ([]*models.EventDepositReceived, int64, error)
 	FindDepositReceivedByTxHash(ctx context.Context, chainID int64, txHash string) ([]*models.EventDepositReceived, error)
+	FindDepositReceivedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventDepositReceived, int64, error)
 
 	// DepositRecorded operations
 	CreateDepositRecorded(ctx context.Context, event *models.EventDepositRecorded) error
@@ -22,12 +24,15 @@ type DepositEventRepository interface {
 	FindDepositRecordedByChain(ctx context.Context, chainID int64, page, limit int) ([]*models.EventDepositRecorded, int64, error)
 	FindDepositRecordedByLocalID(ctx context.Context, chainID int64, localDepositID uint64) (*models.EventDepositRecorded, error)
 	FindDepositRecordedByOwner(ctx context.Context, ownerChainID uint32, ownerData string, page, limit int) ([]*models.EventDepositRecorded, int64, error)
+	CountByPromoteCode(ctx context.Context, promoteCode string) (int64, error) // recorded deposits per promote code (referral reporting)
+	FindDepositRecordedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventDepositRecorded, int64, error)
 
 	// DepositUsed operations
 	CreateDepositUsed(ctx context.Context, event *models.EventDepositUsed) error
 	GetDepositUsedByID(ctx context.Context, id uint64) (*models.EventDepositUsed, error)
 	FindDepositUsedByLocalID(ctx context.Context, chainID int64, localDepositID uint64) (*models.EventDepositUsed, error)
 	FindDepositUsedByCommitment(ctx context.Context, chainID int64, commitment string) ([]*models.EventDepositUsed, error)
+	FindDepositUsedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventDepositUsed, int64, error)
 }
 
 // depositEventRepository implements DepositEventRepository
@@ -156,6 +161,15 @@ func (r *depositEventRepository) FindDepositRecordedByOwner(ctx context.Context,
 	return events, total, nil
 }
 
+// CountByPromoteCode counts recorded deposits carrying the promote code; the code is normalized like stored codes
+func (r *depositEventRepository) CountByPromoteCode(ctx context.Context, promoteCode string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.EventDepositRecorded{}).
+		Where("promote_code = ?", utils.NormalizePromoteCode(promoteCode)).
+		Count(&count).Error
+	return count, err
+}
+
 // DepositUsed implementations
 func (r *depositEventRepository) CreateDepositUsed(ctx context.Context, event *models.EventDepositUsed) error {
 	return r.db.WithContext(ctx).Create(event).Error
@@ -181,14 +195,47 @@ func (r *depositEventRepository) FindDepositUsedByLocalID(ctx context.Context, c
 	return &event, nil
 }
 
+// FindDepositUsedByCommitment finds DepositUsed events of a commitment (uses idx_event_deposit_useds_commitment)
+// chainID <= 0 searches all chains.
 func (r *depositEventRepository) FindDepositUsedByCommitment(ctx context.Context, chainID int64, commitment string) ([]*models.EventDepositUsed, error) {
 	var events []*models.EventDepositUsed
-	err := r.db.WithContext(ctx).
-		Where("chain_id = ? AND commitment = ?", chainID, commitment).
-		Find(&events).Error
+	query := r.db.WithContext(ctx).Where("commitment = ?", commitment)
+	if chainID > 0 {
+		query = query.Where("chain_id = ?", chainID)
+	}
+	err := query.Find(&events).Error
 	if err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
+// FindDepositReceivedInRange lists DepositReceived events of a chain within a block range
+func (r *depositEventRepository) FindDepositReceivedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventDepositReceived, int64, error) {
+	var events []*models.EventDepositReceived
+	total, err := findEventsInRange(ctx, r.db, &models.EventDepositReceived{}, &events, blockRange)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// FindDepositRecordedInRange lists DepositRecorded events of a chain within a block range
+func (r *depositEventRepository) FindDepositRecordedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventDepositRecorded, int64, error) {
+	var events []*models.EventDepositRecorded
+	total, err := findEventsInRange(ctx, r.db, &models.EventDepositRecorded{}, &events, blockRange)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// FindDepositUsedInRange lists DepositUsed events of a chain within a block range
+func (r *depositEventRepository) FindDepositUsedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventDepositUsed, int64, error) {
+	var events []*models.EventDepositUsed
+	total, err := findEventsInRange(ctx, r.db, &models.EventDepositUsed{}, &events, blockRange)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
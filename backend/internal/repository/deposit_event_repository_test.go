@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCountByPromoteCodeNormalizesCode(t *testing.T) {
+	for _, code := range []string{"  abc123 ", "ABC123", "abc-123"} {
+		t.Run(code, func(t *testing.T) {
+			database, recorder := openRecording(t)
+			repo := NewDepositEventRepository(database)
+
+			if _, err := repo.CountByPromoteCode(context.Background(), code); err != nil {
+				t.Fatalf("CountByPromoteCode: %v", err)
+			}
+			if query := recorder.last(); !strings.Contains(query, "WHERE promote_code = $1") {
+				t.Errorf("query %q does not filter on promote_code", query)
+			}
+			if args := fmt.Sprint(recorder.lastArgs()); args != "[ABC123]" {
+				t.Errorf("args = %s, want [ABC123]", args)
+			}
+		})
+	}
+}
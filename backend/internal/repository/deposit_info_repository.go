@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrInsufficientAllocatable reserving the amount would take allocatable_remaining below zero
+var ErrInsufficientAllocatable = errors.New("deposit has insufficient allocatable amount remaining")
+
+// DepositInfoRepository defines the interface for DepositInfo data access
+type DepositInfoRepository interface {
+	GetByLocalID(ctx context.Context, chainID int64, localDepositID uint64) (*models.DepositInfo, error)
+
+	// Allocation tracking (allocatable_remaining)
+	ReserveFromDeposit(ctx context.Context, chainID int64, localDepositID uint64, amount string) error // fails with ErrInsufficientAllocatable instead of going negative
+	ReleaseToDeposit(ctx context.Context, chainID int64, localDepositID uint64, amount string) error   // never exceeds allocatable_amount
+}
+
+// depositInfoRepository implements DepositInfoRepository
+type depositInfoRepository struct {
+	db *gorm.DB
+}
+
+// NewDepositInfoRepository creates a new DepositInfoRepository instance
+func NewDepositInfoRepository(db *gorm.DB) DepositInfoRepository {
+	return &depositInfoRepository{db: db}
+}
+
+// GetByLocalID retrieves a deposit by SLIP-44 chain ID and local deposit ID
+func (r *depositInfoRepository) GetByLocalID(ctx context.Context, chainID int64, localDepositID uint64) (*models.DepositInfo, error) {
+	var deposit models.DepositInfo
+	err := r.db.WithContext(ctx).
+		Where("slip44_chain_id = ? AND local_deposit_id = ?", chainID, localDepositID).
+		First(&deposit).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deposit, nil
+}
+
+// ReserveFromDeposit atomically decrements allocatable_remaining by amount
+// The guard is in the UPDATE itself, so concurrent reservations cannot overdraw the deposit.
+func (r *depositInfoRepository) ReserveFromDeposit(ctx context.Context, chainID int64, localDepositID uint64, amount string) error {
+	if err := validateDepositAmount(amount); err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.DepositInfo{}).
+		Where("slip44_chain_id = ? AND local_deposit_id = ? AND allocatable_remaining >= ?::numeric", chainID, localDepositID, amount).
+		Update("allocatable_remaining", gorm.Expr("allocatable_remaining - ?::numeric", amount))
+	if result.Error != nil {
+		return fmt.Errorf("failed to reserve from deposit %d/%d: %w", chainID, localDepositID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.noRowsError(ctx, chainID, localDepositID, amount)
+	}
+	return nil
+}
+
+// ReleaseToDeposit atomically increments allocatable_remaining by amount (e.g. when allocations are replaced)
+func (r *depositInfoRepository) ReleaseToDeposit(ctx context.Context, chainID int64, localDepositID uint64, amount string) error {
+	if err := validateDepositAmount(amount); err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.DepositInfo{}).
+		Where("slip44_chain_id = ? AND local_deposit_id = ?", chainID, localDepositID).
+		Update("allocatable_remaining", gorm.Expr(
+			"LEAST(allocatable_remaining + ?::numeric, COALESCE(NULLIF(allocatable_amount, '')::numeric, 0))", amount))
+	if result.Error != nil {
+		return fmt.Errorf("failed to release to deposit %d/%d: %w", chainID, localDepositID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("deposit %d/%d: %w", chainID, localDepositID, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// noRowsError tells a missing deposit apart from an insufficient remaining amount
+func (r *depositInfoRepository) noRowsError(ctx context.Context, chainID int64, localDepositID uint64, amount string) error {
+	deposit, err := r.GetByLocalID(ctx, chainID, localDepositID)
+	if err != nil {
+		return fmt.Errorf("deposit %d/%d: %w", chainID, localDepositID, err)
+	}
+	return fmt.Errorf("%w: deposit %d/%d has %s, requested %s",
+		ErrInsufficientAllocatable, chainID, localDepositID, deposit.AllocatableRemaining, amount)
+}
+
+// validateDepositAmount requires a non-negative decimal integer
+func validateDepositAmount(amount string) error {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() < 0 {
+		return fmt.Errorf("invalid amount: %q", amount)
+	}
+	return nil
+}
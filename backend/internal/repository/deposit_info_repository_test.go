@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestDepositReservationRejectsInvalidAmounts(t *testing.T) {
+	for _, amount := range []string{"", "-1", "1.5", "1e18", "abc"} {
+		database, recorder := openRecording(t)
+		repo := NewDepositInfoRepository(database)
+
+		if err := repo.ReserveFromDeposit(context.Background(), 714, 7, amount); err == nil {
+			t.Errorf("ReserveFromDeposit(%q) succeeded", amount)
+		}
+		if err := repo.ReleaseToDeposit(context.Background(), 714, 7, amount); err == nil {
+			t.Errorf("ReleaseToDeposit(%q) succeeded", amount)
+		}
+		if len(recorder.statements) != 0 {
+			t.Errorf("amount %q reached the database: %q", amount, recorder.statements)
+		}
+	}
+}
+
+func TestReserveFromDepositGuardsInTheUpdate(t *testing.T) {
+	database, recorder := openRecording(t)
+	repo := NewDepositInfoRepository(database)
+
+	// The recorder affects no rows and finds no deposit: a missing deposit, not an insufficient one
+	err := repo.ReserveFromDeposit(context.Background(), 714, 7, "600")
+	if !errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrInsufficientAllocatable) {
+		t.Errorf("ReserveFromDeposit: %v, want ErrRecordNotFound", err)
+	}
+	if len(recorder.statements) != 2 {
+		t.Fatalf("statements = %q, want the guarded UPDATE and the deposit lookup", recorder.statements)
+	}
+	update := recorder.statements[0]
+	for _, want := range []string{
+		`SET "allocatable_remaining"=allocatable_remaining - $1::numeric`,
+		"allocatable_remaining >= $5::numeric",
+	} {
+		if !strings.Contains(update, want) {
+			t.Errorf("update %q does not contain %q", update, want)
+		}
+	}
+}
+
+func TestReleaseToDepositIsCappedAtAllocatableAmount(t *testing.T) {
+	database, recorder := openRecording(t)
+	repo := NewDepositInfoRepository(database)
+
+	err := repo.ReleaseToDeposit(context.Background(), 714, 7, "600")
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("ReleaseToDeposit on a missing deposit: %v, want ErrRecordNotFound", err)
+	}
+	if update := recorder.last(); !strings.Contains(update, "LEAST(allocatable_remaining + $1::numeric, COALESCE(NULLIF(allocatable_amount, '')::numeric, 0))") {
+		t.Errorf("update %q is not capped at allocatable_amount", update)
+	}
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// BlockRange selects events of one chain within an inclusive block range, ordered by block_number, log_index
+// Limit <= 0 returns every matching event.
+type BlockRange struct {
+	ChainID   int64
+	FromBlock uint64
+	ToBlock   uint64
+	Offset    int
+	Limit     int
+}
+
+// findEventsInRange loads the events of one event table within r into dest and returns the total match count
+func findEventsInRange(ctx context.Context, db *gorm.DB, model interface{}, dest interface{}, r BlockRange) (int64, error) {
+	query := db.WithContext(ctx).Model(model).
+		Where("chain_id = ? AND block_number BETWEEN ? AND ?", r.ChainID, r.FromBlock, r.ToBlock)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	query = query.Order("block_number ASC, log_index ASC")
+	if r.Offset > 0 {
+		query = query.Offset(r.Offset)
+	}
+	if r.Limit > 0 {
+		query = query.Limit(r.Limit)
+	}
+	if err := query.Find(dest).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
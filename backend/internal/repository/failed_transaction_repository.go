@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FailedTransactionFilter optional filters for FailedTransactionRepository.FindAll (empty fields match everything)
+type FailedTransactionFilter struct {
+	Status models.FailedTransactionStatus
+	TxType models.FailedTransactionType
+}
+
+// FailedTransactionRepository defines the interface for FailedTransaction data access
+type FailedTransactionRepository interface {
+	GetByID(ctx context.Context, id string) (*models.FailedTransaction, error)
+
+	// Query methods
+	FindByStatus(ctx context.Context, status models.FailedTransactionStatus) ([]*models.FailedTransaction, error)
+	FindByType(ctx context.Context, txType models.FailedTransactionType) ([]*models.FailedTransaction, error)
+	FindAll(ctx context.Context, filter FailedTransactionFilter, page, pageSize int) ([]*models.FailedTransaction, int64, error)
+}
+
+// failedTransactionRepository implements FailedTransactionRepository
+type failedTransactionRepository struct {
+	db *gorm.DB
+}
+
+// NewFailedTransactionRepository creates a new FailedTransactionRepository instance
+func NewFailedTransactionRepository(db *gorm.DB) FailedTransactionRepository {
+	return &failedTransactionRepository{db: db}
+}
+
+// GetByID gets a failed transaction by ID
+func (r *failedTransactionRepository) GetByID(ctx context.Context, id string) (*models.FailedTransaction, error) {
+	var failedTx models.FailedTransaction
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&failedTx).Error; err != nil {
+		return nil, err
+	}
+	return &failedTx, nil
+}
+
+// FindByStatus finds failed transactions by status, newest first
+func (r *failedTransactionRepository) FindByStatus(ctx context.Context, status models.FailedTransactionStatus) ([]*models.FailedTransaction, error) {
+	var failedTxs []*models.FailedTransaction
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at DESC").
+		Find(&failedTxs).Error
+	return failedTxs, err
+}
+
+// FindByType finds failed transactions by transaction type, newest first
+func (r *failedTransactionRepository) FindByType(ctx context.Context, txType models.FailedTransactionType) ([]*models.FailedTransaction, error) {
+	var failedTxs []*models.FailedTransaction
+	err := r.db.WithContext(ctx).
+		Where("tx_type = ?", txType).
+		Order("created_at DESC").
+		Find(&failedTxs).Error
+	return failedTxs, err
+}
+
+// FindAll finds failed transactions matching the filter with pagination, newest first
+func (r *failedTransactionRepository) FindAll(ctx context.Context, filter FailedTransactionFilter, page, pageSize int) ([]*models.FailedTransaction, int64, error) {
+	var failedTxs []*models.FailedTransaction
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.FailedTransaction{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TxType != "" {
+		query = query.Where("tx_type = ?", filter.TxType)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&failedTxs).Error
+	return failedTxs, total, err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestFailedTransactionFindAllFilters(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    FailedTransactionFilter
+		wantWhere string
+		wantArgs  string // bound arguments of the page query
+	}{
+		{"no filter", FailedTransactionFilter{}, "", "[20]"},
+		{"status only", FailedTransactionFilter{Status: models.FailedTransactionStatusPending},
+			`WHERE status = $1 ORDER BY`, "[pending 20]"},
+		{"tx type only", FailedTransactionFilter{TxType: models.FailedTransactionTypeWithdraw},
+			`WHERE tx_type = $1 ORDER BY`, "[withdraw 20]"},
+		{"status and tx type", FailedTransactionFilter{Status: models.FailedTransactionStatusAbandoned, TxType: models.FailedTransactionTypeCommitment},
+			`WHERE status = $1 AND tx_type = $2 ORDER BY`, "[abandoned commitment 20]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, recorder := openRecording(t)
+			repo := NewFailedTransactionRepository(database)
+
+			if _, _, err := repo.FindAll(context.Background(), tt.filter, 1, 20); err != nil {
+				t.Fatalf("FindAll: %v", err)
+			}
+			if len(recorder.statements) != 2 || !strings.HasPrefix(recorder.statements[0], "SELECT count(*)") {
+				t.Fatalf("statements = %q, want a count and a page query", recorder.statements)
+			}
+			page := recorder.last()
+			if tt.wantWhere == "" && strings.Contains(page, "WHERE") {
+				t.Errorf("unfiltered query %q has a WHERE clause", page)
+			}
+			if tt.wantWhere != "" && !strings.Contains(page, tt.wantWhere) {
+				t.Errorf("query %q does not contain %q", page, tt.wantWhere)
+			}
+			if !strings.Contains(page, "ORDER BY created_at DESC LIMIT $") {
+				t.Errorf("query %q is not ordered newest first with a limit", page)
+			}
+			if args := fmt.Sprint(recorder.lastArgs()); args != tt.wantArgs {
+				t.Errorf("args = %s, want %s", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFailedTransactionFindAllOffset(t *testing.T) {
+	database, recorder := openRecording(t)
+	repo := NewFailedTransactionRepository(database)
+
+	if _, _, err := repo.FindAll(context.Background(), FailedTransactionFilter{}, 3, 10); err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if query := recorder.last(); !strings.HasSuffix(query, "LIMIT $1 OFFSET $2") {
+		t.Errorf("query %q has no offset", query)
+	}
+	if args := fmt.Sprint(recorder.lastArgs()); args != "[10 20]" {
+		t.Errorf("args = %s, want [10 20]", args)
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MaxVersionConflictRetries attempts made by read-modify-write helpers before giving up on ErrVersionConflict
+const MaxVersionConflictRetries = 3
+
+// ErrVersionConflict the row was updated by another writer since it was read (optimistic lock)
+var ErrVersionConflict = errors.New("version conflict: record was modified concurrently")
+
+// UpdateIfVersion updates the row with the given id only if its version still equals version
+// model selects the table (e.g. &models.Checkbook{}) and must be models.OptimisticLocked so the version is incremented.
+// Returns ErrVersionConflict if the row was changed (or deleted) since it was read.
+func UpdateIfVersion(db *gorm.DB, model interface{}, id string, version int64, updates map[string]interface{}) error {
+	result := db.Model(model).
+		Where("id = ? AND version = ?", id, version).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: id=%s, version=%d", ErrVersionConflict, id, version)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go-backend/internal/models"
+)
+
+// QueueRootChainReport result of QueueRootRepository.ValidateChain for one chain
+type QueueRootChainReport struct {
+	ChainID    int64
+	Total      int
+	Genesis    []string       // roots whose previous_root is empty or all-zero (exactly one expected)
+	Gaps       []QueueRootGap // previous_root values with no stored root (missed CommitmentRootUpdated)
+	Duplicates []string       // root values stored more than once
+	Forks      []string       // previous_root values shared by more than one root
+	Cycles     [][]string     // roots linked back to themselves, in link order
+	Orphans    []string       // roots not reachable from a genesis root (behind a gap or in a cycle)
+}
+
+// QueueRootGap a missing predecessor and the stored root that points at it
+type QueueRootGap struct {
+	MissingRoot   string // previous_root that is not stored
+	NextRoot      string // stored root whose previous_root is MissingRoot
+	NextBlock     uint64 // block of NextRoot
+	PreviousBlock uint64 // block of the latest stored root before NextBlock, 0 if none
+}
+
+// OK reports whether the chain is a single unbroken list
+func (r *QueueRootChainReport) OK() bool {
+	return len(r.Genesis) <= 1 && len(r.Gaps) == 0 && len(r.Duplicates) == 0 &&
+		len(r.Forks) == 0 && len(r.Cycles) == 0 && len(r.Orphans) == 0
+}
+
+// ValidateChain checks the previous_root links of all queue roots of a chain
+// Every non-genesis root must have exactly one stored predecessor and be reachable from the genesis root.
+func (r *queueRootRepository) ValidateChain(ctx context.Context, chainID int64) (*QueueRootChainReport, error) {
+	var roots []*models.QueueRoot
+	if err := r.db.WithContext(ctx).Where("chain_id = ?", chainID).Order("block_number ASC, created_at ASC").Find(&roots).Error; err != nil {
+		return nil, err
+	}
+	return validateQueueRootChain(chainID, roots), nil
+}
+
+// validateQueueRootChain builds the report from the loaded roots (ordered by block number)
+func validateQueueRootChain(chainID int64, roots []*models.QueueRoot) *QueueRootChainReport {
+	report := &QueueRootChainReport{ChainID: chainID, Total: len(roots)}
+
+	byRoot := make(map[string]*models.QueueRoot, len(roots))
+	successors := make(map[string][]string)
+	for _, record := range roots {
+		key := normalizeQueueRoot(record.Root)
+		if _, exists := byRoot[key]; exists {
+			report.Duplicates = append(report.Duplicates, record.Root)
+			continue
+		}
+		byRoot[key] = record
+		if isGenesisQueueRoot(record.PreviousRoot) {
+			report.Genesis = append(report.Genesis, record.Root)
+			continue
+		}
+		prev := normalizeQueueRoot(record.PreviousRoot)
+		successors[prev] = append(successors[prev], record.Root)
+	}
+
+	for prev, next := range successors {
+		if len(next) > 1 {
+			report.Forks = append(report.Forks, prev)
+		}
+		if _, exists := byRoot[prev]; !exists {
+			for _, root := range next {
+				nextRecord := byRoot[normalizeQueueRoot(root)]
+				report.Gaps = append(report.Gaps, QueueRootGap{
+					MissingRoot:   prev,
+					NextRoot:      root,
+					NextBlock:     nextRecord.BlockNumber,
+					PreviousBlock: latestBlockBefore(roots, nextRecord.BlockNumber),
+				})
+			}
+		}
+	}
+
+	// Follow previous_root links from every root: 1 = on the current walk, 2 = done
+	state := make(map[string]int, len(byRoot))
+	reachable := make(map[string]bool, len(byRoot))
+	for _, record := range roots {
+		var path []string
+		key := normalizeQueueRoot(record.Root)
+		ok := false
+		for {
+			if state[key] == 2 {
+				ok = reachable[key]
+				break
+			}
+			if state[key] == 1 {
+				report.Cycles = append(report.Cycles, cycleFrom(path, key, byRoot))
+				break
+			}
+			state[key] = 1
+			path = append(path, key)
+			current := byRoot[key]
+			if isGenesisQueueRoot(current.PreviousRoot) {
+				ok = true
+				break
+			}
+			prev := normalizeQueueRoot(current.PreviousRoot)
+			if _, exists := byRoot[prev]; !exists {
+				break // gap, already reported
+			}
+			key = prev
+		}
+		for _, visited := range path {
+			state[visited] = 2
+			reachable[visited] = ok
+		}
+	}
+
+	for _, record := range roots {
+		key := normalizeQueueRoot(record.Root)
+		if byRoot[key] == record && !reachable[key] {
+			report.Orphans = append(report.Orphans, record.Root)
+		}
+	}
+
+	sort.Strings(report.Forks)
+	sort.Slice(report.Gaps, func(i, j int) bool { return report.Gaps[i].NextBlock < report.Gaps[j].NextBlock })
+	return report
+}
+
+// cycleFrom returns the roots of the cycle that starts at key on the current walk
+func cycleFrom(path []string, key string, byRoot map[string]*models.QueueRoot) []string {
+	var cycle []string
+	for i, visited := range path {
+		if visited == key {
+			for _, member := range path[i:] {
+				cycle = append(cycle, byRoot[member].Root)
+			}
+			break
+		}
+	}
+	return cycle
+}
+
+// latestBlockBefore returns the highest block number below block among roots ordered by block number
+func latestBlockBefore(roots []*models.QueueRoot, block uint64) uint64 {
+	latest := uint64(0)
+	for _, record := range roots {
+		if record.BlockNumber >= block {
+			break
+		}
+		latest = record.BlockNumber
+	}
+	return latest
+}
+
+// isGenesisQueueRoot reports whether previous_root marks the first root of the queue
+func isGenesisQueueRoot(previousRoot string) bool {
+	return previousRoot == "" || normalizeQueueRoot(previousRoot) == zeroQueueRoot
+}
+
+// normalizeQueueRoot lowercases a root for comparison
+func normalizeQueueRoot(root string) string {
+	return strings.ToLower(strings.TrimSpace(root))
+}
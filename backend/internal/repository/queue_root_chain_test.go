@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+// queueRootChain roots 0..n-1 of a chain, root i mined in block 100+10*i
+func queueRootChain(n int) []*models.QueueRoot {
+	roots := make([]*models.QueueRoot, 0, n)
+	for i := 0; i < n; i++ {
+		record := testQueueRoot(i)
+		record.BlockNumber = uint64(100 + 10*i)
+		roots = append(roots, record)
+	}
+	return roots
+}
+
+func TestValidateQueueRootChainValid(t *testing.T) {
+	roots := queueRootChain(5)
+	roots[3].Root = "0x" + strings.ToUpper(roots[3].Root[2:]) // links compare case-insensitively
+
+	report := validateQueueRootChain(714, roots)
+	if !report.OK() {
+		t.Fatalf("report = %+v, want OK", report)
+	}
+	if report.Total != 5 || len(report.Genesis) != 1 || report.Genesis[0] != roots[0].Root {
+		t.Errorf("total = %d, genesis = %v, want 5 roots from %s", report.Total, report.Genesis, roots[0].Root)
+	}
+}
+
+func TestValidateQueueRootChainGap(t *testing.T) {
+	all := queueRootChain(5)
+	roots := append(append([]*models.QueueRoot{}, all[:2]...), all[3:]...) // root 2 never stored
+
+	report := validateQueueRootChain(714, roots)
+	if report.OK() {
+		t.Fatal("a chain with a missing root reported OK")
+	}
+	want := QueueRootGap{MissingRoot: all[2].Root, NextRoot: all[3].Root, NextBlock: 130, PreviousBlock: 110}
+	if len(report.Gaps) != 1 || report.Gaps[0] != want {
+		t.Errorf("gaps = %+v, want [%+v]", report.Gaps, want)
+	}
+	if fmt.Sprint(report.Orphans) != fmt.Sprint([]string{all[3].Root, all[4].Root}) {
+		t.Errorf("orphans = %v, want the roots behind the gap", report.Orphans)
+	}
+	if len(report.Cycles) != 0 || len(report.Forks) != 0 || len(report.Duplicates) != 0 {
+		t.Errorf("report = %+v, want only the gap", report)
+	}
+}
+
+func TestValidateQueueRootChainCycle(t *testing.T) {
+	roots := queueRootChain(3)
+	a, b := testQueueRoot(10), testQueueRoot(11)
+	a.PreviousRoot = b.Root // a -> b -> a, detached from the genesis chain
+	roots = append(roots, a, b)
+
+	report := validateQueueRootChain(714, roots)
+	if report.OK() {
+		t.Fatal("a chain with a cycle reported OK")
+	}
+	if len(report.Cycles) != 1 || len(report.Cycles[0]) != 2 {
+		t.Fatalf("cycles = %v, want one cycle of two roots", report.Cycles)
+	}
+	members := strings.Join(report.Cycles[0], ",")
+	if !strings.Contains(members, a.Root) || !strings.Contains(members, b.Root) {
+		t.Errorf("cycle = %v, want %s and %s", report.Cycles[0], a.Root, b.Root)
+	}
+	if fmt.Sprint(report.Orphans) != fmt.Sprint([]string{a.Root, b.Root}) {
+		t.Errorf("orphans = %v, want the cycle members", report.Orphans)
+	}
+	if len(report.Gaps) != 0 {
+		t.Errorf("gaps = %+v, want none", report.Gaps)
+	}
+}
+
+func TestValidateQueueRootChainDuplicatesAndForks(t *testing.T) {
+	roots := queueRootChain(3)
+	duplicate := *roots[1]
+	duplicate.ID = "qr-dup"
+	fork := testQueueRoot(20)
+	fork.PreviousRoot = roots[1].Root // second successor of root 1
+	roots = append(roots, &duplicate, fork)
+
+	report := validateQueueRootChain(714, roots)
+	if fmt.Sprint(report.Duplicates) != fmt.Sprint([]string{roots[1].Root}) {
+		t.Errorf("duplicates = %v, want [%s]", report.Duplicates, roots[1].Root)
+	}
+	if fmt.Sprint(report.Forks) != fmt.Sprint([]string{roots[1].Root}) {
+		t.Errorf("forks = %v, want [%s]", report.Forks, roots[1].Root)
+	}
+}
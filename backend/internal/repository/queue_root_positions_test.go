@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// memQueueRootLinks queueRootLinks over an in-memory chain
+type memQueueRootLinks struct {
+	roots map[string]*models.QueueRoot // key: root
+	ids   map[string]*models.QueueRoot // key: ID
+}
+
+func newMemQueueRootLinks() *memQueueRootLinks {
+	return &memQueueRootLinks{roots: make(map[string]*models.QueueRoot), ids: make(map[string]*models.QueueRoot)}
+}
+
+func (l *memQueueRootLinks) add(record *models.QueueRoot) {
+	l.roots[record.Root] = record
+	l.ids[record.ID] = record
+}
+
+func (l *memQueueRootLinks) byRoot(root string) (*models.QueueRoot, error) {
+	if record, ok := l.roots[root]; ok {
+		copied := *record
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (l *memQueueRootLinks) successor(previousRoot string) (*models.QueueRoot, error) {
+	return l.findSuccessor(previousRoot, false)
+}
+
+func (l *memQueueRootLinks) unpositionedSuccessor(previousRoot string) (*models.QueueRoot, error) {
+	return l.findSuccessor(previousRoot, true)
+}
+
+func (l *memQueueRootLinks) findSuccessor(previousRoot string, unpositioned bool) (*models.QueueRoot, error) {
+	for _, record := range l.roots {
+		if record.PreviousRoot == previousRoot && (!unpositioned || record.Position == nil) {
+			copied := *record
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (l *memQueueRootLinks) setPosition(id string, position int64) error {
+	l.ids[id].Position = &position
+	return nil
+}
+
+func (l *memQueueRootLinks) position(t *testing.T, root string) int64 {
+	t.Helper()
+	record := l.roots[root]
+	if record.Position == nil {
+		t.Fatalf("root %s has no position", record.ID)
+	}
+	return *record.Position
+}
+
+// testQueueRoot root i of a chain, linked to root i-1 (the first root links to the zero root)
+func testQueueRoot(i int) *models.QueueRoot {
+	return &models.QueueRoot{
+		ID:                  fmt.Sprintf("qr-%d", i),
+		Root:                fmt.Sprintf("0x%064x", i+1),
+		PreviousRoot:        fmt.Sprintf("0x%064x", i),
+		CreatedByCommitment: fmt.Sprintf("0xc%063x", i+1),
+	}
+}
+
+func TestAssignQueuePositionsNumbersWholeChain(t *testing.T) {
+	const chainLength = 2000
+	links := newMemQueueRootLinks()
+	for i := 0; i < chainLength; i++ {
+		links.add(testQueueRoot(i))
+	}
+
+	// Positioning from the first root walks forward over every unpositioned successor
+	if err := assignQueuePositions(links, testQueueRoot(0).Root); err != nil {
+		t.Fatalf("assignQueuePositions: %v", err)
+	}
+	for i := 0; i < chainLength; i++ {
+		if got := links.position(t, testQueueRoot(i).Root); got != int64(i+1) {
+			t.Fatalf("root %d position = %d, want %d", i, got, i+1)
+		}
+	}
+
+	// An appended root continues from its positioned predecessor
+	next := testQueueRoot(chainLength)
+	links.add(next)
+	if err := assignQueuePositions(links, next.Root); err != nil {
+		t.Fatalf("assignQueuePositions appended root: %v", err)
+	}
+	if got := links.position(t, next.Root); got != chainLength+1 {
+		t.Errorf("appended root position = %d, want %d", got, chainLength+1)
+	}
+}
+
+func TestAssignQueuePositionsOutOfOrderArrival(t *testing.T) {
+	links := newMemQueueRootLinks()
+	for _, i := range []int{0, 1} {
+		links.add(testQueueRoot(i))
+	}
+	if err := assignQueuePositions(links, testQueueRoot(1).Root); err != nil {
+		t.Fatalf("assignQueuePositions: %v", err)
+	}
+
+	// Roots 3 and 4 arrive before root 2: their predecessor is missing, so they stay unpositioned
+	for _, i := range []int{3, 4} {
+		links.add(testQueueRoot(i))
+		if err := assignQueuePositions(links, testQueueRoot(i).Root); err != nil {
+			t.Fatalf("assignQueuePositions root %d: %v", i, err)
+		}
+		if links.roots[testQueueRoot(i).Root].Position != nil {
+			t.Fatalf("root %d positioned across a gap", i)
+		}
+	}
+
+	// Backfilling root 2 numbers it and the successors that were waiting behind it
+	links.add(testQueueRoot(2))
+	if err := assignQueuePositions(links, testQueueRoot(2).Root); err != nil {
+		t.Fatalf("assignQueuePositions backfilled root: %v", err)
+	}
+	for i := 0; i <= 4; i++ {
+		if got := links.position(t, testQueueRoot(i).Root); got != int64(i+1) {
+			t.Errorf("root %d position = %d, want %d", i, got, i+1)
+		}
+	}
+}
+
+func TestAssignQueuePositionsDetectsCycle(t *testing.T) {
+	links := newMemQueueRootLinks()
+	a, b := testQueueRoot(0), testQueueRoot(1)
+	a.PreviousRoot = b.Root // a -> b -> a
+	links.add(a)
+	links.add(b)
+
+	err := assignQueuePositions(links, b.Root)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("assignQueuePositions: %v, want a cycle error", err)
+	}
+	if a.Position != nil || b.Position != nil {
+		t.Error("roots positioned despite the cycle")
+	}
+}
+
+func TestWalkCommitmentsAfterFollowsLinks(t *testing.T) {
+	links := newMemQueueRootLinks()
+	for i := 0; i < 5; i++ {
+		links.add(testQueueRoot(i))
+	}
+	links.roots[testQueueRoot(3).Root].CreatedByCommitment = "" // roots without a commitment are skipped
+
+	commitments, err := walkCommitmentsAfter(links, testQueueRoot(1).Root)
+	if err != nil {
+		t.Fatalf("walkCommitmentsAfter: %v", err)
+	}
+	want := []string{testQueueRoot(2).CreatedByCommitment, testQueueRoot(4).CreatedByCommitment}
+	if strings.Join(commitments, ",") != strings.Join(want, ",") {
+		t.Errorf("commitments = %v, want %v", commitments, want)
+	}
+
+	if commitments, err := walkCommitmentsAfter(links, testQueueRoot(4).Root); err != nil || len(commitments) != 0 {
+		t.Errorf("after the last root = %v, %v, want none", commitments, err)
+	}
+}
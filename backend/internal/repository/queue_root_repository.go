@@ -2,11 +2,15 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"go-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
+// zeroQueueRoot previous_root of the first queue root
+const zeroQueueRoot = "0x0000000000000000000000000000000000000000000000000000000000000000"
+
 // QueueRootRepository defines the interface for QueueRoot data access
 type QueueRootRepository interface {
 	// Basic CRUD operations
@@ -23,12 +27,20 @@ type QueueRootRepository interface {
 	GetByCommitment(ctx context.Context, commitment string) (*models.QueueRoot, error) // Get queue root by created_by_commitment
 	FindByPreviousRoot(ctx context.Context, previousRoot string) (*models.QueueRoot, error) // Find queue root by previous_root
 
+	// Queue position index (commitments after a root without walking the linked list)
+	AssignPositions(ctx context.Context, root string, chainID int64) error
+	GetCommitmentsAfter(ctx context.Context, root string) ([]string, error)
+
+	// Integrity check of the previous_root links (see QueueRootChainReport)
+	ValidateChain(ctx context.Context, chainID int64) (*QueueRootChainReport, error)
+
 	// CommitmentRootUpdated event operations
 	CreateCommitmentRootUpdatedEvent(ctx context.Context, event *models.EventCommitmentRootUpdated) error
 	GetCommitmentRootUpdatedEventByID(ctx context.Context, id uint64) (*models.EventCommitmentRootUpdated, error)
 	FindCommitmentRootUpdatedByRoot(ctx context.Context, newRoot string) (*models.EventCommitmentRootUpdated, error)
 	FindCommitmentRootUpdatedByChain(ctx context.Context, chainID int64, page, limit int) ([]*models.EventCommitmentRootUpdated, int64, error)
 	FindCommitmentRootUpdatedByTxHash(ctx context.Context, chainID int64, txHash string) ([]*models.EventCommitmentRootUpdated, error)
+	FindCommitmentRootUpdatedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventCommitmentRootUpdated, int64, error)
 }
 
 // queueRootRepository implements QueueRootRepository
@@ -132,6 +144,166 @@ func (r *queueRootRepository) FindByPreviousRoot(ctx context.Context, previousRo
 	return &queueRoot, nil
 }
 
+// AssignPositions assigns queue positions to root and any unpositioned neighbours
+// Leaves the segment unpositioned if the chain back to a positioned root is incomplete.
+func (r *queueRootRepository) AssignPositions(ctx context.Context, root string, chainID int64) error {
+	return assignQueuePositions(gormQueueRootLinks{db: r.db.WithContext(ctx), chainID: chainID}, root)
+}
+
+// GetCommitmentsAfter returns the commitments of all queue roots after root, in queue order
+// Uses the position index (single range query); roots that cannot be positioned fall back to walking the linked list
+func (r *queueRootRepository) GetCommitmentsAfter(ctx context.Context, root string) ([]string, error) {
+	record, err := r.GetByRoot(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if record.Position == nil {
+		if err := r.AssignPositions(ctx, record.Root, record.ChainID); err != nil {
+			return nil, fmt.Errorf("failed to assign queue positions: %w", err)
+		}
+		if record, err = r.GetByRoot(ctx, root); err != nil {
+			return nil, err
+		}
+	}
+	if record.Position == nil {
+		return walkCommitmentsAfter(gormQueueRootLinks{db: r.db.WithContext(ctx), chainID: record.ChainID}, record.Root)
+	}
+
+	commitments := make([]string, 0)
+	err = r.db.WithContext(ctx).
+		Model(&models.QueueRoot{}).
+		Where("chain_id = ? AND position > ? AND created_by_commitment <> ''", record.ChainID, *record.Position).
+		Order("position ASC").
+		Pluck("created_by_commitment", &commitments).Error
+	if err != nil {
+		return nil, err
+	}
+	return commitments, nil
+}
+
+// queueRootLinks the lookups and writes the queue position numbering needs, scoped to one chain
+type queueRootLinks interface {
+	byRoot(root string) (*models.QueueRoot, error)            // gorm.ErrRecordNotFound when absent
+	successor(previousRoot string) (*models.QueueRoot, error) // gorm.ErrRecordNotFound at the end of the queue
+	unpositionedSuccessor(previousRoot string) (*models.QueueRoot, error)
+	setPosition(id string, position int64) error
+}
+
+// gormQueueRootLinks queueRootLinks over the queue_roots table
+type gormQueueRootLinks struct {
+	db      *gorm.DB
+	chainID int64
+}
+
+func (l gormQueueRootLinks) byRoot(root string) (*models.QueueRoot, error) {
+	var record models.QueueRoot
+	if err := l.db.Where("root = ? AND chain_id = ?", root, l.chainID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (l gormQueueRootLinks) successor(previousRoot string) (*models.QueueRoot, error) {
+	var record models.QueueRoot
+	if err := l.db.Where("previous_root = ? AND chain_id = ?", previousRoot, l.chainID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (l gormQueueRootLinks) unpositionedSuccessor(previousRoot string) (*models.QueueRoot, error) {
+	var record models.QueueRoot
+	if err := l.db.Where("previous_root = ? AND chain_id = ? AND position IS NULL", previousRoot, l.chainID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (l gormQueueRootLinks) setPosition(id string, position int64) error {
+	return l.db.Model(&models.QueueRoot{}).Where("id = ?", id).Update("position", position).Error
+}
+
+// assignQueuePositions walks back from root to the nearest positioned root (or the all-zero root), numbers that
+// segment forward, then continues numbering successors that arrived earlier without a position.
+// Returns nil without numbering anything if a predecessor is missing (positions are assigned once the gap is backfilled).
+func assignQueuePositions(links queueRootLinks, root string) error {
+	// 1. Walk back to a positioned root
+	var segment []*models.QueueRoot // newest first
+	base := int64(0)
+	visited := make(map[string]bool)
+	for current := root; ; {
+		if visited[current] {
+			return fmt.Errorf("queue root cycle detected at %s", current)
+		}
+		visited[current] = true
+
+		record, err := links.byRoot(current)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if record.Position != nil {
+			base = *record.Position
+			break
+		}
+		segment = append(segment, record)
+		if record.PreviousRoot == "" || record.PreviousRoot == zeroQueueRoot {
+			break
+		}
+		current = record.PreviousRoot
+	}
+
+	// 2. Number the segment oldest first
+	position := base
+	for i := len(segment) - 1; i >= 0; i-- {
+		position++
+		if err := links.setPosition(segment[i].ID, position); err != nil {
+			return err
+		}
+	}
+
+	// 3. Continue with successors that were stored before root was positioned
+	for current := root; ; {
+		next, err := links.unpositionedSuccessor(current)
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		position++
+		if err := links.setPosition(next.ID, position); err != nil {
+			return err
+		}
+		current = next.Root
+	}
+}
+
+// walkCommitmentsAfter follows previous_root links forward from root until the end of the queue
+func walkCommitmentsAfter(links queueRootLinks, root string) ([]string, error) {
+	commitments := make([]string, 0)
+	visited := map[string]bool{root: true}
+	for current := root; ; {
+		next, err := links.successor(current)
+		if err == gorm.ErrRecordNotFound {
+			return commitments, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if visited[next.Root] {
+			return nil, fmt.Errorf("queue root cycle detected at %s", next.Root)
+		}
+		visited[next.Root] = true
+		if next.CreatedByCommitment != "" {
+			commitments = append(commitments, next.CreatedByCommitment)
+		}
+		current = next.Root
+	}
+}
+
 // CommitmentRootUpdated event operations
 func (r *queueRootRepository) CreateCommitmentRootUpdatedEvent(ctx context.Context, event *models.EventCommitmentRootUpdated) error {
 	return r.db.WithContext(ctx).Create(event).Error
@@ -182,3 +354,12 @@ func (r *queueRootRepository) FindCommitmentRootUpdatedByTxHash(ctx context.Cont
 	return events, nil
 }
 
+// FindCommitmentRootUpdatedInRange lists CommitmentRootUpdated events of a chain within a block range
+func (r *queueRootRepository) FindCommitmentRootUpdatedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventCommitmentRootUpdated, int64, error) {
+	var events []*models.EventCommitmentRootUpdated
+	total, err := findEventsInRange(ctx, r.db, &models.EventCommitmentRootUpdated{}, &events, blockRange)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder a database/sql connector that records every statement and answers queries with no rows
+// Lets tests check the SQL a repository builds without a database.
+type sqlRecorder struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]interface{} // bound arguments of each statement
+}
+
+func (r *sqlRecorder) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{recorder: r}, nil
+}
+func (r *sqlRecorder) Driver() driver.Driver { return recordingDriver{} }
+
+func (r *sqlRecorder) record(query string, args []driver.NamedValue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.statements = append(r.statements, query)
+	r.args = append(r.args, values)
+}
+
+// last returns the last recorded statement
+func (r *sqlRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.statements) == 0 {
+		return ""
+	}
+	return r.statements[len(r.statements)-1]
+}
+
+// lastArgs returns the bound arguments of the last recorded statement
+func (r *sqlRecorder) lastArgs() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.args) == 0 {
+		return nil
+	}
+	return r.args[len(r.args)-1]
+}
+
+type recordingDriver struct{}
+
+func (recordingDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("recordingDriver: use sql.OpenDB with a sqlRecorder")
+}
+
+type recordingConn struct{ recorder *sqlRecorder }
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("recordingConn: prepare not supported")
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+func (c *recordingConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.recorder.record(query, args)
+	return emptyRows{}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.recorder.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+// openRecording opens a Postgres-dialect gorm.DB over a sqlRecorder
+func openRecording(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{}
+	database, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(recorder)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return database, recorder
+}
+
+func TestFindDeletedClampsPagination(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		want     string
+		notWant  string
+	}{
+		{"first page", 1, 20, "LIMIT $2", "OFFSET"},
+		{"second page", 2, 20, "LIMIT $2 OFFSET $3", ""},
+		{"page zero", 0, 20, "LIMIT $2", "OFFSET"},
+		{"negative page", -3, 20, "LIMIT $2", "OFFSET"},
+		{"no page size", 2, 0, "ORDER BY deleted_at DESC", "LIMIT"},
+		{"negative page size", 1, -5, "ORDER BY deleted_at DESC", "LIMIT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, recorder := openRecording(t)
+			repo := NewWithdrawRequestRepository(database)
+
+			if _, _, err := repo.FindDeleted(context.Background(), "0xnullifier", tt.page, tt.pageSize); err != nil {
+				t.Fatalf("FindDeleted: %v", err)
+			}
+			query := recorder.last()
+			if !strings.Contains(query, tt.want) {
+				t.Errorf("query %q does not contain %q", query, tt.want)
+			}
+			if tt.notWant != "" && strings.Contains(query, tt.notWant) {
+				t.Errorf("query %q contains %q", query, tt.notWant)
+			}
+		})
+	}
+}
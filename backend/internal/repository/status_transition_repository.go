@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StatusTransitionRepository defines the interface for StatusTransition data access
+type StatusTransitionRepository interface {
+	Create(ctx context.Context, transition *models.StatusTransition) error
+
+	// Query methods
+	FindByEntity(ctx context.Context, entityType, entityID string) ([]*models.StatusTransition, error)
+}
+
+// statusTransitionRepository implements StatusTransitionRepository
+type statusTransitionRepository struct {
+	db *gorm.DB
+}
+
+// NewStatusTransitionRepository creates a new StatusTransitionRepository instance
+func NewStatusTransitionRepository(db *gorm.DB) StatusTransitionRepository {
+	return &statusTransitionRepository{db: db}
+}
+
+// Create records a status transition
+func (r *statusTransitionRepository) Create(ctx context.Context, transition *models.StatusTransition) error {
+	return r.db.WithContext(ctx).Create(transition).Error
+}
+
+// FindByEntity returns the status history of an entity, oldest first
+func (r *statusTransitionRepository) FindByEntity(ctx context.Context, entityType, entityID string) ([]*models.StatusTransition, error) {
+	var transitions []*models.StatusTransition
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC, id ASC").
+		Find(&transitions).Error
+	return transitions, err
+}
+
+// transitionTriggerKey is the context key of the trigger context recorded with status transitions
+type transitionTriggerKey struct{}
+
+// WithTransitionTrigger sets the trigger context (e.g. "ExecuteWithdraw", "UnifiedPolling") recorded with the
+// status transitions written by repository methods called with the returned context
+// A trigger already set on ctx is kept: the outermost caller (e.g. RetryPayout calling ProcessPayout) wins.
+func WithTransitionTrigger(ctx context.Context, trigger string) context.Context {
+	if existing, ok := ctx.Value(transitionTriggerKey{}).(string); ok && existing != "" {
+		return ctx
+	}
+	return context.WithValue(ctx, transitionTriggerKey{}, trigger)
+}
+
+// transitionTrigger returns the trigger context set on ctx, or fallback (the repository method) if none is set
+func transitionTrigger(ctx context.Context, fallback string) string {
+	if trigger, ok := ctx.Value(transitionTriggerKey{}).(string); ok && trigger != "" {
+		return trigger
+	}
+	return fallback
+}
+
+// withdrawStatuses is the main status and the stage sub-statuses of a withdraw request
+type withdrawStatuses struct {
+	Status        string
+	ProofStatus   string
+	ExecuteStatus string
+	PayoutStatus  string
+	HookStatus    string
+}
+
+// statusesOf returns the statuses of a withdraw request
+func statusesOf(request *models.WithdrawRequest) withdrawStatuses {
+	return withdrawStatuses{
+		Status:        request.Status,
+		ProofStatus:   string(request.ProofStatus),
+		ExecuteStatus: string(request.ExecuteStatus),
+		PayoutStatus:  string(request.PayoutStatus),
+		HookStatus:    string(request.HookStatus),
+	}
+}
+
+// withdrawStatusTransitions returns one transition per changed status between before and after
+// Main status changes are recorded as-is, stage sub-status changes as "<stage>:<status>" (e.g. "proof:pending"),
+// so a withdraw request has a single history under models.StatusTransitionEntityWithdrawRequest.
+func withdrawStatusTransitions(id string, before, after withdrawStatuses, trigger string) []*models.StatusTransition {
+	now := time.Now()
+	var transitions []*models.StatusTransition
+	add := func(stage, from, to string) {
+		if from == to {
+			return
+		}
+		if stage != "" {
+			from, to = stage+":"+from, stage+":"+to
+		}
+		transitions = append(transitions, &models.StatusTransition{
+			EntityType:     models.StatusTransitionEntityWithdrawRequest,
+			EntityID:       id,
+			FromStatus:     from,
+			ToStatus:       to,
+			TriggerContext: trigger,
+			CreatedAt:      now,
+		})
+	}
+	add("proof", before.ProofStatus, after.ProofStatus)
+	add("execute", before.ExecuteStatus, after.ExecuteStatus)
+	add("payout", before.PayoutStatus, after.PayoutStatus)
+	add("hook", before.HookStatus, after.HookStatus)
+	add("", before.Status, after.Status)
+	return transitions
+}
+
+// recordWithdrawStatusTransitions writes the transitions of a withdraw request (failures are logged, never returned)
+// The rows are written in a nested transaction (a savepoint inside the caller's transaction), so a failed
+// insert does not abort the status update it belongs to.
+func recordWithdrawStatusTransitions(ctx context.Context, db *gorm.DB, id string, before, after withdrawStatuses, trigger string) {
+	transitions := withdrawStatusTransitions(id, before, after, trigger)
+	if len(transitions) == 0 {
+		return
+	}
+	if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&transitions).Error
+	}); err != nil {
+		log.Printf("⚠️ [%s] Failed to record status transitions for withdraw request %s: %v", trigger, id, err)
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestWithdrawStatusTransitionsRecordsEachChangedStatus(t *testing.T) {
+	before := withdrawStatuses{Status: "proving", ProofStatus: "in_progress", ExecuteStatus: "pending", PayoutStatus: "pending", HookStatus: "not_required"}
+	after := before
+	after.ProofStatus = "completed"
+	after.Status = "proof_generated"
+
+	transitions := withdrawStatusTransitions("wr1", before, after, "autoGenerateProof")
+	if len(transitions) != 2 {
+		t.Fatalf("got %d transitions, want proof and main status", len(transitions))
+	}
+	if got := transitions[0]; got.FromStatus != "proof:in_progress" || got.ToStatus != "proof:completed" {
+		t.Errorf("stage transition = %s → %s, want proof:in_progress → proof:completed", got.FromStatus, got.ToStatus)
+	}
+	if got := transitions[1]; got.FromStatus != "proving" || got.ToStatus != "proof_generated" {
+		t.Errorf("main transition = %s → %s, want proving → proof_generated", got.FromStatus, got.ToStatus)
+	}
+	for _, transition := range transitions {
+		if transition.EntityType != models.StatusTransitionEntityWithdrawRequest || transition.EntityID != "wr1" ||
+			transition.TriggerContext != "autoGenerateProof" {
+			t.Errorf("transition = %+v, want withdraw_request wr1 triggered by autoGenerateProof", transition)
+		}
+	}
+
+	if transitions := withdrawStatusTransitions("wr1", before, before, "Modify"); len(transitions) != 0 {
+		t.Errorf("unchanged statuses recorded %d transitions, want none", len(transitions))
+	}
+}
+
+func TestTransitionTriggerFallsBackToRepositoryMethod(t *testing.T) {
+	if got := transitionTrigger(context.Background(), "UpdateProofStatus"); got != "UpdateProofStatus" {
+		t.Errorf("trigger = %q, want the repository method", got)
+	}
+	ctx := WithTransitionTrigger(context.Background(), "ExecuteWithdraw")
+	if got := transitionTrigger(ctx, "UpdateProofStatus"); got != "ExecuteWithdraw" {
+		t.Errorf("trigger = %q, want ExecuteWithdraw", got)
+	}
+}
+
+func TestWithTransitionTriggerKeepsOutermostTrigger(t *testing.T) {
+	ctx := WithTransitionTrigger(context.Background(), "RetryPayout")
+	ctx = WithTransitionTrigger(ctx, "ProcessPayout")
+	ctx = WithTransitionTrigger(ctx, "UpdatePayoutStatus")
+	if got := transitionTrigger(ctx, "Modify"); got != "RetryPayout" {
+		t.Errorf("trigger = %q, want the outermost RetryPayout", got)
+	}
+}
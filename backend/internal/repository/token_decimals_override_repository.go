@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenDecimalsOverrideRepository defines the interface for TokenDecimalsOverride data access
+type TokenDecimalsOverrideRepository interface {
+	FindAll(ctx context.Context) ([]*models.TokenDecimalsOverride, error)
+	Upsert(ctx context.Context, override *models.TokenDecimalsOverride) error // insert, or update decimals of (chain_id, token_address)
+}
+
+// tokenDecimalsOverrideRepository implements TokenDecimalsOverrideRepository
+type tokenDecimalsOverrideRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenDecimalsOverrideRepository creates a new TokenDecimalsOverrideRepository instance
+func NewTokenDecimalsOverrideRepository(db *gorm.DB) TokenDecimalsOverrideRepository {
+	return &tokenDecimalsOverrideRepository{db: db}
+}
+
+// FindAll finds every override, ordered by chain and token address
+func (r *tokenDecimalsOverrideRepository) FindAll(ctx context.Context) ([]*models.TokenDecimalsOverride, error) {
+	var overrides []*models.TokenDecimalsOverride
+	err := r.db.WithContext(ctx).
+		Order("chain_id ASC, token_address ASC").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// Upsert inserts an override or replaces the decimals of the existing one for the same chain and token
+func (r *tokenDecimalsOverrideRepository) Upsert(ctx context.Context, override *models.TokenDecimalsOverride) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chain_id"}, {Name: "token_address"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"decimals":            override.Decimals,
+			"configured_decimals": override.ConfiguredDecimals,
+			"updated_at":          gorm.Expr("NOW()"),
+		}),
+	}).Create(override).Error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories repositories bound to one database handle, see Transactor
+type Repositories struct {
+	WithdrawRequests WithdrawRequestRepository
+	Allocations      AllocationRepository
+	Checkbooks       CheckbookRepository
+}
+
+// Transactor runs work spanning several repositories in one database transaction
+type Transactor interface {
+	// InTransaction runs fn with repositories bound to a transaction: committed when fn returns nil,
+	// rolled back when it returns an error (which InTransaction returns)
+	InTransaction(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// gormTransactor implements Transactor
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a Transactor running its transactions on db
+func NewTransactor(db *gorm.DB) Transactor {
+	return &gormTransactor{db: db}
+}
+
+// InTransaction implements Transactor
+func (t *gormTransactor) InTransaction(ctx context.Context, fn func(repos Repositories) error) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			WithdrawRequests: NewWithdrawRequestRepository(tx),
+			Allocations:      NewAllocationRepository(tx),
+			Checkbooks:       NewCheckbookRepository(tx),
+		})
+	})
+}
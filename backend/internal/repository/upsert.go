@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Upsert inserts record, or when a row with the same conflictColumns already exists sets its updateColumns
+// to record's values instead (INSERT ... ON CONFLICT DO UPDATE), in one statement so concurrent writers cannot
+// race into a duplicate-key error. conflictColumns must be covered by a unique index. record's primary key is
+// filled in either case. With no updateColumns the existing row is left untouched (see CreateIfAbsent).
+func Upsert[T any](db *gorm.DB, conflictColumns []string, record *T, updateColumns []string) error {
+	if len(updateColumns) == 0 {
+		_, err := CreateIfAbsent(db, conflictColumns, record)
+		return err
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   onConflictColumns(conflictColumns),
+		DoUpdates: clause.AssignmentColumns(updateColumns),
+	}).Create(record).Error
+}
+
+// CreateIfAbsent inserts record unless a row with the same conflictColumns already exists (ON CONFLICT DO NOTHING)
+// created is false when the row existed; record is then not filled from it. Unlike a plain Create, the conflict
+// does not abort a surrounding transaction.
+func CreateIfAbsent[T any](db *gorm.DB, conflictColumns []string, record *T) (created bool, err error) {
+	result := db.Clauses(clause.OnConflict{
+		Columns:   onConflictColumns(conflictColumns),
+		DoNothing: true,
+	}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// onConflictColumns converts column names to an ON CONFLICT target
+func onConflictColumns(names []string) []clause.Column {
+	columns := make([]clause.Column, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, clause.Column{Name: name})
+	}
+	return columns
+}
+
+// EventLogConflictColumns unique key of the chain event tables: one row per emitted log
+var EventLogConflictColumns = []string{"chain_id", "transaction_hash", "log_index"}
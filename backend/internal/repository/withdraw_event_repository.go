@@ -14,6 +14,7 @@ type WithdrawEventRepository interface {
 	GetWithdrawRequestedByID(ctx context.Context, id uint64) (*models.EventWithdrawRequested, error)
 	FindWithdrawRequestedByRecipient(ctx context.Context, recipientChainID uint32, recipientData string, page, limit int) ([]*models.EventWithdrawRequested, int64, error)
 	FindWithdrawRequestedByTxHash(ctx context.Context, chainID int64, txHash string) ([]*models.EventWithdrawRequested, error)
+	FindWithdrawRequestedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventWithdrawRequested, int64, error)
 
 	// WithdrawExecuted operations
 	CreateWithdrawExecuted(ctx context.Context, event *models.EventWithdrawExecuted) error
@@ -21,6 +22,7 @@ type WithdrawEventRepository interface {
 	FindWithdrawExecutedByNullifier(ctx context.Context, nullifier string) (*models.EventWithdrawExecuted, error)
 	FindWithdrawExecutedByRecipient(ctx context.Context, recipientChainID uint32, recipientData string, page, limit int) ([]*models.EventWithdrawExecuted, int64, error)
 	FindWithdrawExecutedByTxHash(ctx context.Context, chainID int64, txHash string) ([]*models.EventWithdrawExecuted, error)
+	FindWithdrawExecutedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventWithdrawExecuted, int64, error)
 }
 
 // withdrawEventRepository implements WithdrawEventRepository
@@ -126,3 +128,22 @@ func (r *withdrawEventRepository) FindWithdrawExecutedByTxHash(ctx context.Conte
 	return events, nil
 }
 
+// FindWithdrawRequestedInRange lists WithdrawRequested events of a chain within a block range
+func (r *withdrawEventRepository) FindWithdrawRequestedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventWithdrawRequested, int64, error) {
+	var events []*models.EventWithdrawRequested
+	total, err := findEventsInRange(ctx, r.db, &models.EventWithdrawRequested{}, &events, blockRange)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// FindWithdrawExecutedInRange lists WithdrawExecuted events of a chain within a block range
+func (r *withdrawEventRepository) FindWithdrawExecutedInRange(ctx context.Context, blockRange BlockRange) ([]*models.EventWithdrawExecuted, int64, error) {
+	var events []*models.EventWithdrawExecuted
+	total, err := findEventsInRange(ctx, r.db, &models.EventWithdrawExecuted{}, &events, blockRange)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"time"
 
 	"go-backend/internal/models"
 
@@ -16,18 +20,33 @@ type WithdrawRequestRepository interface {
 	Create(ctx context.Context, request *models.WithdrawRequest) error
 	GetByID(ctx context.Context, id string) (*models.WithdrawRequest, error)
 	GetByNullifier(ctx context.Context, nullifier string) (*models.WithdrawRequest, error)
+	GetByIdempotencyKey(ctx context.Context, ownerChainID uint32, ownerData, key string) (*models.WithdrawRequest, error)
 	GetByPayoutTxHash(ctx context.Context, txHash string) (*models.WithdrawRequest, error)
+	GetByExecuteTxHash(ctx context.Context, txHash string) (*models.WithdrawRequest, error)
+	GetByIntentManagerTxHash(ctx context.Context, txHash string) (*models.WithdrawRequest, error)
 	Update(ctx context.Context, request *models.WithdrawRequest) error
+	Modify(ctx context.Context, id string, fn func(request *models.WithdrawRequest) error) (*models.WithdrawRequest, error)
 	Delete(ctx context.Context, id string) error
+	FindDeleted(ctx context.Context, nullifier string, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
 
 	// Query methods
 	FindByOwner(ctx context.Context, ownerChainID uint32, ownerData string, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
 	FindByBeneficiary(ctx context.Context, beneficiaryChainID uint32, beneficiaryData string, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
+	FindByOwnerOrBeneficiary(ctx context.Context, chainID uint32, data string, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
 	FindByStatus(ctx context.Context, status string) ([]*models.WithdrawRequest, error)
 	FindByProofStatus(ctx context.Context, status models.ProofStatus) ([]*models.WithdrawRequest, error)
 	FindByExecuteStatus(ctx context.Context, status models.ExecuteStatus) ([]*models.WithdrawRequest, error)
 	FindByPayoutStatus(ctx context.Context, status models.PayoutStatus) ([]*models.WithdrawRequest, error)
+	FindByProofStatusPaged(ctx context.Context, status models.ProofStatus, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
+	FindByExecuteStatusPaged(ctx context.Context, status models.ExecuteStatus, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
+	FindByPayoutStatusPaged(ctx context.Context, status models.PayoutStatus, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
+	FindByStatuses(ctx context.Context, filter StatusFilter) ([]*models.WithdrawRequest, int64, error)
+	FindByStatusesAfter(ctx context.Context, filter StatusFilter, afterID string, limit int) ([]*models.WithdrawRequest, error) // keyset page by id
 	FindByHookStatus(ctx context.Context, status models.HookStatus) ([]*models.WithdrawRequest, error)
+	FindStuck(ctx context.Context, status string, olderThan time.Duration) ([]*models.WithdrawRequest, error)
+	FindExpired(ctx context.Context, now time.Time, limit int) ([]*models.WithdrawRequest, error)
+	FindRecipientMismatches(ctx context.Context, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
+	FindNeedsManualReview(ctx context.Context, page, pageSize int) ([]*models.WithdrawRequest, int64, error)
 	CountByOwner(ctx context.Context, ownerChainID uint32, ownerData string) (int64, error)
 	CountByBeneficiary(ctx context.Context, beneficiaryChainID uint32, beneficiaryData string) (int64, error)
 	CountByStatus(ctx context.Context, ownerChainID uint32, ownerData string, status string) (int64, error)
@@ -45,6 +64,27 @@ type WithdrawRequestRepository interface {
 
 	// Update withdraw nullifier (used when proof is generated and public_values first nullifier differs)
 	UpdateWithdrawNullifier(ctx context.Context, id string, nullifier string) error
+	UpdateNullifierMismatch(ctx context.Context, id string, mismatch bool) error
+
+	// Record IntentManager transaction hash (tracked separately from payout_tx_hash)
+	UpdateIntentManagerTxHash(ctx context.Context, id string, txHash string) error
+
+	// Record the actual gas cost of the executeWithdraw transaction from its receipt
+	UpdateExecuteGasCost(ctx context.Context, id string, gasUsed uint64, gasPrice string) error
+	UpdateHookCalldata(ctx context.Context, id string, calldata string) error
+
+	// Append a payout / hook / fallback retry or failure to retry_history
+	AppendRetryHistory(ctx context.Context, id string, entry models.RetryHistoryEntry) error
+}
+
+// StatusFilter filters withdraw requests by sub-statuses, nil fields are not filtered (all set fields must match)
+// PageSize <= 0 returns all matching requests
+type StatusFilter struct {
+	ProofStatus   *models.ProofStatus
+	ExecuteStatus *models.ExecuteStatus
+	PayoutStatus  *models.PayoutStatus
+	Page          int
+	PageSize      int
 }
 
 // withdrawRequestRepository implements WithdrawRequestRepository
@@ -86,6 +126,19 @@ func (r *withdrawRequestRepository) GetByNullifier(ctx context.Context, nullifie
 	return &request, nil
 }
 
+// GetByIdempotencyKey retrieves the non-deleted withdraw request an owner created with a client idempotency key
+// Keys are scoped to the owner: different owners may use the same key.
+func (r *withdrawRequestRepository) GetByIdempotencyKey(ctx context.Context, ownerChainID uint32, ownerData, key string) (*models.WithdrawRequest, error) {
+	var request models.WithdrawRequest
+	err := r.db.WithContext(ctx).
+		Where("owner_chain_id = ? AND owner_data = ? AND idempotency_key = ?", ownerChainID, ownerData, key).
+		First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
 // GetByPayoutTxHash retrieves a withdraw request by payout transaction hash
 // Note: There might be multiple requests with the same payout_tx_hash, so this returns the first one found
 // In practice, each payout should have a unique txHash
@@ -98,16 +151,109 @@ func (r *withdrawRequestRepository) GetByPayoutTxHash(ctx context.Context, txHas
 	return &request, nil
 }
 
+// GetByExecuteTxHash retrieves a withdraw request by executeWithdraw transaction hash
+func (r *withdrawRequestRepository) GetByExecuteTxHash(ctx context.Context, txHash string) (*models.WithdrawRequest, error) {
+	var request models.WithdrawRequest
+	err := r.db.WithContext(ctx).Where("execute_tx_hash = ?", txHash).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetByIntentManagerTxHash retrieves a withdraw request by IntentManager transaction hash
+func (r *withdrawRequestRepository) GetByIntentManagerTxHash(ctx context.Context, txHash string) (*models.WithdrawRequest, error) {
+	var request models.WithdrawRequest
+	err := r.db.WithContext(ctx).Where("intent_manager_tx_hash = ?", txHash).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
 // Update updates a withdraw request
+// Returns models.ErrInvalidSubStatuses (nothing is written) for an impossible sub-status combination and
+// ErrVersionConflict if the request was modified since it was read; on success request.Version is advanced.
 func (r *withdrawRequestRepository) Update(ctx context.Context, request *models.WithdrawRequest) error {
-	return r.db.WithContext(ctx).Save(request).Error
+	if err := request.ValidateSubStatuses(); err != nil {
+		log.Printf("❌ [WithdrawRequestRepository] Refusing to save withdraw request %s: %v", request.ID, err)
+		return err
+	}
+
+	version := request.Version
+	result := r.db.WithContext(ctx).Model(request).
+		Where("version = ?", version).
+		Select("*").
+		Updates(request)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: withdraw request %s, version=%d", ErrVersionConflict, request.ID, version)
+	}
+	request.Version = version + 1
+	return nil
 }
 
-// Delete deletes a withdraw request
+// Modify loads a withdraw request, applies fn and saves it, reloading and retrying on version conflict
+// fn may run several times and must only mutate the request it is given; an error from fn aborts without saving.
+// Status changes made by fn are recorded as status transitions (see WithTransitionTrigger).
+func (r *withdrawRequestRepository) Modify(ctx context.Context, id string, fn func(request *models.WithdrawRequest) error) (*models.WithdrawRequest, error) {
+	var err error
+	for attempt := 1; attempt <= MaxVersionConflictRetries; attempt++ {
+		var request *models.WithdrawRequest
+		request, err = r.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		before := statusesOf(request)
+		if err = fn(request); err != nil {
+			return nil, err
+		}
+		if err = r.Update(ctx, request); err == nil {
+			recordWithdrawStatusTransitions(ctx, r.db, id, before, statusesOf(request), transitionTrigger(ctx, "Modify"))
+			return request, nil
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+		log.Printf("⚠️ [WithdrawRequestRepository] Version conflict updating %s (attempt %d/%d), retrying", id, attempt, MaxVersionConflictRetries)
+	}
+	return nil, err
+}
+
+// Delete soft-deletes a withdraw request (sets deleted_at)
+// Deleted requests are ignored by all other queries, so a new request can reuse the nullifier; see FindDeleted.
 func (r *withdrawRequestRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.WithdrawRequest{}).Error
 }
 
+// FindDeleted finds soft-deleted withdraw requests with pagination, newest deletion first (audits)
+// An empty nullifier returns all deleted requests. Pages start at 1 (smaller values are treated as 1) and
+// pageSize <= 0 returns all of them, like FindByStatuses.
+func (r *withdrawRequestRepository) FindDeleted(ctx context.Context, nullifier string, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	var requests []*models.WithdrawRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).Unscoped().Model(&models.WithdrawRequest{}).Where("deleted_at IS NOT NULL")
+	if nullifier != "" {
+		query = query.Where("withdraw_nullifier = ?", nullifier)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("deleted_at DESC")
+	if pageSize > 0 {
+		if page < 1 {
+			page = 1
+		}
+		query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	err := query.Find(&requests).Error
+	return requests, total, err
+}
+
 // FindByOwner finds withdraw requests by owner with pagination
 func (r *withdrawRequestRepository) FindByOwner(ctx context.Context, ownerChainID uint32, ownerData string, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
 	var requests []*models.WithdrawRequest
@@ -156,6 +302,35 @@ func (r *withdrawRequestRepository) FindByBeneficiary(ctx context.Context, benef
 	return requests, total, err
 }
 
+// FindByOwnerOrBeneficiary finds withdraw requests where the address is the owner or the beneficiary, with pagination
+// Both roles are matched on the same row with one OR, so a request where the user is both appears once
+// and the total counts it once.
+func (r *withdrawRequestRepository) FindByOwnerOrBeneficiary(ctx context.Context, chainID uint32, data string, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	var requests []*models.WithdrawRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.WithdrawRequest{}).
+		Where("(owner_chain_id = ? AND owner_data = ?) OR (recipient_chain_id = ? AND recipient_data = ?)",
+			chainID, data, chainID, data)
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	offset := (page - 1) * pageSize
+	err := query.
+		Offset(offset).
+		Limit(pageSize).
+		Order("created_at DESC").
+		Order("id").
+		Find(&requests).Error
+
+	return requests, total, err
+}
+
 // FindByStatus finds withdraw requests by status
 func (r *withdrawRequestRepository) FindByStatus(ctx context.Context, status string) ([]*models.WithdrawRequest, error) {
 	var requests []*models.WithdrawRequest
@@ -166,6 +341,30 @@ func (r *withdrawRequestRepository) FindByStatus(ctx context.Context, status str
 	return requests, err
 }
 
+// terminalWithdrawStatuses lists models.TerminalWithdrawStatuses, the main statuses never considered stuck
+func terminalWithdrawStatuses() []string {
+	statuses := make([]string, 0, len(models.TerminalWithdrawStatuses))
+	for status := range models.TerminalWithdrawStatuses {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// FindStuck finds non-terminal withdraw requests not updated for longer than olderThan
+// If status is empty, all non-terminal statuses are included
+func (r *withdrawRequestRepository) FindStuck(ctx context.Context, status string, olderThan time.Duration) ([]*models.WithdrawRequest, error) {
+	var requests []*models.WithdrawRequest
+	query := r.db.WithContext(ctx).
+		Where("status NOT IN ?", terminalWithdrawStatuses()).
+		Where("updated_at < ?", time.Now().Add(-olderThan))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("updated_at ASC").Find(&requests).Error
+	return requests, err
+}
+
 // CountByOwner counts withdraw requests by owner
 func (r *withdrawRequestRepository) CountByOwner(ctx context.Context, ownerChainID uint32, ownerData string) (int64, error) {
 	var count int64
@@ -197,19 +396,55 @@ func (r *withdrawRequestRepository) CountByStatus(ctx context.Context, ownerChai
 }
 
 // UpdateStatus updates the status of a withdraw request by ID
+// The update is applied to the current row through Modify, so it is version-checked and the merged sub-statuses validated.
 func (r *withdrawRequestRepository) UpdateStatus(ctx context.Context, id, status string) error {
-	return r.db.WithContext(ctx).
-		Model(&models.WithdrawRequest{}).
-		Where("id = ?", id).
-		Update("status", status).Error
+	_, err := r.Modify(WithTransitionTrigger(ctx, "UpdateStatus"), id, func(request *models.WithdrawRequest) error {
+		request.Status = status
+		return nil
+	})
+	return err
 }
 
-// UpdateStatusByNullifier updates the status of a withdraw request by nullifier
+// UpdateStatusByNullifier updates the status of the withdraw requests with the given nullifier
 func (r *withdrawRequestRepository) UpdateStatusByNullifier(ctx context.Context, nullifier, status string) error {
+	var ids []string
+	if err := r.db.WithContext(ctx).Model(&models.WithdrawRequest{}).
+		Where("withdraw_nullifier = ?", nullifier).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	ctx = WithTransitionTrigger(ctx, "UpdateStatusByNullifier")
+	for _, id := range ids {
+		if _, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+			request.Status = status
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindExpired finds requests past expires_at that never started executing (see WithdrawRequest.IsExpired), oldest expiry first
+func (r *withdrawRequestRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*models.WithdrawRequest, error) {
+	var requests []*models.WithdrawRequest
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at < ?", now).
+		Where("execute_status = ? AND proof_status IN ?", models.ExecuteStatusPending,
+			[]models.ProofStatus{models.ProofStatusPending, models.ProofStatusFailed}).
+		Where("status NOT IN ?", []string{string(models.WithdrawStatusCancelled),
+			string(models.WithdrawStatusManuallyResolved), string(models.WithdrawStatusFailedPermanent)}).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&requests).Error
+	return requests, err
+}
+
+// UpdateNullifierMismatch records whether the last generated proof's nullifiers differed from the DB
+func (r *withdrawRequestRepository) UpdateNullifierMismatch(ctx context.Context, id string, mismatch bool) error {
 	return r.db.WithContext(ctx).
 		Model(&models.WithdrawRequest{}).
-		Where("withdraw_nullifier = ?", nullifier).
-		Update("status", status).Error
+		Where("id = ?", id).
+		Update("nullifier_mismatch", mismatch).Error
 }
 
 // UpdateWithdrawNullifier updates the withdraw_nullifier field (used when proof is generated and public_values first nullifier differs)
@@ -245,6 +480,36 @@ func (r *withdrawRequestRepository) FindByProofStatus(ctx context.Context, statu
 	return requests, err
 }
 
+// FindRecipientMismatches finds withdraw requests whose on-chain recipient differed from the stored recipient
+func (r *withdrawRequestRepository) FindRecipientMismatches(ctx context.Context, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	var requests []*models.WithdrawRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WithdrawRequest{}).Where("recipient_mismatch = ?", true)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&requests).Error
+	return requests, total, err
+}
+
+// FindNeedsManualReview finds withdraw requests whose payout was flagged for manual review
+func (r *withdrawRequestRepository) FindNeedsManualReview(ctx context.Context, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	var requests []*models.WithdrawRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WithdrawRequest{}).Where("payout_needs_manual_review = ?", true)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&requests).Error
+	return requests, total, err
+}
+
 // FindByExecuteStatus finds withdraw requests by execute status
 func (r *withdrawRequestRepository) FindByExecuteStatus(ctx context.Context, status models.ExecuteStatus) ([]*models.WithdrawRequest, error) {
 	var requests []*models.WithdrawRequest
@@ -265,6 +530,72 @@ func (r *withdrawRequestRepository) FindByPayoutStatus(ctx context.Context, stat
 	return requests, err
 }
 
+// FindByProofStatusPaged finds withdraw requests by proof status with pagination
+func (r *withdrawRequestRepository) FindByProofStatusPaged(ctx context.Context, status models.ProofStatus, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	return r.FindByStatuses(ctx, StatusFilter{ProofStatus: &status, Page: page, PageSize: pageSize})
+}
+
+// FindByExecuteStatusPaged finds withdraw requests by execute status with pagination
+func (r *withdrawRequestRepository) FindByExecuteStatusPaged(ctx context.Context, status models.ExecuteStatus, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	return r.FindByStatuses(ctx, StatusFilter{ExecuteStatus: &status, Page: page, PageSize: pageSize})
+}
+
+// FindByPayoutStatusPaged finds withdraw requests by payout status with pagination
+func (r *withdrawRequestRepository) FindByPayoutStatusPaged(ctx context.Context, status models.PayoutStatus, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	return r.FindByStatuses(ctx, StatusFilter{PayoutStatus: &status, Page: page, PageSize: pageSize})
+}
+
+// FindByStatuses finds withdraw requests matching all set statuses in the filter, returns the page and the total count
+// Ordered by created_at DESC, id so pages are stable
+func (r *withdrawRequestRepository) FindByStatuses(ctx context.Context, filter StatusFilter) ([]*models.WithdrawRequest, int64, error) {
+	var requests []*models.WithdrawRequest
+	var total int64
+
+	query := applyStatusFilter(r.db.WithContext(ctx).Model(&models.WithdrawRequest{}), filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC").Order("id")
+	if filter.PageSize > 0 {
+		page := filter.Page
+		if page < 1 {
+			page = 1
+		}
+		query = query.Offset((page - 1) * filter.PageSize).Limit(filter.PageSize)
+	}
+
+	err := query.Find(&requests).Error
+	return requests, total, err
+}
+
+// FindByStatusesAfter returns up to limit requests matching the filter's statuses with id > afterID, ordered by id
+// Keyset paging: unlike offsets, pages do not shift when earlier rows change status while the caller walks them.
+// Page and PageSize of the filter are ignored; an empty afterID starts from the beginning.
+func (r *withdrawRequestRepository) FindByStatusesAfter(ctx context.Context, filter StatusFilter, afterID string, limit int) ([]*models.WithdrawRequest, error) {
+	var requests []*models.WithdrawRequest
+	query := applyStatusFilter(r.db.WithContext(ctx).Model(&models.WithdrawRequest{}), filter)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	err := query.Order("id").Limit(limit).Find(&requests).Error
+	return requests, err
+}
+
+// applyStatusFilter adds a condition for each status set in the filter
+func applyStatusFilter(query *gorm.DB, filter StatusFilter) *gorm.DB {
+	if filter.ProofStatus != nil {
+		query = query.Where("proof_status = ?", *filter.ProofStatus)
+	}
+	if filter.ExecuteStatus != nil {
+		query = query.Where("execute_status = ?", *filter.ExecuteStatus)
+	}
+	if filter.PayoutStatus != nil {
+		query = query.Where("payout_status = ?", *filter.PayoutStatus)
+	}
+	return query
+}
+
 // FindByHookStatus finds withdraw requests by hook status
 func (r *withdrawRequestRepository) FindByHookStatus(ctx context.Context, status models.HookStatus) ([]*models.WithdrawRequest, error) {
 	var requests []*models.WithdrawRequest
@@ -276,154 +607,112 @@ func (r *withdrawRequestRepository) FindByHookStatus(ctx context.Context, status
 }
 
 // UpdateProofStatus updates proof generation status (Stage 1)
-// Uses GORM Updates method to update only specified fields
+// Applied to the current row through Modify: version-checked, and refused with models.ErrInvalidSubStatuses if the
+// merged sub-statuses are impossible.
 func (r *withdrawRequestRepository) UpdateProofStatus(ctx context.Context, id string, status models.ProofStatus, proof string, publicValues string, err string) error {
-	updates := map[string]interface{}{
-		"proof_status": status,
-	}
-
-	if status == models.ProofStatusCompleted {
-		updates["proof"] = proof
-		updates["public_values"] = publicValues
-		updates["proof_generated_at"] = gorm.Expr("NOW()")
-	} else if status == models.ProofStatusFailed {
-		updates["proof_error"] = err
-	}
-
-	result := r.db.WithContext(ctx).
-		Model(&models.WithdrawRequest{}).
-		Where("id = ?", id).
-		Updates(updates)
-
-	if result.Error != nil {
-		log.Printf("❌ [UpdateProofStatus] Database error for request %s: %v", id, result.Error)
-		return fmt.Errorf("failed to update proof status: %w", result.Error)
-	}
-
-	if result.RowsAffected == 0 {
-		log.Printf("⚠️ [UpdateProofStatus] No rows updated for request %s (status: %s). Request may not exist or already has this status.", id, status)
-		return fmt.Errorf("no rows updated for request %s", id)
+	_, modifyErr := r.Modify(WithTransitionTrigger(ctx, "UpdateProofStatus"), id, func(request *models.WithdrawRequest) error {
+		request.ProofStatus = status
+		if status == models.ProofStatusCompleted {
+			now := time.Now()
+			request.Proof = proof
+			request.PublicValues = publicValues
+			request.ProofGeneratedAt = &now
+		} else if status == models.ProofStatusFailed {
+			request.ProofError = err
+		}
+		return nil
+	})
+	if modifyErr != nil {
+		log.Printf("❌ [UpdateProofStatus] Failed to update request %s to proof_status=%s: %v", id, status, modifyErr)
+		return fmt.Errorf("failed to update proof status: %w", modifyErr)
 	}
 
-	log.Printf("✅ [UpdateProofStatus] Updated request %s: proof_status=%s, rowsAffected=%d", id, status, result.RowsAffected)
+	log.Printf("✅ [UpdateProofStatus] Updated request %s: proof_status=%s", id, status)
 	return nil
 }
 
 // UpdateExecuteStatus updates on-chain verification status (Stage 2)
-// Note: This method is used by services that don't need row-level locking.
-// For concurrent-safe updates, use transaction with FOR UPDATE in the service layer.
+// Applied to the current row through Modify (version-checked, merged sub-statuses validated).
+// A request already in a final execute status is left unchanged and nil is returned.
 func (r *withdrawRequestRepository) UpdateExecuteStatus(ctx context.Context, id string, status models.ExecuteStatus, txHash string, blockNumber *uint64, err string) error {
-	// First check if already in final status to avoid unnecessary updates
-	var existing models.WithdrawRequest
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&existing).Error; err == nil {
-		// Check if already in a final status
-		if existing.ExecuteStatus == models.ExecuteStatusSuccess ||
-			existing.ExecuteStatus == models.ExecuteStatusVerifyFailed ||
-			existing.ExecuteStatus == models.ExecuteStatusSubmitFailed {
-			// Already in final status, skip update to avoid conflicts
-			log.Printf("⚠️ [UpdateExecuteStatus] Request %s already in final status: %s, skipping update", id, existing.ExecuteStatus)
-			return nil
+	_, modifyErr := r.Modify(WithTransitionTrigger(ctx, "UpdateExecuteStatus"), id, func(request *models.WithdrawRequest) error {
+		switch request.ExecuteStatus {
+		case models.ExecuteStatusSuccess, models.ExecuteStatusVerifyFailed, models.ExecuteStatusSubmitFailed:
+			return errAlreadyFinal
 		}
-	}
-
-	updates := map[string]interface{}{
-		"execute_status": status,
-	}
-
-	if txHash != "" {
-		updates["execute_tx_hash"] = txHash
-	}
-
-	if status == models.ExecuteStatusSuccess {
-		updates["executed_at"] = gorm.Expr("NOW()")
-		if blockNumber != nil {
-			updates["execute_block_number"] = *blockNumber
+		request.ExecuteStatus = status
+		if txHash != "" {
+			request.ExecuteTxHash = txHash
 		}
-	} else if status == models.ExecuteStatusSubmitFailed {
-		updates["execute_error"] = err
-	}
-
-	// Use WHERE clause to only update if not already in final status (optimistic locking)
-	result := r.db.WithContext(ctx).
-		Model(&models.WithdrawRequest{}).
-		Where("id = ? AND execute_status NOT IN ?", id, []models.ExecuteStatus{
-			models.ExecuteStatusSuccess,
-			models.ExecuteStatusVerifyFailed,
-			models.ExecuteStatusSubmitFailed,
-		}).
-		Updates(updates)
-
-	if result.Error != nil {
-		return fmt.Errorf("failed to update execute status: %w", result.Error)
+		if status == models.ExecuteStatusSuccess {
+			now := time.Now()
+			request.ExecutedAt = &now
+			if blockNumber != nil {
+				request.ExecuteBlockNumber = blockNumber
+			}
+		} else if status == models.ExecuteStatusSubmitFailed {
+			request.ExecuteError = err
+		}
+		return nil
+	})
+	if errors.Is(modifyErr, errAlreadyFinal) {
+		log.Printf("⚠️ [UpdateExecuteStatus] Request %s already in a final execute status, skipping update", id)
+		return nil
 	}
-
-	if result.RowsAffected == 0 {
-		// No rows updated - likely already in final status or doesn't exist
-		log.Printf("⚠️ [UpdateExecuteStatus] No rows updated for request %s (may already be in final status)", id)
-		return nil // Don't return error - this is expected if already updated
+	if modifyErr != nil {
+		return fmt.Errorf("failed to update execute status: %w", modifyErr)
 	}
 
-	log.Printf("✅ [UpdateExecuteStatus] Updated request %s: execute_status=%s, txHash=%s, rowsAffected=%d", id, status, txHash, result.RowsAffected)
+	log.Printf("✅ [UpdateExecuteStatus] Updated request %s: execute_status=%s, txHash=%s", id, status, txHash)
 	return nil
 }
 
+// errAlreadyFinal aborts a Modify whose stage already reached a final status
+var errAlreadyFinal = errors.New("stage already in final status")
+
 // UpdatePayoutStatus updates Intent execution status (Stage 3)
+// Applied to the current row through Modify (version-checked, merged sub-statuses validated).
 func (r *withdrawRequestRepository) UpdatePayoutStatus(ctx context.Context, id string, status models.PayoutStatus, txHash string, blockNumber *uint64, err string) error {
-	updates := map[string]interface{}{
-		"payout_status": status,
-	}
-
-	if txHash != "" {
-		updates["payout_tx_hash"] = txHash
-	}
-
-	if status == models.PayoutStatusCompleted {
-		updates["payout_completed_at"] = gorm.Expr("NOW()")
-		if blockNumber != nil {
-			updates["payout_block_number"] = *blockNumber
+	_, modifyErr := r.Modify(WithTransitionTrigger(ctx, "UpdatePayoutStatus"), id, func(request *models.WithdrawRequest) error {
+		now := time.Now()
+		request.PayoutStatus = status
+		if txHash != "" {
+			request.PayoutTxHash = txHash
 		}
-	} else if status == models.PayoutStatusFailed {
-		updates["payout_error"] = err
-		updates["payout_last_retry_at"] = gorm.Expr("NOW()")
-		// Increment retry count
-		r.db.WithContext(ctx).
-			Model(&models.WithdrawRequest{}).
-			Where("id = ?", id).
-			UpdateColumn("payout_retry_count", gorm.Expr("payout_retry_count + 1"))
-	}
-
-	return r.db.WithContext(ctx).
-		Model(&models.WithdrawRequest{}).
-		Where("id = ?", id).
-		Updates(updates).Error
+		if status == models.PayoutStatusCompleted {
+			request.PayoutCompletedAt = &now
+			if blockNumber != nil {
+				request.PayoutBlockNumber = blockNumber
+			}
+		} else if status == models.PayoutStatusFailed {
+			request.PayoutError = err
+			request.PayoutLastRetryAt = &now
+			request.PayoutRetryCount++
+		}
+		return nil
+	})
+	return modifyErr
 }
 
 // UpdateHookStatus updates Hook purchase status (Stage 4)
+// Applied to the current row through Modify (version-checked, merged sub-statuses validated).
 func (r *withdrawRequestRepository) UpdateHookStatus(ctx context.Context, id string, status models.HookStatus, txHash string, err string) error {
-	updates := map[string]interface{}{
-		"hook_status": status,
-	}
-
-	if txHash != "" {
-		updates["hook_tx_hash"] = txHash
-	}
-
-	if status == models.HookStatusCompleted {
-		updates["hook_completed_at"] = gorm.Expr("NOW()")
-	} else if status == models.HookStatusFailed {
-		updates["hook_error"] = err
-		updates["hook_last_retry_at"] = gorm.Expr("NOW()")
-		// Increment retry count
-		r.db.WithContext(ctx).
-			Model(&models.WithdrawRequest{}).
-			Where("id = ?", id).
-			UpdateColumn("hook_retry_count", gorm.Expr("hook_retry_count + 1"))
-	}
-
-	return r.db.WithContext(ctx).
-		Model(&models.WithdrawRequest{}).
-		Where("id = ?", id).
-		Updates(updates).Error
+	_, modifyErr := r.Modify(WithTransitionTrigger(ctx, "UpdateHookStatus"), id, func(request *models.WithdrawRequest) error {
+		now := time.Now()
+		request.HookStatus = status
+		if txHash != "" {
+			request.HookTxHash = txHash
+		}
+		if status == models.HookStatusCompleted {
+			request.HookCompletedAt = &now
+		} else if status == models.HookStatusFailed {
+			request.HookError = err
+			request.HookLastRetryAt = &now
+			request.HookRetryCount++
+		}
+		return nil
+	})
+	return modifyErr
 }
 
 // UpdateFallbackStatus updates fallback transfer status
@@ -445,3 +734,55 @@ func (r *withdrawRequestRepository) UpdateFallbackStatus(ctx context.Context, id
 		Where("id = ?", id).
 		Updates(updates).Error
 }
+
+// UpdateIntentManagerTxHash records the IntentManager transaction hash for a withdraw request
+func (r *withdrawRequestRepository) UpdateIntentManagerTxHash(ctx context.Context, id string, txHash string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.WithdrawRequest{}).
+		Where("id = ?", id).
+		Update("intent_manager_tx_hash", txHash).Error
+}
+
+// UpdateExecuteGasCost records gas used and effective gas price (wei) of the executeWithdraw receipt
+// An empty gasPrice (not reported by the node) leaves the stored price unchanged.
+func (r *withdrawRequestRepository) UpdateExecuteGasCost(ctx context.Context, id string, gasUsed uint64, gasPrice string) error {
+	updates := map[string]interface{}{
+		"execute_gas_used": gasUsed,
+	}
+	if gasPrice != "" {
+		updates["execute_gas_price"] = gasPrice
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.WithdrawRequest{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// UpdateHookCalldata stores the Stage 4 hook calldata, e.g. when it is recovered from the on-chain event
+func (r *withdrawRequestRepository) UpdateHookCalldata(ctx context.Context, id string, calldata string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.WithdrawRequest{}).
+		Where("id = ?", id).
+		Update("hook_calldata", calldata).Error
+}
+
+// AppendRetryHistory appends entry to the request's retry_history
+func (r *withdrawRequestRepository) AppendRetryHistory(ctx context.Context, id string, entry models.RetryHistoryEntry) error {
+	return AppendWithdrawRetryHistory(r.db.WithContext(ctx), id, entry)
+}
+
+// AppendWithdrawRetryHistory appends entry to a withdraw request's retry_history in a single UPDATE
+// The append happens in SQL, so concurrent writers never lose each other's entries. The update goes through the
+// table rather than the model: gorm drops writes to retry_history, which the model marks read-only.
+func AppendWithdrawRetryHistory(db *gorm.DB, id string, entry models.RetryHistoryEntry) error {
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	encoded, err := json.Marshal([]models.RetryHistoryEntry{entry})
+	if err != nil {
+		return fmt.Errorf("failed to encode retry history entry: %w", err)
+	}
+	return db.Table("withdraw_requests").
+		Where("id = ?", id).
+		UpdateColumn("retry_history", gorm.Expr("COALESCE(retry_history, '[]'::jsonb) || ?::jsonb", string(encoded))).Error
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+)
+
+func TestFindByStatusesPaginationBoundaries(t *testing.T) {
+	executeStatus := models.ExecuteStatusSubmitFailed
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		wantSQL  string // suffix of the page query
+		wantArgs string // bound arguments of the page query
+	}{
+		{"first page", 1, 10, "ORDER BY created_at DESC,id LIMIT $2", "[submit_failed 10]"},
+		{"last full page", 3, 10, "ORDER BY created_at DESC,id LIMIT $2 OFFSET $3", "[submit_failed 10 20]"},
+		{"page zero is the first page", 0, 10, "ORDER BY created_at DESC,id LIMIT $2", "[submit_failed 10]"},
+		{"negative page is the first page", -2, 10, "ORDER BY created_at DESC,id LIMIT $2", "[submit_failed 10]"},
+		{"page size one", 5, 1, "ORDER BY created_at DESC,id LIMIT $2 OFFSET $3", "[submit_failed 1 4]"},
+		{"no page size returns everything", 3, 0, "ORDER BY created_at DESC,id", "[submit_failed]"},
+		{"negative page size returns everything", 1, -1, "ORDER BY created_at DESC,id", "[submit_failed]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, recorder := openRecording(t)
+			repo := NewWithdrawRequestRepository(database)
+
+			if _, _, err := repo.FindByStatuses(context.Background(), StatusFilter{
+				ExecuteStatus: &executeStatus, Page: tt.page, PageSize: tt.pageSize,
+			}); err != nil {
+				t.Fatalf("FindByStatuses: %v", err)
+			}
+			if query := recorder.last(); !strings.HasSuffix(query, tt.wantSQL) {
+				t.Errorf("query %q does not end with %q", query, tt.wantSQL)
+			}
+			if args := fmt.Sprint(recorder.lastArgs()); args != tt.wantArgs {
+				t.Errorf("args = %s, want %s", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFindByStatusesFiltersInSQL(t *testing.T) {
+	proof, execute, payout := models.ProofStatusCompleted, models.ExecuteStatusSuccess, models.PayoutStatusFailed
+	tests := []struct {
+		name      string
+		filter    StatusFilter
+		wantWhere string
+	}{
+		{"no filter", StatusFilter{}, ""},
+		{"proof only", StatusFilter{ProofStatus: &proof}, "WHERE proof_status = $1 AND"},
+		{"execute and payout", StatusFilter{ExecuteStatus: &execute, PayoutStatus: &payout}, "WHERE execute_status = $1 AND payout_status = $2 AND"},
+		{"all three", StatusFilter{ProofStatus: &proof, ExecuteStatus: &execute, PayoutStatus: &payout},
+			"WHERE proof_status = $1 AND execute_status = $2 AND payout_status = $3 AND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, recorder := openRecording(t)
+			repo := NewWithdrawRequestRepository(database)
+
+			if _, _, err := repo.FindByStatuses(context.Background(), tt.filter); err != nil {
+				t.Fatalf("FindByStatuses: %v", err)
+			}
+			// Both the count and the page query carry the same filter
+			for i, query := range recorder.statements {
+				if tt.wantWhere == "" && strings.Contains(query, "_status =") {
+					t.Errorf("statement %d %q filters on a status", i, query)
+				}
+				if tt.wantWhere != "" && !strings.Contains(query, tt.wantWhere) {
+					t.Errorf("statement %d %q does not contain %q", i, query, tt.wantWhere)
+				}
+			}
+			if len(recorder.statements) != 2 || !strings.HasPrefix(recorder.statements[0], "SELECT count(*)") {
+				t.Errorf("statements = %q, want a count and a page query", recorder.statements)
+			}
+		})
+	}
+}
+
+func TestFindByStatusesAfterUsesKeyset(t *testing.T) {
+	executeStatus := models.ExecuteStatusVerifyFailed
+	tests := []struct {
+		name     string
+		afterID  string
+		wantSQL  string
+		wantArgs string
+	}{
+		{"first page", "", "WHERE execute_status = $1 AND \"withdraw_requests\".\"deleted_at\" IS NULL ORDER BY id LIMIT $2", "[verify_failed 50]"},
+		{"next page", "wr-0042", "WHERE execute_status = $1 AND id > $2 AND \"withdraw_requests\".\"deleted_at\" IS NULL ORDER BY id LIMIT $3", "[verify_failed wr-0042 50]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, recorder := openRecording(t)
+			repo := NewWithdrawRequestRepository(database)
+
+			if _, err := repo.FindByStatusesAfter(context.Background(), StatusFilter{ExecuteStatus: &executeStatus}, tt.afterID, 50); err != nil {
+				t.Fatalf("FindByStatusesAfter: %v", err)
+			}
+			if len(recorder.statements) != 1 {
+				t.Fatalf("statements = %q, want only the page query", recorder.statements)
+			}
+			if query := recorder.last(); !strings.HasSuffix(query, tt.wantSQL) {
+				t.Errorf("query %q does not end with %q", query, tt.wantSQL)
+			}
+			if args := fmt.Sprint(recorder.lastArgs()); args != tt.wantArgs {
+				t.Errorf("args = %s, want %s", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFindByStatusesAfterVisitsEveryRowWhileRowsLeaveTheFilter(t *testing.T) {
+	database := dbtest.Open(t)
+	repo := NewWithdrawRequestRepository(database)
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		request := &models.WithdrawRequest{
+			ID:                fmt.Sprintf("wr-%d", i),
+			WithdrawNullifier: fmt.Sprintf("0x%d", i),
+			Status:            string(models.WithdrawStatusFailedPermanent),
+			ProofStatus:       models.ProofStatusCompleted,
+			ExecuteStatus:     models.ExecuteStatusVerifyFailed,
+		}
+		if err := database.Create(request).Error; err != nil {
+			t.Fatalf("create %s: %v", request.ID, err)
+		}
+	}
+
+	// Like batch-cancel-withdraw: rows read are moved out of the filter before the next page, except wr-2,
+	// whose cancellation failed and which still matches
+	executeStatus := models.ExecuteStatusVerifyFailed
+	filter := StatusFilter{ExecuteStatus: &executeStatus}
+	var visited []string
+	lastID := ""
+	for pages := 0; pages < 10; pages++ {
+		requests, err := repo.FindByStatusesAfter(ctx, filter, lastID, 2)
+		if err != nil {
+			t.Fatalf("FindByStatusesAfter(%q): %v", lastID, err)
+		}
+		for _, request := range requests {
+			visited = append(visited, request.ID)
+			lastID = request.ID
+			if request.ID == "wr-2" {
+				continue
+			}
+			if err := database.Model(&models.WithdrawRequest{}).Where("id = ?", request.ID).Updates(map[string]interface{}{
+				"status": models.WithdrawStatusCancelled, "execute_status": models.ExecuteStatusPending,
+			}).Error; err != nil {
+				t.Fatalf("move %s out of the filter: %v", request.ID, err)
+			}
+		}
+		if len(requests) < 2 {
+			break
+		}
+	}
+
+	if got := strings.Join(visited, ","); got != "wr-1,wr-2,wr-3,wr-4,wr-5" {
+		t.Errorf("visited %s, want every request once in id order", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"go-backend/internal/clients"
 	"go-backend/internal/config"
 	"go-backend/internal/handlers"
+	"go-backend/internal/logging"
 	"go-backend/internal/middleware"
 	"go-backend/internal/repository"
 	"go-backend/internal/services"
@@ -89,6 +90,7 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 		retryCheckbookRepo := repository.NewCheckbookRepository(db)
 		// Use service container's BlockchainTxService for consistency
 		retryCheckbookService := services.NewCheckbookService(retryCheckbookRepo, db, app.Container.UnifiedPollingService, pushService, app.Container.ZKVMClient, app.Container.BlockchainTxService)
+		retryCheckbookService.SetTronTransactionService(app.Container.TronTxService)
 		retryHandler := handlers.NewRetryHandler(db, app.Container.UnifiedPollingService, pushService, retryCheckbookService)
 		retry := api.Group("/retry")
 		retry.Use(authMiddleware.RequireAuth()) // need JWT
@@ -145,6 +147,9 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 		// ============ check ============
 		// GET /api/health
 		api.GET("/health", handlers.HealthCheckHandler)
+		// GET /api/health/ready - k8s readiness: DB, RPC clients and ZKVM
+		readinessHandler := newReadinessHandler(db)
+		api.GET("/health/ready", readinessHandler.ReadinessHandler)
 
 		// ============ Pool  ( - ) ============
 		// :  22 -> 13 ( 41%)
@@ -244,6 +249,14 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 
 			// Batch Operations
 			adminMetrics.POST("/metrics/batch", adminMetricsHandler.BatchUpdateMetricsHandler)
+
+			// Proof Generation Queue
+			var proofGenerationService *services.ProofGenerationService
+			if app.Container != nil {
+				proofGenerationService = app.Container.ProofGenerationService
+			}
+			proofQueueHandler := handlers.NewProofQueueHandler(proofGenerationService)
+			adminMetrics.GET("/proof-queue/stats", proofQueueHandler.GetQueueStatsHandler)
 		}
 
 		// ============ Pool  ( -  localhost) ============
@@ -308,7 +321,7 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 		allocationRepo := repository.NewAllocationRepository(db)
 		checkbookRepo := repository.NewCheckbookRepository(db)
 		queueRootRepo := repository.NewQueueRootRepository(db)
-		withdrawRequestService := services.NewWithdrawRequestService(withdrawRequestRepo, allocationRepo, checkbookRepo, queueRootRepo)
+		withdrawRequestService := services.NewWithdrawRequestService(withdrawRequestRepo, allocationRepo, checkbookRepo, queueRootRepo, repository.NewTransactor(db), logging.New(config.GetLogFormat()))
 
 		// Set up auto-triggering for proof generation (if services are available)
 		// Note: These are optional - if not set, auto-triggering will be disabled
@@ -362,6 +375,11 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 				logrus.Info("✅ [WithdrawRequest] Intent service created (not in ServiceContainer)")
 			}
 
+			// Set TronTxService from ServiceContainer (TRON submission path)
+			if app.Container.TronTxService != nil {
+				withdrawRequestService.SetTronTransactionService(app.Container.TronTxService)
+			}
+
 			// Set ProofGenerationService from ServiceContainer (shared instance)
 			if app.Container.ProofGenerationService != nil {
 				withdrawRequestService.SetProofGenerationService(app.Container.ProofGenerationService)
@@ -385,6 +403,15 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 			logrus.Warn("   → Auto-submission will be disabled")
 		}
 
+		// Register with the container so in-flight proof generation is drained on shutdown
+		if app.Container != nil {
+			app.Container.WithdrawRequestService = withdrawRequestService
+
+			// Cancel requests that never got a proof before their expires_at, releasing their allocations
+			app.Container.WithdrawExpiryService = services.NewWithdrawExpiryService(withdrawRequestRepo, withdrawRequestService)
+			app.Container.WithdrawExpiryService.Start()
+		}
+
 		withdrawRequestHandler := handlers.NewWithdrawRequestHandler(withdrawRequestRepo, withdrawRequestService)
 
 		// Intent System: Create withdraw request
@@ -393,6 +420,7 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 		withdraws.Use(authMiddleware.RequireAuth()) // need JWT
 		{
 			withdraws.POST("/submit", withdrawRequestHandler.CreateWithdrawRequestHandler)
+			withdraws.POST("/preview-commitments", withdrawRequestHandler.PreviewCommitmentGroupsHandler) // read-only, before signing
 		}
 
 		myWithdrawRequests := api.Group("/my/withdraw-requests")
@@ -401,17 +429,34 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 
 			myWithdrawRequests.GET("", withdrawRequestHandler.ListMyWithdrawRequestsHandler)
 			myWithdrawRequests.GET("/stats", withdrawRequestHandler.GetMyWithdrawStatsHandler)
+			myWithdrawRequests.GET("/withdrawable-balance", withdrawRequestHandler.GetMyWithdrawableBalanceHandler) // idle allocations per token
 			myWithdrawRequests.GET("/:id", withdrawRequestHandler.GetMyWithdrawRequestHandler)
 			myWithdrawRequests.GET("/by-nullifier/:nullifier", withdrawRequestHandler.GetMyWithdrawRequestByNullifierHandler) //  nullifier
 
 			// retry
-			myWithdrawRequests.POST("/:id/retry", withdrawRequestHandler.RetryWithdrawRequestHandler)   // retry
-			myWithdrawRequests.POST("/:id/retry-payout", withdrawRequestHandler.RetryPayoutHandler)     // retry payout
-			myWithdrawRequests.POST("/:id/retry-fallback", withdrawRequestHandler.RetryFallbackHandler) // retry fallback
+			myWithdrawRequests.POST("/:id/retry", withdrawRequestHandler.RetryWithdrawRequestHandler)      // retry
+			myWithdrawRequests.POST("/:id/retry-payout", withdrawRequestHandler.RetryPayoutHandler)        // retry payout
+			myWithdrawRequests.POST("/:id/retry-fallback", withdrawRequestHandler.RetryFallbackHandler)    // retry fallback
+			myWithdrawRequests.POST("/:id/signature", withdrawRequestHandler.ResignWithdrawRequestHandler) // re-sign after partial cancel
 
 			myWithdrawRequests.DELETE("/:id", withdrawRequestHandler.CancelWithdrawRequestHandler)
 		}
 
+		// Admin: WithdrawRequests flagged for investigation (recipient mismatch, payout manual review), retry history (localhost only)
+		if localhostOnly != nil {
+			adminWithdrawRequests := api.Group("/admin/withdraw-requests")
+			adminWithdrawRequests.Use(localhostOnly.Restrict())
+			{
+				adminWithdrawRequests.GET("/recipient-mismatches", withdrawRequestHandler.ListRecipientMismatchesHandler)
+				adminWithdrawRequests.GET("/payout-manual-review", withdrawRequestHandler.ListPayoutManualReviewsHandler)
+				adminWithdrawRequests.GET("/:id/retry-history", withdrawRequestHandler.GetRetryHistoryHandler)
+			}
+		}
+
+		// Admin: force a stuck WithdrawRequest into manually_resolved (admin authentication required)
+		withdrawAdminAuth := middleware.NewAdminAuthMiddleware(logrus.New())
+		api.POST("/admin/withdraw-requests/:id/manually-resolve", withdrawAdminAuth.RequireAdminAuth(), withdrawRequestHandler.ManuallyResolveHandler)
+
 		// ============  Beneficiary WithdrawRequest  (need) ============
 		myBeneficiaryRequests := api.Group("/my/beneficiary-withdraw-requests")
 		myBeneficiaryRequests.Use(authMiddleware.RequireAuth()) // need JWT
@@ -513,6 +558,34 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 		multisig.GET("/status", multisigHandler.GetSystemStatus)
 	}
 
+	// ============ Failed Transactions ============
+	// Browse and retry failed commitment/withdraw submissions (admin authentication required)
+	var failedTxRetryService *services.FailedTransactionRetryService
+	if app.Container != nil && app.Container.BlockchainTxService != nil {
+		failedTxRetryService = services.NewFailedTransactionRetryService(app.Container.BlockchainTxService, services.NewBlockScannerClient(config.GetScannerURL()))
+	}
+	failedTxHandler := handlers.NewFailedTransactionHandler(repository.NewFailedTransactionRepository(db), failedTxRetryService)
+	adminFailedTxs := api.Group("/admin/failed-transactions")
+	adminFailedTxs.Use(adminAuthMiddleware.RequireAdminAuth())
+	{
+		adminFailedTxs.GET("", failedTxHandler.ListFailedTransactionsHandler)
+		adminFailedTxs.POST("/:id/retry", failedTxHandler.RetryFailedTransactionHandler)
+	}
+
+	// ============ Events ============
+	// Raw stored events by chain and block range, for debugging and integrations (admin authentication required)
+	eventQueryHandler := handlers.NewEventQueryHandler(services.NewEventQueryService(
+		repository.NewDepositEventRepository(db),
+		repository.NewWithdrawEventRepository(db),
+		repository.NewQueueRootRepository(db),
+	))
+	api.GET("/admin/events", adminAuthMiddleware.RequireAdminAuth(), eventQueryHandler.ListEventsHandler)
+
+	// ============ Push Resend ============
+	// Re-broadcast an entity's current state after a missed WebSocket push (admin authentication required)
+	resendPushHandler := handlers.NewResendPushHandler(db, pushService)
+	api.POST("/admin/resend-push", adminAuthMiddleware.RequireAdminAuth(), resendPushHandler.ResendPushHandler)
+
 	// ============ WebSocket ============
 	// WebSocketconnection
 	// api.GET("/ws", ...) registers /api/ws (since api = r.Group("/api"))
@@ -587,3 +660,28 @@ func SetupZKPayRoutes(r *gin.Engine, db *gorm.DB, kmsHandler *handlers.KMSHandle
 		kytOracle.POST("/associate-address", oracleHandler.AssociateAddressWithCodeHandler)
 	}
 }
+
+// newReadinessHandler creates the readiness handler from the shared service container
+// Dependencies are only passed when set, so a missing service is reported instead of a typed nil
+func newReadinessHandler(db *gorm.DB) *handlers.ReadinessHandler {
+	var blockchainService *services.BlockchainTransactionService
+	var zkvmClient *clients.ZKVMClient
+	if app.Container != nil {
+		blockchainService = app.Container.BlockchainTxService
+		zkvmClient = app.Container.ZKVMClient
+	}
+	if zkvmClient == nil && config.AppConfig != nil && config.AppConfig.ZKVM.BaseURL != "" {
+		zkvmClient = clients.NewZKVMClient(config.AppConfig.ZKVM.BaseURL)
+	}
+
+	if blockchainService == nil && zkvmClient == nil {
+		return handlers.NewReadinessHandler(db, nil, nil)
+	}
+	if blockchainService == nil {
+		return handlers.NewReadinessHandler(db, nil, zkvmClient)
+	}
+	if zkvmClient == nil {
+		return handlers.NewReadinessHandler(db, blockchainService, nil)
+	}
+	return handlers.NewReadinessHandler(db, blockchainService, zkvmClient)
+}
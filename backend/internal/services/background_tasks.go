@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// backgroundTaskCancelGrace time given to tasks to return after their context is cancelled on shutdown timeout
+const backgroundTaskCancelGrace = 5 * time.Second
+
+// BackgroundTasks tracks background goroutines so they can be drained on shutdown
+// Tasks receive a context that is cancelled only if Shutdown times out.
+type BackgroundTasks struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewBackgroundTasks creates a task tracker
+func NewBackgroundTasks() *BackgroundTasks {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BackgroundTasks{ctx: ctx, cancel: cancel}
+}
+
+// Go starts fn in a tracked goroutine, returns false (and does not start fn) after Shutdown was called
+func (t *BackgroundTasks) Go(name string, fn func(ctx context.Context)) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		log.Printf("⚠️ [BackgroundTasks] Shutting down, not starting task: %s", name)
+		return false
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		fn(t.ctx)
+	}()
+	return true
+}
+
+// Shutdown stops accepting tasks and waits for running tasks to finish
+// If ctx expires first, running tasks are cancelled and given a short grace period to return; ctx.Err() is returned.
+func (t *BackgroundTasks) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.cancel()
+		log.Printf("✅ [BackgroundTasks] All background tasks finished")
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Printf("⚠️ [BackgroundTasks] Shutdown timed out, cancelling running tasks")
+	t.cancel()
+	select {
+	case <-done:
+	case <-time.After(backgroundTaskCancelGrace):
+		log.Printf("❌ [BackgroundTasks] Tasks did not return within %s after cancellation", backgroundTaskCancelGrace)
+	}
+	return ctx.Err()
+}
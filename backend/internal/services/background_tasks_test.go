@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+)
+
+func TestShutdownBlocksUntilTaskCompletes(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	if !tasks.Go("fake", func(ctx context.Context) {
+		<-release
+		close(finished)
+	}) {
+		t.Fatal("Go refused a task before Shutdown")
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- tasks.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v while the task was still running", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Errorf("Shutdown: %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return after the task finished")
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("Shutdown returned before the task finished")
+	}
+}
+
+func TestShutdownTimeoutCancelsRunningTasks(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	cancelled := make(chan struct{})
+	tasks.Go("fake", func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tasks.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown: %v, want context.DeadlineExceeded", err)
+	}
+	select {
+	case <-cancelled:
+	default:
+		t.Error("task context was not cancelled when Shutdown timed out")
+	}
+}
+
+func TestGoRefusesTasksAfterShutdown(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	if err := tasks.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if tasks.Go("late", func(ctx context.Context) { t.Error("task started after Shutdown") }) {
+		t.Error("Go accepted a task after Shutdown")
+	}
+}
+
+func TestReleaseInterruptedProofResetsToPending(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr-interrupted", "100")
+	store.requests["wr-interrupted"].ProofStatus = models.ProofStatusInProgress
+	store.addPendingRequest("wr-done", "100")
+	store.requests["wr-done"].WithdrawNullifier = "0xdone"
+	store.requests["wr-done"].ProofStatus = models.ProofStatusCompleted
+	service := newFakeWithdrawService(store)
+
+	service.releaseInterruptedProof("wr-interrupted")
+	service.releaseInterruptedProof("wr-done")
+
+	if got := store.request("wr-interrupted").ProofStatus; got != models.ProofStatusPending {
+		t.Errorf("interrupted request proof_status = %s, want pending", got)
+	}
+	if got := store.request("wr-done").ProofStatus; got != models.ProofStatusCompleted {
+		t.Errorf("finished request proof_status = %s, want completed (untouched)", got)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// clientHealthCheckTimeout timeout of the BlockNumber probe sent to each client
+const clientHealthCheckTimeout = 10 * time.Second
+
+// replacedClientCloseGrace how long a replaced client stays open: callers that looked it up before the swap
+// (a transaction send, a receipt wait) keep using it until then
+const replacedClientCloseGrace = 5 * time.Minute
+
+// closeReplacedClient closes a client swapped out by the health watcher once the grace period has passed
+var closeReplacedClient = func(client *ethclient.Client) {
+	time.AfterFunc(replacedClientCloseGrace, client.Close)
+}
+
+// StartHealthWatcher periodically probes every client with BlockNumber and reconnects dead ones
+// A failed client is re-dialed against the network's other RPCEndpoints, starting after the one it was connected to,
+// and replaced in the client map; the old client is closed after replacedClientCloseGrace, since other goroutines
+// may still hold it. Calling it while the watcher runs is a no-op.
+func (b *BlockchainTransactionService) StartHealthWatcher(interval time.Duration) {
+	b.clientsMu.Lock()
+	if b.healthStopCh != nil {
+		b.clientsMu.Unlock()
+		return
+	}
+	stopCh := make(chan struct{})
+	b.healthStopCh = stopCh
+	b.clientsMu.Unlock()
+
+	log.Printf("🚀 Starting RPC client health watcher (check interval: %v)", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				b.checkClientHealth()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// StopHealthWatcher stops the health watcher started by StartHealthWatcher
+func (b *BlockchainTransactionService) StopHealthWatcher() {
+	b.clientsMu.Lock()
+	defer b.clientsMu.Unlock()
+	if b.healthStopCh == nil {
+		return
+	}
+	close(b.healthStopCh)
+	b.healthStopCh = nil
+	log.Printf("🛑 RPC client health watcher stopped")
+}
+
+// GetClientHealth returns the last health check result of each chain (true = healthy)
+func (b *BlockchainTransactionService) GetClientHealth() map[int]bool {
+	b.clientsMu.RLock()
+	defer b.clientsMu.RUnlock()
+	health := make(map[int]bool, len(b.health))
+	for chainID, healthy := range b.health {
+		health[chainID] = healthy
+	}
+	return health
+}
+
+// checkClientHealth probes every client outside the lock and reconnects the ones that failed
+func (b *BlockchainTransactionService) checkClientHealth() {
+	b.clientsMu.RLock()
+	clients := make(map[int]*ethclient.Client, len(b.clients))
+	for chainID, client := range b.clients {
+		clients[chainID] = client
+	}
+	b.clientsMu.RUnlock()
+
+	for chainID, client := range clients {
+		ctx, cancel := context.WithTimeout(context.Background(), clientHealthCheckTimeout)
+		_, err := client.BlockNumber(ctx)
+		cancel()
+		if err == nil {
+			b.setClientHealth(chainID, true)
+			continue
+		}
+
+		log.Printf("⚠️ [ClientHealth] Chain %d client failed BlockNumber: %v, reconnecting", chainID, err)
+		b.setClientHealth(chainID, false)
+		b.reconnectClient(chainID, client)
+	}
+}
+
+// reconnectClient re-dials a chain's RPC endpoints and swaps the new client in for failed
+func (b *BlockchainTransactionService) reconnectClient(chainID int, failed *ethclient.Client) {
+	networkConfig, err := config.GetNetworkConfigByChainID(chainID)
+	if err != nil {
+		log.Printf("❌ [ClientHealth] Cannot reconnect chain %d: %v", chainID, err)
+		return
+	}
+
+	b.clientsMu.RLock()
+	current := b.endpoints[chainID]
+	b.clientsMu.RUnlock()
+
+	client, endpoint, err := dialRPCEndpoints(rotateEndpoints(networkConfig.RPCEndpoints, current))
+	if err != nil {
+		log.Printf("❌ [ClientHealth] All RPC endpoints failed for chain %d: %v", chainID, err)
+		return
+	}
+
+	b.clientsMu.Lock()
+	if b.clients[chainID] != failed {
+		// Replaced concurrently; keep the newer client
+		b.clientsMu.Unlock()
+		client.Close()
+		return
+	}
+	b.clients[chainID] = client
+	b.endpoints[chainID] = endpoint
+	b.health[chainID] = true
+	b.clientsMu.Unlock()
+
+	closeReplacedClient(failed)
+	log.Printf("✅ [ClientHealth] Chain %d reconnected to %s", chainID, endpoint)
+}
+
+// setClientHealth records the health check result of a chain
+func (b *BlockchainTransactionService) setClientHealth(chainID int, healthy bool) {
+	b.clientsMu.Lock()
+	defer b.clientsMu.Unlock()
+	b.health[chainID] = healthy
+}
+
+// rotateEndpoints orders endpoints to start right after current, wrapping around so current is tried last
+// Returns endpoints unchanged when current is not among them.
+func rotateEndpoints(endpoints []string, current string) []string {
+	for i, endpoint := range endpoints {
+		if endpoint == current {
+			rotated := make([]string, 0, len(endpoints))
+			rotated = append(rotated, endpoints[i+1:]...)
+			return append(rotated, endpoints[:i+1]...)
+		}
+	}
+	return endpoints
+}
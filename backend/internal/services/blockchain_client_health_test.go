@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// newStubRPCServer answers net_version and eth_blockNumber until failing is set, then returns HTTP 500
+func newStubRPCServer(t *testing.T, failing *atomic.Bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := `"0x10"`
+		if req.Method == "net_version" {
+			result = `"56"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHealthWatcherSwapsFailedClientWithoutClosingIt(t *testing.T) {
+	const chainID = 714
+	var primaryDown atomic.Bool
+	primary := newStubRPCServer(t, &primaryDown)
+	backup := newStubRPCServer(t, new(atomic.Bool))
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{Networks: map[string]config.NetworkConfig{
+		"bsc": {ChainID: chainID, Enabled: true, RPCEndpoints: []string{primary.URL, backup.URL}},
+	}}}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	var closed []*ethclient.Client
+	previousClose := closeReplacedClient
+	closeReplacedClient = func(client *ethclient.Client) { closed = append(closed, client) }
+	t.Cleanup(func() { closeReplacedClient = previousClose })
+
+	original, err := ethclient.Dial(primary.URL)
+	if err != nil {
+		t.Fatalf("dial primary: %v", err)
+	}
+	t.Cleanup(original.Close)
+	service := NewBlockchainTransactionService(nil)
+	service.setClient(chainID, original, primary.URL)
+
+	service.checkClientHealth()
+	if !service.GetClientHealth()[chainID] || len(closed) != 0 {
+		t.Fatalf("healthy client: health=%v replaced=%d, want healthy and untouched", service.GetClientHealth()[chainID], len(closed))
+	}
+
+	primaryDown.Store(true)
+	service.checkClientHealth()
+	client, _ := service.GetClient(chainID)
+	if client == original || service.endpoints[chainID] != backup.URL {
+		t.Fatalf("after failure client endpoint = %s, want swapped to the backup", service.endpoints[chainID])
+	}
+	if len(closed) != 1 || closed[0] != original {
+		t.Fatalf("replaced clients handed to closeReplacedClient = %d, want the failed one", len(closed))
+	}
+
+	// A caller that still holds the old client keeps working once its endpoint recovers
+	primaryDown.Store(false)
+	if _, err := original.BlockNumber(context.Background()); err != nil {
+		t.Errorf("replaced client unusable before its grace period ended: %v", err)
+	}
+	service.checkClientHealth()
+	if !service.GetClientHealth()[chainID] {
+		t.Error("chain not reported healthy after reconnecting")
+	}
+}
@@ -1,17 +1,25 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"regexp"
 	"strings"
 	"time"
 
 	"go-backend/internal/clients"
 	"go-backend/internal/config"
+	"go-backend/internal/logging"
 	"go-backend/internal/models"
+	"go-backend/internal/repository"
+	"go-backend/internal/types"
 	"go-backend/internal/utils"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -43,12 +51,28 @@ type BlockchainEventProcessor struct {
 	db               *gorm.DB
 	queueRootManager *QueueRootManager
 	pushService      *WebSocketPushService
-	dbWithPush       *DatabaseWithPushService // DatabaseUpdate+pushservice
-	decimalConverter *utils.DecimalConverter  // TokenConvert
+	dbWithPush       *DatabaseWithPushService            // DatabaseUpdate+pushservice
+	decimalConverter *utils.TokenAddressDecimalConverter // TokenConvert (by tokenId or token address)
+
+	statusTransitionRepo repository.StatusTransitionRepository // status change audit log
+	withdrawRequestRepo  repository.WithdrawRequestRepository  // WithdrawRequest lookups by TX hash
+	depositEventRepo     repository.DepositEventRepository     // DepositUsed lookups by commitment
+	checkbookRepo        repository.CheckbookRepository        // Checkbook lookups by commitment
+
+	matchByDeprecatedRequestID bool // also match withdraw events by the deprecated request_id (withdraw.match_by_deprecated_request_id)
+
+	decodeRecipient func(event *clients.EventWithdrawRequestedResponse) (uint16, string, error) // executeWithdraw recipient decoder, replaceable for tests
+
+	logger logging.Logger // structured logger (text or JSON)
 }
 
 // NewBlockchainEventProcessor Createblockchain event processor
-func NewBlockchainEventProcessor(db *gorm.DB, pushService *WebSocketPushService, dbWithPush *DatabaseWithPushService) *BlockchainEventProcessor {
+// logger may be nil, in which case the format from logging.format config is used
+func NewBlockchainEventProcessor(db *gorm.DB, pushService *WebSocketPushService, dbWithPush *DatabaseWithPushService, logger logging.Logger) *BlockchainEventProcessor {
+	if logger == nil {
+		logger = logging.New(config.GetLogFormat())
+	}
+
 	// CreateBlockScanner API client - Useconfigurationinterface
 	blockScannerURL := config.GetScannerURL()
 	blockScannerAPI := clients.NewBlockScannerAPIClient(blockScannerURL)
@@ -57,12 +81,7 @@ func NewBlockchainEventProcessor(db *gorm.DB, pushService *WebSocketPushService,
 	queueRootManager := NewQueueRootManager(db, blockScannerAPI)
 
 	// configuration fileLoadTokenconfiguration
-	var decimalConverter *utils.DecimalConverter
-	if len(config.AppConfig.Tokens.ChainDecimals) > 0 {
-		decimalConverter = utils.NewDecimalConverterWithConfig(config.AppConfig.Tokens.ChainDecimals)
-	} else {
-		decimalConverter = utils.NewDecimalConverter() // UseDefaultConfiguration
-	}
+	decimalConverter := NewTokenDecimalConverter(db)
 
 	return &BlockchainEventProcessor{
 		db:               db,
@@ -70,15 +89,53 @@ func NewBlockchainEventProcessor(db *gorm.DB, pushService *WebSocketPushService,
 		pushService:      pushService,
 		dbWithPush:       dbWithPush,
 		decimalConverter: decimalConverter, // Useconfiguration fileorDefaultconfiguration
+
+		statusTransitionRepo: repository.NewStatusTransitionRepository(db),
+		withdrawRequestRepo:  repository.NewWithdrawRequestRepository(db),
+		depositEventRepo:     repository.NewDepositEventRepository(db),
+		checkbookRepo:        repository.NewCheckbookRepository(db),
+
+		matchByDeprecatedRequestID: config.GetWithdrawConfig().MatchByDeprecatedRequestID,
+
+		decodeRecipient: decodeWithdrawRecipient,
+
+		logger: logger,
 	}
 }
 
+// WithDB returns a copy of the processor that reads and writes through db, e.g. a transaction of the caller
+// dbWithPush is shared and still writes through its own connection.
+func (p *BlockchainEventProcessor) WithDB(db *gorm.DB) *BlockchainEventProcessor {
+	bound := *p
+	bound.db = db
+	bound.queueRootManager = NewQueueRootManager(db, p.queueRootManager.blockScannerAPI)
+	bound.statusTransitionRepo = repository.NewStatusTransitionRepository(db)
+	bound.withdrawRequestRepo = repository.NewWithdrawRequestRepository(db)
+	bound.depositEventRepo = repository.NewDepositEventRepository(db)
+	bound.checkbookRepo = repository.NewCheckbookRepository(db)
+	return &bound
+}
+
 // ============ eventprocess ============
 
+// isChainSupported reports whether events of chainID are processed (blockchain.supported_chains)
+// Events of other chains are logged and acknowledged without creating or updating any record.
+func (p *BlockchainEventProcessor) isChainSupported(eventName string, chainID int64) bool {
+	if config.IsChainSupported(int(chainID)) {
+		return true
+	}
+	p.logger.Warn("Ignoring event from unsupported chain", "event", eventName, "chain_id", chainID)
+	return false
+}
+
 // ProcessDepositReceived process Treasury.DepositReceived event
 func (p *BlockchainEventProcessor) ProcessDepositReceived(event *clients.EventDepositReceivedResponse) error {
+	if !p.isChainSupported("DepositReceived", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 [start] processDepositReceivedevent: Chain=%d, LocalDepositId=%d", event.ChainID, event.EventData.LocalDepositId)
 	log.Printf("🔍 [event] Depositor=%s, Amount=%s, Token=%s", event.EventData.Depositor, event.EventData.Amount, event.EventData.Token)
+	event.EventData.PromoteCode = utils.NormalizePromoteCode(event.EventData.PromoteCode)
 
 	// 1. saveevent
 	log.Printf("💾 [1] startsaveDepositReceivedeventDatabase...")
@@ -104,38 +161,13 @@ func (p *BlockchainEventProcessor) ProcessDepositReceived(event *clients.EventDe
 	log.Printf("🔧 [data] EventRecord: ChainID=%d, TxHash=%s, LocalDepositId=%d",
 		eventRecord.SLIP44ChainID, eventRecord.TransactionHash, eventRecord.LocalDepositId)
 
-	// UseUpsert：attempt，existsthenCreate，existsthenUpdate
-	var existingEvent models.EventDepositReceived
-	err := p.db.Where("chain_id = ? AND transaction_hash = ? AND log_index = ?",
-		event.ChainID, event.TransactionHash, event.LogIndex).First(&existingEvent).Error
-
-	if err == gorm.ErrRecordNotFound {
-		// exists，Createrecord
-		if err := p.db.Create(eventRecord).Error; err != nil {
-			log.Printf("❌ [failed] CreateDepositReceivedeventfailed: %v", err)
-			return err
-		}
-		log.Printf("✅ [] DepositReceivedeventalreadyCreate, ID=%d", eventRecord.ID)
-	} else if err != nil {
-		log.Printf("❌ [queryfailed] queryDepositReceivedeventfailed: %v", err)
+	// Upsert by (chain_id, transaction_hash, log_index): a re-delivered event updates the stored row
+	if err := repository.Upsert(p.db, repository.EventLogConflictColumns, eventRecord,
+		[]string{"depositor", "token", "amount", "local_deposit_id", "promote_code", "updated_at"}); err != nil {
+		log.Printf("❌ [failed] UpsertDepositReceivedeventfailed: %v", err)
 		return err
-	} else {
-		// exists，Updaterecord
-		updates := map[string]interface{}{
-			"depositor":        event.EventData.Depositor,
-			"token":            event.EventData.Token,
-			"amount":           event.EventData.Amount,
-			"local_deposit_id": event.EventData.LocalDepositId,
-			"promote_code":     event.EventData.PromoteCode,
-			"updated_at":       time.Now(),
-		}
-		if err := p.db.Model(&existingEvent).Updates(updates).Error; err != nil {
-			log.Printf("❌ [failed] UpdateDepositReceivedeventfailed: %v", err)
-			return err
-		}
-		eventRecord.ID = existingEvent.ID
-		log.Printf("✅ [Update] DepositReceivedeventalreadyUpdate, ID=%d", eventRecord.ID)
 	}
+	log.Printf("✅ [] DepositReceivedeventalreadysaved, ID=%d", eventRecord.ID)
 
 	// 2. ：CreateCheckbookrecord（ifexists）
 	log.Printf("📝 [2] startCreate/UpdateCheckbookrecord...")
@@ -150,15 +182,17 @@ func (p *BlockchainEventProcessor) ProcessDepositReceived(event *clients.EventDe
 
 // ProcessDepositRecorded process ZKPayProxy.DepositRecorded event
 func (p *BlockchainEventProcessor) ProcessDepositRecorded(event *clients.EventDepositRecordedResponse) error {
+	if !p.isChainSupported("DepositRecorded", event.ChainID) {
+		return nil
+	}
 	log.Printf("🚀 [ProcessDepositRecorded] Function called! Chain=%d, LocalDepositId=%d", event.ChainID, event.EventData.LocalDepositId)
-
-	// Ensure tokenKey mapper is initialized
-	utils.InitTokenKeyHashMap()
+	// Normalized once here so the event row, DepositInfo and Checkbook all store the same code
+	event.EventData.PromoteCode = utils.NormalizePromoteCode(event.EventData.PromoteCode)
 
 	// Convert tokenKey hash to original string (e.g., "USDT")
 	// Solidity indexed string is encoded as keccak256 hash, we need to convert it back
 	log.Printf("🔍 [ProcessDepositRecorded] Converting tokenKey hash: %s", event.EventData.TokenKey)
-	originalTokenKey := utils.GetTokenKeyFromHash(event.EventData.TokenKey)
+	originalTokenKey := resolveTokenKey(event.EventData.TokenKey, "ProcessDepositRecorded")
 	log.Printf("🔍 [ProcessDepositRecorded] Converted tokenKey: %s", originalTokenKey)
 	log.Printf("📥 [ProcessDepositRecorded] processDepositRecordedevent: Chain=%d, LocalDepositId=%d, TokenKey=%s (hash: %s), AllocatableAmount=%s, FeeTotalLocked=%s",
 		event.ChainID, event.EventData.LocalDepositId, originalTokenKey, event.EventData.TokenKey, event.EventData.AllocatableAmount, event.EventData.FeeTotalLocked)
@@ -198,6 +232,7 @@ func (p *BlockchainEventProcessor) ProcessDepositRecorded(event *clients.EventDe
 		// Event Data
 		LocalDepositId:    event.EventData.LocalDepositId,
 		TokenId:           event.EventData.TokenId,
+		TokenKey:          originalTokenKey,
 		OwnerChainId:      event.EventData.Owner.ChainId,
 		OwnerData:         ownerUniversalAddress, // 32-byte Universal Address
 		GrossAmount:       event.EventData.GrossAmount,
@@ -210,172 +245,143 @@ func (p *BlockchainEventProcessor) ProcessDepositRecorded(event *clients.EventDe
 		EventTimestamp:    event.EventData.Timestamp,
 	}
 
-	// UseUpsert：attempt，existsthenCreate，existsthenUpdate
-	var existingEvent models.EventDepositRecorded
-	err := p.db.Where("chain_id = ? AND transaction_hash = ? AND log_index = ?",
-		event.ChainID, event.TransactionHash, event.LogIndex).First(&existingEvent).Error
-
-	if err == gorm.ErrRecordNotFound {
-		// exists，Createrecord
-		if err := p.db.Create(eventRecord).Error; err != nil {
-			log.Printf("❌ [failed] CreateDepositRecordedeventfailed: %v", err)
+	// Steps 1-3 commit or roll back together, a failure part-way must not leave a half-recorded deposit
+	var pushCheckbookID, pushOldStatus string
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		// Upsert by (chain_id, transaction_hash, log_index): a re-delivered event updates the stored row, including owner_data
+		if err := repository.Upsert(tx, repository.EventLogConflictColumns, eventRecord, []string{
+			"local_deposit_id", "token_id", "owner_chain_id", "owner_data", "gross_amount", "fee_total_locked",
+			"allocatable_amount", "promote_code", "address_rank", "deposit_tx_hash", "event_block_number",
+			"event_timestamp", "updated_at",
+		}); err != nil {
+			log.Printf("❌ [failed] UpsertDepositRecordedeventfailed: %v", err)
 			return err
 		}
-		log.Printf("✅ [] DepositRecordedeventalreadyCreate, ID=%d", eventRecord.ID)
-	} else if err != nil {
-		log.Printf("❌ [queryfailed] queryDepositRecordedeventfailed: %v", err)
-		return err
-	} else {
-		// exists，Updaterecord - ：Updateowner_data
-		updates := map[string]interface{}{
-			"local_deposit_id":   event.EventData.LocalDepositId,
-			"token_id":           event.EventData.TokenId,
-			"owner_chain_id":     event.EventData.Owner.ChainId,
-			"owner_data":         event.EventData.Owner.Data,
-			"gross_amount":       event.EventData.GrossAmount,
-			"fee_total_locked":   event.EventData.FeeTotalLocked,
-			"allocatable_amount": event.EventData.AllocatableAmount,
-			"promote_code":       event.EventData.PromoteCode,
-			"address_rank":       event.EventData.AddressRank,
-			"deposit_tx_hash":    event.EventData.DepositTxHash,
-			"event_block_number": event.EventData.BlockNumber,
-			"event_timestamp":    event.EventData.Timestamp,
-			"updated_at":         time.Now(),
-		}
-		if err := p.db.Model(&existingEvent).Updates(updates).Error; err != nil {
-			log.Printf("❌ [failed] UpdateDepositRecordedeventfailed: %v", err)
+		log.Printf("✅ [] DepositRecordedeventalreadysaved, ID=%d, OwnerData=%s", eventRecord.ID, eventRecord.OwnerData)
+
+		// 2. ：CreateorUpdateDepositInforecord
+		depositInfo := &models.DepositInfo{
+			SLIP44ChainID:  uint32(event.ChainID), // UseNATS subjectParseSLIP-44 chain ID
+			ChainID:        event.ChainID,         // Setchain_id
+			LocalDepositID: event.EventData.LocalDepositId,
+			TokenID:        event.EventData.TokenId,
+			Owner: models.UniversalAddress{
+				SLIP44ChainID: uint32(event.ChainID), // UseNATS subjectParseSLIP-44 chain ID
+				Data:          ownerUniversalAddress, // same 32-byte Universal Address as the event row
+			},
+			GrossAmount:       event.EventData.GrossAmount,
+			FeeTotalLocked:    event.EventData.FeeTotalLocked,
+			AllocatableAmount: event.EventData.AllocatableAmount,
+			PromoteCode:       event.EventData.PromoteCode,
+			AddressRank:       event.EventData.AddressRank,
+			DepositTxHash:     event.EventData.DepositTxHash,
+			BlockNumber:       event.EventData.BlockNumber,
+			ContractTimestamp: event.EventData.Timestamp,
+			Used:              false, // statusnotUse
+
+			AllocatableRemaining: event.EventData.AllocatableAmount, // nothing allocated yet
+		}
+
+		// Insert unless it exists; an existing row is only updated below, subject to the checkbook's progress
+		// Note: Primary key is (slip44_chain_id, local_deposit_id)
+		created, err := repository.CreateIfAbsent(tx, []string{"slip44_chain_id", "local_deposit_id"}, depositInfo)
+		if err != nil {
+			log.Printf("❌ [failed] CreateDepositInforecordfailed: %v", err)
 			return err
 		}
-		eventRecord.ID = existingEvent.ID
-		log.Printf("✅ [Update] DepositRecordedeventalreadyUpdate, ID=%d, OwnerData=%s", eventRecord.ID, event.EventData.Owner.Data)
-	}
 
-	// 2. ：CreateorUpdateDepositInforecord
-	depositInfo := &models.DepositInfo{
-		SLIP44ChainID:  uint32(event.ChainID), // UseNATS subjectParseSLIP-44 chain ID
-		ChainID:        event.ChainID,         // Setchain_id
-		LocalDepositID: event.EventData.LocalDepositId,
-		TokenID:        event.EventData.TokenId,
-		Owner: models.UniversalAddress{
-			SLIP44ChainID: uint32(event.ChainID), // UseNATS subjectParseSLIP-44 chain ID
-			Data:          event.EventData.Owner.Data,
-		},
-		GrossAmount:       event.EventData.GrossAmount,
-		FeeTotalLocked:    event.EventData.FeeTotalLocked,
-		AllocatableAmount: event.EventData.AllocatableAmount,
-		PromoteCode:       event.EventData.PromoteCode,
-		AddressRank:       event.EventData.AddressRank,
-		DepositTxHash:     event.EventData.DepositTxHash,
-		BlockNumber:       event.EventData.BlockNumber,
-		ContractTimestamp: event.EventData.Timestamp,
-		Used:              false, // statusnotUse
-	}
-
-	// UseUpsert：attempt，existsthenCreate，existsthenUpdate
-	// Note: Primary key is (slip44_chain_id, local_deposit_id), so query using slip44_chain_id
-	var existingDepositInfo models.DepositInfo
-	err = p.db.Where("slip44_chain_id = ? AND local_deposit_id = ?",
-		event.ChainID, event.EventData.LocalDepositId).First(&existingDepositInfo).Error
-
-	needUpdate := false
-	if err == gorm.ErrRecordNotFound {
-		// Record not found, try to create
-		if err := p.db.Create(depositInfo).Error; err != nil {
-			// Handle duplicate key error (race condition - record was created by another process)
-			if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "23505") {
-				log.Printf("⚠️ [duplicate] DepositInforecordalreadyexists, attemptingupdate...")
-				// Record was created by another process, query and update it
-				err = p.db.Where("slip44_chain_id = ? AND local_deposit_id = ?",
-					event.ChainID, event.EventData.LocalDepositId).First(&existingDepositInfo).Error
-				if err != nil {
-					log.Printf("❌ [queryfailed] queryDepositInforecordafterduplicatefailed: %v", err)
-					return err
-				}
-				needUpdate = true
-			} else {
-				log.Printf("❌ [failed] CreateDepositInforecordfailed: %v", err)
-				return err
-			}
-		} else {
+		var existingDepositInfo models.DepositInfo
+		needUpdate := !created
+		if created {
 			log.Printf("✅ [] DepositInforecordalreadyCreate, ChainID=%d, LocalDepositID=%d, OwnerData=%s",
 				depositInfo.SLIP44ChainID, depositInfo.LocalDepositID, depositInfo.Owner.Data)
+		} else if err := tx.Where("slip44_chain_id = ? AND local_deposit_id = ?",
+			event.ChainID, event.EventData.LocalDepositId).First(&existingDepositInfo).Error; err != nil {
+			log.Printf("❌ [queryfailed] queryDepositInforecordfailed: %v", err)
+			return err
 		}
-	} else if err != nil {
-		log.Printf("❌ [queryfailed] queryDepositInforecordfailed: %v", err)
-		return err
-	} else {
-		// Record exists, need to update
-		needUpdate = true
-	}
-
-	// Update existing record if needed
-	if needUpdate {
-		// Check if associated Checkbook status has progressed beyond ready_for_commitment
-		// If so, skip update to avoid rolling back progress
-		var checkbook models.Checkbook
-		err := p.db.Where("chain_id = ? AND local_deposit_id = ?",
-			event.ChainID, event.EventData.LocalDepositId).First(&checkbook).Error
-
-		if err == nil {
-			// Checkbook exists, check its status
-			statusProgression := p.getStatusProgression()
-			currentLevel, exists := statusProgression[checkbook.Status]
-			readyForCommitmentLevel := statusProgression[models.CheckbookStatusReadyForCommitment]
-
-			if !exists {
-				// Status not in progression map (e.g., failure states), allow update
-				log.Printf("⚠️ [unknown] DepositInforecordupdate: Checkbookstatus=%s notinprogressionmap, allowupdate",
-					checkbook.Status)
-			} else if currentLevel > readyForCommitmentLevel {
-				// Status has progressed beyond ready_for_commitment, skip update
-				log.Printf("⚠️ [skip] DepositInforecordupdate: Checkbookstatus=%s (level=%d) > ready_for_commitment (level=%d), skipupdatetoavoidrollback",
-					checkbook.Status, currentLevel, readyForCommitmentLevel)
-				needUpdate = false
-			} else {
-				log.Printf("✅ [allow] DepositInforecordupdate: Checkbookstatus=%s (level=%d) <= ready_for_commitment (level=%d), allowupdate",
-					checkbook.Status, currentLevel, readyForCommitmentLevel)
-			}
-		} else if err != gorm.ErrRecordNotFound {
-			// Query error (not just not found), log but continue with update
-			log.Printf("⚠️ [query] Checkbookqueryfailed: %v, continuewithDepositInfoupdate", err)
-		}
-		// If Checkbook not found, allow update (Checkbook will be created later)
 
+		// Update existing record if needed
 		if needUpdate {
-			updates := map[string]interface{}{
-				"chain_id":           event.ChainID, // chain_idUpdate
-				"token_id":           event.EventData.TokenId,
-				"owner_chain_id":     event.EventData.Owner.ChainId,
-				"owner_data":         event.EventData.Owner.Data, // 🔧 ：Updateowner_data
-				"gross_amount":       event.EventData.GrossAmount,
-				"fee_total_locked":   event.EventData.FeeTotalLocked,
-				"allocatable_amount": event.EventData.AllocatableAmount,
-				"promote_code":       event.EventData.PromoteCode,
-				"address_rank":       event.EventData.AddressRank,
-				"deposit_tx_hash":    event.EventData.DepositTxHash,
-				"block_number":       event.EventData.BlockNumber,
-				"contract_timestamp": event.EventData.Timestamp,
-				"updated_at":         time.Now(),
+			// Check if associated Checkbook status has progressed beyond ready_for_commitment
+			// If so, skip update to avoid rolling back progress
+			var checkbook models.Checkbook
+			err := tx.Where("chain_id = ? AND local_deposit_id = ?",
+				event.ChainID, event.EventData.LocalDepositId).First(&checkbook).Error
+
+			if err == nil {
+				// Checkbook exists, check its status
+				statusProgression := p.getStatusProgression()
+				currentLevel, exists := statusProgression[checkbook.Status]
+				readyForCommitmentLevel := statusProgression[models.CheckbookStatusReadyForCommitment]
+
+				if !exists {
+					// Status not in progression map (e.g., failure states), allow update
+					log.Printf("⚠️ [unknown] DepositInforecordupdate: Checkbookstatus=%s notinprogressionmap, allowupdate",
+						checkbook.Status)
+				} else if currentLevel > readyForCommitmentLevel {
+					// Status has progressed beyond ready_for_commitment, skip update
+					log.Printf("⚠️ [skip] DepositInforecordupdate: Checkbookstatus=%s (level=%d) > ready_for_commitment (level=%d), skipupdatetoavoidrollback",
+						checkbook.Status, currentLevel, readyForCommitmentLevel)
+					needUpdate = false
+				} else {
+					log.Printf("✅ [allow] DepositInforecordupdate: Checkbookstatus=%s (level=%d) <= ready_for_commitment (level=%d), allowupdate",
+						checkbook.Status, currentLevel, readyForCommitmentLevel)
+				}
+			} else if err != gorm.ErrRecordNotFound {
+				// Query error (not just not found), log but continue with update
+				log.Printf("⚠️ [query] Checkbookqueryfailed: %v, continuewithDepositInfoupdate", err)
 			}
-			if err := p.db.Model(&existingDepositInfo).Updates(updates).Error; err != nil {
-				log.Printf("❌ [failed] UpdateDepositInforecordfailed: %v", err)
-				return err
+			// If Checkbook not found, allow update (Checkbook will be created later)
+
+			if needUpdate {
+				updates := map[string]interface{}{
+					"chain_id":           event.ChainID, // chain_idUpdate
+					"token_id":           event.EventData.TokenId,
+					"owner_chain_id":     event.EventData.Owner.ChainId,
+					"owner_data":         ownerUniversalAddress, // normalized like the create path, never the raw event value
+					"gross_amount":       event.EventData.GrossAmount,
+					"fee_total_locked":   event.EventData.FeeTotalLocked,
+					"allocatable_amount": event.EventData.AllocatableAmount,
+					"promote_code":       event.EventData.PromoteCode,
+					"address_rank":       event.EventData.AddressRank,
+					"deposit_tx_hash":    event.EventData.DepositTxHash,
+					"block_number":       event.EventData.BlockNumber,
+					"contract_timestamp": event.EventData.Timestamp,
+					"updated_at":         time.Now(),
+				}
+				// Keep what is already allocated to checks (failed checkbooks may still have them): new amount - (old amount - old remaining)
+				updates["allocatable_remaining"] = gorm.Expr(
+					"GREATEST(?::numeric - (COALESCE(NULLIF(allocatable_amount, '')::numeric, 0) - allocatable_remaining), 0)",
+					event.EventData.AllocatableAmount)
+				if err := tx.Model(&existingDepositInfo).Updates(updates).Error; err != nil {
+					log.Printf("❌ [failed] UpdateDepositInforecordfailed: %v", err)
+					return err
+				}
+				log.Printf("✅ [Update] DepositInforecordalreadyUpdate, ChainID=%d, LocalDepositID=%d, OwnerData=%s",
+					event.ChainID, event.EventData.LocalDepositId, ownerUniversalAddress)
 			}
-			log.Printf("✅ [Update] DepositInforecordalreadyUpdate, ChainID=%d, LocalDepositID=%d, OwnerData=%s",
-				event.ChainID, event.EventData.LocalDepositId, event.EventData.Owner.Data)
 		}
-	}
 
-	// 3. ：UpdateCheckbookstatusready_for_commitment
-	log.Printf("📝 [3] startUpdateCheckbookstatusready_for_commitment...")
-	if err := p.updateCheckbookToReadyForCommitment(event); err != nil {
-		log.Printf("❌ [failed] UpdateCheckbookstatusfailed: %v", err)
-		// Return error to ensure the caller knows the checkbook creation/update failed
-		return fmt.Errorf("UpdateCheckbookstatusfailed: %w", err)
-	} else {
+		// 3. ：UpdateCheckbookstatusready_for_commitment
+		log.Printf("📝 [3] startUpdateCheckbookstatusready_for_commitment...")
+		checkbookID, oldCheckbookStatus, err := p.updateCheckbookToReadyForCommitment(tx, event)
+		if err != nil {
+			log.Printf("❌ [failed] UpdateCheckbookstatusfailed: %v", err)
+			// Return error to ensure the caller knows the checkbook creation/update failed
+			return fmt.Errorf("UpdateCheckbookstatusfailed: %w", err)
+		}
 		log.Printf("✅ [3] UpdateCheckbookstatuscompleted")
+		pushCheckbookID, pushOldStatus = checkbookID, oldCheckbookStatus
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	// Push only after commit, clients must not see a checkbook state that may be rolled back
+	p.pushCheckbookUpdate(pushCheckbookID, pushOldStatus, "DepositRecorded")
+
 	// 4. Fee query records are now managed by KYT Oracle service
 	// No need to update fee_query_records table in backend
 
@@ -385,7 +391,11 @@ func (p *BlockchainEventProcessor) ProcessDepositRecorded(event *clients.EventDe
 
 // ProcessDepositUsed process ZKPayProxy.DepositUsed event
 func (p *BlockchainEventProcessor) ProcessDepositUsed(event *clients.EventDepositUsedResponse) error {
+	if !p.isChainSupported("DepositUsed", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 processDepositUsedevent: Chain=%d, LocalDepositId=%d, Commitment=%s", event.ChainID, event.EventData.LocalDepositId, event.EventData.Commitment)
+	event.EventData.PromoteCode = utils.NormalizePromoteCode(event.EventData.PromoteCode)
 
 	// 1. saveevent
 	eventRecord := &models.EventDepositUsed{
@@ -405,12 +415,14 @@ func (p *BlockchainEventProcessor) ProcessDepositUsed(event *clients.EventDeposi
 		PromoteCode:    event.EventData.PromoteCode,
 	}
 
-	if err := p.db.Create(eventRecord).Error; err != nil {
+	// Upsert by (chain_id, transaction_hash, log_index): a re-delivered event must not insert a second row
+	if err := repository.Upsert(p.db, repository.EventLogConflictColumns, eventRecord,
+		[]string{"local_deposit_id", "commitment", "promote_code", "updated_at"}); err != nil {
 		log.Printf("❌ saveDepositUsedeventfailed: %v", err)
 		return err
 	}
 
-	// 2. ：DepositInfoalreadyUse
+	// 2. ：DepositInfoalreadyUse (idempotent)
 	// Note: Primary key is (slip44_chain_id, local_deposit_id), so query using slip44_chain_id
 	result := p.db.Model(&models.DepositInfo{}).
 		Where("slip44_chain_id = ? AND local_deposit_id = ?", event.ChainID, event.EventData.LocalDepositId).
@@ -455,6 +467,9 @@ func (p *BlockchainEventProcessor) ProcessDepositUsed(event *clients.EventDeposi
 
 // ProcessCommitmentRootUpdated process ZKPayProxy.CommitmentRootUpdated event
 func (p *BlockchainEventProcessor) ProcessCommitmentRootUpdated(event *clients.EventCommitmentRootUpdatedResponse) error {
+	if !p.isChainSupported("CommitmentRootUpdated", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 processCommitmentRootUpdatedevent: Chain=%d, OldRoot=%s, NewRoot=%s", event.ChainID, event.EventData.OldRoot, event.EventData.NewRoot)
 
 	// 1. saveevent
@@ -494,34 +509,16 @@ func (p *BlockchainEventProcessor) ProcessCommitmentRootUpdated(event *clients.E
 		return nil
 	}
 
-	// 1: commitmentDepositUsedrecord
-	var depositUsedEvents []models.EventDepositUsed
-	if err := p.db.Where("commitment = ?", event.EventData.Commitment).Find(&depositUsedEvents).Error; err != nil {
-		log.Printf("❌ DepositUsedrecordfailed: %v", err)
-		return fmt.Errorf("DepositUsedrecordfailed: %w", err)
+	affectedCheckbooks, err := p.findCheckbooksByCommitment(event.EventData.Commitment)
+	if err != nil {
+		return err
 	}
-
-	if len(depositUsedEvents) == 0 {
-		log.Printf("⚠️ [CommitmentRootUpdated] notcorresponding toDepositUsedrecord，Commitment=%s", event.EventData.Commitment)
+	if len(affectedCheckbooks) == 0 {
+		log.Printf("⚠️ [CommitmentRootUpdated] notcorresponding toCheckbook/DepositUsedrecord，Commitment=%s", event.EventData.Commitment)
 		log.Printf("✅ CommitmentRootUpdatedeventprocesscompleted: ID=%d, notcorresponding toDepositUsed", eventRecord.ID)
 		return nil
 	}
 
-	// 2: DepositUsedrecord(ChainID + LocalDepositId)corresponding toCheckbook
-	var affectedCheckbooks []models.Checkbook
-	for _, depositUsed := range depositUsedEvents {
-		var checkbooks []models.Checkbook
-		if err := p.db.Where("chain_id = ? AND local_deposit_id = ?",
-			depositUsed.SLIP44ChainID, depositUsed.LocalDepositId).Find(&checkbooks).Error; err != nil {
-			log.Printf("❌ Checkbookfailed: ChainID=%d, LocalDepositId=%d, Error=%v",
-				depositUsed.SLIP44ChainID, depositUsed.LocalDepositId, err)
-			continue
-		}
-		affectedCheckbooks = append(affectedCheckbooks, checkbooks...)
-		log.Printf("🔗 [] Commitment=%s -> DepositUsed(ChainID=%d, LocalDepositId=%d) -> %dCheckbook",
-			event.EventData.Commitment, depositUsed.SLIP44ChainID, depositUsed.LocalDepositId, len(checkbooks))
-	}
-
 	updatedCount := 0
 	targetStatus := models.CheckbookStatusWithCheckbook
 
@@ -554,17 +551,73 @@ func (p *BlockchainEventProcessor) ProcessCommitmentRootUpdated(event *clients.E
 	return nil
 }
 
+// findCheckbooksByCommitment returns the checkbooks of a commitment
+// Direct lookup by checkbooks.commitment, plus every checkbook of the deposits whose DepositUsed event carries
+// the commitment (covers checkbooks whose commitment column is not set yet), deduplicated by ID.
+func (p *BlockchainEventProcessor) findCheckbooksByCommitment(commitment string) ([]models.Checkbook, error) {
+	ctx := context.Background()
+	var affectedCheckbooks []models.Checkbook
+	seen := make(map[string]bool)
+
+	checkbook, err := p.checkbookRepo.GetByCommitment(ctx, commitment)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Printf("❌ Checkbookfailed: Commitment=%s, Error=%v", commitment, err)
+		return nil, fmt.Errorf("failed to query checkbook by commitment: %w", err)
+	}
+	if checkbook != nil {
+		seen[checkbook.ID] = true
+		affectedCheckbooks = append(affectedCheckbooks, *checkbook)
+	}
+
+	// 1: commitmentDepositUsedrecord
+	depositUsedEvents, err := p.depositEventRepo.FindDepositUsedByCommitment(ctx, 0, commitment)
+	if err != nil {
+		log.Printf("❌ DepositUsedrecordfailed: %v", err)
+		return nil, fmt.Errorf("DepositUsedrecordfailed: %w", err)
+	}
+
+	// 2: DepositUsedrecord(ChainID + LocalDepositId)corresponding toCheckbook
+	for _, depositUsed := range depositUsedEvents {
+		var checkbooks []models.Checkbook
+		if err := p.db.Where("chain_id = ? AND local_deposit_id = ?",
+			depositUsed.SLIP44ChainID, depositUsed.LocalDepositId).Find(&checkbooks).Error; err != nil {
+			log.Printf("❌ Checkbookfailed: ChainID=%d, LocalDepositId=%d, Error=%v",
+				depositUsed.SLIP44ChainID, depositUsed.LocalDepositId, err)
+			continue
+		}
+		for _, cb := range checkbooks {
+			if !seen[cb.ID] {
+				seen[cb.ID] = true
+				affectedCheckbooks = append(affectedCheckbooks, cb)
+			}
+		}
+		log.Printf("🔗 [] Commitment=%s -> DepositUsed(ChainID=%d, LocalDepositId=%d) -> %dCheckbook",
+			commitment, depositUsed.SLIP44ChainID, depositUsed.LocalDepositId, len(checkbooks))
+	}
+
+	return affectedCheckbooks, nil
+}
+
 // ProcessWithdrawRequested process ZKPayProxy.WithdrawRequested event
 func (p *BlockchainEventProcessor) ProcessWithdrawRequested(event *clients.EventWithdrawRequestedResponse) error {
-	log.Printf("📥 processWithdrawRequestedevent: Chain=%d, RequestId=%s, Amount=%s", event.ChainID, event.EventData.RequestId, event.EventData.Amount)
-
-	// 1. Parserecipienthash - needdataGet
-	log.Printf("⚠️ WithdrawRequestedeventrecipienthash: %s", event.EventData.Recipient)
-	log.Printf("   indexed tuplekeccak256hash，needinput dataParserecipient")
+	if !p.isChainSupported("WithdrawRequested", event.ChainID) {
+		return nil
+	}
+	p.logger.Info("[WithdrawRequested] Processing event",
+		"chain_id", event.ChainID, "request_id", event.EventData.RequestId, "amount", event.EventData.Amount, "tx_hash", event.TransactionHash)
 
-	// Use，TODO: dataParserecipient
-	recipientChainId := uint16(0)              // ：needParse
-	recipientData := event.EventData.Recipient // Usehashdata
+	// 1. Parse recipient - indexed tuple is keccak256 hashed in the log, so decode it from the tx input data
+	recipientChainId, recipientData, err := p.decodeRecipient(event)
+	recipientDecoded := err == nil
+	if err != nil {
+		p.logger.Warn("[WithdrawRequested] Failed to decode recipient from tx input data, falling back to indexed recipient hash",
+			"request_id", event.EventData.RequestId, "recipient_hash", event.EventData.Recipient, "error", err)
+		recipientChainId = 0
+		recipientData = event.EventData.Recipient
+	} else {
+		p.logger.Info("[WithdrawRequested] Decoded recipient",
+			"request_id", event.EventData.RequestId, "recipient_chain_id", recipientChainId, "recipient_data", recipientData)
+	}
 
 	// 1. saveevent
 	eventRecord := &models.EventWithdrawRequested{
@@ -579,144 +632,239 @@ func (p *BlockchainEventProcessor) ProcessWithdrawRequested(event *clients.Event
 
 		// Event Data
 		RequestId:        event.EventData.RequestId,
-		RecipientChainId: recipientChainId, // ，needParse
-		RecipientData:    recipientData,    // Usehash
+		RecipientChainId: recipientChainId, // decoded from encodedPublicValues (0 if decoding failed)
+		RecipientData:    recipientData,    // decoded beneficiary data (hash if decoding failed)
 		TokenId:          event.EventData.TokenId,
 		Amount:           event.EventData.Amount,
 	}
 
 	if err := p.db.Create(eventRecord).Error; err != nil {
-		log.Printf("❌ saveWithdrawRequestedeventfailed: %v", err)
+		p.logger.Error("[WithdrawRequested] Failed to save event", "request_id", event.EventData.RequestId, "error", err)
 		return err
 	}
 
 	// 2. ：orCreateCheckrecord，status
-	log.Printf("📝 [2] startprocessWithdrawRequestedCheck...")
 	if err := p.processWithdrawRequestedCheck(event); err != nil {
-		log.Printf("❌ [failed] processWithdrawRequested Checkfailed: %v", err)
+		p.logger.Error("[WithdrawRequested] Failed to process Check", "request_id", event.EventData.RequestId, "error", err)
 		// returnError，eventalreadysaveSuccess
 	}
 
 	// 3. Update WithdrawRequest status: proof_status=completed, execute_status=success, payout_status=pending
-	log.Printf("📝 [3] startupdateWithdrawRequeststatus...")
 	var withdrawRequest models.WithdrawRequest
-
-	// Use transaction with FOR UPDATE to prevent deadlocks with polling service
-	tx := p.db.Begin()
+	var fromStatus string
+	statusUpdated := false
 	defer func() {
 		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Printf("❌ Panic in ProcessWithdrawRequested: %v", r)
+			p.logger.Error("[WithdrawRequested] Panic", "request_id", event.EventData.RequestId, "panic", r)
 		}
 	}()
 
-	err := tx.Set("gorm:query_option", "FOR UPDATE").
-		Where("withdraw_nullifier = ?", event.EventData.RequestId).
-		First(&withdrawRequest).Error
-	if err != nil {
+	// Use transaction with FOR UPDATE to prevent deadlocks with polling service
+	// (a savepoint when p.db is already a transaction, e.g. when events are reprocessed)
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Set("gorm:query_option", "FOR UPDATE").
+			Where("withdraw_nullifier = ?", event.EventData.RequestId).
+			First(&withdrawRequest).Error
 		if err == gorm.ErrRecordNotFound {
-			log.Printf("⚠️ [WithdrawRequested] WithdrawRequest not found by nullifier: RequestId=%s", event.EventData.RequestId)
+			p.logger.Warn("[WithdrawRequested] WithdrawRequest not found by nullifier", "request_id", event.EventData.RequestId)
 			// Try to find by Check's withdraw_request_id (if Check was found in step 2)
 			var check models.Check
 			checkErr := tx.Where("nullifier = ? OR request_id = ?", event.EventData.RequestId, event.EventData.RequestId).
 				First(&check).Error
 			if checkErr == nil && check.WithdrawRequestID != nil && *check.WithdrawRequestID != "" {
-				log.Printf("🔍 [WithdrawRequested] Found Check with withdraw_request_id=%s, trying to find WithdrawRequest", *check.WithdrawRequestID)
+				p.logger.Info("[WithdrawRequested] Found Check, looking up WithdrawRequest by withdraw_request_id",
+					"request_id", event.EventData.RequestId, "withdraw_request_id", *check.WithdrawRequestID)
 				err = tx.Set("gorm:query_option", "FOR UPDATE").
 					Where("id = ?", *check.WithdrawRequestID).
 					First(&withdrawRequest).Error
 				if err == nil {
-					log.Printf("✅ [WithdrawRequested] Found WithdrawRequest via Check's withdraw_request_id: %s", withdrawRequest.ID)
+					p.logger.Info("[WithdrawRequested] Found WithdrawRequest via Check's withdraw_request_id", "withdraw_request_id", withdrawRequest.ID)
 				}
 			}
 		}
-
 		if err != nil {
-			tx.Rollback()
-			if err == gorm.ErrRecordNotFound {
-				log.Printf("⚠️ [WithdrawRequested] WithdrawRequest not found: RequestId=%s (may be user-initiated withdraw or fee)", event.EventData.RequestId)
-				// Don't fail, just log - WithdrawRequest may not exist yet (user-initiated withdraw or fee)
-			} else {
-				log.Printf("❌ [WithdrawRequested] Query WithdrawRequest failed: %v", err)
-				// Don't return error - event already saved successfully
+			return err
+		}
+
+		// WithdrawRequest found, verify the on-chain recipient matches the stored recipient
+		recipientMismatch := false
+		if recipientDecoded && !recipientsMatch(recipientData, int(recipientChainId), withdrawRequest.Recipient) {
+			recipientMismatch = true
+			p.logger.Error("[WithdrawRequested] SECURITY: on-chain recipient does not match stored recipient, flagging recipient_mismatch",
+				"withdraw_request_id", withdrawRequest.ID, "request_id", event.EventData.RequestId, "tx_hash", event.TransactionHash,
+				"onchain_recipient", recipientData, "onchain_recipient_chain_id", recipientChainId,
+				"stored_recipient", withdrawRequest.Recipient.Data, "stored_recipient_chain_id", withdrawRequest.Recipient.SLIP44ChainID)
+		}
+
+		// Check if already in final status to avoid unnecessary updates
+		// This prevents conflicts with polling service that might have already updated it
+		if withdrawRequest.IsExecuteFinal() {
+			p.logger.Warn("[WithdrawRequested] WithdrawRequest already has execute_status=success, skipping update",
+				"withdraw_request_id", withdrawRequest.ID, "status", withdrawRequest.Status)
+			if !recipientMismatch {
+				return nil
+			}
+			// Status is final, but the mismatch flag is still recorded
+			if err := tx.Model(&withdrawRequest).Update("recipient_mismatch", true).Error; err != nil {
+				return fmt.Errorf("failed to flag recipient_mismatch: %w", err)
 			}
-			return nil // Exit early if WithdrawRequest not found
+			return nil
 		}
-	}
 
-	// WithdrawRequest found, proceed with status update
-	// Check if already in final status to avoid unnecessary updates
-	// This prevents conflicts with polling service that might have already updated it
-	if withdrawRequest.ExecuteStatus == models.ExecuteStatusSuccess {
-		tx.Rollback()
-		log.Printf("⚠️ [WithdrawRequested] WithdrawRequest %s already has execute_status=success, skipping update", withdrawRequest.ID)
-	} else {
 		// Update status: proof_status=completed, execute_status=success
 		// Only update payout_status to pending if it's not already completed
-		blockNumber := uint64(event.BlockNumber)
-		chainID := uint32(event.ChainID) // SLIP44 chain ID where executeWithdraw TX was submitted
-
 		// Validate TransactionHash is not empty
 		if event.TransactionHash == "" {
-			log.Printf("⚠️ [WithdrawRequested] WARNING: TransactionHash is empty! RequestId=%s", event.EventData.RequestId)
+			p.logger.Warn("[WithdrawRequested] TransactionHash is empty", "request_id", event.EventData.RequestId)
+		}
+
+		fromStatus = withdrawRequest.Status
+		executeChainID := uint32(event.ChainID) // SLIP44 chain ID where executeWithdraw TX was submitted
+		executeBlockNumber := uint64(event.BlockNumber)
+		if err := p.saveWithdrawRequest(tx, &withdrawRequest, "WithdrawRequested", func(fresh *models.WithdrawRequest) {
+			now := time.Now()
+			fresh.ProofStatus = models.ProofStatusCompleted
+			fresh.ExecuteStatus = models.ExecuteStatusSuccess
+			fresh.ExecuteChainID = &executeChainID
+			fresh.ExecuteTxHash = event.TransactionHash
+			fresh.ExecuteBlockNumber = &executeBlockNumber
+			fresh.ExecutedAt = &now
+			if recipientMismatch {
+				fresh.RecipientMismatch = true
+			}
+			// Only reset payout_status to pending if it's not already completed
+			// This prevents overwriting a completed payout status (e.g., if WithdrawExecuted arrived first)
+			if !fresh.IsPayoutFinal() {
+				fresh.PayoutStatus = models.PayoutStatusPending
+			} else {
+				p.logger.Warn("[WithdrawRequested] WithdrawRequest already has payout_status=completed, skipping payout_status update",
+					"withdraw_request_id", fresh.ID)
+			}
+		}); err != nil {
+			return fmt.Errorf("failed to update WithdrawRequest status: %w", err)
 		}
+		statusUpdated = true
+		return nil
+	})
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		// Don't fail, just log - WithdrawRequest may not exist yet (user-initiated withdraw or fee)
+		p.logger.Warn("[WithdrawRequested] WithdrawRequest not found (may be user-initiated withdraw or fee)", "request_id", event.EventData.RequestId)
+		return nil
+	case err != nil:
+		// Don't return error - event already saved successfully
+		p.logger.Error("[WithdrawRequested] Failed to update WithdrawRequest", "request_id", event.EventData.RequestId,
+			"withdraw_request_id", withdrawRequest.ID, "error", err)
+	case statusUpdated:
+		p.logger.Info("[WithdrawRequested] WithdrawRequest status updated",
+			"withdraw_request_id", withdrawRequest.ID, "chain_id", event.ChainID, "tx_hash", event.TransactionHash,
+			"block_number", event.BlockNumber, "from_status", fromStatus, "status", withdrawRequest.Status,
+			"proof_status", withdrawRequest.ProofStatus, "execute_status", withdrawRequest.ExecuteStatus, "payout_status", withdrawRequest.PayoutStatus)
+		// Push WebSocket update for WithdrawRequest status change
+		if p.pushService != nil {
+			p.pushService.PushWithdrawRequestStatusUpdateDirect(&withdrawRequest, "", "WithdrawRequested")
+		}
+	}
+
+	p.logger.Info("[WithdrawRequested] Event processed", "event_id", eventRecord.ID, "request_id", event.EventData.RequestId)
+	return nil
+}
 
-		log.Printf("📝 [WithdrawRequested] Event TransactionHash: %s, BlockNumber: %d, ChainID: %d", event.TransactionHash, event.BlockNumber, event.ChainID)
+// recipientsMatch compares a decoded on-chain recipient with the stored recipient as 32-byte Universal Addresses
+// Each side is normalized for its own chain, so an EVM address and its zero-padded Universal form match.
+func recipientsMatch(onChainRecipient string, onChainChainID int, stored models.UniversalAddress) bool {
+	return toUniversalRecipient(onChainRecipient, onChainChainID) == toUniversalRecipient(stored.Data, int(stored.SLIP44ChainID))
+}
 
-		updates := map[string]interface{}{
-			"proof_status":         models.ProofStatusCompleted,
-			"execute_status":       models.ExecuteStatusSuccess,
-			"execute_chain_id":     chainID, // Record chain ID where execute transaction was submitted
-			"execute_tx_hash":      event.TransactionHash,
-			"execute_block_number": blockNumber,
-			"executed_at":          gorm.Expr("NOW()"),
+// toUniversalRecipient converts an address of chainID to a lowercase 32-byte Universal Address
+// TRON Base58 is only decoded for TRON (SLIP-44 195); an address that cannot be converted is returned lowercased.
+func toUniversalRecipient(address string, chainID int) string {
+	normalized := utils.NormalizeAddressForChain(strings.TrimSpace(address), chainID)
+	switch {
+	case utils.IsUniversalAddress(normalized):
+		return "0x" + strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(normalized, "0x"), "0X"))
+	case chainID == 195 && utils.IsTronAddress(normalized):
+		if universal, err := utils.TronToUniversalAddress(normalized); err == nil {
+			return universal
+		}
+	case utils.IsEvmAddress(normalized):
+		if universal, err := utils.EvmToUniversalAddress(normalized); err == nil {
+			return universal
+		}
+	}
+	return strings.ToLower(normalized)
+}
+
+// decodeWithdrawRecipient fetches the executeWithdraw transaction and decodes the recipient
+// from its encodedPublicValues (the WithdrawRequested log only carries the keccak256 hash)
+func decodeWithdrawRecipient(event *clients.EventWithdrawRequestedResponse) (uint16, string, error) {
+	if event.TransactionHash == "" {
+		return 0, "", fmt.Errorf("transaction hash is empty")
+	}
+	if config.AppConfig == nil {
+		return 0, "", fmt.Errorf("config not loaded")
+	}
+
+	var rpcEndpoints []string
+	for _, networkConfig := range config.AppConfig.Blockchain.Networks {
+		if int64(networkConfig.ChainID) == event.ChainID {
+			rpcEndpoints = networkConfig.RPCEndpoints
+			break
 		}
+	}
+	if len(rpcEndpoints) == 0 {
+		return 0, "", fmt.Errorf("no RPC endpoints configured for chain %d", event.ChainID)
+	}
 
-		// Only update payout_status to pending if it's not already completed
-		// This prevents overwriting a completed payout status (e.g., if WithdrawExecuted arrived first)
-		if withdrawRequest.PayoutStatus != models.PayoutStatusCompleted {
-			updates["payout_status"] = models.PayoutStatusPending
-		} else {
-			log.Printf("⚠️ [WithdrawRequested] WithdrawRequest %s already has payout_status=completed, skipping payout_status update", withdrawRequest.ID)
+	txHash := common.HexToHash(event.TransactionHash)
+	var lastErr error
+	for _, endpoint := range rpcEndpoints {
+		client, err := ethclient.Dial(endpoint)
+		if err != nil {
+			lastErr = fmt.Errorf("dial %s: %w", endpoint, err)
+			continue
 		}
 
-		if err := tx.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-			tx.Rollback()
-			log.Printf("❌ [WithdrawRequested] Failed to update WithdrawRequest status: %v", err)
-			// Don't return error - event already saved successfully
-		} else {
-			// Reload to get updated sub-statuses (Updates() already updated proof_status, execute_status, payout_status in DB)
-			if err := tx.Where("id = ?", withdrawRequest.ID).First(&withdrawRequest).Error; err != nil {
-				tx.Rollback()
-				log.Printf("❌ [WithdrawRequested] Failed to reload WithdrawRequest: %v", err)
-			} else {
-				// Update main status based on sub-statuses (Status is computed, not set directly)
-				withdrawRequest.UpdateMainStatus()
-				if err := tx.Save(&withdrawRequest).Error; err != nil {
-					tx.Rollback()
-					log.Printf("❌ [WithdrawRequested] Failed to update main status: %v", err)
-				} else {
-					if err := tx.Commit().Error; err != nil {
-						log.Printf("❌ [WithdrawRequested] Failed to commit transaction: %v", err)
-					} else {
-						log.Printf("✅ [WithdrawRequested] WithdrawRequest status updated: ID=%s, proof_status=completed, execute_status=success, payout_status=pending, computed_status=%s", withdrawRequest.ID, withdrawRequest.Status)
-						// Push WebSocket update for WithdrawRequest status change
-						if p.pushService != nil {
-							p.pushService.PushWithdrawRequestStatusUpdateDirect(&withdrawRequest, "", "WithdrawRequested")
-						}
-					}
-				}
-			}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		tx, _, err := client.TransactionByHash(ctx, txHash)
+		cancel()
+		client.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("get transaction %s from %s: %w", event.TransactionHash, endpoint, err)
+			continue
+		}
+
+		publicValues, err := types.ParseExecuteWithdrawCallData(tx.Data(), executeWithdrawMethods()...)
+		if err != nil {
+			return 0, "", fmt.Errorf("decode executeWithdraw input: %w", err)
 		}
+
+		recipientChainID, err := withdrawRecipientChainID(publicValues)
+		if err != nil {
+			return 0, "", err
+		}
+		return recipientChainID, publicValues.BeneficiaryData, nil
 	}
 
-	log.Printf("✅ WithdrawRequestedeventprocesscompleted: ID=%d", eventRecord.ID)
-	return nil
+	return 0, "", lastErr
+}
+
+// withdrawRecipientChainID returns the recipient chain of a withdraw, its public values' sourceChainId
+// The recipient's chain ID column is a uint16, so a larger SLIP-44 ID is an error rather than truncated.
+func withdrawRecipientChainID(publicValues *types.WithdrawPublicValues) (uint16, error) {
+	if publicValues.SourceChainID > math.MaxUint16 {
+		return 0, fmt.Errorf("recipient chain ID %d does not fit in uint16", publicValues.SourceChainID)
+	}
+	return uint16(publicValues.SourceChainID), nil
 }
 
 // ProcessWithdrawExecuted process Treasury.WithdrawExecuted event
 func (p *BlockchainEventProcessor) ProcessWithdrawExecuted(event *clients.EventWithdrawExecutedResponse) error {
-	log.Printf("📥 processWithdrawExecutedevent: Chain=%d, RequestId=%s, Amount=%s", event.ChainID, event.EventData.RequestId, event.EventData.Amount)
+	if !p.isChainSupported("WithdrawExecuted", event.ChainID) {
+		return nil
+	}
+	p.logger.Info("[WithdrawExecuted] Processing event",
+		"chain_id", event.ChainID, "request_id", event.EventData.RequestId, "amount", event.EventData.Amount, "tx_hash", event.TransactionHash)
 
 	// 1. saveevent
 	// Convert Recipient address to Universal Address format (32-byte)
@@ -757,129 +905,123 @@ func (p *BlockchainEventProcessor) ProcessWithdrawExecuted(event *clients.EventW
 		RequestId: event.EventData.RequestId,
 	}
 
+	// Idempotency: the same WithdrawExecuted event may be delivered more than once
+	var existingEvent models.EventWithdrawExecuted
+	err := p.db.Where("chain_id = ? AND transaction_hash = ? AND log_index = ?",
+		event.ChainID, event.TransactionHash, event.LogIndex).First(&existingEvent).Error
+	if err == nil {
+		p.logger.Info("[WithdrawExecuted] Event already processed, skipping",
+			"tx_hash", event.TransactionHash, "log_index", event.LogIndex, "event_id", existingEvent.ID)
+		return nil
+	} else if err != gorm.ErrRecordNotFound {
+		p.logger.Error("[WithdrawExecuted] Query existing event failed", "tx_hash", event.TransactionHash, "error", err)
+		return err
+	}
+
 	if err := p.db.Create(eventRecord).Error; err != nil {
-		log.Printf("❌ saveWithdrawExecutedeventfailed: %v", err)
+		p.logger.Error("[WithdrawExecuted] Failed to save event", "request_id", event.EventData.RequestId, "error", err)
 		return err
 	}
 
 	// 2. ：Checkrecord，statuscompleted
-	log.Printf("📝 [2] startprocessWithdrawExecutedCheck...")
 	if err := p.processWithdrawExecutedCheck(event); err != nil {
-		log.Printf("❌ [failed] processWithdrawExecuted Checkfailed: %v", err)
+		p.logger.Error("[WithdrawExecuted] Failed to process Check", "request_id", event.EventData.RequestId, "error", err)
 		// returnError，eventalreadysaveSuccess
 	}
 
 	// 3. Update WithdrawRequest status: payout_status=completed
-	log.Printf("📝 [3] startupdateWithdrawRequeststatus...")
-	var withdrawRequest models.WithdrawRequest
-	// 优先通过 withdraw_nullifier 查询
-	err := p.db.Where("withdraw_nullifier = ?", event.EventData.RequestId).First(&withdrawRequest).Error
+	// Requests matched only through their Checks were updated by processWithdrawExecutedCheck above
+	match, err := p.findWithdrawRequestForEvent(event.EventData.RequestId, false)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			// Fallback: 尝试通过 request_id (DEPRECATED) 查询
-			log.Printf("🔍 [WithdrawExecuted] WithdrawRequest not found by withdraw_nullifier, trying request_id (DEPRECATED): RequestId=%s", event.EventData.RequestId)
-			err = p.db.Where("request_id = ?", event.EventData.RequestId).First(&withdrawRequest).Error
-			if err != nil {
-				if err == gorm.ErrRecordNotFound {
-					log.Printf("⚠️ [WithdrawExecuted] WithdrawRequest not found by withdraw_nullifier or request_id: RequestId=%s", event.EventData.RequestId)
-					// Don't fail, just log - WithdrawRequest may not exist
-					return nil
-				}
-				log.Printf("❌ [WithdrawExecuted] Query WithdrawRequest by request_id failed: %v", err)
-				// Don't return error - event already saved successfully
-				return nil
-			}
-			// Found by request_id, continue below
-		} else {
-			log.Printf("❌ [WithdrawExecuted] Query WithdrawRequest failed: %v", err)
-			// Don't return error - event already saved successfully
-			return nil
-		}
+		p.logger.Error("[WithdrawExecuted] Query WithdrawRequest failed", "request_id", event.EventData.RequestId, "error", err)
+		// Don't return error - event already saved successfully
+		return nil
+	}
+	if match.request == nil {
+		p.logger.Warn("[WithdrawExecuted] WithdrawRequest not found", "request_id", event.EventData.RequestId,
+			"match_by_deprecated_request_id", p.matchByDeprecatedRequestID)
+		// Don't fail, just log - WithdrawRequest may not exist
+		return nil
+	}
+	withdrawRequest := *match.request
+	p.logger.Info("[WithdrawExecuted] Found WithdrawRequest", "withdraw_request_id", withdrawRequest.ID, "matched_by", match.matchedBy)
+
+	// Already completed by this payout transaction - don't overwrite payout_completed_at or re-push
+	if withdrawRequest.IsPayoutFinal() && withdrawRequest.PayoutTxHash == event.TransactionHash {
+		p.logger.Info("[WithdrawExecuted] WithdrawRequest already completed with this payout tx, skipping update",
+			"withdraw_request_id", withdrawRequest.ID, "tx_hash", withdrawRequest.PayoutTxHash)
+		return nil
 	}
 
 	// Found WithdrawRequest, continue with status update
 	{
 		// Log sub-statuses BEFORE update
-		log.Printf("📊 [WithdrawExecuted] Sub-statuses BEFORE update: proof_status=%s, execute_status=%s, payout_status=%s, hook_status=%s, fallback_transferred=%v, main_status=%s",
-			withdrawRequest.ProofStatus, withdrawRequest.ExecuteStatus, withdrawRequest.PayoutStatus, withdrawRequest.HookStatus, withdrawRequest.FallbackTransferred, withdrawRequest.Status)
-		log.Printf("📝 [WithdrawExecuted] Event TransactionHash: %s, BlockNumber: %d, ChainID: %d", event.TransactionHash, event.BlockNumber, event.ChainID)
+		p.logger.Info("[WithdrawExecuted] Sub-statuses before update",
+			"withdraw_request_id", withdrawRequest.ID, "status", withdrawRequest.Status,
+			"proof_status", withdrawRequest.ProofStatus, "execute_status", withdrawRequest.ExecuteStatus, "payout_status", withdrawRequest.PayoutStatus,
+			"hook_status", withdrawRequest.HookStatus, "fallback_transferred", withdrawRequest.FallbackTransferred)
 
 		// Update both execute_status and payout_status to completed
 		// WithdrawExecuted event indicates both execute (verification) and payout are completed
 		blockNumber := uint64(event.BlockNumber)
 		chainID := uint32(event.ChainID) // SLIP44 chain ID where payout TX was executed
 
-		// Validate TransactionHash is not empty
+		// Without a transaction hash the payout cannot be linked to an explorer: flag it instead of completing it
 		if event.TransactionHash == "" {
-			log.Printf("⚠️ [WithdrawExecuted] WARNING: TransactionHash is empty! RequestId=%s", event.EventData.RequestId)
-		}
-
-		updates := map[string]interface{}{
-			"execute_status":      models.ExecuteStatusSuccess, // Ensure execute_status is success
-			"payout_status":       models.PayoutStatusCompleted,
-			"payout_chain_id":     chainID, // Record chain ID where payout transaction was executed
-			"payout_tx_hash":      event.TransactionHash,
-			"payout_block_number": blockNumber,
-			"payout_completed_at": gorm.Expr("NOW()"),
-		}
-
-		// Only update execute fields if they are not already set (WithdrawRequested may have set them)
-		if withdrawRequest.ExecuteTxHash == "" {
-			updates["execute_tx_hash"] = event.TransactionHash
-		}
-		if withdrawRequest.ExecuteBlockNumber == nil {
-			updates["execute_block_number"] = blockNumber
-		}
-		if withdrawRequest.ExecuteChainID == nil {
-			updates["execute_chain_id"] = chainID // Record chain ID if not set
-		}
-		if withdrawRequest.ExecutedAt == nil {
-			updates["executed_at"] = gorm.Expr("NOW()")
+			p.flagPayoutForManualReview(&withdrawRequest, event.EventData.RequestId)
+			return nil
 		}
 
-		log.Printf("📝 [WithdrawExecuted] Updating sub-statuses: execute_status=%s, payout_status=%s, execute_chain_id=%d, payout_chain_id=%d",
-			updates["execute_status"], updates["payout_status"], chainID, chainID)
+		oldStatus := withdrawRequest.Status
+		if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "WithdrawExecuted", func(fresh *models.WithdrawRequest) {
+			now := time.Now()
+			fresh.ExecuteStatus = models.ExecuteStatusSuccess // Ensure execute_status is success
+			fresh.PayoutStatus = models.PayoutStatusCompleted
+			fresh.PayoutChainID = &chainID // Record chain ID where payout transaction was executed
+			fresh.PayoutTxHash = event.TransactionHash
+			fresh.PayoutBlockNumber = &blockNumber
+			fresh.PayoutCompletedAt = &now
 
-		if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-			log.Printf("❌ [WithdrawExecuted] Failed to update WithdrawRequest status: %v", err)
+			// Only update execute fields if they are not already set (WithdrawRequested may have set them)
+			if fresh.ExecuteTxHash == "" {
+				fresh.ExecuteTxHash = event.TransactionHash
+			}
+			if fresh.ExecuteBlockNumber == nil {
+				fresh.ExecuteBlockNumber = &blockNumber
+			}
+			if fresh.ExecuteChainID == nil {
+				fresh.ExecuteChainID = &chainID // Record chain ID if not set
+			}
+			if fresh.ExecutedAt == nil {
+				fresh.ExecutedAt = &now
+			}
+		}); err != nil {
+			p.logger.Error("[WithdrawExecuted] Failed to update WithdrawRequest status", "withdraw_request_id", withdrawRequest.ID, "error", err)
 			// Don't return error - event already saved successfully
 		} else {
-			// Reload to get updated sub-statuses
-			if err := p.db.First(&withdrawRequest, "id = ?", withdrawRequest.ID).Error; err != nil {
-				log.Printf("❌ [WithdrawExecuted] Failed to reload WithdrawRequest: %v", err)
-			} else {
-				// Log sub-statuses AFTER update (before computing main status)
-				log.Printf("📊 [WithdrawExecuted] Sub-statuses AFTER update (before UpdateMainStatus): proof_status=%s, execute_status=%s, payout_status=%s, hook_status=%s, fallback_transferred=%v, main_status=%s",
-					withdrawRequest.ProofStatus, withdrawRequest.ExecuteStatus, withdrawRequest.PayoutStatus, withdrawRequest.HookStatus, withdrawRequest.FallbackTransferred, withdrawRequest.Status)
-
-				// Update main status based on sub-statuses (Status is computed, not set directly)
-				oldStatus := withdrawRequest.Status
-				withdrawRequest.UpdateMainStatus()
-
-				// Log main status computation result
-				log.Printf("🧮 [WithdrawExecuted] Main status computation result: %s → %s (based on: proof=%s, execute=%s, payout=%s, hook=%s, fallback=%v)",
-					oldStatus, withdrawRequest.Status, withdrawRequest.ProofStatus, withdrawRequest.ExecuteStatus, withdrawRequest.PayoutStatus, withdrawRequest.HookStatus, withdrawRequest.FallbackTransferred)
-
-				if err := p.db.Save(&withdrawRequest).Error; err != nil {
-					log.Printf("❌ [WithdrawExecuted] Failed to update main status: %v", err)
-				} else {
-					log.Printf("✅ [WithdrawExecuted] WithdrawRequest status updated: ID=%s, final_status=%s (was %s)", withdrawRequest.ID, withdrawRequest.Status, oldStatus)
-					// Push WebSocket update for WithdrawRequest status change
-					if p.pushService != nil {
-						p.pushService.PushWithdrawRequestStatusUpdateDirect(&withdrawRequest, oldStatus, "WithdrawExecuted")
-					}
-				}
+			p.logger.Info("[WithdrawExecuted] WithdrawRequest status updated",
+				"withdraw_request_id", withdrawRequest.ID, "chain_id", chainID, "tx_hash", event.TransactionHash,
+				"block_number", blockNumber, "from_status", oldStatus, "status", withdrawRequest.Status,
+				"proof_status", withdrawRequest.ProofStatus, "execute_status", withdrawRequest.ExecuteStatus,
+				"payout_status", withdrawRequest.PayoutStatus, "hook_status", withdrawRequest.HookStatus,
+				"fallback_transferred", withdrawRequest.FallbackTransferred)
+			// Push WebSocket update for WithdrawRequest status change
+			if p.pushService != nil {
+				p.pushService.PushWithdrawRequestStatusUpdateDirect(&withdrawRequest, oldStatus, "WithdrawExecuted")
 			}
 		}
 	}
 
-	log.Printf("✅ WithdrawExecutedeventprocesscompleted: ID=%d", eventRecord.ID)
+	p.logger.Info("[WithdrawExecuted] Event processed", "event_id", eventRecord.ID, "request_id", event.EventData.RequestId)
 	return nil
 }
 
 // ProcessIntentManagerWithdrawExecuted process IntentManager.WithdrawExecuted event
 // This event indicates that payout (Stage 3) has completed successfully
 func (p *BlockchainEventProcessor) ProcessIntentManagerWithdrawExecuted(event *clients.EventIntentManagerWithdrawExecutedResponse) error {
+	if !p.isChainSupported("IntentManager.WithdrawExecuted", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 process IntentManager.WithdrawExecuted event: Chain=%d, WorkerType=%d, Success=%v",
 		event.ChainID, event.EventData.WorkerType, event.EventData.Success)
 
@@ -890,38 +1032,29 @@ func (p *BlockchainEventProcessor) ProcessIntentManagerWithdrawExecuted(event *c
 		// The contract may have reverted, but we should mark it as failed
 	}
 
-	// 1. Try to find the corresponding WithdrawRequest by payout_tx_hash
-	// Note: If Treasury.payout and IntentManager.executeWithdraw are in the same transaction,
-	// the txHash will match. If they're in different transactions (cross-chain), we need to
-	// use a different matching strategy (e.g., by beneficiary address and time range)
-	var withdrawRequest models.WithdrawRequest
-	err := p.db.Where("payout_tx_hash = ?", event.TransactionHash).First(&withdrawRequest).Error
-
+	// 1. Find the corresponding WithdrawRequest
+	// Match order: intent_manager_tx_hash → payout_tx_hash (Treasury.payout and IntentManager in the same TX)
+	// → the single recent processing payout (cross-chain, TX hash not recorded yet)
+	withdrawRequest, err := p.findIntentManagerWithdrawRequest(event.TransactionHash)
 	if err == gorm.ErrRecordNotFound {
-		// Try to find by matching beneficiary and recent payout status
-		// This handles cross-chain scenarios where payout_tx_hash might be different
-		log.Printf("⚠️ [IntentManager.WithdrawExecuted] No WithdrawRequest found with payout_tx_hash=%s, trying alternative matching", event.TransactionHash)
-
-		// Find requests with payout_status=processing that are recent (within last 24 hours)
-		// Note: This is a fallback - ideally we should track the IntentManager transaction hash separately
-		err = p.db.Where("payout_status = ? AND payout_status_updated_at > ?",
-			models.PayoutStatusProcessing,
-			time.Now().Add(-24*time.Hour)).First(&withdrawRequest).Error
-
-		if err == gorm.ErrRecordNotFound {
-			log.Printf("⚠️ [IntentManager.WithdrawExecuted] No matching WithdrawRequest found, skipping status update")
-			log.Printf("   TransactionHash=%s, WorkerType=%d, Success=%v, Message=%s",
-				event.TransactionHash, event.EventData.WorkerType, event.EventData.Success, event.EventData.Message)
-			return nil // Don't fail, just log and continue
-		} else if err != nil {
-			log.Printf("❌ [IntentManager.WithdrawExecuted] Query failed: %v", err)
-			return fmt.Errorf("query WithdrawRequest failed: %w", err)
-		}
+		log.Printf("⚠️ [IntentManager.WithdrawExecuted] No matching WithdrawRequest found, skipping status update")
+		log.Printf("   TransactionHash=%s, WorkerType=%d, Success=%v, Message=%s",
+			event.TransactionHash, event.EventData.WorkerType, event.EventData.Success, event.EventData.Message)
+		return nil // Don't fail, just log and continue
 	} else if err != nil {
 		log.Printf("❌ [IntentManager.WithdrawExecuted] Query failed: %v", err)
 		return fmt.Errorf("query WithdrawRequest failed: %w", err)
 	}
 
+	// Record IntentManager TX hash (kept separate from payout_tx_hash)
+	if withdrawRequest.IntentManagerTxHash != event.TransactionHash {
+		if err := p.withdrawRequestRepo.UpdateIntentManagerTxHash(context.Background(), withdrawRequest.ID, event.TransactionHash); err != nil {
+			log.Printf("❌ [IntentManager.WithdrawExecuted] Failed to record intent_manager_tx_hash: %v", err)
+			return err
+		}
+		withdrawRequest.IntentManagerTxHash = event.TransactionHash
+	}
+
 	log.Printf("✅ [IntentManager.WithdrawExecuted] Found matching WithdrawRequest: ID=%s, current_payout_status=%s",
 		withdrawRequest.ID, withdrawRequest.PayoutStatus)
 
@@ -929,46 +1062,35 @@ func (p *BlockchainEventProcessor) ProcessIntentManagerWithdrawExecuted(event *c
 	if event.EventData.Success {
 		// Update to completed
 		blockNumber := uint64(event.BlockNumber)
-		if err := p.updateWithdrawRequestPayoutStatus(&withdrawRequest, models.PayoutStatusCompleted,
-			event.TransactionHash, &blockNumber, ""); err != nil {
+		// payout_tx_hash is left unchanged: the event TX hash is the IntentManager TX, recorded above
+		if err := p.updateWithdrawRequestPayoutStatus(withdrawRequest, models.PayoutStatusCompleted,
+			"", &blockNumber, "", "IntentManager.WithdrawExecuted"); err != nil {
 			log.Printf("❌ [IntentManager.WithdrawExecuted] Failed to update payout status: %v", err)
 			return err
 		}
 
-		// Update main status
-		withdrawRequest.PayoutStatus = models.PayoutStatusCompleted
-		withdrawRequest.UpdateMainStatus()
-		if err := p.db.Save(&withdrawRequest).Error; err != nil {
-			log.Printf("❌ [IntentManager.WithdrawExecuted] Failed to update main status: %v", err)
-			return err
-		}
-
 		log.Printf("✅ [IntentManager.WithdrawExecuted] Payout status updated to completed: ID=%s", withdrawRequest.ID)
 		// Push WebSocket update for WithdrawRequest status change
 		if p.pushService != nil {
-			p.pushService.PushWithdrawRequestStatusUpdateDirect(&withdrawRequest, "", "IntentManager.WithdrawExecuted")
+			p.pushService.PushWithdrawRequestStatusUpdateDirect(withdrawRequest, "", "IntentManager.WithdrawExecuted")
 		}
 	} else {
 		// Update to failed
-		if err := p.updateWithdrawRequestPayoutStatus(&withdrawRequest, models.PayoutStatusFailed,
-			event.TransactionHash, nil, event.EventData.Message); err != nil {
+		if err := p.updateWithdrawRequestPayoutStatus(withdrawRequest, models.PayoutStatusFailed,
+			"", nil, event.EventData.Message, "IntentManager.WithdrawExecuted"); err != nil {
 			log.Printf("❌ [IntentManager.WithdrawExecuted] Failed to update payout status: %v", err)
 			return err
 		}
-
-		// Update main status
-		withdrawRequest.PayoutStatus = models.PayoutStatusFailed
-		withdrawRequest.UpdateMainStatus()
-		if err := p.db.Save(&withdrawRequest).Error; err != nil {
-			log.Printf("❌ [IntentManager.WithdrawExecuted] Failed to update main status: %v", err)
-			return err
-		}
+		p.appendRetryHistory(withdrawRequest.ID, models.RetryHistoryEntry{
+			Stage: models.RetryStagePayout, Attempt: withdrawRequest.PayoutRetryCount,
+			Error: event.EventData.Message, Trigger: "IntentManager.WithdrawExecuted",
+		})
 
 		log.Printf("⚠️ [IntentManager.WithdrawExecuted] Payout status updated to failed: ID=%s, Message=%s",
 			withdrawRequest.ID, event.EventData.Message)
 		// Push WebSocket update for WithdrawRequest status change
 		if p.pushService != nil {
-			p.pushService.PushWithdrawRequestStatusUpdateDirect(&withdrawRequest, "", "IntentManager.WithdrawExecuted")
+			p.pushService.PushWithdrawRequestStatusUpdateDirect(withdrawRequest, "", "IntentManager.WithdrawExecuted")
 		}
 	}
 
@@ -976,39 +1098,71 @@ func (p *BlockchainEventProcessor) ProcessIntentManagerWithdrawExecuted(event *c
 	return nil
 }
 
-// updateWithdrawRequestPayoutStatus updates the payout status of a WithdrawRequest
+// findIntentManagerWithdrawRequest finds the WithdrawRequest for an IntentManager.WithdrawExecuted event
+// The time-window fallback only matches when exactly one request is processing, so concurrent
+// payouts are never cross-matched; ambiguous events return gorm.ErrRecordNotFound.
+func (p *BlockchainEventProcessor) findIntentManagerWithdrawRequest(txHash string) (*models.WithdrawRequest, error) {
+	ctx := context.Background()
+
+	request, err := p.withdrawRequestRepo.GetByIntentManagerTxHash(ctx, txHash)
+	if err != gorm.ErrRecordNotFound {
+		return request, err
+	}
+
+	request, err = p.withdrawRequestRepo.GetByPayoutTxHash(ctx, txHash)
+	if err != gorm.ErrRecordNotFound {
+		return request, err
+	}
+
+	log.Printf("⚠️ [IntentManager.WithdrawExecuted] No WithdrawRequest found with intent_manager_tx_hash/payout_tx_hash=%s, trying alternative matching", txHash)
+
+	// Find requests with payout_status=processing that are recent (within last 24 hours)
+	// and not yet associated with another IntentManager TX
+	var candidates []models.WithdrawRequest
+	if err := p.db.Where("payout_status = ? AND updated_at > ? AND (intent_manager_tx_hash IS NULL OR intent_manager_tx_hash = '')",
+		models.PayoutStatusProcessing,
+		time.Now().Add(-24*time.Hour)).Limit(2).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, gorm.ErrRecordNotFound
+	case 1:
+		return &candidates[0], nil
+	default:
+		log.Printf("⚠️ [IntentManager.WithdrawExecuted] Multiple processing WithdrawRequests found, cannot match TX %s unambiguously", txHash)
+		return nil, gorm.ErrRecordNotFound
+	}
+}
+
+// updateWithdrawRequestPayoutStatus updates the payout status of a WithdrawRequest and recomputes its main status
+// On success *request is the saved row (a failure increments payout_retry_count).
 func (p *BlockchainEventProcessor) updateWithdrawRequestPayoutStatus(
 	request *models.WithdrawRequest,
 	status models.PayoutStatus,
 	txHash string,
 	blockNumber *uint64,
 	errMsg string,
+	triggerContext string,
 ) error {
-	updates := map[string]interface{}{
-		"payout_status": status,
-	}
-
-	if txHash != "" {
-		updates["payout_tx_hash"] = txHash
-	}
-
-	if status == models.PayoutStatusCompleted {
-		updates["payout_completed_at"] = gorm.Expr("NOW()")
-		if blockNumber != nil {
-			updates["payout_block_number"] = *blockNumber
+	return p.saveWithdrawRequest(p.db, request, triggerContext, func(fresh *models.WithdrawRequest) {
+		now := time.Now()
+		fresh.PayoutStatus = status
+		if txHash != "" {
+			fresh.PayoutTxHash = txHash
+		}
+		if status == models.PayoutStatusCompleted {
+			fresh.PayoutCompletedAt = &now
+			if blockNumber != nil {
+				fresh.PayoutBlockNumber = blockNumber
+			}
+		} else if status == models.PayoutStatusFailed {
+			fresh.PayoutError = errMsg
+			fresh.PayoutLastRetryAt = &now
+			fresh.PayoutRetryCount++
 		}
-	} else if status == models.PayoutStatusFailed {
-		updates["payout_error"] = errMsg
-		updates["payout_last_retry_at"] = gorm.Expr("NOW()")
-		// Increment retry count
-		p.db.Model(&models.WithdrawRequest{}).
-			Where("id = ?", request.ID).
-			UpdateColumn("payout_retry_count", gorm.Expr("payout_retry_count + 1"))
-	}
-
-	return p.db.Model(&models.WithdrawRequest{}).
-		Where("id = ?", request.ID).
-		Updates(updates).Error
+	})
 }
 
 // ============ event ============
@@ -1068,32 +1222,21 @@ func (p *BlockchainEventProcessor) createOrUpdateCheckbook(event *clients.EventD
 
 		// ifGrossAmountempty，DepositReceivedAmount（Convert）
 		updates := map[string]interface{}{}
-		if existingCheckbook.GrossAmount == "" && event.EventData.Amount != "" {
-			// 💱 Convert：amountConvertcontractamount（18）
-			// DepositReceivedTokenIdSet0，WaitDepositRecordedUpdateTokenId
-			managementAmount, convErr := p.decimalConverter.ConvertToManagementAmount(
-				event.EventData.Amount,
-				event.ChainID,
-				0, // DepositReceivedUse0，WaitDepositRecordedUpdate
-			)
-			if convErr != nil {
-				log.Printf("❌ [Convertfailed] %v，useamount", convErr)
-				managementAmount = event.EventData.Amount
-			} else {
-				// recordConvert
-				p.decimalConverter.LogConversion(
-					event.EventData.Amount,
-					managementAmount,
-					event.ChainID,
-					0, // DepositReceivedUseTokenId=0
-					"to_management",
-				)
-			}
-
+		if existingCheckbook.GrossAmount == "" || existingCheckbook.GrossAmount == "0" {
+			managementAmount := p.depositReceivedManagementAmount(event)
 			updates["gross_amount"] = managementAmount
 			log.Printf("🔧 [data] GrossAmount: %s (Convert: %s)", event.EventData.Amount, managementAmount)
 		}
 
+		// DepositRecorded normally arrives after DepositReceived and sets token_key; existing values are never blanked.
+		// If it arrived first without the checkbook getting a token_key, backfill it from the recorded event.
+		if existingCheckbook.TokenKey == "" {
+			if tokenKey := p.findRecordedTokenKey(event.ChainID, event.EventData.LocalDepositId); tokenKey != "" {
+				updates["token_key"] = tokenKey
+				log.Printf("🔧 [data] TokenKey backfilled from DepositRecorded: %s", tokenKey)
+			}
+		}
+
 		// Update（if）
 		if len(updates) > 0 {
 			if err := p.db.Model(&existingCheckbook).Updates(updates).Error; err != nil {
@@ -1113,26 +1256,7 @@ func (p *BlockchainEventProcessor) createOrUpdateCheckbook(event *clients.EventD
 		return fmt.Errorf("queryCheckbookfailed: %w", err)
 	}
 
-	// 💱 Convert：amountConvertcontractamount（18）
-	// DepositReceivedTokenIdSet0，WaitDepositRecordedUpdateTokenId
-	managementAmount, err := p.decimalConverter.ConvertToManagementAmount(
-		event.EventData.Amount,
-		event.ChainID,
-		0, // DepositReceivedUse0，WaitDepositRecordedUpdate
-	)
-	if err != nil {
-		log.Printf("❌ [Convertfailed] %v，useamount", err)
-		managementAmount = event.EventData.Amount
-	} else {
-		// recordConvert
-		p.decimalConverter.LogConversion(
-			event.EventData.Amount,
-			managementAmount,
-			event.ChainID,
-			0, // DepositReceivedUseTokenId=0
-			"to_management",
-		)
-	}
+	managementAmount := p.depositReceivedManagementAmount(event)
 
 	// Checkbookexists，Create
 	newCheckbook := &models.Checkbook{
@@ -1176,7 +1300,6 @@ func (p *BlockchainEventProcessor) createOrUpdateCheckbook(event *clients.EventD
 		log.Printf("✅ [success] CreateCheckbooksuccess!")
 		log.Printf("⚠️ [DepositReceived] pushservicenotinitialize，WebSocketpush")
 	}
-
 	log.Printf("   ID=%s, ChainID=%d, LocalDepositId=%d, Status=%s, User=%s",
 		newCheckbook.ID, newCheckbook.SLIP44ChainID, newCheckbook.LocalDepositID, newCheckbook.Status, userAddress.Data)
 
@@ -1184,7 +1307,9 @@ func (p *BlockchainEventProcessor) createOrUpdateCheckbook(event *clients.EventD
 }
 
 // updateCheckbookToReadyForCommitment DepositRecordedeventUpdateCheckbookstatus
-func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(event *clients.EventDepositRecordedResponse) error {
+// Writes go through tx; returns the updated checkbook's ID and previous status for the caller to push after commit
+// (empty ID when a checkbook was created instead).
+func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(tx *gorm.DB, event *clients.EventDepositRecordedResponse) (string, string, error) {
 	log.Printf("📋 [CheckbookUpdate] startprocess...")
 	log.Printf("🔍 [query] ChainID=%d, LocalDepositID=%d Checkbook",
 		event.ChainID, event.EventData.LocalDepositId)
@@ -1193,7 +1318,7 @@ func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(event *cl
 
 	//  chainid + local_deposit_id corresponding toCheckbookrecord
 	var checkbook models.Checkbook
-	err := p.db.Where("chain_id = ? AND local_deposit_id = ?",
+	err := tx.Where("chain_id = ? AND local_deposit_id = ?",
 		event.ChainID, event.EventData.LocalDepositId).First(&checkbook).Error
 
 	if err == gorm.ErrRecordNotFound {
@@ -1202,20 +1327,17 @@ func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(event *cl
 		log.Printf("📝 [Createrecord] needDepositRecordedeventCreateCheckbook")
 
 		// if，DepositRecordedeventCreateCheckbook
-		return p.createCheckbookFromDepositRecorded(event)
+		return "", "", p.createCheckbookFromDepositRecorded(tx, event)
 	} else if err != nil {
 		log.Printf("❌ [queryerror] queryCheckbookfailed: %v", err)
-		return fmt.Errorf("queryCheckbookfailed: %w", err)
+		return "", "", fmt.Errorf("queryCheckbookfailed: %w", err)
 	}
 
 	log.Printf("✅ [record] Checkbook ID=%s, currentstatus=%s", checkbook.ID, checkbook.Status)
 
-	// Ensure tokenKey mapper is initialized
-	utils.InitTokenKeyHashMap()
-
 	// Convert tokenKey hash to original string (e.g., "USDT")
 	log.Printf("🔍 [updateCheckbookToReadyForCommitment] Converting tokenKey hash: %s", event.EventData.TokenKey)
-	originalTokenKey := utils.GetTokenKeyFromHash(event.EventData.TokenKey)
+	originalTokenKey := resolveTokenKey(event.EventData.TokenKey, "updateCheckbookToReadyForCommitment")
 	log.Printf("🔍 [updateCheckbookToReadyForCommitment] Converted tokenKey: %s", originalTokenKey)
 
 	// UpdateDepositRecordedevent，user_data
@@ -1231,18 +1353,18 @@ func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(event *cl
 		// Convert 20-byte EVM address to 32-byte Universal Address
 		universalAddr, err := utils.EvmToUniversalAddress(normalizedAddress)
 		if err != nil {
-			return fmt.Errorf("failed to convert EVM address to Universal Address: %w", err)
+			return "", "", fmt.Errorf("failed to convert EVM address to Universal Address: %w", err)
 		}
 		universalAddressData = universalAddr
 	} else if utils.IsTronAddress(normalizedAddress) {
 		// Convert TRON Base58 address to 32-byte Universal Address
 		universalAddr, err := utils.TronToUniversalAddress(normalizedAddress)
 		if err != nil {
-			return fmt.Errorf("failed to convert TRON address to Universal Address: %w", err)
+			return "", "", fmt.Errorf("failed to convert TRON address to Universal Address: %w", err)
 		}
 		universalAddressData = universalAddr
 	} else {
-		return fmt.Errorf("unsupported address format: %s", normalizedAddress)
+		return "", "", fmt.Errorf("unsupported address format: %s", normalizedAddress)
 	}
 
 	// Log event data before creating updates map
@@ -1304,28 +1426,16 @@ func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(event *cl
 		log.Printf("   → %s = %v", key, value)
 	}
 
-	// Update - Usepushservice
-	if p.dbWithPush != nil {
-		log.Printf("🔄 [DepositRecorded] Using push service to update checkbook...")
-		if err := p.dbWithPush.UpdateCheckbook(checkbook.ID, updates, "DepositRecorded"); err != nil {
-			log.Printf("❌ [DepositRecorded] UpdateCheckbookfailed: %v", err)
-			return fmt.Errorf("UpdateCheckbookfailed: %w", err)
-		}
-		log.Printf("✅ [DepositRecorded] CheckbookUpdatesuccessalreadypush: ID=%s", checkbook.ID)
-	} else {
-		// ：UpdateDatabase
-		log.Printf("🔄 [DepositRecorded] Using direct database update...")
-		if err := p.db.Model(&checkbook).Updates(updates).Error; err != nil {
-			log.Printf("❌ [DepositRecorded] UpdateCheckbookfailed: %v", err)
-			return fmt.Errorf("UpdateCheckbookfailed: %w", err)
-		}
-		log.Printf("✅ [DepositRecorded] CheckbookUpdatesuccess: ID=%s", checkbook.ID)
-		log.Printf("⚠️ pushservicenotinitialize，push")
+	// Update inside the caller's transaction, the push is sent by the caller after commit
+	if err := tx.Model(&checkbook).Updates(updates).Error; err != nil {
+		log.Printf("❌ [DepositRecorded] UpdateCheckbookfailed: %v", err)
+		return "", "", fmt.Errorf("UpdateCheckbookfailed: %w", err)
 	}
+	log.Printf("✅ [DepositRecorded] CheckbookUpdatesuccess: ID=%s", checkbook.ID)
 
 	// Verify the update by querying the checkbook again
 	var updatedCheckbook models.Checkbook
-	if err := p.db.Where("id = ?", checkbook.ID).First(&updatedCheckbook).Error; err == nil {
+	if err := tx.Where("id = ?", checkbook.ID).First(&updatedCheckbook).Error; err == nil {
 		log.Printf("✅ [DepositRecorded] Verification - Checkbook ID=%s, Status=%s, AllocatableAmount=%s, FeeTotalLocked=%s",
 			updatedCheckbook.ID, updatedCheckbook.Status, updatedCheckbook.AllocatableAmount, updatedCheckbook.FeeTotalLocked)
 	} else {
@@ -1335,13 +1445,72 @@ func (p *BlockchainEventProcessor) updateCheckbookToReadyForCommitment(event *cl
 	log.Printf("   Update: gross_amount=%s, allocatable_amount=%s, fee_total_locked=%s, promote_code=%s, token_key=%s",
 		event.EventData.GrossAmount, event.EventData.AllocatableAmount, event.EventData.FeeTotalLocked, event.EventData.PromoteCode, originalTokenKey)
 
-	return nil
+	return checkbook.ID, string(checkbook.Status), nil
+}
+
+// pushCheckbookUpdate pushes a checkbook's committed state to WebSocket clients (no-op without push service or ID)
+func (p *BlockchainEventProcessor) pushCheckbookUpdate(checkbookID, oldStatus, context string) {
+	if p.pushService == nil || checkbookID == "" {
+		return
+	}
+	var checkbook models.Checkbook
+	if err := p.db.First(&checkbook, "id = ?", checkbookID).Error; err != nil {
+		log.Printf("⚠️ [%s] Failed to load Checkbook %s for push: %v", context, checkbookID, err)
+		return
+	}
+	p.pushService.PushCheckbookStatusUpdateDirect(&checkbook, oldStatus, context)
+}
+
+// depositReceivedManagementAmount converts the DepositReceived amount to management units (18 decimals)
+// DepositReceived has no TokenId yet (set by DepositRecorded), so it converts by token address.
+// A missing amount becomes "0" so checkbook amounts are never stored empty; a failed conversion keeps the raw amount.
+func (p *BlockchainEventProcessor) depositReceivedManagementAmount(event *clients.EventDepositReceivedResponse) string {
+	managementAmount, err := p.decimalConverter.ConvertToManagementAmountByAddress(
+		event.EventData.Amount,
+		event.ChainID,
+		event.EventData.Token,
+	)
+	if errors.Is(err, utils.ErrEmptyAmount) {
+		log.Printf("⚠️ [Convert] DepositReceived without amount (ChainID=%d, LocalDepositId=%d), storing 0",
+			event.ChainID, event.EventData.LocalDepositId)
+		return "0"
+	}
+	if err != nil {
+		log.Printf("❌ [Convertfailed] %v，useamount", err)
+		return event.EventData.Amount
+	}
+	return managementAmount
+}
+
+// findRecordedTokenKey returns the token key of an already processed DepositRecorded event for the deposit, "" if none
+func (p *BlockchainEventProcessor) findRecordedTokenKey(chainID int64, localDepositID uint64) string {
+	var recorded models.EventDepositRecorded
+	err := p.db.Where("chain_id = ? AND local_deposit_id = ? AND token_key <> ''", chainID, localDepositID).
+		Order("id DESC").First(&recorded).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("⚠️ [findRecordedTokenKey] Failed to query DepositRecorded for ChainID=%d, LocalDepositId=%d: %v", chainID, localDepositID, err)
+		}
+		return ""
+	}
+	return recorded.TokenKey
+}
+
+// resolveTokenKey converts an indexed tokenKey hash to its key (built-in map + tokens.tokenKeys)
+// A hash no known key maps to is logged and returned unchanged; verify-tokenkeys lists the affected checkbooks.
+func resolveTokenKey(hash string, caller string) string {
+	utils.RegisterTokenKeys(config.GetTokenKeys())
+	tokenKey, ok := utils.LookupTokenKey(hash)
+	if !ok {
+		log.Printf("⚠️ [%s] Unknown tokenKey hash %s, storing %q as token_key (add the key to tokens.tokenKeys)", caller, hash, tokenKey)
+	}
+	return tokenKey
 }
 
 // createCheckbookFromDepositRecorded DepositRecordedeventCreateCheckbook
-func (p *BlockchainEventProcessor) createCheckbookFromDepositRecorded(event *clients.EventDepositRecordedResponse) error {
+func (p *BlockchainEventProcessor) createCheckbookFromDepositRecorded(tx *gorm.DB, event *clients.EventDepositRecordedResponse) error {
 	// Convert tokenKey hash to original string (e.g., "USDT")
-	originalTokenKey := utils.GetTokenKeyFromHash(event.EventData.TokenKey)
+	originalTokenKey := resolveTokenKey(event.EventData.TokenKey, "createCheckbookFromDepositRecorded")
 
 	// useraddress - Event data should already be in Universal Address format (32-byte)
 	normalizedAddress := utils.NormalizeAddressForChain(strings.TrimSpace(event.EventData.Owner.Data), int(event.ChainID))
@@ -1395,7 +1564,7 @@ func (p *BlockchainEventProcessor) createCheckbookFromDepositRecorded(event *cli
 	}
 
 	log.Printf("💾 [] startDatabase...")
-	if err := p.db.Create(newCheckbook).Error; err != nil {
+	if err := tx.Create(newCheckbook).Error; err != nil {
 		log.Printf("❌ [failed] CreateCheckbookfailed: %v", err)
 		return fmt.Errorf("CreateCheckbookfailed: %w", err)
 	}
@@ -1460,59 +1629,28 @@ func (p *BlockchainEventProcessor) processWithdrawRequestedCheck(event *clients.
 func (p *BlockchainEventProcessor) processWithdrawExecutedCheck(event *clients.EventWithdrawExecutedResponse) error {
 	requestId := event.EventData.RequestId
 
-	// Step 1: Find WithdrawRequest by withdraw_nullifier or request_id (DEPRECATED)
-	var withdrawRequest models.WithdrawRequest
-	// 优先通过 withdraw_nullifier 查询
-	err := p.db.Where("withdraw_nullifier = ?", requestId).First(&withdrawRequest).Error
+	// Step 1: Find the WithdrawRequest, or the Checks the requestId was recorded on
+	match, err := p.findWithdrawRequestForEvent(requestId, true)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			// Fallback: 尝试通过 request_id (DEPRECATED) 查询
-			log.Printf("🔍 [WithdrawExecuted] WithdrawRequest not found by withdraw_nullifier, trying request_id (DEPRECATED): RequestId=%s", requestId)
-			err = p.db.Where("request_id = ?", requestId).First(&withdrawRequest).Error
-			if err != nil {
-				if err == gorm.ErrRecordNotFound {
-					log.Printf("⚠️ [WithdrawExecuted] WithdrawRequest not found by withdraw_nullifier or request_id: RequestId=%s", requestId)
-
-					// Fallback 1: Try to find Check by nullifier field (commitment nullifier)
-					log.Printf("🔍 [WithdrawExecuted] Trying to find Check by nullifier field: %s", requestId)
-					var checksByNullifier []models.Check
-					err = p.db.Where("nullifier = ?", requestId).Find(&checksByNullifier).Error
-					if err == nil && len(checksByNullifier) > 0 {
-						log.Printf("✅ [WithdrawExecuted] Found %d Checks by nullifier field", len(checksByNullifier))
-						// 尝试通过 Check 的 withdraw_request_id 更新 WithdrawRequest 状态
-						if err := p.updateWithdrawRequestFromChecks(checksByNullifier, event); err != nil {
-							log.Printf("⚠️ [WithdrawExecuted] Failed to update WithdrawRequest from Checks: %v", err)
-						}
-						return p.updateChecksAndPushCheckbook(checksByNullifier, event)
-					}
-
-					// Fallback 2: Try to find Check by deprecated request_id field (for backward compatibility)
-					log.Printf("🔍 [WithdrawExecuted] Trying to find Check by deprecated request_id field: %s", requestId)
-					var checksByRequestID []models.Check
-					err = p.db.Where("request_id = ?", requestId).Find(&checksByRequestID).Error
-					if err == nil && len(checksByRequestID) > 0 {
-						log.Printf("✅ [WithdrawExecuted] Found %d Checks by request_id field", len(checksByRequestID))
-						// 尝试通过 Check 的 withdraw_request_id 更新 WithdrawRequest 状态
-						if err := p.updateWithdrawRequestFromChecks(checksByRequestID, event); err != nil {
-							log.Printf("⚠️ [WithdrawExecuted] Failed to update WithdrawRequest from Checks: %v", err)
-						}
-						return p.updateChecksAndPushCheckbook(checksByRequestID, event)
-					}
-
-					log.Printf("⚠️ [WithdrawExecuted] Check not found by any method: RequestId=%s", requestId)
-					return nil // Not an error, may be user-initiated withdraw or fee
-				}
-				log.Printf("❌ [WithdrawExecuted] Query WithdrawRequest by request_id failed: %v", err)
-				return fmt.Errorf("query WithdrawRequest failed: %w", err)
-			}
-			// Found by request_id, continue below
-		} else {
-			log.Printf("❌ [WithdrawExecuted] Query WithdrawRequest failed: %v", err)
-			return fmt.Errorf("query WithdrawRequest failed: %w", err)
+		log.Printf("❌ [WithdrawExecuted] Query WithdrawRequest failed: %v", err)
+		return err
+	}
+	if match.request == nil {
+		if len(match.checks) == 0 {
+			log.Printf("⚠️ [WithdrawExecuted] WithdrawRequest and Check not found: RequestId=%s, match_by_deprecated_request_id=%v",
+				requestId, p.matchByDeprecatedRequestID)
+			return nil // Not an error, may be user-initiated withdraw or fee
+		}
+		log.Printf("✅ [WithdrawExecuted] Found %d Checks by %s", len(match.checks), match.matchedBy)
+		// 尝试通过 Check 的 withdraw_request_id 更新 WithdrawRequest 状态
+		if err := p.updateWithdrawRequestFromChecks(match.checks, event); err != nil {
+			log.Printf("⚠️ [WithdrawExecuted] Failed to update WithdrawRequest from Checks: %v", err)
 		}
+		return p.updateChecksAndPushCheckbook(match.checks, event)
 	}
+	withdrawRequest := match.request
 
-	log.Printf("✅ [WithdrawExecuted] Found WithdrawRequest: ID=%s", withdrawRequest.ID)
+	log.Printf("✅ [WithdrawExecuted] Found WithdrawRequest by %s: ID=%s", match.matchedBy, withdrawRequest.ID)
 
 	// Step 2: Find all Checks associated with this WithdrawRequest
 	var checks []models.Check
@@ -1522,6 +1660,10 @@ func (p *BlockchainEventProcessor) processWithdrawExecutedCheck(event *clients.E
 		return fmt.Errorf("queryCheckfailed: %w", err)
 	}
 
+	if len(checks) == 0 && !p.matchByDeprecatedRequestID {
+		log.Printf("⚠️ [WithdrawExecuted] No Checks found for WithdrawRequest ID=%s", withdrawRequest.ID)
+		return nil
+	}
 	if len(checks) == 0 {
 		log.Printf("⚠️ [WithdrawExecuted] No Checks found for WithdrawRequest ID=%s, trying deprecated request_id field", withdrawRequest.ID)
 		// Fallback: Try to find by deprecated request_id field
@@ -1568,43 +1710,51 @@ func (p *BlockchainEventProcessor) updateWithdrawRequestFromChecks(checks []mode
 
 		log.Printf("✅ [WithdrawExecuted] Found WithdrawRequest by Check's withdraw_request_id: ID=%s", requestID)
 
+		if event.TransactionHash == "" {
+			p.flagPayoutForManualReview(&withdrawRequest, event.EventData.RequestId)
+			continue
+		}
+
 		// 更新状态（与 ProcessWithdrawExecuted 中的逻辑一致）
 		blockNumber := uint64(event.BlockNumber)
 		chainID := uint32(event.ChainID)
 
-		updates := map[string]interface{}{
-			"execute_status":      models.ExecuteStatusSuccess,
-			"payout_status":       models.PayoutStatusCompleted,
-			"payout_chain_id":     chainID,
-			"payout_tx_hash":      event.TransactionHash,
-			"payout_block_number": blockNumber,
-			"payout_completed_at": gorm.Expr("NOW()"),
-		}
-
-		// Only update execute fields if they are not already set
-		if withdrawRequest.ExecuteTxHash == "" {
-			updates["execute_tx_hash"] = event.TransactionHash
-			updates["execute_block_number"] = blockNumber
-			updates["execute_chain_id"] = chainID
-		}
-
-		if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
+		if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "WithdrawExecuted", func(fresh *models.WithdrawRequest) {
+			now := time.Now()
+			fresh.ExecuteStatus = models.ExecuteStatusSuccess
+			fresh.PayoutStatus = models.PayoutStatusCompleted
+			fresh.PayoutChainID = &chainID
+			fresh.PayoutTxHash = event.TransactionHash
+			fresh.PayoutBlockNumber = &blockNumber
+			fresh.PayoutCompletedAt = &now
+
+			// Only update execute fields if they are not already set
+			if fresh.ExecuteTxHash == "" {
+				fresh.ExecuteTxHash = event.TransactionHash
+				fresh.ExecuteBlockNumber = &blockNumber
+				fresh.ExecuteChainID = &chainID
+			}
+		}); err != nil {
 			log.Printf("❌ [WithdrawExecuted] Failed to update WithdrawRequest: %v", err)
 			continue
 		}
 
-		// 更新主状态
-		withdrawRequest.UpdateMainStatus()
-		if err := p.db.Model(&withdrawRequest).Update("status", withdrawRequest.Status).Error; err != nil {
-			log.Printf("⚠️ [WithdrawExecuted] Failed to update main status: %v", err)
-		}
-
 		log.Printf("✅ [WithdrawExecuted] Updated WithdrawRequest status: ID=%s, Status=%s", requestID, withdrawRequest.Status)
 	}
 
 	return nil
 }
 
+// flagPayoutForManualReview marks a request payout_needs_manual_review for a WithdrawExecuted event without a
+// transaction hash; payout_status is left as is rather than completed with an empty payout_tx_hash
+func (p *BlockchainEventProcessor) flagPayoutForManualReview(withdrawRequest *models.WithdrawRequest, requestID string) {
+	p.logger.Error("[WithdrawExecuted] TransactionHash is empty, payout not marked completed, flagging payout_needs_manual_review",
+		"withdraw_request_id", withdrawRequest.ID, "request_id", requestID, "payout_status", withdrawRequest.PayoutStatus)
+	if err := p.db.Model(withdrawRequest).Update("payout_needs_manual_review", true).Error; err != nil {
+		p.logger.Error("[WithdrawExecuted] Failed to flag payout_needs_manual_review", "withdraw_request_id", withdrawRequest.ID, "error", err)
+	}
+}
+
 // updateChecksAndPushCheckbook updates Checks to 'used' status and pushes Checkbook updates
 func (p *BlockchainEventProcessor) updateChecksAndPushCheckbook(checks []models.Check, event *clients.EventWithdrawExecutedResponse) error {
 
@@ -1663,6 +1813,11 @@ func (p *BlockchainEventProcessor) updateChecksAndPushCheckbook(checks []models.
 
 // getStatusProgression Getstatus
 func (p *BlockchainEventProcessor) getStatusProgression() map[models.CheckbookStatus]int {
+	return CheckbookStatusProgression()
+}
+
+// CheckbookStatusProgression level of each forward Checkbook status; statuses not in the map (failed, DELETED) rank 0
+func CheckbookStatusProgression() map[models.CheckbookStatus]int {
 	return map[models.CheckbookStatus]int{
 		models.CheckbookStatusPending:              1,
 		models.CheckbookStatusUnsigned:             2,
@@ -1674,13 +1829,49 @@ func (p *BlockchainEventProcessor) getStatusProgression() map[models.CheckbookSt
 	}
 }
 
+// CheckbookEventEvidence which on-chain events have been stored for one checkbook's deposit
+type CheckbookEventEvidence struct {
+	DepositRecorded       bool // EventDepositRecorded for (chain_id, local_deposit_id)
+	DepositUsed           bool // EventDepositUsed for (chain_id, local_deposit_id)
+	CommitmentRootUpdated bool // EventCommitmentRootUpdated for the checkbook's commitment
+}
+
+// DeriveCheckbookStatus the status the event processor would have moved a checkbook to for the given events
+// Returns "" when no event implies a status. Mirrors ProcessDepositRecorded (ready_for_commitment)
+// and ProcessDepositUsed / ProcessCommitmentRootUpdated (with_checkbook).
+func DeriveCheckbookStatus(evidence CheckbookEventEvidence) models.CheckbookStatus {
+	switch {
+	case evidence.DepositUsed || evidence.CommitmentRootUpdated:
+		return models.CheckbookStatusWithCheckbook
+	case evidence.DepositRecorded:
+		return models.CheckbookStatusReadyForCommitment
+	default:
+		return ""
+	}
+}
+
+// IsCheckbookStatusAhead reports whether target is further along the progression than current,
+// the same rule advanceCheckbookStatus applies before updating
+func IsCheckbookStatusAhead(current, target models.CheckbookStatus) bool {
+	progression := CheckbookStatusProgression()
+	return progression[target] > progression[current]
+}
+
 // advanceCheckbookStatus Checkbookstatus（ifcurrentstatus）
+// The update is conditional on checkbook.Version; on a version conflict the checkbook is reloaded and the
+// progression re-checked, so a concurrent event can never move the status backwards.
 func (p *BlockchainEventProcessor) advanceCheckbookStatus(checkbook *models.Checkbook, targetStatus models.CheckbookStatus, context string) (bool, error) {
 	statusProgression := p.getStatusProgression()
-	currentLevel := statusProgression[checkbook.Status]
 	targetLevel := statusProgression[targetStatus]
 
-	if currentLevel < targetLevel {
+	for attempt := 1; ; attempt++ {
+		currentLevel := statusProgression[checkbook.Status]
+		if currentLevel >= targetLevel {
+			log.Printf("ℹ️ [%s] status: current=%s（%d） >= target=%s（%d）",
+				context, checkbook.Status, currentLevel, targetStatus, targetLevel)
+			return false, nil
+		}
+
 		oldStatus := checkbook.Status
 
 		// UsepushserviceUpdatestatus
@@ -1689,28 +1880,37 @@ func (p *BlockchainEventProcessor) advanceCheckbookStatus(checkbook *models.Chec
 			"updated_at": time.Now(),
 		}
 
+		var err error
 		if p.dbWithPush != nil {
-			if err := p.dbWithPush.UpdateCheckbook(checkbook.ID, updates, context); err != nil {
-				log.Printf("❌ [%s] statusfailed: %v", context, err)
-				return false, fmt.Errorf("UpdateCheckbookstatusfailed: %w", err)
+			err = p.dbWithPush.UpdateCheckbookIfVersion(checkbook.ID, checkbook.Version, updates, context)
+		} else {
+			err = repository.UpdateIfVersion(p.db, &models.Checkbook{}, checkbook.ID, checkbook.Version, updates)
+		}
+
+		if errors.Is(err, repository.ErrVersionConflict) && attempt < repository.MaxVersionConflictRetries {
+			log.Printf("⚠️ [%s] Checkbook %s modified concurrently (attempt %d/%d), reloading",
+				context, checkbook.ID, attempt, repository.MaxVersionConflictRetries)
+			if reloadErr := p.db.First(checkbook, "id = ?", checkbook.ID).Error; reloadErr != nil {
+				return false, fmt.Errorf("failed to reload checkbook %s: %w", checkbook.ID, reloadErr)
 			}
+			continue
+		}
+		if err != nil {
+			log.Printf("❌ [%s] statusfailed: %v", context, err)
+			return false, fmt.Errorf("UpdateCheckbookstatusfailed: %w", err)
+		}
+
+		checkbook.Status = targetStatus
+		checkbook.Version++
+		if p.dbWithPush != nil {
 			log.Printf("🔄 [%s] statussuccessalreadypush: %s → %s (ID=%s)", context, oldStatus, targetStatus, checkbook.ID)
 		} else {
-			// ：UpdateDatabase
-			checkbook.Status = targetStatus
-			if err := p.db.Save(checkbook).Error; err != nil {
-				log.Printf("❌ [%s] statusfailed: %v", context, err)
-				return false, fmt.Errorf("UpdateCheckbookstatusfailed: %w", err)
-			}
 			log.Printf("🔄 [%s] statussuccess: %s → %s (ID=%s)", context, oldStatus, targetStatus, checkbook.ID)
 			log.Printf("⚠️ [%s] pushservicenotinitialize，push", context)
 		}
 
+		p.recordStatusTransition(models.StatusTransitionEntityCheckbook, checkbook.ID, string(oldStatus), string(targetStatus), context)
 		return true, nil
-	} else {
-		log.Printf("ℹ️ [%s] status: current=%s（%d） >= target=%s（%d）",
-			context, checkbook.Status, currentLevel, targetStatus, targetLevel)
-		return false, nil
 	}
 }
 
@@ -1750,6 +1950,7 @@ func (p *BlockchainEventProcessor) advanceCheckStatus(check *models.Check, targe
 			log.Printf("⚠️ [%s] pushservicenotinitialize，push", context)
 		}
 
+		p.recordStatusTransition(models.StatusTransitionEntityCheck, check.ID, string(oldStatus), string(targetStatus), context)
 		return true, nil
 	} else {
 		log.Printf("ℹ️ [%s] Checkstatus: current=%s（%d） >= target=%s（%d）",
@@ -1758,6 +1959,60 @@ func (p *BlockchainEventProcessor) advanceCheckStatus(check *models.Check, targe
 	}
 }
 
+// recordStatusTransition writes a status transition audit row (failures are logged, never returned)
+func (p *BlockchainEventProcessor) recordStatusTransition(entityType, entityID, fromStatus, toStatus, triggerContext string) {
+	if p.statusTransitionRepo == nil || fromStatus == toStatus {
+		return
+	}
+	transition := &models.StatusTransition{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		FromStatus:     fromStatus,
+		ToStatus:       toStatus,
+		TriggerContext: triggerContext,
+		CreatedAt:      time.Now(),
+	}
+	if err := p.statusTransitionRepo.Create(context.Background(), transition); err != nil {
+		log.Printf("⚠️ [%s] Failed to record status transition for %s %s (%s → %s): %v",
+			triggerContext, entityType, entityID, fromStatus, toStatus, err)
+	}
+}
+
+// saveWithdrawRequest applies apply (nil = no field change) to a freshly loaded WithdrawRequest, recomputes its main
+// status and saves it conditionally on its version; on a conflict the row is reloaded and apply runs again, so a
+// concurrent writer's changes are kept instead of overwritten. withdrawRequest is refreshed with the saved row.
+// An inconsistent sub-status combination is logged and not written (models.ErrInvalidSubStatuses is returned).
+// The repository records the main and sub-status transitions with triggerContext.
+func (p *BlockchainEventProcessor) saveWithdrawRequest(db *gorm.DB, withdrawRequest *models.WithdrawRequest, triggerContext string, apply func(fresh *models.WithdrawRequest)) error {
+	ctx := repository.WithTransitionTrigger(context.Background(), triggerContext)
+	saved, err := repository.NewWithdrawRequestRepository(db).Modify(ctx, withdrawRequest.ID, func(fresh *models.WithdrawRequest) error {
+		if apply != nil {
+			apply(fresh)
+		}
+		fresh.UpdateMainStatus()
+		if err := fresh.ValidateSubStatuses(); err != nil {
+			p.logger.Error("["+triggerContext+"] Refusing to save WithdrawRequest with inconsistent sub-statuses",
+				"withdraw_request_id", fresh.ID, "proof_status", fresh.ProofStatus,
+				"execute_status", fresh.ExecuteStatus, "payout_status", fresh.PayoutStatus,
+				"hook_status", fresh.HookStatus, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*withdrawRequest = *saved
+	return nil
+}
+
+// appendRetryHistory records a payout / hook failure in the request's retry_history (audit only, errors are logged)
+func (p *BlockchainEventProcessor) appendRetryHistory(withdrawRequestID string, entry models.RetryHistoryEntry) {
+	if err := repository.AppendWithdrawRetryHistory(p.db, withdrawRequestID, entry); err != nil {
+		log.Printf("⚠️ [RetryHistory] Failed to record %s attempt %d of %s: %v", entry.Stage, entry.Attempt, withdrawRequestID, err)
+	}
+}
+
 // ============ queue rootqueryinterface ============
 
 // GetCommitmentQueueInfo commitmentGetqueue rootInfoandsubsequentcommitment
@@ -1774,6 +2029,9 @@ func (p *BlockchainEventProcessor) GetCommitmentChainFromRoot(startRoot string)
 
 // ProcessPayoutExecuted processes Treasury.PayoutExecuted event
 func (p *BlockchainEventProcessor) ProcessPayoutExecuted(event *clients.EventPayoutExecutedResponse) error {
+	if !p.isChainSupported("PayoutExecuted", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessPayoutExecuted: Chain=%d, RequestId=%s, WorkerType=%d",
 		event.ChainID, event.EventData.RequestId, event.EventData.WorkerType)
 
@@ -1794,28 +2052,19 @@ func (p *BlockchainEventProcessor) ProcessPayoutExecuted(event *clients.EventPay
 	now := time.Now()
 	workerType := uint8(event.EventData.WorkerType)
 
-	updates := map[string]interface{}{
-		"payout_status":       models.PayoutStatusCompleted,
-		"payout_chain_id":     chainID, // Record chain ID where payout transaction was executed
-		"payout_tx_hash":      event.TransactionHash,
-		"payout_block_number": blockNumber,
-		"payout_completed_at": now,
-		"worker_type":         workerType,
-		"actual_output":       event.EventData.ActualOutput,
-		"payout_error":        "", // Clear error on success
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-		return fmt.Errorf("update WithdrawRequest failed: %w", err)
-	}
-
-	// Update main status
-	withdrawRequest.PayoutStatus = models.PayoutStatusCompleted
-	withdrawRequest.WorkerType = &workerType
-	withdrawRequest.ActualOutput = event.EventData.ActualOutput
-	withdrawRequest.UpdateMainStatus()
-	if err := p.db.Save(&withdrawRequest).Error; err != nil {
-		log.Printf("❌ [PayoutExecuted] Failed to update main status: %v", err)
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "PayoutExecuted", func(fresh *models.WithdrawRequest) {
+		// A payout implies executeWithdraw succeeded, its confirmation may not have been processed yet
+		fresh.ExecuteStatus = models.ExecuteStatusSuccess
+		fresh.PayoutStatus = models.PayoutStatusCompleted
+		fresh.PayoutChainID = &chainID // Record chain ID where payout transaction was executed
+		fresh.PayoutTxHash = event.TransactionHash
+		fresh.PayoutBlockNumber = &blockNumber
+		fresh.PayoutCompletedAt = &now
+		fresh.WorkerType = &workerType
+		fresh.ActualOutput = event.EventData.ActualOutput
+		fresh.PayoutError = "" // Clear error on success
+	}); err != nil {
+		log.Printf("❌ [PayoutExecuted] Failed to update WithdrawRequest: %v", err)
 		return err
 	}
 
@@ -1830,6 +2079,9 @@ func (p *BlockchainEventProcessor) ProcessPayoutExecuted(event *clients.EventPay
 // ProcessPayoutFailed processes Treasury.PayoutFailed event
 // ⭐ Simplified design: Payout failure → failed_permanent (waiting for manual resolution)
 func (p *BlockchainEventProcessor) ProcessPayoutFailed(event *clients.EventPayoutFailedResponse) error {
+	if !p.isChainSupported("PayoutFailed", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessPayoutFailed: Chain=%d, RequestId=%s, WorkerType=%d, Error=%s",
 		event.ChainID, event.EventData.RequestId, event.EventData.WorkerType, event.EventData.ErrorReason)
 
@@ -1844,16 +2096,18 @@ func (p *BlockchainEventProcessor) ProcessPayoutFailed(event *clients.EventPayou
 		return fmt.Errorf("query WithdrawRequest failed: %w", err)
 	}
 
-	// ⭐ Simplified design: Directly set to failed_permanent (waiting for manual resolution)
-	updates := map[string]interface{}{
-		"payout_status": models.PayoutStatusFailed,
-		"payout_error":  event.EventData.ErrorReason,
-		"status":        string(models.WithdrawStatusFailedPermanent), // ⭐ Directly set to failed_permanent
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
+	// ⭐ Simplified design: payout_status=failed maps to failed_permanent (waiting for manual resolution)
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "PayoutFailed", func(fresh *models.WithdrawRequest) {
+		fresh.ExecuteStatus = models.ExecuteStatusSuccess // the payout was attempted, so executeWithdraw succeeded
+		fresh.PayoutStatus = models.PayoutStatusFailed
+		fresh.PayoutError = event.EventData.ErrorReason
+	}); err != nil {
 		return fmt.Errorf("update WithdrawRequest failed: %w", err)
 	}
+	p.appendRetryHistory(withdrawRequest.ID, models.RetryHistoryEntry{
+		Stage: models.RetryStagePayout, Attempt: withdrawRequest.PayoutRetryCount + 1,
+		Error: event.EventData.ErrorReason, Trigger: "PayoutFailed",
+	})
 
 	log.Printf("⚠️ [PayoutFailed] Payout failed → failed_permanent (waiting for manual resolution): RequestId=%s, Error=%s",
 		event.EventData.RequestId, event.EventData.ErrorReason)
@@ -1862,6 +2116,9 @@ func (p *BlockchainEventProcessor) ProcessPayoutFailed(event *clients.EventPayou
 
 // ProcessHookExecuted processes IntentManager.HookExecuted event
 func (p *BlockchainEventProcessor) ProcessHookExecuted(event *clients.EventHookExecutedResponse) error {
+	if !p.isChainSupported("HookExecuted", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessHookExecuted: Chain=%d, RequestId=%s", event.ChainID, event.EventData.RequestId)
 
 	var withdrawRequest models.WithdrawRequest
@@ -1877,23 +2134,14 @@ func (p *BlockchainEventProcessor) ProcessHookExecuted(event *clients.EventHookE
 	// Update hook status to completed
 	now := time.Now()
 	chainID := uint32(event.ChainID) // SLIP44 chain ID where hook TX was executed
-	updates := map[string]interface{}{
-		"hook_status":       models.HookStatusCompleted,
-		"hook_chain_id":     chainID, // Record chain ID where hook transaction was executed
-		"hook_tx_hash":      event.TransactionHash,
-		"hook_completed_at": now,
-		"hook_error":        "", // Clear error on success
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-		return fmt.Errorf("update WithdrawRequest failed: %w", err)
-	}
-
-	// Update main status
-	withdrawRequest.HookStatus = models.HookStatusCompleted
-	withdrawRequest.UpdateMainStatus()
-	if err := p.db.Save(&withdrawRequest).Error; err != nil {
-		log.Printf("❌ [HookExecuted] Failed to update main status: %v", err)
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "HookExecuted", func(fresh *models.WithdrawRequest) {
+		fresh.HookStatus = models.HookStatusCompleted
+		fresh.HookChainID = &chainID // Record chain ID where hook transaction was executed
+		fresh.HookTxHash = event.TransactionHash
+		fresh.HookCompletedAt = &now
+		fresh.HookError = "" // Clear error on success
+	}); err != nil {
+		log.Printf("❌ [HookExecuted] Failed to update WithdrawRequest: %v", err)
 		return err
 	}
 
@@ -1907,6 +2155,9 @@ func (p *BlockchainEventProcessor) ProcessHookExecuted(event *clients.EventHookE
 
 // ProcessHookFailed processes IntentManager.HookFailed event
 func (p *BlockchainEventProcessor) ProcessHookFailed(event *clients.EventHookFailedResponse) error {
+	if !p.isChainSupported("HookFailed", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessHookFailed: Chain=%d, RequestId=%s", event.ChainID, event.EventData.RequestId)
 
 	var withdrawRequest models.WithdrawRequest
@@ -1921,26 +2172,22 @@ func (p *BlockchainEventProcessor) ProcessHookFailed(event *clients.EventHookFai
 
 	// Update hook status to failed (even on failure, record the transaction hash)
 	chainID := uint32(event.ChainID) // SLIP44 chain ID where hook TX was executed
-	updates := map[string]interface{}{
-		"hook_status":   models.HookStatusFailed,
-		"hook_chain_id": chainID,               // Record chain ID where hook transaction was executed
-		"hook_tx_hash":  event.TransactionHash, // Record transaction hash even on failure
-		"hook_error":    event.EventData.ErrorData,
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-		return fmt.Errorf("update WithdrawRequest failed: %w", err)
-	}
-
-	// Update main status (will check fallback_transferred in UpdateMainStatus)
-	withdrawRequest.HookStatus = models.HookStatusFailed
-	withdrawRequest.HookError = event.EventData.ErrorData
-	withdrawRequest.UpdateMainStatus()
-	if err := p.db.Save(&withdrawRequest).Error; err != nil {
-		log.Printf("❌ [HookFailed] Failed to update main status: %v", err)
+	// Main status will check fallback_transferred in UpdateMainStatus
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "HookFailed", func(fresh *models.WithdrawRequest) {
+		fresh.HookStatus = models.HookStatusFailed
+		fresh.HookChainID = &chainID             // Record chain ID where hook transaction was executed
+		fresh.HookTxHash = event.TransactionHash // Record transaction hash even on failure
+		fresh.HookError = event.EventData.ErrorData
+	}); err != nil {
+		log.Printf("❌ [HookFailed] Failed to update WithdrawRequest: %v", err)
 		return err
 	}
 
+	p.appendRetryHistory(withdrawRequest.ID, models.RetryHistoryEntry{
+		Stage: models.RetryStageHook, Attempt: withdrawRequest.HookRetryCount + 1,
+		Error: event.EventData.ErrorData, Trigger: "HookFailed",
+	})
+
 	log.Printf("⚠️ [HookFailed] Hook failed: RequestId=%s, waiting for fallback", event.EventData.RequestId)
 	// Push WebSocket update for WithdrawRequest status change
 	if p.pushService != nil {
@@ -1951,6 +2198,9 @@ func (p *BlockchainEventProcessor) ProcessHookFailed(event *clients.EventHookFai
 
 // ProcessFallbackTransferred processes IntentManager.FallbackTransferred event
 func (p *BlockchainEventProcessor) ProcessFallbackTransferred(event *clients.EventFallbackTransferredResponse) error {
+	if !p.isChainSupported("FallbackTransferred", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessFallbackTransferred: Chain=%d, RequestId=%s", event.ChainID, event.EventData.RequestId)
 
 	var withdrawRequest models.WithdrawRequest
@@ -1963,21 +2213,12 @@ func (p *BlockchainEventProcessor) ProcessFallbackTransferred(event *clients.Eve
 		return fmt.Errorf("query WithdrawRequest failed: %w", err)
 	}
 
-	// Update fallback status
-	updates := map[string]interface{}{
-		"fallback_transferred": true,
-		"fallback_error":       "", // Clear error on success
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-		return fmt.Errorf("update WithdrawRequest failed: %w", err)
-	}
-
-	// Update main status (should be completed with fallback_transferred = true)
-	withdrawRequest.FallbackTransferred = true
-	withdrawRequest.UpdateMainStatus()
-	if err := p.db.Save(&withdrawRequest).Error; err != nil {
-		log.Printf("❌ [FallbackTransferred] Failed to update main status: %v", err)
+	// Update fallback status (main status should be completed with fallback_transferred = true)
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "FallbackTransferred", func(fresh *models.WithdrawRequest) {
+		fresh.FallbackTransferred = true
+		fresh.FallbackError = "" // Clear error on success
+	}); err != nil {
+		log.Printf("❌ [FallbackTransferred] Failed to update WithdrawRequest: %v", err)
 		return err
 	}
 
@@ -1991,6 +2232,9 @@ func (p *BlockchainEventProcessor) ProcessFallbackTransferred(event *clients.Eve
 
 // ProcessFallbackFailed processes IntentManager.FallbackFailed event
 func (p *BlockchainEventProcessor) ProcessFallbackFailed(event *clients.EventFallbackFailedResponse) error {
+	if !p.isChainSupported("FallbackFailed", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessFallbackFailed: Chain=%d, RequestId=%s, Error=%s",
 		event.ChainID, event.EventData.RequestId, event.EventData.ErrorReason)
 
@@ -2005,21 +2249,11 @@ func (p *BlockchainEventProcessor) ProcessFallbackFailed(event *clients.EventFal
 	}
 
 	// Update fallback error (simplified: just record error, wait for manual resolution)
-	updates := map[string]interface{}{
-		"fallback_error":       event.EventData.ErrorReason,
-		"fallback_transferred": false,
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
-		return fmt.Errorf("update WithdrawRequest failed: %w", err)
-	}
-
-	// Update main status
-	withdrawRequest.FallbackError = event.EventData.ErrorReason
-	withdrawRequest.FallbackTransferred = false
-	withdrawRequest.UpdateMainStatus()
-	if err := p.db.Save(&withdrawRequest).Error; err != nil {
-		log.Printf("❌ [FallbackFailed] Failed to update main status: %v", err)
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "FallbackFailed", func(fresh *models.WithdrawRequest) {
+		fresh.FallbackError = event.EventData.ErrorReason
+		fresh.FallbackTransferred = false
+	}); err != nil {
+		log.Printf("❌ [FallbackFailed] Failed to update WithdrawRequest: %v", err)
 		return err
 	}
 
@@ -2034,6 +2268,9 @@ func (p *BlockchainEventProcessor) ProcessFallbackFailed(event *clients.EventFal
 // ProcessManuallyResolved processes ZKPayProxy.ManuallyResolved event
 // This event is emitted when admin manually resolves a failed withdraw request
 func (p *BlockchainEventProcessor) ProcessManuallyResolved(event *clients.EventManuallyResolvedResponse) error {
+	if !p.isChainSupported("ManuallyResolved", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessManuallyResolved: Chain=%d, RequestId=%s, Resolver=%s, Note=%s",
 		event.ChainID, event.EventData.RequestId, event.EventData.Resolver, event.EventData.Note)
 
@@ -2048,12 +2285,14 @@ func (p *BlockchainEventProcessor) ProcessManuallyResolved(event *clients.EventM
 		return fmt.Errorf("query WithdrawRequest failed: %w", err)
 	}
 
-	// Set status to manually_resolved (terminal state)
-	updates := map[string]interface{}{
-		"status": string(models.WithdrawStatusManuallyResolved),
-	}
-
-	if err := p.db.Model(&withdrawRequest).Updates(updates).Error; err != nil {
+	// Set status to manually_resolved (terminal state, kept by UpdateMainStatus)
+	if err := p.saveWithdrawRequest(p.db, &withdrawRequest, "ManuallyResolved", func(fresh *models.WithdrawRequest) {
+		now := time.Now()
+		fresh.Status = string(models.WithdrawStatusManuallyResolved)
+		fresh.ResolvedBy = event.EventData.Resolver
+		fresh.ResolutionNote = event.EventData.Note
+		fresh.ResolvedAt = &now
+	}); err != nil {
 		return fmt.Errorf("update WithdrawRequest failed: %w", err)
 	}
 
@@ -2064,6 +2303,9 @@ func (p *BlockchainEventProcessor) ProcessManuallyResolved(event *clients.EventM
 
 // ProcessPayoutRetryRecordCreated processes Treasury.PayoutRetryRecordCreated event
 func (p *BlockchainEventProcessor) ProcessPayoutRetryRecordCreated(event *clients.EventPayoutRetryRecordCreatedResponse) error {
+	if !p.isChainSupported("PayoutRetryRecordCreated", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessPayoutRetryRecordCreated: Chain=%d, RecordId=%s, RequestId=%s",
 		event.ChainID, event.EventData.RecordId, event.EventData.RequestId)
 
@@ -2075,6 +2317,9 @@ func (p *BlockchainEventProcessor) ProcessPayoutRetryRecordCreated(event *client
 
 // ProcessFallbackRetryRecordCreated processes Treasury.FallbackRetryRecordCreated event
 func (p *BlockchainEventProcessor) ProcessFallbackRetryRecordCreated(event *clients.EventFallbackRetryRecordCreatedResponse) error {
+	if !p.isChainSupported("FallbackRetryRecordCreated", event.ChainID) {
+		return nil
+	}
 	log.Printf("📥 ProcessFallbackRetryRecordCreated: Chain=%d, RecordId=%s, RequestId=%s",
 		event.ChainID, event.EventData.RecordId, event.EventData.RequestId)
 
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/config"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func TestProcessDepositRecordedRollsBackWhenCheckbookWriteFails(t *testing.T) {
+	database := dbtest.Open(t)
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	// Fail the checkbook insert, the last of the three writes
+	errInjected := errors.New("injected checkbook failure")
+	if err := database.Callback().Create().Before("gorm:create").Register("test:fail_checkbooks", func(db *gorm.DB) {
+		if db.Statement.Table == "checkbooks" {
+			db.AddError(errInjected)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	event := &clients.EventDepositRecordedResponse{
+		ChainID:         714,
+		EventName:       "DepositRecorded",
+		BlockNumber:     100,
+		TransactionHash: "0xdeposit",
+	}
+	event.EventData.LocalDepositId = 7
+	event.EventData.TokenKey = "USDT"
+	event.EventData.Owner.ChainId = 714
+	event.EventData.Owner.Data = "0x00000000000000000000000000000000000000aa"
+	event.EventData.GrossAmount = "1000"
+	event.EventData.FeeTotalLocked = "10"
+	event.EventData.AllocatableAmount = "990"
+
+	processor := NewBlockchainEventProcessor(database, nil, nil, nil)
+	if err := processor.ProcessDepositRecorded(event); !errors.Is(err, errInjected) {
+		t.Fatalf("ProcessDepositRecorded = %v, want the injected checkbook failure", err)
+	}
+
+	var deposits, events int64
+	database.Model(&models.DepositInfo{}).Count(&deposits)
+	database.Model(&models.EventDepositRecorded{}).Count(&events)
+	if deposits != 0 || events != 0 {
+		t.Errorf("after the failed checkbook write: %d deposit info and %d event rows, want both rolled back", deposits, events)
+	}
+}
+
+func TestProcessDepositUsedRedeliveryStoresOneEvent(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+	deposit := models.DepositInfo{
+		SLIP44ChainID:        714,
+		ChainID:              714,
+		LocalDepositID:       7,
+		GrossAmount:          "1000",
+		FeeTotalLocked:       "0",
+		AllocatableAmount:    "1000",
+		AllocatableRemaining: "1000",
+	}
+	if err := database.Create(&deposit).Error; err != nil {
+		t.Fatalf("create deposit: %v", err)
+	}
+
+	event := &clients.EventDepositUsedResponse{
+		ChainID:         714,
+		EventName:       "DepositUsed",
+		BlockNumber:     120,
+		TransactionHash: "0xused",
+		LogIndex:        2,
+	}
+	event.EventData.LocalDepositId = 7
+	event.EventData.Commitment = "0xcommitment"
+
+	for i := 0; i < 2; i++ {
+		if err := processor.ProcessDepositUsed(event); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	var events []models.EventDepositUsed
+	if err := database.Find(&events).Error; err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("%d DepositUsed rows after two deliveries, want 1", len(events))
+	}
+	if events[0].Commitment != "0xcommitment" || events[0].LocalDepositId != 7 {
+		t.Errorf("event = %+v, want deposit 7 with 0xcommitment", events[0])
+	}
+
+	var stored models.DepositInfo
+	if err := database.First(&stored, "slip44_chain_id = ? AND local_deposit_id = ?", 714, 7).Error; err != nil {
+		t.Fatalf("reload deposit: %v", err)
+	}
+	if !stored.Used {
+		t.Error("deposit not marked used")
+	}
+}
+
+// depositRecordedEvent a DepositRecorded event for deposit localDepositID on BSC
+func depositRecordedEvent(localDepositID uint64, tokenKey string) *clients.EventDepositRecordedResponse {
+	event := &clients.EventDepositRecordedResponse{
+		ChainID:         714,
+		EventName:       "DepositRecorded",
+		BlockNumber:     101,
+		TransactionHash: "0xrecorded",
+	}
+	event.EventData.LocalDepositId = localDepositID
+	event.EventData.TokenKey = tokenKey
+	event.EventData.Owner.ChainId = 714
+	event.EventData.Owner.Data = "0x00000000000000000000000000000000000000aa"
+	event.EventData.GrossAmount = "1000"
+	event.EventData.FeeTotalLocked = "10"
+	event.EventData.AllocatableAmount = "990"
+	return event
+}
+
+// depositReceivedEvent the matching DepositReceived event
+func depositReceivedEvent(localDepositID uint64) *clients.EventDepositReceivedResponse {
+	event := &clients.EventDepositReceivedResponse{
+		ChainID:         714,
+		EventName:       "DepositReceived",
+		BlockNumber:     100,
+		TransactionHash: "0xreceived",
+	}
+	event.EventData.LocalDepositId = localDepositID
+	event.EventData.Depositor = "0x00000000000000000000000000000000000000aa"
+	event.EventData.Token = "0x55d398326f99059fF775485246999027B3197955"
+	event.EventData.Amount = "1000"
+	return event
+}
+
+func TestDepositRecordedBeforeReceivedKeepsTokenKey(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+
+	if err := processor.ProcessDepositRecorded(depositRecordedEvent(8, "USDT")); err != nil {
+		t.Fatalf("ProcessDepositRecorded: %v", err)
+	}
+	if err := processor.ProcessDepositReceived(depositReceivedEvent(8)); err != nil {
+		t.Fatalf("ProcessDepositReceived: %v", err)
+	}
+
+	var checkbooks []models.Checkbook
+	if err := database.Where("local_deposit_id = ?", 8).Find(&checkbooks).Error; err != nil {
+		t.Fatalf("load checkbooks: %v", err)
+	}
+	if len(checkbooks) != 1 {
+		t.Fatalf("%d checkbooks for the deposit, want 1", len(checkbooks))
+	}
+	if checkbooks[0].TokenKey != "USDT" {
+		t.Errorf("token_key = %q after the late DepositReceived, want USDT", checkbooks[0].TokenKey)
+	}
+}
+
+func TestLateDepositReceivedBackfillsTokenKey(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+
+	if err := processor.ProcessDepositRecorded(depositRecordedEvent(9, "USDT")); err != nil {
+		t.Fatalf("ProcessDepositRecorded: %v", err)
+	}
+	// A checkbook that ended up without a token_key, e.g. written before the recorded event carried one
+	if err := database.Model(&models.Checkbook{}).Where("local_deposit_id = ?", 9).Update("token_key", "").Error; err != nil {
+		t.Fatalf("clear token_key: %v", err)
+	}
+	if err := processor.ProcessDepositReceived(depositReceivedEvent(9)); err != nil {
+		t.Fatalf("ProcessDepositReceived: %v", err)
+	}
+
+	var checkbook models.Checkbook
+	if err := database.Where("local_deposit_id = ?", 9).First(&checkbook).Error; err != nil {
+		t.Fatalf("load checkbook: %v", err)
+	}
+	if checkbook.TokenKey != "USDT" {
+		t.Errorf("token_key = %q, want USDT backfilled from the stored DepositRecorded event", checkbook.TokenKey)
+	}
+}
+
+func TestDepositReceivedWithoutAmountStoresZero(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+
+	event := depositReceivedEvent(10)
+	event.EventData.Amount = ""
+	if err := processor.ProcessDepositReceived(event); err != nil {
+		t.Fatalf("ProcessDepositReceived: %v", err)
+	}
+	var checkbook models.Checkbook
+	if err := database.Where("local_deposit_id = ?", 10).First(&checkbook).Error; err != nil {
+		t.Fatalf("load checkbook: %v", err)
+	}
+	if checkbook.GrossAmount != "0" || checkbook.Amount != "0" {
+		t.Errorf("gross_amount = %q, amount = %q, want both \"0\"", checkbook.GrossAmount, checkbook.Amount)
+	}
+
+	// A redelivery that carries the amount fills in the "0" placeholder
+	event = depositReceivedEvent(10)
+	if err := processor.ProcessDepositReceived(event); err != nil {
+		t.Fatalf("ProcessDepositReceived with amount: %v", err)
+	}
+	if err := database.Where("local_deposit_id = ?", 10).First(&checkbook).Error; err != nil {
+		t.Fatalf("reload checkbook: %v", err)
+	}
+	if checkbook.GrossAmount == "0" || checkbook.GrossAmount == "" {
+		t.Errorf("gross_amount = %q after the amount arrived, want it filled in", checkbook.GrossAmount)
+	}
+}
+
+func TestDepositReceivedFromUnsupportedChainIsIgnored(t *testing.T) {
+	processor, database, pushes := newTestEventProcessor(t)
+	config.AppConfig.Blockchain.SupportedChains = []int{60}
+
+	if err := processor.ProcessDepositReceived(depositReceivedEvent(11)); err != nil {
+		t.Fatalf("ProcessDepositReceived on unsupported chain: %v, want ignored without error", err)
+	}
+
+	var checkbooks, events int64
+	database.Model(&models.Checkbook{}).Where("local_deposit_id = ?", 11).Count(&checkbooks)
+	database.Model(&models.EventDepositReceived{}).Where("local_deposit_id = ?", 11).Count(&events)
+	if checkbooks != 0 || events != 0 {
+		t.Errorf("%d checkbooks and %d DepositReceived rows for chain 714, want none", checkbooks, events)
+	}
+	if got := pushCount(pushes); got != 0 {
+		t.Errorf("%d pushes, want 0", got)
+	}
+
+	config.AppConfig.Blockchain.SupportedChains = []int{60, 714}
+	if err := processor.ProcessDepositReceived(depositReceivedEvent(11)); err != nil {
+		t.Fatalf("ProcessDepositReceived on supported chain: %v", err)
+	}
+	database.Model(&models.Checkbook{}).Where("local_deposit_id = ?", 11).Count(&checkbooks)
+	if checkbooks != 1 {
+		t.Errorf("%d checkbooks once chain 714 is supported, want 1", checkbooks)
+	}
+}
+
+func TestRedeliveredDepositRecordedStoresNormalizedOwner(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+	wantOwner := "0x" + strings.Repeat("0", 62) + "aa" // 32-byte Universal Address of the 0x...aa owner
+
+	first := depositRecordedEvent(12, "USDT")
+	if err := processor.ProcessDepositRecorded(first); err != nil {
+		t.Fatalf("ProcessDepositRecorded (create): %v", err)
+	}
+	var created models.DepositInfo
+	if err := database.First(&created, "local_deposit_id = ?", 12).Error; err != nil {
+		t.Fatalf("load created DepositInfo: %v", err)
+	}
+	if created.Owner.Data != wantOwner {
+		t.Fatalf("created owner_data = %q, want %q", created.Owner.Data, wantOwner)
+	}
+
+	// The same owner in raw event form (mixed case, 20 bytes) goes through the update path
+	second := depositRecordedEvent(12, "USDT")
+	second.EventData.Owner.Data = "0x00000000000000000000000000000000000000AA"
+	second.EventData.AllocatableAmount = "980"
+	if err := processor.ProcessDepositRecorded(second); err != nil {
+		t.Fatalf("ProcessDepositRecorded (update): %v", err)
+	}
+
+	var updated models.DepositInfo
+	if err := database.First(&updated, "local_deposit_id = ?", 12).Error; err != nil {
+		t.Fatalf("load updated DepositInfo: %v", err)
+	}
+	if updated.AllocatableAmount != "980" {
+		t.Fatalf("allocatable_amount = %s, want 980 (update path not taken)", updated.AllocatableAmount)
+	}
+	if updated.Owner.Data != wantOwner {
+		t.Errorf("updated owner_data = %q, want the normalized %q like the create path", updated.Owner.Data, wantOwner)
+	}
+	var event models.EventDepositRecorded
+	if err := database.First(&event, "local_deposit_id = ?", 12).Error; err != nil {
+		t.Fatalf("load DepositRecorded row: %v", err)
+	}
+	if event.OwnerData != updated.Owner.Data {
+		t.Errorf("event owner_data = %q, DepositInfo owner_data = %q, want the same", event.OwnerData, updated.Owner.Data)
+	}
+}
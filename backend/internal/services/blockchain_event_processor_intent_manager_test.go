@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func intentManagerWithdrawExecutedEvent(txHash string, success bool) *clients.EventIntentManagerWithdrawExecutedResponse {
+	event := &clients.EventIntentManagerWithdrawExecutedResponse{
+		ChainID:         714,
+		EventName:       "WithdrawExecuted",
+		BlockNumber:     300,
+		TransactionHash: txHash,
+	}
+	event.EventData.Success = success
+	return event
+}
+
+func loadWithdrawRequest(t *testing.T, database *gorm.DB, id string) models.WithdrawRequest {
+	t.Helper()
+	var request models.WithdrawRequest
+	if err := database.First(&request, "id = ?", id).Error; err != nil {
+		t.Fatalf("reload %s: %v", id, err)
+	}
+	return request
+}
+
+func TestIntentManagerWithdrawExecutedMatchesItsOwnRequest(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+	createExecutedWithdrawRequest(t, database, "wr-a", "0xnullifier-a")
+	createExecutedWithdrawRequest(t, database, "wr-b", "0xnullifier-b")
+	database.Model(&models.WithdrawRequest{}).Where("id = ?", "wr-a").Update("payout_tx_hash", "0xtx-a")
+	database.Model(&models.WithdrawRequest{}).Where("id = ?", "wr-b").Update("payout_tx_hash", "0xtx-b")
+
+	if err := processor.ProcessIntentManagerWithdrawExecuted(intentManagerWithdrawExecutedEvent("0xtx-b", true)); err != nil {
+		t.Fatalf("ProcessIntentManagerWithdrawExecuted: %v", err)
+	}
+
+	a, b := loadWithdrawRequest(t, database, "wr-a"), loadWithdrawRequest(t, database, "wr-b")
+	if b.PayoutStatus != models.PayoutStatusCompleted || b.IntentManagerTxHash != "0xtx-b" {
+		t.Errorf("wr-b payout_status=%s intent_manager_tx_hash=%q, want completed by 0xtx-b", b.PayoutStatus, b.IntentManagerTxHash)
+	}
+	if a.PayoutStatus != models.PayoutStatusProcessing || a.IntentManagerTxHash != "" {
+		t.Errorf("wr-a payout_status=%s intent_manager_tx_hash=%q, want untouched", a.PayoutStatus, a.IntentManagerTxHash)
+	}
+
+	// A redelivery matches through intent_manager_tx_hash and still leaves wr-a alone
+	if err := processor.ProcessIntentManagerWithdrawExecuted(intentManagerWithdrawExecutedEvent("0xtx-b", true)); err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+	if a := loadWithdrawRequest(t, database, "wr-a"); a.PayoutStatus != models.PayoutStatusProcessing {
+		t.Errorf("wr-a payout_status=%s after redelivery, want processing", a.PayoutStatus)
+	}
+}
+
+func TestIntentManagerWithdrawExecutedSkipsAmbiguousFallback(t *testing.T) {
+	processor, database, pushes := newTestEventProcessor(t)
+	createExecutedWithdrawRequest(t, database, "wr-a", "0xnullifier-a")
+	createExecutedWithdrawRequest(t, database, "wr-b", "0xnullifier-b")
+
+	// Neither request has a TX hash yet, so the event can only match by time window: two candidates, no match
+	if err := processor.ProcessIntentManagerWithdrawExecuted(intentManagerWithdrawExecutedEvent("0xunknown", true)); err != nil {
+		t.Fatalf("ProcessIntentManagerWithdrawExecuted: %v", err)
+	}
+
+	for _, id := range []string{"wr-a", "wr-b"} {
+		request := loadWithdrawRequest(t, database, id)
+		if request.PayoutStatus != models.PayoutStatusProcessing || request.IntentManagerTxHash != "" {
+			t.Errorf("%s payout_status=%s intent_manager_tx_hash=%q, want untouched", id, request.PayoutStatus, request.IntentManagerTxHash)
+		}
+	}
+	if n := pushCount(pushes); n != 0 {
+		t.Errorf("%d pushes for an unmatched event, want 0", n)
+	}
+}
+
+func TestIntentManagerWithdrawExecutedFallsBackToSingleProcessingRequest(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+	createExecutedWithdrawRequest(t, database, "wr-a", "0xnullifier-a")
+
+	if err := processor.ProcessIntentManagerWithdrawExecuted(intentManagerWithdrawExecutedEvent("0xcross-chain", true)); err != nil {
+		t.Fatalf("ProcessIntentManagerWithdrawExecuted: %v", err)
+	}
+
+	a := loadWithdrawRequest(t, database, "wr-a")
+	if a.PayoutStatus != models.PayoutStatusCompleted || a.IntentManagerTxHash != "0xcross-chain" {
+		t.Errorf("wr-a payout_status=%s intent_manager_tx_hash=%q, want completed by 0xcross-chain", a.PayoutStatus, a.IntentManagerTxHash)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/types"
+)
+
+func TestDecodeWithdrawRecipientFromPublicValues(t *testing.T) {
+	contract, err := contractABIFor(&config.NetworkConfig{ChainID: 714, ContractABIVersion: ContractABIVersion1})
+	if err != nil {
+		t.Fatalf("contractABIFor: %v", err)
+	}
+	// slip44ChainID 60, sourceChainId 714
+	callData, err := contract.packWithdraw(contract.parsed, []byte{0x01}, encodeWithdrawPublicValues(t, 1500, 60))
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	publicValues, err := types.ParseExecuteWithdrawCallData(callData, executeWithdrawMethods()...)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	chainID, err := withdrawRecipientChainID(publicValues)
+	if err != nil || chainID != 714 {
+		t.Errorf("recipient chain = %d, %v; want the sourceChainId 714", chainID, err)
+	}
+	// beneficiaryData is bytes32{3}: the recipient, not the keccak256 hash the log carries
+	if want := "0x03" + strings.Repeat("0", 62); publicValues.BeneficiaryData != want {
+		t.Errorf("recipient data = %s, want %s", publicValues.BeneficiaryData, want)
+	}
+}
+
+func TestWithdrawRecipientChainIDRejectsOutOfRangeChain(t *testing.T) {
+	if chainID, err := withdrawRecipientChainID(&types.WithdrawPublicValues{SourceChainID: math.MaxUint16}); err != nil || chainID != math.MaxUint16 {
+		t.Errorf("chain %d = %d, %v; want it accepted", math.MaxUint16, chainID, err)
+	}
+	if _, err := withdrawRecipientChainID(&types.WithdrawPublicValues{SourceChainID: math.MaxUint16 + 1}); err == nil {
+		t.Error("chain above uint16 accepted, want an error instead of truncation")
+	}
+}
+
+func TestDeriveCheckbookStatus(t *testing.T) {
+	tests := []struct {
+		evidence CheckbookEventEvidence
+		want     models.CheckbookStatus
+	}{
+		{CheckbookEventEvidence{}, ""},
+		{CheckbookEventEvidence{DepositRecorded: true}, models.CheckbookStatusReadyForCommitment},
+		{CheckbookEventEvidence{DepositUsed: true}, models.CheckbookStatusWithCheckbook},
+		{CheckbookEventEvidence{CommitmentRootUpdated: true}, models.CheckbookStatusWithCheckbook},
+		{CheckbookEventEvidence{DepositRecorded: true, DepositUsed: true}, models.CheckbookStatusWithCheckbook},
+		{CheckbookEventEvidence{DepositRecorded: true, CommitmentRootUpdated: true}, models.CheckbookStatusWithCheckbook},
+		{CheckbookEventEvidence{DepositUsed: true, CommitmentRootUpdated: true}, models.CheckbookStatusWithCheckbook},
+		{CheckbookEventEvidence{DepositRecorded: true, DepositUsed: true, CommitmentRootUpdated: true}, models.CheckbookStatusWithCheckbook},
+	}
+	for _, tt := range tests {
+		if got := DeriveCheckbookStatus(tt.evidence); got != tt.want {
+			t.Errorf("DeriveCheckbookStatus(%+v) = %q, want %q", tt.evidence, got, tt.want)
+		}
+	}
+}
+
+func TestIsCheckbookStatusAhead(t *testing.T) {
+	tests := []struct {
+		current, target models.CheckbookStatus
+		want            bool
+	}{
+		{models.CheckbookStatusUnsigned, models.CheckbookStatusReadyForCommitment, true},
+		{models.CheckbookStatusReadyForCommitment, models.CheckbookStatusWithCheckbook, true},
+		{models.CheckbookStatusCommitmentPending, models.CheckbookStatusWithCheckbook, true},
+		{models.CheckbookStatusReadyForCommitment, models.CheckbookStatusReadyForCommitment, false},
+		{models.CheckbookStatusGeneratingProof, models.CheckbookStatusReadyForCommitment, false}, // never backwards
+		{models.CheckbookStatusWithCheckbook, models.CheckbookStatusReadyForCommitment, false},
+		{models.CheckbookStatusSubmissionFailed, models.CheckbookStatusWithCheckbook, true}, // failed statuses rank 0
+		{models.CheckbookStatusUnsigned, "", false},
+	}
+	for _, tt := range tests {
+		if got := IsCheckbookStatusAhead(tt.current, tt.target); got != tt.want {
+			t.Errorf("IsCheckbookStatusAhead(%s, %s) = %v, want %v", tt.current, tt.target, got, tt.want)
+		}
+	}
+}
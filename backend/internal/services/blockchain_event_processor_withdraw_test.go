@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/config"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// newTestEventProcessor a processor over a fresh test database whose pushes are queued, not delivered (see pushCount)
+func newTestEventProcessor(t *testing.T) (*BlockchainEventProcessor, *gorm.DB, *WebSocketPushService) {
+	t.Helper()
+	database := dbtest.Open(t)
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	pushes := &WebSocketPushService{hub: make(chan PushMessage, 64)}
+	return NewBlockchainEventProcessor(database, pushes, nil, nil), database, pushes
+}
+
+// pushCount number of messages queued on a push service built by newTestEventProcessor
+func pushCount(pushes *WebSocketPushService) int {
+	return len(pushes.hub)
+}
+
+// createExecutedWithdrawRequest stores a request executed on-chain and waiting for its payout
+func createExecutedWithdrawRequest(t *testing.T, database *gorm.DB, id, nullifier string) {
+	t.Helper()
+	request := models.WithdrawRequest{
+		ID:                id,
+		WithdrawNullifier: nullifier,
+		Status:            string(models.WithdrawStatusWaitingForPayout),
+		ProofStatus:       models.ProofStatusCompleted,
+		ExecuteStatus:     models.ExecuteStatusSuccess,
+		PayoutStatus:      models.PayoutStatusProcessing,
+		HookStatus:        models.HookStatusNotRequired,
+		Amount:            "1000",
+		Version:           1,
+	}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create withdraw request: %v", err)
+	}
+}
+
+func withdrawExecutedEvent(nullifier, txHash string) *clients.EventWithdrawExecutedResponse {
+	event := &clients.EventWithdrawExecutedResponse{
+		ChainID:         714,
+		EventName:       "WithdrawExecuted",
+		BlockNumber:     200,
+		TransactionHash: txHash,
+		LogIndex:        3,
+	}
+	event.EventData.Recipient = "0x00000000000000000000000000000000000000bb"
+	event.EventData.Token = "0x00000000000000000000000000000000000000cc"
+	event.EventData.Amount = "1000"
+	event.EventData.RequestId = nullifier
+	return event
+}
+
+func TestProcessWithdrawExecutedTwiceChangesStateOnce(t *testing.T) {
+	processor, database, pushes := newTestEventProcessor(t)
+	createExecutedWithdrawRequest(t, database, "wr-dup", "0xnullifier-dup")
+	event := withdrawExecutedEvent("0xnullifier-dup", "0xpayout-dup")
+
+	for i := 0; i < 2; i++ {
+		if err := processor.ProcessWithdrawExecuted(event); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	var events int64
+	database.Model(&models.EventWithdrawExecuted{}).Where("transaction_hash = ?", "0xpayout-dup").Count(&events)
+	if events != 1 {
+		t.Errorf("%d WithdrawExecuted rows, want 1", events)
+	}
+	var request models.WithdrawRequest
+	if err := database.First(&request, "id = ?", "wr-dup").Error; err != nil {
+		t.Fatalf("reload request: %v", err)
+	}
+	if request.PayoutStatus != models.PayoutStatusCompleted || request.PayoutTxHash != "0xpayout-dup" {
+		t.Errorf("payout_status=%s payout_tx_hash=%s, want completed by 0xpayout-dup", request.PayoutStatus, request.PayoutTxHash)
+	}
+	if request.Version != 2 {
+		t.Errorf("version = %d, want 2 (one update)", request.Version)
+	}
+	var transitions int64
+	database.Model(&models.StatusTransition{}).
+		Where("entity_id = ? AND to_status = ?", "wr-dup", "payout:"+string(models.PayoutStatusCompleted)).Count(&transitions)
+	if transitions != 1 {
+		t.Errorf("%d payout completed transitions recorded, want 1", transitions)
+	}
+	if got := pushCount(pushes); got != 1 {
+		t.Errorf("%d pushes, want 1", got)
+	}
+}
+
+func TestProcessWithdrawExecutedWithoutTxHashFlagsForManualReview(t *testing.T) {
+	processor, database, pushes := newTestEventProcessor(t)
+	createExecutedWithdrawRequest(t, database, "wr-nohash", "0xnullifier-nohash")
+
+	if err := processor.ProcessWithdrawExecuted(withdrawExecutedEvent("0xnullifier-nohash", "")); err != nil {
+		t.Fatalf("process event: %v", err)
+	}
+
+	var request models.WithdrawRequest
+	if err := database.First(&request, "id = ?", "wr-nohash").Error; err != nil {
+		t.Fatalf("reload request: %v", err)
+	}
+	if !request.PayoutNeedsManualReview {
+		t.Error("payout_needs_manual_review = false, want true")
+	}
+	if request.PayoutStatus != models.PayoutStatusProcessing {
+		t.Errorf("payout_status = %s, want %s (not completed)", request.PayoutStatus, models.PayoutStatusProcessing)
+	}
+	if request.PayoutTxHash != "" || request.PayoutCompletedAt != nil {
+		t.Errorf("payout_tx_hash=%q payout_completed_at=%v, want both unset", request.PayoutTxHash, request.PayoutCompletedAt)
+	}
+	if got := pushCount(pushes); got != 0 {
+		t.Errorf("%d pushes, want 0", got)
+	}
+
+	flagged, total, err := repository.NewWithdrawRequestRepository(database).FindNeedsManualReview(context.Background(), 1, 20)
+	if err != nil {
+		t.Fatalf("FindNeedsManualReview: %v", err)
+	}
+	if total != 1 || len(flagged) != 1 || flagged[0].ID != "wr-nohash" {
+		t.Errorf("FindNeedsManualReview = %d rows (total %d), want only wr-nohash", len(flagged), total)
+	}
+}
+
+// createSubmittedWithdrawRequest stores a request whose executeWithdraw was sent but not yet confirmed
+func createSubmittedWithdrawRequest(t *testing.T, database *gorm.DB, id, nullifier string) {
+	t.Helper()
+	request := models.WithdrawRequest{
+		ID:                id,
+		WithdrawNullifier: nullifier,
+		Status:            string(models.WithdrawStatusSubmitted),
+		ProofStatus:       models.ProofStatusCompleted,
+		ExecuteStatus:     models.ExecuteStatusSubmitted,
+		PayoutStatus:      models.PayoutStatusPending,
+		HookStatus:        models.HookStatusNotRequired,
+		Amount:            "1000",
+		Version:           1,
+	}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create withdraw request: %v", err)
+	}
+}
+
+func TestPayoutExecutedWhileExecuteSubmittedCompletesBothStages(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+	createSubmittedWithdrawRequest(t, database, "wr-early-payout", "0xnullifier-early-payout")
+
+	event := &clients.EventPayoutExecutedResponse{ChainID: 714, BlockNumber: 300, TransactionHash: "0xpayout-early"}
+	event.EventData.RequestId = "0xnullifier-early-payout"
+	event.EventData.ActualOutput = "990"
+	if err := processor.ProcessPayoutExecuted(event); err != nil {
+		t.Fatalf("ProcessPayoutExecuted: %v", err)
+	}
+
+	var request models.WithdrawRequest
+	if err := database.First(&request, "id = ?", "wr-early-payout").Error; err != nil {
+		t.Fatalf("reload request: %v", err)
+	}
+	if request.ExecuteStatus != models.ExecuteStatusSuccess || request.PayoutStatus != models.PayoutStatusCompleted ||
+		request.PayoutTxHash != "0xpayout-early" {
+		t.Errorf("execute %s, payout %s (tx %s), want success and completed by 0xpayout-early",
+			request.ExecuteStatus, request.PayoutStatus, request.PayoutTxHash)
+	}
+}
+
+func TestPayoutFailedWhileExecuteSubmittedRecordsFailure(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+	createSubmittedWithdrawRequest(t, database, "wr-early-failure", "0xnullifier-early-failure")
+
+	event := &clients.EventPayoutFailedResponse{ChainID: 714, BlockNumber: 300, TransactionHash: "0xpayout-failed"}
+	event.EventData.RequestId = "0xnullifier-early-failure"
+	event.EventData.ErrorReason = "insufficient liquidity"
+	if err := processor.ProcessPayoutFailed(event); err != nil {
+		t.Fatalf("ProcessPayoutFailed: %v", err)
+	}
+
+	var request models.WithdrawRequest
+	if err := database.First(&request, "id = ?", "wr-early-failure").Error; err != nil {
+		t.Fatalf("reload request: %v", err)
+	}
+	if request.ExecuteStatus != models.ExecuteStatusSuccess || request.PayoutStatus != models.PayoutStatusFailed ||
+		request.PayoutError != "insufficient liquidity" {
+		t.Errorf("execute %s, payout %s (%q), want success and failed with the event's reason",
+			request.ExecuteStatus, request.PayoutStatus, request.PayoutError)
+	}
+}
+
+func TestRecipientsMatch(t *testing.T) {
+	const (
+		evm           = "0x00000000000000000000000000000000000000bb"
+		evmUniversal  = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+		tron          = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+		tronUniversal = "0x000000000000000000000000a614f803b6fd780986a42c78ec9c7f77e6ded13c"
+	)
+	tests := []struct {
+		name           string
+		onChain        string
+		onChainChainID int
+		stored         models.UniversalAddress
+		want           bool
+	}{
+		{"same evm address", evm, 714, models.UniversalAddress{SLIP44ChainID: 714, Data: evm}, true},
+		{"evm case differs", "0x00000000000000000000000000000000000000BB", 714, models.UniversalAddress{SLIP44ChainID: 714, Data: evm}, true},
+		{"evm without 0x", "00000000000000000000000000000000000000bb", 714, models.UniversalAddress{SLIP44ChainID: 714, Data: evm}, true},
+		{"evm against padded universal", evmUniversal, 714, models.UniversalAddress{SLIP44ChainID: 714, Data: evm}, true},
+		{"universal case and prefix differ", "0X00000000000000000000000000000000000000000000000000000000000000BB", 60, models.UniversalAddress{SLIP44ChainID: 60, Data: evmUniversal}, true},
+		{"tron against universal", tronUniversal, 195, models.UniversalAddress{SLIP44ChainID: 195, Data: tron}, true},
+		{"tron against evm form", "0xa614f803b6fd780986a42c78ec9c7f77e6ded13c", 195, models.UniversalAddress{SLIP44ChainID: 195, Data: tron}, true},
+		{"surrounding whitespace", " " + evm + " ", 714, models.UniversalAddress{SLIP44ChainID: 714, Data: evm}, true},
+		{"different evm address", "0x00000000000000000000000000000000000000cc", 714, models.UniversalAddress{SLIP44ChainID: 714, Data: evm}, false},
+		{"different tron address", evmUniversal, 195, models.UniversalAddress{SLIP44ChainID: 195, Data: tron}, false},
+		{"tron base58 on an evm chain", tron, 714, models.UniversalAddress{SLIP44ChainID: 714, Data: tronUniversal}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recipientsMatch(tt.onChain, tt.onChainChainID, tt.stored); got != tt.want {
+				t.Errorf("recipientsMatch(%q, %d, %+v) = %v, want %v", tt.onChain, tt.onChainChainID, tt.stored, got, tt.want)
+			}
+		})
+	}
+}
+
+// createProvingWithdrawRequest stores a request to recipient on chain 714 whose executeWithdraw is not yet confirmed
+func createProvingWithdrawRequest(t *testing.T, database *gorm.DB, id, nullifier, recipient string) {
+	t.Helper()
+	request := models.WithdrawRequest{
+		ID:                id,
+		WithdrawNullifier: nullifier,
+		Recipient:         models.UniversalAddress{SLIP44ChainID: 714, Data: recipient},
+		Status:            string(models.WithdrawStatusProving),
+		ProofStatus:       models.ProofStatusInProgress,
+		ExecuteStatus:     models.ExecuteStatusPending,
+		PayoutStatus:      models.PayoutStatusPending,
+		HookStatus:        models.HookStatusNotRequired,
+		Amount:            "1000",
+		Version:           1,
+	}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create withdraw request: %v", err)
+	}
+}
+
+func TestProcessWithdrawRequestedFlagsRecipientMismatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		decodedRecipient string
+		wantMismatch     bool
+	}{
+		{"same recipient in universal form", "0x00000000000000000000000000000000000000000000000000000000000000BB", false},
+		{"different recipient", "0x00000000000000000000000000000000000000000000000000000000000000cc", true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, database, _ := newTestEventProcessor(t)
+			id, nullifier := fmt.Sprintf("wr-recipient-%d", i), fmt.Sprintf("0xnullifier-recipient-%d", i)
+			createProvingWithdrawRequest(t, database, id, nullifier, "0x00000000000000000000000000000000000000bb")
+			processor.decodeRecipient = func(*clients.EventWithdrawRequestedResponse) (uint16, string, error) {
+				return 714, tt.decodedRecipient, nil
+			}
+
+			event := &clients.EventWithdrawRequestedResponse{
+				ChainID:         714,
+				EventName:       "WithdrawRequested",
+				BlockNumber:     100,
+				TransactionHash: fmt.Sprintf("0xexecute-recipient-%d", i),
+			}
+			event.EventData.RequestId = nullifier
+			event.EventData.Amount = "1000"
+			if err := processor.ProcessWithdrawRequested(event); err != nil {
+				t.Fatalf("process event: %v", err)
+			}
+
+			var request models.WithdrawRequest
+			if err := database.First(&request, "id = ?", id).Error; err != nil {
+				t.Fatalf("reload request: %v", err)
+			}
+			if request.RecipientMismatch != tt.wantMismatch {
+				t.Errorf("recipient_mismatch = %v, want %v", request.RecipientMismatch, tt.wantMismatch)
+			}
+			if request.ExecuteStatus != models.ExecuteStatusSuccess {
+				t.Errorf("execute_status = %s, want %s", request.ExecuteStatus, models.ExecuteStatusSuccess)
+			}
+		})
+	}
+}
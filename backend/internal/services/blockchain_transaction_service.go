@@ -7,6 +7,7 @@ import (
 	"log"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"go-backend/internal/config"
@@ -55,12 +56,32 @@ func (s *PrivateKeySigningStrategy) Name() string {
 }
 
 // KMSSigningStrategy KMS
+// The recovery id is selected by recovering against the cached signing address of the network.
 type KMSSigningStrategy struct {
-	keyMgmt *KeyManagementService
+	keyMgmt  *KeyManagementService
+	verified common.Address // address the last signature recovered to
 }
 
 func (s *KMSSigningStrategy) Sign(networkConfig *config.NetworkConfig, txHash []byte, txHashHex string) ([]byte, error) {
-	return s.keyMgmt.SignWithKMS(networkConfig, txHash, txHashHex)
+	expected, err := cachedKMSSigningAddress(s.keyMgmt, networkConfig)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.keyMgmt.SignWithKMS(networkConfig, txHash, txHashHex)
+	if err != nil {
+		return nil, err
+	}
+	signature, err = selectRecoveryID(txHash, signature, expected)
+	if err != nil {
+		return nil, err
+	}
+	s.verified = expected
+	return signature, nil
+}
+
+// VerifiedAddress returns the address the last signature was verified against, false before a successful Sign
+func (s *KMSSigningStrategy) VerifiedAddress() (common.Address, bool) {
+	return s.verified, s.verified != (common.Address{})
 }
 
 func (s *KMSSigningStrategy) Name() string {
@@ -72,8 +93,18 @@ func (s *KMSSigningStrategy) Name() string {
 // BlockchainTransactionService blockchain transaction service
 type BlockchainTransactionService struct {
 	clients        map[int]*ethclient.Client // chainID -> client
+	endpoints      map[int]string            // chainID -> RPC endpoint the client is connected to
+	clientsMu      sync.RWMutex              // guards clients and endpoints (the health watcher replaces dead clients)
+	health         map[int]bool              // chainID -> last health check result, guarded by clientsMu
+	healthStopCh   chan struct{}             // closes the health watcher, nil when not running
 	keyMgmtService *KeyManagementService     // key management service
 	queueService   *TransactionQueueService  // transaction queue service (optional)
+	nonceManager   *nonceManager             // per-signer nonce allocation
+
+	balanceMu      sync.RWMutex     // guards signerBalances, lowBalance and balanceStopCh
+	signerBalances map[int]*big.Int // chainID -> signer balance (wei) at the last balance check
+	lowBalance     map[int]bool     // chainID -> below lowBalanceThresholdWei at the last check (alerts once per drop)
+	balanceStopCh  chan struct{}    // closes the balance monitor, nil when not running
 }
 
 // getZKPayContractAddress gets ZKPay contract address with priority: Database > networkConfig
@@ -150,9 +181,15 @@ type WithdrawRequest struct {
 	PublicValues      string `json:"public_values"`       // ZKVM encoded public values (hex string, optional - if provided, will be used directly)
 	Token             string `json:"token"`               // token contract address
 	TokenKey          string `json:"token_key"`           // tokenKey (e.g., "USDT")
+	IntentType        uint8  `json:"intent_type"`         // 0=RawToken, 1=AssetToken
+	AssetID           string `json:"asset_id"`            // AssetToken: bytes32 asset ID (chainId + adapterId + tokenId)
 	// Failed
 	CheckbookID string `json:"checkbook_id"` // checkbook ID
 	CheckID     string `json:"check_id"`     // check ID
+	// Execute transaction this request already sent; only it may be replaced on "replacement transaction underpriced"
+	PreviousTxHash string `json:"previous_tx_hash,omitempty"`
+	// SLIP-44 chain the transaction is sent to, resolved once by the caller (0 = resolveSubmissionChain from ChainID)
+	SubmitChainID int `json:"submit_chain_id,omitempty"`
 }
 
 // CommitmentTxResponse commitment transaction response ( BlockScanner API  CommitmentTxResponse)
@@ -177,8 +214,13 @@ type WithdrawResponse struct {
 func NewBlockchainTransactionService(keyMgmtService *KeyManagementService) *BlockchainTransactionService {
 	service := &BlockchainTransactionService{
 		clients:        make(map[int]*ethclient.Client),
+		endpoints:      make(map[int]string),
+		health:         make(map[int]bool),
 		keyMgmtService: keyMgmtService,
 		queueService:   nil, // Will be set via SetQueueService
+		nonceManager:   newNonceManager(),
+		signerBalances: make(map[int]*big.Int),
+		lowBalance:     make(map[int]bool),
 	}
 
 	// addCreate，address
@@ -220,32 +262,8 @@ func (b *BlockchainTransactionService) InitializeClients() error {
 		}
 
 		// attemptconnectionRPC
-		var client *ethclient.Client
-		var err error
-		var connectedEndpoint string
-
 		log.Printf("   🔗 [InitializeClients] Attempting to connect to RPC endpoints...")
-		for i, rpcEndpoint := range networkConfig.RPCEndpoints {
-			log.Printf("      Trying endpoint %d/%d: %s", i+1, len(networkConfig.RPCEndpoints), rpcEndpoint)
-			client, err = ethclient.Dial(rpcEndpoint)
-			if err == nil {
-				log.Printf("      ✅ Dial successful, testing connection...")
-				// connection
-				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				networkID, err := client.NetworkID(ctx)
-				cancel()
-				if err == nil {
-					log.Printf("      ✅ Connection verified! Network ID: %s", networkID.String())
-					connectedEndpoint = rpcEndpoint
-					break
-				}
-				log.Printf("      ❌ NetworkID check failed: %v", err)
-				client.Close()
-			} else {
-				log.Printf("      ❌ Dial failed: %v", err)
-			}
-		}
-
+		client, connectedEndpoint, err := dialRPCEndpoints(networkConfig.RPCEndpoints)
 		if err != nil {
 			log.Printf("❌ [InitializeClients] All RPC endpoints failed for network %s", networkName)
 			return fmt.Errorf("failed to connect to %s network: %w", networkName, err)
@@ -254,18 +272,19 @@ func (b *BlockchainTransactionService) InitializeClients() error {
 
 		// UseSLIP-44 Coin Typestorageclient（）
 		log.Printf("✅ [InitializeClients] successconnectionRPC: %s (SLIP-44: %d)", networkName, networkConfig.ChainID)
-		log.Printf("🔍 [InitializeClients] storageclient，currentclients: %d", len(b.clients))
-		b.clients[networkConfig.ChainID] = client
-		log.Printf("🔍 [InitializeClients] storageclient，currentclients: %d", len(b.clients))
+		b.setClient(networkConfig.ChainID, client, connectedEndpoint)
+		log.Printf("🔍 [InitializeClients] storageclient，currentclients: %d", b.GetClientCount())
 		log.Printf("✅ [InitializeClients] clientstoragecompleted: chainID=%d", networkConfig.ChainID)
 	}
 
 	log.Printf("🎉 [InitializeClients] ========================================")
 	log.Printf("🎉 [InitializeClients] Initialization completed successfully!")
+	b.clientsMu.RLock()
 	log.Printf("🎉 [InitializeClients] Total clients initialized: %d", len(b.clients))
 	for chainID, client := range b.clients {
 		log.Printf("   ✅ Chain ID %d: client=%p", chainID, client)
 	}
+	b.clientsMu.RUnlock()
 	log.Printf("🎉 [InitializeClients] ========================================")
 	return nil
 }
@@ -275,9 +294,12 @@ func (b *BlockchainTransactionService) GetClient(chainID int) (*ethclient.Client
 	log.Printf("🔍 [GetClient] client:")
 	log.Printf("   Serviceaddress: %p", b)
 	log.Printf("   clients mapaddress: %p", b.clients)
-	log.Printf("   clients map: %d", len(b.clients))
 	log.Printf("   requestChainID: %d", chainID)
 
+	b.clientsMu.RLock()
+	defer b.clientsMu.RUnlock()
+	log.Printf("   clients map: %d", len(b.clients))
+
 	// existsclient
 	if len(b.clients) > 0 {
 		log.Printf("   client:")
@@ -295,11 +317,72 @@ func (b *BlockchainTransactionService) GetClient(chainID int) (*ethclient.Client
 
 // GetClientCount GetalreadyInitializeRPCclient
 func (b *BlockchainTransactionService) GetClientCount() int {
+	b.clientsMu.RLock()
+	defer b.clientsMu.RUnlock()
 	return len(b.clients)
 }
 
+// lookupClient returns the current client of a chain without GetClient's debug logging
+func (b *BlockchainTransactionService) lookupClient(chainID int) (*ethclient.Client, bool) {
+	b.clientsMu.RLock()
+	defer b.clientsMu.RUnlock()
+	client, exists := b.clients[chainID]
+	return client, exists
+}
+
+// setClient stores the client of a chain and the endpoint it is connected to
+func (b *BlockchainTransactionService) setClient(chainID int, client *ethclient.Client, endpoint string) {
+	b.clientsMu.Lock()
+	defer b.clientsMu.Unlock()
+	b.clients[chainID] = client
+	b.endpoints[chainID] = endpoint
+	b.health[chainID] = true
+}
+
+// dialRPCEndpoints connects to the first endpoint that answers net_version, in order
+// Returns the client, the endpoint it is connected to, and the last error if every endpoint failed.
+func dialRPCEndpoints(endpoints []string) (*ethclient.Client, string, error) {
+	err := fmt.Errorf("no RPC endpoints configured")
+	for i, rpcEndpoint := range endpoints {
+		log.Printf("      Trying endpoint %d/%d: %s", i+1, len(endpoints), rpcEndpoint)
+		client, dialErr := ethclient.Dial(rpcEndpoint)
+		if dialErr != nil {
+			log.Printf("      ❌ Dial failed: %v", dialErr)
+			err = dialErr
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		networkID, idErr := client.NetworkID(ctx)
+		cancel()
+		if idErr != nil {
+			log.Printf("      ❌ NetworkID check failed: %v", idErr)
+			client.Close()
+			err = idErr
+			continue
+		}
+		log.Printf("      ✅ Connection verified! Network ID: %s", networkID.String())
+		return client, rpcEndpoint, nil
+	}
+	return nil, "", err
+}
+
+// PingClient checks that the RPC client for chainID responds to eth_blockNumber, returns the latest block
+func (b *BlockchainTransactionService) PingClient(ctx context.Context, chainID int) (uint64, error) {
+	client, exists := b.lookupClient(chainID)
+	if !exists {
+		return 0, fmt.Errorf("no RPC client for chain %d", chainID)
+	}
+	blockNumber, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("chain %d: eth_blockNumber failed: %w", chainID, err)
+	}
+	return blockNumber, nil
+}
+
 // GetAllClientIDs GetalreadyInitializechain ID
 func (b *BlockchainTransactionService) GetAllClientIDs() []int {
+	b.clientsMu.RLock()
+	defer b.clientsMu.RUnlock()
 	ids := make([]int, 0, len(b.clients))
 	for chainID := range b.clients {
 		ids = append(ids, chainID)
@@ -322,9 +405,9 @@ func (b *BlockchainTransactionService) SubmitCommitment(req *CommitmentRequest)
 func (b *BlockchainTransactionService) submitCommitmentViaQueue(req *CommitmentRequest) (*CommitmentTxResponse, error) {
 	log.Printf("🚀 [SubmitCommitment] Enqueuing commitment transaction...")
 
-	// 获取签名地址（Commitment 也提交到 BSC，使用 MANAGEMENT_CHAIN_ID）
-	const MANAGEMENT_CHAIN_ID = 714 // BSC
-	networkConfig, err := config.GetNetworkConfigByChainID(MANAGEMENT_CHAIN_ID)
+	// 获取签名地址（提交链由 resolveSubmissionChain 决定，默认管理链）
+	submitChainID := resolveSubmissionChain(submissionCommitment, req.ChainID)
+	networkConfig, err := config.GetNetworkConfigByChainID(submitChainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network config: %w", err)
 	}
@@ -334,10 +417,10 @@ func (b *BlockchainTransactionService) submitCommitmentViaQueue(req *CommitmentR
 		return nil, fmt.Errorf("failed to get signing address: %w", err)
 	}
 
-	// 入队（使用 MANAGEMENT_CHAIN_ID 而不是 req.ChainID，因为实际提交到 BSC）
+	// 入队（使用提交链 chainID，而不是 req.ChainID）
 	queueID, err := b.queueService.EnqueueCommitment(
 		signingAddress,
-		uint32(MANAGEMENT_CHAIN_ID), // 使用 BSC 的 chainID
+		uint32(submitChainID), // 使用提交链的 chainID
 		req.CheckbookID,
 		req,
 		100, // 默认优先级
@@ -360,13 +443,18 @@ func (b *BlockchainTransactionService) submitCommitmentViaQueue(req *CommitmentR
 
 // submitCommitmentDirect 直接提交 commitment（原有逻辑）
 func (b *BlockchainTransactionService) submitCommitmentDirect(req *CommitmentRequest) (*CommitmentTxResponse, error) {
-	// chain ID
-	const MANAGEMENT_CHAIN_ID = 714 // BSCID
+	// Submission target (management chain by default, see resolveSubmissionChain)
+	target, err := resolveSubmissionTarget(submissionCommitment, req.ChainID)
+	if err != nil {
+		log.Printf("❌ Resolve submission target failed: %v", err)
+		return nil, err
+	}
+	submitChainID := target.slip44ChainID
 	log.Printf("🚨🚨🚨 [PROOF DEBUG] SubmitCommitment ！🚨🚨🚨")
 	log.Printf("🚀 [SubmitCommitment] startprocesscommitment:")
 	log.Printf("   Serviceaddress: %p", b)
 	log.Printf("   clients mapaddress: %p", b.clients)
-	log.Printf("   clients map: %d", len(b.clients))
+	log.Printf("   clients map: %d", b.GetClientCount())
 	log.Printf("📋 [Commitmentrequest]:")
 	log.Printf("   ChainID: %d", req.ChainID)
 	log.Printf("   LocalDepositID: %d", req.LocalDepositID)
@@ -381,12 +469,8 @@ func (b *BlockchainTransactionService) submitCommitmentDirect(req *CommitmentReq
 		return req.SP1Proof
 	}())
 
-	// Getnetworkconfiguration
-	networkConfig, err := config.GetNetworkConfigByChainID(req.ChainID)
-	if err != nil {
-		log.Printf("❌ Getnetworkconfigurationfailed: %v", err)
-		return nil, fmt.Errorf("failed to get network config: %w", err)
-	}
+	// Network configuration of the chain the commitment is submitted to
+	networkConfig := target.network
 
 	// Checkconfiguration（configuration）
 	useKMS := false
@@ -400,15 +484,15 @@ func (b *BlockchainTransactionService) submitCommitmentDirect(req *CommitmentReq
 		log.Printf("🔑  (KMSnotconfiguration)")
 		useKMS = false
 	} else {
-		log.Printf("❌ configuration: chainID=%d (KMS)", MANAGEMENT_CHAIN_ID)
-		return nil, fmt.Errorf("no signing method configured for management chainID %d", MANAGEMENT_CHAIN_ID)
+		log.Printf("❌ configuration: chainID=%d (KMS)", submitChainID)
+		return nil, fmt.Errorf("no signing method configured for submission chainID %d", submitChainID)
 	}
 
 	// Getclient
-	client, exists := b.clients[MANAGEMENT_CHAIN_ID]
+	client, exists := b.lookupClient(submitChainID)
 	if !exists {
-		log.Printf("❌ RPCclientnotinitialize: chainID=%d", MANAGEMENT_CHAIN_ID)
-		return nil, fmt.Errorf("management chain client not initialized for chainID %d", MANAGEMENT_CHAIN_ID)
+		log.Printf("❌ RPCclientnotinitialize: chainID=%d", submitChainID)
+		return nil, fmt.Errorf("submission chain client not initialized for chainID %d", submitChainID)
 	}
 
 	// 🔍 RPCconnectionstatus
@@ -448,20 +532,17 @@ func (b *BlockchainTransactionService) submitCommitmentDirect(req *CommitmentReq
 		return nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	// Verify chain ID: We're connecting to MANAGEMENT_CHAIN_ID (BSC 714), so verify BSC's EVM Chain ID (56)
-	// req.ChainID is the source chain (where commitment is created), but we always submit to BSC
-	expectedEvmChainID := utils.Slip44ToEvm(MANAGEMENT_CHAIN_ID) // BSC SLIP-44 714 -> EVM 56
-	actualEvmChainID := actualChainID.Uint64()
-
+	// Verify chain ID against the submission target: the management chain by default, the source chain
+	// (req.ChainID, where the deposit was made) when commitments are routed to the deposit chain
 	log.Printf("🔗 chain ID:")
-	log.Printf("   managementSLIP-44: %d (BSC)", MANAGEMENT_CHAIN_ID)
+	log.Printf("   submitSLIP-44: %d", submitChainID)
 	log.Printf("   sourceSLIP-44: %d (commitment source)", req.ChainID)
-	log.Printf("   expectedEVM Chain ID: %d (BSC)", expectedEvmChainID)
-	log.Printf("   actualEVM Chain ID: %d (from RPC)", actualEvmChainID)
+	log.Printf("   expectedEVM Chain ID: %d (submission)", target.evmChainID)
+	log.Printf("   actualEVM Chain ID: %s (from RPC)", actualChainID)
 
-	if actualEvmChainID != uint64(expectedEvmChainID) {
-		log.Printf("⚠️  Chain ID mismatch! Expected EVM %d (BSC), got EVM %d", expectedEvmChainID, actualEvmChainID)
-		return nil, fmt.Errorf("chain ID mismatch: expected EVM %d (BSC SLIP-44 %d), got EVM %d", expectedEvmChainID, MANAGEMENT_CHAIN_ID, actualEvmChainID)
+	if err := target.verifyChainID(actualChainID); err != nil {
+		log.Printf("⚠️  %v", err)
+		return nil, err
 	}
 
 	// Usechain ID（EVM Chain ID）
@@ -493,9 +574,9 @@ func (b *BlockchainTransactionService) SubmitWithdraw(req *WithdrawRequest) (*Wi
 func (b *BlockchainTransactionService) submitWithdrawViaQueue(req *WithdrawRequest) (*WithdrawResponse, error) {
 	log.Printf("🚀 [SubmitWithdraw] Enqueuing withdraw transaction...")
 
-	// 获取签名地址
-	const MANAGEMENT_CHAIN_ID = 714 // BSC
-	networkConfig, err := config.GetNetworkConfigByChainID(MANAGEMENT_CHAIN_ID)
+	// 获取签名地址（提交链由 withdrawSubmitChain 决定，默认管理链）
+	submitChainID := withdrawSubmitChain(req)
+	networkConfig, err := config.GetNetworkConfigByChainID(submitChainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network config: %w", err)
 	}
@@ -505,11 +586,11 @@ func (b *BlockchainTransactionService) submitWithdrawViaQueue(req *WithdrawReque
 		return nil, fmt.Errorf("failed to get signing address: %w", err)
 	}
 
-	// 入队（使用 MANAGEMENT_CHAIN_ID，因为 Withdraw 提交到 BSC）
+	// 入队（使用提交链 chainID，默认管理链 714）
 	queueID, err := b.queueService.EnqueueWithdraw(
 		signingAddress,
-		uint32(MANAGEMENT_CHAIN_ID), // 使用 BSC 的 chainID (714)
-		req.CheckID,                 // 使用 CheckID 作为 RequestID
+		uint32(submitChainID), // 使用提交链的 chainID
+		req.CheckID,           // 使用 CheckID 作为 RequestID
 		req.CheckbookID,
 		req.CheckID,
 		req,
@@ -533,10 +614,37 @@ func (b *BlockchainTransactionService) submitWithdrawViaQueue(req *WithdrawReque
 
 // submitWithdrawDirect 直接提交 withdraw（原有逻辑）
 func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest) (*WithdrawResponse, error) {
+	_, signedTx, err := b.sendWithdrawDirect(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// response
+	return &WithdrawResponse{
+		TxHash:    signedTx.Hash().Hex(),
+		GasUsed:   signedTx.Gas(), // gas limit, the receipt is not available yet (actual usage: execute_gas_used)
+		GasPrice:  signedTx.GasPrice().String(),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// SubmitWithdrawAndWait submits a withdraw and blocks until it is mined, for callers that need the outcome (admin tools)
+// The transaction is always sent directly, not through the queue. The response carries the receipt's actual GasUsed;
+// a reverted transaction, or no receipt within timeout, is returned as an error.
+func (b *BlockchainTransactionService) SubmitWithdrawAndWait(req *WithdrawRequest, timeout time.Duration) (*WithdrawResponse, error) {
+	client, signedTx, err := b.sendWithdrawDirect(req)
+	if err != nil {
+		return nil, err
+	}
+	return b.processTransaction(client, signedTx, req, timeout)
+}
+
+// sendWithdrawDirect signs and sends executeWithdraw on the submission chain, returning the client and sent transaction
+func (b *BlockchainTransactionService) sendWithdrawDirect(req *WithdrawRequest) (*ethclient.Client, *types.Transaction, error) {
 	log.Printf("🚀 [SubmitWithdraw] startprocesswithdraw:")
 	log.Printf("   Serviceaddress: %p", b)
 	log.Printf("   clients mapaddress: %p", b.clients)
-	log.Printf("   clients map: %d", len(b.clients))
+	log.Printf("   clients map: %d", b.GetClientCount())
 	log.Printf("📋 [Withdrawrequest]:")
 	log.Printf("   ChainID: %d", req.ChainID)
 	log.Printf("   CheckbookID: %s", req.CheckbookID)
@@ -553,14 +661,15 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 		return req.SP1Proof
 	}())
 
-	// Getnetworkconfiguration - withdrawBSC
-	const MANAGEMENT_CHAIN_ID = 714 // BSCID
-	log.Printf("🏗️ [SubmitWithdraw] : BSC(714)，target(%d)recordcontract", req.ChainID)
-	networkConfig, err := config.GetNetworkConfigByChainID(MANAGEMENT_CHAIN_ID)
+	// Submission target - management chain by default, see withdrawSubmitChain
+	target, err := submissionTargetForChain(submissionWithdraw, withdrawSubmitChain(req))
 	if err != nil {
-		log.Printf("❌ Getnetworkconfigurationfailed: %v", err)
-		return nil, fmt.Errorf("failed to get network config: %w", err)
+		log.Printf("❌ Resolve submission target failed: %v", err)
+		return nil, nil, err
 	}
+	submitChainID := target.slip44ChainID
+	log.Printf("🏗️ [SubmitWithdraw] : submit(%d)，target(%d)recordcontract", submitChainID, req.ChainID)
+	networkConfig := target.network
 
 	// Checkconfiguration（configuration）
 	useKMS := false
@@ -574,15 +683,15 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 		log.Printf("🔑  (KMSnotconfiguration)")
 		useKMS = false
 	} else {
-		log.Printf("❌ configuration: chainID=%d (KMS)", MANAGEMENT_CHAIN_ID)
-		return nil, fmt.Errorf("no signing method configured for management chainID %d", MANAGEMENT_CHAIN_ID)
+		log.Printf("❌ configuration: chainID=%d (KMS)", submitChainID)
+		return nil, nil, fmt.Errorf("no signing method configured for submission chainID %d", submitChainID)
 	}
 
 	// Getclient
-	client, exists := b.clients[MANAGEMENT_CHAIN_ID]
+	client, exists := b.lookupClient(submitChainID)
 	if !exists {
-		log.Printf("❌ RPCclientnotinitialize: chainID=%d", MANAGEMENT_CHAIN_ID)
-		return nil, fmt.Errorf("management chain client not initialized for chainID %d", MANAGEMENT_CHAIN_ID)
+		log.Printf("❌ RPCclientnotinitialize: chainID=%d", submitChainID)
+		return nil, nil, fmt.Errorf("submission chain client not initialized for chainID %d", submitChainID)
 	}
 
 	// 🔍 RPCconnectionstatus
@@ -590,7 +699,7 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 	blockNumber, err := client.BlockNumber(context.Background())
 	if err != nil {
 		log.Printf("❌ RPCconnectionfailed: %v", err)
-		return nil, fmt.Errorf("failed to test RPC connection: %w", err)
+		return nil, nil, fmt.Errorf("failed to test RPC connection: %w", err)
 	}
 	log.Printf("✅ RPCconnection，currentblock number: %d", blockNumber)
 
@@ -598,7 +707,7 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 	signingAddress, err := b.keyMgmtService.GetSigningAddress(networkConfig)
 	if err != nil {
 		log.Printf("❌ Getaddressfailed: %v", err)
-		return nil, fmt.Errorf("failed to get signing address: %w", err)
+		return nil, nil, fmt.Errorf("failed to get signing address: %w", err)
 	}
 	fromAddress := common.HexToAddress(signingAddress)
 	log.Printf("📍 useaddress: %s", fromAddress.Hex())
@@ -608,7 +717,7 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 	balance, err := client.BalanceAt(context.Background(), fromAddress, nil)
 	if err != nil {
 		log.Printf("❌ queryfailed: %v", err)
-		return nil, fmt.Errorf("failed to query balance: %w", err)
+		return nil, nil, fmt.Errorf("failed to query balance: %w", err)
 	}
 
 	// Convert
@@ -619,23 +728,20 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 	actualChainID, err := client.NetworkID(context.Background())
 	if err != nil {
 		log.Printf("❌ Getchain IDfailed: %v", err)
-		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+		return nil, nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	// Verify chain ID: We're connecting to MANAGEMENT_CHAIN_ID (BSC 714), so verify BSC's EVM Chain ID (56)
-	// req.ChainID is the target chain (beneficiary chain), not the chain we're submitting to
-	expectedEvmChainID := utils.Slip44ToEvm(MANAGEMENT_CHAIN_ID) // BSC SLIP-44 714 -> EVM 56
-	actualEvmChainID := actualChainID.Uint64()
-
+	// Verify chain ID against the submission target: the management chain by default, the beneficiary chain
+	// (req.ChainID) when withdraws are routed to it
 	log.Printf("🔗 chain ID:")
-	log.Printf("   managementSLIP-44: %d (BSC)", MANAGEMENT_CHAIN_ID)
+	log.Printf("   submitSLIP-44: %d", submitChainID)
 	log.Printf("   targetSLIP-44: %d (beneficiary)", req.ChainID)
-	log.Printf("   expectedEVM Chain ID: %d (BSC)", expectedEvmChainID)
-	log.Printf("   actualEVM Chain ID: %d (from RPC)", actualEvmChainID)
+	log.Printf("   expectedEVM Chain ID: %d (submission)", target.evmChainID)
+	log.Printf("   actualEVM Chain ID: %s (from RPC)", actualChainID)
 
-	if actualEvmChainID != uint64(expectedEvmChainID) {
-		log.Printf("⚠️  Chain ID mismatch! Expected EVM %d (BSC), got EVM %d", expectedEvmChainID, actualEvmChainID)
-		return nil, fmt.Errorf("chain ID mismatch: expected EVM %d (BSC SLIP-44 %d), got EVM %d", expectedEvmChainID, MANAGEMENT_CHAIN_ID, actualEvmChainID)
+	if err := target.verifyChainID(actualChainID); err != nil {
+		log.Printf("⚠️  %v", err)
+		return nil, nil, err
 	}
 
 	// Usechain ID（EVM Chain ID）
@@ -649,11 +755,12 @@ func (b *BlockchainTransactionService) submitWithdrawDirect(req *WithdrawRequest
 		strategy = &PrivateKeySigningStrategy{keyMgmt: b.keyMgmtService}
 	}
 
-	return b.submitWithdrawWithSigner(client, networkConfig, req, fromAddress, chainID, strategy)
+	signedTx, err := b.sendWithdrawWithSigner(client, networkConfig, req, fromAddress, chainID, strategy)
+	return client, signedTx, err
 }
 
-// submitWithdrawWithSigner  Withdraw （use）
-func (b *BlockchainTransactionService) submitWithdrawWithSigner(client *ethclient.Client, networkConfig *config.NetworkConfig, req *WithdrawRequest, fromAddress common.Address, chainID *big.Int, strategy SigningStrategy) (*WithdrawResponse, error) {
+// sendWithdrawWithSigner  Withdraw （use）, returns the sent transaction
+func (b *BlockchainTransactionService) sendWithdrawWithSigner(client *ethclient.Client, networkConfig *config.NetworkConfig, req *WithdrawRequest, fromAddress common.Address, chainID *big.Int, strategy SigningStrategy) (*types.Transaction, error) {
 	log.Printf("🔑 use %s ", strategy.Name())
 
 	// GetVerify
@@ -663,8 +770,17 @@ func (b *BlockchainTransactionService) submitWithdrawWithSigner(client *ethclien
 		return nil, fmt.Errorf("failed to query balance: %w", err)
 	}
 
+	// Release the allocated nonce if the transaction is never broadcast
+	var tx *types.Transaction
+	sent := false
+	defer func() {
+		if tx != nil && !sent {
+			b.nonceManager.release(chainID, fromAddress, tx.Nonce())
+		}
+	}()
+
 	// not
-	tx, err := b.buildUnsignedTransaction(client, networkConfig, req, fromAddress, chainID)
+	tx, err = b.buildUnsignedTransaction(client, networkConfig, req, fromAddress, chainID)
 	if err != nil {
 		log.Printf("❌ notfailed: %v", err)
 		return nil, fmt.Errorf("failed to build unsigned transaction: %w", err)
@@ -675,57 +791,59 @@ func (b *BlockchainTransactionService) submitWithdrawWithSigner(client *ethclien
 		return nil, err
 	}
 
-	// GetEIP155hash
-	signer := types.NewEIP155Signer(chainID)
-	sigHash := signer.Hash(tx)
-	log.Printf("📝 hash: %s", tx.Hash().Hex())
-	log.Printf("📝 EIP155hash: %s", sigHash.Hex())
+	sign := func(tx *types.Transaction) (*types.Transaction, error) {
+		// GetEIP155hash
+		signer := types.NewEIP155Signer(chainID)
+		sigHash := signer.Hash(tx)
+		log.Printf("📝 hash: %s", tx.Hash().Hex())
+		log.Printf("📝 EIP155hash: %s", sigHash.Hex())
 
-	// use
-	signature, err := strategy.Sign(networkConfig, sigHash.Bytes(), sigHash.Hex())
-	if err != nil {
-		log.Printf("❌ %s failed: %v", strategy.Name(), err)
-		return nil, fmt.Errorf("failed to sign with %s: %w", strategy.Name(), err)
-	}
+		// use
+		signature, err := strategy.Sign(networkConfig, sigHash.Bytes(), sigHash.Hex())
+		if err != nil {
+			log.Printf("❌ %s failed: %v", strategy.Name(), err)
+			return nil, fmt.Errorf("failed to sign with %s: %w", strategy.Name(), err)
+		}
 
-	signedTx, err := b.applySignatureToTransaction(tx, signature, chainID)
-	if err != nil {
-		log.Printf("❌ failed: %v", err)
-		return nil, fmt.Errorf("failed to apply signature: %w", err)
-	}
+		signedTx, err := b.applySignatureToTransaction(tx, signature, chainID)
+		if err != nil {
+			log.Printf("❌ failed: %v", err)
+			return nil, fmt.Errorf("failed to apply signature: %w", err)
+		}
 
-	// Verifyaddress
-	actualSender, err := types.Sender(signer, signedTx)
-	if err != nil {
-		log.Printf("❌ address: %v", err)
-	} else {
-		log.Printf("✅ Verifysuccess，address: %s", actualSender.Hex())
-		if actualSender != fromAddress {
-			log.Printf("⚠️  ：addressdifferent from: expected=%s, actual=%s", fromAddress.Hex(), actualSender.Hex())
+		// Verifyaddress
+		actualSender, err := verifySignedSender(signer, signedTx, fromAddress, strategy)
+		if err != nil {
+			log.Printf("❌ addressVerifyfailed: %v", err)
+			return nil, err
 		}
+		log.Printf("✅ Verifysuccess，address: %s", actualSender.Hex())
+		return signedTx, nil
+	}
+	checkBalance := func(tx *types.Transaction) error {
+		return b.validateGasBalance(client, networkConfig, tx, balance, fromAddress)
 	}
 
-	log.Printf("🚀 startblockchain...")
-	err = client.SendTransaction(context.Background(), signedTx)
+	signedTx, err := sendReplacingOwnPending(context.Background(), client, tx, req.PreviousTxHash, networkConfig, sign, checkBalance)
 	if err != nil {
-		log.Printf("❌ failed: %v", err)
-		return nil, fmt.Errorf("failed to send transaction: %w", err)
+		if signedTx != nil {
+			sendErr := fmt.Errorf("failed to send transaction: %w", err)
+			if reason, detail := parseRevertReason(err, client, signedTx); reason != RevertReasonNone {
+				log.Printf("   revert reason: %s (%s)", reason, detail)
+				return nil, &RevertError{Reason: reason, Detail: detail, Err: sendErr}
+			}
+			return nil, sendErr
+		}
+		return nil, err
 	}
+	sent = true
 
 	log.Printf("✅ success！")
 	log.Printf("   hash: %s", signedTx.Hash().Hex())
 	log.Printf("   Gas: %s wei", signedTx.GasPrice().String())
 	log.Printf("   GasRestrict: %d", signedTx.Gas())
 
-	// response
-	response := &WithdrawResponse{
-		TxHash:    signedTx.Hash().Hex(),
-		GasUsed:   signedTx.Gas(), // gasUseneedreceiptGet
-		GasPrice:  signedTx.GasPrice().String(),
-		Timestamp: time.Now().Unix(),
-	}
-
-	return response, nil
+	return signedTx, nil
 }
 
 // waitForTransactionWithRetry Waitconfirm
@@ -859,7 +977,7 @@ func (b *BlockchainTransactionService) waitForTransactionWithRetry(client *ethcl
 
 // EstimateGas Gas
 func (b *BlockchainTransactionService) EstimateGas(chainID int, from, to common.Address, data []byte) (uint64, error) {
-	client, exists := b.clients[chainID]
+	client, exists := b.lookupClient(chainID)
 	if !exists {
 		return 0, fmt.Errorf("client not initialized for chainID %d", chainID)
 	}
@@ -894,13 +1012,14 @@ func (b *BlockchainTransactionService) recordFailedTransaction(req *WithdrawRequ
 		Amount:        req.Amount,
 		RetryCount:    0,
 		MaxRetries:    10,
-		NextRetryAt:   time.Now().Add(10 * time.Second), // 10secondsstartretry
 		LastError:     "",
 		OriginalError: errorMsg,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
 
+	failedTx.NextRetryAt = failedTx.CalculateNextRetryTime() // first retry after ~10s, then exponential backoff
+
 	if err := db.DB.Create(failedTx).Error; err != nil {
 		return fmt.Errorf("failed to record failed transaction: %w", err)
 	}
@@ -912,30 +1031,12 @@ func (b *BlockchainTransactionService) recordFailedTransaction(req *WithdrawRequ
 // setupGasAndValidateBalance SetgasandVerify
 func (b *BlockchainTransactionService) setupGasAndValidateBalance(client *ethclient.Client, networkConfig *config.NetworkConfig, auth *bind.TransactOpts, balance *big.Int, fromAddress common.Address) error {
 	// Setgas
-	if networkConfig.GasPrice != "" && networkConfig.GasPrice != "auto" {
-		// ifconfigurationgas price，Useconfiguration
-		gasPrice, ok := new(big.Int).SetString(networkConfig.GasPrice, 10)
-		if ok {
-			auth.GasPrice = gasPrice
-			log.Printf("⛽ useconfigurationGas Price: %s wei", networkConfig.GasPrice)
-		}
-	} else {
-		// Getcurrentnetworkgas price
-		suggestedGasPrice, err := client.SuggestGasPrice(context.Background())
-		if err != nil {
-			log.Printf("⚠️ Getgas pricefailed，use: %v", err)
-			auth.GasPrice = big.NewInt(5000000000) // 5 Gwei
-		} else {
-			// 20%
-			multiplier := big.NewInt(120)
-			hundred := big.NewInt(100)
-			adjustedGasPrice := new(big.Int).Mul(suggestedGasPrice, multiplier)
-			adjustedGasPrice = adjustedGasPrice.Div(adjustedGasPrice, hundred)
-			auth.GasPrice = adjustedGasPrice
-			log.Printf("⛽ GetGas Price: %s wei (: %s wei + 20%%)",
-				adjustedGasPrice.String(), suggestedGasPrice.String())
-		}
+	gasPrice, err := computeGasPrice(client, networkConfig)
+	if err != nil {
+		return err
 	}
+	auth.GasPrice = gasPrice
+	log.Printf("⛽ Gas Price: %s wei", gasPrice.String())
 
 	if networkConfig.GasLimit > 0 {
 		auth.GasLimit = networkConfig.GasLimit
@@ -956,18 +1057,10 @@ func (b *BlockchainTransactionService) validateGasBalance(client *ethclient.Clie
 		gasLimit = tx.Gas()
 	} else {
 		// networkconfigurationGetorUseDefault
-		if networkConfig.GasPrice != "" && networkConfig.GasPrice != "auto" {
-			gasPrice, _ = new(big.Int).SetString(networkConfig.GasPrice, 10)
-		} else {
-			suggestedGasPrice, err := client.SuggestGasPrice(context.Background())
-			if err != nil {
-				gasPrice = big.NewInt(5000000000) // 5 Gwei
-			} else {
-				multiplier := big.NewInt(120)
-				hundred := big.NewInt(100)
-				gasPrice = new(big.Int).Mul(suggestedGasPrice, multiplier)
-				gasPrice = gasPrice.Div(gasPrice, hundred)
-			}
+		var err error
+		gasPrice, err = computeGasPrice(client, networkConfig)
+		if err != nil {
+			return err
 		}
 
 		if networkConfig.GasLimit > 0 {
@@ -1003,26 +1096,16 @@ func (b *BlockchainTransactionService) validateGasBalance(client *ethclient.Clie
 
 // buildUnsignedTransaction not
 func (b *BlockchainTransactionService) buildUnsignedTransaction(client *ethclient.Client, networkConfig *config.NetworkConfig, req *WithdrawRequest, fromAddress common.Address, chainID *big.Int) (*types.Transaction, error) {
-	// Getnonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	// Setgas (before allocating a nonce, so a rejected gas price does not consume one)
+	gasPrice, err := computeGasPrice(client, networkConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return nil, err
 	}
 
-	// Setgas
-	var gasPrice *big.Int
-	if networkConfig.GasPrice != "" && networkConfig.GasPrice != "auto" {
-		gasPrice, _ = new(big.Int).SetString(networkConfig.GasPrice, 10)
-	} else {
-		suggestedGasPrice, err := client.SuggestGasPrice(context.Background())
-		if err != nil {
-			gasPrice = big.NewInt(5000000000) // 5 Gwei
-		} else {
-			multiplier := big.NewInt(120)
-			hundred := big.NewInt(100)
-			gasPrice = new(big.Int).Mul(suggestedGasPrice, multiplier)
-			gasPrice = gasPrice.Div(gasPrice, hundred)
-		}
+	// Getnonce (serialized per signer)
+	nonce, err := b.nonceManager.acquire(client, chainID, fromAddress)
+	if err != nil {
+		return nil, err
 	}
 
 	// SetgasRestrict
@@ -1036,6 +1119,7 @@ func (b *BlockchainTransactionService) buildUnsignedTransaction(client *ethclien
 	// data
 	txData, err := b.buildWithdrawCallData(networkConfig, req)
 	if err != nil {
+		b.nonceManager.release(chainID, fromAddress, nonce)
 		return nil, fmt.Errorf("failed to build call data: %w", err)
 	}
 
@@ -1043,6 +1127,7 @@ func (b *BlockchainTransactionService) buildUnsignedTransaction(client *ethclien
 	// Get ZKPay contract address - priority: Database > networkConfig
 	zkpayContract, err := getZKPayContractAddress(networkConfig)
 	if err != nil {
+		b.nonceManager.release(chainID, fromAddress, nonce)
 		return nil, fmt.Errorf("failed to get ZKPay contract address: %w", err)
 	}
 	contractAddress := common.HexToAddress(zkpayContract)
@@ -1086,26 +1171,11 @@ type WithdrawPublicValues struct {
 }
 
 // buildWithdrawCallData builds the call data for executeWithdraw function
-// New signature: executeWithdraw(bytes calldata proof, bytes calldata encodedPublicValues)
+// The signature follows the network's contractAbiVersion (see contract_abi.go).
 func (b *BlockchainTransactionService) buildWithdrawCallData(networkConfig *config.NetworkConfig, req *WithdrawRequest) ([]byte, error) {
-	// ZKPay contract ABI - new executeWithdraw signature
-	zkPayABI := `[
-		{
-			"inputs": [
-				{"name": "proof", "type": "bytes"},
-				{"name": "encodedPublicValues", "type": "bytes"}
-			],
-			"name": "executeWithdraw",
-			"outputs": [],
-			"stateMutability": "nonpayable",
-			"type": "function"
-		}
-	]`
-
-	// Parse ABI
-	parsedABI, err := abi.JSON(strings.NewReader(zkPayABI))
+	contract, err := contractABIFor(networkConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+		return nil, err
 	}
 
 	// Verify recipient format
@@ -1138,6 +1208,24 @@ func (b *BlockchainTransactionService) buildWithdrawCallData(networkConfig *conf
 			}
 		}
 
+		// Intent type: RawToken uses adapterId=0, AssetToken takes adapterId from AssetID
+		var adapterID uint32
+		switch req.IntentType {
+		case uint8(models.IntentTypeRawToken):
+			adapterID = 0 // Not used for RawToken
+		case uint8(models.IntentTypeAssetToken):
+			if req.AssetID == "" {
+				return nil, fmt.Errorf("asset ID is required for AssetToken withdraw")
+			}
+			_, assetAdapterID, _, err := utils.DecodeAssetID(req.AssetID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid asset ID %s: %w", req.AssetID, err)
+			}
+			adapterID = assetAdapterID
+		default:
+			return nil, fmt.Errorf("unsupported intent type: %d", req.IntentType)
+		}
+
 		// Build WithdrawPublicValues struct
 		commitmentRoot := common.HexToHash(req.QueueRoot)
 		nullifiers := []common.Hash{common.HexToHash(req.NullifierHash)}
@@ -1154,9 +1242,9 @@ func (b *BlockchainTransactionService) buildWithdrawCallData(networkConfig *conf
 			CommitmentRoot:  commitmentRoot,
 			Nullifiers:      nullifiersBytes,
 			Amount:          amount,
-			IntentType:      0, // Default to 0 (RawToken)
+			IntentType:      req.IntentType,
 			Slip44ChainID:   uint32(req.ChainID),
-			AdapterId:       0,            // Not used for RawToken
+			AdapterId:       adapterID,
 			TokenKey:        req.TokenKey, // Token key (same as tokenSymbol)
 			BeneficiaryData: beneficiaryData,
 			MinOutput:       [32]byte{}, // No minimum output constraint
@@ -1217,15 +1305,15 @@ func (b *BlockchainTransactionService) buildWithdrawCallData(networkConfig *conf
 		log.Printf("   amount: %s", req.Amount)
 		log.Printf("   recipient: %s", req.Recipient)
 		log.Printf("   tokenKey: %s", req.TokenKey)
+		log.Printf("   intentType: %d, assetID: %s", req.IntentType, req.AssetID)
 	}
 
-	// Pack with new signature: executeWithdraw(bytes proof, bytes encodedPublicValues)
-	data, err := parsedABI.Pack("executeWithdraw", proof, encodedPublicValues)
+	data, err := contract.packWithdraw(contract.parsed, proof, encodedPublicValues)
 	if err != nil {
-		return nil, fmt.Errorf("failed to pack withdraw function: %w", err)
+		return nil, fmt.Errorf("failed to pack withdraw function (contract ABI v%d): %w", contract.version, err)
 	}
 
-	log.Printf("✅ [withdraw] data success, data: %d bytes", len(data))
+	log.Printf("✅ [withdraw] data success, data: %d bytes, contract ABI v%d", len(data), contract.version)
 	return data, nil
 }
 
@@ -1242,8 +1330,26 @@ func (b *BlockchainTransactionService) applySignatureToTransaction(tx *types.Tra
 	return signedTx, nil
 }
 
-// processTransaction process（Waitconfirmreturn）
-func (b *BlockchainTransactionService) processTransaction(client *ethclient.Client, tx *types.Transaction, req *WithdrawRequest) (*WithdrawResponse, error) {
+// verifySignedSender recovers the sender of a signed transaction and rejects it unless it is fromAddress
+// (and, for KMS, the address the signature was verified against)
+func verifySignedSender(signer types.Signer, signedTx *types.Transaction, fromAddress common.Address, strategy SigningStrategy) (common.Address, error) {
+	actualSender, err := types.Sender(signer, signedTx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover sender address: %w", err)
+	}
+	if actualSender != fromAddress {
+		return common.Address{}, fmt.Errorf("sender address mismatch: expected %s, got %s", fromAddress.Hex(), actualSender.Hex())
+	}
+	if kms, ok := strategy.(*KMSSigningStrategy); ok {
+		if verified, ok := kms.VerifiedAddress(); !ok || verified != actualSender {
+			return common.Address{}, fmt.Errorf("sender %s does not match KMS signing address %s", actualSender.Hex(), verified.Hex())
+		}
+	}
+	return actualSender, nil
+}
+
+// processTransaction process（Waitconfirmreturn）, waiting at most timeout for the receipt
+func (b *BlockchainTransactionService) processTransaction(client *ethclient.Client, tx *types.Transaction, req *WithdrawRequest, timeout time.Duration) (*WithdrawResponse, error) {
 	log.Printf("✅ success:")
 	log.Printf("   hash: %s", tx.Hash().Hex())
 	log.Printf("   Gas Price: %s wei", tx.GasPrice().String())
@@ -1252,7 +1358,7 @@ func (b *BlockchainTransactionService) processTransaction(client *ethclient.Clie
 
 	// Wait
 	log.Printf("⏳ wait...")
-	receipt, err := b.waitForTransactionWithRetry(client, tx, 3*time.Minute)
+	receipt, err := b.waitForTransactionWithRetry(client, tx, timeout)
 	if err != nil {
 		log.Printf("❌ retryconfirm: %v", err)
 
@@ -1267,7 +1373,7 @@ func (b *BlockchainTransactionService) processTransaction(client *ethclient.Clie
 	// Checkstatus
 	if receipt.Status == 0 {
 		log.Printf("❌ failed")
-		return nil, fmt.Errorf("transaction failed")
+		return nil, fmt.Errorf("transaction %s reverted in block %d (gas used %d)", tx.Hash().Hex(), receipt.BlockNumber.Uint64(), receipt.GasUsed)
 	}
 
 	log.Printf("✅ success:")
@@ -1302,9 +1408,18 @@ func (b *BlockchainTransactionService) submitCommitmentWithSigner(client *ethcli
 	balanceEth := new(big.Float).Quo(new(big.Float).SetInt(balance), new(big.Float).SetInt64(1e18))
 	log.Printf("✅ [submitCommitmentWithSigner] Balance: %s wei (%.6f BNB)", balance.String(), balanceEth)
 
+	// Release the allocated nonce if the transaction is never broadcast
+	var tx *types.Transaction
+	sent := false
+	defer func() {
+		if tx != nil && !sent {
+			b.nonceManager.release(chainID, fromAddress, tx.Nonce())
+		}
+	}()
+
 	// not
 	log.Printf("🔧 [submitCommitmentWithSigner] Building unsigned transaction...")
-	tx, err := b.buildUnsignedCommitmentTransaction(client, networkConfig, req, fromAddress, chainID)
+	tx, err = b.buildUnsignedCommitmentTransaction(client, networkConfig, req, fromAddress, chainID)
 	if err != nil {
 		log.Printf("❌ [submitCommitmentWithSigner] Failed to build unsigned transaction: %v", err)
 		return nil, fmt.Errorf("failed to build unsigned transaction: %w", err)
@@ -1347,19 +1462,12 @@ func (b *BlockchainTransactionService) submitCommitmentWithSigner(client *ethcli
 
 	// Verifyaddress
 	log.Printf("🔍 [submitCommitmentWithSigner] Verifying sender address from signature...")
-	actualSender, err := types.Sender(signer, signedTx)
+	actualSender, err := verifySignedSender(signer, signedTx, fromAddress, strategy)
 	if err != nil {
-		log.Printf("❌ [submitCommitmentWithSigner] Failed to recover sender address: %v", err)
-	} else {
-		log.Printf("✅ [submitCommitmentWithSigner] Sender address verified: %s", actualSender.Hex())
-		if actualSender != fromAddress {
-			log.Printf("⚠️  [submitCommitmentWithSigner] Warning: sender address mismatch!")
-			log.Printf("   Expected: %s", fromAddress.Hex())
-			log.Printf("   Actual: %s", actualSender.Hex())
-		} else {
-			log.Printf("✅ [submitCommitmentWithSigner] Sender address matches expected address")
-		}
+		log.Printf("❌ [submitCommitmentWithSigner] Sender verification failed: %v", err)
+		return nil, err
 	}
+	log.Printf("✅ [submitCommitmentWithSigner] Sender address verified: %s", actualSender.Hex())
 
 	log.Printf("📤 [submitCommitmentWithSigner] ========================================")
 	log.Printf("📤 [submitCommitmentWithSigner] Sending transaction to blockchain...")
@@ -1373,6 +1481,7 @@ func (b *BlockchainTransactionService) submitCommitmentWithSigner(client *ethcli
 		log.Printf("❌ [submitCommitmentWithSigner] Failed to send transaction: %v", err)
 		return nil, fmt.Errorf("failed to send transaction: %w", err)
 	}
+	sent = true
 
 	log.Printf("✅ [submitCommitmentWithSigner] ========================================")
 	log.Printf("✅ [submitCommitmentWithSigner] Transaction sent to blockchain successfully!")
@@ -1405,36 +1514,24 @@ func (b *BlockchainTransactionService) buildUnsignedCommitmentTransaction(client
 	log.Printf("   From Address: %s", fromAddress.Hex())
 	log.Printf("   Chain ID: %s", chainID.String())
 
+	// Setgas (before allocating a nonce, so a rejected gas price does not consume one)
+	log.Printf("⛽ [buildUnsignedCommitmentTransaction] Setting gas price...")
+	gasPrice, err := computeGasPrice(client, networkConfig)
+	if err != nil {
+		log.Printf("❌ [buildUnsignedCommitmentTransaction] Failed to compute gas price: %v", err)
+		return nil, err
+	}
+	log.Printf("   Gas price: %s wei", gasPrice.String())
+
 	// Getnonce
 	log.Printf("🔢 [buildUnsignedCommitmentTransaction] Getting pending nonce...")
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	nonce, err := b.nonceManager.acquire(client, chainID, fromAddress)
 	if err != nil {
 		log.Printf("❌ [buildUnsignedCommitmentTransaction] Failed to get nonce: %v", err)
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return nil, err
 	}
 	log.Printf("✅ [buildUnsignedCommitmentTransaction] Nonce: %d", nonce)
 
-	// Setgas
-	log.Printf("⛽ [buildUnsignedCommitmentTransaction] Setting gas price...")
-	var gasPrice *big.Int
-	if networkConfig.GasPrice != "" && networkConfig.GasPrice != "auto" {
-		gasPrice, _ = new(big.Int).SetString(networkConfig.GasPrice, 10)
-		log.Printf("   Using configured gas price: %s wei", gasPrice.String())
-	} else {
-		log.Printf("   Getting suggested gas price from network...")
-		suggestedGasPrice, err := client.SuggestGasPrice(context.Background())
-		if err != nil {
-			gasPrice = big.NewInt(5000000000) // 5 Gwei
-			log.Printf("   ⚠️  Failed to get suggested gas price, using default: %s wei (5 Gwei)", gasPrice.String())
-		} else {
-			multiplier := big.NewInt(120)
-			hundred := big.NewInt(100)
-			gasPrice = new(big.Int).Mul(suggestedGasPrice, multiplier)
-			gasPrice = gasPrice.Div(gasPrice, hundred)
-			log.Printf("   ✅ Suggested gas price: %s wei, using 120%%: %s wei", suggestedGasPrice.String(), gasPrice.String())
-		}
-	}
-
 	// SetgasRestrict
 	var gasLimit uint64
 	if networkConfig.GasLimit > 0 {
@@ -1449,6 +1546,7 @@ func (b *BlockchainTransactionService) buildUnsignedCommitmentTransaction(client
 	log.Printf("📦 [buildUnsignedCommitmentTransaction] Building call data...")
 	txData, err := b.buildCommitmentCallData(networkConfig, req)
 	if err != nil {
+		b.nonceManager.release(chainID, fromAddress, nonce)
 		log.Printf("❌ [buildUnsignedCommitmentTransaction] Failed to build call data: %v", err)
 		return nil, fmt.Errorf("failed to build call data: %w", err)
 	}
@@ -1460,6 +1558,7 @@ func (b *BlockchainTransactionService) buildUnsignedCommitmentTransaction(client
 	// Get ZKPay contract address - priority: Database > networkConfig
 	zkpayContract, err := getZKPayContractAddress(networkConfig)
 	if err != nil {
+		b.nonceManager.release(chainID, fromAddress, nonce)
 		log.Printf("❌ [buildUnsignedCommitmentTransaction] Failed to get ZKPay contract address: %v", err)
 		return nil, fmt.Errorf("failed to get ZKPay contract address: %w", err)
 	}
@@ -1499,24 +1598,10 @@ func (b *BlockchainTransactionService) buildCommitmentCallData(networkConfig *co
 		}
 		return req.SP1Proof
 	}())
-	// ZKPay contract ABI - new executeCommitment signature
-	zkPayABI := `[
-		{
-			"inputs": [
-				{"name": "proof", "type": "bytes"},
-				{"name": "encodedPublicValues", "type": "bytes"}
-			],
-			"name": "executeCommitment",
-			"outputs": [],
-			"stateMutability": "nonpayable",
-			"type": "function"
-		}
-	]`
-
-	// ParseABI
-	parsedABI, err := abi.JSON(strings.NewReader(zkPayABI))
+	// executeCommitment signature of the network's contractAbiVersion
+	contract, err := contractABIFor(networkConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+		return nil, err
 	}
 
 	// Parseproof - ZKVM servicereturnhexproof.bytes()
@@ -1599,13 +1684,12 @@ func (b *BlockchainTransactionService) buildCommitmentCallData(networkConfig *co
 	log.Printf("   proof: %d bytes", len(proof))
 	log.Printf("   encodedPublicValues: %d bytes", len(encodedPublicValues))
 
-	// Pack with new signature: executeCommitment(bytes proof, bytes encodedPublicValues)
-	data, err := parsedABI.Pack("executeCommitment", proof, encodedPublicValues)
+	data, err := contract.packCommitment(contract.parsed, proof, encodedPublicValues)
 	if err != nil {
-		return nil, fmt.Errorf("failed to pack executeCommitment function: %w", err)
+		return nil, fmt.Errorf("failed to pack executeCommitment function (contract ABI v%d): %w", contract.version, err)
 	}
 
-	log.Printf("✅ [executeCommitment] datasuccess，data: %d bytes", len(data))
+	log.Printf("✅ [executeCommitment] datasuccess，data: %d bytes, contract ABI v%d", len(data), contract.version)
 	return data, nil
 }
 
@@ -1690,13 +1774,14 @@ func (b *BlockchainTransactionService) recordFailedCommitmentTransaction(req *Co
 		Amount:        req.AllocatableAmount,
 		RetryCount:    0,
 		MaxRetries:    10,
-		NextRetryAt:   time.Now().Add(10 * time.Second),
 		LastError:     "",
 		OriginalError: errorMsg,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
 
+	failedTx.NextRetryAt = failedTx.CalculateNextRetryTime() // first retry after ~10s, then exponential backoff
+
 	if err := db.DB.Create(failedTx).Error; err != nil {
 		return fmt.Errorf("failed to record failed commitment transaction: %w", err)
 	}
@@ -1707,6 +1792,10 @@ func (b *BlockchainTransactionService) recordFailedCommitmentTransaction(req *Co
 
 // Close clientconnection
 func (b *BlockchainTransactionService) Close() {
+	b.StopHealthWatcher()
+	b.StopBalanceMonitor()
+	b.clientsMu.RLock()
+	defer b.clientsMu.RUnlock()
 	for _, client := range b.clients {
 		client.Close()
 	}
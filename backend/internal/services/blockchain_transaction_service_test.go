@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// newStubReceiptServer answers eth_getTransactionReceipt with null for the first pendingPolls calls (not mined yet),
+// then with a receipt of the given status and gas used
+func newStubReceiptServer(t *testing.T, txHash common.Hash, pendingPolls int32, status, gasUsed uint64) *httptest.Server {
+	t.Helper()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := "null"
+		if req.Method == "eth_getTransactionReceipt" && polls.Add(1) > pendingPolls {
+			receipt, _ := json.Marshal(map[string]interface{}{
+				"transactionHash":   txHash.Hex(),
+				"blockHash":         common.HexToHash("0xb10c").Hex(),
+				"blockNumber":       "0x64",
+				"transactionIndex":  "0x0",
+				"status":            "0x" + big.NewInt(int64(status)).Text(16),
+				"gasUsed":           "0x" + big.NewInt(int64(gasUsed)).Text(16),
+				"cumulativeGasUsed": "0x" + big.NewInt(int64(gasUsed)).Text(16),
+				"logsBloom":         "0x" + strings.Repeat("0", 512),
+				"logs":              []interface{}{},
+			})
+			result = string(receipt)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newWaitFixture(t *testing.T, status uint64) (*ethclient.Client, *types.Transaction) {
+	t.Helper()
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(5_000_000_000), Gas: 600000, To: &to})
+	server := newStubReceiptServer(t, tx.Hash(), 1, status, 412345)
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial stub: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client, tx
+}
+
+func TestProcessTransactionWaitsForReceiptAndReportsGasUsed(t *testing.T) {
+	client, tx := newWaitFixture(t, types.ReceiptStatusSuccessful)
+	service := NewBlockchainTransactionService(nil)
+
+	response, err := service.processTransaction(client, tx, &WithdrawRequest{}, time.Minute)
+	if err != nil {
+		t.Fatalf("processTransaction: %v", err)
+	}
+	if response.TxHash != tx.Hash().Hex() || response.GasUsed != 412345 {
+		t.Errorf("response = %s gas %d, want %s gas 412345 from the receipt", response.TxHash, response.GasUsed, tx.Hash().Hex())
+	}
+	if response.GasPrice != "5000000000" {
+		t.Errorf("gas price = %s, want the transaction's 5000000000", response.GasPrice)
+	}
+}
+
+func TestProcessTransactionFailsOnRevertedReceipt(t *testing.T) {
+	client, tx := newWaitFixture(t, types.ReceiptStatusFailed)
+	service := NewBlockchainTransactionService(nil)
+
+	if _, err := service.processTransaction(client, tx, &WithdrawRequest{}, time.Minute); err == nil || !strings.Contains(err.Error(), "reverted") {
+		t.Fatalf("err = %v, want a revert error", err)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// Likely reasons a checkbook never reached ready_for_commitment, checked in this order
+const (
+	StuckReasonDepositRecordedMissing = "deposit_recorded_missing" // DepositRecorded was never stored (not emitted, not scanned or failed to process)
+	StuckReasonDepositInfoMissing     = "deposit_info_missing"     // DepositRecorded stored but no DepositInfo was written for it
+	StuckReasonTokenKeyMissing        = "token_key_missing"        // the checkbook's token_key was never resolved
+	StuckReasonUnknown                = "unknown"                  // everything present: the status update itself was lost (see cmd/repair-checkbook-status)
+)
+
+// StuckCheckbookDiagnosis one checkbook below ready_for_commitment and what its deposit is missing
+type StuckCheckbookDiagnosis struct {
+	CheckbookID     string                 `json:"checkbook_id"`
+	ChainID         uint32                 `json:"chain_id"`
+	LocalDepositID  uint64                 `json:"local_deposit_id"`
+	Status          models.CheckbookStatus `json:"status"`
+	CreatedAt       time.Time              `json:"created_at"`
+	DepositRecorded bool                   `json:"deposit_recorded"` // EventDepositRecorded exists for (chain_id, local_deposit_id)
+	DepositInfo     bool                   `json:"deposit_info"`     // DepositInfo exists for (chain_id, local_deposit_id)
+	TokenKey        bool                   `json:"token_key"`        // checkbook token_key is populated
+	Reason          string                 `json:"reason"`           // StuckReason*
+}
+
+// DiagnoseStuckCheckbooks lists checkbooks created more than olderThan ago that are still below ready_for_commitment
+// in the status progression, oldest first, with the stored data their deposit is missing.
+// Only forward statuses are considered; failed and deleted checkbooks are not stuck.
+func (p *BlockchainEventProcessor) DiagnoseStuckCheckbooks(ctx context.Context, olderThan time.Duration) ([]StuckCheckbookDiagnosis, error) {
+	statusProgression := p.getStatusProgression()
+	readyLevel := statusProgression[models.CheckbookStatusReadyForCommitment]
+	var stuckStatuses []models.CheckbookStatus
+	for status, level := range statusProgression {
+		if level < readyLevel {
+			stuckStatuses = append(stuckStatuses, status)
+		}
+	}
+
+	var checkbooks []models.Checkbook
+	err := p.db.WithContext(ctx).
+		Where("status IN ? AND created_at < ?", stuckStatuses, time.Now().Add(-olderThan)).
+		Order("created_at ASC").
+		Find(&checkbooks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stuck checkbooks: %w", err)
+	}
+
+	depositInfoRepo := repository.NewDepositInfoRepository(p.db)
+	diagnoses := make([]StuckCheckbookDiagnosis, 0, len(checkbooks))
+	for _, checkbook := range checkbooks {
+		diagnosis := StuckCheckbookDiagnosis{
+			CheckbookID:    checkbook.ID,
+			ChainID:        checkbook.SLIP44ChainID,
+			LocalDepositID: checkbook.LocalDepositID,
+			Status:         checkbook.Status,
+			CreatedAt:      checkbook.CreatedAt,
+			TokenKey:       checkbook.TokenKey != "",
+		}
+
+		chainID := int64(checkbook.SLIP44ChainID)
+		_, err := p.depositEventRepo.FindDepositRecordedByLocalID(ctx, chainID, checkbook.LocalDepositID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to query DepositRecorded of checkbook %s: %w", checkbook.ID, err)
+		}
+		diagnosis.DepositRecorded = err == nil
+
+		_, err = depositInfoRepo.GetByLocalID(ctx, chainID, checkbook.LocalDepositID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to query DepositInfo of checkbook %s: %w", checkbook.ID, err)
+		}
+		diagnosis.DepositInfo = err == nil
+
+		diagnosis.Reason = diagnoseStuckReason(diagnosis)
+		diagnoses = append(diagnoses, diagnosis)
+	}
+	return diagnoses, nil
+}
+
+// diagnoseStuckReason the first missing piece of a stuck checkbook's deposit
+func diagnoseStuckReason(d StuckCheckbookDiagnosis) string {
+	switch {
+	case !d.DepositRecorded:
+		return StuckReasonDepositRecordedMissing
+	case !d.DepositInfo:
+		return StuckReasonDepositInfoMissing
+	case !d.TokenKey:
+		return StuckReasonTokenKeyMissing
+	default:
+		return StuckReasonUnknown
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+)
+
+func TestDiagnoseStuckCheckbooks(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+
+	received := func(localDepositID uint64) {
+		t.Helper()
+		event := depositReceivedEvent(localDepositID)
+		event.TransactionHash = fmt.Sprintf("0xreceived-%d", localDepositID)
+		if err := processor.ProcessDepositReceived(event); err != nil {
+			t.Fatalf("ProcessDepositReceived %d: %v", localDepositID, err)
+		}
+	}
+	recorded := func(localDepositID uint64) {
+		t.Helper()
+		event := depositRecordedEvent(localDepositID, "USDT")
+		event.TransactionHash = fmt.Sprintf("0xrecorded-%d", localDepositID)
+		if err := processor.ProcessDepositRecorded(event); err != nil {
+			t.Fatalf("ProcessDepositRecorded %d: %v", localDepositID, err)
+		}
+	}
+
+	received(21) // DepositRecorded never arrived
+	received(22) // recorded, but its checkbook is back at unsigned without a token_key
+	recorded(22)
+	if err := database.Model(&models.Checkbook{}).Where("local_deposit_id = ?", 22).
+		Updates(map[string]interface{}{"status": models.CheckbookStatusUnsigned, "token_key": ""}).Error; err != nil {
+		t.Fatalf("rewind checkbook 22: %v", err)
+	}
+	received(23) // ready_for_commitment: not stuck
+	recorded(23)
+	if err := database.Model(&models.Checkbook{}).Where("local_deposit_id IN ?", []uint64{21, 22, 23}).
+		UpdateColumn("created_at", time.Now().Add(-2*time.Hour)).Error; err != nil {
+		t.Fatalf("backdate checkbooks: %v", err)
+	}
+	received(24) // stuck, but younger than the threshold
+
+	diagnoses, err := processor.DiagnoseStuckCheckbooks(context.Background(), time.Hour)
+	if err != nil {
+		t.Fatalf("DiagnoseStuckCheckbooks: %v", err)
+	}
+
+	got := make(map[uint64]StuckCheckbookDiagnosis)
+	for _, diagnosis := range diagnoses {
+		got[diagnosis.LocalDepositID] = diagnosis
+	}
+	if len(diagnoses) != 2 {
+		t.Fatalf("%d stuck checkbooks %v, want deposits 21 and 22", len(diagnoses), got)
+	}
+
+	missingRecorded, ok := got[21]
+	if !ok {
+		t.Fatalf("deposit 21 not diagnosed")
+	}
+	if missingRecorded.Reason != StuckReasonDepositRecordedMissing || missingRecorded.DepositRecorded || missingRecorded.DepositInfo {
+		t.Errorf("deposit 21 = %+v, want %s without DepositRecorded or DepositInfo", missingRecorded, StuckReasonDepositRecordedMissing)
+	}
+
+	missingTokenKey, ok := got[22]
+	if !ok {
+		t.Fatalf("deposit 22 not diagnosed")
+	}
+	if missingTokenKey.Reason != StuckReasonTokenKeyMissing || !missingTokenKey.DepositRecorded || !missingTokenKey.DepositInfo ||
+		missingTokenKey.TokenKey || missingTokenKey.Status != models.CheckbookStatusUnsigned {
+		t.Errorf("deposit 22 = %+v, want unsigned %s with DepositRecorded and DepositInfo", missingTokenKey, StuckReasonTokenKeyMissing)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"go-backend/internal/clients"
 	"go-backend/internal/config"
 	"log"
-	"math/big"
 	"strings"
 	"time"
 
@@ -27,6 +26,7 @@ type CheckbookService struct {
 	pushService       *WebSocketPushService
 	zkvmClient        *clients.ZKVMClient
 	blockchainService *BlockchainTransactionService // Use service container instance
+	tronService       *TronTransactionService       // Optional: commitments whose submission chain is TRON
 }
 
 // createCheckbookService
@@ -45,6 +45,11 @@ func NewCheckbookService(repo repository.CheckbookRepository, db *gorm.DB, polli
 	return service
 }
 
+// SetTronTransactionService sets the TRON transaction service for commitments submitted to TRON (SLIP-44 195)
+func (s *CheckbookService) SetTronTransactionService(service *TronTransactionService) {
+	s.tronService = service
+}
+
 // updateCheckbookstatus
 func (s *CheckbookService) UpdateStatus(checkbookID string, newStatus models.CheckbookStatus) error {
 	ctx := context.Background()
@@ -196,7 +201,7 @@ func (s *CheckbookService) RetryCommitmentSubmission(checkbookID string) error {
 		CommitmentHash: commitmentStr,
 	}
 
-	txHash, err := s.submitCommitmentToChain(checkbookID, proof)
+	txHash, submitChainID, err := s.submitCommitmentToChain(checkbookID, proof)
 	if err != nil {
 		// Update status to submission_failed on error
 		if updateErr := s.UpdateStatus(checkbookID, models.CheckbookStatusSubmissionFailed); updateErr != nil {
@@ -210,7 +215,7 @@ func (s *CheckbookService) RetryCommitmentSubmission(checkbookID string) error {
 		EntityType:    "checkbook",
 		EntityID:      checkbookID,
 		TaskType:      models.PollingCommitmentConfirmation,
-		ChainID:       56, // BSC
+		ChainID:       uint32(submitChainID), // chain the commitment was sent to
 		TxHash:        txHash,
 		TargetStatus:  string(models.CheckbookStatusWithCheckbook),
 		CurrentStatus: string(models.CheckbookStatusCommitmentPending),
@@ -258,7 +263,7 @@ func (s *CheckbookService) GenerateAndSubmitCommitment(checkbookID string, req G
 	}
 
 	// blockchain
-	txHash, err := s.submitCommitmentToChain(checkbookID, proof)
+	txHash, submitChainID, err := s.submitCommitmentToChain(checkbookID, proof)
 	if err != nil {
 		// Update status to submission_failed on error
 		if updateErr := s.UpdateStatus(checkbookID, models.CheckbookStatusSubmissionFailed); updateErr != nil {
@@ -272,7 +277,7 @@ func (s *CheckbookService) GenerateAndSubmitCommitment(checkbookID string, req G
 		EntityType:    "checkbook",
 		EntityID:      checkbookID,
 		TaskType:      models.PollingCommitmentConfirmation,
-		ChainID:       56, // BSC
+		ChainID:       uint32(submitChainID), // chain the commitment was sent to
 		TxHash:        txHash,
 		TargetStatus:  string(models.CheckbookStatusWithCheckbook),
 		CurrentStatus: string(models.CheckbookStatusCommitmentPending),
@@ -296,13 +301,17 @@ func (s *CheckbookService) GenerateAndSubmitCommitment(checkbookID string, req G
 	return nil
 }
 
-// commitment
-func (s *CheckbookService) submitCommitmentToChain(checkbookID string, proof *ProofResult) (string, error) {
+// submitCommitmentToChain submits the checkbook's commitment, returning the tx hash and the SLIP-44 chain it was sent to
+func (s *CheckbookService) submitCommitmentToChain(checkbookID string, proof *ProofResult) (string, int, error) {
 	log.Printf("🚀 commitmentblockchain...")
 
-	// blockchaincommitment
-	// proof
-	chainID := 714 // BSC SLIP-44 ChainID，configurationget
+	// The request carries the deposit chain; resolveSubmissionChain picks the chain it is sent to from it
+	checkbook, err := s.repo.GetByID(context.Background(), checkbookID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get checkbook: %w", err)
+	}
+	chainID := int(checkbook.SLIP44ChainID) // deposit chain SLIP-44 ChainID
+	submitChainID := resolveSubmissionChain(submissionCommitment, chainID)
 	localDepositID := s.parseLocalDepositIDFromProof(proof)
 	tokenKey := s.parseTokenKeyFromProof(proof) // Use token_key instead of token_id
 	allocatableAmount := s.parseAllocatableAmountFromProof(proof)
@@ -334,15 +343,15 @@ func (s *CheckbookService) submitCommitmentToChain(checkbookID string, proof *Pr
 		// initializeclient（useconfiguration）
 		if err := blockchainService.InitializeClients(); err != nil {
 			log.Printf("❌ initializeblockchainclientfailed: %v", err)
-			return "", fmt.Errorf("failed to initialize blockchain clients: %w", err)
+			return "", 0, fmt.Errorf("failed to initialize blockchain clients: %w", err)
 		}
 	}
 
-	// commitmentblockchain
-	response, err := blockchainService.SubmitCommitment(commitmentReq)
+	// commitmentblockchain - EVM or TRON by the chain it is submitted to
+	response, err := chainTransactionServiceFor(uint32(submitChainID), blockchainService, s.tronService).SubmitCommitment(commitmentReq)
 	if err != nil {
 		log.Printf("❌ commitmentfailed: %v", err)
-		return "", fmt.Errorf("failed to submit commitment: %w", err)
+		return "", 0, fmt.Errorf("failed to submit commitment: %w", err)
 	}
 
 	txHash := response.TxHash
@@ -352,7 +361,7 @@ func (s *CheckbookService) submitCommitmentToChain(checkbookID string, proof *Pr
 	log.Printf("   CommitmentHash: %s", proof.CommitmentHash)
 	log.Printf("   TxHash: %s", txHash)
 
-	return txHash, nil
+	return txHash, submitChainID, nil
 }
 
 // parseLocalDepositIDFromProof proofLocalDepositID
@@ -434,13 +443,18 @@ func generateCommitmentProof(zkClient *clients.ZKVMClient, req GenerateCommitmen
 	// gettoken
 	tokenSymbol, _, chainName := getTokenInfo(req.ChainInfo) // tokenID no longer used (replaced by token_key)
 
+	zkAllocations, err := convertAllocationsToZKVM(req.Allocations)
+	if err != nil {
+		return nil, err
+	}
+
 	// ZKVM API - Updated to use token_key and simplified allocations
 	zkRequest := &clients.BuildCommitmentRequest{
 		DepositID:   "1", // usecheckbookdatarecordgetDepositID
 		TokenKey:    tokenSymbol, // Use token_key instead of token_id
 		ChainName:   chainName,
 		Lang:        getLanguageCode(req.Language),
-		Allocations: convertAllocationsToZKVM(req.Allocations), // Simplified allocations (only seq and amount)
+		Allocations: zkAllocations, // Simplified allocations (only seq and amount)
 		Signature: clients.MultichainSignatureRequest{
 			ChainID:       56, // chain ID，configurationget
 			SignatureData: req.Signature,
@@ -475,30 +489,20 @@ func generateCommitmentProof(zkClient *clients.ZKVMClient, req GenerateCommitmen
 }
 
 // convertAllocationsToZKVM allocationsZKVM - Updated to use simplified allocation structure
-func convertAllocationsToZKVM(allocations []AllocationData) []clients.CommitmentAllocationRequest {
+// Amounts are converted to 32-byte HEX; an amount that is not a uint256 fails the conversion.
+func convertAllocationsToZKVM(allocations []AllocationData) ([]clients.CommitmentAllocationRequest, error) {
 	zkAllocations := make([]clients.CommitmentAllocationRequest, len(allocations))
 	for i, alloc := range allocations {
-		// Convert amount to HEX format (32 bytes, 64 hex chars, no 0x prefix)
-		amountHex := alloc.Amount
-		if !strings.HasPrefix(amountHex, "0x") {
-			// If not hex, assume it's decimal and convert
-			amountBig, ok := new(big.Int).SetString(amountHex, 10)
-			if ok {
-				amountHex = fmt.Sprintf("%064x", amountBig)
-			}
-		} else {
-			amountHex = strings.TrimPrefix(amountHex, "0x")
-			if len(amountHex) < 64 {
-				amountHex = strings.Repeat("0", 64-len(amountHex)) + amountHex
-			}
+		amountHex, err := AllocationAmountHex(alloc.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("allocation %d: %w", i, err)
 		}
-		
 		zkAllocations[i] = clients.CommitmentAllocationRequest{
-			Seq:    uint8(i), // Allocation sequence (0-255)
+			Seq:    uint8(i),  // Allocation sequence (0-255)
 			Amount: amountHex, // 32 bytes HEX format (no 0x prefix)
 		}
 	}
-	return zkAllocations
+	return zkAllocations, nil
 }
 
 // extractAddressFromSignature address
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestConvertAllocationsToZKVMPadsAmounts(t *testing.T) {
+	allocations, err := convertAllocationsToZKVM([]AllocationData{{Amount: "255"}, {Amount: "0xff"}})
+	if err != nil {
+		t.Fatalf("convertAllocationsToZKVM: %v", err)
+	}
+	want := strings.Repeat("0", 62) + "ff"
+	for i, allocation := range allocations {
+		if allocation.Amount != want || allocation.Seq != uint8(i) {
+			t.Errorf("allocation %d = seq %d amount %s, want seq %d amount %s", i, allocation.Seq, allocation.Amount, i, want)
+		}
+	}
+}
+
+func TestConvertAllocationsToZKVMRejectsInvalidAmounts(t *testing.T) {
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	overflow := new(big.Int).Add(maxUint256, big.NewInt(1))
+
+	tests := []struct {
+		name   string
+		amount string
+	}{
+		{"non-numeric", "12abc"},
+		{"empty", ""},
+		{"negative", "-1"},
+		{"non-hex", "0xzz"},
+		{"decimal above uint256", overflow.String()},
+		{"hex above uint256", "0x" + overflow.Text(16)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := convertAllocationsToZKVM([]AllocationData{{Amount: "1"}, {Amount: tt.amount}})
+			if !errors.Is(err, ErrInvalidAllocationAmount) {
+				t.Fatalf("err = %v, want ErrInvalidAllocationAmount", err)
+			}
+			if !strings.Contains(err.Error(), "allocation 1") {
+				t.Errorf("err = %v, want it to name allocation 1", err)
+			}
+		})
+	}
+
+	if amount, err := AllocationAmountHex(maxUint256.String()); err != nil || amount != strings.Repeat("f", 64) {
+		t.Errorf("AllocationAmountHex(2^256-1) = %s, %v; want 64 f's", amount, err)
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// TimelineEntryType kind of a checkbook timeline entry
+type TimelineEntryType string
+
+const (
+	TimelineDepositReceived       TimelineEntryType = "deposit_received"
+	TimelineDepositRecorded       TimelineEntryType = "deposit_recorded"
+	TimelineDepositUsed           TimelineEntryType = "deposit_used"
+	TimelineCommitmentRootUpdated TimelineEntryType = "commitment_root_updated"
+	TimelineStatusTransition      TimelineEntryType = "status_transition"
+)
+
+// CheckbookTimelineEntry one on-chain event or status transition of a checkbook
+// Event holds the underlying row (*models.EventDepositReceived, ..., *models.StatusTransition).
+type CheckbookTimelineEntry struct {
+	Type            TimelineEntryType `json:"type"`
+	Timestamp       time.Time         `json:"timestamp"` // block timestamp for events, created_at for status transitions
+	ChainID         int64             `json:"chain_id,omitempty"`
+	BlockNumber     uint64            `json:"block_number,omitempty"`
+	LogIndex        uint              `json:"log_index,omitempty"`
+	TransactionHash string            `json:"transaction_hash,omitempty"`
+	Event           interface{}       `json:"event"`
+}
+
+// CheckbookTimeline full history of a deposit, oldest entry first
+type CheckbookTimeline struct {
+	Checkbook  *models.Checkbook        `json:"checkbook"`
+	Commitment string                   `json:"commitment,omitempty"`
+	QueueRoot  *models.QueueRoot        `json:"queue_root,omitempty"` // queue root created by the commitment (nil until CommitmentRootUpdated)
+	Entries    []CheckbookTimelineEntry `json:"entries"`
+}
+
+// GetCheckbookTimeline aggregates the deposit events, commitment events and status transitions of a checkbook
+// Deposit events are joined on chain_id + local_deposit_id, CommitmentRootUpdated and the queue root on the commitment.
+// Read-only.
+func (s *CheckbookService) GetCheckbookTimeline(ctx context.Context, checkbookID string) (*CheckbookTimeline, error) {
+	database := s.db.WithContext(ctx)
+
+	var checkbook models.Checkbook
+	if err := database.First(&checkbook, "id = ?", checkbookID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get checkbook %s: %w", checkbookID, err)
+	}
+
+	timeline := &CheckbookTimeline{Checkbook: &checkbook}
+	chainID := int64(checkbook.SLIP44ChainID)
+	depositFilter := database.Where("chain_id = ? AND local_deposit_id = ?", chainID, checkbook.LocalDepositID)
+
+	var received []*models.EventDepositReceived
+	if err := depositFilter.Session(&gorm.Session{}).Order("block_number, log_index").Find(&received).Error; err != nil {
+		return nil, fmt.Errorf("failed to load DepositReceived events: %w", err)
+	}
+	for _, event := range received {
+		timeline.Entries = append(timeline.Entries, CheckbookTimelineEntry{
+			Type: TimelineDepositReceived, Timestamp: event.BlockTimestamp, ChainID: event.ChainID,
+			BlockNumber: event.BlockNumber, LogIndex: event.LogIndex, TransactionHash: event.TransactionHash, Event: event,
+		})
+	}
+
+	var recorded []*models.EventDepositRecorded
+	if err := depositFilter.Session(&gorm.Session{}).Order("block_number, log_index").Find(&recorded).Error; err != nil {
+		return nil, fmt.Errorf("failed to load DepositRecorded events: %w", err)
+	}
+	for _, event := range recorded {
+		timeline.Entries = append(timeline.Entries, CheckbookTimelineEntry{
+			Type: TimelineDepositRecorded, Timestamp: event.BlockTimestamp, ChainID: event.ChainID,
+			BlockNumber: event.BlockNumber, LogIndex: event.LogIndex, TransactionHash: event.TransactionHash, Event: event,
+		})
+	}
+
+	var used []*models.EventDepositUsed
+	if err := depositFilter.Session(&gorm.Session{}).Order("block_number, log_index").Find(&used).Error; err != nil {
+		return nil, fmt.Errorf("failed to load DepositUsed events: %w", err)
+	}
+	for _, event := range used {
+		timeline.Entries = append(timeline.Entries, CheckbookTimelineEntry{
+			Type: TimelineDepositUsed, Timestamp: event.BlockTimestamp, ChainID: event.ChainID,
+			BlockNumber: event.BlockNumber, LogIndex: event.LogIndex, TransactionHash: event.TransactionHash, Event: event,
+		})
+	}
+
+	// Commitment linkage: the checkbook's commitment, or the one from DepositUsed if the checkbook was not updated
+	if checkbook.Commitment != nil && *checkbook.Commitment != "" {
+		timeline.Commitment = *checkbook.Commitment
+	} else if len(used) > 0 {
+		timeline.Commitment = used[len(used)-1].Commitment
+	}
+
+	if timeline.Commitment != "" {
+		commitment := strings.ToLower(timeline.Commitment)
+
+		var rootUpdates []*models.EventCommitmentRootUpdated
+		if err := database.Where("LOWER(commitment) = ?", commitment).Order("block_number, log_index").Find(&rootUpdates).Error; err != nil {
+			return nil, fmt.Errorf("failed to load CommitmentRootUpdated events: %w", err)
+		}
+		for _, event := range rootUpdates {
+			timeline.Entries = append(timeline.Entries, CheckbookTimelineEntry{
+				Type: TimelineCommitmentRootUpdated, Timestamp: event.BlockTimestamp, ChainID: event.ChainID,
+				BlockNumber: event.BlockNumber, LogIndex: event.LogIndex, TransactionHash: event.TransactionHash, Event: event,
+			})
+		}
+
+		var queueRoot models.QueueRoot
+		err := database.Where("LOWER(created_by_commitment) = ?", commitment).Order("created_at DESC").First(&queueRoot).Error
+		if err == nil {
+			timeline.QueueRoot = &queueRoot
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load queue root: %w", err)
+		}
+	}
+
+	transitions, err := repository.NewStatusTransitionRepository(s.db).FindByEntity(ctx, models.StatusTransitionEntityCheckbook, checkbook.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load status transitions: %w", err)
+	}
+	for _, transition := range transitions {
+		timeline.Entries = append(timeline.Entries, CheckbookTimelineEntry{
+			Type: TimelineStatusTransition, Timestamp: transition.CreatedAt, Event: transition,
+		})
+	}
+
+	sortTimelineEntries(timeline.Entries)
+	return timeline, nil
+}
+
+// sortTimelineEntries orders entries by timestamp; events on the same chain with equal timestamps by block number and log index
+// Entries that cannot be compared otherwise keep their insertion order (deposit flow order, transitions last).
+func sortTimelineEntries(entries []CheckbookTimelineEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.ChainID != 0 && a.ChainID == b.ChainID {
+			if a.BlockNumber != b.BlockNumber {
+				return a.BlockNumber < b.BlockNumber
+			}
+			return a.LogIndex < b.LogIndex
+		}
+		return false
+	})
+}
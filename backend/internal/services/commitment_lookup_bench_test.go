@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const commitmentBenchEvents = 100000
+
+// seedCommitmentBench stores commitmentBenchEvents DepositUsed events, one per deposit, and a checkbook for
+// the deposit of the commitment looked up
+func seedCommitmentBench(b *testing.B, database *gorm.DB) (commitment string) {
+	b.Helper()
+	events := make([]models.EventDepositUsed, 0, 1000)
+	for i := 0; i < commitmentBenchEvents; i++ {
+		events = append(events, models.EventDepositUsed{
+			ChainID:         714,
+			SLIP44ChainID:   714,
+			EventName:       "DepositUsed",
+			BlockNumber:     uint64(i),
+			TransactionHash: fmt.Sprintf("0x%064x", i),
+			BlockTimestamp:  time.Unix(int64(i), 0),
+			LocalDepositId:  uint64(i),
+			Commitment:      fmt.Sprintf("0xc%063x", i),
+		})
+		if len(events) == cap(events) {
+			if err := database.Create(&events).Error; err != nil {
+				b.Fatalf("seed events: %v", err)
+			}
+			events = events[:0]
+		}
+	}
+
+	target := commitmentBenchEvents / 2
+	checkbook := models.Checkbook{
+		ID:             "cb-bench",
+		SLIP44ChainID:  714,
+		LocalDepositID: uint64(target),
+		TokenKey:       "USDT",
+		Amount:         "100",
+		Status:         models.CheckbookStatusCommitmentPending,
+		Version:        1,
+	}
+	if err := database.Create(&checkbook).Error; err != nil {
+		b.Fatalf("seed checkbook: %v", err)
+	}
+	return fmt.Sprintf("0xc%063x", target)
+}
+
+// legacyCheckbooksByCommitment the lookup before findCheckbooksByCommitment: DepositUsed by commitment
+// (no commitment index), then the checkbooks of each deposit
+func legacyCheckbooksByCommitment(database *gorm.DB, commitment string) ([]models.Checkbook, error) {
+	var depositUsedEvents []models.EventDepositUsed
+	if err := database.Where("commitment = ?", commitment).Find(&depositUsedEvents).Error; err != nil {
+		return nil, err
+	}
+	var affected []models.Checkbook
+	for _, depositUsed := range depositUsedEvents {
+		var checkbooks []models.Checkbook
+		if err := database.Where("chain_id = ? AND local_deposit_id = ?", depositUsed.SLIP44ChainID, depositUsed.LocalDepositId).
+			Find(&checkbooks).Error; err != nil {
+			return nil, err
+		}
+		affected = append(affected, checkbooks...)
+	}
+	return affected, nil
+}
+
+// BenchmarkCheckbooksByCommitment compares the CommitmentRootUpdated checkbook lookup before and after
+// idx_event_deposit_useds_commitment over 100k DepositUsed events
+func BenchmarkCheckbooksByCommitment(b *testing.B) {
+	database := dbtest.Open(b)
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{}
+	b.Cleanup(func() { config.AppConfig = previousConfig })
+	commitment := seedCommitmentBench(b, database)
+	processor := NewBlockchainEventProcessor(database, nil, nil, nil)
+
+	b.Run("before", func(b *testing.B) {
+		if err := database.Exec("DROP INDEX IF EXISTS idx_event_deposit_useds_commitment").Error; err != nil {
+			b.Fatalf("drop index: %v", err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if checkbooks, err := legacyCheckbooksByCommitment(database, commitment); err != nil || len(checkbooks) != 1 {
+				b.Fatalf("legacy lookup = %d checkbooks, %v", len(checkbooks), err)
+			}
+		}
+	})
+
+	b.Run("after", func(b *testing.B) {
+		if err := database.Exec("CREATE INDEX IF NOT EXISTS idx_event_deposit_useds_commitment ON event_deposit_useds(commitment)").Error; err != nil {
+			b.Fatalf("create index: %v", err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if checkbooks, err := processor.findCheckbooksByCommitment(commitment); err != nil || len(checkbooks) != 1 {
+				b.Fatalf("findCheckbooksByCommitment = %d checkbooks, %v", len(checkbooks), err)
+			}
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"go-backend/internal/config"
+)
+
+// requiredConfirmations returns the confirmation depth configured for a network (default 1)
+func requiredConfirmations(chainID int) uint64 {
+	networkConfig, err := config.GetNetworkConfigByChainID(chainID)
+	if err != nil {
+		return config.DefaultConfirmations
+	}
+	return networkConfig.GetConfirmations()
+}
+
+// hasConfirmations reports whether a receipt in receiptBlock is buried under the required number of blocks
+func hasConfirmations(currentBlock, receiptBlock, required uint64) bool {
+	return currentBlock >= receiptBlock && currentBlock-receiptBlock >= required
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// ZKPay contract ABI versions, selected per network with contractAbiVersion
+// When the contract changes the executeWithdraw / executeCommitment signature, add the new version to
+// contractABIs and point LatestContractABIVersion at it; networks still on the old contract pin the old version
+// until they are upgraded.
+const (
+	// ContractABIVersion1 executeWithdraw(bytes proof, bytes encodedPublicValues) and
+	// executeCommitment(bytes proof, bytes encodedPublicValues)
+	ContractABIVersion1 = 1
+
+	// LatestContractABIVersion version used by networks without contractAbiVersion
+	LatestContractABIVersion = ContractABIVersion1
+)
+
+// contractABI the executeWithdraw / executeCommitment encoding of one contract version
+type contractABI struct {
+	version int
+	parsed  abi.ABI
+
+	// packWithdraw packs the executeWithdraw call data from the proof and the ABI-encoded WithdrawPublicValues
+	packWithdraw func(parsed abi.ABI, proof, encodedPublicValues []byte) ([]byte, error)
+	// packCommitment packs the executeCommitment call data from the proof and the checkbook's ZKVM public values
+	packCommitment func(parsed abi.ABI, proof, encodedPublicValues []byte) ([]byte, error)
+}
+
+// contractABIs known contract versions
+var contractABIs = map[int]*contractABI{
+	ContractABIVersion1: {
+		version: ContractABIVersion1,
+		parsed: mustParseABI(`[
+			{
+				"inputs": [
+					{"name": "proof", "type": "bytes"},
+					{"name": "encodedPublicValues", "type": "bytes"}
+				],
+				"name": "executeWithdraw",
+				"outputs": [],
+				"stateMutability": "nonpayable",
+				"type": "function"
+			},
+			{
+				"inputs": [
+					{"name": "proof", "type": "bytes"},
+					{"name": "encodedPublicValues", "type": "bytes"}
+				],
+				"name": "executeCommitment",
+				"outputs": [],
+				"stateMutability": "nonpayable",
+				"type": "function"
+			}
+		]`),
+		packWithdraw: func(parsed abi.ABI, proof, encodedPublicValues []byte) ([]byte, error) {
+			return parsed.Pack("executeWithdraw", proof, encodedPublicValues)
+		},
+		packCommitment: func(parsed abi.ABI, proof, encodedPublicValues []byte) ([]byte, error) {
+			return parsed.Pack("executeCommitment", proof, encodedPublicValues)
+		},
+	},
+}
+
+// mustParseABI parses a JSON ABI, panicking on error (for use in package-level tables)
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid contract ABI: %v", err))
+	}
+	return parsed
+}
+
+// contractABIFor returns the contract ABI of a network, the latest one when contractAbiVersion is unset
+func contractABIFor(networkConfig *config.NetworkConfig) (*contractABI, error) {
+	version := networkConfig.ContractABIVersion
+	if version == 0 {
+		version = LatestContractABIVersion
+	}
+	contract, ok := contractABIs[version]
+	if !ok {
+		known := make([]int, 0, len(contractABIs))
+		for v := range contractABIs {
+			known = append(known, v)
+		}
+		sort.Ints(known)
+		return nil, fmt.Errorf("unsupported contractAbiVersion %d for chain %d (known versions: %v)",
+			networkConfig.ContractABIVersion, networkConfig.ChainID, known)
+	}
+	return contract, nil
+}
+
+// executeWithdrawMethods returns the executeWithdraw method of every known contract version, oldest first
+// Call data sent to the contract of any version decodes with them (types.ParseExecuteWithdrawCallData).
+func executeWithdrawMethods() []abi.Method {
+	versions := make([]int, 0, len(contractABIs))
+	for version := range contractABIs {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	methods := make([]abi.Method, 0, len(versions))
+	for _, version := range versions {
+		if method, ok := contractABIs[version].parsed.Methods["executeWithdraw"]; ok {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// testContractABIVersion is a contract version that only exists in tests: executeWithdraw takes a deadline too
+const testContractABIVersion = 99
+
+func registerTestContractABI(t *testing.T) {
+	t.Helper()
+	contractABIs[testContractABIVersion] = &contractABI{
+		version: testContractABIVersion,
+		parsed: mustParseABI(`[{
+			"inputs": [
+				{"name": "proof", "type": "bytes"},
+				{"name": "encodedPublicValues", "type": "bytes"},
+				{"name": "deadline", "type": "uint256"}
+			],
+			"name": "executeWithdraw",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}]`),
+		packWithdraw: func(parsed abi.ABI, proof, encodedPublicValues []byte) ([]byte, error) {
+			return parsed.Pack("executeWithdraw", proof, encodedPublicValues, big.NewInt(1_900_000_000))
+		},
+	}
+	t.Cleanup(func() { delete(contractABIs, testContractABIVersion) })
+}
+
+// encodeWithdrawPublicValues ABI-encodes WithdrawPublicValues the way ZKVM returns them (offset word + tuple)
+func encodeWithdrawPublicValues(t *testing.T, amount int64, chainID uint32) []byte {
+	t.Helper()
+	newType := func(name string) abi.Type {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatalf("abi type %s: %v", name, err)
+		}
+		return typ
+	}
+	tuple := abi.Arguments{
+		{Type: newType("bytes32")}, {Type: newType("bytes32[]")}, {Type: newType("uint256")}, {Type: newType("uint8")},
+		{Type: newType("uint32")}, {Type: newType("uint32")}, {Type: newType("string")}, {Type: newType("bytes32")},
+		{Type: newType("bytes32")}, {Type: newType("uint32")}, {Type: newType("string")},
+	}
+	packed, err := tuple.Pack([32]byte{1}, [][32]byte{{2}}, big.NewInt(amount), uint8(0), chainID, uint32(0), "USDT",
+		[32]byte{3}, [32]byte{}, uint32(714), "USDT")
+	if err != nil {
+		t.Fatalf("pack public values: %v", err)
+	}
+	offset := make([]byte, 32)
+	offset[31] = 32
+	return append(offset, packed...)
+}
+
+func TestExecuteWithdrawCallDataOfEitherContractVersionDecodes(t *testing.T) {
+	registerTestContractABI(t)
+	proof := []byte{0xde, 0xad}
+	publicValues := encodeWithdrawPublicValues(t, 1500, 60)
+
+	var selectors [][]byte
+	for _, version := range []int{ContractABIVersion1, testContractABIVersion} {
+		contract, err := contractABIFor(&config.NetworkConfig{ChainID: 714, ContractABIVersion: version})
+		if err != nil {
+			t.Fatalf("contractABIFor(v%d): %v", version, err)
+		}
+		callData, err := contract.packWithdraw(contract.parsed, proof, publicValues)
+		if err != nil {
+			t.Fatalf("pack v%d: %v", version, err)
+		}
+		selectors = append(selectors, callData[:4])
+
+		decoded, err := types.ParseExecuteWithdrawCallData(callData, executeWithdrawMethods()...)
+		if err != nil {
+			t.Fatalf("decode v%d call data: %v", version, err)
+		}
+		if decoded.Amount != "1500" || decoded.Slip44ChainID != 60 {
+			t.Errorf("v%d decoded amount=%s chain=%d, want 1500 / 60", version, decoded.Amount, decoded.Slip44ChainID)
+		}
+	}
+	if bytes.Equal(selectors[0], selectors[1]) {
+		t.Fatalf("contract versions share selector 0x%x", selectors[0])
+	}
+
+	// Without the test version's method its call data is not mistaken for v1
+	contract := contractABIs[testContractABIVersion]
+	callData, _ := contract.packWithdraw(contract.parsed, proof, publicValues)
+	if _, err := types.ParseExecuteWithdrawCallData(callData); err == nil {
+		t.Error("v1-only decoding accepted call data of another contract version")
+	}
+}
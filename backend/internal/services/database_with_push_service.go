@@ -3,6 +3,7 @@ package services
 import (
 	"fmt"
 	"go-backend/internal/models"
+	"go-backend/internal/repository"
 	"log"
 
 	"gorm.io/gorm"
@@ -27,6 +28,17 @@ func NewDatabaseWithPushService(db *gorm.DB, pushService *WebSocketPushService)
 
 // UpdateCheckbook updateCheckbookpush
 func (s *DatabaseWithPushService) UpdateCheckbook(checkbookID string, updates map[string]interface{}, context string) error {
+	return s.updateCheckbook(checkbookID, nil, updates, context)
+}
+
+// UpdateCheckbookIfVersion updates a Checkbook only if its version is unchanged, then pushes the update
+// Returns repository.ErrVersionConflict (nothing is pushed) if the checkbook was modified since it was read.
+func (s *DatabaseWithPushService) UpdateCheckbookIfVersion(checkbookID string, version int64, updates map[string]interface{}, context string) error {
+	return s.updateCheckbook(checkbookID, &version, updates, context)
+}
+
+// updateCheckbook updates a Checkbook (conditionally on version when given) and pushes the new status
+func (s *DatabaseWithPushService) updateCheckbook(checkbookID string, version *int64, updates map[string]interface{}, context string) error {
 	// 2. Get old status before update (for WebSocket push)
 	var oldStatus string
 	if s.pushService != nil {
@@ -39,7 +51,12 @@ func (s *DatabaseWithPushService) UpdateCheckbook(checkbookID string, updates ma
 	}
 
 	// 1. updatedata
-	if err := s.db.Model(&models.Checkbook{}).Where("id = ?", checkbookID).Updates(updates).Error; err != nil {
+	if version != nil {
+		if err := repository.UpdateIfVersion(s.db, &models.Checkbook{}, checkbookID, *version, updates); err != nil {
+			log.Printf("❌ [%s] updateCheckbookfailed: %v", context, err)
+			return fmt.Errorf("updateCheckbookfailed: %w", err)
+		}
+	} else if err := s.db.Model(&models.Checkbook{}).Where("id = ?", checkbookID).Updates(updates).Error; err != nil {
 		log.Printf("❌ [%s] updateCheckbookfailed: %v", context, err)
 		return fmt.Errorf("updateCheckbookfailed: %w", err)
 	}
@@ -93,7 +110,7 @@ func (s *DatabaseWithPushService) UpdateCheck(checkID string, updates map[string
 	// 2. pushupdate
 	// When Check (Allocation) status changes:
 	// - Always push allocation_update (Check is Allocation, AllocationsStore needs update)
-	// - If Check is associated with WithdrawRequest, also push withdrawal_update (WithdrawalsStore needs update)
+	// - If Check is associated with WithdrawRequest, also push withdraw_request.status_changed (WithdrawalsStore needs update)
 	if s.pushService != nil {
 		var updatedCheck models.Check
 		if err := s.db.First(&updatedCheck, "id = ?", checkID).Error; err == nil {
@@ -131,8 +148,8 @@ func (s *DatabaseWithPushService) UpdateCheckStatus(checkID string, newStatus mo
 	// 2. push status change
 	// When Check (Allocation) status changes:
 	// - Always push allocation_update (Check is Allocation, AllocationsStore needs update)
-	// - Always push checkbook_update (Checkbook's allocations have changed)
-	// - If Check is associated with WithdrawRequest, also push withdrawal_update (WithdrawalsStore needs update)
+	// - Always push checkbook.status_changed (Checkbook's allocations have changed)
+	// - If Check is associated with WithdrawRequest, also push withdraw_request.status_changed (WithdrawalsStore needs update)
 	if s.pushService != nil {
 		var updatedCheck models.Check
 		if err := s.db.First(&updatedCheck, "id = ?", checkID).Error; err == nil {
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/utils"
+)
+
+var (
+	ErrMalformedSubject = errors.New("malformed NATS subject")
+	ErrUnknownEvent     = errors.New("unknown event")
+)
+
+// EventSubject parsed NATS event subject: zkpay.<chain>.<contract>.<event>
+// SLIP44ChainID is 0 when the subject names no known chain; the payload's chainId is used then.
+type EventSubject struct {
+	SLIP44ChainID int
+	Contract      string
+	Event         string
+}
+
+// Dispatch routes a raw NATS message to the matching Process* handler
+// The payload is the event JSON (clients.Event*Response); if it carries no chainId, the SLIP-44 chain ID
+// from the subject is used. Adding an event means adding one case below.
+func (p *BlockchainEventProcessor) Dispatch(subject string, payload []byte) error {
+	parts := strings.Split(subject, ".")
+	if len(parts) != 4 || parts[0] != "zkpay" || parts[2] == "" || parts[3] == "" {
+		return fmt.Errorf("%w: %q (expected zkpay.<chain>.<contract>.<event>)", ErrMalformedSubject, subject)
+	}
+	parsed := EventSubject{Contract: parts[2], Event: parts[3]}
+	if chainID, err := utils.GetSlip44ChainIDFromSubject(subject); err == nil {
+		parsed.SLIP44ChainID = chainID
+	}
+
+	switch parsed.Event {
+	case "DepositReceived":
+		var event clients.EventDepositReceivedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessDepositReceived(&event)
+	case "DepositRecorded":
+		var event clients.EventDepositRecordedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessDepositRecorded(&event)
+	case "DepositUsed":
+		var event clients.EventDepositUsedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessDepositUsed(&event)
+	case "CommitmentRootUpdated":
+		var event clients.EventCommitmentRootUpdatedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessCommitmentRootUpdated(&event)
+	case "WithdrawRequested":
+		var event clients.EventWithdrawRequestedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessWithdrawRequested(&event)
+	case "WithdrawExecuted":
+		// Same event name on Treasury (payout side) and IntentManager
+		if parsed.Contract == "IntentManager" {
+			var event clients.EventIntentManagerWithdrawExecutedResponse
+			if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+				return err
+			}
+			return p.ProcessIntentManagerWithdrawExecuted(&event)
+		}
+		var event clients.EventWithdrawExecutedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessWithdrawExecuted(&event)
+	case "PayoutExecuted":
+		var event clients.EventPayoutExecutedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessPayoutExecuted(&event)
+	case "PayoutFailed":
+		var event clients.EventPayoutFailedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessPayoutFailed(&event)
+	case "HookExecuted":
+		var event clients.EventHookExecutedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessHookExecuted(&event)
+	case "HookFailed":
+		var event clients.EventHookFailedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessHookFailed(&event)
+	case "FallbackTransferred":
+		var event clients.EventFallbackTransferredResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessFallbackTransferred(&event)
+	case "FallbackFailed":
+		var event clients.EventFallbackFailedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessFallbackFailed(&event)
+	case "PayoutRetryRecordCreated":
+		var event clients.EventPayoutRetryRecordCreatedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessPayoutRetryRecordCreated(&event)
+	case "FallbackRetryRecordCreated":
+		var event clients.EventFallbackRetryRecordCreatedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessFallbackRetryRecordCreated(&event)
+	case "ManuallyResolved":
+		var event clients.EventManuallyResolvedResponse
+		if err := decodeEvent(payload, &event, &event.ChainID, parsed); err != nil {
+			return err
+		}
+		return p.ProcessManuallyResolved(&event)
+	}
+
+	return fmt.Errorf("%w: %s.%s (subject %s)", ErrUnknownEvent, parsed.Contract, parsed.Event, subject)
+}
+
+// decodeEvent unmarshals an event payload and fills its chain ID from the subject when the payload has none
+func decodeEvent(payload []byte, event interface{}, chainID *int64, subject EventSubject) error {
+	if err := json.Unmarshal(payload, event); err != nil {
+		return fmt.Errorf("failed to unmarshal %s event: %w", subject.Event, err)
+	}
+	if *chainID == 0 {
+		*chainID = int64(subject.SLIP44ChainID)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go-backend/internal/clients"
+)
+
+func TestDispatchRejectsMalformedSubjects(t *testing.T) {
+	processor := &BlockchainEventProcessor{}
+	subjects := []string{
+		"",
+		"zkpay",
+		"zkpay.bsc.EnclavePay",
+		"zkpay.bsc.EnclavePay.DepositUsed.extra",
+		"nats.bsc.EnclavePay.DepositUsed",
+		"ZKPAY.bsc.EnclavePay.DepositUsed",
+		"zkpay.bsc..DepositUsed",
+		"zkpay.bsc.EnclavePay.",
+	}
+	for _, subject := range subjects {
+		t.Run(subject, func(t *testing.T) {
+			if err := processor.Dispatch(subject, []byte(`{}`)); !errors.Is(err, ErrMalformedSubject) {
+				t.Errorf("Dispatch = %v, want ErrMalformedSubject", err)
+			}
+		})
+	}
+}
+
+func TestDecodeEventFillsChainFromSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		subject EventSubject
+		want    int64
+	}{
+		{"no chainId in payload", `{}`, EventSubject{SLIP44ChainID: 714, Event: "DepositUsed"}, 714},
+		{"payload chainId wins", `{"chainId":195}`, EventSubject{SLIP44ChainID: 714, Event: "DepositUsed"}, 195},
+		{"no chain in subject", `{"chainId":60}`, EventSubject{Event: "DepositUsed"}, 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event clients.EventDepositUsedResponse
+			if err := decodeEvent([]byte(tt.payload), &event, &event.ChainID, tt.subject); err != nil {
+				t.Fatalf("decodeEvent: %v", err)
+			}
+			if event.ChainID != tt.want {
+				t.Errorf("chainId = %d, want %d", event.ChainID, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchRejectsBeforeProcessing(t *testing.T) {
+	// None of these reach a Process* handler, so an empty processor is enough
+	processor := &BlockchainEventProcessor{}
+
+	if err := processor.Dispatch("zkpay.bsc", []byte(`{}`)); !errors.Is(err, ErrMalformedSubject) {
+		t.Errorf("malformed subject: %v, want ErrMalformedSubject", err)
+	}
+	if err := processor.Dispatch("zkpay.bsc.EnclavePay.SomethingNew", []byte(`{}`)); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("unknown event: %v, want ErrUnknownEvent", err)
+	}
+	err := processor.Dispatch("zkpay.bsc.EnclavePay.DepositUsed", []byte(`{not json`))
+	if err == nil || errors.Is(err, ErrUnknownEvent) || errors.Is(err, ErrMalformedSubject) {
+		t.Errorf("bad payload: %v, want an unmarshal error", err)
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+// Event names accepted by EventQueryService, as stored in each event table's event_name
+const (
+	EventNameDepositReceived       = "DepositReceived"
+	EventNameDepositRecorded       = "DepositRecorded"
+	EventNameDepositUsed           = "DepositUsed"
+	EventNameCommitmentRootUpdated = "CommitmentRootUpdated"
+	EventNameWithdrawRequested     = "WithdrawRequested"
+	EventNameWithdrawExecuted      = "WithdrawExecuted"
+)
+
+// ErrInvalidEventQuery unknown event name or malformed block range / pagination
+var ErrInvalidEventQuery = newServiceError(CodeInvalidEventQuery, "invalid event query")
+
+// ChainEvent one stored event of any type; exactly one of the typed fields is set, matching EventName
+type ChainEvent struct {
+	EventName       string `json:"event_name"`
+	ChainID         int64  `json:"chain_id"`
+	BlockNumber     uint64 `json:"block_number"`
+	LogIndex        uint   `json:"log_index"`
+	TransactionHash string `json:"transaction_hash"`
+
+	DepositReceived       *models.EventDepositReceived       `json:"deposit_received,omitempty"`
+	DepositRecorded       *models.EventDepositRecorded       `json:"deposit_recorded,omitempty"`
+	DepositUsed           *models.EventDepositUsed           `json:"deposit_used,omitempty"`
+	CommitmentRootUpdated *models.EventCommitmentRootUpdated `json:"commitment_root_updated,omitempty"`
+	WithdrawRequested     *models.EventWithdrawRequested     `json:"withdraw_requested,omitempty"`
+	WithdrawExecuted      *models.EventWithdrawExecuted      `json:"withdraw_executed,omitempty"`
+}
+
+// EventRangeQuery events of one chain within an inclusive block range; an empty EventName queries every event table
+type EventRangeQuery struct {
+	ChainID   int64
+	FromBlock uint64
+	ToBlock   uint64
+	EventName string
+	Page      int
+	PageSize  int
+}
+
+// EventRangePage one page of events ordered by block_number, log_index, and the total match count
+type EventRangePage struct {
+	Events []ChainEvent `json:"events"`
+	Total  int64        `json:"total"`
+}
+
+// eventSource loads one event table's events as ChainEvents
+type eventSource func(ctx context.Context, blockRange repository.BlockRange) ([]ChainEvent, int64, error)
+
+// EventQueryService lists raw stored events by chain and block range (debugging, external integrations)
+type EventQueryService struct {
+	sources map[string]eventSource
+	order   []string // event names, in the order tables are queried
+}
+
+// NewEventQueryService creates a new EventQueryService
+func NewEventQueryService(
+	depositEventRepo repository.DepositEventRepository,
+	withdrawEventRepo repository.WithdrawEventRepository,
+	queueRootRepo repository.QueueRootRepository,
+) *EventQueryService {
+	s := &EventQueryService{sources: make(map[string]eventSource)}
+
+	s.register(EventNameDepositReceived, func(ctx context.Context, r repository.BlockRange) ([]ChainEvent, int64, error) {
+		events, total, err := depositEventRepo.FindDepositReceivedInRange(ctx, r)
+		result := make([]ChainEvent, 0, len(events))
+		for _, e := range events {
+			result = append(result, ChainEvent{EventName: EventNameDepositReceived, ChainID: e.ChainID, BlockNumber: e.BlockNumber,
+				LogIndex: e.LogIndex, TransactionHash: e.TransactionHash, DepositReceived: e})
+		}
+		return result, total, err
+	})
+	s.register(EventNameDepositRecorded, func(ctx context.Context, r repository.BlockRange) ([]ChainEvent, int64, error) {
+		events, total, err := depositEventRepo.FindDepositRecordedInRange(ctx, r)
+		result := make([]ChainEvent, 0, len(events))
+		for _, e := range events {
+			result = append(result, ChainEvent{EventName: EventNameDepositRecorded, ChainID: e.ChainID, BlockNumber: e.BlockNumber,
+				LogIndex: e.LogIndex, TransactionHash: e.TransactionHash, DepositRecorded: e})
+		}
+		return result, total, err
+	})
+	s.register(EventNameDepositUsed, func(ctx context.Context, r repository.BlockRange) ([]ChainEvent, int64, error) {
+		events, total, err := depositEventRepo.FindDepositUsedInRange(ctx, r)
+		result := make([]ChainEvent, 0, len(events))
+		for _, e := range events {
+			result = append(result, ChainEvent{EventName: EventNameDepositUsed, ChainID: e.ChainID, BlockNumber: e.BlockNumber,
+				LogIndex: e.LogIndex, TransactionHash: e.TransactionHash, DepositUsed: e})
+		}
+		return result, total, err
+	})
+	s.register(EventNameCommitmentRootUpdated, func(ctx context.Context, r repository.BlockRange) ([]ChainEvent, int64, error) {
+		events, total, err := queueRootRepo.FindCommitmentRootUpdatedInRange(ctx, r)
+		result := make([]ChainEvent, 0, len(events))
+		for _, e := range events {
+			result = append(result, ChainEvent{EventName: EventNameCommitmentRootUpdated, ChainID: e.ChainID, BlockNumber: e.BlockNumber,
+				LogIndex: e.LogIndex, TransactionHash: e.TransactionHash, CommitmentRootUpdated: e})
+		}
+		return result, total, err
+	})
+	s.register(EventNameWithdrawRequested, func(ctx context.Context, r repository.BlockRange) ([]ChainEvent, int64, error) {
+		events, total, err := withdrawEventRepo.FindWithdrawRequestedInRange(ctx, r)
+		result := make([]ChainEvent, 0, len(events))
+		for _, e := range events {
+			result = append(result, ChainEvent{EventName: EventNameWithdrawRequested, ChainID: e.ChainID, BlockNumber: e.BlockNumber,
+				LogIndex: e.LogIndex, TransactionHash: e.TransactionHash, WithdrawRequested: e})
+		}
+		return result, total, err
+	})
+	s.register(EventNameWithdrawExecuted, func(ctx context.Context, r repository.BlockRange) ([]ChainEvent, int64, error) {
+		events, total, err := withdrawEventRepo.FindWithdrawExecutedInRange(ctx, r)
+		result := make([]ChainEvent, 0, len(events))
+		for _, e := range events {
+			result = append(result, ChainEvent{EventName: EventNameWithdrawExecuted, ChainID: e.ChainID, BlockNumber: e.BlockNumber,
+				LogIndex: e.LogIndex, TransactionHash: e.TransactionHash, WithdrawExecuted: e})
+		}
+		return result, total, err
+	})
+
+	return s
+}
+
+// register adds the source of one event table
+func (s *EventQueryService) register(eventName string, source eventSource) {
+	s.sources[eventName] = source
+	s.order = append(s.order, eventName)
+}
+
+// FindEventsInRange returns one page of events of q.ChainID in [q.FromBlock, q.ToBlock]
+// With an EventName only that table is queried and paginated in SQL. Without one, each table returns its first
+// page*pageSize events, which are merged by (block_number, log_index) before the page is cut.
+func (s *EventQueryService) FindEventsInRange(ctx context.Context, q EventRangeQuery) (*EventRangePage, error) {
+	if q.FromBlock > q.ToBlock {
+		return nil, fmt.Errorf("%w: from_block %d is after to_block %d", ErrInvalidEventQuery, q.FromBlock, q.ToBlock)
+	}
+	if q.Page < 1 || q.PageSize < 1 {
+		return nil, fmt.Errorf("%w: page and page_size must be positive", ErrInvalidEventQuery)
+	}
+	offset := (q.Page - 1) * q.PageSize
+
+	if q.EventName != "" {
+		source, ok := s.sources[q.EventName]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown event name %q", ErrInvalidEventQuery, q.EventName)
+		}
+		events, total, err := source(ctx, repository.BlockRange{
+			ChainID: q.ChainID, FromBlock: q.FromBlock, ToBlock: q.ToBlock, Offset: offset, Limit: q.PageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s events: %w", q.EventName, err)
+		}
+		return &EventRangePage{Events: events, Total: total}, nil
+	}
+
+	var merged []ChainEvent
+	var total int64
+	for _, eventName := range s.order {
+		events, count, err := s.sources[eventName](ctx, repository.BlockRange{
+			ChainID: q.ChainID, FromBlock: q.FromBlock, ToBlock: q.ToBlock, Limit: offset + q.PageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s events: %w", eventName, err)
+		}
+		merged = append(merged, events...)
+		total += count
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].BlockNumber != merged[j].BlockNumber {
+			return merged[i].BlockNumber < merged[j].BlockNumber
+		}
+		return merged[i].LogIndex < merged[j].LogIndex
+	})
+
+	if offset >= len(merged) {
+		return &EventRangePage{Events: []ChainEvent{}, Total: total}, nil
+	}
+	end := offset + q.PageSize
+	if end > len(merged) {
+		end = len(merged)
+	}
+	return &EventRangePage{Events: merged[offset:end], Total: total}, nil
+}
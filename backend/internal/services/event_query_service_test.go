@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// newTestEventQueryService an EventQueryService over a test database holding events of three tables:
+// chain 714 in blocks 99-201 and one chain 60 event inside the queried range
+func newTestEventQueryService(t *testing.T) *EventQueryService {
+	t.Helper()
+	database := dbtest.Open(t)
+
+	create := func(record interface{}) {
+		t.Helper()
+		if err := database.Create(record).Error; err != nil {
+			t.Fatalf("create %T: %v", record, err)
+		}
+	}
+	received := func(chainID int64, block uint64, logIndex uint) {
+		create(&models.EventDepositReceived{ChainID: chainID, SLIP44ChainID: chainID, EventName: EventNameDepositReceived,
+			BlockNumber: block, LogIndex: logIndex, TransactionHash: fmt.Sprintf("0xreceived-%d-%d", block, logIndex)})
+	}
+	used := func(block uint64, logIndex uint) {
+		create(&models.EventDepositUsed{ChainID: 714, SLIP44ChainID: 714, EventName: EventNameDepositUsed,
+			BlockNumber: block, LogIndex: logIndex, TransactionHash: fmt.Sprintf("0xused-%d-%d", block, logIndex)})
+	}
+	executed := func(block uint64, logIndex uint) {
+		create(&models.EventWithdrawExecuted{ChainID: 714, SLIP44ChainID: 714, EventName: EventNameWithdrawExecuted,
+			BlockNumber: block, LogIndex: logIndex, TransactionHash: fmt.Sprintf("0xexecuted-%d-%d", block, logIndex)})
+	}
+
+	received(714, 99, 0) // before the range
+	received(714, 100, 1)
+	received(714, 150, 0)
+	received(60, 150, 0) // other chain
+	used(150, 2)
+	used(200, 0)
+	used(201, 0) // after the range
+	executed(100, 0)
+	executed(200, 5)
+
+	return newEventQueryServiceFor(database)
+}
+
+func newEventQueryServiceFor(database *gorm.DB) *EventQueryService {
+	return NewEventQueryService(repository.NewDepositEventRepository(database),
+		repository.NewWithdrawEventRepository(database), repository.NewQueueRootRepository(database))
+}
+
+// eventKeys "<name>@<block>.<log>" of each event, in order
+func eventKeys(events []ChainEvent) string {
+	keys := make([]string, len(events))
+	for i, e := range events {
+		keys[i] = fmt.Sprintf("%s@%d.%d", e.EventName, e.BlockNumber, e.LogIndex)
+	}
+	return strings.Join(keys, " ")
+}
+
+func TestFindEventsInRange(t *testing.T) {
+	service := newTestEventQueryService(t)
+
+	tests := []struct {
+		name      string
+		query     EventRangeQuery
+		wantKeys  string
+		wantTotal int64
+	}{
+		{"all tables, range bounds inclusive",
+			EventRangeQuery{ChainID: 714, FromBlock: 100, ToBlock: 200, Page: 1, PageSize: 50},
+			"WithdrawExecuted@100.0 DepositReceived@100.1 DepositReceived@150.0 DepositUsed@150.2 DepositUsed@200.0 WithdrawExecuted@200.5", 6},
+		{"single block",
+			EventRangeQuery{ChainID: 714, FromBlock: 200, ToBlock: 200, Page: 1, PageSize: 50},
+			"DepositUsed@200.0 WithdrawExecuted@200.5", 2},
+		{"filtered by event name",
+			EventRangeQuery{ChainID: 714, FromBlock: 100, ToBlock: 200, EventName: EventNameDepositUsed, Page: 1, PageSize: 50},
+			"DepositUsed@150.2 DepositUsed@200.0", 2},
+		{"filtered by event name, second page",
+			EventRangeQuery{ChainID: 714, FromBlock: 0, ToBlock: 1000, EventName: EventNameDepositReceived, Page: 2, PageSize: 2},
+			"DepositReceived@150.0", 3},
+		{"merged tables, second page",
+			EventRangeQuery{ChainID: 714, FromBlock: 100, ToBlock: 200, Page: 2, PageSize: 4},
+			"DepositUsed@200.0 WithdrawExecuted@200.5", 6},
+		{"past the last page",
+			EventRangeQuery{ChainID: 714, FromBlock: 100, ToBlock: 200, Page: 3, PageSize: 4},
+			"", 6},
+		{"other chain",
+			EventRangeQuery{ChainID: 60, FromBlock: 100, ToBlock: 200, Page: 1, PageSize: 50},
+			"DepositReceived@150.0", 1},
+		{"empty range",
+			EventRangeQuery{ChainID: 714, FromBlock: 300, ToBlock: 400, Page: 1, PageSize: 50},
+			"", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.FindEventsInRange(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("FindEventsInRange: %v", err)
+			}
+			if got := eventKeys(page.Events); got != tt.wantKeys {
+				t.Errorf("events = %q, want %q", got, tt.wantKeys)
+			}
+			if page.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", page.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestFindEventsInRangeRejectsInvalidQueries(t *testing.T) {
+	// Validation happens before any table is queried
+	service := newEventQueryServiceFor(nil)
+
+	for name, query := range map[string]EventRangeQuery{
+		"from after to":      {ChainID: 714, FromBlock: 201, ToBlock: 200, Page: 1, PageSize: 10},
+		"unknown event name": {ChainID: 714, FromBlock: 1, ToBlock: 2, EventName: "Transfer", Page: 1, PageSize: 10},
+		"zero page":          {ChainID: 714, FromBlock: 1, ToBlock: 2, Page: 0, PageSize: 10},
+		"zero page size":     {ChainID: 714, FromBlock: 1, ToBlock: 2, Page: 1, PageSize: 0},
+	} {
+		if _, err := service.FindEventsInRange(context.Background(), query); !errors.Is(err, ErrInvalidEventQuery) {
+			t.Errorf("%s: %v, want ErrInvalidEventQuery", name, err)
+		}
+	}
+}
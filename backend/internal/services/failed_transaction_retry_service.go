@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"go-backend/internal/config"
 	"go-backend/internal/db"
 	"go-backend/internal/models"
+	"go-backend/internal/repository"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -25,10 +27,17 @@ func getCommitmentString(commitment *string) string {
 	return *commitment
 }
 
+// Manual retry errors
+var (
+	ErrFailedTransactionNotRetryable = errors.New("failed transaction cannot be retried in its current status")
+	ErrFailedTransactionMaxRetries   = errors.New("failed transaction reached its maximum retries")
+)
+
 // FailedTransactionRetryService Failedretryservice
 type FailedTransactionRetryService struct {
 	blockchainService *BlockchainTransactionService
 	scannerClient     *BlockScannerClient
+	failedTxRepo      repository.FailedTransactionRepository
 }
 
 // NewFailedTransactionRetryService CreateFailedretryservice
@@ -36,7 +45,36 @@ func NewFailedTransactionRetryService(blockchainService *BlockchainTransactionSe
 	return &FailedTransactionRetryService{
 		blockchainService: blockchainService,
 		scannerClient:     scannerClient,
+		failedTxRepo:      repository.NewFailedTransactionRepository(db.DB),
+	}
+}
+
+// RetryFailedTransaction retries one failed transaction now, ignoring next_retry_at (admin)
+// Pending and abandoned transactions can be retried while retry_count < max_retries; a failed attempt
+// counts against the limit like a scheduled retry. Returns the updated record along with the retry error.
+func (s *FailedTransactionRetryService) RetryFailedTransaction(ctx context.Context, id string) (*models.FailedTransaction, error) {
+	failedTx, err := s.failedTxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch failedTx.Status {
+	case models.FailedTransactionStatusPending, models.FailedTransactionStatusAbandoned:
+	default:
+		return failedTx, fmt.Errorf("%w: status=%s", ErrFailedTransactionNotRetryable, failedTx.Status)
+	}
+	if failedTx.RetryCount >= failedTx.MaxRetries {
+		return failedTx, fmt.Errorf("%w: %d/%d", ErrFailedTransactionMaxRetries, failedTx.RetryCount, failedTx.MaxRetries)
 	}
+
+	log.Printf("🔁 [retryservice] Manual retry of %s (%d/%d)", failedTx.ID, failedTx.RetryCount+1, failedTx.MaxRetries)
+	retryErr := s.processSingleFailedTransaction(failedTx)
+
+	updated, err := s.failedTxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return updated, retryErr
 }
 
 // StartRetryService Startretryservice，CheckretryFailed
@@ -252,6 +290,7 @@ func (s *FailedTransactionRetryService) markAsRetrying(failedTxID string) error
 }
 
 // markRetryFailed retryFailed
+// Contract reverts are abandoned immediately; transient (RPC/network) errors are rescheduled with backoff.
 func (s *FailedTransactionRetryService) markRetryFailed(failedTxID, errorMsg string) error {
 	return db.DB.Transaction(func(tx *gorm.DB) error {
 		var failedTx models.FailedTransaction
@@ -259,17 +298,33 @@ func (s *FailedTransactionRetryService) markRetryFailed(failedTxID, errorMsg str
 			return err
 		}
 
+		if isPermanentTxError(errorMsg) {
+			log.Printf("⚠️ [retryservice] %s failed permanently (contract revert), not retrying: %s", failedTxID, errorMsg)
+			failedTx.MarkAsAbandoned(errorMsg)
+			return tx.Save(&failedTx).Error
+		}
+
 		failedTx.IncrementRetry(errorMsg)
-		failedTx.Status = models.FailedTransactionStatusPending // pendingstatusWaitnext timeretry
+		if failedTx.Status != models.FailedTransactionStatusAbandoned {
+			failedTx.Status = models.FailedTransactionStatusPending // pendingstatusWaitnext timeretry
+			log.Printf("⏳ [retryservice] %s retry %d/%d scheduled at %s",
+				failedTxID, failedTx.RetryCount, failedTx.MaxRetries, failedTx.NextRetryAt.Format(time.RFC3339))
+		}
 
 		return tx.Save(&failedTx).Error
 	})
 }
 
+// isPermanentTxError reports whether a failure will not go away on retry (contract revert, invalid proof, used nullifier)
+// Everything else (timeouts, connection errors, nonce/gas issues) is treated as transient.
+func isPermanentTxError(errorMsg string) bool {
+	return classifyRevertMessage(errorMsg).IsPermanent()
+}
+
 // getEthClient Getclient
 func (s *FailedTransactionRetryService) getEthClient(chainID int) (*ethclient.Client, error) {
 	// Useblockchain serviceclient
-	client := s.blockchainService.clients[chainID]
+	client, _ := s.blockchainService.lookupClient(chainID)
 	if client == nil {
 		return nil, fmt.Errorf("client not found for chainID %d", chainID)
 	}
@@ -329,7 +384,7 @@ func (s *FailedTransactionRetryService) getChainIDFromFailedTx(failedTx *models.
 					}
 				}
 			}
-			return 714, nil // DefaultUseBSCSLIP-44 chainID
+			return config.GetManagementChainID(), nil // Default: management chain SLIP-44 chainID
 		}
 		return 0, fmt.Errorf("failed to query checkbook: %w", err)
 	}
@@ -464,13 +519,16 @@ func (s *FailedTransactionRetryService) resubmitCommitmentTransaction(failedTx *
 	return s.markRetrySuccessful(failedTx.ID, "success")
 }
 
-// markRetrySuccessful retrySuccess
+// markRetrySuccessful retrySuccess (the successful attempt counts as a retry)
 func (s *FailedTransactionRetryService) markRetrySuccessful(txID, reason string) error {
+	now := time.Now()
 	return db.DB.Model(&models.FailedTransaction{}).
 		Where("id = ?", txID).
 		Updates(map[string]interface{}{
-			"status":     models.FailedTransactionStatusRecovered,
-			"updated_at": time.Now(),
+			"status":      models.FailedTransactionStatusRecovered,
+			"retry_count": gorm.Expr("retry_count + 1"),
+			"resolved_at": &now,
+			"updated_at":  now,
 		}).Error
 }
 
@@ -481,7 +539,7 @@ func (s *FailedTransactionRetryService) markRetryAbandoned(txID, reason string)
 		Where("id = ?", txID).
 		Updates(map[string]interface{}{
 			"status":     models.FailedTransactionStatusAbandoned,
-			"error_msg":  reason,
+			"last_error": reason,
 			"updated_at": time.Now(),
 		}).Error
 }
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeFailedTxRepo repository.FailedTransactionRepository over an in-memory map
+type fakeFailedTxRepo struct {
+	repository.FailedTransactionRepository
+	txs map[string]*models.FailedTransaction
+}
+
+func (r *fakeFailedTxRepo) GetByID(ctx context.Context, id string) (*models.FailedTransaction, error) {
+	failedTx, ok := r.txs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *failedTx
+	return &copied, nil
+}
+
+func TestRetryFailedTransactionGuards(t *testing.T) {
+	repo := &fakeFailedTxRepo{txs: map[string]*models.FailedTransaction{
+		"recovered": {ID: "recovered", Status: models.FailedTransactionStatusRecovered, MaxRetries: 10},
+		"retrying":  {ID: "retrying", Status: models.FailedTransactionStatusRetrying, MaxRetries: 10},
+		"at-limit":  {ID: "at-limit", Status: models.FailedTransactionStatusAbandoned, RetryCount: 10, MaxRetries: 10},
+		"over":      {ID: "over", Status: models.FailedTransactionStatusPending, RetryCount: 11, MaxRetries: 10},
+	}}
+	service := &FailedTransactionRetryService{failedTxRepo: repo}
+
+	tests := []struct {
+		id      string
+		wantErr error
+	}{
+		{"missing", gorm.ErrRecordNotFound},
+		{"recovered", ErrFailedTransactionNotRetryable},
+		{"retrying", ErrFailedTransactionNotRetryable},
+		{"at-limit", ErrFailedTransactionMaxRetries},
+		{"over", ErrFailedTransactionMaxRetries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			// The guards return before any resubmission, so the service needs no blockchain client
+			failedTx, err := service.RetryFailedTransaction(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RetryFailedTransaction(%s): %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if !errors.Is(tt.wantErr, gorm.ErrRecordNotFound) && (failedTx == nil || failedTx.ID != tt.id) {
+				t.Errorf("RetryFailedTransaction(%s) returned %+v, want the unchanged record", tt.id, failedTx)
+			}
+		})
+	}
+}
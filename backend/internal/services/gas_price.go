@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrGasPriceAboveCeiling computed gas price exceeds the network's gasPriceMaxGwei, the transaction is not sent
+var ErrGasPriceAboveCeiling = errors.New("gas price exceeds configured ceiling")
+
+// computeGasPrice returns the gas price to send with on a network
+// A fixed gasPrice (wei) from config is used as-is; otherwise the suggested price times gasPriceMultiplier,
+// or gasPriceFallbackGwei if the suggestion cannot be fetched. Prices above gasPriceMaxGwei are rejected.
+func computeGasPrice(client *ethclient.Client, networkConfig *config.NetworkConfig) (*big.Int, error) {
+	if networkConfig.GasPrice != "" && networkConfig.GasPrice != "auto" {
+		gasPrice, ok := new(big.Int).SetString(networkConfig.GasPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid gasPrice in network config: %q", networkConfig.GasPrice)
+		}
+		return checkGasPriceCeiling(gasPrice, networkConfig)
+	}
+
+	suggestedGasPrice, err := client.SuggestGasPrice(context.Background())
+	return gasPriceFromSuggestion(suggestedGasPrice, err, networkConfig)
+}
+
+// gasPriceFromSuggestion applies the multiplier, fallback and ceiling to a suggested gas price
+func gasPriceFromSuggestion(suggestedGasPrice *big.Int, suggestErr error, networkConfig *config.NetworkConfig) (*big.Int, error) {
+	if suggestErr != nil || suggestedGasPrice == nil {
+		gasPrice := gweiToWei(networkConfig.GetGasPriceFallbackGwei())
+		log.Printf("⚠️ [GasPrice] Failed to get suggested gas price, using fallback %s wei: %v", gasPrice.String(), suggestErr)
+		return checkGasPriceCeiling(gasPrice, networkConfig)
+	}
+
+	multiplier := networkConfig.GetGasPriceMultiplier()
+	gasPrice := roundToWei(new(big.Float).Mul(new(big.Float).SetInt(suggestedGasPrice), big.NewFloat(multiplier)))
+	log.Printf("⛽ [GasPrice] Suggested %s wei x %.2f = %s wei", suggestedGasPrice.String(), multiplier, gasPrice.String())
+	return checkGasPriceCeiling(gasPrice, networkConfig)
+}
+
+// checkGasPriceCeiling rejects a gas price above gasPriceMaxGwei (no ceiling when unset)
+func checkGasPriceCeiling(gasPrice *big.Int, networkConfig *config.NetworkConfig) (*big.Int, error) {
+	if networkConfig.GasPriceMaxGwei <= 0 {
+		return gasPrice, nil
+	}
+	ceiling := gweiToWei(networkConfig.GasPriceMaxGwei)
+	if gasPrice.Cmp(ceiling) > 0 {
+		return nil, fmt.Errorf("%w: %s wei > %s wei (gasPriceMaxGwei=%g, chain %d)",
+			ErrGasPriceAboveCeiling, gasPrice.String(), ceiling.String(), networkConfig.GasPriceMaxGwei, networkConfig.ChainID)
+	}
+	return gasPrice, nil
+}
+
+// gweiToWei converts a Gwei amount to wei
+func gweiToWei(gwei float64) *big.Int {
+	return roundToWei(new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(1e9)))
+}
+
+// roundToWei rounds a non-negative wei amount to the nearest integer
+// (float factors like 1.2 are not exact, truncating would turn 10 gwei x 1.2 into 11.999999999 gwei)
+func roundToWei(amount *big.Float) *big.Int {
+	wei, _ := amount.Add(amount, big.NewFloat(0.5)).Int(nil)
+	return wei
+}
+
+// Replacement of a pending transaction holding the same nonce
+const (
+	maxGasPriceReplacements = 3    // bumped resubmissions per send before giving up
+	replacementBumpPermille = 1125 // each replacement pays 12.5% more; nodes require at least 10%
+)
+
+// ErrNonceHeldByForeignTx the nonce is held by a pending transaction the request did not send, it is not replaced
+var ErrNonceHeldByForeignTx = errors.New("nonce held by a transaction not sent by this request")
+
+// withdrawTxSender is the part of the RPC client used to send a transaction and look up the one it would replace
+type withdrawTxSender interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+}
+
+// sendReplacingOwnPending signs and sends tx; on "replacement transaction underpriced" it re-signs the same nonce
+// at 12.5% over the pending transaction's gas price (at most maxGasPriceReplacements times)
+// Only previousTxHash, the transaction the request itself sent, is replaced, and only while it is still pending
+// with the same nonce. The returned transaction is the last one sent when sending fails, nil on any other error.
+func sendReplacingOwnPending(ctx context.Context, client withdrawTxSender, tx *types.Transaction, previousTxHash string,
+	networkConfig *config.NetworkConfig, sign func(*types.Transaction) (*types.Transaction, error),
+	checkBalance func(*types.Transaction) error) (*types.Transaction, error) {
+	for replacements := 0; ; replacements++ {
+		signedTx, err := sign(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("🚀 startblockchain...")
+		err = client.SendTransaction(ctx, signedTx)
+		if err == nil {
+			return signedTx, nil
+		}
+		log.Printf("❌ failed: %v", err)
+
+		if !isReplacementUnderpriced(err) || replacements >= maxGasPriceReplacements {
+			return signedTx, err
+		}
+		bumpedGasPrice, bumpErr := replacementGasPrice(ctx, client, previousTxHash, tx, networkConfig)
+		if bumpErr != nil {
+			log.Printf("⚠️ [Replacement] not replacing nonce %d: %v", tx.Nonce(), bumpErr)
+			return signedTx, fmt.Errorf("%w (%w)", err, bumpErr)
+		}
+		log.Printf("⛽ [Replacement %d/%d] replacing own pending tx %s at nonce %d, gas price %s -> %s wei",
+			replacements+1, maxGasPriceReplacements, previousTxHash, tx.Nonce(), tx.GasPrice().String(), bumpedGasPrice.String())
+		tx = withGasPrice(tx, bumpedGasPrice)
+		if err := checkBalance(tx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// replacementGasPrice returns the gas price that replaces the request's own pending transaction previousTxHash
+// 12.5% over the pending transaction's price, or over tx's own price once an earlier replacement was refused
+func replacementGasPrice(ctx context.Context, client withdrawTxSender, previousTxHash string, tx *types.Transaction, networkConfig *config.NetworkConfig) (*big.Int, error) {
+	if previousTxHash == "" {
+		return nil, fmt.Errorf("%w: nonce %d, request has no previous transaction", ErrNonceHeldByForeignTx, tx.Nonce())
+	}
+	pending, isPending, err := client.TransactionByHash(ctx, common.HexToHash(previousTxHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up previous transaction %s: %w", previousTxHash, err)
+	}
+	if !isPending || pending.Nonce() != tx.Nonce() {
+		return nil, fmt.Errorf("%w: nonce %d, previous transaction %s pending=%v nonce=%d",
+			ErrNonceHeldByForeignTx, tx.Nonce(), previousTxHash, isPending, pending.Nonce())
+	}
+
+	base := pending.GasPrice()
+	if tx.GasPrice().Cmp(base) > 0 {
+		base = tx.GasPrice()
+	}
+	return bumpGasPrice(base, networkConfig)
+}
+
+// isReplacementUnderpriced reports whether a send failed because a pending transaction with the same nonce
+// pays more than the replacement may ("replacement transaction underpriced")
+func isReplacementUnderpriced(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "replacement transaction underpriced")
+}
+
+// bumpGasPrice raises gasPrice by 12.5% (rounded up), subject to the network's gasPriceMaxGwei ceiling
+func bumpGasPrice(gasPrice *big.Int, networkConfig *config.NetworkConfig) (*big.Int, error) {
+	bumped := new(big.Int).Mul(gasPrice, big.NewInt(replacementBumpPermille))
+	bumped.Add(bumped, big.NewInt(999))
+	bumped.Div(bumped, big.NewInt(1000))
+	return checkGasPriceCeiling(bumped, networkConfig)
+}
+
+// withGasPrice copies an unsigned legacy transaction with a different gas price (same nonce, gas, to, value, data)
+func withGasPrice(tx *types.Transaction, gasPrice *big.Int) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: gasPrice,
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// stubTxSender rejects sends below minGasPrice as underpriced and reports pendingTx as the pending transaction
+type stubTxSender struct {
+	pendingHash common.Hash
+	pendingTx   *types.Transaction
+	minGasPrice *big.Int
+	sent        []*types.Transaction
+}
+
+func (s *stubTxSender) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	s.sent = append(s.sent, tx)
+	if tx.GasPrice().Cmp(s.minGasPrice) < 0 {
+		return errors.New("replacement transaction underpriced")
+	}
+	return nil
+}
+
+func (s *stubTxSender) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if hash != s.pendingHash {
+		return nil, false, errors.New("not found")
+	}
+	return s.pendingTx, true, nil
+}
+
+func newReplacementFixture(t *testing.T, pendingNonce uint64) (*stubTxSender, *types.Transaction, func(*types.Transaction) (*types.Transaction, error)) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(56))
+	sign := func(tx *types.Transaction) (*types.Transaction, error) {
+		return types.SignTx(tx, signer, key)
+	}
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	pending, err := sign(types.NewTx(&types.LegacyTx{Nonce: pendingNonce, GasPrice: big.NewInt(10_000_000_000), Gas: 600000, To: &to}))
+	if err != nil {
+		t.Fatalf("sign pending: %v", err)
+	}
+	client := &stubTxSender{
+		pendingHash: pending.Hash(),
+		pendingTx:   pending,
+		// nodes accept a replacement paying at least 10% more than the pending transaction
+		minGasPrice: big.NewInt(11_000_000_000),
+	}
+	// the resubmission starts from a fresh suggestion below the stuck transaction's price
+	tx := types.NewTx(&types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(5_000_000_000), Gas: 600000, To: &to})
+	return client, tx, sign
+}
+
+func TestSendReplacingOwnPendingBumpsOverPendingGasPrice(t *testing.T) {
+	client, tx, sign := newReplacementFixture(t, 7)
+	networkConfig := &config.NetworkConfig{ChainID: 714}
+
+	sent, err := sendReplacingOwnPending(context.Background(), client, tx, client.pendingHash.Hex(), networkConfig, sign,
+		func(*types.Transaction) error { return nil })
+	if err != nil {
+		t.Fatalf("sendReplacingOwnPending: %v", err)
+	}
+	if len(client.sent) != 2 {
+		t.Fatalf("sent %d transactions, want the underpriced one and its replacement", len(client.sent))
+	}
+	// 12.5% over the pending transaction's 10 gwei, not over the 5 gwei suggestion
+	if want := big.NewInt(11_250_000_000); sent.GasPrice().Cmp(want) != 0 {
+		t.Errorf("replacement gas price = %s, want %s", sent.GasPrice(), want)
+	}
+	if sent.Nonce() != 7 {
+		t.Errorf("replacement nonce = %d, want the pending nonce 7", sent.Nonce())
+	}
+}
+
+func TestSendReplacingOwnPendingLeavesForeignTransaction(t *testing.T) {
+	tests := []struct {
+		name           string
+		pendingNonce   uint64
+		previousTxHash func(*stubTxSender) string
+	}{
+		{"no previous transaction", 7, func(*stubTxSender) string { return "" }},
+		{"previous transaction unknown", 7, func(*stubTxSender) string { return common.HexToHash("0x01").Hex() }},
+		{"previous transaction holds another nonce", 6, func(c *stubTxSender) string { return c.pendingHash.Hex() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, tx, sign := newReplacementFixture(t, tt.pendingNonce)
+
+			sent, err := sendReplacingOwnPending(context.Background(), client, tx, tt.previousTxHash(client),
+				&config.NetworkConfig{ChainID: 714}, sign, func(*types.Transaction) error { return nil })
+			if !isReplacementUnderpriced(err) {
+				t.Fatalf("err = %v, want the underpriced send error", err)
+			}
+			if len(client.sent) != 1 || sent != client.sent[0] {
+				t.Errorf("sent %d transactions, want only the original attempt", len(client.sent))
+			}
+		})
+	}
+}
+
+func TestSendReplacingOwnPendingStopsAtGasPriceCeiling(t *testing.T) {
+	client, tx, sign := newReplacementFixture(t, 7)
+	networkConfig := &config.NetworkConfig{ChainID: 714, GasPriceMaxGwei: 11}
+
+	if _, err := sendReplacingOwnPending(context.Background(), client, tx, client.pendingHash.Hex(), networkConfig, sign,
+		func(*types.Transaction) error { return nil }); !errors.Is(err, ErrGasPriceAboveCeiling) {
+		t.Fatalf("err = %v, want ErrGasPriceAboveCeiling", err)
+	}
+	if len(client.sent) != 1 {
+		t.Errorf("sent %d transactions, want no replacement above the ceiling", len(client.sent))
+	}
+}
+
+func TestGasPriceFromSuggestion(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	suggestErr := errors.New("rpc unavailable")
+
+	tests := []struct {
+		name       string
+		suggested  *big.Int
+		suggestErr error
+		network    config.NetworkConfig
+		want       *big.Int
+		wantErr    error
+	}{
+		{"default multiplier", gwei(10), nil, config.NetworkConfig{}, gwei(12), nil},
+		{"configured multiplier", gwei(10), nil, config.NetworkConfig{GasPriceMultiplier: 1.5}, gwei(15), nil},
+		{"multiplier below one", gwei(10), nil, config.NetworkConfig{GasPriceMultiplier: 0.9}, gwei(9), nil},
+		{"default fallback", nil, suggestErr, config.NetworkConfig{}, gwei(5), nil},
+		{"configured fallback", nil, suggestErr, config.NetworkConfig{GasPriceFallbackGwei: 3}, gwei(3), nil},
+		{"fallback without error", nil, nil, config.NetworkConfig{GasPriceFallbackGwei: 7}, gwei(7), nil},
+		{"at the ceiling", gwei(10), nil, config.NetworkConfig{GasPriceMaxGwei: 12}, gwei(12), nil},
+		{"above the ceiling", gwei(10), nil, config.NetworkConfig{GasPriceMaxGwei: 11}, nil, ErrGasPriceAboveCeiling},
+		{"fallback above the ceiling", nil, suggestErr, config.NetworkConfig{GasPriceFallbackGwei: 20, GasPriceMaxGwei: 10}, nil, ErrGasPriceAboveCeiling},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.network.ChainID = 714
+			got, err := gasPriceFromSuggestion(tt.suggested, tt.suggestErr, &tt.network)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("gasPriceFromSuggestion: %v", err)
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("gas price = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
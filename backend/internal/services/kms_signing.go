@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"go-backend/internal/config"
+	"go-backend/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrKMSSignatureMismatch neither recovery id recovers the KMS signing address
+var ErrKMSSignatureMismatch = errors.New("KMS signature does not recover to the signing address")
+
+// kmsSigningAddresses signing address per network (chain ID), resolved once from the key management service
+var kmsSigningAddresses = struct {
+	sync.RWMutex
+	byChain map[int]common.Address
+}{byChain: make(map[int]common.Address)}
+
+// cachedKMSSigningAddress returns the signing address of a network, resolving and caching it on first use
+func cachedKMSSigningAddress(keyMgmt *KeyManagementService, networkConfig *config.NetworkConfig) (common.Address, error) {
+	kmsSigningAddresses.RLock()
+	address, ok := kmsSigningAddresses.byChain[networkConfig.ChainID]
+	kmsSigningAddresses.RUnlock()
+	if ok {
+		return address, nil
+	}
+
+	signingAddress, err := keyMgmt.GetSigningAddress(networkConfig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get signing address: %w", err)
+	}
+	address, err = signingAddressToEVM(signingAddress)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	kmsSigningAddresses.Lock()
+	kmsSigningAddresses.byChain[networkConfig.ChainID] = address
+	kmsSigningAddresses.Unlock()
+	log.Printf("🔑 [KMS] Cached signing address for chain %d: %s", networkConfig.ChainID, address.Hex())
+	return address, nil
+}
+
+// signingAddressToEVM parses a signing address (EVM hex or TRON Base58) into its 20-byte form
+func signingAddressToEVM(signingAddress string) (common.Address, error) {
+	if utils.IsTronAddress(signingAddress) {
+		universal, err := utils.TronToUniversalAddress(signingAddress)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid signing address %s: %w", signingAddress, err)
+		}
+		evm, err := utils.ExtractEvmAddressFromUniversal(universal)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid signing address %s: %w", signingAddress, err)
+		}
+		signingAddress = evm
+	}
+	if !common.IsHexAddress(signingAddress) {
+		return common.Address{}, fmt.Errorf("invalid signing address: %s", signingAddress)
+	}
+	return common.HexToAddress(signingAddress), nil
+}
+
+// selectRecoveryID returns the [R || S || V] signature whose V (0 or 1) recovers to expected
+// The V byte returned by KMS is ignored (it may be 0/1, 27/28 or missing); both candidates are tried.
+func selectRecoveryID(hash []byte, signature []byte, expected common.Address) ([]byte, error) {
+	if len(signature) != 64 && len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid KMS signature length: %d", len(signature))
+	}
+
+	candidate := make([]byte, crypto.SignatureLength)
+	copy(candidate, signature[:64])
+	for _, v := range []byte{0, 1} {
+		candidate[64] = v
+		pubKey, err := crypto.SigToPub(hash, candidate)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(crypto.PubkeyToAddress(*pubKey).Bytes(), expected.Bytes()) {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: expected %s", ErrKMSSignatureMismatch, expected.Hex())
+}
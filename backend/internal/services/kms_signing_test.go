@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// kmsTestKey key behind the mock KMS
+var kmsTestKey = func() *ecdsa.PrivateKey {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// mockKMSSignature signs hash like a KMS would: r and s, followed by the given V bytes (if any)
+func mockKMSSignature(t *testing.T, hash []byte, v ...byte) (rs []byte, recoveryID byte) {
+	t.Helper()
+	signature, err := crypto.Sign(hash, kmsTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(signature[:64:64], v...), signature[64]
+}
+
+func TestSelectRecoveryIDPicksTheRecoveringV(t *testing.T) {
+	signer := crypto.PubkeyToAddress(kmsTestKey.PublicKey)
+	hash := crypto.Keccak256([]byte("withdraw tx"))
+	_, wantV := mockKMSSignature(t, hash)
+
+	tests := []struct {
+		name string
+		v    []byte // V byte as returned by KMS
+	}{
+		{"no V", nil},
+		{"correct V", []byte{wantV}},
+		{"flipped V", []byte{wantV ^ 1}},
+		{"legacy 27/28 V", []byte{wantV + 27}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, _ := mockKMSSignature(t, hash, tt.v...)
+			got, err := selectRecoveryID(hash, signature, signer)
+			if err != nil {
+				t.Fatalf("selectRecoveryID: %v", err)
+			}
+			if got[64] != wantV || !bytes.Equal(got[:64], signature[:64]) {
+				t.Errorf("signature V = %d, want %d with r/s unchanged", got[64], wantV)
+			}
+			pubKey, err := crypto.SigToPub(hash, got)
+			if err != nil || crypto.PubkeyToAddress(*pubKey) != signer {
+				t.Errorf("selected signature does not recover to %s", signer.Hex())
+			}
+		})
+	}
+}
+
+func TestSelectRecoveryIDRejectsOtherAddressAndBadLength(t *testing.T) {
+	hash := crypto.Keccak256([]byte("withdraw tx"))
+	signature, _ := mockKMSSignature(t, hash)
+	otherKey, _ := crypto.GenerateKey()
+
+	if _, err := selectRecoveryID(hash, signature, crypto.PubkeyToAddress(otherKey.PublicKey)); !errors.Is(err, ErrKMSSignatureMismatch) {
+		t.Errorf("other address: %v, want ErrKMSSignatureMismatch", err)
+	}
+	if _, err := selectRecoveryID(hash, signature[:63], crypto.PubkeyToAddress(otherKey.PublicKey)); err == nil || errors.Is(err, ErrKMSSignatureMismatch) {
+		t.Errorf("63-byte signature: %v, want a length error", err)
+	}
+}
+
+func TestSigningAddressToEVM(t *testing.T) {
+	if got, err := signingAddressToEVM("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"); err != nil || got.Hex() != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Errorf("hex address = %s, %v", got.Hex(), err)
+	}
+	if _, err := signingAddressToEVM("not-an-address"); err == nil {
+		t.Error("invalid address accepted")
+	}
+}
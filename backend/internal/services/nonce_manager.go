@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nonceKey identifies a signer on a chain
+type nonceKey struct {
+	chainID string
+	address common.Address
+}
+
+// pendingNonceReader is the part of the chain client the nonce manager reads from
+type pendingNonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// nonceManager serializes nonce allocation per (chainID, fromAddress)
+// Concurrent submissions from the same signer get strictly increasing nonces instead of
+// each reading the same PendingNonceAt value.
+type nonceManager struct {
+	mu       sync.Mutex
+	locks    map[nonceKey]*sync.Mutex
+	next     map[nonceKey]uint64              // next nonce to hand out
+	released map[nonceKey]map[uint64]struct{} // nonces below next that were handed out but never broadcast
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{
+		locks:    make(map[nonceKey]*sync.Mutex),
+		next:     make(map[nonceKey]uint64),
+		released: make(map[nonceKey]map[uint64]struct{}),
+	}
+}
+
+// signerLock returns the mutex for a signer, creating it if needed
+func (m *nonceManager) signerLock(key nonceKey) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.locks[key] = lock
+	}
+	return lock
+}
+
+// acquire allocates the next nonce for a signer
+// Uses the larger of the chain pending nonce and the locally tracked next nonce, so transactions
+// sent by other processes are respected and in-flight local transactions are not reused.
+// A released nonce at or above the chain pending nonce is handed out first, filling the gap it left.
+func (m *nonceManager) acquire(client pendingNonceReader, chainID *big.Int, from common.Address) (uint64, error) {
+	key := nonceKey{chainID: chainID.String(), address: from}
+	lock := m.signerLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	chainNonce, err := client.PendingNonceAt(context.Background(), from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	m.mu.Lock()
+	nonce, reused := m.takeReleased(key, chainNonce)
+	if !reused {
+		nonce = chainNonce
+		if tracked, ok := m.next[key]; ok && tracked > chainNonce {
+			nonce = tracked
+		}
+		m.next[key] = nonce + 1
+	}
+	m.mu.Unlock()
+
+	if nonce != chainNonce {
+		log.Printf("🔢 [NonceManager] Chain=%s, From=%s: pending nonce %d, using tracked nonce %d", chainID.String(), from.Hex(), chainNonce, nonce)
+	}
+	return nonce, nil
+}
+
+// takeReleased removes and returns the lowest released nonce not yet used on chain; m.mu must be held
+func (m *nonceManager) takeReleased(key nonceKey, chainNonce uint64) (uint64, bool) {
+	var lowest uint64
+	found := false
+	for nonce := range m.released[key] {
+		if nonce < chainNonce {
+			// Filled by another process meanwhile
+			delete(m.released[key], nonce)
+			continue
+		}
+		if !found || nonce < lowest {
+			lowest, found = nonce, true
+		}
+	}
+	if found {
+		delete(m.released[key], lowest)
+	}
+	return lowest, found
+}
+
+// release returns a nonce that was allocated but never broadcast (build/sign/send failed)
+// Tracking only rolls back when it is the highest nonce handed out; a lower one may already be followed by
+// broadcast transactions, so it is kept aside and handed out again by the next acquire to fill the gap.
+func (m *nonceManager) release(chainID *big.Int, from common.Address, nonce uint64) {
+	key := nonceKey{chainID: chainID.String(), address: from}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next, ok := m.next[key]
+	if !ok || nonce >= next {
+		return
+	}
+	if nonce+1 != next {
+		if m.released[key] == nil {
+			m.released[key] = make(map[uint64]struct{})
+		}
+		m.released[key][nonce] = struct{}{}
+		log.Printf("🔄 [NonceManager] Released nonce %d below tracked %d: Chain=%s, From=%s", nonce, next, chainID.String(), from.Hex())
+		return
+	}
+
+	// Roll back past this nonce and any released ones directly below it
+	next = nonce
+	for next > 0 {
+		if _, released := m.released[key][next-1]; !released {
+			break
+		}
+		delete(m.released[key], next-1)
+		next--
+	}
+	m.next[key] = next
+	log.Printf("🔄 [NonceManager] Rolled back tracked nonce to %d: Chain=%s, From=%s", next, chainID.String(), from.Hex())
+}
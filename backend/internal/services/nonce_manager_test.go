@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fixedPendingNonce reports the same pending nonce every time, as a node does while nothing is mined
+type fixedPendingNonce uint64
+
+func (n fixedPendingNonce) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(n), nil
+}
+
+func TestNonceManagerConcurrentAllocationKeepsInFlightNonces(t *testing.T) {
+	const workers = 20
+	manager := newNonceManager()
+	chainID := big.NewInt(56)
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	client := fixedPendingNonce(100)
+
+	// Every third allocation fails before broadcasting and releases its nonce
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var broadcast []uint64
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce, err := manager.acquire(client, chainID, from)
+			if err != nil {
+				t.Errorf("acquire: %v", err)
+				return
+			}
+			if i%3 == 0 {
+				manager.release(chainID, from, nonce)
+				return
+			}
+			mu.Lock()
+			broadcast = append(broadcast, nonce)
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	// Refill until every released nonce has been reused; none may collide with a broadcast one
+	for len(broadcast) < workers {
+		nonce, err := manager.acquire(client, chainID, from)
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		broadcast = append(broadcast, nonce)
+	}
+	sort.Slice(broadcast, func(i, j int) bool { return broadcast[i] < broadcast[j] })
+	for i, nonce := range broadcast {
+		if want := uint64(100 + i); nonce != want {
+			t.Fatalf("broadcast nonces = %v, want %d contiguous nonces from 100", broadcast, workers)
+		}
+	}
+}
+
+func TestNonceManagerReleaseRollsBackOnlyTheHighestNonce(t *testing.T) {
+	manager := newNonceManager()
+	chainID := big.NewInt(56)
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	client := fixedPendingNonce(5)
+
+	first, _ := manager.acquire(client, chainID, from)
+	second, _ := manager.acquire(client, chainID, from)
+
+	// The failed nonce 5 sits below the in-flight 6: tracking must not fall back to the chain's 5
+	manager.release(chainID, from, first)
+	if nonce, _ := manager.acquire(client, chainID, from); nonce != first {
+		t.Fatalf("after releasing %d got %d, want the gap refilled", first, nonce)
+	}
+	if nonce, _ := manager.acquire(client, chainID, from); nonce != second+1 {
+		t.Fatalf("next nonce = %d, want %d past the in-flight one", nonce, second+1)
+	}
+
+	// Releasing the highest nonce hands it out again
+	manager.release(chainID, from, second+1)
+	if nonce, _ := manager.acquire(client, chainID, from); nonce != second+1 {
+		t.Errorf("after releasing the highest nonce got %d, want %d", nonce, second+1)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"sync"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+)
+
+// PollingStats active and queued polling tasks of the worker pool
+type PollingStats struct {
+	MaxConcurrent int                                         `json:"max_concurrent"`
+	Active        int                                         `json:"active"`
+	Queued        int                                         `json:"queued"`
+	ByType        map[models.PollingTaskType]PollingTypeStats `json:"by_type"`
+}
+
+// PollingTypeStats active and queued polling tasks of one task type
+type PollingTypeStats struct {
+	Limit  int `json:"limit"`
+	Active int `json:"active"`
+	Queued int `json:"queued"`
+}
+
+// pollingPool runs polling tasks with bounded concurrency: at most maxConcurrent in total and at most
+// the type limit per task type. Tasks over a cap wait in a FIFO queue per type.
+type pollingPool struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	typeLimits    map[models.PollingTaskType]int
+	active        map[models.PollingTaskType]int
+	queued        map[models.PollingTaskType][]*models.PollingTask
+	activeTotal   int
+	queuedTotal   int
+	run           func(task *models.PollingTask)
+}
+
+// newPollingPoolFromConfig creates the pool from the polling configuration
+func newPollingPoolFromConfig(run func(task *models.PollingTask)) *pollingPool {
+	cfg := config.GetPollingConfig()
+	typeLimits := make(map[models.PollingTaskType]int, len(cfg.MaxConcurrentPerType))
+	for taskType, limit := range cfg.MaxConcurrentPerType {
+		typeLimits[models.PollingTaskType(taskType)] = limit
+	}
+	return newPollingPool(cfg.MaxConcurrent, typeLimits, run)
+}
+
+// newPollingPool creates a pool; a type limit <= 0 (or missing) means maxConcurrent
+func newPollingPool(maxConcurrent int, typeLimits map[models.PollingTaskType]int, run func(task *models.PollingTask)) *pollingPool {
+	if maxConcurrent <= 0 {
+		maxConcurrent = config.DefaultPollingMaxConcurrent
+	}
+	return &pollingPool{
+		maxConcurrent: maxConcurrent,
+		typeLimits:    typeLimits,
+		active:        make(map[models.PollingTaskType]int),
+		queued:        make(map[models.PollingTaskType][]*models.PollingTask),
+		run:           run,
+	}
+}
+
+// Submit queues a task and starts it as soon as its caps allow
+func (p *pollingPool) Submit(task *models.PollingTask) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queued[task.TaskType] = append(p.queued[task.TaskType], task)
+	p.queuedTotal++
+	p.dispatchLocked()
+}
+
+// Capacity number of tasks that can be submitted without growing the queue beyond maxConcurrent
+func (p *pollingPool) Capacity() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxConcurrent - p.activeTotal - p.queuedTotal
+}
+
+// SaturatedTypes task types whose active + queued tasks already reach their limit (fetching more would only queue them)
+func (p *pollingPool) SaturatedTypes() []models.PollingTaskType {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var saturated []models.PollingTaskType
+	for taskType := range p.typeLimits {
+		if p.active[taskType]+len(p.queued[taskType]) >= p.limitLocked(taskType) {
+			saturated = append(saturated, taskType)
+		}
+	}
+	return saturated
+}
+
+// Stats snapshot of the pool
+func (p *pollingPool) Stats() PollingStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := PollingStats{
+		MaxConcurrent: p.maxConcurrent,
+		Active:        p.activeTotal,
+		Queued:        p.queuedTotal,
+		ByType:        make(map[models.PollingTaskType]PollingTypeStats),
+	}
+	for taskType, active := range p.active {
+		stats.ByType[taskType] = PollingTypeStats{Limit: p.limitLocked(taskType), Active: active, Queued: len(p.queued[taskType])}
+	}
+	for taskType, queue := range p.queued {
+		stats.ByType[taskType] = PollingTypeStats{Limit: p.limitLocked(taskType), Active: p.active[taskType], Queued: len(queue)}
+	}
+	return stats
+}
+
+// limitLocked concurrency cap of a task type
+func (p *pollingPool) limitLocked(taskType models.PollingTaskType) int {
+	if limit := p.typeLimits[taskType]; limit > 0 && limit < p.maxConcurrent {
+		return limit
+	}
+	return p.maxConcurrent
+}
+
+// dispatchLocked starts queued tasks, one per type per round, until the caps are reached
+func (p *pollingPool) dispatchLocked() {
+	for p.activeTotal < p.maxConcurrent {
+		started := false
+		for taskType, queue := range p.queued {
+			if len(queue) == 0 || p.active[taskType] >= p.limitLocked(taskType) {
+				continue
+			}
+			task := queue[0]
+			if len(queue) == 1 {
+				delete(p.queued, taskType)
+			} else {
+				p.queued[taskType] = queue[1:]
+			}
+			p.queuedTotal--
+			p.active[taskType]++
+			p.activeTotal++
+			started = true
+			go p.execute(task)
+
+			if p.activeTotal >= p.maxConcurrent {
+				return
+			}
+		}
+		if !started {
+			return
+		}
+	}
+}
+
+// execute runs one task and frees its slot
+func (p *pollingPool) execute(task *models.PollingTask) {
+	defer func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.active[task.TaskType]--
+		if p.active[task.TaskType] == 0 {
+			delete(p.active, task.TaskType)
+		}
+		p.activeTotal--
+		p.dispatchLocked()
+	}()
+	p.run(task)
+}
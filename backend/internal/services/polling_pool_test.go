@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+)
+
+// concurrencyProbe records how many tasks run at once, in total and per type
+type concurrencyProbe struct {
+	mu         sync.Mutex
+	active     int
+	maxActive  int
+	byType     map[models.PollingTaskType]int
+	maxPerType map[models.PollingTaskType]int
+	done       sync.WaitGroup
+}
+
+func newConcurrencyProbe(tasks int) *concurrencyProbe {
+	probe := &concurrencyProbe{byType: make(map[models.PollingTaskType]int), maxPerType: make(map[models.PollingTaskType]int)}
+	probe.done.Add(tasks)
+	return probe
+}
+
+func (c *concurrencyProbe) run(task *models.PollingTask) {
+	defer c.done.Done()
+	c.mu.Lock()
+	c.active++
+	c.byType[task.TaskType]++
+	if c.active > c.maxActive {
+		c.maxActive = c.active
+	}
+	if c.byType[task.TaskType] > c.maxPerType[task.TaskType] {
+		c.maxPerType[task.TaskType] = c.byType[task.TaskType]
+	}
+	c.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond) // overlap with the tasks started next
+
+	c.mu.Lock()
+	c.active--
+	c.byType[task.TaskType]--
+	c.mu.Unlock()
+}
+
+func TestPollingPoolNeverExceedsCaps(t *testing.T) {
+	const tasks = 40
+	probe := newConcurrencyProbe(tasks)
+	pool := newPollingPool(3, map[models.PollingTaskType]int{models.PollingWithdrawExecute: 1}, probe.run)
+
+	for i := 0; i < tasks; i++ {
+		taskType := models.PollingWithdrawExecute
+		if i%2 == 0 {
+			taskType = models.PollingCommitmentConfirmation
+		}
+		pool.Submit(&models.PollingTask{ID: fmt.Sprintf("task-%d", i), TaskType: taskType})
+	}
+
+	finished := make(chan struct{})
+	go func() { probe.done.Wait(); close(finished) }()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("tasks did not all run: %+v", pool.Stats())
+	}
+
+	if probe.maxActive > 3 {
+		t.Errorf("%d tasks ran at once, cap is 3", probe.maxActive)
+	}
+	if probe.maxActive < 2 {
+		t.Errorf("at most %d task ran at once, want the pool to run tasks concurrently", probe.maxActive)
+	}
+	if got := probe.maxPerType[models.PollingWithdrawExecute]; got != 1 {
+		t.Errorf("%d withdraw_execute tasks ran at once, want exactly the type cap 1", got)
+	}
+	if stats := pool.Stats(); stats.Active != 0 || stats.Queued != 0 {
+		t.Errorf("stats after all tasks = %+v, want empty", stats)
+	}
+}
+
+func TestPollingPoolQueuesOverCap(t *testing.T) {
+	release := make(chan struct{})
+	pool := newPollingPool(2, map[models.PollingTaskType]int{models.PollingWithdrawExecute: 1}, func(*models.PollingTask) { <-release })
+	defer close(release)
+
+	for i := 0; i < 3; i++ {
+		pool.Submit(&models.PollingTask{ID: fmt.Sprintf("exec-%d", i), TaskType: models.PollingWithdrawExecute})
+	}
+	pool.Submit(&models.PollingTask{ID: "confirm", TaskType: models.PollingCommitmentConfirmation})
+
+	stats := pool.Stats()
+	if stats.Active != 2 || stats.Queued != 2 {
+		t.Errorf("active/queued = %d/%d, want 2/2", stats.Active, stats.Queued)
+	}
+	if execute := stats.ByType[models.PollingWithdrawExecute]; execute.Limit != 1 || execute.Active != 1 || execute.Queued != 2 {
+		t.Errorf("withdraw_execute = %+v, want limit 1, 1 active, 2 queued", execute)
+	}
+	if saturated := pool.SaturatedTypes(); len(saturated) != 1 || saturated[0] != models.PollingWithdrawExecute {
+		t.Errorf("saturated = %v, want [withdraw_execute]", saturated)
+	}
+	if capacity := pool.Capacity(); capacity != -2 {
+		t.Errorf("capacity = %d, want -2 (queue already over the pool size)", capacity)
+	}
+}
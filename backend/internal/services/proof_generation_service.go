@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"go-backend/internal/clients"
+	"go-backend/internal/config"
 	"go-backend/internal/models"
 	"go-backend/internal/types"
 
@@ -29,6 +30,8 @@ type ProofGenerationService struct {
 	stopChan       chan struct{}
 	wg             sync.WaitGroup
 	webSocketPushService *WebSocketPushService
+	proofTimeout         time.Duration    // withdraw tasks not completed within this time are failed (0 = never)
+	now                  func() time.Time // clock, replaceable for tests
 }
 
 // NewProofGenerationService 创建证明生成服务
@@ -45,6 +48,8 @@ func NewProofGenerationService(
 		processingTasks:    make(map[string]bool),
 		stopChan:           make(chan struct{}),
 		webSocketPushService: webSocketPushService,
+		proofTimeout:         time.Duration(max(config.GetWithdrawConfig().ProofGenerationTimeout, 0)) * time.Second,
+		now:                  time.Now,
 	}
 }
 
@@ -55,7 +60,17 @@ func (s *ProofGenerationService) Start() {
 	// 启动工作协程
 	s.wg.Add(1)
 	go s.processTasks()
-	
+
+	// 启动队列指标导出
+	s.wg.Add(1)
+	go s.exportQueueMetrics()
+
+	// Fail withdraw proof tasks whose ZKVM worker never finished
+	if s.proofTimeout > 0 {
+		s.wg.Add(1)
+		go s.withdrawProofTimeoutLoop()
+	}
+
 	// 恢复未完成的任务
 	if err := s.recoverPendingTasks(); err != nil {
 		log.Printf("⚠️ [ProofGenerationService] Failed to recover pending tasks: %v", err)
@@ -677,7 +692,7 @@ func (s *ProofGenerationService) processWithdrawProofTask(taskID string) {
 	}
 
 	// 更新状态为 processing
-	now := time.Now()
+	now := s.now()
 	if err := s.db.Model(&task).Updates(map[string]interface{}{
 		"status":     models.WithdrawProofTaskStatusProcessing,
 		"started_at":  &now,
@@ -723,14 +738,23 @@ func (s *ProofGenerationService) processWithdrawProofTask(taskID string) {
 	}
 
 	// 更新任务状态为已完成
+	// Only while still processing: a task failed by the timeout sweeper meanwhile has released its allocations,
+	// so its late proof must not be submitted
 	completedAt := time.Now()
-	if err := s.db.Model(&task).Updates(map[string]interface{}{
-		"status":       models.WithdrawProofTaskStatusCompleted,
-		"result_data":  string(resultData),
-		"completed_at": &completedAt,
-		"updated_at":   time.Now(),
-	}).Error; err != nil {
-		log.Printf("❌ [ProofGenerationService] Failed to update withdraw task status: %v", err)
+	result := s.db.Model(&task).
+		Where("status = ?", models.WithdrawProofTaskStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":       models.WithdrawProofTaskStatusCompleted,
+			"result_data":  string(resultData),
+			"completed_at": &completedAt,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		log.Printf("❌ [ProofGenerationService] Failed to update withdraw task status: %v", result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("⚠️ [ProofGenerationService] Withdraw task %s is no longer processing (timed out?), discarding its proof", task.ID)
 		return
 	}
 
@@ -895,13 +919,9 @@ func (s *ProofGenerationService) continueWithdrawSubmission(
 		log.Printf("❌ [ProofGenerationService] Failed to submit withdraw: %v", err)
 		
 		// 判断错误类型
-		errorMsg := err.Error()
-		isContractRevert := strings.Contains(errorMsg, "execution reverted") ||
-			strings.Contains(errorMsg, "revert") ||
-			strings.Contains(errorMsg, "invalid proof") ||
-			strings.Contains(errorMsg, "nullifier already used")
+		reason, _ := parseRevertReason(err, nil, nil)
 
-		if isContractRevert {
+		if reason.IsPermanent() {
 			// 验证失败，不可重试
 			s.db.Model(&withdrawRequest).Update("execute_status", models.ExecuteStatusVerifyFailed)
 			// 立即更新关联的 Check 状态为 idle（释放 allocations）
@@ -1015,5 +1035,6 @@ func (s *ProofGenerationService) updateChecksStatusOnFailure(requestID string, e
 
 	default:
 		log.Printf("ℹ️ [ProofGenerationService] ExecuteStatus=%s, no Check status update needed", executeStatus)
-	}	return nil
+	}
+	return nil
 }
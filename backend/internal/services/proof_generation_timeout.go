@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// withdrawProofTimeoutInterval how often withdraw proof tasks are checked for the timeout
+const withdrawProofTimeoutInterval = time.Minute
+
+// withdrawProofTimeoutBatchSize timed-out tasks failed per check
+const withdrawProofTimeoutBatchSize = 100
+
+// withdrawProofTimeoutLoop periodically fails withdraw proof tasks that exceeded proofTimeout
+func (s *ProofGenerationService) withdrawProofTimeoutLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(withdrawProofTimeoutInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if failed := s.failTimedOutWithdrawProofTasks(); failed > 0 {
+				log.Printf("⏰ [ProofGenerationService] Failed %d timed-out withdraw proof task(s)", failed)
+			}
+		}
+	}
+}
+
+// failTimedOutWithdrawProofTasks fails withdraw proof tasks that started processing more than proofTimeout ago,
+// along with their requests (proof_status=failed), and releases the requests' allocations to idle.
+// Tasks still queued are not timed out: time spent waiting for a worker is not proof generation time.
+// Returns how many tasks were failed.
+func (s *ProofGenerationService) failTimedOutWithdrawProofTasks() int {
+	deadline := s.now().Add(-s.proofTimeout)
+
+	var tasks []models.WithdrawProofGenerationTask
+	if err := s.db.
+		Where("status = ? AND COALESCE(started_at, created_at) < ?", models.WithdrawProofTaskStatusProcessing, deadline).
+		Order("started_at ASC").
+		Limit(withdrawProofTimeoutBatchSize).
+		Find(&tasks).Error; err != nil {
+		log.Printf("❌ [ProofGenerationService] Failed to query timed-out withdraw proof tasks: %v", err)
+		return 0
+	}
+
+	failed := 0
+	for i := range tasks {
+		task := &tasks[i]
+		timedOut, err := s.failTimedOutWithdrawProofTask(task)
+		if err != nil {
+			log.Printf("❌ [ProofGenerationService] Failed to time out withdraw proof task %s: %v", task.ID, err)
+			continue
+		}
+		if timedOut {
+			failed++
+		}
+	}
+	return failed
+}
+
+// failTimedOutWithdrawProofTask fails one task in a transaction, timedOut=false when it completed meanwhile
+// Every update is guarded by the status it was read in, so a task the worker has just completed (or a request
+// whose proof was already stored) is left alone, and its allocations are only released when the request was failed.
+func (s *ProofGenerationService) failTimedOutWithdrawProofTask(task *models.WithdrawProofGenerationTask) (bool, error) {
+	startedAt := task.CreatedAt
+	if task.StartedAt != nil {
+		startedAt = *task.StartedAt
+	}
+	errorMsg := fmt.Sprintf("proof generation timed out after %s (processing started at %s)",
+		s.proofTimeout, startedAt.Format(time.RFC3339))
+	timedOut := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WithdrawProofGenerationTask{}).
+			Where("id = ? AND status = ?", task.ID, models.WithdrawProofTaskStatusProcessing).
+			Updates(map[string]interface{}{
+				"status":     models.WithdrawProofTaskStatusFailed,
+				"last_error": errorMsg,
+				"updated_at": s.now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // completed or failed meanwhile
+		}
+		timedOut = true
+
+		var request models.WithdrawRequest
+		if err := tx.Where("id = ?", task.WithdrawRequestID).First(&request).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to load withdraw request: %w", err)
+		}
+		if request.ProofStatus != models.ProofStatusInProgress && request.ProofStatus != models.ProofStatusPending {
+			return nil // proof stored by another path, the request is no longer waiting for this task
+		}
+
+		fromStatus := request.ProofStatus
+		request.ProofStatus = models.ProofStatusFailed
+		request.UpdateMainStatus()
+		result = tx.Model(&models.WithdrawRequest{}).
+			Where("id = ? AND proof_status = ?", request.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"proof_status": models.ProofStatusFailed,
+				"proof_error":  errorMsg,
+				"status":       request.Status,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to fail withdraw request: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil // proof status changed since it was read, the allocations still belong to the request
+		}
+
+		// Same release as verify_failed (updateChecksStatusOnFailure): pending allocations back to idle
+		if err := tx.Model(&models.Check{}).
+			Where("withdraw_request_id = ? AND status = ?", request.ID, models.AllocationStatusPending).
+			Updates(map[string]interface{}{
+				"status":              models.AllocationStatusIdle,
+				"withdraw_request_id": nil,
+				"updated_at":          s.now(),
+			}).Error; err != nil {
+			return fmt.Errorf("failed to release allocations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if timedOut {
+		log.Printf("⏰ [ProofGenerationService] Withdraw proof task %s timed out, WithdrawRequest %s failed and allocations released",
+			task.ID, task.WithdrawRequestID)
+	}
+	return timedOut, nil
+}
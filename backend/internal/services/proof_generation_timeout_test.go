@@ -0,0 +1,128 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// seedProofTimeoutRequest stores a request with proof_status=in_progress, one pending allocation and a
+// processing withdraw proof task created at createdAt and picked up by a worker at startedAt
+func seedProofTimeoutRequest(t *testing.T, database *gorm.DB, id string, createdAt, startedAt time.Time) {
+	t.Helper()
+	requestID := id
+	records := []interface{}{
+		&models.WithdrawRequest{
+			ID:                id,
+			WithdrawNullifier: "0xnullifier-" + id,
+			QueueRoot:         "0xroot",
+			Amount:            "100",
+			AllocationIDs:     `["alloc-` + id + `"]`,
+			Status:            string(models.WithdrawStatusProving),
+			ProofStatus:       models.ProofStatusInProgress,
+			ExecuteStatus:     models.ExecuteStatusPending,
+			PayoutStatus:      models.PayoutStatusPending,
+			HookStatus:        models.HookStatusNotRequired,
+		},
+		&models.Check{
+			ID:                "alloc-" + id,
+			CheckbookID:       "cb-" + id,
+			Amount:            "100",
+			Status:            models.AllocationStatusPending,
+			Nullifier:         "0xnullifier-" + id,
+			WithdrawRequestID: &requestID,
+		},
+		&models.WithdrawProofGenerationTask{
+			ID:                "task-" + id,
+			Status:            models.WithdrawProofTaskStatusProcessing,
+			WithdrawRequestID: id,
+			CreatedAt:         createdAt,
+			UpdatedAt:         startedAt,
+			StartedAt:         &startedAt,
+		},
+	}
+	for _, record := range records {
+		if err := database.Create(record).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", record, err)
+		}
+	}
+}
+
+func TestFailTimedOutWithdrawProofTasks(t *testing.T) {
+	database := dbtest.Open(t)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := &ProofGenerationService{
+		db:           database,
+		proofTimeout: 15 * time.Minute,
+		now:          func() time.Time { return now },
+	}
+
+	// Started 20 minutes ago: timed out
+	seedProofTimeoutRequest(t, database, "stale", now.Add(-30*time.Minute), now.Add(-20*time.Minute))
+	// Queued for an hour but picked up 5 minutes ago: still within the timeout
+	seedProofTimeoutRequest(t, database, "late-start", now.Add(-time.Hour), now.Add(-5*time.Minute))
+
+	if failed := service.failTimedOutWithdrawProofTasks(); failed != 1 {
+		t.Fatalf("failed %d task(s), want 1", failed)
+	}
+
+	var task models.WithdrawProofGenerationTask
+	database.First(&task, "id = ?", "task-stale")
+	if task.Status != models.WithdrawProofTaskStatusFailed {
+		t.Errorf("stale task status = %s, want failed", task.Status)
+	}
+	var request models.WithdrawRequest
+	database.First(&request, "id = ?", "stale")
+	if request.ProofStatus != models.ProofStatusFailed || request.Status != string(models.WithdrawStatusProofFailed) {
+		t.Errorf("stale request proof_status=%s status=%s, want failed/proof_failed", request.ProofStatus, request.Status)
+	}
+	var allocation models.Check
+	database.First(&allocation, "id = ?", "alloc-stale")
+	if allocation.Status != models.AllocationStatusIdle || allocation.WithdrawRequestID != nil {
+		t.Errorf("stale allocation status=%s, want idle and unlinked", allocation.Status)
+	}
+
+	database.First(&task, "id = ?", "task-late-start")
+	if task.Status != models.WithdrawProofTaskStatusProcessing {
+		t.Errorf("late-start task status = %s, want processing", task.Status)
+	}
+
+	// 11 minutes later the late-start task exceeds the timeout too
+	now = now.Add(11 * time.Minute)
+	if failed := service.failTimedOutWithdrawProofTasks(); failed != 1 {
+		t.Fatalf("failed %d task(s) after advancing the clock, want 1", failed)
+	}
+}
+
+func TestFailTimedOutWithdrawProofTaskKeepsAllocationsOfCompletedProof(t *testing.T) {
+	database := dbtest.Open(t)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := &ProofGenerationService{
+		db:           database,
+		proofTimeout: 15 * time.Minute,
+		now:          func() time.Time { return now },
+	}
+	seedProofTimeoutRequest(t, database, "done", now.Add(-time.Hour), now.Add(-time.Hour))
+	// The proof was stored by another path after the task was picked up
+	database.Model(&models.WithdrawRequest{}).Where("id = ?", "done").
+		Updates(map[string]interface{}{"proof_status": models.ProofStatusCompleted, "status": models.WithdrawStatusProofGenerated})
+
+	service.failTimedOutWithdrawProofTasks()
+
+	var request models.WithdrawRequest
+	database.First(&request, "id = ?", "done")
+	if request.ProofStatus != models.ProofStatusCompleted {
+		t.Errorf("proof_status = %s, want completed", request.ProofStatus)
+	}
+	var allocation models.Check
+	database.First(&allocation, "id = ?", "alloc-done")
+	if allocation.Status != models.AllocationStatusPending {
+		t.Errorf("allocation status = %s, want pending (still reserved by the request)", allocation.Status)
+	}
+}
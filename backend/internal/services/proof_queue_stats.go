@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"go-backend/internal/metrics"
+	"go-backend/internal/models"
+)
+
+// proofQueueStatsWindow 平均等待/生成时间的统计窗口
+const proofQueueStatsWindow = time.Hour
+
+// proofQueueMetricsInterval Prometheus 指标刷新间隔
+const proofQueueMetricsInterval = 30 * time.Second
+
+// ProofTaskBucketStats 单类任务（首次执行 / 重试）的统计
+type ProofTaskBucketStats struct {
+	Pending              int64   `json:"pending"`
+	InProgress           int64   `json:"in_progress"`
+	Completed            int64   `json:"completed"`              // 统计窗口内完成的任务数
+	AvgWaitSeconds       float64 `json:"avg_wait_seconds"`       // created_at -> started_at
+	AvgGenerationSeconds float64 `json:"avg_generation_seconds"` // started_at -> completed_at
+}
+
+// ProofTaskQueueStats 单个队列的统计，重试过的任务单独统计
+type ProofTaskQueueStats struct {
+	Fresh   ProofTaskBucketStats `json:"fresh"`
+	Retried ProofTaskBucketStats `json:"retried"`
+}
+
+// ProofQueueStats 证明生成队列统计
+type ProofQueueStats struct {
+	Commitment    ProofTaskQueueStats `json:"commitment"`
+	Withdraw      ProofTaskQueueStats `json:"withdraw"`
+	InFlight      int                 `json:"in_flight"` // 当前工作协程持有的任务数
+	WindowSeconds int64               `json:"window_seconds"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+}
+
+// proofTaskBucketRow 聚合查询结果行
+type proofTaskBucketRow struct {
+	Retried              bool
+	Pending              int64
+	InProgress           int64
+	Completed            int64
+	AvgWaitSeconds       float64
+	AvgGenerationSeconds float64
+}
+
+// GetQueueStats 统计证明生成队列（待处理数、处理中数、平均等待时间、平均生成时间）
+// 统计基于任务表的时间戳聚合，只在读取内存中的处理中任务数时短暂持有读锁
+func (s *ProofGenerationService) GetQueueStats() (*ProofQueueStats, error) {
+	since := time.Now().Add(-proofQueueStatsWindow)
+
+	commitmentStats, err := s.queryTaskQueueStats(models.ProofGenerationTask{}.TableName(), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commitment proof queue stats: %w", err)
+	}
+
+	withdrawStats, err := s.queryTaskQueueStats(models.WithdrawProofGenerationTask{}.TableName(), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withdraw proof queue stats: %w", err)
+	}
+
+	s.taskMutex.RLock()
+	inFlight := len(s.processingTasks)
+	s.taskMutex.RUnlock()
+
+	return &ProofQueueStats{
+		Commitment:    *commitmentStats,
+		Withdraw:      *withdrawStats,
+		InFlight:      inFlight,
+		WindowSeconds: int64(proofQueueStatsWindow.Seconds()),
+		GeneratedAt:   time.Now(),
+	}, nil
+}
+
+// queryTaskQueueStats 对单个任务表做聚合统计（retry_count > 0 视为重试任务）
+func (s *ProofGenerationService) queryTaskQueueStats(tableName string, since time.Time) (*ProofTaskQueueStats, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			retry_count > 0 AS retried,
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'processing') AS in_progress,
+			COUNT(*) FILTER (WHERE status = 'completed' AND completed_at >= @since) AS completed,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (started_at - created_at))) FILTER (WHERE started_at >= @since), 0) AS avg_wait_seconds,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (completed_at - started_at))) FILTER (WHERE status = 'completed' AND completed_at >= @since), 0) AS avg_generation_seconds
+		FROM %s
+		WHERE status IN ('pending', 'processing') OR started_at >= @since OR completed_at >= @since
+		GROUP BY retried`, tableName)
+
+	var rows []proofTaskBucketRow
+	if err := s.db.Raw(query, map[string]interface{}{"since": since}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &ProofTaskQueueStats{}
+	for _, row := range rows {
+		bucket := &stats.Fresh
+		if row.Retried {
+			bucket = &stats.Retried
+		}
+		bucket.Pending = row.Pending
+		bucket.InProgress = row.InProgress
+		bucket.Completed = row.Completed
+		bucket.AvgWaitSeconds = row.AvgWaitSeconds
+		bucket.AvgGenerationSeconds = row.AvgGenerationSeconds
+	}
+	return stats, nil
+}
+
+// exportQueueMetrics 定期将队列统计导出为 Prometheus 指标
+func (s *ProofGenerationService) exportQueueMetrics() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(proofQueueMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			stats, err := s.GetQueueStats()
+			if err != nil {
+				log.Printf("⚠️ [ProofGenerationService] Failed to collect queue stats: %v", err)
+				continue
+			}
+			setProofQueueMetrics("commitment", stats.Commitment)
+			setProofQueueMetrics("withdraw", stats.Withdraw)
+			metrics.ProofQueueInFlight.Set(float64(stats.InFlight))
+		}
+	}
+}
+
+// setProofQueueMetrics 更新单个队列的指标
+func setProofQueueMetrics(queue string, stats ProofTaskQueueStats) {
+	for attempt, bucket := range map[string]ProofTaskBucketStats{"fresh": stats.Fresh, "retried": stats.Retried} {
+		metrics.ProofQueueTasks.WithLabelValues(queue, "pending", attempt).Set(float64(bucket.Pending))
+		metrics.ProofQueueTasks.WithLabelValues(queue, "processing", attempt).Set(float64(bucket.InProgress))
+		metrics.ProofQueueTasks.WithLabelValues(queue, "completed", attempt).Set(float64(bucket.Completed))
+		metrics.ProofQueueAvgWaitSeconds.WithLabelValues(queue, attempt).Set(bucket.AvgWaitSeconds)
+		metrics.ProofQueueAvgGenerationSeconds.WithLabelValues(queue, attempt).Set(bucket.AvgGenerationSeconds)
+	}
+}
@@ -1,12 +1,14 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"go-backend/internal/clients"
 	"go-backend/internal/models"
+	"go-backend/internal/repository"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +17,7 @@ import (
 type QueueRootManager struct {
 	db              *gorm.DB
 	blockScannerAPI *clients.BlockScannerAPIClient // BlockScanner APIclient
+	queueRootRepo   repository.QueueRootRepository // position index queries
 }
 
 // NewQueueRootManager Create queue root manager
@@ -22,6 +25,7 @@ func NewQueueRootManager(db *gorm.DB, blockScannerAPI *clients.BlockScannerAPICl
 	return &QueueRootManager{
 		db:              db,
 		blockScannerAPI: blockScannerAPI,
+		queueRootRepo:   repository.NewQueueRootRepository(db),
 	}
 }
 
@@ -84,6 +88,11 @@ func (m *QueueRootManager) ProcessCommitmentRootUpdated(event *clients.EventComm
 			log.Printf("⚠️ Queue root record already exists, skipping creation: %s", newRootRecord.Root)
 		}
 
+		// 3.3 Assign queue positions (new root, backfilled predecessors, successors that arrived out of order)
+		if err := repository.NewQueueRootRepository(tx).AssignPositions(context.Background(), newRootRecord.Root, event.ChainID); err != nil {
+			return fmt.Errorf("Failed to assign queue root positions: %w", err)
+		}
+
 		log.Printf("✅ Queue root bidirectional linked list update completed: NewRoot=%s", event.EventData.NewRoot)
 		return nil
 	})
@@ -230,31 +239,12 @@ func (m *QueueRootManager) GetCommitmentQueueInfo(targetCommitment string) (*Com
 		CommitmentsAfter: []string{},
 	}
 
-	// 3. Trace forward to get all subsequent commitment array
-	currentRoot := targetRecord.Root
-	maxTraversal := 1000 // Prevent infinite loop
-
-	for i := 0; i < maxTraversal; i++ {
-		// Find next record with currentRoot as PreviousRoot
-		var nextRecord models.QueueRoot
-		err := m.db.Where("previous_root = ?", currentRoot).First(&nextRecord).Error
-		if err != nil {
-			if err == gorm.ErrRecordNotFound {
-				log.Printf("📍 Reached queue end, no more subsequent records: root=%s", currentRoot)
-				break
-			}
-			log.Printf("❌ Failed to query subsequent record: %v", err)
-			break
-		}
-
-		// Add to subsequent commitment array
-		result.CommitmentsAfter = append(result.CommitmentsAfter, nextRecord.CreatedByCommitment)
-		log.Printf("🔗 Found subsequent commitment: %s (root: %s -> %s)",
-			nextRecord.CreatedByCommitment, currentRoot, nextRecord.Root)
-
-		// Continue forward searching
-		currentRoot = nextRecord.Root
+	// 3. Get all subsequent commitments (position index, no traversal cap)
+	commitmentsAfter, err := m.GetCommitmentsAfter(targetRecord.Root)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query subsequent commitments: %w", err)
 	}
+	result.CommitmentsAfter = commitmentsAfter
 
 	log.Printf("🎯 Commitment queue info query completed: target=%s, old_root=%s, commitments_after_count=%d",
 		targetCommitment, result.OldRoot, len(result.CommitmentsAfter))
@@ -262,6 +252,11 @@ func (m *QueueRootManager) GetCommitmentQueueInfo(targetCommitment string) (*Com
 	return result, nil
 }
 
+// GetCommitmentsAfter Get all commitments after the specified root, in queue order
+func (m *QueueRootManager) GetCommitmentsAfter(root string) ([]string, error) {
+	return m.queueRootRepo.GetCommitmentsAfter(context.Background(), root)
+}
+
 // CommitmentQueueInfo Commitment queue info
 type CommitmentQueueInfo struct {
 	TargetCommitment string   `json:"target_commitment"` // Target commitment
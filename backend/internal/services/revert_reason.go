@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// RevertReason typed cause of a failed transaction submission
+type RevertReason string
+
+const (
+	RevertReasonNone              RevertReason = ""                   // Not a revert (network/RPC error)
+	RevertReasonInvalidProof      RevertReason = "invalid_proof"      // Verifier rejected the proof
+	RevertReasonNullifierUsed     RevertReason = "nullifier_used"     // Nullifier already spent on-chain
+	RevertReasonInsufficientFunds RevertReason = "insufficient_funds" // Signer cannot pay gas (node error, not a revert)
+	RevertReasonReverted          RevertReason = "reverted"           // Reverted for a reason not recognized below
+)
+
+// IsPermanent reports whether resubmitting the same transaction will fail the same way
+// Only contract reverts are permanent; an underfunded signer can be topped up.
+func (r RevertReason) IsPermanent() bool {
+	switch r {
+	case RevertReasonInvalidProof, RevertReasonNullifierUsed, RevertReasonReverted:
+		return true
+	default:
+		return false
+	}
+}
+
+// revertReplayTimeout timeout of the eth_call replaying a failed transaction
+const revertReplayTimeout = 15 * time.Second
+
+// revertSelectors custom error selectors raised by the verifier and privacy pool
+var revertSelectors = map[[4]byte]RevertReason{
+	errorSelector("InvalidProof()"):                       RevertReasonInvalidProof,
+	errorSelector("WrongVerifierSelector(bytes4,bytes4)"): RevertReasonInvalidProof,
+	errorSelector("NullifierAlreadyUsed()"):               RevertReasonNullifierUsed,
+	errorSelector("NullifierAlreadyUsed(bytes32)"):        RevertReasonNullifierUsed,
+}
+
+// revertMessages lowercase revert strings / provider messages, checked in order
+var revertMessages = []struct {
+	substring string
+	reason    RevertReason
+}{
+	{"nullifier already used", RevertReasonNullifierUsed},
+	{"nullifier used", RevertReasonNullifierUsed},
+	{"nullifieralreadyused", RevertReasonNullifierUsed},
+	{"invalid proof", RevertReasonInvalidProof},
+	{"invalidproof", RevertReasonInvalidProof},
+	{"proof verification failed", RevertReasonInvalidProof},
+	{"insufficient funds", RevertReasonInsufficientFunds},
+	{"execution reverted", RevertReasonReverted},
+	{"vm exception", RevertReasonReverted},
+	{"revert", RevertReasonReverted},
+}
+
+// errorSelector first 4 bytes of keccak256(signature)
+func errorSelector(signature string) [4]byte {
+	var selector [4]byte
+	copy(selector[:], crypto.Keccak256([]byte(signature))[:4])
+	return selector
+}
+
+// parseRevertReason classifies a failed submission into a typed RevertReason and a human-readable detail
+// Structured revert data on err (Error(string) or a known custom error) is used first. Otherwise, if tx is given,
+// the transaction is replayed with eth_call at the block it failed in (latest when it was never mined) to obtain
+// the structured revert. Provider error strings are the last resort. client and tx may be nil.
+func parseRevertReason(err error, client *ethclient.Client, tx *types.Transaction) (RevertReason, string) {
+	if err == nil {
+		return RevertReasonNone, ""
+	}
+
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		return revertErr.Reason, revertErr.Detail
+	}
+
+	if reason, detail, ok := revertReasonFromData(err); ok {
+		return reason, detail
+	}
+
+	if client != nil && tx != nil {
+		if replayErr := replayTransaction(client, tx); replayErr != nil {
+			if reason, detail, ok := revertReasonFromData(replayErr); ok {
+				return reason, detail
+			}
+		}
+	}
+
+	return classifyRevertMessage(err.Error()), err.Error()
+}
+
+// classifyRevertMessage maps a revert string or provider error message to a RevertReason
+func classifyRevertMessage(message string) RevertReason {
+	lower := strings.ToLower(message)
+	for _, m := range revertMessages {
+		if strings.Contains(lower, m.substring) {
+			return m.reason
+		}
+	}
+	return RevertReasonNone
+}
+
+// revertReasonFromData decodes the revert data carried by an RPC error, ok=false when there is none
+func revertReasonFromData(err error) (RevertReason, string, bool) {
+	raw, ok := revertData(err)
+	if !ok {
+		return RevertReasonNone, "", false
+	}
+
+	if message, unpackErr := abi.UnpackRevert(raw); unpackErr == nil {
+		if reason := classifyRevertMessage(message); reason != RevertReasonNone && reason != RevertReasonInsufficientFunds {
+			return reason, message, true
+		}
+		return RevertReasonReverted, message, true
+	}
+
+	if len(raw) >= 4 {
+		var selector [4]byte
+		copy(selector[:], raw[:4])
+		if reason, known := revertSelectors[selector]; known {
+			return reason, "custom error " + hexutil.Encode(raw), true
+		}
+	}
+	return RevertReasonReverted, "custom error " + hexutil.Encode(raw), true
+}
+
+// revertData extracts the raw revert bytes from an RPC error implementing ErrorData
+func revertData(err error) ([]byte, bool) {
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok || data == "" {
+		return nil, false
+	}
+	raw, decodeErr := hexutil.Decode(data)
+	if decodeErr != nil || len(raw) == 0 {
+		return nil, false
+	}
+	return raw, true
+}
+
+// replayTransaction re-executes tx with eth_call at its block (latest if not mined) and returns the call error
+func replayTransaction(client *ethclient.Client, tx *types.Transaction) error {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil // unsigned: cannot replay from the right sender
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), revertReplayTimeout)
+	defer cancel()
+
+	var blockNumber *big.Int
+	if receipt, receiptErr := client.TransactionReceipt(ctx, tx.Hash()); receiptErr == nil {
+		blockNumber = receipt.BlockNumber
+	}
+
+	_, err = client.CallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, blockNumber)
+	return err
+}
+
+// RevertError a submission failure already classified by parseRevertReason
+type RevertError struct {
+	Reason RevertReason
+	Detail string
+	Err    error
+}
+
+// Error implements error
+func (e *RevertError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying submission error
+func (e *RevertError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// dataError an RPC error carrying revert data, like the go-ethereum JSON-RPC client returns
+type dataError struct {
+	message string
+	data    interface{}
+}
+
+func (e *dataError) Error() string          { return e.message }
+func (e *dataError) ErrorData() interface{} { return e.data }
+
+func TestClassifyRevertMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    RevertReason
+	}{
+		{"execution reverted: nullifier already used", RevertReasonNullifierUsed},
+		{"execution reverted: Nullifier Used", RevertReasonNullifierUsed},
+		{"execution reverted: NullifierAlreadyUsed()", RevertReasonNullifierUsed},
+		{"execution reverted: invalid proof", RevertReasonInvalidProof},
+		{"execution reverted: InvalidProof()", RevertReasonInvalidProof},
+		{"Proof verification failed", RevertReasonInvalidProof},
+		{"insufficient funds for gas * price + value: balance 0, tx cost 21000", RevertReasonInsufficientFunds},
+		{"execution reverted", RevertReasonReverted},
+		{"VM Exception while processing transaction: revert", RevertReasonReverted},
+		{"transaction reverted without a reason", RevertReasonReverted},
+		{"nonce too low", RevertReasonNone},
+		{"replacement transaction underpriced", RevertReasonNone},
+		{"context deadline exceeded", RevertReasonNone},
+		{"dial tcp 10.0.0.1:8545: connect: connection refused", RevertReasonNone},
+		{"", RevertReasonNone},
+	}
+	for _, tt := range tests {
+		if got := classifyRevertMessage(tt.message); got != tt.want {
+			t.Errorf("classifyRevertMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestRevertReasonIsPermanent(t *testing.T) {
+	permanent := map[RevertReason]bool{
+		RevertReasonNone:              false,
+		RevertReasonInvalidProof:      true,
+		RevertReasonNullifierUsed:     true,
+		RevertReasonInsufficientFunds: false,
+		RevertReasonReverted:          true,
+	}
+	for reason, want := range permanent {
+		if got := reason.IsPermanent(); got != want {
+			t.Errorf("%q.IsPermanent() = %v, want %v", reason, got, want)
+		}
+	}
+	if isPermanentTxError("insufficient funds for gas") || !isPermanentTxError("execution reverted: invalid proof") {
+		t.Error("isPermanentTxError does not follow the typed reason")
+	}
+}
+
+func TestParseRevertReasonPrefersRevertData(t *testing.T) {
+	invalidProof := errorSelector("InvalidProof()")
+	nullifierUsed := errorSelector("NullifierAlreadyUsed(bytes32)")
+	unknown := []byte{0x12, 0x34, 0x56, 0x78}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantReason RevertReason
+		wantDetail string
+	}{
+		{"nil", nil, RevertReasonNone, ""},
+		{"Error(string) nullifier", &dataError{"execution reverted", errorStringRevert(t, "nullifier already used")},
+			RevertReasonNullifierUsed, "nullifier already used"},
+		{"Error(string) unrecognized", &dataError{"execution reverted", errorStringRevert(t, "paused")},
+			RevertReasonReverted, "paused"},
+		{"Error(string) mentioning funds is still a revert", &dataError{"execution reverted", errorStringRevert(t, "insufficient funds in pool")},
+			RevertReasonReverted, "insufficient funds in pool"},
+		{"custom error InvalidProof", &dataError{"execution reverted", hexutil.Encode(invalidProof[:])},
+			RevertReasonInvalidProof, "custom error " + hexutil.Encode(invalidProof[:])},
+		{"custom error with arguments", &dataError{"execution reverted", hexutil.Encode(append(nullifierUsed[:], make([]byte, 32)...))},
+			RevertReasonNullifierUsed, ""},
+		{"unknown custom error", &dataError{"execution reverted", hexutil.Encode(unknown)},
+			RevertReasonReverted, "custom error 0x12345678"},
+		{"data is not hex falls back to the message", &dataError{"execution reverted: invalid proof", "not hex"},
+			RevertReasonInvalidProof, "execution reverted: invalid proof"},
+		{"no data, provider message", errors.New("insufficient funds for gas * price + value"),
+			RevertReasonInsufficientFunds, "insufficient funds for gas * price + value"},
+		{"no data, transient", errors.New("i/o timeout"), RevertReasonNone, "i/o timeout"},
+		{"wrapped data error", fmt.Errorf("failed to send transaction: %w", &dataError{"execution reverted", hexutil.Encode(invalidProof[:])}),
+			RevertReasonInvalidProof, ""},
+		{"already classified", &RevertError{Reason: RevertReasonNullifierUsed, Detail: "from replay", Err: errors.New("send failed")},
+			RevertReasonNullifierUsed, "from replay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, detail := parseRevertReason(tt.err, nil, nil)
+			if reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+			if tt.wantDetail != "" && detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", detail, tt.wantDetail)
+			}
+		})
+	}
+}
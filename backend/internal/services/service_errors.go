@@ -0,0 +1,65 @@
+package services
+
+import "errors"
+
+// Service error codes, stable identifiers the API layer maps to HTTP statuses
+const (
+	CodeInvalidAllocations           = "INVALID_ALLOCATIONS"
+	CodeAllocationsNotIdle           = "ALLOCATIONS_NOT_IDLE"
+	CodeAllocationsDifferentUser     = "ALLOCATIONS_DIFFERENT_USER"
+	CodeAllocationsExceedAllocatable = "ALLOCATIONS_EXCEED_ALLOCATABLE"
+	CodeAllocationNotInRequest       = "ALLOCATION_NOT_IN_REQUEST"
+	CodeInvalidAllocationAmount      = "INVALID_ALLOCATION_AMOUNT"
+	CodeMalformedAllocationIDs       = "MALFORMED_ALLOCATION_IDS"
+	CodeTooManyAllocations           = "TOO_MANY_ALLOCATIONS"
+	CodeTooManyCheckbooks            = "TOO_MANY_CHECKBOOKS"
+	CodeMixedTokenAllocations        = "MIXED_TOKEN_ALLOCATIONS"
+	CodeInvalidIntent                = "INVALID_INTENT"
+	CodeInvalidOverrideNullifier     = "INVALID_OVERRIDE_NULLIFIER"
+	CodeCannotCancel                 = "CANNOT_CANCEL"
+	CodeCannotRetry                  = "CANNOT_RETRY"
+	CodeCannotManuallyResolve        = "CANNOT_MANUALLY_RESOLVE"
+	CodeRequestClosed                = "REQUEST_CLOSED"
+	CodeSignatureNotStored           = "SIGNATURE_NOT_STORED"
+	CodeMaxRetriesExceeded           = "MAX_RETRIES_EXCEEDED"
+	CodeProofNotCompleted            = "PROOF_NOT_COMPLETED"
+	CodeAlreadyExecuted              = "ALREADY_EXECUTED"
+	CodeVerificationFailed           = "VERIFICATION_FAILED"
+	CodeExecuteNotSuccessful         = "EXECUTE_NOT_SUCCESSFUL"
+	CodePayoutNotCompleted           = "PAYOUT_NOT_COMPLETED"
+	CodePayoutAlreadyCompleted       = "PAYOUT_ALREADY_COMPLETED"
+	CodeAlreadyProcessing            = "ALREADY_PROCESSING"
+	CodeHookNotFailed                = "HOOK_NOT_FAILED"
+	CodeHookCalldataMissing          = "HOOK_CALLDATA_MISSING"
+	CodeInvalidEventQuery            = "INVALID_EVENT_QUERY"
+	CodeBlockchainUnavailable        = "BLOCKCHAIN_UNAVAILABLE"
+	CodeRateLimited                  = "RATE_LIMITED"
+	CodeIdempotencyKeyReused         = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// ServiceError a service-layer failure carrying a machine-readable code
+// Sentinels are *ServiceError values, so errors.Is keeps working through fmt.Errorf("%w") wraps
+// and errors.As / ErrorCode recover the code for the HTTP layer.
+type ServiceError struct {
+	Code    string
+	Message string
+}
+
+// Error implements error
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+// newServiceError creates a sentinel ServiceError
+func newServiceError(code, message string) error {
+	return &ServiceError{Code: code, Message: message}
+}
+
+// ErrorCode returns the code of the first ServiceError in err's chain, "" when there is none
+func ErrorCode(err error) string {
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestServiceErrorSentinelsCarryCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{ErrInvalidAllocations, CodeInvalidAllocations},
+		{ErrAllocationsNotIdle, CodeAllocationsNotIdle},
+		{ErrAllocationsDifferentUser, CodeAllocationsDifferentUser},
+		{ErrAllocationsExceedAllocatable, CodeAllocationsExceedAllocatable},
+		{ErrInvalidIntent, CodeInvalidIntent},
+		{ErrCannotCancel, CodeCannotCancel},
+		{ErrWithdrawRequestClosed, CodeRequestClosed},
+		{ErrCannotPartialCancel, CodeCannotCancel},
+		{ErrAllocationNotInRequest, CodeAllocationNotInRequest},
+		{ErrCannotRetryPayout, CodeCannotRetry},
+		{ErrCannotRetryHook, CodeCannotRetry},
+		{ErrCannotRetryProof, CodeCannotRetry},
+		{ErrSignatureNotStored, CodeSignatureNotStored},
+		{ErrCannotResign, CodeCannotRetry},
+		{ErrMaxRetriesExceeded, CodeMaxRetriesExceeded},
+		{ErrMalformedAllocationIDs, CodeMalformedAllocationIDs},
+		{ErrInvalidOverrideNullifier, CodeInvalidOverrideNullifier},
+		{ErrCannotManuallyResolve, CodeCannotManuallyResolve},
+		{ErrInvalidAllocationAmount, CodeInvalidAllocationAmount},
+		{ErrProofNotCompleted, CodeProofNotCompleted},
+		{ErrAlreadyExecuted, CodeAlreadyExecuted},
+		{ErrVerificationFailed, CodeVerificationFailed},
+		{ErrExecuteNotSuccessful, CodeExecuteNotSuccessful},
+		{ErrPayoutNotCompleted, CodePayoutNotCompleted},
+		{ErrPayoutAlreadyCompleted, CodePayoutAlreadyCompleted},
+		{ErrAlreadyProcessing, CodeAlreadyProcessing},
+		{ErrHookNotFailed, CodeHookNotFailed},
+		{ErrBlockchainUnavailable, CodeBlockchainUnavailable},
+		{ErrTooManyAllocations, CodeTooManyAllocations},
+		{ErrTooManyCheckbooks, CodeTooManyCheckbooks},
+		{ErrMixedTokenAllocations, CodeMixedTokenAllocations},
+		{ErrHookCalldataMissing, CodeHookCalldataMissing},
+		{ErrRateLimited, CodeRateLimited},
+		{ErrIdempotencyKeyReused, CodeIdempotencyKeyReused},
+		{ErrInvalidEventQuery, CodeInvalidEventQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code+"/"+tt.err.Error(), func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.code {
+				t.Errorf("ErrorCode = %q, want %q", got, tt.code)
+			}
+			wrapped := fmt.Errorf("request wr1: %w", tt.err)
+			if got := ErrorCode(wrapped); got != tt.code {
+				t.Errorf("ErrorCode(wrapped) = %q, want %q", got, tt.code)
+			}
+			if !errors.Is(wrapped, tt.err) {
+				t.Error("errors.Is(wrapped, sentinel) = false")
+			}
+		})
+	}
+
+	if got := ErrorCode(errors.New("plain")); got != "" {
+		t.Errorf("ErrorCode(plain error) = %q, want empty", got)
+	}
+}
+
+func TestServiceFailuresReturnCodedErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		call     func(t *testing.T) error
+		sentinel error
+		code     string
+	}{
+		{"cancel executed request", func(t *testing.T) error {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].ProofStatus = models.ProofStatusCompleted
+			store.requests["wr1"].ExecuteStatus = models.ExecuteStatusSuccess
+			return newFakeWithdrawService(store).CancelWithdrawRequest(ctx, "wr1")
+		}, ErrCannotCancel, CodeCannotCancel},
+		{"partial cancel after proof started", func(t *testing.T) error {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100", "200")
+			store.requests["wr1"].ProofStatus = models.ProofStatusInProgress
+			return newFakeWithdrawService(store).CancelWithdrawRequestPartial(ctx, "wr1", []string{"a1"})
+		}, ErrCannotPartialCancel, CodeCannotCancel},
+		{"partial cancel of a foreign allocation", func(t *testing.T) error {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100", "200")
+			return newFakeWithdrawService(store).CancelWithdrawRequestPartial(ctx, "wr1", []string{"a9"})
+		}, ErrAllocationNotInRequest, CodeAllocationNotInRequest},
+		{"retry proof of a cancelled request", func(t *testing.T) error {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].Status = string(models.WithdrawStatusCancelled)
+			return newRetryProofService(t, store).RetryProofGeneration(ctx, "wr1")
+		}, ErrCannotRetryProof, CodeCannotRetry},
+		{"retry proof without signature", func(t *testing.T) error {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].Signature = ""
+			return newRetryProofService(t, store).RetryProofGeneration(ctx, "wr1")
+		}, ErrSignatureNotStored, CodeSignatureNotStored},
+		{"create over locked allocations", func(t *testing.T) error {
+			store := newFakeStore()
+			ids := store.addIdleAllocations("cb1", "0xowner", "100")
+			store.allocations[ids[0]].Status = models.AllocationStatusPending
+			_, err := newFakeWithdrawService(store).CreateWithdrawRequest(ctx, idempotentCreateInput("", ids...))
+			return err
+		}, ErrAllocationsNotIdle, CodeAllocationsNotIdle},
+		{"create without allocations", func(t *testing.T) error {
+			store := newFakeStore()
+			_, err := newFakeWithdrawService(store).CreateWithdrawRequest(ctx, idempotentCreateInput(""))
+			return err
+		}, ErrInvalidAllocations, CodeInvalidAllocations},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(t)
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("err = %v, want %v", err, tt.sentinel)
+			}
+			if got := ErrorCode(err); got != tt.code {
+				t.Errorf("ErrorCode = %q, want %q", got, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// signerBalanceCheckTimeout timeout of the BalanceAt call made for each chain
+const signerBalanceCheckTimeout = 10 * time.Second
+
+// lowBalanceWebhookTimeout timeout of one low-balance webhook POST
+const lowBalanceWebhookTimeout = 10 * time.Second
+
+// LowBalanceAlert body POSTed to blockchain.low_balance_webhook_url when a signer drops below its threshold
+type LowBalanceAlert struct {
+	ChainID      int       `json:"chain_id"`
+	Network      string    `json:"network"`
+	Address      string    `json:"address"`
+	BalanceWei   string    `json:"balance_wei"`
+	ThresholdWei string    `json:"threshold_wei"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// StartBalanceMonitor periodically reads the signing address balance of every chain with a client
+// Chains whose network sets lowBalanceThresholdWei alert (log + optional webhook) when the balance drops below it,
+// once per drop: the alert re-arms when the balance is back at or above the threshold. A first check runs
+// immediately. Calling it while the monitor runs is a no-op.
+func (b *BlockchainTransactionService) StartBalanceMonitor(interval time.Duration) {
+	b.balanceMu.Lock()
+	if b.balanceStopCh != nil {
+		b.balanceMu.Unlock()
+		return
+	}
+	stopCh := make(chan struct{})
+	b.balanceStopCh = stopCh
+	b.balanceMu.Unlock()
+
+	log.Printf("🚀 Starting signer balance monitor (check interval: %v)", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		b.checkSignerBalances()
+		for {
+			select {
+			case <-ticker.C:
+				b.checkSignerBalances()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// StopBalanceMonitor stops the monitor started by StartBalanceMonitor
+func (b *BlockchainTransactionService) StopBalanceMonitor() {
+	b.balanceMu.Lock()
+	defer b.balanceMu.Unlock()
+	if b.balanceStopCh == nil {
+		return
+	}
+	close(b.balanceStopCh)
+	b.balanceStopCh = nil
+	log.Printf("🛑 Signer balance monitor stopped")
+}
+
+// GetSignerBalances returns the signer balance (wei) of each chain at the last balance check
+func (b *BlockchainTransactionService) GetSignerBalances() map[int]*big.Int {
+	b.balanceMu.RLock()
+	defer b.balanceMu.RUnlock()
+	balances := make(map[int]*big.Int, len(b.signerBalances))
+	for chainID, balance := range b.signerBalances {
+		balances[chainID] = new(big.Int).Set(balance)
+	}
+	return balances
+}
+
+// checkSignerBalances reads every chain's signer balance outside the lock and alerts on newly low balances
+func (b *BlockchainTransactionService) checkSignerBalances() {
+	b.clientsMu.RLock()
+	clients := make(map[int]*ethclient.Client, len(b.clients))
+	for chainID, client := range b.clients {
+		clients[chainID] = client
+	}
+	b.clientsMu.RUnlock()
+
+	chainIDs := make([]int, 0, len(clients))
+	for chainID := range clients {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Ints(chainIDs)
+
+	for _, chainID := range chainIDs {
+		networkConfig, err := config.GetNetworkConfigByChainID(chainID)
+		if err != nil {
+			log.Printf("⚠️ [SignerBalance] Chain %d: %v", chainID, err)
+			continue
+		}
+		signingAddress, err := b.keyMgmtService.GetSigningAddress(networkConfig)
+		if err != nil {
+			log.Printf("⚠️ [SignerBalance] Chain %d: failed to get signing address: %v", chainID, err)
+			continue
+		}
+		b.checkSignerBalance(chainID, clients[chainID], networkConfig, signingAddress)
+	}
+}
+
+// checkSignerBalance reads one chain's signer balance and alerts when it just dropped below the threshold
+func (b *BlockchainTransactionService) checkSignerBalance(chainID int, client *ethclient.Client, networkConfig *config.NetworkConfig, signingAddress string) {
+	ctx, cancel := context.WithTimeout(context.Background(), signerBalanceCheckTimeout)
+	balance, err := client.BalanceAt(ctx, common.HexToAddress(signingAddress), nil)
+	cancel()
+	if err != nil {
+		log.Printf("⚠️ [SignerBalance] Chain %d: failed to query balance of %s: %v", chainID, signingAddress, err)
+		return
+	}
+
+	threshold, err := lowBalanceThreshold(networkConfig)
+	if err != nil {
+		log.Printf("⚠️ [SignerBalance] Chain %d: %v", chainID, err)
+	}
+	if alert := b.recordSignerBalance(chainID, balance, threshold); alert {
+		b.alertLowBalance(networkConfig, signingAddress, balance, threshold)
+	}
+}
+
+// recordSignerBalance stores a chain's balance and reports whether it just dropped below threshold
+// A nil threshold (not monitored) never alerts and clears the low state.
+func (b *BlockchainTransactionService) recordSignerBalance(chainID int, balance, threshold *big.Int) bool {
+	b.balanceMu.Lock()
+	defer b.balanceMu.Unlock()
+	b.signerBalances[chainID] = balance
+
+	low := threshold != nil && balance.Cmp(threshold) < 0
+	wasLow := b.lowBalance[chainID]
+	b.lowBalance[chainID] = low
+	if wasLow && !low && threshold != nil {
+		log.Printf("✅ [SignerBalance] Chain %d signer balance recovered: %s wei (threshold %s wei)", chainID, balance, threshold)
+	}
+	return low && !wasLow
+}
+
+// alertLowBalance logs a low signer balance and POSTs it to blockchain.low_balance_webhook_url when set
+func (b *BlockchainTransactionService) alertLowBalance(networkConfig *config.NetworkConfig, signingAddress string, balance, threshold *big.Int) {
+	log.Printf("🚨 [SignerBalance] Chain %d (%s) signer %s balance %s wei is below the threshold %s wei, top it up before submissions fail",
+		networkConfig.ChainID, networkConfig.Name, signingAddress, balance, threshold)
+
+	if config.AppConfig == nil || config.AppConfig.Blockchain.LowBalanceWebhookURL == "" {
+		return
+	}
+	alert := LowBalanceAlert{
+		ChainID:      networkConfig.ChainID,
+		Network:      networkConfig.Name,
+		Address:      signingAddress,
+		BalanceWei:   balance.String(),
+		ThresholdWei: threshold.String(),
+		CheckedAt:    time.Now().UTC(),
+	}
+	if err := postLowBalanceAlert(config.AppConfig.Blockchain.LowBalanceWebhookURL, alert); err != nil {
+		log.Printf("❌ [SignerBalance] Failed to send low balance webhook for chain %d: %v", networkConfig.ChainID, err)
+	}
+}
+
+// postLowBalanceAlert POSTs alert as JSON, any non-2xx response is an error
+func postLowBalanceAlert(url string, alert LowBalanceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	client := &http.Client{Timeout: lowBalanceWebhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// lowBalanceThreshold parses a network's lowBalanceThresholdWei, nil when it is not set
+func lowBalanceThreshold(networkConfig *config.NetworkConfig) (*big.Int, error) {
+	value := strings.TrimSpace(networkConfig.LowBalanceThresholdWei)
+	if value == "" {
+		return nil, nil
+	}
+	threshold, ok := new(big.Int).SetString(value, 10)
+	if !ok || threshold.Sign() < 0 {
+		return nil, fmt.Errorf("invalid lowBalanceThresholdWei %q, balance is not monitored", networkConfig.LowBalanceThresholdWei)
+	}
+	return threshold, nil
+}
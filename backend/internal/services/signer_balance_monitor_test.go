@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// newStubBalanceRPC answers eth_getBalance with the current value of balance (wei)
+func newStubBalanceRPC(t *testing.T, balance *atomic.Int64) *ethclient.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_getBalance" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, balance.Load())
+	}))
+	t.Cleanup(server.Close)
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial stub RPC: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestSignerBalanceAlertFiresOncePerDrop(t *testing.T) {
+	const chainID = 714
+	const signer = "0x00000000000000000000000000000000000000aa"
+
+	var mu sync.Mutex
+	var alerts []LowBalanceAlert
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert LowBalanceAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		alerts = append(alerts, alert)
+		mu.Unlock()
+	}))
+	t.Cleanup(webhook.Close)
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{LowBalanceWebhookURL: webhook.URL}}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+	networkConfig := &config.NetworkConfig{ChainID: chainID, Name: "bsc", LowBalanceThresholdWei: "1000"}
+
+	var balance atomic.Int64
+	client := newStubBalanceRPC(t, &balance)
+	service := NewBlockchainTransactionService(nil)
+	alertCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(alerts)
+	}
+
+	steps := []struct {
+		name       string
+		balance    int64
+		wantAlerts int
+	}{
+		{"above threshold", 5000, 0},
+		{"drops below threshold", 400, 1},
+		{"still low", 300, 1},
+		{"still low again", 200, 1},
+		{"recovers", 1000, 1},
+		{"drops again", 999, 2},
+	}
+	for _, step := range steps {
+		balance.Store(step.balance)
+		service.checkSignerBalance(chainID, client, networkConfig, signer)
+
+		if got := alertCount(); got != step.wantAlerts {
+			t.Fatalf("%s: %d alerts, want %d", step.name, got, step.wantAlerts)
+		}
+		if got := service.GetSignerBalances()[chainID]; got == nil || got.Int64() != step.balance {
+			t.Errorf("%s: GetSignerBalances = %v, want %d", step.name, got, step.balance)
+		}
+	}
+
+	first := alerts[0]
+	if first.ChainID != chainID || first.Network != "bsc" || first.Address != signer ||
+		first.BalanceWei != "400" || first.ThresholdWei != "1000" {
+		t.Errorf("first alert = %+v, want chain 714 bsc signer at 400 of 1000 wei", first)
+	}
+}
+
+func TestSignerBalanceWithoutThresholdNeverAlerts(t *testing.T) {
+	var posts atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { posts.Add(1) }))
+	t.Cleanup(webhook.Close)
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{LowBalanceWebhookURL: webhook.URL}}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	var balance atomic.Int64
+	client := newStubBalanceRPC(t, &balance)
+	service := NewBlockchainTransactionService(nil)
+	service.checkSignerBalance(714, client, &config.NetworkConfig{ChainID: 714, Name: "bsc"}, "0x00000000000000000000000000000000000000aa")
+
+	if got := posts.Load(); got != 0 {
+		t.Errorf("%d alerts for a network without lowBalanceThresholdWei, want 0", got)
+	}
+	if got := service.GetSignerBalances()[714]; got == nil || got.Sign() != 0 {
+		t.Errorf("GetSignerBalances = %v, want the zero balance recorded", got)
+	}
+}
@@ -171,7 +171,7 @@ func (s *SubgraphSyncService) SyncChain(chainID int64, subgraphConfig SubgraphCo
 		}
 
 		if exists {
-			log.Printf("ℹ️  Event already exists in database: txHash=%s, logIndex=%d", event.TransactionHash, event.LogIndex)
+			log.Printf("ℹ️  Event already exists in database: txHash=%s, logIndex=%s", event.TransactionHash, event.LogIndex)
 			continue
 		}
 
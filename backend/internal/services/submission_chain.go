@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+
+	"go-backend/internal/config"
+	"go-backend/internal/utils"
+)
+
+// submissionTxType transaction kinds routed by resolveSubmissionChain
+type submissionTxType string
+
+const (
+	submissionCommitment submissionTxType = "commitment"
+	submissionWithdraw   submissionTxType = "withdraw"
+)
+
+// resolveSubmissionChain returns the SLIP-44 chain a commitment or withdraw is submitted to
+// requestChainID is the request's chain: the deposit chain for commitments, the beneficiary chain for withdraws.
+// With the default routing both go to the management chain.
+func resolveSubmissionChain(txType submissionTxType, requestChainID int) int {
+	commitmentRouting, withdrawRouting := config.GetSubmitChainRouting()
+
+	routeToRequestChain := false
+	switch txType {
+	case submissionCommitment:
+		routeToRequestChain = commitmentRouting == config.SubmitChainDeposit
+	case submissionWithdraw:
+		routeToRequestChain = withdrawRouting == config.SubmitChainBeneficiary
+	}
+
+	if routeToRequestChain && requestChainID > 0 {
+		return utils.SmartToSlip44(requestChainID)
+	}
+	return config.GetManagementChainID()
+}
+
+// submissionTarget the chain a commitment or withdraw is sent to, with the EVM chain ID its RPC must report
+type submissionTarget struct {
+	slip44ChainID int
+	evmChainID    uint64
+	network       *config.NetworkConfig
+}
+
+// resolveSubmissionTarget resolves the submission chain of resolveSubmissionChain and its network config
+func resolveSubmissionTarget(txType submissionTxType, requestChainID int) (*submissionTarget, error) {
+	return submissionTargetForChain(txType, resolveSubmissionChain(txType, requestChainID))
+}
+
+// submissionTargetForChain the target of an already resolved submission chain
+// The expected EVM chain ID is the network's evmChainId when set, otherwise the SLIP-44 mapping of its chain.
+func submissionTargetForChain(txType submissionTxType, submitChainID int) (*submissionTarget, error) {
+	networkConfig, err := config.GetNetworkConfigByChainID(submitChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	evmChainID := networkConfig.EVMChainID
+	if evmChainID <= 0 {
+		evmChainID = utils.Slip44ToEvm(submitChainID) // e.g. BSC SLIP-44 714 -> EVM 56
+	}
+	if evmChainID <= 0 {
+		return nil, fmt.Errorf("no EVM chain ID known for %s submission chain SLIP-44 %d, set evmChainId in its network config", txType, submitChainID)
+	}
+
+	return &submissionTarget{slip44ChainID: submitChainID, evmChainID: uint64(evmChainID), network: networkConfig}, nil
+}
+
+// verifyChainID checks that the RPC connected for the target reports the target's EVM chain ID
+func (t *submissionTarget) verifyChainID(actualChainID *big.Int) error {
+	if !actualChainID.IsUint64() || actualChainID.Uint64() != t.evmChainID {
+		return fmt.Errorf("chain ID mismatch: expected EVM %d (submission SLIP-44 %d), got EVM %s", t.evmChainID, t.slip44ChainID, actualChainID)
+	}
+	return nil
+}
+
+// withdrawSubmitChain the SLIP-44 chain executeWithdraw is sent to: SubmitChainID when the caller resolved it,
+// otherwise resolveSubmissionChain from req.ChainID
+func withdrawSubmitChain(req *WithdrawRequest) int {
+	if req.SubmitChainID > 0 {
+		return req.SubmitChainID
+	}
+	return resolveSubmissionChain(submissionWithdraw, req.ChainID)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// newStubChainRPC an RPC endpoint reporting evmChainID (eth_chainId and net_version)
+func newStubChainRPC(t *testing.T, evmChainID uint64) *ethclient.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_chainId":
+			result = "0x" + strconv.FormatUint(evmChainID, 16)
+		case "net_version":
+			result = strconv.FormatUint(evmChainID, 10)
+		}
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial stub: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// useSubmissionConfig installs a config with BSC (SLIP-44 714) as management chain and Ethereum (60) enabled
+func useSubmissionConfig(t *testing.T, commitmentRouting, withdrawRouting string, ethEVMChainID int) {
+	t.Helper()
+	previous := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{
+		ManagementChainID:     714,
+		CommitmentSubmitChain: commitmentRouting,
+		WithdrawSubmitChain:   withdrawRouting,
+		Networks: map[string]config.NetworkConfig{
+			"bsc":      {ChainID: 714, Enabled: true},
+			"ethereum": {ChainID: 60, Enabled: true, EVMChainID: ethEVMChainID},
+		},
+	}}
+	t.Cleanup(func() { config.AppConfig = previous })
+}
+
+func TestSubmissionTargetVerifiesRPCChainID(t *testing.T) {
+	tests := []struct {
+		name        string
+		commitment  string
+		withdraw    string
+		ethEVMID    int
+		txType      submissionTxType
+		requestID   int
+		wantSLIP44  int
+		rpcChainID  uint64
+		wantMatches bool
+	}{
+		{"commitment on management chain", "", "", 0, submissionCommitment, 60, 714, 56, true},
+		{"commitment on management chain, RPC of the source chain", "", "", 0, submissionCommitment, 60, 714, 1, false},
+		{"commitment on source chain", config.SubmitChainDeposit, "", 0, submissionCommitment, 60, 60, 1, true},
+		{"commitment on source chain, RPC of the management chain", config.SubmitChainDeposit, "", 0, submissionCommitment, 60, 60, 56, false},
+		{"commitment on source chain with evmChainId override", config.SubmitChainDeposit, "", 11155111, submissionCommitment, 60, 60, 11155111, true},
+		{"commitment on source chain, override not honoured by RPC", config.SubmitChainDeposit, "", 11155111, submissionCommitment, 60, 60, 1, false},
+		{"withdraw on management chain", "", "", 0, submissionWithdraw, 60, 714, 56, true},
+		{"withdraw on beneficiary chain", "", config.SubmitChainBeneficiary, 0, submissionWithdraw, 60, 60, 1, true},
+		{"withdraw on beneficiary chain, RPC of the management chain", "", config.SubmitChainBeneficiary, 0, submissionWithdraw, 60, 60, 56, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSubmissionConfig(t, tt.commitment, tt.withdraw, tt.ethEVMID)
+			client := newStubChainRPC(t, tt.rpcChainID)
+
+			target, err := resolveSubmissionTarget(tt.txType, tt.requestID)
+			if err != nil {
+				t.Fatalf("resolveSubmissionTarget: %v", err)
+			}
+			if target.slip44ChainID != tt.wantSLIP44 {
+				t.Fatalf("submission chain = %d, want %d", target.slip44ChainID, tt.wantSLIP44)
+			}
+
+			actual, err := client.NetworkID(context.Background())
+			if err != nil {
+				t.Fatalf("NetworkID: %v", err)
+			}
+			err = target.verifyChainID(actual)
+			if tt.wantMatches && err != nil {
+				t.Errorf("verifyChainID: %v", err)
+			}
+			if !tt.wantMatches && (err == nil || !strings.Contains(err.Error(), "chain ID mismatch")) {
+				t.Errorf("verifyChainID = %v, want a chain ID mismatch", err)
+			}
+		})
+	}
+}
+
+func TestResolveSubmissionTargetRequiresEnabledNetwork(t *testing.T) {
+	useSubmissionConfig(t, config.SubmitChainDeposit, "", 0)
+
+	if _, err := resolveSubmissionTarget(submissionCommitment, 195); err == nil {
+		t.Error("resolveSubmissionTarget for a chain without network config succeeded, want an error")
+	}
+}
+
+func TestResolveSubmissionChainRouting(t *testing.T) {
+	tests := []struct {
+		name       string
+		management int
+		commitment string
+		withdraw   string
+		txType     submissionTxType
+		requestID  int
+		want       int
+	}{
+		// Single management chain: every submission goes there, whatever the request chain
+		{"default commitment", 0, "", "", submissionCommitment, 60, config.DefaultManagementChainID},
+		{"default withdraw", 0, "", "", submissionWithdraw, 195, config.DefaultManagementChainID},
+		{"configured management chain", 966, config.SubmitChainManagement, config.SubmitChainManagement, submissionWithdraw, 60, 966},
+		{"unknown routing value", 714, "nearest", "cheapest", submissionCommitment, 60, 714},
+		{"routing value of the other type", 714, config.SubmitChainBeneficiary, config.SubmitChainDeposit, submissionWithdraw, 60, 714},
+
+		// Per-chain routing: to the deposit / beneficiary chain, EVM IDs mapped to SLIP-44
+		{"commitment to deposit chain", 714, config.SubmitChainDeposit, "", submissionCommitment, 60, 60},
+		{"commitment routing is case-insensitive", 714, "Deposit", "", submissionCommitment, 966, 966},
+		{"commitment with EVM request chain", 714, config.SubmitChainDeposit, "", submissionCommitment, 1, 60},
+		{"withdraw to beneficiary chain", 714, "", config.SubmitChainBeneficiary, submissionWithdraw, 195, 195},
+		{"withdraw routing leaves commitments on management", 714, "", config.SubmitChainBeneficiary, submissionCommitment, 195, 714},
+		{"beneficiary routing without a request chain", 714, "", config.SubmitChainBeneficiary, submissionWithdraw, 0, 714},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{
+				ManagementChainID:     tt.management,
+				CommitmentSubmitChain: tt.commitment,
+				WithdrawSubmitChain:   tt.withdraw,
+			}}
+			t.Cleanup(func() { config.AppConfig = previous })
+
+			if got := resolveSubmissionChain(tt.txType, tt.requestID); got != tt.want {
+				t.Errorf("resolveSubmissionChain(%s, %d) = %d, want %d", tt.txType, tt.requestID, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"go-backend/internal/config"
+	"go-backend/internal/repository"
+	"go-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// NewTokenDecimalConverter the converter deposit amounts are stored with: tokens.chainDecimals and
+// tokens.tokenAddressDecimals from the config, with token_decimals_overrides applied
+func NewTokenDecimalConverter(db *gorm.DB) *utils.TokenAddressDecimalConverter {
+	if config.AppConfig == nil {
+		return utils.NewDecimalConverterWithTokenAddresses(nil, nil)
+	}
+	return utils.NewDecimalConverterWithTokenAddresses(
+		config.AppConfig.Tokens.ChainDecimals, // empty: UseDefaultConfiguration
+		tokenAddressDecimalsWithOverrides(db, config.AppConfig.Tokens.TokenAddressDecimals),
+	)
+}
+
+// tokenAddressDecimalsWithOverrides the tokens.tokenAddressDecimals config with token_decimals_overrides applied
+// Overrides (on-chain decimals written by cmd/verify-token-decimals) win over the configured value of the same
+// token. If the overrides cannot be loaded the config is used as-is.
+func tokenAddressDecimalsWithOverrides(db *gorm.DB, configured map[int]map[string]int) map[int]map[string]int {
+	if db == nil {
+		return configured
+	}
+	overrides, err := repository.NewTokenDecimalsOverrideRepository(db).FindAll(context.Background())
+	if err != nil {
+		log.Printf("⚠️ [TokenDecimals] Failed to load token decimals overrides, using config only: %v", err)
+		return configured
+	}
+	if len(overrides) == 0 {
+		return configured
+	}
+
+	merged := make(map[int]map[string]int, len(configured))
+	for chainID, tokens := range configured {
+		chainTokens := make(map[string]int, len(tokens))
+		for address, decimals := range tokens {
+			chainTokens[utils.CanonicalTokenAddress(address, chainID)] = decimals
+		}
+		merged[chainID] = chainTokens
+	}
+	for _, override := range overrides {
+		if merged[override.ChainID] == nil {
+			merged[override.ChainID] = make(map[string]int)
+		}
+		key := utils.CanonicalTokenAddress(override.TokenAddress, override.ChainID)
+		if configuredDecimals, ok := merged[override.ChainID][key]; ok && configuredDecimals != override.Decimals {
+			log.Printf("⚠️ [TokenDecimals] Chain %d token %s: configured decimals %d overridden by on-chain %d",
+				override.ChainID, key, configuredDecimals, override.Decimals)
+		}
+		merged[override.ChainID][key] = override.Decimals
+	}
+	return merged
+}
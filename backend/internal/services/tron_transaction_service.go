@@ -0,0 +1,290 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/config"
+	"go-backend/internal/utils"
+)
+
+// ChainTransactionService submits ZKPay transactions to a chain
+// Implemented by BlockchainTransactionService (EVM) and TronTransactionService (TRON)
+type ChainTransactionService interface {
+	SubmitWithdraw(req *WithdrawRequest) (*WithdrawResponse, error)
+	SubmitCommitment(req *CommitmentRequest) (*CommitmentTxResponse, error)
+}
+
+var (
+	_ ChainTransactionService = (*BlockchainTransactionService)(nil)
+	_ ChainTransactionService = (*TronTransactionService)(nil)
+)
+
+// TronChainID TRON SLIP-44 chain ID
+const TronChainID = 195
+
+// tronDefaultFeeLimit default fee limit for triggerSmartContract (sun, 1 TRX = 1e6 sun)
+const tronDefaultFeeLimit = int64(1000 * 1e6)
+
+// TronTransactionService TRON transaction service
+// Builds triggerSmartContract transactions via the TRON HTTP API, signs the txID with the
+// configured signer (private key or KMS, same secp256k1 key as EVM) and broadcasts them.
+type TronTransactionService struct {
+	keyMgmtService *KeyManagementService
+	evmService     *BlockchainTransactionService // ZKPay ABI encoding is shared with the EVM path
+	httpClient     *http.Client
+
+	// signer returns the signing address and strategy of a network, keyMgmtSigner by default
+	signer func(networkConfig *config.NetworkConfig) (string, SigningStrategy, error)
+}
+
+// NewTronTransactionService creates a TRON transaction service
+func NewTronTransactionService(keyMgmtService *KeyManagementService, evmService *BlockchainTransactionService) *TronTransactionService {
+	service := &TronTransactionService{
+		keyMgmtService: keyMgmtService,
+		evmService:     evmService,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+	}
+	service.signer = service.keyMgmtSigner
+	return service
+}
+
+// SubmitWithdraw submits executeWithdraw to the TRON ZKPay contract
+func (t *TronTransactionService) SubmitWithdraw(req *WithdrawRequest) (*WithdrawResponse, error) {
+	log.Printf("🚀 [TronTx] SubmitWithdraw: CheckbookID=%s, CheckID=%s, TargetChain=%d", req.CheckbookID, req.CheckID, req.ChainID)
+
+	networkConfig, err := config.GetNetworkConfigByChainID(TronChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TRON network config: %w", err)
+	}
+
+	callData, err := t.evmService.buildWithdrawCallData(networkConfig, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build call data: %w", err)
+	}
+
+	txID, err := t.triggerAndBroadcast(networkConfig, callData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WithdrawResponse{
+		TxHash:    txID,
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// SubmitCommitment submits executeCommitment to the TRON ZKPay contract
+func (t *TronTransactionService) SubmitCommitment(req *CommitmentRequest) (*CommitmentTxResponse, error) {
+	log.Printf("🚀 [TronTx] SubmitCommitment: CheckbookID=%s, SourceChain=%d", req.CheckbookID, req.ChainID)
+
+	networkConfig, err := config.GetNetworkConfigByChainID(TronChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TRON network config: %w", err)
+	}
+
+	callData, err := t.evmService.buildCommitmentCallData(networkConfig, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build call data: %w", err)
+	}
+
+	txID, err := t.triggerAndBroadcast(networkConfig, callData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CommitmentTxResponse{
+		TxHash:    txID,
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// tronTriggerResponse /wallet/triggersmartcontract response
+type tronTriggerResponse struct {
+	Result struct {
+		Result  bool   `json:"result"`
+		Code    string `json:"code"`
+		Message string `json:"message"` // hex encoded
+	} `json:"result"`
+	Transaction map[string]interface{} `json:"transaction"`
+}
+
+// tronBroadcastResponse /wallet/broadcasttransaction response
+type tronBroadcastResponse struct {
+	Result  bool   `json:"result"`
+	TxID    string `json:"txid"`
+	Code    string `json:"code"`
+	Message string `json:"message"` // hex encoded
+}
+
+// triggerAndBroadcast builds, signs and broadcasts a triggerSmartContract transaction, returns the txID
+func (t *TronTransactionService) triggerAndBroadcast(networkConfig *config.NetworkConfig, callData []byte) (string, error) {
+	if len(networkConfig.RPCEndpoints) == 0 {
+		return "", fmt.Errorf("no TRON API endpoint configured")
+	}
+	endpoint := strings.TrimSuffix(networkConfig.RPCEndpoints[0], "/")
+
+	signingAddress, strategy, err := t.signer(networkConfig)
+	if err != nil {
+		return "", err
+	}
+	ownerAddress, err := toTronBase58(signingAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid signing address: %w", err)
+	}
+
+	zkpayContract, err := getZKPayContractAddress(networkConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ZKPay contract address: %w", err)
+	}
+	contractAddress, err := toTronBase58(zkpayContract)
+	if err != nil {
+		return "", fmt.Errorf("invalid ZKPay contract address: %w", err)
+	}
+
+	log.Printf("🔧 [TronTx] triggerSmartContract: owner=%s, contract=%s, data=%d bytes", ownerAddress, contractAddress, len(callData))
+
+	// 1. Build unsigned transaction
+	var trigger tronTriggerResponse
+	if err := t.post(endpoint+"/wallet/triggersmartcontract", map[string]interface{}{
+		"owner_address":    ownerAddress,
+		"contract_address": contractAddress,
+		"data":             hex.EncodeToString(callData),
+		"fee_limit":        tronDefaultFeeLimit,
+		"call_value":       0,
+		"visible":          true,
+	}, &trigger); err != nil {
+		return "", fmt.Errorf("triggersmartcontract failed: %w", err)
+	}
+	if !trigger.Result.Result || trigger.Transaction == nil {
+		return "", fmt.Errorf("triggersmartcontract rejected: code=%s, message=%s", trigger.Result.Code, decodeTronMessage(trigger.Result.Message))
+	}
+
+	txID, _ := trigger.Transaction["txID"].(string)
+	rawDataHex, _ := trigger.Transaction["raw_data_hex"].(string)
+	rawData, err := hex.DecodeString(rawDataHex)
+	if err != nil || txID == "" {
+		return "", fmt.Errorf("invalid transaction returned by triggersmartcontract")
+	}
+
+	// txID = sha256(raw_data), verify before signing
+	digest := sha256.Sum256(rawData)
+	if hex.EncodeToString(digest[:]) != strings.ToLower(txID) {
+		return "", fmt.Errorf("txID mismatch: expected %s, got %s", hex.EncodeToString(digest[:]), txID)
+	}
+
+	// 2. Sign txID
+	signature, err := strategy.Sign(networkConfig, digest[:], "0x"+txID)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign with %s: %w", strategy.Name(), err)
+	}
+	trigger.Transaction["signature"] = []string{hex.EncodeToString(signature)}
+
+	// 3. Broadcast
+	var broadcast tronBroadcastResponse
+	if err := t.post(endpoint+"/wallet/broadcasttransaction", trigger.Transaction, &broadcast); err != nil {
+		return "", fmt.Errorf("broadcasttransaction failed: %w", err)
+	}
+	if !broadcast.Result {
+		return "", fmt.Errorf("broadcast rejected: code=%s, message=%s", broadcast.Code, decodeTronMessage(broadcast.Message))
+	}
+
+	log.Printf("✅ [TronTx] Transaction broadcast: txID=%s", txID)
+	return txID, nil
+}
+
+// keyMgmtSigner returns the network's signing address with its private key or KMS strategy
+func (t *TronTransactionService) keyMgmtSigner(networkConfig *config.NetworkConfig) (string, SigningStrategy, error) {
+	strategy, err := t.signingStrategy(networkConfig)
+	if err != nil {
+		return "", nil, err
+	}
+	signingAddress, err := t.keyMgmtService.GetSigningAddress(networkConfig)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get signing address: %w", err)
+	}
+	return signingAddress, strategy, nil
+}
+
+// signingStrategy selects private key or KMS signing (same priority as the EVM path)
+func (t *TronTransactionService) signingStrategy(networkConfig *config.NetworkConfig) (SigningStrategy, error) {
+	if networkConfig.UsePrivateKey && networkConfig.PrivateKey != "" && networkConfig.PrivateKey != "test_private_key_placeholder" {
+		return &PrivateKeySigningStrategy{keyMgmt: t.keyMgmtService}, nil
+	}
+	if t.keyMgmtService.IsKMSEnabled(networkConfig) && networkConfig.KMSKeyAlias != "" {
+		return &KMSSigningStrategy{keyMgmt: t.keyMgmtService}, nil
+	}
+	if networkConfig.PrivateKey != "" && networkConfig.PrivateKey != "test_private_key_placeholder" {
+		return &PrivateKeySigningStrategy{keyMgmt: t.keyMgmtService}, nil
+	}
+	return nil, fmt.Errorf("no signing method configured for TRON chainID %d", networkConfig.ChainID)
+}
+
+// post sends a JSON POST request to the TRON HTTP API
+func (t *TronTransactionService) post(url string, body interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("TRON API error: %d %s: %s", resp.StatusCode, resp.Status, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// toTronBase58 converts an EVM hex address to TRON Base58, TRON addresses are returned as-is
+func toTronBase58(address string) (string, error) {
+	if utils.IsTronAddress(address) {
+		return address, nil
+	}
+	return utils.EvmToTronAddress(address)
+}
+
+// decodeTronMessage decodes the hex encoded error message returned by the TRON API
+func decodeTronMessage(message string) string {
+	if decoded, err := hex.DecodeString(message); err == nil {
+		return string(decoded)
+	}
+	return message
+}
+
+// chainTransactionServiceFor returns the transaction service for the chain a transaction is submitted to
+func chainTransactionServiceFor(chainID uint32, evmService *BlockchainTransactionService, tronService *TronTransactionService) ChainTransactionService {
+	if clients.IsTronChain(chainID) && tronService != nil {
+		return tronService
+	}
+	return evmService
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gorm.io/gorm"
+)
+
+const tronTestContract = "0x00000000000000000000000000000000000000c1"
+
+// tronTestStrategy signs with kmsTestKey like the private key strategy would
+type tronTestStrategy struct{}
+
+func (tronTestStrategy) Sign(networkConfig *config.NetworkConfig, txHash []byte, txHashHex string) ([]byte, error) {
+	return crypto.Sign(txHash, kmsTestKey)
+}
+
+func (tronTestStrategy) Name() string { return "test" }
+
+// stubTronAPI a TRON HTTP API that builds a fixed transaction and records what was triggered and broadcast
+type stubTronAPI struct {
+	mu         sync.Mutex
+	rawData    []byte
+	triggers   []map[string]interface{}
+	broadcasts []map[string]interface{}
+	rejectWith string // hex message of a rejected broadcast, accepted when empty
+}
+
+func (s *stubTronAPI) txID() string {
+	digest := sha256.Sum256(s.rawData)
+	return hex.EncodeToString(digest[:])
+}
+
+func newStubTronAPI(t *testing.T) (*stubTronAPI, string) {
+	t.Helper()
+	api := &stubTronAPI{rawData: []byte("tron raw transaction")}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var response interface{}
+		switch r.URL.Path {
+		case "/wallet/triggersmartcontract":
+			api.triggers = append(api.triggers, body)
+			response = map[string]interface{}{
+				"result":      map[string]interface{}{"result": true},
+				"transaction": map[string]interface{}{"txID": api.txID(), "raw_data_hex": hex.EncodeToString(api.rawData)},
+			}
+		case "/wallet/broadcasttransaction":
+			api.broadcasts = append(api.broadcasts, body)
+			if api.rejectWith != "" {
+				response = map[string]interface{}{"result": false, "code": "CONTRACT_VALIDATE_ERROR", "message": api.rejectWith}
+			} else {
+				response = map[string]interface{}{"result": true, "txid": api.txID()}
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return api, server.URL
+}
+
+// useTronSubmission points the TRON network (and the ZKPay contract lookup) at the stub API, with BSC as management
+// chain and both commitments and withdraws routed to the deposit / beneficiary chain
+func useTronSubmission(t *testing.T, endpoint string) *gorm.DB {
+	t.Helper()
+	database := dbtest.Open(t)
+	previousDB, previousConfig := db.DB, config.AppConfig
+	t.Cleanup(func() { db.DB, config.AppConfig = previousDB, previousConfig })
+
+	db.DB = database
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{
+		ManagementChainID:     714,
+		CommitmentSubmitChain: config.SubmitChainDeposit,
+		WithdrawSubmitChain:   config.SubmitChainBeneficiary,
+		Networks: map[string]config.NetworkConfig{
+			"bsc":  {ChainID: 714, Enabled: true},
+			"tron": {ChainID: TronChainID, Enabled: true, RPCEndpoints: []string{endpoint + "/"}, ZKPayContract: tronTestContract},
+		},
+	}}
+	return database
+}
+
+func newTestTronService() *TronTransactionService {
+	service := NewTronTransactionService(nil, NewBlockchainTransactionService(nil))
+	signer := crypto.PubkeyToAddress(kmsTestKey.PublicKey).Hex()
+	service.signer = func(networkConfig *config.NetworkConfig) (string, SigningStrategy, error) {
+		return signer, tronTestStrategy{}, nil
+	}
+	return service
+}
+
+// checkTronBroadcast asserts one triggerSmartContract from the signer to the ZKPay contract with callData,
+// broadcast with a signature of its txID by the signer
+func checkTronBroadcast(t *testing.T, api *stubTronAPI, callData []byte) {
+	t.Helper()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.triggers) != 1 || len(api.broadcasts) != 1 {
+		t.Fatalf("%d triggers and %d broadcasts, want 1 each", len(api.triggers), len(api.broadcasts))
+	}
+
+	signer := crypto.PubkeyToAddress(kmsTestKey.PublicKey)
+	wantOwner, _ := utils.EvmToTronAddress(signer.Hex())
+	wantContract, _ := utils.EvmToTronAddress(tronTestContract)
+	trigger := api.triggers[0]
+	if trigger["owner_address"] != wantOwner || trigger["contract_address"] != wantContract || trigger["visible"] != true {
+		t.Errorf("trigger from %v to %v (visible %v), want %s to %s in Base58", trigger["owner_address"],
+			trigger["contract_address"], trigger["visible"], wantOwner, wantContract)
+	}
+	if trigger["data"] != hex.EncodeToString(callData) {
+		t.Errorf("trigger data = %v, want the ZKPay call data", trigger["data"])
+	}
+
+	broadcast := api.broadcasts[0]
+	signatures, _ := broadcast["signature"].([]interface{})
+	if broadcast["txID"] != api.txID() || len(signatures) != 1 {
+		t.Fatalf("broadcast %v, want transaction %s with one signature", broadcast, api.txID())
+	}
+	signature, err := hex.DecodeString(signatures[0].(string))
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	digest := sha256.Sum256(api.rawData)
+	pubKey, err := crypto.SigToPub(digest[:], signature)
+	if err != nil || crypto.PubkeyToAddress(*pubKey) != signer {
+		t.Errorf("broadcast signature does not recover to the signer %s over the txID", signer.Hex())
+	}
+}
+
+func tronTestWithdrawRequest() *WithdrawRequest {
+	return &WithdrawRequest{
+		ChainID:       60,
+		SubmitChainID: TronChainID,
+		NullifierHash: "0x" + strings.Repeat("11", 32),
+		Recipient:     "0x" + strings.Repeat("00", 12) + strings.Repeat("bb", 20),
+		Amount:        "100",
+		SP1Proof:      "0x01",
+		PublicValues:  "0x02",
+		TokenKey:      "USDT",
+		CheckbookID:   "cb-tron",
+		CheckID:       "cb-tron-1",
+	}
+}
+
+func TestTronSubmitWithdrawSignsAndBroadcasts(t *testing.T) {
+	api, endpoint := newStubTronAPI(t)
+	useTronSubmission(t, endpoint)
+	service := newTestTronService()
+	req := tronTestWithdrawRequest()
+
+	response, err := service.SubmitWithdraw(req)
+	if err != nil {
+		t.Fatalf("SubmitWithdraw: %v", err)
+	}
+	if response.TxHash != api.txID() {
+		t.Errorf("TxHash = %s, want the txID %s", response.TxHash, api.txID())
+	}
+
+	networkConfig, _ := config.GetNetworkConfigByChainID(TronChainID)
+	callData, err := service.evmService.buildWithdrawCallData(networkConfig, req)
+	if err != nil {
+		t.Fatalf("buildWithdrawCallData: %v", err)
+	}
+	checkTronBroadcast(t, api, callData)
+}
+
+func TestTronSubmitCommitmentSignsAndBroadcasts(t *testing.T) {
+	api, endpoint := newStubTronAPI(t)
+	database := useTronSubmission(t, endpoint)
+	if err := database.Create(&models.Checkbook{ID: "cb-tron", SLIP44ChainID: TronChainID, PublicValues: "0x03"}).Error; err != nil {
+		t.Fatalf("create checkbook: %v", err)
+	}
+	service := newTestTronService()
+	req := &CommitmentRequest{ChainID: TronChainID, CheckbookID: "cb-tron", SP1Proof: "0x04", Commitment: "0x05"}
+
+	response, err := service.SubmitCommitment(req)
+	if err != nil {
+		t.Fatalf("SubmitCommitment: %v", err)
+	}
+	if response.TxHash != api.txID() {
+		t.Errorf("TxHash = %s, want the txID %s", response.TxHash, api.txID())
+	}
+
+	networkConfig, _ := config.GetNetworkConfigByChainID(TronChainID)
+	callData, err := service.evmService.buildCommitmentCallData(networkConfig, req)
+	if err != nil {
+		t.Fatalf("buildCommitmentCallData: %v", err)
+	}
+	checkTronBroadcast(t, api, callData)
+}
+
+func TestTronRejectedBroadcastReturnsDecodedMessage(t *testing.T) {
+	api, endpoint := newStubTronAPI(t)
+	api.rejectWith = hex.EncodeToString([]byte("REVERT opcode executed"))
+	useTronSubmission(t, endpoint)
+
+	_, err := newTestTronService().SubmitWithdraw(tronTestWithdrawRequest())
+	if err == nil || !strings.Contains(err.Error(), "REVERT opcode executed") {
+		t.Errorf("SubmitWithdraw = %v, want the decoded broadcast rejection", err)
+	}
+}
+
+func TestChainTransactionServiceForRoutesByChainType(t *testing.T) {
+	evm := NewBlockchainTransactionService(nil)
+	tron := newTestTronService()
+
+	if got := chainTransactionServiceFor(TronChainID, evm, tron); got != tron {
+		t.Errorf("service for TRON = %T, want the TRON service", got)
+	}
+	if got := chainTransactionServiceFor(714, evm, tron); got != evm {
+		t.Errorf("service for BSC = %T, want the EVM service", got)
+	}
+	if got := chainTransactionServiceFor(TronChainID, evm, nil); got != evm {
+		t.Errorf("service for TRON without a TRON service = %T, want the EVM service", got)
+	}
+}
+
+func TestExecuteWithdrawToTronSkipsEVMQuickCheck(t *testing.T) {
+	api, endpoint := newStubTronAPI(t)
+	database := useTronSubmission(t, endpoint)
+
+	store := newFakeStore()
+	allocationIDs := store.addIdleAllocations("cb-tron", "0x00000000000000000000000000000000000000aa", "100")
+	allocationJSON, _ := json.Marshal(allocationIDs)
+	store.requests["wr-tron"] = &models.WithdrawRequest{
+		ID:                  "wr-tron",
+		WithdrawNullifier:   "0x" + strings.Repeat("22", 32),
+		AllocationIDs:       string(allocationJSON),
+		Amount:              "100",
+		Recipient:           models.UniversalAddress{SLIP44ChainID: TronChainID, Data: "0x00000000000000000000000000000000000000bb"},
+		TargetSLIP44ChainID: TronChainID,
+		Status:              string(models.WithdrawStatusProofGenerated),
+		ProofStatus:         models.ProofStatusCompleted,
+		Proof:               "0x01",
+		PublicValues:        "0x02",
+		ExecuteStatus:       models.ExecuteStatusPending,
+		Version:             1,
+	}
+
+	var managementCalls, tronCalls int
+	tron := newTestTronService()
+	tron.evmService.setClient(714, newCountingReceiptRPC(t, &managementCalls), "management")
+	tron.evmService.setClient(TronChainID, newCountingReceiptRPC(t, &tronCalls), "tron-jsonrpc")
+
+	service := newFakeWithdrawService(store)
+	service.blockchainService = tron.evmService
+	service.tronService = tron
+	service.pollingService = NewUnifiedPollingService(database, nil, nil)
+	service.executeSimulateFirst = true
+	service.quickCheckDelays = []time.Duration{time.Millisecond}
+
+	if err := service.ExecuteWithdraw(context.Background(), "wr-tron"); err != nil {
+		t.Fatalf("ExecuteWithdraw: %v", err)
+	}
+	if len(api.broadcasts) != 1 {
+		t.Fatalf("%d TRON broadcasts, want 1", len(api.broadcasts))
+	}
+	if managementCalls != 0 || tronCalls != 0 {
+		t.Errorf("%d management and %d TRON EVM RPC calls, want none (no simulation or EVM receipt check)", managementCalls, tronCalls)
+	}
+
+	var task models.PollingTask
+	if err := database.Where("entity_id = ? AND task_type = ?", "wr-tron", models.PollingWithdrawExecute).First(&task).Error; err != nil {
+		t.Fatalf("load polling task: %v", err)
+	}
+	if task.ChainID != TronChainID || task.TxHash != api.txID() {
+		t.Errorf("polling task on chain %d for %s, want chain %d for %s", task.ChainID, task.TxHash, TronChainID, api.txID())
+	}
+	if request := store.request("wr-tron"); request.ExecuteStatus != models.ExecuteStatusSubmitted || request.ExecuteTxHash != api.txID() {
+		t.Errorf("execute %s with tx %s, want submitted with %s", request.ExecuteStatus, request.ExecuteTxHash, api.txID())
+	}
+}
+
+func TestCommitmentToTronDepositChainUsesTronService(t *testing.T) {
+	api, endpoint := newStubTronAPI(t)
+	database := useTronSubmission(t, endpoint)
+	checkbook := models.Checkbook{ID: "cb-tron", SLIP44ChainID: TronChainID, PublicValues: "0x03"}
+	if err := database.Create(&checkbook).Error; err != nil {
+		t.Fatalf("create checkbook: %v", err)
+	}
+	store := newFakeStore()
+	store.checkbooks["cb-tron"] = &checkbook
+
+	service := NewCheckbookService(&fakeCheckbookRepo{store: store}, database, nil, nil, nil, NewBlockchainTransactionService(nil))
+	service.SetTronTransactionService(newTestTronService())
+
+	txHash, submitChainID, err := service.submitCommitmentToChain("cb-tron", &ProofResult{Proof: "0x04", CommitmentHash: "0x05"})
+	if err != nil {
+		t.Fatalf("submitCommitmentToChain: %v", err)
+	}
+	if txHash != api.txID() || submitChainID != TronChainID {
+		t.Errorf("submitted %s on chain %d, want %s on TRON", txHash, submitChainID, api.txID())
+	}
+	if len(api.broadcasts) != 1 {
+		t.Errorf("%d TRON broadcasts, want 1", len(api.broadcasts))
+	}
+}
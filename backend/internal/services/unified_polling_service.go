@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go-backend/internal/clients"
 	"go-backend/internal/utils"
@@ -9,10 +11,14 @@ import (
 	"time"
 
 	"go-backend/internal/models"
+	"go-backend/internal/repository"
 
 	"gorm.io/gorm"
 )
 
+// errPollingUpdateSkipped returned from a Modify callback when the polled entity no longer needs the update
+var errPollingUpdateSkipped = errors.New("polling update no longer applicable")
+
 // unified polling service
 type UnifiedPollingService struct {
 	db            *gorm.DB
@@ -24,11 +30,12 @@ type UnifiedPollingService struct {
 	mutex         sync.RWMutex
 	batchSize     int           // batch processing task count
 	pollInterval  time.Duration // main polling interval
+	pool          *pollingPool  // bounded worker pool executing the tasks
 }
 
 // Createunified polling service
 func NewUnifiedPollingService(db *gorm.DB, pushService *WebSocketPushService, scannerClient *clients.BlockchainScannerClient) *UnifiedPollingService {
-	return &UnifiedPollingService{
+	s := &UnifiedPollingService{
 		db:            db,
 		blockchains:   make(map[uint32]models.BlockchainClientInterface),
 		pushService:   pushService,
@@ -37,6 +44,8 @@ func NewUnifiedPollingService(db *gorm.DB, pushService *WebSocketPushService, sc
 		batchSize:     10,
 		pollInterval:  5 * time.Second,
 	}
+	s.pool = newPollingPoolFromConfig(s.executePollingTask)
+	return s
 }
 
 // blockchainclient
@@ -171,8 +180,18 @@ func (s *UnifiedPollingService) pollTaskLoop() {
 }
 
 // processpolling
+// Ready tasks are handed to the worker pool; only as many are fetched as the pool can take, and task types
+// already at their cap are skipped so they stay pending in the database.
 func (s *UnifiedPollingService) processPendingTasks() {
-	tasks := s.getReadyTasks(s.batchSize)
+	limit := s.pool.Capacity()
+	if limit > s.batchSize {
+		limit = s.batchSize
+	}
+	if limit <= 0 {
+		return
+	}
+
+	tasks := s.getReadyTasks(limit, s.pool.SaturatedTypes())
 	if len(tasks) == 0 {
 		return
 	}
@@ -182,22 +201,20 @@ func (s *UnifiedPollingService) processPendingTasks() {
 		log.Printf("📋 Processing %d pending polling tasks", len(tasks))
 	}
 
-	var wg sync.WaitGroup
 	for _, task := range tasks {
-		wg.Add(1)
-		go func(t *models.PollingTask) {
-			defer wg.Done()
-			s.executePollingTask(t)
-		}(task)
+		s.pool.Submit(task)
 	}
-	wg.Wait()
 }
 
 // Get
-func (s *UnifiedPollingService) getReadyTasks(limit int) []*models.PollingTask {
+func (s *UnifiedPollingService) getReadyTasks(limit int, excludeTypes []models.PollingTaskType) []*models.PollingTask {
 	var tasks []*models.PollingTask
 
-	err := s.db.Where("status = ? AND next_poll_at <= ?", models.PollingTaskStatusPending, time.Now()).
+	query := s.db.Where("status = ? AND next_poll_at <= ?", models.PollingTaskStatusPending, time.Now())
+	if len(excludeTypes) > 0 {
+		query = query.Where("task_type NOT IN ?", excludeTypes)
+	}
+	err := query.
 		Order("next_poll_at ASC").
 		Limit(limit).
 		Find(&tasks).Error
@@ -458,18 +475,46 @@ func (s *UnifiedPollingService) pollWithdrawExecute(task *models.PollingTask) (b
 		return false, nil // Transaction not confirmed yet, continue polling
 	}
 
-	// Transaction is confirmed, update status
+	// Transaction is confirmed, record its gas cost and update status
+	s.recordWithdrawExecuteGasCost(task.EntityID, txStatus)
 	if !txStatus.Success {
 		// Transaction failed
 		s.updateWithdrawRequestExecuteStatus(task.EntityID, string(models.ExecuteStatusVerifyFailed), task.TxHash, txStatus.BlockNumber, "Transaction reverted on-chain")
 		return true, nil // Polling completed (Failed)
 	}
 
-	// Transaction succeeded
-	s.updateWithdrawRequestExecuteStatus(task.EntityID, string(models.ExecuteStatusSuccess), task.TxHash, txStatus.BlockNumber, "")
+	// Transaction succeeded, wait for the confirmation depth before moving to the target status (success)
+	required := requiredConfirmations(int(task.ChainID))
+	currentBlock, err := client.GetBlockNumber()
+	if err != nil {
+		return false, fmt.Errorf("failed to get current block number: %w", err)
+	}
+	if !hasConfirmations(currentBlock, txStatus.BlockNumber, required) {
+		log.Printf("⏳ [Polling] Withdraw request %s tx %s in block %d, current block %d, waiting for %d confirmation(s)",
+			task.EntityID, task.TxHash, txStatus.BlockNumber, currentBlock, required)
+		return false, nil // Keep submitted, continue polling
+	}
+
+	s.updateWithdrawRequestExecuteStatus(task.EntityID, task.TargetStatus, task.TxHash, txStatus.BlockNumber, "")
 	return true, nil // Polling completed (Success)
 }
 
+// recordWithdrawExecuteGasCost persists the receipt gas cost reported by the client (skipped when not reported)
+func (s *UnifiedPollingService) recordWithdrawExecuteGasCost(requestID string, txStatus *models.TransactionStatus) {
+	if txStatus.GasUsed == 0 {
+		return
+	}
+	updates := map[string]interface{}{
+		"execute_gas_used": txStatus.GasUsed,
+	}
+	if txStatus.EffectiveGasPrice != "" {
+		updates["execute_gas_price"] = txStatus.EffectiveGasPrice
+	}
+	if err := s.db.Model(&models.WithdrawRequest{}).Where("id = ?", requestID).Updates(updates).Error; err != nil {
+		log.Printf("⚠️ [Polling] Failed to record gas cost for withdraw request %s: %v", requestID, err)
+	}
+}
+
 // pollingwithdrawcompleted
 func (s *UnifiedPollingService) pollWithdrawCrossChain(task *models.PollingTask) (bool, error) {
 	// Checkwithdrawwhethertargetcompleted
@@ -584,15 +629,32 @@ func (s *UnifiedPollingService) updateCheckbookStatus(checkbookID, newStatus str
 	}
 
 	oldStatus := string(checkbook.Status)
-	checkbook.Status = models.CheckbookStatus(newStatus)
 
-	err = s.db.Save(&checkbook).Error
+	// Conditional on the checkbook version: a concurrent writer makes us reload and retry instead of overwriting it
+	for attempt := 1; ; attempt++ {
+		err = repository.UpdateIfVersion(s.db, &models.Checkbook{}, checkbook.ID, checkbook.Version, map[string]interface{}{
+			"status":     models.CheckbookStatus(newStatus),
+			"updated_at": time.Now(),
+		})
+		if errors.Is(err, repository.ErrVersionConflict) && attempt < repository.MaxVersionConflictRetries {
+			if reloadErr := s.db.First(&checkbook, "id = ?", checkbookID).Error; reloadErr != nil {
+				log.Printf("❌ Failed to reload checkbook %s: %v", checkbookID, reloadErr)
+				return
+			}
+			oldStatus = string(checkbook.Status)
+			continue
+		}
+		break
+	}
 	if err != nil {
 		log.Printf("❌ Failed to update checkbook status: %v", err)
 		return
 	}
+	checkbook.Status = models.CheckbookStatus(newStatus)
+	checkbook.Version++
 
 	log.Printf("✅ Updated checkbook %s status: %s → %s", checkbookID, oldStatus, newStatus)
+	s.recordStatusTransition(models.StatusTransitionEntityCheckbook, checkbookID, oldStatus, newStatus)
 
 	// pushstatusUpdate (ifpushservice)
 	if s.pushService != nil {
@@ -638,6 +700,7 @@ func (s *UnifiedPollingService) updateCheckStatus(checkID, newStatus string) {
 	}
 
 	log.Printf("✅ Updated check %s status: %s → %s", checkID, oldStatus, newStatus)
+	s.recordStatusTransition(models.StatusTransitionEntityCheck, checkID, string(oldStatus), newStatus)
 
 	// pushstatusUpdate (ifpushservice)
 	if s.pushService != nil {
@@ -657,110 +720,86 @@ func (s *UnifiedPollingService) updateCheckStatus(checkID, newStatus string) {
 	}
 }
 
+// pollingTransitionTrigger is the trigger context of status transitions made by the polling service
+const pollingTransitionTrigger = "PollingService"
+
+// recordStatusTransition writes a status transition audit row (failures are logged, never returned)
+// Withdraw request transitions are recorded by the repository.
+func (s *UnifiedPollingService) recordStatusTransition(entityType, entityID, fromStatus, toStatus string) {
+	if fromStatus == toStatus {
+		return
+	}
+	transition := &models.StatusTransition{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		FromStatus:     fromStatus,
+		ToStatus:       toStatus,
+		TriggerContext: pollingTransitionTrigger,
+		CreatedAt:      time.Now(),
+	}
+	if err := repository.NewStatusTransitionRepository(s.db).Create(context.Background(), transition); err != nil {
+		log.Printf("⚠️ [Polling] Failed to record status transition for %s %s (%s → %s): %v", entityType, entityID, fromStatus, toStatus, err)
+	}
+}
+
 // UpdateWithdrawRequeststatus
 func (s *UnifiedPollingService) updateWithdrawRequestStatus(requestID, newStatus string) {
 	s.updateWithdrawRequestExecuteStatus(requestID, newStatus, "", 0, "")
 }
 
 // updateWithdrawRequestExecuteStatus updates withdraw request execute status with transaction details
-// Uses database transaction to prevent concurrent update conflicts
+// The update is conditional on the request's version (reloaded and re-checked on conflict), so it cannot
+// overwrite a concurrent update by the event processor.
 func (s *UnifiedPollingService) updateWithdrawRequestExecuteStatus(requestID, newStatus, txHash string, blockNumber uint64, errMsg string) {
-	// Use transaction to ensure atomicity and prevent deadlocks
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Printf("❌ Panic in updateWithdrawRequestExecuteStatus: %v", r)
+	var oldStatus string
+	ctx := repository.WithTransitionTrigger(context.Background(), pollingTransitionTrigger)
+	_, err := repository.NewWithdrawRequestRepository(s.db).Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		oldStatus = string(request.ExecuteStatus)
+
+		// Polling service only updates execute_status from submitting to success/failed
+		// If execute_status is not submitting, skip update (may have been updated by event listener)
+		if request.ExecuteStatus != models.ExecuteStatusSubmitted {
+			return errPollingUpdateSkipped
 		}
-	}()
 
-	var request models.WithdrawRequest
-	// Use FOR UPDATE to lock the row and prevent concurrent updates
-	err := tx.Set("gorm:query_option", "FOR UPDATE").
-		Where("id = ?", requestID).
-		First(&request).Error
-	if err != nil {
-		tx.Rollback()
-		log.Printf("❌ Failed to get withdraw request %s: %v", requestID, err)
-		return
-	}
-
-	oldStatus := string(request.ExecuteStatus)
-	
-	// Polling service only updates execute_status from submitting to success/failed
-	// If execute_status is not submitting, skip update (may have been updated by event listener)
-	if request.ExecuteStatus != models.ExecuteStatusSubmitted {
-		tx.Rollback()
-		log.Printf("⚠️ [Polling] Withdraw request %s execute_status=%s (not submitting), skipping update. Event listener may have already updated it.", requestID, oldStatus)
-		return
-	}
-	
-	// Check if already in final status - avoid unnecessary updates
-	if s.isFinalStatus("withdraw_request", oldStatus) {
-		tx.Rollback()
-		log.Printf("⚠️ [Polling] Withdraw request %s already in final status: %s, skipping update", requestID, oldStatus)
-		return
-	}
-
-	// Prepare updates
-	updates := map[string]interface{}{
-		"execute_status": models.ExecuteStatus(newStatus),
-	}
-
-	if txHash != "" {
-		updates["execute_tx_hash"] = txHash
-	}
-
-	if newStatus == string(models.ExecuteStatusSuccess) {
-		updates["executed_at"] = gorm.Expr("NOW()")
-		if blockNumber > 0 {
-			updates["execute_block_number"] = blockNumber
+		request.ExecuteStatus = models.ExecuteStatus(newStatus)
+		if txHash != "" {
+			request.ExecuteTxHash = txHash
 		}
-	} else if newStatus == string(models.ExecuteStatusVerifyFailed) || newStatus == string(models.ExecuteStatusSubmitFailed) {
-		if errMsg != "" {
-			updates["execute_error"] = errMsg
+		if newStatus == string(models.ExecuteStatusSuccess) {
+			now := time.Now()
+			request.ExecutedAt = &now
+			if blockNumber > 0 {
+				request.ExecuteBlockNumber = &blockNumber
+			}
+		} else if newStatus == string(models.ExecuteStatusVerifyFailed) || newStatus == string(models.ExecuteStatusSubmitFailed) {
+			if errMsg != "" {
+				request.ExecuteError = errMsg
+			}
 		}
-	}
 
-	// Update database within transaction
-	err = tx.Model(&request).Updates(updates).Error
-	if err != nil {
-		tx.Rollback()
-		log.Printf("❌ Failed to update withdraw request status: %v", err)
+		// Update main status if needed
+		request.UpdateMainStatus()
+		return nil
+	})
+	if errors.Is(err, errPollingUpdateSkipped) {
+		log.Printf("⚠️ [Polling] Withdraw request %s execute_status=%s (not submitting), skipping update. Event listener may have already updated it.", requestID, oldStatus)
 		return
 	}
-
-	// Reload to get updated status
-	err = tx.Where("id = ?", requestID).First(&request).Error
 	if err != nil {
-		tx.Rollback()
-		log.Printf("⚠️ Failed to reload withdraw request after update: %v", err)
+		log.Printf("❌ Failed to update withdraw request %s status: %v", requestID, err)
 		return
 	}
 
-	// Update main status if needed
-	request.UpdateMainStatus()
-	if err := tx.Save(&request).Error; err != nil {
-		tx.Rollback()
-		log.Printf("⚠️ Failed to update main status: %v", err)
-		return
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		log.Printf("❌ Failed to commit transaction: %v", err)
-		return
-	}
-
-	log.Printf("✅ Updated withdraw request %s execute_status: %s → %s (txHash=%s, blockNumber=%d)", 
+	log.Printf("✅ Updated withdraw request %s execute_status: %s → %s (txHash=%s, blockNumber=%d)",
 		requestID, oldStatus, newStatus, txHash, blockNumber)
 
-	// Push WebSocket update for WithdrawRequest status change (outside transaction)
+	// Push WebSocket update for WithdrawRequest status change
 	if s.pushService != nil {
 		// Reload withdraw request to get latest computed status
 		var updatedRequest models.WithdrawRequest
 		if err := s.db.Where("id = ?", requestID).First(&updatedRequest).Error; err == nil {
-			// Ensure main status is computed correctly (UpdateMainStatus was already called in transaction, but reload to be safe)
+			// Ensure main status is computed correctly (UpdateMainStatus was already applied when saving, but reload to be safe)
 			updatedRequest.UpdateMainStatus()
 			// Push WebSocket update (no need to save again, just push the update)
 			s.pushService.PushWithdrawRequestStatusUpdateDirect(&updatedRequest, oldStatus, "PollingService")
@@ -771,7 +810,7 @@ func (s *UnifiedPollingService) updateWithdrawRequestExecuteStatus(requestID, ne
 		}
 	}
 
-	// Cancel related polling tasks if final status (outside transaction)
+	// Cancel related polling tasks if final status
 	if s.isFinalStatus("withdraw_request", newStatus) {
 		s.cancelRelatedTasks("withdraw_request", requestID)
 	}
@@ -968,12 +1007,18 @@ func (s *UnifiedPollingService) GetPollingStatus() map[string]interface{} {
 		"active_tasks":      activeTaskCount,
 		"total_tasks":       totalTaskCount,
 		"batch_size":        s.batchSize,
+		"pool":              s.pool.Stats(),
 		"poll_interval":     s.pollInterval.String(),
 		"registered_chains": len(s.blockchains),
 		"recent_tasks":      recentTasks,
 	}
 }
 
+// GetPollingStats Get active/queued task counts of the worker pool, per task type
+func (s *UnifiedPollingService) GetPollingStats() PollingStats {
+	return s.pool.Stats()
+}
+
 // GetTaskStatus Getstatus
 func (s *UnifiedPollingService) GetTaskStatus(taskID string) (*models.PollingTask, error) {
 	var task models.PollingTask
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+)
+
+// stubPollingClient reports a successful receipt in receiptBlock while the chain head is at currentBlock
+type stubPollingClient struct {
+	models.BlockchainClientInterface
+	receiptBlock uint64
+	currentBlock uint64
+	gasUsed      uint64 // receipt gas, 0 when not reported
+	gasPrice     string
+}
+
+func (c *stubPollingClient) CheckTransactionStatus(txHash string) (*models.TransactionStatus, error) {
+	return &models.TransactionStatus{Exists: true, Confirmed: true, Success: true, BlockNumber: c.receiptBlock,
+		GasUsed: c.gasUsed, EffectiveGasPrice: c.gasPrice}, nil
+}
+
+func (c *stubPollingClient) GetBlockNumber() (uint64, error) {
+	return c.currentBlock, nil
+}
+
+func TestPollWithdrawExecuteWaitsForConfirmationDepth(t *testing.T) {
+	const chainID = 714
+	database := dbtest.Open(t)
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{Networks: map[string]config.NetworkConfig{
+		"bsc": {ChainID: chainID, Enabled: true, Confirmations: 3},
+	}}}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	request := models.WithdrawRequest{
+		ID:                "wr-confirmations",
+		WithdrawNullifier: "0x01",
+		Amount:            "100",
+		Status:            string(models.WithdrawStatusSubmitting),
+		ProofStatus:       models.ProofStatusCompleted,
+		ExecuteStatus:     models.ExecuteStatusSubmitted,
+		PayoutStatus:      models.PayoutStatusPending,
+		HookStatus:        models.HookStatusNotRequired,
+		Version:           1,
+	}
+	if err := database.Create(&request).Error; err != nil {
+		t.Fatalf("create withdraw request: %v", err)
+	}
+
+	client := &stubPollingClient{receiptBlock: 100, currentBlock: 102}
+	service := NewUnifiedPollingService(database, nil, nil)
+	service.blockchains[chainID] = client
+	task := &models.PollingTask{
+		EntityType:   "withdraw_request",
+		EntityID:     request.ID,
+		ChainID:      chainID,
+		TxHash:       "0xabc",
+		TargetStatus: string(models.ExecuteStatusSuccess),
+	}
+
+	// Receipt is 2 blocks deep, 3 required: keep polling, stay submitted
+	done, err := service.pollWithdrawExecute(task)
+	if err != nil || done {
+		t.Fatalf("poll at 2 confirmations = done %v, err %v; want to keep polling", done, err)
+	}
+	var stored models.WithdrawRequest
+	database.First(&stored, "id = ?", request.ID)
+	if stored.ExecuteStatus != models.ExecuteStatusSubmitted {
+		t.Fatalf("execute_status = %s before the confirmation depth, want submitted", stored.ExecuteStatus)
+	}
+
+	client.currentBlock = 103
+	if done, err := service.pollWithdrawExecute(task); err != nil || !done {
+		t.Fatalf("poll at 3 confirmations = done %v, err %v; want done", done, err)
+	}
+	database.First(&stored, "id = ?", request.ID)
+	if stored.ExecuteStatus != models.ExecuteStatusSuccess {
+		t.Errorf("execute_status = %s at the confirmation depth, want success", stored.ExecuteStatus)
+	}
+}
+
+func TestHasConfirmations(t *testing.T) {
+	tests := []struct {
+		current, receipt, required uint64
+		want                       bool
+	}{
+		{100, 100, 1, false}, // just mined
+		{101, 100, 1, true},
+		{102, 100, 3, false},
+		{103, 100, 3, true},
+		{99, 100, 1, false}, // node behind the receipt's block
+	}
+	for _, tt := range tests {
+		if got := hasConfirmations(tt.current, tt.receipt, tt.required); got != tt.want {
+			t.Errorf("hasConfirmations(current %d, receipt %d, required %d) = %v, want %v", tt.current, tt.receipt, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestPollWithdrawExecuteRecordsReceiptGasCost(t *testing.T) {
+	const chainID = 714
+	database := dbtest.Open(t)
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{Networks: map[string]config.NetworkConfig{
+		"bsc": {ChainID: chainID, Enabled: true, Confirmations: 1},
+	}}}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	tests := []struct {
+		name      string
+		gasUsed   uint64
+		gasPrice  string
+		wantPrice string
+	}{
+		{"receipt with gas", 84211, "3000000000", "3000000000"},
+		{"gas price not reported", 84211, "", ""},
+		{"gas not reported", 0, "", ""}, // nothing recorded
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.WithdrawRequest{
+				ID:                fmt.Sprintf("wr-gas-%d", i),
+				WithdrawNullifier: fmt.Sprintf("0x%02x", i+10),
+				Amount:            "100",
+				Status:            string(models.WithdrawStatusSubmitting),
+				ProofStatus:       models.ProofStatusCompleted,
+				ExecuteStatus:     models.ExecuteStatusSubmitted,
+				PayoutStatus:      models.PayoutStatusPending,
+				HookStatus:        models.HookStatusNotRequired,
+				Version:           1,
+			}
+			if err := database.Create(&request).Error; err != nil {
+				t.Fatalf("create withdraw request: %v", err)
+			}
+
+			service := NewUnifiedPollingService(database, nil, nil)
+			service.blockchains[chainID] = &stubPollingClient{receiptBlock: 100, currentBlock: 101, gasUsed: tt.gasUsed, gasPrice: tt.gasPrice}
+			task := &models.PollingTask{
+				EntityType:   "withdraw_request",
+				EntityID:     request.ID,
+				ChainID:      chainID,
+				TxHash:       "0xabc",
+				TargetStatus: string(models.ExecuteStatusSuccess),
+			}
+			if done, err := service.pollWithdrawExecute(task); err != nil || !done {
+				t.Fatalf("pollWithdrawExecute = done %v, err %v; want done", done, err)
+			}
+
+			var stored models.WithdrawRequest
+			if err := database.First(&stored, "id = ?", request.ID).Error; err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			switch {
+			case tt.gasUsed == 0 && stored.ExecuteGasUsed != nil:
+				t.Errorf("execute_gas_used = %d, want unset", *stored.ExecuteGasUsed)
+			case tt.gasUsed != 0 && (stored.ExecuteGasUsed == nil || *stored.ExecuteGasUsed != tt.gasUsed):
+				t.Errorf("execute_gas_used = %v, want %d", stored.ExecuteGasUsed, tt.gasUsed)
+			}
+			if stored.ExecuteGasPrice != tt.wantPrice {
+				t.Errorf("execute_gas_price = %q, want %q", stored.ExecuteGasPrice, tt.wantPrice)
+			}
+			if stored.ExecuteStatus != models.ExecuteStatusSuccess {
+				t.Errorf("execute_status = %s, want success", stored.ExecuteStatus)
+			}
+		})
+	}
+}
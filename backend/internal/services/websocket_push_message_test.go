@@ -0,0 +1,120 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func decodePushJSON(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid push message JSON %s: %v", data, err)
+	}
+	return decoded
+}
+
+func TestEncodePushMessageVersions(t *testing.T) {
+	message := newPushMessage(PushTypeWithdrawRequestStatusChanged, "60:0xowner", WithdrawalUpdateData{
+		Action:     "updated",
+		OldStatus:  string(models.WithdrawStatusCreated),
+		Withdrawal: models.WithdrawRequest{ID: "wr1", Status: string(models.WithdrawStatusProving)},
+	})
+
+	t.Run("current", func(t *testing.T) {
+		data, err := encodePushMessage(message, PushMessageVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := decodePushJSON(t, data)
+		if decoded["version"] != float64(PushMessageVersion) || decoded["type"] != PushTypeWithdrawRequestStatusChanged {
+			t.Errorf("version=%v type=%v, want %d %s", decoded["version"], decoded["type"], PushMessageVersion, PushTypeWithdrawRequestStatusChanged)
+		}
+		payload, ok := decoded["payload"].(map[string]interface{})
+		if !ok || payload["old_status"] != string(models.WithdrawStatusCreated) {
+			t.Errorf("payload = %v, want old_status created", decoded["payload"])
+		}
+		if _, ok := decoded["data"]; ok {
+			t.Errorf("version %d message carries the legacy data field", PushMessageVersion)
+		}
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		data, err := encodePushMessage(message, LegacyPushMessageVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := decodePushJSON(t, data)
+		if decoded["type"] != "withdrawal_update" {
+			t.Errorf("type = %v, want withdrawal_update", decoded["type"])
+		}
+		if _, ok := decoded["version"]; ok {
+			t.Errorf("legacy message carries a version")
+		}
+		if _, ok := decoded["payload"]; ok {
+			t.Errorf("legacy message carries payload")
+		}
+		if data, ok := decoded["data"].(map[string]interface{}); !ok || data["action"] != "updated" {
+			t.Errorf("data = %v, want the payload", decoded["data"])
+		}
+	})
+
+	t.Run("legacy checkbook type", func(t *testing.T) {
+		data, err := encodePushMessage(newPushMessage(PushTypeCheckbookStatusChanged, "60:0xowner", CheckbookUpdateData{}), LegacyPushMessageVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded := decodePushJSON(t, data); decoded["type"] != "checkbook_update" {
+			t.Errorf("type = %v, want checkbook_update", decoded["type"])
+		}
+	})
+}
+
+func TestPushVersionFromRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		header string
+		want   int
+	}{
+		{"not asked", "/ws", "", LegacyPushMessageVersion},
+		{"query", "/ws?push_version=2", "", PushMessageVersion},
+		{"header", "/ws", "2", PushMessageVersion},
+		{"query wins", "/ws?push_version=1", "2", LegacyPushMessageVersion},
+		{"unknown version", "/ws?push_version=9", "", LegacyPushMessageVersion},
+		{"garbage", "/ws?push_version=abc", "", LegacyPushMessageVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if tt.header != "" {
+				r.Header.Set("X-Push-Version", tt.header)
+			}
+			if got := PushVersionFromRequest(r); got != tt.want {
+				t.Errorf("PushVersionFromRequest = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleBroadcastEncodesPerConnectionVersion(t *testing.T) {
+	service := NewWebSocketPushService()
+	legacy := &Connection{ID: "legacy", UserAddress: "60:0xowner", Send: make(chan []byte, 4), PushVersion: LegacyPushMessageVersion}
+	current := &Connection{ID: "current", UserAddress: "60:0xowner", Send: make(chan []byte, 4), PushVersion: PushMessageVersion}
+	for _, conn := range []*Connection{legacy, current} {
+		service.handleRegister(conn)
+		<-conn.Send // connection_established
+	}
+
+	service.handleBroadcast(newPushMessage(PushTypeCheckbookStatusChanged, "60:0xowner", CheckbookUpdateData{Action: "updated"}))
+
+	if decoded := decodePushJSON(t, <-legacy.Send); decoded["type"] != "checkbook_update" || decoded["data"] == nil {
+		t.Errorf("legacy connection got %v", decoded)
+	}
+	if decoded := decodePushJSON(t, <-current.Send); decoded["type"] != PushTypeCheckbookStatusChanged || decoded["payload"] == nil {
+		t.Errorf("current connection got %v", decoded)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"go-backend/internal/config"
 	"go-backend/internal/models"
 
 	"github.com/gorilla/websocket"
@@ -30,11 +32,92 @@ type Connection struct {
 	UserAddress string          `json:"user_address"`
 	Conn        *websocket.Conn `json:"-"`
 	Send        chan []byte     `json:"-"`
-	LastPing    time.Time       `json:"last_ping"`
+	LastPing    time.Time       `json:"last_ping"`    // last pong (or client ping) received, see Touch
+	PushVersion int             `json:"push_version"` // push message envelope the client asked for, see PushVersionFromRequest
+
+	mu     sync.Mutex // guards LastPing and closed
+	closed bool       // Send has been closed
+}
+
+// Touch records that the client answered (protocol pong or application ping)
+func (c *Connection) Touch() {
+	c.mu.Lock()
+	c.LastPing = time.Now()
+	c.mu.Unlock()
+}
+
+// lastSeen time of the last pong
+func (c *Connection) lastSeen() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.LastPing
+}
+
+// trySend queues data without blocking; false if the queue is full or the connection was closed
+func (c *Connection) trySend(data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.Send == nil {
+		return false
+	}
+	select {
+	case c.Send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend closes Send once, so a connection closed mid-push never panics the broadcaster
+func (c *Connection) closeSend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.Send == nil {
+		return
+	}
+	c.closed = true
+	close(c.Send)
+}
+
+// PushMessageVersion version of the push message envelope, bumped whenever a message schema changes incompatibly
+// Version 1 (unversioned) carried the payload under "data" and used checkbook_update / withdrawal_update for status changes.
+const PushMessageVersion = 2
+
+// LegacyPushMessageVersion envelope sent to clients that do not ask for a version (deprecated, see legacyPushMessage)
+const LegacyPushMessageVersion = 1
+
+// PushVersionFromRequest the push envelope version a client asks for with ?push_version= (or the X-Push-Version header)
+// Clients that do not ask, or ask for an unknown version, get LegacyPushMessageVersion until it is removed.
+func PushVersionFromRequest(r *http.Request) int {
+	requested := r.URL.Query().Get("push_version")
+	if requested == "" {
+		requested = r.Header.Get("X-Push-Version")
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(requested))
+	if err != nil || version < LegacyPushMessageVersion || version > PushMessageVersion {
+		return LegacyPushMessageVersion
+	}
+	return version
 }
 
-// Push message base structure
+// Push message types of status changes
+const (
+	PushTypeCheckbookStatusChanged       = "checkbook.status_changed"
+	PushTypeWithdrawRequestStatusChanged = "withdraw_request.status_changed"
+)
+
+// PushMessage envelope of every push message: the payload is typed by Type and shaped by Version
 type PushMessage struct {
+	Version     int         `json:"version"`
+	Type        string      `json:"type"`
+	Timestamp   string      `json:"timestamp"`
+	MessageID   string      `json:"message_id"`
+	UserAddress string      `json:"user_address"`
+	Payload     interface{} `json:"payload"`
+}
+
+// legacyPushMessage version 1 envelope: payload under "data", no version, status changes under their old type names
+type legacyPushMessage struct {
 	Type        string      `json:"type"`
 	Timestamp   string      `json:"timestamp"`
 	MessageID   string      `json:"message_id"`
@@ -42,9 +125,46 @@ type PushMessage struct {
 	Data        interface{} `json:"data"`
 }
 
+// legacyPushTypes version 1 names of the message types renamed in version 2
+var legacyPushTypes = map[string]string{
+	PushTypeCheckbookStatusChanged:       "checkbook_update",
+	PushTypeWithdrawRequestStatusChanged: "withdrawal_update",
+}
+
+// encodePushMessage marshals a message in the envelope of the given version
+func encodePushMessage(message PushMessage, version int) ([]byte, error) {
+	if version >= PushMessageVersion {
+		return json.Marshal(message)
+	}
+	messageType := message.Type
+	if legacyType, ok := legacyPushTypes[messageType]; ok {
+		messageType = legacyType
+	}
+	return json.Marshal(legacyPushMessage{
+		Type:        messageType,
+		Timestamp:   message.Timestamp,
+		MessageID:   message.MessageID,
+		UserAddress: message.UserAddress,
+		Data:        message.Payload,
+	})
+}
+
+// newPushMessage wraps a payload in the current envelope version
+func newPushMessage(messageType string, userAddress string, payload interface{}) PushMessage {
+	return PushMessage{
+		Version:     PushMessageVersion,
+		Type:        messageType,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		MessageID:   generateMessageID(),
+		UserAddress: userAddress,
+		Payload:     payload,
+	}
+}
+
 // Checkbook update data (SDK compatible format)
 type CheckbookUpdateData struct {
 	Action      string            `json:"action"`                 // 'created' | 'updated' | 'deleted'
+	OldStatus   string            `json:"old_status,omitempty"`   // Status before the change ("" when created)
 	Checkbook   models.Checkbook  `json:"checkbook"`              // Complete Checkbook object
 	Previous    *models.Checkbook `json:"previous,omitempty"`     // Previous state (for updates)
 	UserMessage string            `json:"user_message,omitempty"` // User-friendly message
@@ -68,6 +188,7 @@ type AllocationUpdateData struct {
 // - WithdrawRequest = Withdrawal request (contains multiple Checks via AllocationIDs)
 type WithdrawalUpdateData struct {
 	Action      string                  `json:"action"`                 // 'created' | 'updated' | 'deleted'
+	OldStatus   string                  `json:"old_status,omitempty"`   // Status before the change ("" when created)
 	Withdrawal  models.WithdrawRequest  `json:"withdrawal"`             // Complete WithdrawRequest object (NOT Check)
 	Previous    *models.WithdrawRequest `json:"previous,omitempty"`     // Previous state (for updates)
 	UserMessage string                  `json:"user_message,omitempty"` // User-friendly message
@@ -115,10 +236,14 @@ type CheckStatusUpdateData struct {
 type WebSocketPushService struct {
 	connections map[string]*Connection   // key: connectionID
 	userConns   map[string][]*Connection // key: userAddress, value: connections
+	subscribed  map[string]string        // key: connectionID, value: subscribed universal address (defaults to JWT address)
 	hub         chan PushMessage
 	register    chan *Connection
 	unregister  chan *Connection
 	mutex       sync.RWMutex
+
+	pingInterval time.Duration // protocol-level ping interval (websocket.pingInterval)
+	pongWait     time.Duration // connections without a pong for this long are reaped (websocket.pongWait)
 }
 
 // User-friendly status message mapping
@@ -155,12 +280,16 @@ var checkStatusMessages = map[models.CheckStatus]struct {
 
 // createWebSocketPush service
 func NewWebSocketPushService() *WebSocketPushService {
+	heartbeat := config.GetWebSocketConfig()
 	service := &WebSocketPushService{
-		connections: make(map[string]*Connection),
-		userConns:   make(map[string][]*Connection),
-		hub:         make(chan PushMessage, 256),
-		register:    make(chan *Connection),
-		unregister:  make(chan *Connection),
+		connections:  make(map[string]*Connection),
+		userConns:    make(map[string][]*Connection),
+		subscribed:   make(map[string]string),
+		hub:          make(chan PushMessage, 256),
+		register:     make(chan *Connection),
+		unregister:   make(chan *Connection),
+		pingInterval: time.Duration(heartbeat.PingInterval) * time.Second,
+		pongWait:     time.Duration(heartbeat.PongWait) * time.Second,
 	}
 
 	go service.run()
@@ -169,8 +298,14 @@ func NewWebSocketPushService() *WebSocketPushService {
 
 // Push service
 func (s *WebSocketPushService) run() {
+	reapTicker := time.NewTicker(s.pingInterval)
+	defer reapTicker.Stop()
+
 	for {
 		select {
+		case <-reapTicker.C:
+			s.reapStaleConnections()
+
 		case conn := <-s.register:
 			s.handleRegister(conn)
 
@@ -210,6 +345,9 @@ func (s *WebSocketPushService) RegisterConnectionMapping(conn *Connection) {
 	}
 	s.userConns[conn.UserAddress] = append(s.userConns[conn.UserAddress], conn)
 
+	// Subscribe to the authenticated (JWT) address by default
+	s.subscribed[conn.ID] = normalizePushAddress(conn.UserAddress)
+
 	log.Printf("📱 WebSocket connection mapping registered: user=%s, connID=%s (connection managed externally)", conn.UserAddress, conn.ID)
 }
 
@@ -221,6 +359,7 @@ func (s *WebSocketPushService) UnregisterConnectionMapping(conn *Connection) {
 
 	// Remove from connection mapping
 	delete(s.connections, conn.ID)
+	delete(s.subscribed, conn.ID)
 
 	// Remove from user connection mapping
 	if userConns, exists := s.userConns[conn.UserAddress]; exists {
@@ -241,6 +380,41 @@ func (s *WebSocketPushService) UnregisterConnectionMapping(conn *Connection) {
 	log.Printf("📱 WebSocket connection mapping unregistered: user=%s, connID=%s", conn.UserAddress, conn.ID)
 }
 
+// Subscribe sets the universal address a connection receives status updates for
+// Only the connection's authenticated (JWT) address is accepted, so a client can never
+// subscribe to another account's checkbook/withdrawal updates.
+func (s *WebSocketPushService) Subscribe(connID string, universalAddress string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	conn, exists := s.connections[connID]
+	if !exists {
+		return ErrClientNotFound
+	}
+
+	address := normalizePushAddress(universalAddress)
+	if address != normalizePushAddress(conn.UserAddress) {
+		log.Printf("⚠️ [WebSocketpush] Rejected subscription: connID=%s, user=%s, requested=%s", connID, conn.UserAddress, universalAddress)
+		return ErrSubscriptionForbidden
+	}
+
+	s.subscribed[connID] = address
+	log.Printf("📱 [WebSocketpush] Connection subscribed: connID=%s, address=%s", connID, address)
+	return nil
+}
+
+// isSubscribed reports whether a connection should receive messages for the target address
+// Caller must hold s.mutex
+func (s *WebSocketPushService) isSubscribed(conn *Connection, userAddress string) bool {
+	subscribedAddress, exists := s.subscribed[conn.ID]
+	return exists && subscribedAddress == normalizePushAddress(userAddress)
+}
+
+// normalizePushAddress normalizes a universal address ("chainID:0x...") for comparison
+func normalizePushAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
 // Handle connection registration
 func (s *WebSocketPushService) handleRegister(conn *Connection) {
 	s.mutex.Lock()
@@ -254,21 +428,18 @@ func (s *WebSocketPushService) handleRegister(conn *Connection) {
 	}
 	s.userConns[conn.UserAddress] = append(s.userConns[conn.UserAddress], conn)
 
+	// Subscribe to the authenticated (JWT) address by default
+	s.subscribed[conn.ID] = normalizePushAddress(conn.UserAddress)
+
 	log.Printf("📱 WebSocket connection registered: user=%s, connID=%s", conn.UserAddress, conn.ID)
 
 	// Send connection confirmation message (only if connection has Send channel)
 	if conn.Send != nil {
-		confirmMsg := PushMessage{
-			Type:        "connection_established",
-			Timestamp:   time.Now().Format(time.RFC3339),
-			MessageID:   generateMessageID(),
-			UserAddress: conn.UserAddress,
-			Data: map[string]interface{}{
-				"user_address":  conn.UserAddress,
-				"connection_id": conn.ID,
-				"message":       "Real-time status connection established",
-			},
-		}
+		confirmMsg := newPushMessage("connection_established", conn.UserAddress, map[string]interface{}{
+			"user_address":  conn.UserAddress,
+			"connection_id": conn.ID,
+			"message":       "Real-time status connection established",
+		})
 
 		s.sendToConnection(conn, confirmMsg)
 	}
@@ -279,10 +450,22 @@ func (s *WebSocketPushService) handleUnregister(conn *Connection) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Remove from connection mapping
+	s.removeConnectionLocked(conn)
+
+	// Close connection
+	conn.closeSend()
+	if conn.Conn != nil {
+		conn.Conn.Close()
+	}
+
+	log.Printf("📱 WebSocket connection unregistered: user=%s, connID=%s", conn.UserAddress, conn.ID)
+}
+
+// removeConnectionLocked removes a connection from the registry (caller must hold s.mutex)
+func (s *WebSocketPushService) removeConnectionLocked(conn *Connection) {
 	delete(s.connections, conn.ID)
+	delete(s.subscribed, conn.ID)
 
-	// Remove from user connection mapping
 	if userConns, exists := s.userConns[conn.UserAddress]; exists {
 		for i, c := range userConns {
 			if c.ID == conn.ID {
@@ -296,16 +479,36 @@ func (s *WebSocketPushService) handleUnregister(conn *Connection) {
 			delete(s.userConns, conn.UserAddress)
 		}
 	}
+}
 
-	// Close connection
-	if conn.Send != nil {
-		close(conn.Send)
-	}
-	if conn.Conn != nil {
+// reapStaleConnections closes WebSocket connections that have not answered a ping within pongWait
+// Closing the socket also ends the read/write loops of externally managed connections.
+// SSE connections (no Conn) have no pongs and end with their request context instead.
+func (s *WebSocketPushService) reapStaleConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for _, conn := range s.connections {
+		if conn.Conn == nil || now.Sub(conn.lastSeen()) <= s.pongWait {
+			continue
+		}
+		s.removeConnectionLocked(conn)
+		conn.closeSend()
 		conn.Conn.Close()
+		log.Printf("🧹 [WebSocketpush] Reaped stale connection: user=%s, connID=%s (no pong for %s)",
+			conn.UserAddress, conn.ID, now.Sub(conn.lastSeen()).Round(time.Second))
 	}
+}
 
-	log.Printf("📱 WebSocket connection unregistered: user=%s, connID=%s", conn.UserAddress, conn.ID)
+// PingInterval interval between protocol-level pings, also used by externally managed connections
+func (s *WebSocketPushService) PingInterval() time.Duration {
+	return s.pingInterval
+}
+
+// PongWait read deadline for protocol-level pongs
+func (s *WebSocketPushService) PongWait() time.Duration {
+	return s.pongWait
 }
 
 // processmessage
@@ -320,8 +523,19 @@ func (s *WebSocketPushService) handleBroadcast(message PushMessage) {
 		return
 	}
 
-	// message
-	data, err := json.Marshal(message)
+	// message, encoded once per envelope version in use
+	encoded := make(map[int][]byte, 2)
+	encode := func(version int) ([]byte, error) {
+		if data, ok := encoded[version]; ok {
+			return data, nil
+		}
+		data, err := encodePushMessage(message, version)
+		if err == nil {
+			encoded[version] = data
+		}
+		return data, err
+	}
+	data, err := encode(PushMessageVersion)
 	if err != nil {
 		log.Printf("❌ Failed to marshal message: %v", err)
 		return
@@ -336,7 +550,7 @@ func (s *WebSocketPushService) handleBroadcast(message PushMessage) {
 	// messagedata
 	switch message.Type {
 	case "checkbook_status_update":
-		if checkbookData, ok := message.Data.(CheckbookStatusUpdateData); ok {
+		if checkbookData, ok := message.Payload.(CheckbookStatusUpdateData); ok {
 			log.Printf("🔔 [WebSocketpush] Checkbookdata: ID=%s, status=%s→%s, usermessage='%s', =%d%%",
 				checkbookData.CheckbookID, checkbookData.OldStatus, checkbookData.NewStatus,
 				checkbookData.UserMessage, checkbookData.Progress)
@@ -347,32 +561,32 @@ func (s *WebSocketPushService) handleBroadcast(message PushMessage) {
 				log.Printf("🔔 [WebSocketpush] data: %+v", checkbookData.ProofData)
 			}
 		}
-	case "checkbook_update":
+	case PushTypeCheckbookStatusChanged:
 		// SDK-compatible checkbook update format
-		if checkbookData, ok := message.Data.(CheckbookUpdateData); ok {
+		if checkbookData, ok := message.Payload.(CheckbookUpdateData); ok {
 			log.Printf("🔔 [WebSocketpush] CheckbookUpdate (SDK): ID=%s, Action=%s, Status=%s, Progress=%d%%",
 				checkbookData.Checkbook.ID, checkbookData.Action, checkbookData.Checkbook.Status, checkbookData.Progress)
 		} else {
-			log.Printf("🔔 [WebSocketpush] CheckbookUpdate (SDK) data: %+v", message.Data)
+			log.Printf("🔔 [WebSocketpush] CheckbookUpdate (SDK) data: %+v", message.Payload)
 		}
 	case "allocation_update":
 		// SDK-compatible allocation update format
-		if allocationData, ok := message.Data.(AllocationUpdateData); ok {
+		if allocationData, ok := message.Payload.(AllocationUpdateData); ok {
 			log.Printf("🔔 [WebSocketpush] AllocationUpdate (SDK): ID=%s, Action=%s, Status=%s",
 				allocationData.Allocation.ID, allocationData.Action, allocationData.Allocation.Status)
 		} else {
-			log.Printf("🔔 [WebSocketpush] AllocationUpdate (SDK) data: %+v", message.Data)
+			log.Printf("🔔 [WebSocketpush] AllocationUpdate (SDK) data: %+v", message.Payload)
 		}
-	case "withdrawal_update":
+	case PushTypeWithdrawRequestStatusChanged:
 		// SDK-compatible withdrawal update format
-		if withdrawalData, ok := message.Data.(WithdrawalUpdateData); ok {
+		if withdrawalData, ok := message.Payload.(WithdrawalUpdateData); ok {
 			log.Printf("🔔 [WebSocketpush] WithdrawalUpdate (SDK): ID=%s, Action=%s, Status=%s",
 				withdrawalData.Withdrawal.ID, withdrawalData.Action, withdrawalData.Withdrawal.Status)
 		} else {
-			log.Printf("🔔 [WebSocketpush] WithdrawalUpdate (SDK) data: %+v", message.Data)
+			log.Printf("🔔 [WebSocketpush] WithdrawalUpdate (SDK) data: %+v", message.Payload)
 		}
 	case "check_status_update":
-		if checkData, ok := message.Data.(CheckStatusUpdateData); ok {
+		if checkData, ok := message.Payload.(CheckStatusUpdateData); ok {
 			log.Printf("🔔 [WebSocketpush] Checkdata: ID=%s, CheckbookID=%s, status=%s→%s, usermessage='%s', =%d%%",
 				checkData.CheckID, checkData.CheckbookID, checkData.OldStatus, checkData.NewStatus,
 				checkData.UserMessage, checkData.Progress)
@@ -384,49 +598,55 @@ func (s *WebSocketPushService) handleBroadcast(message PushMessage) {
 			}
 		}
 	case "connection_established":
-		log.Printf("🔔 [WebSocketpush] connectionconfirmdata: %+v", message.Data)
+		log.Printf("🔔 [WebSocketpush] connectionconfirmdata: %+v", message.Payload)
 	case "status_sync":
-		log.Printf("🔔 [WebSocketpush] statusdata: %+v", message.Data)
+		log.Printf("🔔 [WebSocketpush] statusdata: %+v", message.Payload)
 	default:
-		log.Printf("🔔 [WebSocketpush] data: %+v", message.Data)
+		log.Printf("🔔 [WebSocketpush] data: %+v", message.Payload)
 	}
 
 	// JSONdata（）
 	log.Printf("🔔 [WebSocketpush] JSONdata: %s", string(data))
 	// ================================================
 
-	// userconnection
+	// userconnection (only connections subscribed to the target address)
 	successCount := 0
 	failedCount := 0
+	skippedCount := 0
 	for _, conn := range userConns {
-		select {
-		case conn.Send <- data:
-			// success
+		if !s.isSubscribed(conn, message.UserAddress) {
+			skippedCount++
+			continue
+		}
+		connData, err := encode(conn.PushVersion)
+		if err != nil {
+			log.Printf("❌ Failed to marshal message for push version %d: %v", conn.PushVersion, err)
+			failedCount++
+			continue
+		}
+		if conn.trySend(connData) {
 			successCount++
 			log.Printf("✅ [WebSocketpush] Message queued to connection: %s (user: %s)", conn.ID, message.UserAddress)
-		default:
+		} else {
 			// failed，connectionalready
 			failedCount++
 			log.Printf("⚠️ [WebSocketpush] Failed to send to connection: %s (channel full or closed)", conn.ID)
 		}
 	}
 
-	log.Printf("📤 [WebSocketpush] Message delivery summary: sent=%d, failed=%d, total=%d, user=%s, type=%s",
-		successCount, failedCount, len(userConns), message.UserAddress, message.Type)
+	log.Printf("📤 [WebSocketpush] Message delivery summary: sent=%d, failed=%d, skipped=%d, total=%d, user=%s, type=%s",
+		successCount, failedCount, skippedCount, len(userConns), message.UserAddress, message.Type)
 }
 
 // messageconnection
 func (s *WebSocketPushService) sendToConnection(conn *Connection, message PushMessage) {
-	data, err := json.Marshal(message)
+	data, err := encodePushMessage(message, conn.PushVersion)
 	if err != nil {
 		log.Printf("❌ Failed to marshal message: %v", err)
 		return
 	}
 
-	select {
-	case conn.Send <- data:
-		// success
-	default:
+	if !conn.trySend(data) {
 		log.Printf("⚠️ Failed to send to connection: %s", conn.ID)
 	}
 }
@@ -460,6 +680,7 @@ func (s *WebSocketPushService) HandleSSE(w http.ResponseWriter, r *http.Request,
 		Conn:        nil, // SSEneedWebSocketconnection
 		Send:        make(chan []byte, 256),
 		LastPing:    time.Now(),
+		PushVersion: PushVersionFromRequest(r),
 	}
 
 	log.Printf("📡 SSEconnection: user=%s, connID=%s", userAddress, connection.ID)
@@ -468,11 +689,10 @@ func (s *WebSocketPushService) HandleSSE(w http.ResponseWriter, r *http.Request,
 	s.register <- connection
 
 	// 🔥 connectionmessage（clientonopentrigger）
-	welcomeMsg := fmt.Sprintf(`{"type":"connection_established","timestamp":"%s","message_id":"%s","user_address":"%s"}`,
-		time.Now().Format(time.RFC3339),
-		generateMessageID(),
-		userAddress,
-	)
+	welcomeMsg, err := encodePushMessage(newPushMessage("connection_established", userAddress, nil), connection.PushVersion)
+	if err != nil {
+		log.Printf("❌ Failed to marshal message: %v", err)
+	}
 	if flusher, ok := w.(http.Flusher); ok {
 		fmt.Fprintf(w, "data: %s\n\n", welcomeMsg)
 		flusher.Flush()
@@ -666,6 +886,7 @@ func (s *WebSocketPushService) HandleWebSocket(w http.ResponseWriter, r *http.Re
 		Conn:        conn,
 		Send:        make(chan []byte, 256),
 		LastPing:    time.Now(),
+		PushVersion: PushVersionFromRequest(r),
 	}
 
 	// connection
@@ -678,7 +899,7 @@ func (s *WebSocketPushService) HandleWebSocket(w http.ResponseWriter, r *http.Re
 
 // processconnection
 func (s *WebSocketPushService) handleConnectionWrite(conn *Connection) {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := time.NewTicker(s.pingInterval)
 	defer func() {
 		ticker.Stop()
 		conn.Conn.Close()
@@ -715,10 +936,10 @@ func (s *WebSocketPushService) handleConnectionRead(conn *Connection) {
 	}()
 
 	conn.Conn.SetReadLimit(512)
-	conn.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	conn.Conn.SetReadDeadline(time.Now().Add(s.pongWait))
 	conn.Conn.SetPongHandler(func(string) error {
-		conn.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		conn.LastPing = time.Now()
+		conn.Conn.SetReadDeadline(time.Now().Add(s.pongWait))
+		conn.Touch()
 		return nil
 	})
 
@@ -751,13 +972,7 @@ func (s *WebSocketPushService) BroadcastCheckbookUpdateSDK(userAddress string, d
 		}
 	}
 
-	message := PushMessage{
-		Type:        "checkbook_update", // SDK expects "checkbook_update", not "checkbook_status_update"
-		Timestamp:   time.Now().Format(time.RFC3339),
-		MessageID:   generateMessageID(),
-		UserAddress: userAddress,
-		Data:        data,
-	}
+	message := newPushMessage(PushTypeCheckbookStatusChanged, userAddress, data)
 
 	s.hub <- message
 	log.Printf("✅ [WebSocket SDK] Checkbook update queued for delivery")
@@ -772,20 +987,14 @@ func (s *WebSocketPushService) BroadcastAllocationUpdateSDK(userAddress string,
 	log.Printf("🚀 [WebSocket SDK] Action: %s", data.Action)
 	log.Printf("🚀 [WebSocket SDK] Status: %s", data.Allocation.Status)
 
-	message := PushMessage{
-		Type:        "allocation_update",
-		Timestamp:   time.Now().Format(time.RFC3339),
-		MessageID:   generateMessageID(),
-		UserAddress: userAddress,
-		Data:        data,
-	}
+	message := newPushMessage("allocation_update", userAddress, data)
 
 	s.hub <- message
 	log.Printf("✅ [WebSocket SDK] Allocation update queued for delivery")
 }
 
 // BroadcastWithdrawalUpdateSDK sends SDK-compatible withdrawal update
-// When WithdrawRequest status changes, push withdrawal_update to WithdrawalsStore
+// When WithdrawRequest status changes, push withdraw_request.status_changed to WithdrawalsStore
 func (s *WebSocketPushService) BroadcastWithdrawalUpdateSDK(userAddress string, data WithdrawalUpdateData) {
 	log.Printf("🚀 [WebSocket SDK] Pushing Withdrawal update")
 	log.Printf("🚀 [WebSocket SDK] Target user: %s", userAddress)
@@ -804,13 +1013,7 @@ func (s *WebSocketPushService) BroadcastWithdrawalUpdateSDK(userAddress string,
 		}
 	}
 
-	message := PushMessage{
-		Type:        "withdrawal_update", // SDK expects "withdrawal_update", not "check_status_update"
-		Timestamp:   time.Now().Format(time.RFC3339),
-		MessageID:   generateMessageID(),
-		UserAddress: userAddress,
-		Data:        data,
-	}
+	message := newPushMessage(PushTypeWithdrawRequestStatusChanged, userAddress, data)
 
 	s.hub <- message
 	log.Printf("✅ [WebSocket SDK] Withdrawal update queued for delivery")
@@ -834,13 +1037,7 @@ func (s *WebSocketPushService) BroadcastCheckbookUpdate(userAddress string, data
 		log.Printf("🚀 [WebSocketpush] usermessage: %s (: %d%%)", data.UserMessage, data.Progress)
 	}
 
-	message := PushMessage{
-		Type:        "checkbook_status_update",
-		Timestamp:   time.Now().Format(time.RFC3339),
-		MessageID:   generateMessageID(),
-		UserAddress: userAddress,
-		Data:        data,
-	}
+	message := newPushMessage("checkbook_status_update", userAddress, data)
 
 	log.Printf("🚀 [WebSocketpush] messagealreadyhub，wait")
 	s.hub <- message
@@ -863,13 +1060,7 @@ func (s *WebSocketPushService) BroadcastCheckUpdate(userAddress string, data Che
 		log.Printf("🚀 [WebSocketpush] usermessage: %s (: %d%%)", data.UserMessage, data.Progress)
 	}
 
-	message := PushMessage{
-		Type:        "check_status_update",
-		Timestamp:   time.Now().Format(time.RFC3339),
-		MessageID:   generateMessageID(),
-		UserAddress: userAddress,
-		Data:        data,
-	}
+	message := newPushMessage("check_status_update", userAddress, data)
 
 	log.Printf("🚀 [WebSocketpush] messagealreadyhub，wait")
 	s.hub <- message
@@ -883,19 +1074,18 @@ func (s *WebSocketPushService) BroadcastStatusSync(userAddress string, checkbook
 		"sync_time":  time.Now().Format(time.RFC3339),
 	}
 
-	message := PushMessage{
-		Type:        "status_sync",
-		Timestamp:   time.Now().Format(time.RFC3339),
-		MessageID:   generateMessageID(),
-		UserAddress: userAddress,
-		Data:        syncData,
-	}
+	message := newPushMessage("status_sync", userAddress, syncData)
 
 	s.hub <- message
 }
 
 // getconnection
 func (s *WebSocketPushService) GetActiveConnections() int {
+	return s.ConnectionCount()
+}
+
+// ConnectionCount number of registered push connections (WebSocket and SSE), for monitoring
+func (s *WebSocketPushService) ConnectionCount() int {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 	return len(s.connections)
@@ -935,6 +1125,7 @@ func (s *WebSocketPushService) PushCheckbookStatusUpdate(db *gorm.DB, checkbookI
 	// Send SDK-compatible update
 	s.BroadcastCheckbookUpdateSDK(userAddressStr, CheckbookUpdateData{
 		Action:    action,
+		OldStatus: oldStatus,
 		Checkbook: checkbook,
 		Previous:  nil, // Could store previous state if needed
 	})
@@ -946,7 +1137,7 @@ func (s *WebSocketPushService) PushCheckbookStatusUpdate(db *gorm.DB, checkbookI
 
 // PushCheckStatusUpdate pushes SDK-compatible allocation update (queries check by ID)
 // NOTE: When Check (Allocation) status changes, we should push allocation_update to AllocationsStore
-// NOT withdrawal_update, because Check and WithdrawRequest are different entities
+// NOT withdraw_request.status_changed, because Check and WithdrawRequest are different entities
 func (s *WebSocketPushService) PushCheckStatusUpdate(db *gorm.DB, checkID string, oldStatus string, context string) error {
 	var check models.Check
 	if err := db.First(&check, "id = ?", checkID).Error; err != nil {
@@ -984,7 +1175,7 @@ func (s *WebSocketPushService) PushCheckStatusUpdate(db *gorm.DB, checkID string
 }
 
 // PushWithdrawRequestStatusUpdate pushes SDK-compatible withdrawal update (queries withdrawRequest by ID)
-// When WithdrawRequest status changes, push withdrawal_update to WithdrawalsStore
+// When WithdrawRequest status changes, push withdraw_request.status_changed to WithdrawalsStore
 func (s *WebSocketPushService) PushWithdrawRequestStatusUpdate(db *gorm.DB, withdrawRequestID string, oldStatus string, context string) error {
 	var withdrawRequest models.WithdrawRequest
 	// Use Unscoped() to ensure we get the latest data, and reload to get computed status
@@ -1015,9 +1206,11 @@ func (s *WebSocketPushService) PushWithdrawRequestStatusUpdate(db *gorm.DB, with
 	// Send SDK-compatible withdrawal update
 	s.BroadcastWithdrawalUpdateSDK(userAddressStr, WithdrawalUpdateData{
 		Action:     action,
+		OldStatus:  oldStatus,
 		Withdrawal: withdrawRequest, // Push WithdrawRequest
 		Previous:   nil,             // Could store previous state if needed
 	})
+	s.pushWithdrawalUpdateToBeneficiary(&withdrawRequest, userAddressStr, action, oldStatus)
 
 	log.Printf("📡 [%s] Pushed SDK withdrawal update: user=%s, withdrawRequest=%s, %s→%s",
 		context, userAddressStr, withdrawRequest.ID, oldStatus, withdrawRequest.Status)
@@ -1025,7 +1218,7 @@ func (s *WebSocketPushService) PushWithdrawRequestStatusUpdate(db *gorm.DB, with
 }
 
 // PushWithdrawRequestStatusUpdateDirect pushes SDK-compatible withdrawal update (with existing withdrawRequest object)
-// When WithdrawRequest status changes, push withdrawal_update to WithdrawalsStore
+// When WithdrawRequest status changes, push withdraw_request.status_changed to WithdrawalsStore
 func (s *WebSocketPushService) PushWithdrawRequestStatusUpdateDirect(withdrawRequest *models.WithdrawRequest, oldStatus string, context string) {
 	if withdrawRequest == nil {
 		log.Printf("⚠️ [%s] WithdrawRequest is nil, cannot push status update", context)
@@ -1044,14 +1237,33 @@ func (s *WebSocketPushService) PushWithdrawRequestStatusUpdateDirect(withdrawReq
 	// Send SDK-compatible withdrawal update
 	s.BroadcastWithdrawalUpdateSDK(userAddressStr, WithdrawalUpdateData{
 		Action:     action,
+		OldStatus:  oldStatus,
 		Withdrawal: *withdrawRequest, // Push WithdrawRequest
 		Previous:   nil,              // Could store previous state if needed
 	})
+	s.pushWithdrawalUpdateToBeneficiary(withdrawRequest, userAddressStr, action, oldStatus)
 
 	log.Printf("📡 [%s] Pushed SDK withdrawal update (direct): user=%s, withdrawRequest=%s, %s→%s",
 		context, userAddressStr, withdrawRequest.ID, oldStatus, withdrawRequest.Status)
 }
 
+// pushWithdrawalUpdateToBeneficiary also pushes the withdrawal update to the beneficiary (Recipient) if it differs from the owner
+func (s *WebSocketPushService) pushWithdrawalUpdateToBeneficiary(withdrawRequest *models.WithdrawRequest, ownerAddress string, action string, oldStatus string) {
+	if withdrawRequest.Recipient.Data == "" {
+		return
+	}
+	beneficiaryAddress := s.formatUniversalAddressForPush(withdrawRequest.Recipient.SLIP44ChainID, withdrawRequest.Recipient.Data)
+	if normalizePushAddress(beneficiaryAddress) == normalizePushAddress(ownerAddress) {
+		return
+	}
+	s.BroadcastWithdrawalUpdateSDK(beneficiaryAddress, WithdrawalUpdateData{
+		Action:     action,
+		OldStatus:  oldStatus,
+		Withdrawal: *withdrawRequest,
+		Previous:   nil,
+	})
+}
+
 // PushCheckbookStatusUpdateDirect pushes SDK-compatible checkbook update (with existing checkbook object)
 func (s *WebSocketPushService) PushCheckbookStatusUpdateDirect(checkbook *models.Checkbook, oldStatus string, context string) {
 	// Use formatUniversalAddressForPush to ensure address format matches JWT Universal Address format
@@ -1066,6 +1278,7 @@ func (s *WebSocketPushService) PushCheckbookStatusUpdateDirect(checkbook *models
 	// Send SDK-compatible update
 	s.BroadcastCheckbookUpdateSDK(userAddressStr, CheckbookUpdateData{
 		Action:    action,
+		OldStatus: oldStatus,
 		Checkbook: *checkbook,
 		Previous:  nil,
 	})
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialSilentClient returns the server side of a WebSocket whose client never reads, so it never answers a ping
+func dialSilentClient(t *testing.T) (server *websocket.Conn, client *websocket.Conn) {
+	t.Helper()
+	accepted := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		accepted <- conn
+	}))
+	t.Cleanup(httpServer.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return <-accepted, client
+}
+
+func TestReapStaleConnectionsClosesSilentClients(t *testing.T) {
+	service := newFakePushService()
+	service.pongWait = time.Minute
+
+	silentConn, silentClient := dialSilentClient(t)
+	silent := newFakeConnection(service, "conn-silent", "0xaa")
+	silent.Conn = silentConn
+	silent.LastPing = time.Now().Add(-2 * time.Minute) // last pong long ago
+
+	liveConn, _ := dialSilentClient(t)
+	live := newFakeConnection(service, "conn-live", "0xaa")
+	live.Conn = liveConn
+	live.Touch()
+
+	service.reapStaleConnections()
+
+	if _, ok := service.connections["conn-silent"]; ok {
+		t.Error("connection without a pong for longer than pongWait was not reaped")
+	}
+	if _, ok := service.connections["conn-live"]; !ok {
+		t.Error("connection that answered recently was reaped")
+	}
+	if conns := service.userConns["0xaa"]; len(conns) != 1 || conns[0] != live {
+		t.Errorf("user connections = %v, want only the live one", conns)
+	}
+	if _, open := <-silent.Send; open {
+		t.Error("reaped connection's send queue still open")
+	}
+	if silent.trySend([]byte("late push")) {
+		t.Error("push queued on a reaped connection")
+	}
+
+	// The socket itself is closed: the client sees the connection drop
+	silentClient.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := silentClient.ReadMessage()
+	if netErr, ok := err.(net.Error); err == nil || ok && netErr.Timeout() {
+		t.Errorf("client read after reap: %v, want the connection closed", err)
+	}
+}
@@ -275,8 +275,9 @@ func (m *WebSocketSubscriptionManager) SendMessageToClients(clientIDs []string,
 
 // Error types
 var (
-	ErrClientNotFound       = NewError("client not found")
-	ErrSubscriptionNotFound = NewError("subscription not found")
+	ErrClientNotFound        = NewError("client not found")
+	ErrSubscriptionNotFound  = NewError("subscription not found")
+	ErrSubscriptionForbidden = NewError("cannot subscribe to another address")
 )
 
 // Error helper
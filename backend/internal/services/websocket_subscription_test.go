@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+// newFakePushService a push service without the run loop; deliverQueued hands queued messages to handleBroadcast
+func newFakePushService() *WebSocketPushService {
+	return &WebSocketPushService{
+		connections: make(map[string]*Connection),
+		userConns:   make(map[string][]*Connection),
+		subscribed:  make(map[string]string),
+		hub:         make(chan PushMessage, 16),
+	}
+}
+
+func deliverQueued(s *WebSocketPushService) {
+	for {
+		select {
+		case message := <-s.hub:
+			s.handleBroadcast(message)
+		default:
+			return
+		}
+	}
+}
+
+func newFakeConnection(s *WebSocketPushService, id, userAddress string) *Connection {
+	conn := &Connection{ID: id, UserAddress: userAddress, Send: make(chan []byte, 16)}
+	s.RegisterConnectionMapping(conn)
+	return conn
+}
+
+func TestStatusPushesReachOnlyTheSubscribedConnection(t *testing.T) {
+	service := newFakePushService()
+	alice := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000aa")
+	bob := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000bb")
+	aliceConn := newFakeConnection(service, "conn-alice", alice)
+	bobConn := newFakeConnection(service, "conn-bob", bob)
+
+	service.PushCheckbookStatusUpdateDirect(&models.Checkbook{
+		ID:          "cb-alice",
+		Status:      models.CheckbookStatusWithCheckbook,
+		UserAddress: models.UniversalAddress{SLIP44ChainID: 60, Data: "0x00000000000000000000000000000000000000aa"},
+	}, string(models.CheckbookStatusPending), "test")
+	deliverQueued(service)
+
+	if got := len(aliceConn.Send); got != 1 {
+		t.Errorf("alice received %d checkbook pushes, want 1", got)
+	}
+	if got := len(bobConn.Send); got != 0 {
+		t.Errorf("bob received %d pushes for alice's checkbook, want 0", got)
+	}
+}
+
+func TestWithdrawalPushReachesOwnerAndBeneficiaryOnly(t *testing.T) {
+	service := newFakePushService()
+	alice := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000aa")
+	bob := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000bb")
+	carol := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000cc")
+	aliceConn := newFakeConnection(service, "conn-alice", alice)
+	bobConn := newFakeConnection(service, "conn-bob", bob)
+	carolConn := newFakeConnection(service, "conn-carol", carol)
+
+	service.PushWithdrawRequestStatusUpdateDirect(&models.WithdrawRequest{
+		ID:           "wr1",
+		Status:       string(models.WithdrawStatusCompleted),
+		OwnerAddress: models.UniversalAddress{SLIP44ChainID: 60, Data: "0x00000000000000000000000000000000000000aa"},
+		Recipient:    models.UniversalAddress{SLIP44ChainID: 60, Data: "0x00000000000000000000000000000000000000bb"},
+	}, string(models.WithdrawStatusPayoutProcessing), "test")
+	deliverQueued(service)
+
+	if len(aliceConn.Send) != 1 || len(bobConn.Send) != 1 {
+		t.Errorf("owner received %d, beneficiary %d pushes, want 1 each", len(aliceConn.Send), len(bobConn.Send))
+	}
+	if got := len(carolConn.Send); got != 0 {
+		t.Errorf("unrelated connection received %d pushes, want 0", got)
+	}
+}
+
+func TestSubscribeRejectsAnotherAccountsAddress(t *testing.T) {
+	service := newFakePushService()
+	alice := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000aa")
+	bob := service.formatUniversalAddressForPush(60, "0x00000000000000000000000000000000000000bb")
+	newFakeConnection(service, "conn-alice", alice)
+	newFakeConnection(service, "conn-bob", bob)
+
+	if err := service.Subscribe("conn-bob", alice); !errors.Is(err, ErrSubscriptionForbidden) {
+		t.Errorf("Subscribe(bob, alice) = %v, want ErrSubscriptionForbidden", err)
+	}
+	if err := service.Subscribe("conn-bob", " "+bob+" "); err != nil {
+		t.Errorf("Subscribe(bob, own address) = %v, want nil", err)
+	}
+	if err := service.Subscribe("conn-missing", bob); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("Subscribe(unknown connection) = %v, want ErrClientNotFound", err)
+	}
+}
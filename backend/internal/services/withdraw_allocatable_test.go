@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestValidateAllocatableTotals(t *testing.T) {
+	checkbooks := map[string]*models.Checkbook{
+		"cb1": {ID: "cb1", AllocatableAmount: "1000"},
+		"cb2": {ID: "cb2", AllocatableAmount: "500"},
+	}
+	allocation := func(id, checkbookID, amount string) *models.Check {
+		return &models.Check{ID: id, CheckbookID: checkbookID, Amount: amount}
+	}
+
+	tests := []struct {
+		name        string
+		allocations []*models.Check
+		wantErr     error
+	}{
+		{"below allocatable", []*models.Check{allocation("a1", "cb1", "400"), allocation("a2", "cb1", "599")}, nil},
+		{"exactly allocatable", []*models.Check{allocation("a1", "cb1", "400"), allocation("a2", "cb1", "600")}, nil},
+		{"one over allocatable", []*models.Check{allocation("a1", "cb1", "400"), allocation("a2", "cb1", "601")}, ErrAllocationsExceedAllocatable},
+		{"totals are per checkbook", []*models.Check{allocation("a1", "cb1", "1000"), allocation("a2", "cb2", "500")}, nil},
+		{"second checkbook over", []*models.Check{allocation("a1", "cb1", "1"), allocation("a2", "cb2", "501")}, ErrAllocationsExceedAllocatable},
+		{"negative amount", []*models.Check{allocation("a1", "cb1", "-1")}, ErrInvalidAllocations},
+		{"non-numeric amount", []*models.Check{allocation("a1", "cb1", "1e3")}, ErrInvalidAllocations},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAllocatableTotals(tt.allocations, checkbooks)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("validateAllocatableTotals: %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateAllocatableTotals: %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAllocationsRejectsOverAllocation(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "600000", "400000")
+	service := newFakeWithdrawService(store)
+
+	load := func() []*models.Check {
+		allocations, err := service.allocationRepo.GetByIDs(context.Background(), ids)
+		if err != nil {
+			t.Fatalf("GetByIDs: %v", err)
+		}
+		return allocations
+	}
+
+	// 600000 + 400000 is exactly the 1000000 allocatable
+	if _, err := service.validateAllocations(context.Background(), load()); err != nil {
+		t.Fatalf("at the allocatable amount: %v, want nil", err)
+	}
+
+	store.allocations[ids[1]].Amount = "400001"
+	if _, err := service.validateAllocations(context.Background(), load()); !errors.Is(err, ErrAllocationsExceedAllocatable) {
+		t.Fatalf("one over the allocatable amount: %v, want ErrAllocationsExceedAllocatable", err)
+	}
+}
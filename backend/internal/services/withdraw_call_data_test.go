@@ -0,0 +1,120 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// decodeBuiltPublicValues unpacks executeWithdraw call data and the WithdrawPublicValues built from individual fields
+func decodeBuiltPublicValues(t *testing.T, callData []byte) []interface{} {
+	t.Helper()
+	contract, err := contractABIFor(&config.NetworkConfig{ChainID: 714})
+	if err != nil {
+		t.Fatalf("contractABIFor: %v", err)
+	}
+	args, err := contract.parsed.Methods["executeWithdraw"].Inputs.Unpack(callData[4:])
+	if err != nil {
+		t.Fatalf("unpack executeWithdraw: %v", err)
+	}
+	newType := func(name string) abi.Type {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatalf("abi type %s: %v", name, err)
+		}
+		return typ
+	}
+	publicValues := abi.Arguments{
+		{Type: newType("bytes32")}, {Type: newType("bytes32[]")}, {Type: newType("uint256")}, {Type: newType("uint8")},
+		{Type: newType("uint32")}, {Type: newType("uint32")}, {Type: newType("string")}, {Type: newType("bytes32")},
+		{Type: newType("bytes32")}, {Type: newType("uint32")}, {Type: newType("string")},
+	}
+	values, err := publicValues.Unpack(args[1].([]byte))
+	if err != nil {
+		t.Fatalf("unpack public values: %v", err)
+	}
+	return values
+}
+
+func fieldsOnlyWithdrawRequest() *WithdrawRequest {
+	return &WithdrawRequest{
+		ChainID:       60,
+		SP1Proof:      "0xdead",
+		QueueRoot:     "0x01",
+		NullifierHash: "0x02",
+		Amount:        "1500",
+		Recipient:     "0x" + strings.Repeat("0", 62) + "bb",
+		TokenKey:      "USDT",
+	}
+}
+
+func TestBuildWithdrawCallDataEncodesIntentTypes(t *testing.T) {
+	service := &BlockchainTransactionService{}
+	networkConfig := &config.NetworkConfig{ChainID: 714}
+
+	tests := []struct {
+		name        string
+		intentType  models.IntentType
+		assetID     string
+		wantAdapter uint32
+	}{
+		{"raw token", models.IntentTypeRawToken, "", 0},
+		{"asset token", models.IntentTypeAssetToken, utils.EncodeAssetID(60, 7, 3), 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := fieldsOnlyWithdrawRequest()
+			req.IntentType = uint8(tt.intentType)
+			req.AssetID = tt.assetID
+
+			callData, err := service.buildWithdrawCallData(networkConfig, req)
+			if err != nil {
+				t.Fatalf("buildWithdrawCallData: %v", err)
+			}
+			values := decodeBuiltPublicValues(t, callData)
+			if got := values[3].(uint8); got != uint8(tt.intentType) {
+				t.Errorf("intentType = %d, want %d", got, tt.intentType)
+			}
+			if got := values[4].(uint32); got != 60 {
+				t.Errorf("slip44ChainID = %d, want 60", got)
+			}
+			if got := values[5].(uint32); got != tt.wantAdapter {
+				t.Errorf("adapterId = %d, want %d", got, tt.wantAdapter)
+			}
+			if got := values[6].(string); got != "USDT" {
+				t.Errorf("tokenKey = %q, want USDT", got)
+			}
+		})
+	}
+}
+
+func TestBuildWithdrawCallDataRejectsInvalidIntent(t *testing.T) {
+	service := &BlockchainTransactionService{}
+	networkConfig := &config.NetworkConfig{ChainID: 714}
+
+	tests := []struct {
+		name       string
+		intentType uint8
+		assetID    string
+		wantErr    string
+	}{
+		{"asset token without asset ID", uint8(models.IntentTypeAssetToken), "", "asset ID is required"},
+		{"asset token with malformed asset ID", uint8(models.IntentTypeAssetToken), "0x1234", "invalid asset ID"},
+		{"unknown intent type", 9, "", "unsupported intent type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := fieldsOnlyWithdrawRequest()
+			req.IntentType = tt.intentType
+			req.AssetID = tt.assetID
+			if _, err := service.buildWithdrawCallData(networkConfig, req); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("buildWithdrawCallData: %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeQueueRootRepo QueueRootRepository answering commitment lookups from a map
+type fakeQueueRootRepo struct {
+	repository.QueueRootRepository
+	byCommitment map[string]*models.QueueRoot
+	after        map[string][]string // key: root
+}
+
+func (r *fakeQueueRootRepo) GetByCommitment(ctx context.Context, commitment string) (*models.QueueRoot, error) {
+	if queueRoot, ok := r.byCommitment[commitment]; ok {
+		return queueRoot, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeQueueRootRepo) GetCommitmentsAfter(ctx context.Context, root string) ([]string, error) {
+	return r.after[root], nil
+}
+
+// addCommittedCheckbook stores a checkbook of deposit depositID with a commitment and idle allocations
+func (s *fakeStore) addCommittedCheckbook(checkbookID string, depositID uint64, amounts ...string) []string {
+	ids := s.addIdleAllocations(checkbookID, "0xowner", amounts...)
+	commitment := "0xcommitment-" + checkbookID
+	s.checkbooks[checkbookID].Commitment = &commitment
+	s.checkbooks[checkbookID].LocalDepositID = depositID
+	s.checkbooks[checkbookID].SLIP44ChainID = 714
+	return ids
+}
+
+// realPathCommitmentGroups builds the groups the way proof generation does for a stored request
+func realPathCommitmentGroups(t *testing.T, service *WithdrawRequestService, request *models.WithdrawRequest) interface{} {
+	t.Helper()
+	ctx := context.Background()
+	allocationIDs, err := service.getAllocationIDs(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocations, err := service.allocationRepo.GetByIDs(ctx, allocationIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkbookGroups, checkbookIDs := groupAllocationsByCheckbook(allocations)
+	checkbooks, err := service.checkbookRepo.GetByIDs(ctx, checkbookIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateProofCheckbooks(checkbooks); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := service.buildCommitmentGroups(ctx, checkbooks, checkbookGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return groups
+}
+
+func TestPreviewCommitmentGroupsMatchesProofGeneration(t *testing.T) {
+	store := newFakeStore()
+	later := store.addCommittedCheckbook("cb-later", 9, "100", "200", "300")
+	earlier := store.addCommittedCheckbook("cb-earlier", 3, "400")
+	service := newFakeWithdrawService(store)
+	service.queueRootRepo = &fakeQueueRootRepo{
+		byCommitment: map[string]*models.QueueRoot{
+			"0xcommitment-cb-later": {Root: "0xroot-later", PreviousRoot: "0xroot-before-later"},
+		},
+		after: map[string][]string{"0xroot-later": {"0xc1", "0xc2"}},
+	}
+	allocationIDs := []string{later[1], earlier[0], later[2]}
+
+	requestsBefore, allocationsBefore := store.snapshot()
+	preview, err := service.PreviewCommitmentGroups(context.Background(), allocationIDs)
+	if err != nil {
+		t.Fatalf("PreviewCommitmentGroups: %v", err)
+	}
+	if requestsAfter, allocationsAfter := store.snapshot(); !reflect.DeepEqual(requestsBefore, requestsAfter) ||
+		!reflect.DeepEqual(allocationsBefore, allocationsAfter) {
+		t.Fatal("preview changed stored requests or allocations")
+	}
+
+	// Sorted by deposit ID; only the selected allocations, with hashes over the whole checkbook
+	if len(preview) != 2 || len(preview[0].Allocations) != 1 || len(preview[1].Allocations) != 2 {
+		t.Fatalf("preview = %+v, want deposit 3 with 1 allocation, then deposit 9 with 2", preview)
+	}
+	if got := preview[1].Allocations[0].Credential; len(got.LeftHashes) != 1 || len(got.RightHashes) != 1 {
+		t.Errorf("middle allocation hashes = %d left, %d right, want 1 and 1", len(got.LeftHashes), len(got.RightHashes))
+	}
+
+	request, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", allocationIDs...))
+	if err != nil {
+		t.Fatalf("CreateWithdrawRequest: %v", err)
+	}
+	if real := realPathCommitmentGroups(t, service, request); !reflect.DeepEqual(real, preview) {
+		t.Errorf("proof generation groups = %+v\npreview = %+v", real, preview)
+	}
+}
+
+func TestPreviewCommitmentGroupsRejects(t *testing.T) {
+	store := newFakeStore()
+	committed := store.addCommittedCheckbook("cb1", 1, "100")
+	uncommitted := store.addIdleAllocations("cb2", "0xowner", "100")
+	other := store.addCommittedCheckbook("cb3", 3, "100")
+	store.checkbooks["cb3"].UserAddress.Data = "0xsomeone-else"
+	service := newFakeWithdrawService(store)
+	service.queueRootRepo = &fakeQueueRootRepo{}
+
+	for name, ids := range map[string][]string{
+		"no allocations":     nil,
+		"unknown allocation": {committed[0], "missing"},
+		"no commitment":      {committed[0], uncommitted[0]},
+		"different owners":   {committed[0], other[0]},
+	} {
+		if _, err := service.PreviewCommitmentGroups(context.Background(), ids); err == nil {
+			t.Errorf("%s: preview succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+)
+
+func TestCreateWithdrawRequestDefaultHook(t *testing.T) {
+	tests := []struct {
+		name         string
+		withdraw     config.WithdrawConfig
+		intentHook   string
+		wantStatus   models.HookStatus
+		wantCalldata string
+	}{
+		{"disabled", config.WithdrawConfig{DefaultHookCalldata: "0xabcd"}, "", models.HookStatusNotRequired, ""},
+		{"enabled", config.WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "0xABCD"}, "", models.HookStatusPending, "0xabcd"},
+		{"enabled, intent hook wins", config.WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "0xabcd"}, "0x1234", models.HookStatusPending, "0x1234"},
+		{"disabled, intent hook", config.WithdrawConfig{}, "0x1234", models.HookStatusPending, "0x1234"},
+		{"enabled with invalid calldata", config.WithdrawConfig{DefaultHookEnabled: true, DefaultHookCalldata: "0xzz"}, "", models.HookStatusNotRequired, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previousConfig := config.AppConfig
+			config.AppConfig = &config.Config{Withdraw: tt.withdraw}
+			t.Cleanup(func() { config.AppConfig = previousConfig })
+
+			store := newFakeStore()
+			ids := store.addIdleAllocations("cb1", "0xowner", "100")
+			service := newFakeWithdrawService(store)
+			service.defaultHookCalldata = defaultHookCalldata(config.GetWithdrawConfig())
+
+			input := idempotentCreateInput("", ids...)
+			input.Intent.HookCalldata = tt.intentHook
+			request, err := service.CreateWithdrawRequest(context.Background(), input)
+			if err != nil {
+				t.Fatalf("CreateWithdrawRequest: %v", err)
+			}
+			if request.HookStatus != tt.wantStatus || request.HookCalldata != tt.wantCalldata {
+				t.Errorf("hook_status=%s hook_calldata=%q, want %s %q", request.HookStatus, request.HookCalldata, tt.wantStatus, tt.wantCalldata)
+			}
+		})
+	}
+}
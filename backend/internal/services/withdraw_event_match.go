@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Strategies that matched an on-chain requestId to a WithdrawRequest or its Checks, in the order they are tried
+const (
+	matchByWithdrawNullifier = "withdraw_nullifier"
+	matchByRequestID         = "request_id (DEPRECATED)"
+	matchByCheckNullifier    = "check.nullifier"
+	matchByCheckRequestID    = "check.request_id (DEPRECATED)"
+)
+
+// withdrawEventMatch result of findWithdrawRequestForEvent
+// request is set when the WithdrawRequest itself was found; otherwise checks may hold the Checks that matched
+// (their withdraw_request_id leads to the request). Both empty means nothing matched.
+type withdrawEventMatch struct {
+	request   *models.WithdrawRequest
+	checks    []models.Check
+	matchedBy string
+}
+
+// findWithdrawRequestForEvent resolves a withdraw event's requestId
+// Tries WithdrawRequest.withdraw_nullifier, WithdrawRequest.request_id, then (withChecks) Check.nullifier and
+// Check.request_id. The deprecated request_id lookups are skipped unless withdraw.match_by_deprecated_request_id
+// is set: request IDs are not unique across deployments and may match the wrong request.
+func (p *BlockchainEventProcessor) findWithdrawRequestForEvent(requestID string, withChecks bool) (*withdrawEventMatch, error) {
+	var withdrawRequest models.WithdrawRequest
+	err := p.db.Where("withdraw_nullifier = ?", requestID).First(&withdrawRequest).Error
+	if err == nil {
+		return &withdrawEventMatch{request: &withdrawRequest, matchedBy: matchByWithdrawNullifier}, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("query WithdrawRequest by withdraw_nullifier failed: %w", err)
+	}
+
+	if p.matchByDeprecatedRequestID {
+		log.Printf("🔍 [WithdrawEvent] WithdrawRequest not found by withdraw_nullifier, trying request_id (DEPRECATED): RequestId=%s", requestID)
+		err = p.db.Where("request_id = ?", requestID).First(&withdrawRequest).Error
+		if err == nil {
+			return &withdrawEventMatch{request: &withdrawRequest, matchedBy: matchByRequestID}, nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("query WithdrawRequest by request_id failed: %w", err)
+		}
+	}
+
+	if !withChecks {
+		return &withdrawEventMatch{}, nil
+	}
+
+	// The requestId may be a commitment nullifier recorded on the Check
+	var checks []models.Check
+	if err := p.db.Where("nullifier = ?", requestID).Find(&checks).Error; err != nil {
+		return nil, fmt.Errorf("query Check by nullifier failed: %w", err)
+	}
+	if len(checks) > 0 {
+		return &withdrawEventMatch{checks: checks, matchedBy: matchByCheckNullifier}, nil
+	}
+
+	if p.matchByDeprecatedRequestID {
+		if err := p.db.Where("request_id = ?", requestID).Find(&checks).Error; err != nil {
+			return nil, fmt.Errorf("query Check by request_id failed: %w", err)
+		}
+		if len(checks) > 0 {
+			return &withdrawEventMatch{checks: checks, matchedBy: matchByCheckRequestID}, nil
+		}
+	}
+
+	return &withdrawEventMatch{}, nil
+}
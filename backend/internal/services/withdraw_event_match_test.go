@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestFindWithdrawRequestForEventFallbacks(t *testing.T) {
+	processor, database, _ := newTestEventProcessor(t)
+
+	// Each row matches one strategy; the *-collision rows carry a deprecated request_id equal to an earlier
+	// strategy's key, which must win
+	createExecutedWithdrawRequest(t, database, "wr-nullifier", "0xrequest")
+	createExecutedWithdrawRequest(t, database, "wr-legacy", "0xlegacy-nullifier")
+	createExecutedWithdrawRequest(t, database, "wr-collision", "0xcollision-nullifier")
+	for id, requestID := range map[string]string{"wr-legacy": "0xlegacy", "wr-collision": "0xrequest"} {
+		if err := database.Model(&models.WithdrawRequest{}).Where("id = ?", id).Update("request_id", requestID).Error; err != nil {
+			t.Fatalf("set request_id: %v", err)
+		}
+	}
+	checkLegacy, checkCollision := "0xcheck-legacy", "0xcheck-nullifier"
+	for _, check := range []models.Check{
+		{ID: "check-nullifier", CheckbookID: "cb", Amount: "1", Nullifier: "0xcheck-nullifier"},
+		{ID: "check-legacy", CheckbookID: "cb", Amount: "1", Nullifier: "0xcheck-2", RequestID: &checkLegacy},
+		{ID: "check-collision", CheckbookID: "cb", Amount: "1", Nullifier: "0xcheck-3", RequestID: &checkCollision},
+	} {
+		if err := database.Create(&check).Error; err != nil {
+			t.Fatalf("create check: %v", err)
+		}
+	}
+
+	tests := []struct {
+		requestID   string
+		withChecks  bool
+		deprecated  bool
+		wantMatched string
+		wantID      string // request ID, or the first check ID when only checks matched
+	}{
+		{"0xrequest", true, true, matchByWithdrawNullifier, "wr-nullifier"},
+		{"0xrequest", false, false, matchByWithdrawNullifier, "wr-nullifier"},
+		{"0xlegacy", false, true, matchByRequestID, "wr-legacy"},
+		{"0xlegacy", true, false, "", ""},
+		{"0xcheck-nullifier", true, false, matchByCheckNullifier, "check-nullifier"},
+		{"0xcheck-nullifier", true, true, matchByCheckNullifier, "check-nullifier"},
+		{"0xcheck-nullifier", false, true, "", ""},
+		{"0xcheck-legacy", true, true, matchByCheckRequestID, "check-legacy"},
+		{"0xcheck-legacy", true, false, "", ""},
+		{"0xnothing", true, true, "", ""},
+	}
+	for _, tt := range tests {
+		processor.matchByDeprecatedRequestID = tt.deprecated
+		match, err := processor.findWithdrawRequestForEvent(tt.requestID, tt.withChecks)
+		if err != nil {
+			t.Fatalf("%s (checks=%v, deprecated=%v): %v", tt.requestID, tt.withChecks, tt.deprecated, err)
+		}
+		gotID := ""
+		switch {
+		case match.request != nil:
+			gotID = match.request.ID
+		case len(match.checks) > 0:
+			gotID = match.checks[0].ID
+		}
+		if match.matchedBy != tt.wantMatched || gotID != tt.wantID {
+			t.Errorf("%s (checks=%v, deprecated=%v) = %q %q, want %q %q",
+				tt.requestID, tt.withChecks, tt.deprecated, match.matchedBy, gotID, tt.wantMatched, tt.wantID)
+		}
+	}
+}
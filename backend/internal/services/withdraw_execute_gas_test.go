@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestRecordExecuteGasCostFromReceipt(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr-gas", "100")
+	store.requests["wr-gas"].ExecuteGasPrice = "1"
+	service := newFakeWithdrawService(store)
+
+	// A reverted receipt still paid for its gas
+	service.recordExecuteGasCost(context.Background(), "wr-gas",
+		&ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed, GasUsed: 52000, EffectiveGasPrice: big.NewInt(5_000_000_000)})
+	request := store.request("wr-gas")
+	if request.ExecuteGasUsed == nil || *request.ExecuteGasUsed != 52000 || request.ExecuteGasPrice != "5000000000" {
+		t.Fatalf("gas cost = %v @ %q, want 52000 @ 5000000000", request.ExecuteGasUsed, request.ExecuteGasPrice)
+	}
+
+	// A receipt without an effective gas price keeps the stored price
+	service.recordExecuteGasCost(context.Background(), "wr-gas", &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful, GasUsed: 61000})
+	request = store.request("wr-gas")
+	if request.ExecuteGasUsed == nil || *request.ExecuteGasUsed != 61000 || request.ExecuteGasPrice != "5000000000" {
+		t.Errorf("gas cost = %v @ %q, want 61000 @ 5000000000", request.ExecuteGasUsed, request.ExecuteGasPrice)
+	}
+
+	// An unknown request is only logged
+	service.recordExecuteGasCost(context.Background(), "wr-missing", &ethtypes.Receipt{GasUsed: 1})
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/db/dbtest"
+	"go-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// recordingSubmitter accepts every executeWithdraw and remembers the requests it was given
+type recordingSubmitter struct {
+	ChainTransactionService
+	mu       sync.Mutex
+	requests []*WithdrawRequest
+}
+
+func (s *recordingSubmitter) SubmitWithdraw(req *WithdrawRequest) (*WithdrawResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	return &WithdrawResponse{TxHash: "0xrouted"}, nil
+}
+
+// newCountingReceiptRPC an RPC endpoint without receipts that counts the calls it receives
+func newCountingReceiptRPC(t *testing.T, calls *int) *ethclient.Client {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		*calls++
+		mu.Unlock()
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": nil})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial stub: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestExecuteWithdrawUsesRoutedSubmissionChain(t *testing.T) {
+	database := dbtest.Open(t)
+	useSubmissionConfig(t, "", config.SubmitChainBeneficiary, 0)
+
+	store := newFakeStore()
+	allocationIDs := store.addIdleAllocations("cb-routed", "0x00000000000000000000000000000000000000aa", "100")
+	store.checkbooks["cb-routed"].SLIP44ChainID = 966 // deposited on neither the management nor the beneficiary chain
+	allocationJSON, _ := json.Marshal(allocationIDs)
+	store.requests["wr-routed"] = &models.WithdrawRequest{
+		ID:                  "wr-routed",
+		WithdrawNullifier:   "0xnullifier-routed",
+		AllocationIDs:       string(allocationJSON),
+		Amount:              "100",
+		Recipient:           models.UniversalAddress{SLIP44ChainID: 60, Data: "0x00000000000000000000000000000000000000bb"},
+		TargetSLIP44ChainID: 60,
+		Status:              string(models.WithdrawStatusProofGenerated),
+		ProofStatus:         models.ProofStatusCompleted,
+		Proof:               "0x01",
+		PublicValues:        "0x02",
+		ExecuteStatus:       models.ExecuteStatusPending,
+		Version:             1,
+	}
+
+	var managementCalls, beneficiaryCalls int
+	blockchainService := NewBlockchainTransactionService(nil)
+	blockchainService.setClient(714, newCountingReceiptRPC(t, &managementCalls), "management")
+	blockchainService.setClient(60, newCountingReceiptRPC(t, &beneficiaryCalls), "beneficiary")
+	submitter := &recordingSubmitter{}
+
+	service := newFakeWithdrawService(store)
+	service.blockchainService = blockchainService
+	service.pollingService = NewUnifiedPollingService(database, nil, nil)
+	service.submitter = submitter
+	service.quickCheckDelays = []time.Duration{time.Millisecond}
+
+	if err := service.ExecuteWithdraw(context.Background(), "wr-routed"); err != nil {
+		t.Fatalf("ExecuteWithdraw: %v", err)
+	}
+
+	if len(submitter.requests) != 1 {
+		t.Fatalf("%d submissions, want 1", len(submitter.requests))
+	}
+	if got := submitter.requests[0]; got.SubmitChainID != 60 || got.ChainID != 966 {
+		t.Errorf("submitted with SubmitChainID %d, ChainID %d, want 60 (beneficiary) and 966 (checkbook)", got.SubmitChainID, got.ChainID)
+	}
+	if beneficiaryCalls == 0 || managementCalls != 0 {
+		t.Errorf("receipt checks: %d on the beneficiary chain, %d on the management chain, want them on the beneficiary chain only",
+			beneficiaryCalls, managementCalls)
+	}
+
+	var task models.PollingTask
+	if err := database.Where("entity_id = ? AND task_type = ?", "wr-routed", models.PollingWithdrawExecute).First(&task).Error; err != nil {
+		t.Fatalf("load polling task: %v", err)
+	}
+	if task.ChainID != 60 || task.TxHash != "0xrouted" {
+		t.Errorf("polling task on chain %d for %s, want chain 60 for 0xrouted", task.ChainID, task.TxHash)
+	}
+	if request := store.request("wr-routed"); request.ExecuteChainID == nil || *request.ExecuteChainID != 60 {
+		t.Errorf("execute_chain_id = %v, want 60", request.ExecuteChainID)
+	}
+}
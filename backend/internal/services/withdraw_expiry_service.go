@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-backend/internal/repository"
+)
+
+// withdrawExpiryBatchSize expired requests cancelled per check
+const withdrawExpiryBatchSize = 100
+
+// WithdrawExpiryService cancels withdraw requests past their ExpiresAt that never got a proof
+// Such requests would otherwise hold their allocations in pending forever. Only requests that have not
+// started executing are touched (WithdrawRequest.IsExpired); cancellation releases their allocations.
+type WithdrawExpiryService struct {
+	withdrawRepo    repository.WithdrawRequestRepository
+	withdrawService *WithdrawRequestService
+	running         bool
+	stopCh          chan struct{}
+	checkInterval   time.Duration
+	now             func() time.Time // clock, replaceable for tests
+}
+
+// NewWithdrawExpiryService creates a new WithdrawExpiryService
+func NewWithdrawExpiryService(withdrawRepo repository.WithdrawRequestRepository, withdrawService *WithdrawRequestService) *WithdrawExpiryService {
+	return &WithdrawExpiryService{
+		withdrawRepo:    withdrawRepo,
+		withdrawService: withdrawService,
+		stopCh:          make(chan struct{}),
+		checkInterval:   time.Minute,
+		now:             time.Now,
+	}
+}
+
+// Start begins the expiry check loop
+func (s *WithdrawExpiryService) Start() {
+	if s.running {
+		return
+	}
+	s.running = true
+
+	log.Printf("🚀 Starting WithdrawExpiryService (check interval: %v)", s.checkInterval)
+
+	go s.expiryCheckLoop()
+
+	log.Printf("✅ WithdrawExpiryService started")
+}
+
+// Stop gracefully stops the expiry check loop
+func (s *WithdrawExpiryService) Stop() {
+	if !s.running {
+		return
+	}
+	s.running = false
+	close(s.stopCh)
+	log.Printf("🛑 WithdrawExpiryService stopped")
+}
+
+// expiryCheckLoop periodically cancels expired withdraw requests
+func (s *WithdrawExpiryService) expiryCheckLoop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	// Run initial check on startup
+	s.cancelExpired()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.cancelExpired()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// cancelExpired cancels one batch of expired requests and returns how many were cancelled
+func (s *WithdrawExpiryService) cancelExpired() int {
+	ctx := context.Background()
+	now := s.now()
+
+	requests, err := s.withdrawRepo.FindExpired(ctx, now, withdrawExpiryBatchSize)
+	if err != nil {
+		log.Printf("❌ [WithdrawExpiry] Failed to query expired withdraw requests: %v", err)
+		return 0
+	}
+	if len(requests) == 0 {
+		return 0
+	}
+
+	log.Printf("⚠️ [WithdrawExpiry] Found %d expired withdraw requests", len(requests))
+
+	count := 0
+	for _, request := range requests {
+		expired, err := s.withdrawService.ExpireWithdrawRequest(ctx, request.ID, now)
+		if err != nil {
+			log.Printf("❌ [WithdrawExpiry] Failed to cancel expired request %s: %v", request.ID, err)
+			continue
+		}
+		if !expired {
+			log.Printf("ℹ️ [WithdrawExpiry] Request %s progressed before it could be cancelled, skipping", request.ID)
+			continue
+		}
+		log.Printf("⏰ [WithdrawExpiry] Cancelled request %s (expired at %s, proof_status=%s), allocations released",
+			request.ID, request.ExpiresAt.Format(time.RFC3339), request.ProofStatus)
+		count++
+	}
+	return count
+}
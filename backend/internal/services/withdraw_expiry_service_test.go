@@ -0,0 +1,92 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-backend/internal/models"
+)
+
+// addExpiringRequest stores a request locking one pending allocation of its own checkbook, expiring at expiresAt
+func addExpiringRequest(t *testing.T, store *fakeStore, id string, expiresAt *time.Time, proof models.ProofStatus, execute models.ExecuteStatus) {
+	t.Helper()
+	ids := store.addIdleAllocations("cb-"+id, "0xowner", "100")
+	for _, allocationID := range ids {
+		requestID := id
+		store.allocations[allocationID].Status = models.AllocationStatusPending
+		store.allocations[allocationID].WithdrawRequestID = &requestID
+	}
+	allocationIDs, err := json.Marshal(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &models.WithdrawRequest{
+		ID:            id,
+		AllocationIDs: string(allocationIDs),
+		ExpiresAt:     expiresAt,
+		ProofStatus:   proof,
+		ExecuteStatus: execute,
+		PayoutStatus:  models.PayoutStatusPending,
+		HookStatus:    models.HookStatusNotRequired,
+		Version:       1,
+	}
+	request.UpdateMainStatus()
+	store.requests[id] = request
+}
+
+func TestWithdrawExpiryCancelsOnlyExpiredPreExecuteRequests(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expired, later := now.Add(-time.Minute), now.Add(10*time.Minute)
+
+	store := newFakeStore()
+	addExpiringRequest(t, store, "wr-waiting", &expired, models.ProofStatusPending, models.ExecuteStatusPending)
+	addExpiringRequest(t, store, "wr-proof-failed", &expired, models.ProofStatusFailed, models.ExecuteStatusPending)
+	addExpiringRequest(t, store, "wr-not-yet", &later, models.ProofStatusPending, models.ExecuteStatusPending)
+	addExpiringRequest(t, store, "wr-submitted", &expired, models.ProofStatusCompleted, models.ExecuteStatusSubmitted)
+	addExpiringRequest(t, store, "wr-executed", &expired, models.ProofStatusCompleted, models.ExecuteStatusSuccess)
+	addExpiringRequest(t, store, "wr-no-expiry", nil, models.ProofStatusPending, models.ExecuteStatusPending)
+
+	withdrawService := newFakeWithdrawService(store)
+	expiry := NewWithdrawExpiryService(withdrawService.withdrawRepo, withdrawService)
+	clock := now
+	expiry.now = func() time.Time { return clock }
+
+	assertCancelled := func(t *testing.T, want map[string]bool) {
+		t.Helper()
+		for id, cancelled := range want {
+			request := store.request(id)
+			allocation := store.allocation("cb-" + id + "-1")
+			if cancelled {
+				if request.Status != string(models.WithdrawStatusCancelled) {
+					t.Errorf("%s status = %s, want cancelled", id, request.Status)
+				}
+				if allocation.Status != models.AllocationStatusIdle || allocation.WithdrawRequestID != nil {
+					t.Errorf("%s allocation = %s linked to %v, want released", id, allocation.Status, allocation.WithdrawRequestID)
+				}
+				continue
+			}
+			if request.Status == string(models.WithdrawStatusCancelled) {
+				t.Errorf("%s cancelled, want it kept", id)
+			}
+			if allocation.Status != models.AllocationStatusPending {
+				t.Errorf("%s allocation = %s, want still pending", id, allocation.Status)
+			}
+		}
+	}
+
+	if got := expiry.cancelExpired(); got != 2 {
+		t.Errorf("cancelExpired = %d, want 2", got)
+	}
+	assertCancelled(t, map[string]bool{
+		"wr-waiting": true, "wr-proof-failed": true,
+		"wr-not-yet": false, "wr-submitted": false, "wr-executed": false, "wr-no-expiry": false,
+	})
+
+	// Once the clock passes its expiry the remaining pre-execute request is cancelled too
+	clock = later.Add(time.Second)
+	if got := expiry.cancelExpired(); got != 1 {
+		t.Errorf("cancelExpired after advancing the clock = %d, want 1", got)
+	}
+	assertCancelled(t, map[string]bool{"wr-not-yet": true, "wr-submitted": false, "wr-executed": false, "wr-no-expiry": false})
+}
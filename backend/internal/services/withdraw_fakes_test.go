@@ -0,0 +1,602 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-backend/internal/logging"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// fakeStore in-memory withdraw requests and allocations shared by the fake repositories
+// Methods a test does not stub panic through the embedded nil interfaces.
+type fakeStore struct {
+	mu          sync.Mutex
+	requests    map[string]*models.WithdrawRequest
+	allocations map[string]*models.Check
+	checkbooks  map[string]*models.Checkbook // read-only
+	releaseErr  error                        // returned by ReleaseByWithdrawRequest when set
+	lockErr     error                        // returned by LockForWithdrawal when set
+
+	checkbookQueries int // checkbook repository lookups, one per GetByID or GetByIDs call
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		requests:    make(map[string]*models.WithdrawRequest),
+		allocations: make(map[string]*models.Check),
+		checkbooks:  make(map[string]*models.Checkbook),
+	}
+}
+
+// snapshot copies every record, restore puts a snapshot back (transaction rollback)
+func (s *fakeStore) snapshot() (map[string]models.WithdrawRequest, map[string]models.Check) {
+	requests := make(map[string]models.WithdrawRequest, len(s.requests))
+	for id, r := range s.requests {
+		requests[id] = *r
+	}
+	allocations := make(map[string]models.Check, len(s.allocations))
+	for id, a := range s.allocations {
+		allocations[id] = *a
+	}
+	return requests, allocations
+}
+
+func (s *fakeStore) restore(requests map[string]models.WithdrawRequest, allocations map[string]models.Check) {
+	s.requests = make(map[string]*models.WithdrawRequest, len(requests))
+	for id, r := range requests {
+		r := r
+		s.requests[id] = &r
+	}
+	s.allocations = make(map[string]*models.Check, len(allocations))
+	for id, a := range allocations {
+		a := a
+		s.allocations[id] = &a
+	}
+}
+
+func (s *fakeStore) request(id string) models.WithdrawRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.requests[id]
+}
+
+func (s *fakeStore) allocation(id string) models.Check {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.allocations[id]
+}
+
+// fakeWithdrawRepo WithdrawRequestRepository over a fakeStore
+type fakeWithdrawRepo struct {
+	repository.WithdrawRequestRepository
+	store *fakeStore
+}
+
+func (r *fakeWithdrawRepo) Create(ctx context.Context, request *models.WithdrawRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, ok := r.store.requests[request.ID]; ok {
+		return fmt.Errorf("duplicate withdraw request %s", request.ID)
+	}
+	// Unique indexes on withdraw_nullifier (among non-deleted requests) and (owner, idempotency_key)
+	for _, stored := range r.store.requests {
+		if !stored.DeletedAt.Valid && stored.WithdrawNullifier == request.WithdrawNullifier {
+			return fmt.Errorf("duplicate withdraw nullifier %s", request.WithdrawNullifier)
+		}
+		if request.IdempotencyKey != nil && stored.IdempotencyKey != nil && *stored.IdempotencyKey == *request.IdempotencyKey &&
+			stored.OwnerAddress.SLIP44ChainID == request.OwnerAddress.SLIP44ChainID && stored.OwnerAddress.Data == request.OwnerAddress.Data {
+			return fmt.Errorf("duplicate idempotency key %s", *request.IdempotencyKey)
+		}
+	}
+	if request.Version == 0 {
+		request.Version = 1
+	}
+	stored := *request
+	r.store.requests[request.ID] = &stored
+	return nil
+}
+
+func (r *fakeWithdrawRepo) GetByID(ctx context.Context, id string) (*models.WithdrawRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	request, ok := r.store.requests[id]
+	if !ok || request.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *request
+	return &found, nil
+}
+
+func (r *fakeWithdrawRepo) GetByNullifier(ctx context.Context, nullifier string) (*models.WithdrawRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, request := range r.store.requests {
+		if request.WithdrawNullifier == nullifier && !request.DeletedAt.Valid {
+			found := *request
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindExpired returns every request past expires_at, oldest expiry first
+// Coarser than the repository, which also filters on the statuses: the service's own IsExpired re-check
+// is what must keep requests that started executing alive.
+func (r *fakeWithdrawRepo) FindExpired(ctx context.Context, now time.Time, limit int) ([]*models.WithdrawRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var found []*models.WithdrawRequest
+	for _, request := range r.store.requests {
+		if request.ExpiresAt != nil && request.ExpiresAt.Before(now) && !request.DeletedAt.Valid {
+			copied := *request
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ExpiresAt.Before(*found[j].ExpiresAt) })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r *fakeWithdrawRepo) GetByIdempotencyKey(ctx context.Context, ownerChainID uint32, ownerData, key string) (*models.WithdrawRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, request := range r.store.requests {
+		if !request.DeletedAt.Valid && request.IdempotencyKey != nil && *request.IdempotencyKey == key &&
+			request.OwnerAddress.SLIP44ChainID == ownerChainID && request.OwnerAddress.Data == ownerData {
+			found := *request
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWithdrawRepo) Update(ctx context.Context, request *models.WithdrawRequest) error {
+	if err := request.ValidateSubStatuses(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	stored, ok := r.store.requests[request.ID]
+	if !ok || stored.DeletedAt.Valid || stored.Version != request.Version {
+		return fmt.Errorf("%w: withdraw request %s, version=%d", repository.ErrVersionConflict, request.ID, request.Version)
+	}
+	request.Version++
+	updated := *request
+	r.store.requests[request.ID] = &updated
+	return nil
+}
+
+func (r *fakeWithdrawRepo) Modify(ctx context.Context, id string, fn func(request *models.WithdrawRequest) error) (*models.WithdrawRequest, error) {
+	var err error
+	for attempt := 1; attempt <= repository.MaxVersionConflictRetries; attempt++ {
+		var request *models.WithdrawRequest
+		if request, err = r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		if err = fn(request); err != nil {
+			return nil, err
+		}
+		if err = r.Update(ctx, request); err == nil || !errors.Is(err, repository.ErrVersionConflict) {
+			return request, err
+		}
+	}
+	return nil, err
+}
+
+// UpdateProofStatus mirrors the repository: completion stores the proof, failure the error
+func (r *fakeWithdrawRepo) UpdateProofStatus(ctx context.Context, id string, status models.ProofStatus, proof string, publicValues string, errMsg string) error {
+	_, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+		request.ProofStatus = status
+		if status == models.ProofStatusCompleted {
+			now := time.Now()
+			request.Proof = proof
+			request.PublicValues = publicValues
+			request.ProofGeneratedAt = &now
+		} else if status == models.ProofStatusFailed {
+			request.ProofError = errMsg
+		}
+		return nil
+	})
+	return err
+}
+
+// UpdateExecuteGasCost mirrors the repository: an empty gas price keeps the stored one
+func (r *fakeWithdrawRepo) UpdateExecuteGasCost(ctx context.Context, id string, gasUsed uint64, gasPrice string) error {
+	_, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+		request.ExecuteGasUsed = &gasUsed
+		if gasPrice != "" {
+			request.ExecuteGasPrice = gasPrice
+		}
+		return nil
+	})
+	return err
+}
+
+// UpdateExecuteStatus mirrors the repository: final execute statuses are kept, submit failures record the error
+func (r *fakeWithdrawRepo) UpdateExecuteStatus(ctx context.Context, id string, status models.ExecuteStatus, txHash string, blockNumber *uint64, errMsg string) error {
+	_, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+		switch request.ExecuteStatus {
+		case models.ExecuteStatusSuccess, models.ExecuteStatusVerifyFailed, models.ExecuteStatusSubmitFailed:
+			return nil
+		}
+		request.ExecuteStatus = status
+		if txHash != "" {
+			request.ExecuteTxHash = txHash
+		}
+		if status == models.ExecuteStatusSubmitFailed {
+			request.ExecuteError = errMsg
+		}
+		return nil
+	})
+	return err
+}
+
+// UpdateNullifierMismatch mirrors the repository: records the flag only
+func (r *fakeWithdrawRepo) UpdateNullifierMismatch(ctx context.Context, id string, mismatch bool) error {
+	_, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+		request.NullifierMismatch = mismatch
+		return nil
+	})
+	return err
+}
+
+// UpdatePayoutStatus mirrors the repository: failures record the error and bump the retry count
+func (r *fakeWithdrawRepo) UpdatePayoutStatus(ctx context.Context, id string, status models.PayoutStatus, txHash string, blockNumber *uint64, errMsg string) error {
+	_, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+		now := time.Now()
+		request.PayoutStatus = status
+		if txHash != "" {
+			request.PayoutTxHash = txHash
+		}
+		if status == models.PayoutStatusCompleted {
+			request.PayoutCompletedAt = &now
+			if blockNumber != nil {
+				request.PayoutBlockNumber = blockNumber
+			}
+		} else if status == models.PayoutStatusFailed {
+			request.PayoutError = errMsg
+			request.PayoutLastRetryAt = &now
+			request.PayoutRetryCount++
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *fakeWithdrawRepo) UpdateFallbackStatus(ctx context.Context, id string, transferred bool, errMsg string, retryCount int) error {
+	_, err := r.Modify(ctx, id, func(request *models.WithdrawRequest) error {
+		request.FallbackTransferred = transferred
+		request.FallbackError = errMsg
+		request.FallbackRetryCount = retryCount
+		return nil
+	})
+	return err
+}
+
+// AppendRetryHistory appends to the stored JSON without a version check, like the single-UPDATE append
+func (r *fakeWithdrawRepo) AppendRetryHistory(ctx context.Context, id string, entry models.RetryHistoryEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	request, ok := r.store.requests[id]
+	if !ok || request.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	history, err := request.ParseRetryHistory()
+	if err != nil {
+		return err
+	}
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	encoded, err := json.Marshal(append(history, entry))
+	if err != nil {
+		return err
+	}
+	request.RetryHistory = string(encoded)
+	return nil
+}
+
+// Delete soft-deletes: the request stays in the store with deleted_at set and is ignored by the lookups
+func (r *fakeWithdrawRepo) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if request, ok := r.store.requests[id]; ok {
+		request.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+// fakeAllocationRepo AllocationRepository over a fakeStore
+type fakeAllocationRepo struct {
+	repository.AllocationRepository
+	store *fakeStore
+}
+
+func (r *fakeAllocationRepo) GetByID(ctx context.Context, id string) (*models.Check, error) {
+	found, err := r.GetByIDs(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+func (r *fakeAllocationRepo) GetByIDs(ctx context.Context, ids []string) ([]*models.Check, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	found := make([]*models.Check, 0, len(ids))
+	for _, id := range ids {
+		allocation, ok := r.store.allocations[id]
+		if !ok {
+			return nil, fmt.Errorf("allocation %s: %w", id, gorm.ErrRecordNotFound)
+		}
+		copied := *allocation
+		found = append(found, &copied)
+	}
+	return found, nil
+}
+
+func (r *fakeAllocationRepo) FindByCheckbook(ctx context.Context, checkbookID string) ([]*models.Check, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var found []*models.Check
+	for _, allocation := range r.store.allocations {
+		if allocation.CheckbookID == checkbookID {
+			copied := *allocation
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Seq < found[j].Seq })
+	return found, nil
+}
+
+func (r *fakeAllocationRepo) FindAvailable(ctx context.Context, checkbookID string) ([]*models.Check, error) {
+	all, err := r.FindByCheckbook(ctx, checkbookID)
+	if err != nil {
+		return nil, err
+	}
+	var idle []*models.Check
+	for _, allocation := range all {
+		if allocation.Status == models.AllocationStatusIdle {
+			idle = append(idle, allocation)
+		}
+	}
+	return idle, nil
+}
+
+func (r *fakeAllocationRepo) LockForWithdrawal(ctx context.Context, ids []string, withdrawRequestID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.lockErr != nil {
+		return r.store.lockErr
+	}
+	for _, id := range ids {
+		allocation, ok := r.store.allocations[id]
+		if !ok {
+			return repository.ErrAllocationLockConflict
+		}
+		switch {
+		case allocation.Status == models.AllocationStatusIdle:
+		case allocation.Status == models.AllocationStatusPending && allocation.WithdrawRequestID != nil && *allocation.WithdrawRequestID == withdrawRequestID:
+		default:
+			return repository.ErrAllocationLockConflict
+		}
+	}
+	for _, id := range ids {
+		requestID := withdrawRequestID
+		r.store.allocations[id].Status = models.AllocationStatusPending
+		r.store.allocations[id].WithdrawRequestID = &requestID
+	}
+	return nil
+}
+
+func (r *fakeAllocationRepo) MarkAsUsed(ctx context.Context, ids []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, id := range ids {
+		if allocation, ok := r.store.allocations[id]; ok && allocation.Status == models.AllocationStatusPending {
+			allocation.Status = models.AllocationStatusUsed
+		}
+	}
+	return nil
+}
+
+func (r *fakeAllocationRepo) ReleaseAllocations(ctx context.Context, ids []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, id := range ids {
+		if allocation, ok := r.store.allocations[id]; ok && allocation.Status == models.AllocationStatusPending {
+			allocation.Status = models.AllocationStatusIdle
+			allocation.WithdrawRequestID = nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeAllocationRepo) ReleaseByWithdrawRequest(ctx context.Context, withdrawRequestID string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.releaseErr != nil {
+		return 0, r.store.releaseErr
+	}
+	var released int64
+	for _, allocation := range r.store.allocations {
+		if allocation.WithdrawRequestID != nil && *allocation.WithdrawRequestID == withdrawRequestID &&
+			allocation.Status != models.AllocationStatusUsed {
+			allocation.Status = models.AllocationStatusIdle
+			allocation.WithdrawRequestID = nil
+			released++
+		}
+	}
+	return released, nil
+}
+
+func (r *fakeAllocationRepo) RestoreUsedByWithdrawRequest(ctx context.Context, withdrawRequestID string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var restored int64
+	for _, allocation := range r.store.allocations {
+		if allocation.WithdrawRequestID != nil && *allocation.WithdrawRequestID == withdrawRequestID &&
+			allocation.Status == models.AllocationStatusUsed {
+			allocation.Status = models.AllocationStatusPending
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+// fakeCheckbookRepo CheckbookRepository over a fakeStore
+type fakeCheckbookRepo struct {
+	repository.CheckbookRepository
+	store *fakeStore
+}
+
+func (r *fakeCheckbookRepo) GetByID(ctx context.Context, id string) (*models.Checkbook, error) {
+	found, err := r.GetByIDs(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+func (r *fakeCheckbookRepo) GetByIDs(ctx context.Context, ids []string) ([]*models.Checkbook, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.checkbookQueries++
+	found := make([]*models.Checkbook, 0, len(ids))
+	for _, id := range ids {
+		checkbook, ok := r.store.checkbooks[id]
+		if !ok {
+			return nil, fmt.Errorf("checkbook %s: %w", id, gorm.ErrRecordNotFound)
+		}
+		copied := *checkbook
+		found = append(found, &copied)
+	}
+	return found, nil
+}
+
+func (r *fakeCheckbookRepo) FindByOwner(ctx context.Context, ownerChainID uint32, ownerData string) ([]*models.Checkbook, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var found []*models.Checkbook
+	for _, checkbook := range r.store.checkbooks {
+		if checkbook.UserAddress.SLIP44ChainID == ownerChainID && checkbook.UserAddress.Data == ownerData {
+			copied := *checkbook
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+// fakeTransactor runs transactions one at a time over a fakeStore, restoring it when fn fails
+type fakeTransactor struct {
+	store *fakeStore
+	txMu  sync.Mutex
+}
+
+func (t *fakeTransactor) InTransaction(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	t.txMu.Lock()
+	defer t.txMu.Unlock()
+
+	t.store.mu.Lock()
+	requests, allocations := t.store.snapshot()
+	t.store.mu.Unlock()
+
+	err := fn(repository.Repositories{
+		WithdrawRequests: &fakeWithdrawRepo{store: t.store},
+		Allocations:      &fakeAllocationRepo{store: t.store},
+		Checkbooks:       &fakeCheckbookRepo{store: t.store},
+	})
+	if err != nil {
+		t.store.mu.Lock()
+		t.store.restore(requests, allocations)
+		t.store.mu.Unlock()
+	}
+	return err
+}
+
+// newFakeWithdrawService a WithdrawRequestService over an in-memory store
+func newFakeWithdrawService(store *fakeStore) *WithdrawRequestService {
+	return &WithdrawRequestService{
+		withdrawRepo:   &fakeWithdrawRepo{store: store},
+		allocationRepo: &fakeAllocationRepo{store: store},
+		checkbookRepo:  &fakeCheckbookRepo{store: store},
+		transactor:     &fakeTransactor{store: store},
+		logger:         logging.New(logging.FormatText),
+		tasks:          NewBackgroundTasks(),
+
+		maxAllocationsPerWithdraw: 10,
+		maxCheckbooksPerWithdraw:  10,
+	}
+}
+
+// addIdleAllocations stores a USDT checkbook owned by owner with one idle allocation per amount,
+// allocation IDs "<checkbookID>-<n>" with nullifiers "0x<checkbookID>-<n>"
+func (s *fakeStore) addIdleAllocations(checkbookID, owner string, amounts ...string) []string {
+	s.checkbooks[checkbookID] = &models.Checkbook{
+		ID:                checkbookID,
+		UserAddress:       models.UniversalAddress{SLIP44ChainID: 60, Data: owner},
+		TokenKey:          "USDT",
+		AllocatableAmount: "1000000",
+	}
+	ids := make([]string, 0, len(amounts))
+	for i, amount := range amounts {
+		id := fmt.Sprintf("%s-%d", checkbookID, i+1)
+		s.allocations[id] = &models.Check{
+			ID:          id,
+			CheckbookID: checkbookID,
+			Seq:         uint8(i),
+			Amount:      amount,
+			Status:      models.AllocationStatusIdle,
+			Nullifier:   "0x" + id,
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// addPendingRequest stores a request locking one pending allocation per amount (nullifier "0x<n>" for allocation "a<n>")
+func (s *fakeStore) addPendingRequest(id string, amounts ...string) *models.WithdrawRequest {
+	ids := make([]string, 0, len(amounts))
+	for i, amount := range amounts {
+		allocationID := fmt.Sprintf("a%d", i+1)
+		requestID := id
+		s.allocations[allocationID] = &models.Check{
+			ID:                allocationID,
+			CheckbookID:       "cb1",
+			Seq:               uint8(i),
+			Amount:            amount,
+			Status:            models.AllocationStatusPending,
+			Nullifier:         fmt.Sprintf("0x%d", i+1),
+			WithdrawRequestID: &requestID,
+		}
+		ids = append(ids, fmt.Sprintf("%q", allocationID))
+	}
+	request := &models.WithdrawRequest{
+		ID:                id,
+		WithdrawNullifier: "0x1",
+		AllocationIDs:     "[" + strings.Join(ids, ",") + "]",
+		Status:            string(models.WithdrawStatusCreated),
+		ProofStatus:       models.ProofStatusPending,
+		ExecuteStatus:     models.ExecuteStatusPending,
+		PayoutStatus:      models.PayoutStatusPending,
+		HookStatus:        models.HookStatusNotRequired,
+		Signature:         "0xsig",
+		Version:           1,
+	}
+	s.requests[id] = request
+	copied := *request
+	return &copied
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestCreateWithdrawRequestStoresHookCalldata(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100")
+	service := newFakeWithdrawService(store)
+
+	input := idempotentCreateInput("", ids...)
+	input.Intent.HookCalldata = " 0xA9059CBB00ff "
+	created, err := service.CreateWithdrawRequest(context.Background(), input)
+	if err != nil {
+		t.Fatalf("CreateWithdrawRequest: %v", err)
+	}
+
+	got, err := service.GetWithdrawRequest(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("GetWithdrawRequest: %v", err)
+	}
+	if got.HookCalldata != "0xa9059cbb00ff" {
+		t.Errorf("hook_calldata = %q, want 0xa9059cbb00ff", got.HookCalldata)
+	}
+	if got.HookStatus != models.HookStatusPending {
+		t.Errorf("hook_status = %s, want pending for a request with a hook", got.HookStatus)
+	}
+}
+
+func TestCreateWithdrawRequestWithoutHook(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100")
+	service := newFakeWithdrawService(store)
+
+	created, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...))
+	if err != nil {
+		t.Fatalf("CreateWithdrawRequest: %v", err)
+	}
+	got := store.request(created.ID)
+	if got.HookCalldata != "" || got.HookStatus != models.HookStatusNotRequired {
+		t.Errorf("hook_calldata = %q, hook_status = %s, want empty and not_required", got.HookCalldata, got.HookStatus)
+	}
+
+	// Stage 4 refuses to run without calldata
+	store.requests[created.ID].PayoutStatus = models.PayoutStatusCompleted
+	if err := service.ProcessHook(context.Background(), created.ID); !errors.Is(err, ErrHookCalldataMissing) {
+		t.Errorf("ProcessHook: %v, want ErrHookCalldataMissing", err)
+	}
+	if got := store.request(created.ID).HookStatus; got != models.HookStatusNotRequired {
+		t.Errorf("hook_status = %s after the refused hook, want not_required", got)
+	}
+}
+
+func TestCreateWithdrawRequestRejectsInvalidHookCalldata(t *testing.T) {
+	for _, calldata := range []string{"0x", "0xzz", "0xabc", "not hex"} {
+		t.Run(calldata, func(t *testing.T) {
+			store := newFakeStore()
+			ids := store.addIdleAllocations("cb1", "0xowner", "100")
+			service := newFakeWithdrawService(store)
+
+			input := idempotentCreateInput("", ids...)
+			input.Intent.HookCalldata = calldata
+			if _, err := service.CreateWithdrawRequest(context.Background(), input); !errors.Is(err, ErrInvalidIntent) {
+				t.Errorf("CreateWithdrawRequest: %v, want ErrInvalidIntent", err)
+			}
+			if len(store.requests) != 0 {
+				t.Errorf("%d requests stored", len(store.requests))
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/models"
+)
+
+// newStubZKVMClient a ZKVM client whose withdraw proofs carry the given nullifiers
+func newStubZKVMClient(t *testing.T, nullifiers []string) *clients.ZKVMClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/proof/withdraw" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(clients.BuildWithdrawResponse{
+			Success:      true,
+			ProofData:    "0xproof",
+			PublicValues: "0xpublic",
+			Nullifiers:   nullifiers,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return &clients.ZKVMClient{BaseURL: server.URL, Client: server.Client()}
+}
+
+// addProofRequest stores a request waiting for its proof that locks the given allocations, withdraw_nullifier
+// set to the first allocation's nullifier; their checkbooks get an EVM owner address
+func addProofRequest(t *testing.T, store *fakeStore, id string, allocationIDs []string) {
+	t.Helper()
+	for _, allocationID := range allocationIDs {
+		allocation := store.allocations[allocationID]
+		requestID := id
+		allocation.Status = models.AllocationStatusPending
+		allocation.WithdrawRequestID = &requestID
+		store.checkbooks[allocation.CheckbookID].UserAddress.Data = "0x00000000000000000000000000000000000000aa"
+	}
+	encoded, err := json.Marshal(allocationIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.requests[id] = &models.WithdrawRequest{
+		ID:                id,
+		WithdrawNullifier: store.allocations[allocationIDs[0]].Nullifier,
+		AllocationIDs:     string(encoded),
+		Recipient:         models.UniversalAddress{SLIP44ChainID: 60, Data: "0x00000000000000000000000000000000000000bb"},
+		Status:            string(models.WithdrawStatusCreated),
+		ProofStatus:       models.ProofStatusPending,
+		ExecuteStatus:     models.ExecuteStatusPending,
+		PayoutStatus:      models.PayoutStatusPending,
+		HookStatus:        models.HookStatusNotRequired,
+		Version:           1,
+	}
+}
+
+func TestAutoGenerateProofNullifierCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		strict       bool
+		nullifiers   []string
+		wantStatus   models.ProofStatus
+		wantMismatch bool
+	}{
+		{"matching nullifiers, strict", true, []string{"0xCB-1", "0xcb-2"}, models.ProofStatusCompleted, false},
+		{"mismatch, lenient", false, []string{"0xcb-1", "0xother"}, models.ProofStatusCompleted, true},
+		{"mismatch, strict", true, []string{"0xcb-1", "0xother"}, models.ProofStatusFailed, true},
+		{"count mismatch, strict", true, []string{"0xcb-1"}, models.ProofStatusFailed, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			ids := store.addCommittedCheckbook("cb", 1, "100", "200")
+			addProofRequest(t, store, "wr", ids)
+			service := newFakeWithdrawService(store)
+			service.queueRootRepo = &fakeQueueRootRepo{}
+			service.zkvmClient = newStubZKVMClient(t, tt.nullifiers)
+			service.strictNullifierCheck = tt.strict
+
+			service.autoGenerateProofWithSignature(context.Background(), "wr", "0xsig", 60)
+
+			request := store.request("wr")
+			if request.ProofStatus != tt.wantStatus {
+				t.Fatalf("proof_status = %s (%s), want %s", request.ProofStatus, request.ProofError, tt.wantStatus)
+			}
+			if request.NullifierMismatch != tt.wantMismatch {
+				t.Errorf("nullifier_mismatch = %v, want %v", request.NullifierMismatch, tt.wantMismatch)
+			}
+			if tt.wantStatus == models.ProofStatusFailed {
+				if !strings.Contains(request.ProofError, "strict_nullifier_check") {
+					t.Errorf("proof_error = %q, want the strict_nullifier_check reason", request.ProofError)
+				}
+				if request.Proof != "" {
+					t.Errorf("proof = %q, want none stored", request.Proof)
+				}
+			} else if request.Proof != "0xproof" {
+				t.Errorf("proof = %q, want 0xproof", request.Proof)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/config"
+)
+
+func TestCreateWithdrawRequestStoresProofLang(t *testing.T) {
+	for _, lang := range []uint8{0, 1, 2} {
+		store := newFakeStore()
+		ids := store.addIdleAllocations("cb1", "0xowner", "100")
+		service := newFakeWithdrawService(store)
+
+		input := idempotentCreateInput("", ids...)
+		input.Lang = lang
+		request, err := service.CreateWithdrawRequest(context.Background(), input)
+		if err != nil {
+			t.Fatalf("CreateWithdrawRequest lang=%d: %v", lang, err)
+		}
+		// The stored language is what proof generation (and its retries) sends to the ZKVM
+		if got := store.request(request.ID).ProofLang; got != lang {
+			t.Errorf("proof_lang = %d, want %d from the input", got, lang)
+		}
+	}
+}
+
+func TestChainDisplayName(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig = &config.Config{Blockchain: config.BlockchainConfig{Networks: map[string]config.NetworkConfig{
+		"bsc":      {ChainID: 714, Enabled: true, Name: "BSC Mainnet"},
+		"ethereum": {ChainID: 60, Enabled: false, Name: "Ethereum"},
+		"tron":     {ChainID: 195, Enabled: true},
+	}}}
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	if name := chainDisplayName(714); name == nil || *name != "BSC Mainnet" {
+		t.Errorf("chainDisplayName(714) = %v, want BSC Mainnet", name)
+	}
+	for _, chainID := range []uint32{60, 195, 966} { // disabled, unnamed, not configured
+		if name := chainDisplayName(chainID); name != nil {
+			t.Errorf("chainDisplayName(%d) = %q, want nil", chainID, *name)
+		}
+	}
+}
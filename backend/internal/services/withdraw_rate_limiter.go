@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxIdleRateLimitBuckets buckets kept before full (idle) ones are dropped
+const maxIdleRateLimitBuckets = 10000
+
+// WithdrawRateLimiter decides whether an owner may create another withdraw request
+// Implementations must be safe for concurrent use. TokenBucketRateLimiter keeps its buckets in memory, so each
+// backend instance limits on its own; a shared implementation (e.g. Redis) can be set with SetRateLimiter.
+type WithdrawRateLimiter interface {
+	// Allow consumes one request of ownerKey's budget, false when the owner is over the limit
+	Allow(ctx context.Context, ownerKey string) bool
+}
+
+// tokenBucket the remaining budget of one owner
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucketRateLimiter in-memory WithdrawRateLimiter: one bucket of requestsPerMinute tokens per owner,
+// refilled continuously at requestsPerMinute per minute
+type TokenBucketRateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	perSec   float64
+	buckets  map[string]*tokenBucket
+	now      func() time.Time
+}
+
+// NewTokenBucketRateLimiter creates a TokenBucketRateLimiter allowing requestsPerMinute requests per owner per minute
+// (bursts up to requestsPerMinute); requestsPerMinute must be positive
+func NewTokenBucketRateLimiter(requestsPerMinute int) *TokenBucketRateLimiter {
+	return &TokenBucketRateLimiter{
+		capacity: float64(requestsPerMinute),
+		perSec:   float64(requestsPerMinute) / 60,
+		buckets:  make(map[string]*tokenBucket),
+		now:      time.Now,
+	}
+}
+
+// Allow implements WithdrawRateLimiter
+func (l *TokenBucketRateLimiter) Allow(_ context.Context, ownerKey string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.buckets[ownerKey]
+	if !ok {
+		if len(l.buckets) >= maxIdleRateLimitBuckets {
+			l.dropFullBuckets(now)
+		}
+		bucket = &tokenBucket{tokens: l.capacity, last: now}
+		l.buckets[ownerKey] = bucket
+	} else {
+		bucket.tokens = min(l.capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*l.perSec)
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// dropFullBuckets forgets owners whose bucket has refilled completely, they start full again on their next request
+func (l *TokenBucketRateLimiter) dropFullBuckets(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.perSec >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// newConfiguredWithdrawRateLimiter builds the limiter of withdraw.create_requests_per_minute, nil when unlimited
+func newConfiguredWithdrawRateLimiter(requestsPerMinute int) WithdrawRateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return NewTokenBucketRateLimiter(requestsPerMinute)
+}
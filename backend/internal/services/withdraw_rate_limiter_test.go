@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRateLimiterAllowsUnderLimitAndRejectsOver(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(3)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow(ctx, "60:0xalice") {
+			t.Fatalf("request %d under the limit rejected", i+1)
+		}
+	}
+	if limiter.Allow(ctx, "60:0xalice") {
+		t.Fatal("request over the limit allowed")
+	}
+	if !limiter.Allow(ctx, "60:0xbob") {
+		t.Error("another owner limited by alice's budget")
+	}
+
+	// One token refills every 20s at 3 per minute
+	now = now.Add(20 * time.Second)
+	if !limiter.Allow(ctx, "60:0xalice") {
+		t.Error("request after a refill rejected")
+	}
+	if limiter.Allow(ctx, "60:0xalice") {
+		t.Error("refill granted more than one token")
+	}
+}
+
+func TestCreateWithdrawRequestRateLimitedPerOwner(t *testing.T) {
+	store := newFakeStore()
+	first := store.addIdleAllocations("cb-alice-1", "0xalice", "100")
+	second := store.addIdleAllocations("cb-alice-2", "0xalice", "100")
+	bob := store.addIdleAllocations("cb-bob", "0xbob", "100")
+	service := newFakeWithdrawService(store)
+	service.SetRateLimiter(NewTokenBucketRateLimiter(1))
+	ctx := context.Background()
+
+	if _, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", first...)); err != nil {
+		t.Fatalf("create under the limit: %v", err)
+	}
+	if _, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", second...)); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("create over the limit: err = %v, want ErrRateLimited", err)
+	}
+	if _, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", bob...)); err != nil {
+		t.Errorf("other owner: %v", err)
+	}
+	if len(store.requests) != 2 {
+		t.Errorf("%d requests stored, want 2", len(store.requests))
+	}
+}
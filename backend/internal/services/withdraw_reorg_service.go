@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+)
+
+// errExecuteNotReorged returned from the Modify callback when the request no longer needs a downgrade
+var errExecuteNotReorged = errors.New("execute status no longer eligible for reorg downgrade")
+
+// reorgChainClient the chain reads the reorg check needs (satisfied by *ethclient.Client)
+type reorgChainClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// WithdrawReorgService re-checks recently confirmed executeWithdraw transactions and reverts them when a reorg dropped them
+// A request counts as reorged when the receipt's block is no longer the canonical block at that height, or when its
+// receipt is gone and the transaction is either unknown to the node or has stayed without a receipt for
+// reorgMissingBlocks blocks (a single missing receipt can be a lagging RPC node). A transaction re-included in another
+// block only has its execute_block_number updated, unless it reverted there.
+type WithdrawReorgService struct {
+	db                *gorm.DB
+	withdrawRepo      repository.WithdrawRequestRepository
+	transactor        repository.Transactor
+	blockchainService *BlockchainTransactionService
+	running           bool
+	stopCh            chan struct{}
+	checkInterval     time.Duration
+	missingSince      map[string]uint64 // tx hash -> head block at which its receipt was first found missing
+}
+
+// NewWithdrawReorgService creates a new WithdrawReorgService
+func NewWithdrawReorgService(db *gorm.DB, withdrawRepo repository.WithdrawRequestRepository, transactor repository.Transactor, blockchainService *BlockchainTransactionService) *WithdrawReorgService {
+	return &WithdrawReorgService{
+		db:                db,
+		withdrawRepo:      withdrawRepo,
+		transactor:        transactor,
+		blockchainService: blockchainService,
+		stopCh:            make(chan struct{}),
+		checkInterval:     60 * time.Second, // Check every minute
+		missingSince:      make(map[string]uint64),
+	}
+}
+
+// Start begins the reorg check loop
+func (s *WithdrawReorgService) Start() {
+	if s.running {
+		return
+	}
+	s.running = true
+
+	log.Printf("🚀 Starting WithdrawReorgService (check interval: %v)", s.checkInterval)
+
+	go s.reorgCheckLoop()
+
+	log.Printf("✅ WithdrawReorgService started")
+}
+
+// Stop gracefully stops the reorg check loop
+func (s *WithdrawReorgService) Stop() {
+	if !s.running {
+		return
+	}
+	s.running = false
+	close(s.stopCh)
+	log.Printf("🛑 WithdrawReorgService stopped")
+}
+
+// reorgCheckLoop periodically checks confirmed executeWithdraw transactions for reorgs
+func (s *WithdrawReorgService) reorgCheckLoop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.checkReorgs()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// checkReorgs checks every configured chain that has a client
+func (s *WithdrawReorgService) checkReorgs() {
+	ctx := context.Background()
+	cfg := config.AppConfig
+	if cfg == nil || s.blockchainService == nil {
+		return
+	}
+
+	reverted := 0
+	for name, networkConfig := range cfg.Blockchain.Networks {
+		if !networkConfig.Enabled {
+			continue
+		}
+		client, exists := s.blockchainService.GetClient(networkConfig.ChainID)
+		if !exists {
+			continue
+		}
+		count, err := s.checkChain(ctx, client, networkConfig)
+		if err != nil {
+			log.Printf("❌ [WithdrawReorg] Failed to check %s (chain %d): %v", name, networkConfig.ChainID, err)
+			continue
+		}
+		reverted += count
+	}
+
+	if reverted > 0 {
+		log.Printf("✅ [WithdrawReorg] Reverted %d reorged executeWithdraw transaction(s)", reverted)
+	}
+}
+
+// checkChain re-fetches receipts of success requests executed within the chain's reorg window
+func (s *WithdrawReorgService) checkChain(ctx context.Context, client reorgChainClient, networkConfig config.NetworkConfig) (int, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	window := networkConfig.GetReorgWindowBlocks()
+	var fromBlock uint64
+	if head > window {
+		fromBlock = head - window
+	}
+
+	var requests []models.WithdrawRequest
+	err = s.db.WithContext(ctx).
+		Where("execute_status = ? AND execute_chain_id = ? AND execute_block_number >= ?",
+			models.ExecuteStatusSuccess, networkConfig.ChainID, fromBlock).
+		Find(&requests).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to query executed requests: %w", err)
+	}
+	return s.checkRequests(ctx, client, head, networkConfig.GetReorgMissingBlocks(), requests), nil
+}
+
+// checkRequests reverts the requests whose executeWithdraw transaction was reorged, returning how many were reverted
+func (s *WithdrawReorgService) checkRequests(ctx context.Context, client reorgChainClient, head, missingBlocks uint64, requests []models.WithdrawRequest) int {
+	count := 0
+	for _, request := range requests {
+		if request.ExecuteTxHash == "" {
+			continue
+		}
+		reason, err := s.detectReorg(ctx, client, request, head, missingBlocks)
+		if err != nil {
+			log.Printf("⚠️ [WithdrawReorg] Failed to check request %s (tx=%s): %v", request.ID, request.ExecuteTxHash, err)
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+		if s.revertExecute(ctx, request, reason) {
+			delete(s.missingSince, request.ExecuteTxHash)
+			count++
+		}
+	}
+	return count
+}
+
+// detectReorg returns a non-empty reason when the request's executeWithdraw transaction is no longer canonical
+func (s *WithdrawReorgService) detectReorg(ctx context.Context, client reorgChainClient, request models.WithdrawRequest, head, missingBlocks uint64) (string, error) {
+	txHash := common.HexToHash(request.ExecuteTxHash)
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return s.detectMissingReceipt(ctx, client, request.ExecuteTxHash, head, missingBlocks)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get receipt: %w", err)
+	}
+	delete(s.missingSince, request.ExecuteTxHash)
+
+	header, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return "", fmt.Errorf("failed to get header %s: %w", receipt.BlockNumber, err)
+	}
+	if header.Hash() != receipt.BlockHash {
+		return fmt.Sprintf("receipt block %s (%s) is no longer canonical", receipt.BlockNumber, receipt.BlockHash.Hex()), nil
+	}
+	if request.ExecuteBlockNumber != nil && *request.ExecuteBlockNumber != receipt.BlockNumber.Uint64() {
+		// Re-included in another block: still executed unless it reverted there
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return fmt.Sprintf("transaction moved from block %d to block %s and reverted", *request.ExecuteBlockNumber, receipt.BlockNumber), nil
+		}
+		if err := s.moveExecuteBlock(ctx, request, receipt.BlockNumber.Uint64()); err != nil {
+			return "", fmt.Errorf("failed to record block %s: %w", receipt.BlockNumber, err)
+		}
+	}
+	return "", nil
+}
+
+// moveExecuteBlock records the block a successful executeWithdraw transaction was re-included in
+func (s *WithdrawReorgService) moveExecuteBlock(ctx context.Context, request models.WithdrawRequest, blockNumber uint64) error {
+	_, err := s.withdrawRepo.Modify(ctx, request.ID, func(fresh *models.WithdrawRequest) error {
+		if fresh.ExecuteStatus != models.ExecuteStatusSuccess || fresh.ExecuteTxHash != request.ExecuteTxHash {
+			return errExecuteNotReorged
+		}
+		fresh.ExecuteBlockNumber = &blockNumber
+		return nil
+	})
+	if errors.Is(err, errExecuteNotReorged) {
+		return nil
+	}
+	if err == nil {
+		log.Printf("⚠️ [WithdrawReorg] Request %s re-included in block %d after a reorg (tx=%s)", request.ID, blockNumber, request.ExecuteTxHash)
+	}
+	return err
+}
+
+// detectMissingReceipt decides whether a transaction without a receipt was reorged out
+// The transaction must be unknown to the node (dropped from the mempool too), or its receipt must have stayed
+// missing for missingBlocks blocks; until then the head it was first found missing at is remembered.
+func (s *WithdrawReorgService) detectMissingReceipt(ctx context.Context, client reorgChainClient, txHash string, head, missingBlocks uint64) (string, error) {
+	_, isPending, err := client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return "receipt and transaction no longer found", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	since, seen := s.missingSince[txHash]
+	if !seen {
+		s.missingSince[txHash] = head
+		return "", nil
+	}
+	if head-since < missingBlocks {
+		return "", nil
+	}
+	return fmt.Sprintf("receipt missing for %d blocks (transaction pending=%t)", head-since, isPending), nil
+}
+
+// revertExecute downgrades execute_status to submit_failed so the withdraw can be retried
+// The request's used allocations go back to pending in the same transaction, their nullifiers no longer being
+// consumed on-chain. Requests whose payout has already started are only logged: funds may have moved on the target chain.
+func (s *WithdrawReorgService) revertExecute(ctx context.Context, request models.WithdrawRequest, reason string) bool {
+	var restored int64
+	err := s.transactor.InTransaction(ctx, func(repos repository.Repositories) error {
+		_, err := repos.WithdrawRequests.Modify(ctx, request.ID, func(fresh *models.WithdrawRequest) error {
+			if fresh.ExecuteStatus != models.ExecuteStatusSuccess || fresh.ExecuteTxHash != request.ExecuteTxHash {
+				return errExecuteNotReorged
+			}
+			if fresh.PayoutStatus != models.PayoutStatusPending {
+				return fmt.Errorf("payout already %s, manual review required", fresh.PayoutStatus)
+			}
+			fresh.ExecuteStatus = models.ExecuteStatusSubmitFailed
+			fresh.ExecuteBlockNumber = nil
+			fresh.ExecutedAt = nil
+			fresh.ExecuteError = "reorg: " + reason
+			fresh.UpdateMainStatus()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		restored, err = repos.Allocations.RestoreUsedByWithdrawRequest(ctx, request.ID)
+		return err
+	})
+	if errors.Is(err, errExecuteNotReorged) {
+		return false
+	}
+	if err != nil {
+		log.Printf("❌ [WithdrawReorg] Request %s (tx=%s) was reorged (%s) but could not be reverted: %v",
+			request.ID, request.ExecuteTxHash, reason, err)
+		return false
+	}
+
+	log.Printf("⚠️ [WithdrawReorg] Request %s reverted to execute_status=%s, %d allocation(s) back to pending: %s (tx=%s)",
+		request.ID, models.ExecuteStatusSubmitFailed, restored, reason, request.ExecuteTxHash)
+	return true
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"go-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeReorgClient a chain without receipts; the transaction is pending when known, unknown otherwise
+type fakeReorgClient struct {
+	txKnown bool
+}
+
+func (c *fakeReorgClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (c *fakeReorgClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeReorgClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if !c.txKnown {
+		return nil, false, ethereum.NotFound
+	}
+	return &types.Transaction{}, true, nil
+}
+
+func (c *fakeReorgClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, ethereum.NotFound
+}
+
+// addExecutedRequest stores a request whose executeWithdraw succeeded in block 100, its allocations used
+func (s *fakeStore) addExecutedRequest(id string, amounts ...string) models.WithdrawRequest {
+	s.addPendingRequest(id, amounts...)
+	for _, allocation := range s.allocations {
+		allocation.Status = models.AllocationStatusUsed
+	}
+	block := uint64(100)
+	request := s.requests[id]
+	request.ProofStatus = models.ProofStatusCompleted
+	request.ExecuteStatus = models.ExecuteStatusSuccess
+	request.ExecuteTxHash = "0xabc"
+	request.ExecuteBlockNumber = &block
+	request.UpdateMainStatus()
+	return *request
+}
+
+func newFakeReorgService(store *fakeStore) *WithdrawReorgService {
+	return &WithdrawReorgService{
+		withdrawRepo: &fakeWithdrawRepo{store: store},
+		transactor:   &fakeTransactor{store: store},
+		missingSince: make(map[string]uint64),
+	}
+}
+
+func TestReorgWaitsForPendingTransactionWithoutReceipt(t *testing.T) {
+	store := newFakeStore()
+	request := store.addExecutedRequest("wr1", "100")
+	service := newFakeReorgService(store)
+	client := &fakeReorgClient{txKnown: true}
+	requests := []models.WithdrawRequest{request}
+
+	for _, head := range []uint64{110, 115, 121} {
+		if n := service.checkRequests(context.Background(), client, head, 12, requests); n != 0 {
+			t.Fatalf("head %d: reverted %d, want 0 while receipt missing for fewer than 12 blocks", head, n)
+		}
+	}
+	if stored := store.request("wr1"); stored.ExecuteStatus != models.ExecuteStatusSuccess {
+		t.Fatalf("execute_status = %s, want success", stored.ExecuteStatus)
+	}
+
+	if n := service.checkRequests(context.Background(), client, 122, 12, requests); n != 1 {
+		t.Fatalf("reverted %d, want 1 once receipt missing for 12 blocks", n)
+	}
+	if stored := store.request("wr1"); stored.ExecuteStatus != models.ExecuteStatusSubmitFailed {
+		t.Errorf("execute_status = %s, want submit_failed", stored.ExecuteStatus)
+	}
+}
+
+func TestReorgRevertsUnknownTransactionAndRestoresAllocations(t *testing.T) {
+	store := newFakeStore()
+	request := store.addExecutedRequest("wr1", "100", "200")
+	service := newFakeReorgService(store)
+
+	n := service.checkRequests(context.Background(), &fakeReorgClient{}, 110, 12, []models.WithdrawRequest{request})
+	if n != 1 {
+		t.Fatalf("reverted %d, want 1", n)
+	}
+	stored := store.request("wr1")
+	if stored.ExecuteStatus != models.ExecuteStatusSubmitFailed || stored.ExecuteBlockNumber != nil {
+		t.Errorf("execute_status = %s, block = %v, want submit_failed with no block", stored.ExecuteStatus, stored.ExecuteBlockNumber)
+	}
+	for _, id := range []string{"a1", "a2"} {
+		if a := store.allocation(id); a.Status != models.AllocationStatusPending || a.WithdrawRequestID == nil {
+			t.Errorf("%s status = %s, want pending for wr1", id, a.Status)
+		}
+	}
+}
+
+func TestReorgKeepsAllocationsWhenPayoutStarted(t *testing.T) {
+	store := newFakeStore()
+	request := store.addExecutedRequest("wr1", "100")
+	store.requests["wr1"].PayoutStatus = models.PayoutStatusProcessing
+	service := newFakeReorgService(store)
+
+	if n := service.checkRequests(context.Background(), &fakeReorgClient{}, 110, 12, []models.WithdrawRequest{request}); n != 0 {
+		t.Fatalf("reverted %d, want 0 once payout started", n)
+	}
+	if a := store.allocation("a1"); a.Status != models.AllocationStatusUsed {
+		t.Errorf("a1 status = %s, want used", a.Status)
+	}
+}
+
+// receiptReorgClient a chain where the transaction has a receipt and canonical is the header at the receipt's height
+type receiptReorgClient struct {
+	fakeReorgClient
+	receipt   *types.Receipt
+	canonical *types.Header
+}
+
+func (c *receiptReorgClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return c.receipt, nil
+}
+
+func (c *receiptReorgClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.canonical, nil
+}
+
+// newReceiptReorgClient a receipt in block number with the given status, canonical at that height
+func newReceiptReorgClient(number int64, status uint64) *receiptReorgClient {
+	header := &types.Header{Number: big.NewInt(number)}
+	return &receiptReorgClient{
+		receipt:   &types.Receipt{Status: status, BlockNumber: big.NewInt(number), BlockHash: header.Hash()},
+		canonical: header,
+	}
+}
+
+func TestReorgMovedSuccessfulTransactionUpdatesBlock(t *testing.T) {
+	store := newFakeStore()
+	request := store.addExecutedRequest("wr1", "100")
+	service := newFakeReorgService(store)
+	client := newReceiptReorgClient(105, types.ReceiptStatusSuccessful)
+
+	if n := service.checkRequests(context.Background(), client, 110, 12, []models.WithdrawRequest{request}); n != 0 {
+		t.Fatalf("reverted %d, want 0 for a transaction re-included successfully", n)
+	}
+	stored := store.request("wr1")
+	if stored.ExecuteStatus != models.ExecuteStatusSuccess || stored.ExecuteBlockNumber == nil || *stored.ExecuteBlockNumber != 105 {
+		t.Errorf("execute_status = %s, block = %v, want success in block 105", stored.ExecuteStatus, stored.ExecuteBlockNumber)
+	}
+	if a := store.allocation("a1"); a.Status != models.AllocationStatusUsed {
+		t.Errorf("a1 status = %s, want used", a.Status)
+	}
+}
+
+func TestReorgMovedRevertedTransactionRevertsExecute(t *testing.T) {
+	store := newFakeStore()
+	request := store.addExecutedRequest("wr1", "100")
+	service := newFakeReorgService(store)
+	client := newReceiptReorgClient(105, types.ReceiptStatusFailed)
+
+	if n := service.checkRequests(context.Background(), client, 110, 12, []models.WithdrawRequest{request}); n != 1 {
+		t.Fatalf("reverted %d, want 1 for a transaction that reverted in its new block", n)
+	}
+	if stored := store.request("wr1"); stored.ExecuteStatus != models.ExecuteStatusSubmitFailed {
+		t.Errorf("execute_status = %s, want submit_failed", stored.ExecuteStatus)
+	}
+	if a := store.allocation("a1"); a.Status != models.AllocationStatusPending {
+		t.Errorf("a1 status = %s, want pending", a.Status)
+	}
+}
+
+func TestReorgRevertsReceiptFromNonCanonicalBlock(t *testing.T) {
+	store := newFakeStore()
+	request := store.addExecutedRequest("wr1", "100")
+	service := newFakeReorgService(store)
+	client := newReceiptReorgClient(100, types.ReceiptStatusSuccessful)
+	client.canonical = &types.Header{Number: big.NewInt(100), Extra: []byte("other fork")}
+
+	if n := service.checkRequests(context.Background(), client, 110, 12, []models.WithdrawRequest{request}); n != 1 {
+		t.Fatalf("reverted %d, want 1 when the receipt's block is no longer canonical", n)
+	}
+	stored := store.request("wr1")
+	if stored.ExecuteStatus != models.ExecuteStatusSubmitFailed || !strings.Contains(stored.ExecuteError, "no longer canonical") {
+		t.Errorf("execute_status = %s (%q), want submit_failed for a non-canonical block", stored.ExecuteStatus, stored.ExecuteError)
+	}
+}
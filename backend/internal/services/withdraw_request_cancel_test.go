@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/models"
+)
+
+func TestCancelWithdrawRequestPartialReleasesSubset(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100", "200", "300")
+	store.requests["wr1"].ProofStatus = models.ProofStatusFailed
+	store.requests["wr1"].Status = string(models.WithdrawStatusProofFailed)
+	store.requests["wr1"].ProofError = "zkvm timeout"
+	service := newFakeWithdrawService(store)
+
+	if err := service.CancelWithdrawRequestPartial(context.Background(), "wr1", []string{"a1"}); err != nil {
+		t.Fatalf("CancelWithdrawRequestPartial: %v", err)
+	}
+
+	request := store.request("wr1")
+	if request.AllocationIDs != `["a2","a3"]` {
+		t.Errorf("allocation_ids = %s, want [\"a2\",\"a3\"]", request.AllocationIDs)
+	}
+	if request.Amount != "500" {
+		t.Errorf("amount = %s, want 500", request.Amount)
+	}
+	if request.WithdrawNullifier != "0x2" {
+		t.Errorf("withdraw_nullifier = %s, want the new first allocation's nullifier 0x2", request.WithdrawNullifier)
+	}
+	if request.ProofStatus != models.ProofStatusPending || request.ProofError != "" || request.Signature != "" {
+		t.Errorf("proof state not reset: proof_status=%s proof_error=%q signature=%q", request.ProofStatus, request.ProofError, request.Signature)
+	}
+	if a := store.allocation("a1"); a.Status != models.AllocationStatusIdle || a.WithdrawRequestID != nil {
+		t.Errorf("a1 not released: status=%s", a.Status)
+	}
+	if a := store.allocation("a2"); a.Status != models.AllocationStatusPending {
+		t.Errorf("a2 status = %s, want pending", a.Status)
+	}
+}
+
+func TestCancelWithdrawRequestPartialRefusedOnceProofStarted(t *testing.T) {
+	for _, proofStatus := range []models.ProofStatus{models.ProofStatusInProgress, models.ProofStatusCompleted} {
+		t.Run(string(proofStatus), func(t *testing.T) {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100", "200")
+			store.requests["wr1"].ProofStatus = proofStatus
+			store.requests["wr1"].Proof = "0xproof"
+			service := newFakeWithdrawService(store)
+
+			err := service.CancelWithdrawRequestPartial(context.Background(), "wr1", []string{"a1"})
+			if !errors.Is(err, ErrCannotPartialCancel) {
+				t.Fatalf("err = %v, want ErrCannotPartialCancel", err)
+			}
+			if request := store.request("wr1"); request.AllocationIDs != `["a1","a2"]` || request.Proof != "0xproof" {
+				t.Errorf("request changed: allocation_ids=%s proof=%s", request.AllocationIDs, request.Proof)
+			}
+			if a := store.allocation("a1"); a.Status != models.AllocationStatusPending {
+				t.Errorf("a1 status = %s, want pending", a.Status)
+			}
+		})
+	}
+}
+
+func TestResignAfterPartialCancelAllowsProofRetry(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100", "200")
+	store.requests["wr1"].Signature = "0xold"
+	service := newFakeWithdrawService(store)
+	service.zkvmClient = &clients.ZKVMClient{}
+	// No background proof generation in tests: the drained task group refuses new tasks
+	if err := service.tasks.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := service.CancelWithdrawRequestPartial(ctx, "wr1", []string{"a1"}); err != nil {
+		t.Fatalf("CancelWithdrawRequestPartial: %v", err)
+	}
+	if err := service.RetryProofGeneration(ctx, "wr1"); !errors.Is(err, ErrSignatureNotStored) {
+		t.Fatalf("retry before re-sign: err = %v, want ErrSignatureNotStored", err)
+	}
+
+	if err := service.ResignWithdrawRequest(ctx, "wr1", "0xnew", 714); err != nil {
+		t.Fatalf("ResignWithdrawRequest: %v", err)
+	}
+	request := store.request("wr1")
+	if request.Signature != "0xnew" || request.SignatureChainID != 714 || request.ProofStatus != models.ProofStatusPending {
+		t.Errorf("signature=%q chain=%d proof_status=%s, want 0xnew/714/pending", request.Signature, request.SignatureChainID, request.ProofStatus)
+	}
+	if err := service.RetryProofGeneration(ctx, "wr1"); err != nil {
+		t.Errorf("retry after re-sign: %v", err)
+	}
+
+	store.requests["wr1"].ProofStatus = models.ProofStatusInProgress
+	if err := service.ResignWithdrawRequest(ctx, "wr1", "0xother", 714); !errors.Is(err, ErrCannotResign) {
+		t.Errorf("re-sign during proof generation: err = %v, want ErrCannotResign", err)
+	}
+	if got := store.request("wr1").Signature; got != "0xnew" {
+		t.Errorf("signature = %q, want 0xnew kept", got)
+	}
+}
+
+func TestCancelWithdrawRequestPartialAllAllocationsCancelsRequest(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100", "200")
+	service := newFakeWithdrawService(store)
+
+	if err := service.CancelWithdrawRequestPartial(context.Background(), "wr1", []string{"a2", "a1"}); err != nil {
+		t.Fatalf("CancelWithdrawRequestPartial: %v", err)
+	}
+	if request := store.request("wr1"); request.Status != string(models.WithdrawStatusCancelled) {
+		t.Errorf("status = %s, want cancelled", request.Status)
+	}
+	for _, id := range []string{"a1", "a2"} {
+		if a := store.allocation(id); a.Status != models.AllocationStatusIdle {
+			t.Errorf("%s status = %s, want idle", id, a.Status)
+		}
+	}
+}
+
+func TestCancelWithdrawRequestPartialUnknownAllocation(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100", "200")
+	service := newFakeWithdrawService(store)
+
+	err := service.CancelWithdrawRequestPartial(context.Background(), "wr1", []string{"a1", "other"})
+	if !errors.Is(err, ErrAllocationNotInRequest) {
+		t.Fatalf("err = %v, want ErrAllocationNotInRequest", err)
+	}
+	if a := store.allocation("a1"); a.Status != models.AllocationStatusPending {
+		t.Errorf("a1 released although the request was refused")
+	}
+}
+
+func TestCancelWithdrawRequestRollsBackWhenReleaseFails(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100", "200")
+	store.releaseErr = errors.New("connection reset")
+	service := newFakeWithdrawService(store)
+
+	if err := service.CancelWithdrawRequest(context.Background(), "wr1"); err == nil {
+		t.Fatal("CancelWithdrawRequest succeeded, want the release error")
+	}
+	if request := store.request("wr1"); request.Status != string(models.WithdrawStatusCreated) {
+		t.Errorf("status = %s, want the cancel rolled back to created", request.Status)
+	}
+	for _, id := range []string{"a1", "a2"} {
+		if a := store.allocation(id); a.Status != models.AllocationStatusPending {
+			t.Errorf("%s status = %s, want pending", id, a.Status)
+		}
+	}
+}
+
+func TestCancelWithdrawRequestRacesExecuteSubmission(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		store := newFakeStore()
+		store.addPendingRequest("wr1", "100", "200")
+		store.requests["wr1"].ProofStatus = models.ProofStatusCompleted
+		service := newFakeWithdrawService(store)
+
+		var wg sync.WaitGroup
+		var cancelErr, claimErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cancelErr = service.CancelWithdrawRequest(context.Background(), "wr1")
+		}()
+		go func() {
+			defer wg.Done()
+			claimErr = service.claimExecuteSubmission(context.Background(), "wr1")
+		}()
+		wg.Wait()
+
+		request := store.request("wr1")
+		switch {
+		case cancelErr == nil && claimErr == nil:
+			t.Fatalf("both cancel and execute won: status=%s execute_status=%s", request.Status, request.ExecuteStatus)
+		case cancelErr == nil:
+			if !errors.Is(claimErr, ErrWithdrawRequestClosed) {
+				t.Fatalf("claim err = %v, want ErrWithdrawRequestClosed", claimErr)
+			}
+			if request.ExecuteStatus != models.ExecuteStatusPending {
+				t.Fatalf("execute_status = %s after cancel won, want pending", request.ExecuteStatus)
+			}
+			for _, id := range []string{"a1", "a2"} {
+				if a := store.allocation(id); a.Status != models.AllocationStatusIdle {
+					t.Fatalf("%s status = %s after cancel won, want idle", id, a.Status)
+				}
+			}
+		case claimErr == nil:
+			if !errors.Is(cancelErr, ErrCannotCancel) {
+				t.Fatalf("cancel err = %v, want ErrCannotCancel", cancelErr)
+			}
+			if request.Status == string(models.WithdrawStatusCancelled) {
+				t.Fatal("request cancelled after execute claimed it")
+			}
+			for _, id := range []string{"a1", "a2"} {
+				if a := store.allocation(id); a.Status != models.AllocationStatusPending {
+					t.Fatalf("%s status = %s after execute won, want pending", id, a.Status)
+				}
+			}
+		default:
+			t.Fatalf("neither won: cancel err = %v, claim err = %v", cancelErr, claimErr)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func idempotentCreateInput(key string, allocationIDs ...string) *CreateWithdrawRequestInput {
+	return &CreateWithdrawRequestInput{
+		AllocationIDs: allocationIDs,
+		Intent: models.Intent{
+			Beneficiary: models.UniversalAddress{SLIP44ChainID: 60, Data: "0xbeneficiary"},
+		},
+		Signature:      "0xsig",
+		ChainID:        60,
+		IdempotencyKey: key,
+	}
+}
+
+func TestCreateWithdrawRequestConcurrentSameKey(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100", "200")
+	service := newFakeWithdrawService(store)
+
+	const callers = 8
+	results := make([]*models.WithdrawRequest, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("key-1", ids...))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %v", i, errs[i])
+		}
+		if results[i].ID != results[0].ID {
+			t.Errorf("caller %d got request %s, caller 0 got %s", i, results[i].ID, results[0].ID)
+		}
+	}
+	if len(store.requests) != 1 {
+		t.Errorf("%d requests stored, want 1", len(store.requests))
+	}
+	for _, id := range ids {
+		if a := store.allocation(id); a.Status != models.AllocationStatusPending || *a.WithdrawRequestID != results[0].ID {
+			t.Errorf("allocation %s: status=%s, want pending for %s", id, a.Status, results[0].ID)
+		}
+	}
+}
+
+func TestCreateWithdrawRequestKeyScopedToOwner(t *testing.T) {
+	store := newFakeStore()
+	aliceIDs := store.addIdleAllocations("cb-alice", "0xalice", "100")
+	bobIDs := store.addIdleAllocations("cb-bob", "0xbob", "100")
+	service := newFakeWithdrawService(store)
+
+	alice, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("shared-key", aliceIDs...))
+	if err != nil {
+		t.Fatalf("alice: %v", err)
+	}
+	bob, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("shared-key", bobIDs...))
+	if err != nil {
+		t.Fatalf("bob: %v", err)
+	}
+	if alice.ID == bob.ID {
+		t.Fatalf("owners sharing a key got the same request %s", alice.ID)
+	}
+}
+
+func TestCreateWithdrawRequestKeyReusedForOtherAllocations(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100", "200")
+	service := newFakeWithdrawService(store)
+
+	first, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("key-1", ids[0]))
+	if err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	_, err = service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("key-1", ids[1]))
+	if !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Fatalf("err = %v, want ErrIdempotencyKeyReused", err)
+	}
+	if ErrorCode(err) != CodeIdempotencyKeyReused {
+		t.Errorf("code = %q, want %q", ErrorCode(err), CodeIdempotencyKeyReused)
+	}
+	if a := store.allocation(ids[1]); a.Status != models.AllocationStatusIdle {
+		t.Errorf("allocation %s locked by a refused create", ids[1])
+	}
+
+	again, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("key-1", ids[0]))
+	if err != nil || again.ID != first.ID {
+		t.Fatalf("retry with the original allocations: request=%v err=%v, want %s", again, err, first.ID)
+	}
+}
+
+func TestCreateWithdrawRequestLoadsCheckbooksInBatch(t *testing.T) {
+	store := newFakeStore()
+	var ids []string
+	for _, checkbookID := range []string{"cb1", "cb2", "cb3", "cb4"} {
+		ids = append(ids, store.addIdleAllocations(checkbookID, "0xowner", "100", "200")...)
+	}
+	service := newFakeWithdrawService(store)
+
+	if _, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// The owner lookup for the rate limit, then one batch for every checkbook
+	if store.checkbookQueries != 2 {
+		t.Errorf("create across 4 checkbooks ran %d checkbook queries, want 2", store.checkbookQueries)
+	}
+}
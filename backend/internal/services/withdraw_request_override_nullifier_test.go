@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCreateWithdrawRequestUsesFirstAllocationNullifierByDefault(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100", "200")
+	service := newFakeWithdrawService(store)
+
+	request, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...))
+	if err != nil {
+		t.Fatalf("CreateWithdrawRequest: %v", err)
+	}
+	if request.WithdrawNullifier != "0xcb1-1" {
+		t.Errorf("withdraw_nullifier = %s, want allocations[0].Nullifier 0xcb1-1", request.WithdrawNullifier)
+	}
+}
+
+func TestCreateWithdrawRequestOverrideNullifier(t *testing.T) {
+	override := "0x" + strings.Repeat("ab", 32)
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"lowercase", override, override, false},
+		{"uppercase with spaces", "  0X" + strings.Repeat("AB", 32) + " ", override, false},
+		{"without 0x", strings.Repeat("ab", 32), override, false},
+		{"too short", "0x" + strings.Repeat("ab", 31), "", true},
+		{"too long", "0x" + strings.Repeat("ab", 33), "", true},
+		{"not hex", "0x" + strings.Repeat("zz", 32), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			ids := store.addIdleAllocations("cb1", "0xowner", "100")
+			service := newFakeWithdrawService(store)
+
+			input := idempotentCreateInput("", ids...)
+			input.OverrideWithdrawNullifier = tt.input
+			request, err := service.CreateWithdrawRequest(context.Background(), input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOverrideNullifier) {
+					t.Errorf("CreateWithdrawRequest: %v, want ErrInvalidOverrideNullifier", err)
+				}
+				if len(store.requests) != 0 {
+					t.Errorf("%d requests stored for a rejected override", len(store.requests))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateWithdrawRequest: %v", err)
+			}
+			if request.WithdrawNullifier != tt.want {
+				t.Errorf("withdraw_nullifier = %s, want %s", request.WithdrawNullifier, tt.want)
+			}
+		})
+	}
+}
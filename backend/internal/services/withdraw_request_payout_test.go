@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+// stubPayoutExecutor returns a fixed result and remembers the requests it was asked to pay out
+type stubPayoutExecutor struct {
+	txHash      string
+	blockNumber uint64
+	err         error
+	calls       []string
+}
+
+func (e *stubPayoutExecutor) ExecutePayout(ctx context.Context, request *models.WithdrawRequest) (string, uint64, error) {
+	e.calls = append(e.calls, request.ID)
+	return e.txHash, e.blockNumber, e.err
+}
+
+// addExecutedRequest stores a request whose proof and execute stages succeeded and whose payout is pending
+func addExecutedRequest(store *fakeStore, id string) {
+	store.addPendingRequest(id, "100")
+	request := store.requests[id]
+	request.ProofStatus = models.ProofStatusCompleted
+	request.ExecuteStatus = models.ExecuteStatusSuccess
+	request.Status = string(models.WithdrawStatusWaitingForPayout)
+}
+
+func TestProcessPayoutCompletesWithExecutorResult(t *testing.T) {
+	store := newFakeStore()
+	addExecutedRequest(store, "wr1")
+	service := newFakeWithdrawService(store)
+	executor := &stubPayoutExecutor{txHash: "0xpayout", blockNumber: 4242}
+	service.SetPayoutExecutor(executor)
+
+	if err := service.ProcessPayout(context.Background(), "wr1"); err != nil {
+		t.Fatalf("ProcessPayout: %v", err)
+	}
+
+	if len(executor.calls) != 1 || executor.calls[0] != "wr1" {
+		t.Fatalf("executor calls = %v, want [wr1]", executor.calls)
+	}
+	got := store.request("wr1")
+	if got.PayoutStatus != models.PayoutStatusCompleted {
+		t.Errorf("payout_status = %s, want %s", got.PayoutStatus, models.PayoutStatusCompleted)
+	}
+	if got.PayoutTxHash != "0xpayout" || got.PayoutBlockNumber == nil || *got.PayoutBlockNumber != 4242 {
+		t.Errorf("payout tx = %q at %v, want 0xpayout at 4242", got.PayoutTxHash, got.PayoutBlockNumber)
+	}
+	if got.Status != string(models.WithdrawStatusCompleted) {
+		t.Errorf("status = %s, want %s", got.Status, models.WithdrawStatusCompleted)
+	}
+	if got.PayoutRetryCount != 0 || got.RetryHistory != "" {
+		t.Errorf("retry count %d, history %q after a successful payout, want none", got.PayoutRetryCount, got.RetryHistory)
+	}
+}
+
+func TestProcessPayoutMarksFailedWhenExecutorFails(t *testing.T) {
+	store := newFakeStore()
+	addExecutedRequest(store, "wr1")
+	service := newFakeWithdrawService(store)
+	executorErr := errors.New("multisig rejected")
+	service.SetPayoutExecutor(&stubPayoutExecutor{err: executorErr})
+
+	if err := service.ProcessPayout(context.Background(), "wr1"); !errors.Is(err, executorErr) {
+		t.Fatalf("ProcessPayout: %v, want the executor error", err)
+	}
+
+	got := store.request("wr1")
+	if got.PayoutStatus != models.PayoutStatusFailed {
+		t.Errorf("payout_status = %s, want %s", got.PayoutStatus, models.PayoutStatusFailed)
+	}
+	if got.PayoutError != executorErr.Error() || got.PayoutRetryCount != 1 {
+		t.Errorf("payout error %q, retry count %d, want %q and 1", got.PayoutError, got.PayoutRetryCount, executorErr.Error())
+	}
+	if got.PayoutCompletedAt != nil {
+		t.Error("payout_completed_at set for a failed payout")
+	}
+	if got.Status != string(models.WithdrawStatusFailedPermanent) {
+		t.Errorf("status = %s, want %s", got.Status, models.WithdrawStatusFailedPermanent)
+	}
+	history, err := got.ParseRetryHistory()
+	if err != nil {
+		t.Fatalf("ParseRetryHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Stage != models.RetryStagePayout || history[0].Attempt != 1 || history[0].Error != executorErr.Error() {
+		t.Errorf("retry history = %+v, want one payout attempt 1 with the executor error", history)
+	}
+}
+
+func TestProcessPayoutRejectsUnexecutedRequest(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100")
+	service := newFakeWithdrawService(store)
+	executor := &stubPayoutExecutor{txHash: "0xpayout"}
+	service.SetPayoutExecutor(executor)
+
+	if err := service.ProcessPayout(context.Background(), "wr1"); !errors.Is(err, ErrExecuteNotSuccessful) {
+		t.Fatalf("ProcessPayout: %v, want ErrExecuteNotSuccessful", err)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("executor called %d times for an unexecuted request", len(executor.calls))
+	}
+	if got := store.request("wr1").PayoutStatus; got != models.PayoutStatusPending {
+		t.Errorf("payout_status = %s, want pending", got)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestSaveGeneratedProofSetsProofGenerated(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100")
+	store.requests["wr1"].ProofStatus = models.ProofStatusInProgress
+	service := newFakeWithdrawService(store)
+
+	request, err := service.saveGeneratedProof(context.Background(), "wr1", "0xproof", "0xpublic")
+	if err != nil {
+		t.Fatalf("saveGeneratedProof: %v", err)
+	}
+	if request.Status != string(models.WithdrawStatusProofGenerated) {
+		t.Errorf("status = %s, want %s", request.Status, models.WithdrawStatusProofGenerated)
+	}
+	stored := store.request("wr1")
+	if stored.ProofStatus != models.ProofStatusCompleted || stored.Proof != "0xproof" || stored.PublicValues != "0xpublic" {
+		t.Errorf("proof not stored: proof_status=%s proof=%s public_values=%s", stored.ProofStatus, stored.Proof, stored.PublicValues)
+	}
+}
+
+func TestSaveGeneratedProofKeepsClosedRequest(t *testing.T) {
+	for _, status := range []models.WithdrawRequestStatus{models.WithdrawStatusCancelled, models.WithdrawStatusManuallyResolved} {
+		t.Run(string(status), func(t *testing.T) {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].ProofStatus = models.ProofStatusInProgress
+			store.requests["wr1"].Status = string(status)
+			service := newFakeWithdrawService(store)
+
+			_, err := service.saveGeneratedProof(context.Background(), "wr1", "0xproof", "0xpublic")
+			if !errors.Is(err, ErrWithdrawRequestClosed) {
+				t.Fatalf("err = %v, want ErrWithdrawRequestClosed", err)
+			}
+			stored := store.request("wr1")
+			if stored.Status != string(status) {
+				t.Errorf("status = %s, want %s", stored.Status, status)
+			}
+			if stored.Proof != "" || stored.ProofStatus != models.ProofStatusInProgress {
+				t.Errorf("proof written to a closed request: proof_status=%s proof=%s", stored.ProofStatus, stored.Proof)
+			}
+		})
+	}
+}
+
+func TestSaveGeneratedProofNeverLosesConcurrentCancel(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		store := newFakeStore()
+		store.addPendingRequest("wr1", "100")
+		store.requests["wr1"].ProofStatus = models.ProofStatusInProgress
+		service := newFakeWithdrawService(store)
+		ctx := context.Background()
+
+		var wg sync.WaitGroup
+		var cancelErr, saveErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cancelErr = service.CancelWithdrawRequest(ctx, "wr1")
+		}()
+		go func() {
+			defer wg.Done()
+			_, saveErr = service.saveGeneratedProof(ctx, "wr1", "0xproof", "0xpublic")
+		}()
+		wg.Wait()
+
+		stored := store.request("wr1")
+		if cancelErr != nil {
+			t.Fatalf("CancelWithdrawRequest: %v", cancelErr)
+		}
+		if stored.Status != string(models.WithdrawStatusCancelled) {
+			t.Fatalf("status = %s after cancel and proof save (save err: %v), want cancelled", stored.Status, saveErr)
+		}
+		if saveErr != nil && !errors.Is(saveErr, ErrWithdrawRequestClosed) {
+			t.Fatalf("saveGeneratedProof err = %v, want nil or ErrWithdrawRequestClosed", saveErr)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestQuickCheckReceiptStopsOnContextCancel(t *testing.T) {
+	service := newFakeWithdrawService(newFakeStore())
+	service.quickCheckDelays = []time.Duration{20 * time.Millisecond, time.Hour, time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetches := 0
+	fetch := func(ctx context.Context, hash common.Hash) (*ethtypes.Receipt, error) {
+		fetches++
+		cancel() // shutdown starts while the first receipt is still pending
+		return nil, errors.New("not found")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		receipt, err := service.quickCheckReceipt(ctx, "wr1", "0xabc", fetch)
+		if receipt != nil {
+			err = errors.New("unexpected receipt")
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("quickCheckReceipt: %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("quickCheckReceipt kept waiting after ctx was cancelled")
+	}
+	if fetches != 1 {
+		t.Errorf("%d receipt lookups, want 1 (none after cancel)", fetches)
+	}
+}
+
+func TestQuickCheckReceiptCancelledBeforeFirstAttempt(t *testing.T) {
+	service := newFakeWithdrawService(newFakeStore())
+	service.quickCheckDelays = []time.Duration{time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fetch := func(ctx context.Context, hash common.Hash) (*ethtypes.Receipt, error) {
+		t.Error("receipt looked up after ctx was cancelled")
+		return nil, nil
+	}
+
+	if _, err := service.quickCheckReceipt(ctx, "wr1", "0xabc", fetch); !errors.Is(err, context.Canceled) {
+		t.Errorf("quickCheckReceipt: %v, want context.Canceled", err)
+	}
+}
+
+func TestQuickCheckReceiptReturnsFirstReceipt(t *testing.T) {
+	service := newFakeWithdrawService(newFakeStore())
+	service.quickCheckDelays = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+
+	fetches := 0
+	fetch := func(ctx context.Context, hash common.Hash) (*ethtypes.Receipt, error) {
+		fetches++
+		if fetches < 2 {
+			return nil, errors.New("not found")
+		}
+		return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful, BlockNumber: big.NewInt(77)}, nil
+	}
+
+	receipt, err := service.quickCheckReceipt(context.Background(), "wr1", "0xabc", fetch)
+	if err != nil || receipt == nil || receipt.BlockNumber.Uint64() != 77 {
+		t.Fatalf("quickCheckReceipt = %v, %v, want the block 77 receipt", receipt, err)
+	}
+	if fetches != 2 {
+		t.Errorf("%d receipt lookups, want 2 (stop at the first receipt)", fetches)
+	}
+}
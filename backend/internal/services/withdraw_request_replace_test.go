@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-backend/internal/models"
+	"go-backend/internal/repository"
+)
+
+func TestCreateWithdrawRequestKeepsReplacedRequestSoftDeleted(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100", "200")
+	service := newFakeWithdrawService(store)
+	ctx := context.Background()
+
+	first, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", ids...))
+	if err != nil {
+		t.Fatalf("first CreateWithdrawRequest: %v", err)
+	}
+	if err := service.CancelWithdrawRequest(ctx, first.ID); err != nil {
+		t.Fatalf("CancelWithdrawRequest: %v", err)
+	}
+	second, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", ids...))
+	if err != nil {
+		t.Fatalf("second CreateWithdrawRequest: %v", err)
+	}
+
+	replaced := store.request(first.ID)
+	if !replaced.DeletedAt.Valid || replaced.Status != string(models.WithdrawStatusCancelled) {
+		t.Errorf("replaced request: deleted=%v status=%s, want soft-deleted and cancelled", replaced.DeletedAt.Valid, replaced.Status)
+	}
+	recreated := store.request(second.ID)
+	if recreated.DeletedAt.Valid || recreated.WithdrawNullifier != replaced.WithdrawNullifier {
+		t.Errorf("recreated request: deleted=%v nullifier=%s, want live with nullifier %s",
+			recreated.DeletedAt.Valid, recreated.WithdrawNullifier, replaced.WithdrawNullifier)
+	}
+	for _, id := range ids {
+		if a := store.allocation(id); a.Status != models.AllocationStatusPending || *a.WithdrawRequestID != second.ID {
+			t.Errorf("allocation %s: status=%s, want pending for %s", id, a.Status, second.ID)
+		}
+	}
+}
+
+func TestCreateWithdrawRequestLockFailureWritesNothing(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100", "200")
+	service := newFakeWithdrawService(store)
+	ctx := context.Background()
+
+	first, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", ids...))
+	if err != nil {
+		t.Fatalf("first CreateWithdrawRequest: %v", err)
+	}
+	if err := service.CancelWithdrawRequest(ctx, first.ID); err != nil {
+		t.Fatalf("CancelWithdrawRequest: %v", err)
+	}
+
+	lockErr := errors.New("connection reset")
+	store.lockErr = lockErr
+	if _, err := service.CreateWithdrawRequest(ctx, idempotentCreateInput("", ids...)); !errors.Is(err, lockErr) {
+		t.Fatalf("err = %v, want the lock error", err)
+	}
+
+	if len(store.requests) != 1 {
+		t.Errorf("%d requests stored, want only the first", len(store.requests))
+	}
+	if replaced := store.request(first.ID); replaced.DeletedAt.Valid {
+		t.Error("old request soft-deleted although its replacement was never created")
+	}
+}
+
+func TestCreateWithdrawRequestLockConflictIsAllocationsNotIdle(t *testing.T) {
+	store := newFakeStore()
+	ids := store.addIdleAllocations("cb1", "0xowner", "100", "200")
+	service := newFakeWithdrawService(store)
+
+	// Another request locked an allocation between validation and the lock
+	store.lockErr = fmt.Errorf("%w: allocation %s is pending for withdraw request other", repository.ErrAllocationLockConflict, ids[1])
+	_, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...))
+	if !errors.Is(err, ErrAllocationsNotIdle) {
+		t.Fatalf("err = %v, want ErrAllocationsNotIdle", err)
+	}
+	if len(store.requests) != 0 {
+		t.Errorf("%d requests stored after a lock conflict, want none", len(store.requests))
+	}
+}
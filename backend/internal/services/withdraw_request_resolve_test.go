@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/models"
+)
+
+func TestManuallyResolvePersistsResolverAndReleasesAllocations(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100", "200")
+	store.requests["wr1"].ProofStatus = models.ProofStatusCompleted
+	store.requests["wr1"].ExecuteStatus = models.ExecuteStatusSubmitFailed
+	store.requests["wr1"].Status = string(models.WithdrawStatusSubmitFailed)
+	service := newFakeWithdrawService(store)
+
+	resolved, err := service.ManuallyResolve(context.Background(), "wr1", "ops@example.com", "refunded off-chain")
+	if err != nil {
+		t.Fatalf("ManuallyResolve: %v", err)
+	}
+
+	stored := store.request("wr1")
+	if stored.Status != string(models.WithdrawStatusManuallyResolved) {
+		t.Errorf("status = %s, want manually_resolved", stored.Status)
+	}
+	if stored.ResolvedBy != "ops@example.com" || stored.ResolutionNote != "refunded off-chain" || stored.ResolvedAt == nil {
+		t.Errorf("resolution not persisted: resolved_by=%q note=%q resolved_at=%v", stored.ResolvedBy, stored.ResolutionNote, stored.ResolvedAt)
+	}
+	if resolved.ResolvedBy != stored.ResolvedBy {
+		t.Errorf("returned request resolved_by = %q, stored %q", resolved.ResolvedBy, stored.ResolvedBy)
+	}
+	for _, id := range []string{"a1", "a2"} {
+		if a := store.allocation(id); a.Status != models.AllocationStatusIdle || a.WithdrawRequestID != nil {
+			t.Errorf("%s status = %s, want idle", id, a.Status)
+		}
+	}
+}
+
+func TestManuallyResolveSettlesExecutedAllocations(t *testing.T) {
+	store := newFakeStore()
+	store.addPendingRequest("wr1", "100")
+	store.requests["wr1"].ProofStatus = models.ProofStatusCompleted
+	store.requests["wr1"].ExecuteStatus = models.ExecuteStatusSuccess
+	store.requests["wr1"].PayoutStatus = models.PayoutStatusFailed
+	store.requests["wr1"].Status = string(models.WithdrawStatusFailedPermanent)
+	service := newFakeWithdrawService(store)
+
+	if _, err := service.ManuallyResolve(context.Background(), "wr1", "ops", "paid manually"); err != nil {
+		t.Fatalf("ManuallyResolve: %v", err)
+	}
+	if a := store.allocation("a1"); a.Status != models.AllocationStatusUsed {
+		t.Errorf("a1 status = %s, want used (nullifier consumed on-chain)", a.Status)
+	}
+}
+
+func TestManuallyResolveRefusesTerminalSuccess(t *testing.T) {
+	for _, status := range []models.WithdrawRequestStatus{
+		models.WithdrawStatusCompleted,
+		models.WithdrawStatusCompletedWithHookFailed,
+		models.WithdrawStatusManuallyResolved,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].Status = string(status)
+			store.requests["wr1"].ResolvedBy = "earlier"
+			service := newFakeWithdrawService(store)
+
+			_, err := service.ManuallyResolve(context.Background(), "wr1", "ops", "again")
+			if !errors.Is(err, ErrCannotManuallyResolve) {
+				t.Fatalf("err = %v, want ErrCannotManuallyResolve", err)
+			}
+			stored := store.request("wr1")
+			if stored.Status != string(status) || stored.ResolvedBy != "earlier" {
+				t.Errorf("request changed: status=%s resolved_by=%q", stored.Status, stored.ResolvedBy)
+			}
+			if a := store.allocation("a1"); a.Status != models.AllocationStatusPending {
+				t.Errorf("a1 status = %s, want pending", a.Status)
+			}
+		})
+	}
+}
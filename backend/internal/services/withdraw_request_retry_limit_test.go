@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend/internal/config"
+	"go-backend/internal/models"
+)
+
+// addFailedStageRequest stores request "wr1" whose executeWithdraw succeeded, waiting in failed_permanent
+func addFailedStageRequest(store *fakeStore) *models.WithdrawRequest {
+	store.addPendingRequest("wr1", "100")
+	request := store.requests["wr1"]
+	request.Status = string(models.WithdrawStatusFailedPermanent)
+	request.ProofStatus = models.ProofStatusCompleted
+	request.ExecuteStatus = models.ExecuteStatusSuccess
+	return request
+}
+
+// newRetryCapService a service over store with payout, hook and fallback caps of 2, 3 and 4
+func newRetryCapService(store *fakeStore) *WithdrawRequestService {
+	service := newFakeWithdrawService(store)
+	service.maxPayoutRetries, service.maxHookRetries, service.maxFallbackRetries = 2, 3, 4
+	return service
+}
+
+func TestRetryPayoutStopsAtPayoutRetryLimit(t *testing.T) {
+	store := newFakeStore()
+	request := addFailedStageRequest(store)
+	request.PayoutStatus = models.PayoutStatusFailed
+	request.HookStatus = models.HookStatusNotRequired
+	request.PayoutRetryCount = 2
+	service := newRetryCapService(store)
+
+	if err := service.RetryPayout(context.Background(), "wr1"); !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("retry 2 of 2: %v, want ErrMaxRetriesExceeded", err)
+	}
+
+	// Below the cap the payout runs again (simulated without an executor)
+	request.PayoutRetryCount = 1
+	if err := service.RetryPayout(context.Background(), "wr1"); err != nil {
+		t.Fatalf("retry 1 of 2: %v", err)
+	}
+	if got := store.request("wr1"); got.PayoutStatus != models.PayoutStatusCompleted {
+		t.Errorf("payout_status = %s after the retry, want completed", got.PayoutStatus)
+	}
+}
+
+func TestRetryHookStopsAtHookRetryLimit(t *testing.T) {
+	store := newFakeStore()
+	request := addFailedStageRequest(store)
+	request.PayoutStatus = models.PayoutStatusCompleted
+	request.HookStatus = models.HookStatusFailed
+	request.HookRetryCount = 3
+	service := newRetryCapService(store)
+
+	if err := service.RetryHook(context.Background(), "wr1"); !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("retry 3 of 3: %v, want ErrMaxRetriesExceeded", err)
+	}
+
+	// Below the cap the request gets past the retry check (and stops at the missing calldata)
+	request.HookRetryCount = 2
+	if err := service.RetryHook(context.Background(), "wr1"); !errors.Is(err, ErrHookCalldataMissing) {
+		t.Fatalf("retry 2 of 3: %v, want ErrHookCalldataMissing", err)
+	}
+}
+
+func TestRetryFallbackStopsAtFallbackRetryLimit(t *testing.T) {
+	store := newFakeStore()
+	request := addFailedStageRequest(store)
+	request.PayoutStatus = models.PayoutStatusCompleted
+	request.HookStatus = models.HookStatusFailed
+	request.FallbackError = "transfer reverted"
+	request.FallbackRetryCount = 4
+	service := newRetryCapService(store)
+
+	if err := service.RetryFallback(context.Background(), "wr1"); !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("retry 4 of 4: %v, want ErrMaxRetriesExceeded", err)
+	}
+
+	request.FallbackRetryCount = 3
+	if err := service.RetryFallback(context.Background(), "wr1"); err != nil {
+		t.Fatalf("retry 3 of 4: %v", err)
+	}
+	if got := store.request("wr1"); got.FallbackRetryCount != 4 {
+		t.Errorf("fallback_retry_count = %d after the retry, want 4", got.FallbackRetryCount)
+	}
+}
+
+func TestRetryRejectedForClosedRequests(t *testing.T) {
+	store := newFakeStore()
+	request := addFailedStageRequest(store)
+	request.Status = string(models.WithdrawStatusManuallyResolved)
+	request.PayoutStatus = models.PayoutStatusFailed
+	service := newRetryCapService(store)
+
+	if err := service.RetryPayout(context.Background(), "wr1"); !errors.Is(err, ErrCannotRetryPayout) {
+		t.Errorf("RetryPayout on a manually resolved request: %v, want ErrCannotRetryPayout", err)
+	}
+}
+
+func TestCheckRetryLimitRejectsUnknownStage(t *testing.T) {
+	service := newRetryCapService(newFakeStore())
+	err := service.checkRetryLimit(models.RetryStage("execute"), 0)
+	if err == nil || errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Errorf("checkRetryLimit(execute) = %v, want an unknown stage error", err)
+	}
+}
+
+func TestNewWithdrawRequestServiceRejectsRetryCapBelowOne(t *testing.T) {
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+	config.AppConfig = &config.Config{Withdraw: config.WithdrawConfig{MaxHookRetries: -1}}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewWithdrawRequestService accepted max_hook_retries = -1")
+		}
+	}()
+	NewWithdrawRequestService(nil, nil, nil, nil, nil, nil)
+}
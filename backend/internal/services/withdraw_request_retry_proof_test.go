@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/models"
+)
+
+// newRetryProofService a service whose proof generation is never started in the background
+func newRetryProofService(t *testing.T, store *fakeStore) *WithdrawRequestService {
+	t.Helper()
+	service := newFakeWithdrawService(store)
+	service.zkvmClient = &clients.ZKVMClient{}
+	if err := service.tasks.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	return service
+}
+
+func TestRetryProofGenerationResetsPendingAndFailed(t *testing.T) {
+	for _, proofStatus := range []models.ProofStatus{models.ProofStatusPending, models.ProofStatusFailed} {
+		t.Run(string(proofStatus), func(t *testing.T) {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].Signature = "0xsig"
+			store.requests["wr1"].ProofStatus = proofStatus
+			store.requests["wr1"].ProofError = "zkvm timeout"
+			service := newRetryProofService(t, store)
+
+			if err := service.RetryProofGeneration(context.Background(), "wr1"); err != nil {
+				t.Fatalf("RetryProofGeneration: %v", err)
+			}
+			if request := store.request("wr1"); request.ProofStatus != models.ProofStatusPending || request.ProofError != "" {
+				t.Errorf("proof_status=%s proof_error=%q, want pending and cleared", request.ProofStatus, request.ProofError)
+			}
+		})
+	}
+}
+
+func TestRetryProofGenerationRefusesRunningOrClosed(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name  string
+		setup func(request *models.WithdrawRequest)
+	}{
+		{"in_progress", func(r *models.WithdrawRequest) { r.ProofStatus = models.ProofStatusInProgress }},
+		{"completed", func(r *models.WithdrawRequest) { r.ProofStatus = models.ProofStatusCompleted }},
+		{"cancelled", func(r *models.WithdrawRequest) { r.Status = string(models.WithdrawStatusCancelled) }},
+		{"manually_resolved", func(r *models.WithdrawRequest) { r.Status = string(models.WithdrawStatusManuallyResolved) }},
+		{"expired", func(r *models.WithdrawRequest) { r.ExpiresAt = &expired }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			store.addPendingRequest("wr1", "100")
+			store.requests["wr1"].Signature = "0xsig"
+			tt.setup(store.requests["wr1"])
+			before := store.request("wr1")
+			service := newRetryProofService(t, store)
+
+			if err := service.RetryProofGeneration(context.Background(), "wr1"); !errors.Is(err, ErrCannotRetryProof) {
+				t.Fatalf("err = %v, want ErrCannotRetryProof", err)
+			}
+			if after := store.request("wr1"); after.ProofStatus != before.ProofStatus || after.Version != before.Version {
+				t.Errorf("request changed: proof_status %s → %s, version %d → %d", before.ProofStatus, after.ProofStatus, before.Version, after.Version)
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"go-backend/internal/clients"
+	"go-backend/internal/config"
+	"go-backend/internal/logging"
 	"go-backend/internal/models"
 	"go-backend/internal/repository"
 	"go-backend/internal/types"
@@ -26,15 +28,42 @@ import (
 	"gorm.io/gorm"
 )
 
+// Service errors, each carrying a code (see service_errors.go)
 var (
-	ErrInvalidAllocations       = errors.New("invalid allocations")
-	ErrAllocationsNotIdle       = errors.New("allocations must be idle")
-	ErrAllocationsDifferentUser = errors.New("allocations belong to different users")
-	ErrInvalidIntent            = errors.New("invalid intent")
-	ErrCannotCancel             = errors.New("cannot cancel: execute status is success")
-	ErrCannotRetryPayout        = errors.New("cannot retry payout: invalid status")
-	ErrCannotRetryHook          = errors.New("cannot retry hook: invalid status")
-	ErrMaxRetriesExceeded       = errors.New("max retries exceeded")
+	ErrInvalidAllocations           = newServiceError(CodeInvalidAllocations, "invalid allocations")
+	ErrAllocationsNotIdle           = newServiceError(CodeAllocationsNotIdle, "allocations must be idle")
+	ErrAllocationsDifferentUser     = newServiceError(CodeAllocationsDifferentUser, "allocations belong to different users")
+	ErrAllocationsExceedAllocatable = newServiceError(CodeAllocationsExceedAllocatable, "allocations exceed checkbook allocatable amount")
+	ErrInvalidIntent                = newServiceError(CodeInvalidIntent, "invalid intent")
+	ErrCannotCancel                 = newServiceError(CodeCannotCancel, "cannot cancel: execute status is success")
+	ErrWithdrawRequestClosed        = newServiceError(CodeRequestClosed, "withdraw request was cancelled or manually resolved")
+	ErrCannotPartialCancel          = newServiceError(CodeCannotCancel, "cannot cancel part of a withdraw request once its proof generation started")
+	ErrAllocationNotInRequest       = newServiceError(CodeAllocationNotInRequest, "allocation does not belong to withdraw request")
+	ErrCannotRetryPayout            = newServiceError(CodeCannotRetry, "cannot retry payout: invalid status")
+	ErrCannotRetryHook              = newServiceError(CodeCannotRetry, "cannot retry hook: invalid status")
+	ErrCannotRetryProof             = newServiceError(CodeCannotRetry, "cannot retry proof generation: invalid status")
+	ErrSignatureNotStored           = newServiceError(CodeSignatureNotStored, "signature not stored for withdraw request")
+	ErrCannotResign                 = newServiceError(CodeCannotRetry, "cannot replace signature: proof generation already started or request closed")
+	ErrMaxRetriesExceeded           = newServiceError(CodeMaxRetriesExceeded, "max retries exceeded")
+	ErrMalformedAllocationIDs       = newServiceError(CodeMalformedAllocationIDs, "malformed allocation IDs")
+	ErrInvalidOverrideNullifier     = newServiceError(CodeInvalidOverrideNullifier, "invalid override withdraw nullifier")
+	ErrCannotManuallyResolve        = newServiceError(CodeCannotManuallyResolve, "cannot manually resolve: request already completed or resolved")
+	ErrInvalidAllocationAmount      = newServiceError(CodeInvalidAllocationAmount, "invalid allocation amount")
+	ErrProofNotCompleted            = newServiceError(CodeProofNotCompleted, "proof not completed")
+	ErrAlreadyExecuted              = newServiceError(CodeAlreadyExecuted, "withdraw already executed successfully")
+	ErrVerificationFailed           = newServiceError(CodeVerificationFailed, "verification failed permanently, cannot retry - please cancel the request")
+	ErrExecuteNotSuccessful         = newServiceError(CodeExecuteNotSuccessful, "execute not successful")
+	ErrPayoutNotCompleted           = newServiceError(CodePayoutNotCompleted, "payout not completed")
+	ErrPayoutAlreadyCompleted       = newServiceError(CodePayoutAlreadyCompleted, "payout already completed")
+	ErrAlreadyProcessing            = newServiceError(CodeAlreadyProcessing, "already being processed")
+	ErrHookNotFailed                = newServiceError(CodeHookNotFailed, "hook not in failed state")
+	ErrBlockchainUnavailable        = newServiceError(CodeBlockchainUnavailable, "blockchain service not configured")
+	ErrTooManyAllocations           = newServiceError(CodeTooManyAllocations, "too many allocations")
+	ErrTooManyCheckbooks            = newServiceError(CodeTooManyCheckbooks, "too many checkbooks")
+	ErrMixedTokenAllocations        = newServiceError(CodeMixedTokenAllocations, "allocations must all be of the same token")
+	ErrHookCalldataMissing          = newServiceError(CodeHookCalldataMissing, "no hook calldata stored for withdraw request")
+	ErrRateLimited                  = newServiceError(CodeRateLimited, "too many withdraw requests, please retry later")
+	ErrIdempotencyKeyReused         = newServiceError(CodeIdempotencyKeyReused, "idempotency key already used for different allocations")
 )
 
 // WithdrawRequestService handles WithdrawRequest business logic
@@ -48,6 +77,48 @@ type WithdrawRequestService struct {
 	intentService        *IntentService                 // Optional: for building IntentRequest
 	pollingService       *UnifiedPollingService         // Optional: for polling transaction confirmation
 	proofGenerationService *ProofGenerationService     // Optional: for async proof generation
+
+	payoutExecutor PayoutExecutor          // Optional: real payout (multisig/LiFi), simulated if nil
+	tronService    *TronTransactionService // Optional: TRON submission when the submission chain is TRON
+	submitter      ChainTransactionService // Optional: sends executeWithdraw instead of the per-chain EVM/TRON service
+
+	transactor repository.Transactor // status changes and allocation releases that must commit together
+
+	// Retry caps per stage (from config.withdraw, default 5)
+	maxPayoutRetries   int
+	maxHookRetries     int
+	maxFallbackRetries int
+
+	// executeWithdraw confirmation (from config.withdraw)
+	quickCheckDelays      []time.Duration
+	executePollMaxRetries int
+	executePollInterval   int
+	executeSimulateFirst  bool // eth_call executeWithdraw before sending
+	strictNullifierCheck  bool // ZKVM/DB nullifier mismatch fails proof generation
+
+	// How long a new request may wait for its proof before WithdrawExpiryService cancels it (0 = never)
+	requestTTL time.Duration
+
+	// Withdraw size caps (from config.withdraw)
+	maxAllocationsPerWithdraw int
+	maxCheckbooksPerWithdraw  int
+
+	// Per-owner cap on request creation (from config.withdraw.create_requests_per_minute), nil = unlimited
+	rateLimiter WithdrawRateLimiter
+
+	// Hook calldata of requests whose Intent has none (config.withdraw.default_hook_calldata), "" = no default hook
+	defaultHookCalldata string
+
+	logger logging.Logger // structured logger (text or JSON)
+
+	tasks *BackgroundTasks // background proof generation goroutines, drained by Shutdown
+}
+
+// PayoutExecutor executes Stage 3 payout on-chain
+// Implementations call multisig to execute Treasury.payout(targetChainId, IntentManager, amount, beneficiary, hookCalldata),
+// route cross-chain transfers via LiFi, and return the payout TX hash and block number.
+type PayoutExecutor interface {
+	ExecutePayout(ctx context.Context, request *models.WithdrawRequest) (txHash string, blockNumber uint64, err error)
 }
 
 // NewWithdrawRequestService creates a new WithdrawRequestService
@@ -56,13 +127,120 @@ func NewWithdrawRequestService(
 	allocationRepo repository.AllocationRepository,
 	checkbookRepo repository.CheckbookRepository,
 	queueRootRepo repository.QueueRootRepository,
+	transactor repository.Transactor,
+	logger logging.Logger, // nil uses the format from logging.format config
 ) *WithdrawRequestService {
+	if logger == nil {
+		logger = logging.New(config.GetLogFormat())
+	}
+	withdrawConfig := config.GetWithdrawConfig()
 	return &WithdrawRequestService{
-		withdrawRepo:   withdrawRepo,
-		allocationRepo: allocationRepo,
-		checkbookRepo:  checkbookRepo,
-		queueRootRepo:  queueRootRepo,
+		withdrawRepo:       withdrawRepo,
+		allocationRepo:     allocationRepo,
+		checkbookRepo:      checkbookRepo,
+		queueRootRepo:      queueRootRepo,
+		transactor:         transactor,
+		maxPayoutRetries:   mustRetryLimit("max_payout_retries", withdrawConfig.MaxPayoutRetries),
+		maxHookRetries:     mustRetryLimit("max_hook_retries", withdrawConfig.MaxHookRetries),
+		maxFallbackRetries: mustRetryLimit("max_fallback_retries", withdrawConfig.MaxFallbackRetries),
+
+		quickCheckDelays:      quickCheckDelays(withdrawConfig.QuickCheckDelays),
+		executePollMaxRetries: withdrawConfig.ExecutePollMaxRetries,
+		executePollInterval:   withdrawConfig.ExecutePollInterval,
+		executeSimulateFirst:  withdrawConfig.ExecuteSimulateFirst,
+		strictNullifierCheck:  withdrawConfig.StrictNullifierCheck,
+		requestTTL:            time.Duration(max(withdrawConfig.WithdrawRequestTTL, 0)) * time.Second,
+
+		maxAllocationsPerWithdraw: withdrawConfig.MaxAllocationsPerWithdraw,
+		maxCheckbooksPerWithdraw:  withdrawConfig.MaxCheckbooksPerWithdraw,
+
+		rateLimiter:         newConfiguredWithdrawRateLimiter(withdrawConfig.CreateRequestsPerMinute),
+		defaultHookCalldata: defaultHookCalldata(withdrawConfig),
+
+		logger: logger,
+		tasks:  NewBackgroundTasks(),
+	}
+}
+
+// Shutdown stops starting new background proof generation and waits for running ones until ctx expires
+// Requests whose proof generation was interrupted are reset from in_progress to pending so they can be retried.
+func (s *WithdrawRequestService) Shutdown(ctx context.Context) error {
+	log.Printf("🛑 [WithdrawRequestService] Draining background tasks...")
+	return s.tasks.Shutdown(ctx)
+}
+
+// startProofGeneration runs autoGenerateProofWithSignature as a tracked background task
+func (s *WithdrawRequestService) startProofGeneration(requestID string, signature string, chainID uint32) {
+	started := s.tasks.Go("autoGenerateProof "+requestID, func(ctx context.Context) {
+		s.autoGenerateProofWithSignature(ctx, requestID, signature, chainID)
+		if ctx.Err() != nil {
+			s.releaseInterruptedProof(requestID)
+		}
+	})
+	if !started {
+		log.Printf("⚠️ [autoGenerateProof] Service shutting down, request %s left in pending", requestID)
+	}
+}
+
+// releaseInterruptedProof resets proof_status in_progress -> pending after proof generation was cancelled by shutdown
+func (s *WithdrawRequestService) releaseInterruptedProof(requestID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := s.withdrawRepo.GetByID(ctx, requestID)
+	if err != nil {
+		log.Printf("❌ [autoGenerateProof] Failed to load interrupted request %s: %v", requestID, err)
+		return
+	}
+	if request.ProofStatus != models.ProofStatusInProgress {
+		return
+	}
+	if err := s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusPending, "", "", ""); err != nil {
+		log.Printf("❌ [autoGenerateProof] Failed to reset interrupted request %s to pending: %v", requestID, err)
+		return
+	}
+	log.Printf("🔄 [autoGenerateProof] Proof generation interrupted by shutdown, request %s reset to pending", requestID)
+}
+
+// mustRetryLimit refuses a retry cap below 1, which would block every retry of its stage
+// config.ValidateWithdrawConfig reports the same at startup, so this only trips for callers that skip it.
+func mustRetryLimit(name string, value int) int {
+	if value < 1 {
+		panic(fmt.Sprintf("withdraw.%s must be >= 1, got %d", name, value))
+	}
+	return value
+}
+
+// checkRetryLimit returns ErrMaxRetriesExceeded once a stage has used up its retry cap
+func (s *WithdrawRequestService) checkRetryLimit(stage models.RetryStage, retries int) error {
+	var limit int
+	switch stage {
+	case models.RetryStagePayout:
+		limit = s.maxPayoutRetries
+	case models.RetryStageHook:
+		limit = s.maxHookRetries
+	case models.RetryStageFallback:
+		limit = s.maxFallbackRetries
+	default:
+		return fmt.Errorf("unknown retry stage %q", stage)
+	}
+	if retries >= limit {
+		return ErrMaxRetriesExceeded
 	}
+	return nil
+}
+
+// quickCheckDelays converts configured quick-check delays (seconds) to durations, skipping invalid values
+func quickCheckDelays(seconds []int) []time.Duration {
+	delays := make([]time.Duration, 0, len(seconds))
+	for _, sec := range seconds {
+		if sec <= 0 {
+			log.Printf("⚠️ [WithdrawRequestService] Ignoring invalid withdraw.quick_check_delays value: %d", sec)
+			continue
+		}
+		delays = append(delays, time.Duration(sec)*time.Second)
+	}
+	return delays
 }
 
 // SetZKVMClient sets the ZKVM client for auto-triggering proof generation
@@ -70,6 +248,11 @@ func (s *WithdrawRequestService) SetZKVMClient(client *clients.ZKVMClient) {
 	s.zkvmClient = client
 }
 
+// SetRateLimiter replaces the per-owner creation limiter (nil disables limiting)
+func (s *WithdrawRequestService) SetRateLimiter(limiter WithdrawRateLimiter) {
+	s.rateLimiter = limiter
+}
+
 // SetBlockchainService sets the blockchain transaction service for auto-submitting transactions
 func (s *WithdrawRequestService) SetBlockchainService(service *BlockchainTransactionService) {
 	s.blockchainService = service
@@ -91,6 +274,16 @@ func (s *WithdrawRequestService) SetProofGenerationService(service *ProofGenerat
 	s.proofGenerationService = service
 }
 
+// SetTronTransactionService sets the TRON transaction service for submissions to TRON (SLIP-44 195)
+func (s *WithdrawRequestService) SetTronTransactionService(service *TronTransactionService) {
+	s.tronService = service
+}
+
+// SetPayoutExecutor sets the payout executor (multisig/LiFi) used by ProcessPayout
+func (s *WithdrawRequestService) SetPayoutExecutor(executor PayoutExecutor) {
+	s.payoutExecutor = executor
+}
+
 // updateChecksStatusOnFailure 在提交失败时更新关联的 Check 状态
 func (s *WithdrawRequestService) updateChecksStatusOnFailure(ctx context.Context, requestID string, executeStatus models.ExecuteStatus) error {
 	// 获取与 WithdrawRequest 关联的所有 Check IDs
@@ -141,33 +334,201 @@ type CreateWithdrawRequestInput struct {
 	Intent        models.Intent // Intent object
 	Signature     string        // User signature for ZKVM proof generation
 	ChainID       uint32        // Chain ID for signature (SLIP-44)
+
+	// ZKVM message language (lang), 0 = default (English)
+	Lang uint8
+
+	// Recovery only: on-chain RequestId to use as WithdrawNullifier instead of allocations[0].Nullifier
+	// (0x-prefixed bytes32), for recreating a request that matches an already-emitted RequestId
+	OverrideWithdrawNullifier string
+
+	// Optional client-chosen key: a retried create with the same key returns the existing request
+	IdempotencyKey string
+}
+
+// normalizeOverrideNullifier validates an override RequestId (bytes32 hex) and returns it lowercase with 0x prefix
+func normalizeOverrideNullifier(nullifier string) (string, error) {
+	raw := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(nullifier)), "0x")
+	if decoded, err := hex.DecodeString(raw); err != nil || len(decoded) != 32 {
+		return "", fmt.Errorf("%w: %q (expected 0x-prefixed bytes32)", ErrInvalidOverrideNullifier, nullifier)
+	}
+	return "0x" + raw, nil
+}
+
+// normalizeHookCalldata validates Intent hook calldata (non-empty hex) and returns it lowercase with 0x prefix
+func normalizeHookCalldata(calldata string) (string, error) {
+	raw := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(calldata)), "0x")
+	if decoded, err := hex.DecodeString(raw); err != nil || len(decoded) == 0 {
+		return "", fmt.Errorf("%w: hookCalldata must be 0x-prefixed hex bytes", ErrInvalidIntent)
+	}
+	return "0x" + raw, nil
+}
+
+// defaultHookCalldata the normalized withdraw.default_hook_calldata, "" when the default hook is disabled
+// The calldata is checked by config.ValidateWithdrawConfig at startup; invalid calldata disables the default hook.
+func defaultHookCalldata(cfg config.WithdrawConfig) string {
+	if !cfg.DefaultHookEnabled {
+		return ""
+	}
+	calldata, err := normalizeHookCalldata(cfg.DefaultHookCalldata)
+	if err != nil {
+		log.Printf("⚠️ [WithdrawRequestService] Ignoring invalid withdraw.default_hook_calldata: %v", err)
+		return ""
+	}
+	return calldata
 }
 
 // CreateWithdrawRequest creates a new withdraw request
 // Stage 1 initial state: proof_status = pending, execute_status = pending, payout_status = pending
+// With an IdempotencyKey, a request the same owner already created with that key is returned instead of creating
+// another one, including when a concurrent create with the same key wins the race (allocations no longer idle,
+// unique index). Reusing a key for different allocations fails with ErrIdempotencyKeyReused.
 func (s *WithdrawRequestService) CreateWithdrawRequest(ctx context.Context, input *CreateWithdrawRequestInput) (*models.WithdrawRequest, error) {
+	owner, ownerErr := s.requestOwner(ctx, input.AllocationIDs)
+	if input.IdempotencyKey == "" || ownerErr != nil {
+		// Without an owner the allocations are unusable; createWithdrawRequest reports why
+		if err := s.checkCreateRateLimit(ctx, owner); err != nil {
+			return nil, err
+		}
+		return s.createWithdrawRequest(ctx, input)
+	}
+
+	if existing, err := s.findIdempotentRequest(ctx, owner, input); !errors.Is(err, gorm.ErrRecordNotFound) {
+		return existing, err
+	}
+
+	if err := s.checkCreateRateLimit(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	request, err := s.createWithdrawRequest(ctx, input)
+	if err != nil {
+		if existing, lookupErr := s.findIdempotentRequest(ctx, owner, input); lookupErr == nil {
+			log.Printf("🔁 [CreateWithdrawRequest] Concurrent create with idempotency key %q won (request %s), returning it", input.IdempotencyKey, existing.ID)
+			return existing, nil
+		}
+	}
+	return request, err
+}
+
+// findIdempotentRequest the request the owner already created with input.IdempotencyKey
+// Returns gorm.ErrRecordNotFound when there is none, ErrIdempotencyKeyReused when it was created for other allocations.
+func (s *WithdrawRequestService) findIdempotentRequest(ctx context.Context, owner *models.UniversalAddress, input *CreateWithdrawRequestInput) (*models.WithdrawRequest, error) {
+	existing, err := s.withdrawRepo.GetByIdempotencyKey(ctx, owner.SLIP44ChainID, owner.Data, input.IdempotencyKey)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	existingIDs, err := s.getAllocationIDs(existing)
+	if err != nil {
+		return nil, err
+	}
+	if !sameAllocationIDs(existingIDs, input.AllocationIDs) {
+		return nil, fmt.Errorf("%w: key %q belongs to request %s", ErrIdempotencyKeyReused, input.IdempotencyKey, existing.ID)
+	}
+	log.Printf("🔁 [CreateWithdrawRequest] Idempotency key %q already used by request %s, returning it", input.IdempotencyKey, existing.ID)
+	return existing, nil
+}
+
+// sameAllocationIDs reports whether a and b hold the same allocation IDs, in any order
+func sameAllocationIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, id := range a {
+		counts[id]++
+	}
+	for _, id := range b {
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+	return true
+}
+
+// requestOwner the Universal Address owning a create's allocations: the owner of the first allocation's checkbook
+// (validateAllocations checks that every allocation has the same owner)
+func (s *WithdrawRequestService) requestOwner(ctx context.Context, allocationIDs []string) (*models.UniversalAddress, error) {
+	if len(allocationIDs) == 0 {
+		return nil, ErrInvalidAllocations
+	}
+	allocation, err := s.allocationRepo.GetByID(ctx, allocationIDs[0])
+	if err != nil {
+		return nil, err
+	}
+	checkbook, err := s.checkbookRepo.GetByID(ctx, allocation.CheckbookID)
+	if err != nil {
+		return nil, err
+	}
+	return &checkbook.UserAddress, nil
+}
+
+// checkCreateRateLimit returns ErrRateLimited when the owner is over the creation limit
+// A nil owner (it could not be resolved) is not limited here; createWithdrawRequest rejects such inputs.
+func (s *WithdrawRequestService) checkCreateRateLimit(ctx context.Context, owner *models.UniversalAddress) error {
+	if s.rateLimiter == nil || owner == nil {
+		return nil
+	}
+
+	ownerKey := fmt.Sprintf("%d:%s", owner.SLIP44ChainID, strings.ToLower(strings.TrimSpace(owner.Data)))
+	if !s.rateLimiter.Allow(ctx, ownerKey) {
+		log.Printf("🚦 [CreateWithdrawRequest] Rate limited owner %s", ownerKey)
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// createWithdrawRequest validates the input, creates the request and locks its allocations
+func (s *WithdrawRequestService) createWithdrawRequest(ctx context.Context, input *CreateWithdrawRequestInput) (*models.WithdrawRequest, error) {
 	// Validate input
 	if len(input.AllocationIDs) == 0 {
 		return nil, ErrInvalidAllocations
 	}
-
-	// Get all allocations
-	var allocations []*models.Check
-	for _, id := range input.AllocationIDs {
-		alloc, err := s.allocationRepo.GetByID(ctx, id)
+	hookCalldata := s.defaultHookCalldata
+	if input.Intent.HookCalldata != "" {
+		normalized, err := normalizeHookCalldata(input.Intent.HookCalldata)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get allocation %s: %w", id, err)
+			return nil, err
 		}
-		allocations = append(allocations, alloc)
+		hookCalldata = normalized
+	}
+	if len(input.AllocationIDs) > s.maxAllocationsPerWithdraw {
+		return nil, fmt.Errorf("%w: %d allocations, at most %d per withdraw request",
+			ErrTooManyAllocations, len(input.AllocationIDs), s.maxAllocationsPerWithdraw)
+	}
+
+	// Get all allocations
+	allocations, err := s.allocationRepo.GetByIDs(ctx, input.AllocationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations: %w", err)
 	}
 
-	// Validate allocations
-	if err := s.validateAllocations(allocations); err != nil {
+	// Validate allocations (loads their checkbooks, in allocation order)
+	checkbooks, err := s.validateAllocations(ctx, allocations)
+	if err != nil {
 		return nil, err
 	}
 
+	// Each distinct checkbook becomes one CommitmentGroup of the proof input
+	checkbookIDs := make(map[string]struct{})
+	for _, alloc := range allocations {
+		checkbookIDs[alloc.CheckbookID] = struct{}{}
+	}
+	if len(checkbookIDs) > s.maxCheckbooksPerWithdraw {
+		return nil, fmt.Errorf("%w: allocations span %d checkbooks, at most %d per withdraw request",
+			ErrTooManyCheckbooks, len(checkbookIDs), s.maxCheckbooksPerWithdraw)
+	}
+
 	// Calculate total amount
-	totalAmount := s.calculateTotalAmount(allocations)
+	totalAmount, err := s.calculateTotalAmount(allocations)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate on-chain request ID = nullifiers[0]
 	// Note: Chain contract uses nullifiers[0] as the RequestID for tracking
@@ -186,32 +547,35 @@ func (s *WithdrawRequestService) CreateWithdrawRequest(ctx context.Context, inpu
 		}
 	}
 
-	log.Printf("✅ [CreateWithdrawRequest] All %d allocations have nullifiers. Using first nullifier as RequestID: %s", len(allocations), onChainRequestID)
+	if input.OverrideWithdrawNullifier != "" {
+		override, err := normalizeOverrideNullifier(input.OverrideWithdrawNullifier)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("🚨🚨🚨 [CreateWithdrawRequest] RECOVERY OVERRIDE: using WithdrawNullifier %s instead of allocations[0].Nullifier %s (allocation IDs: %v)",
+			override, onChainRequestID, input.AllocationIDs)
+		onChainRequestID = override
+	} else {
+		log.Printf("✅ [CreateWithdrawRequest] All %d allocations have nullifiers. Using first nullifier as RequestID: %s", len(allocations), onChainRequestID)
+	}
 
 	// Check if a withdraw request with this nullifier already exists
 	// Since validateAllocations already ensures allocations are IDLE, if an existing request exists,
 	// it must be from a previous failed/cancelled withdraw. We should delete it to allow creating a new one.
 	existingRequest, err := s.withdrawRepo.GetByNullifier(ctx, onChainRequestID)
-	if err == nil && existingRequest != nil {
-		// Existing request found - since allocations are IDLE (validated above),
-		// this means the previous request failed/was cancelled and allocations were released.
-		// Delete the old request to allow creating a new one with the same nullifier.
-		// This is safe because:
-		// 1. Allocations are IDLE (not locked/used by any active request)
-		// 2. Nullifier can be reused for IDLE allocations
-		if err := s.withdrawRepo.Delete(ctx, existingRequest.ID); err != nil {
-			return nil, fmt.Errorf("failed to delete existing withdraw request %s: %w", existingRequest.ID, err)
-		}
-		// Continue to create new request below
-	}
-	// If err != nil, it means no existing request found (gorm.ErrRecordNotFound), which is fine - proceed with creation
-
-	// Get checkbook to extract owner address
-	checkbook, err := s.checkbookRepo.GetByID(ctx, allocations[0].CheckbookID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get checkbook: %w", err)
+	if err == nil && existingRequest != nil && input.IdempotencyKey != "" &&
+		existingRequest.IdempotencyKey != nil && *existingRequest.IdempotencyKey == input.IdempotencyKey {
+		// Same create retried: never replace the request it already produced
+		return existingRequest, nil
+	}
+	if err != nil || existingRequest == nil {
+		// No existing request found (gorm.ErrRecordNotFound), nothing to replace
+		existingRequest = nil
 	}
 
+	// The first allocation's checkbook holds the owner address
+	checkbook := checkbooks[0]
+
 	// Create WithdrawRequest
 	request := &models.WithdrawRequest{
 		ID:                uuid.New().String(),
@@ -241,19 +605,27 @@ func (s *WithdrawRequestService) CreateWithdrawRequest(ctx context.Context, inpu
 		PayoutStatus: models.PayoutStatusPending,
 
 		// Stage 4: Hook Purchase (initial state)
-		HookStatus: models.HookStatusNotRequired, // Default: no hook
+		HookStatus:   models.HookStatusNotRequired, // Default: no hook
+		HookCalldata: hookCalldata,
 
 		// Main status
 		Status: string(models.WithdrawStatusCreated),
 
+		// Signature is stored so proof generation can be retried (RetryProofGeneration)
+		Signature:        input.Signature,
+		SignatureChainID: input.ChainID,
+		ProofLang:        input.Lang,
+
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-
-	// Note: Signature is not stored in WithdrawRequest model directly
-	// We'll need to store it temporarily or pass it to autoGenerateProof
-	// For now, we'll store it in a temporary field or pass via context
-	// TODO: Add Signature field to WithdrawRequest model if needed
+	if input.IdempotencyKey != "" {
+		request.IdempotencyKey = &input.IdempotencyKey
+	}
+	if s.requestTTL > 0 {
+		expiresAt := request.CreatedAt.Add(s.requestTTL)
+		request.ExpiresAt = &expiresAt
+	}
 
 	// Store allocation IDs as JSON
 	allocationIDsJSON, err := json.Marshal(input.AllocationIDs)
@@ -262,22 +634,45 @@ func (s *WithdrawRequestService) CreateWithdrawRequest(ctx context.Context, inpu
 	}
 	request.AllocationIDs = string(allocationIDsJSON)
 
-	// Create request in database
-	if err := s.withdrawRepo.Create(ctx, request); err != nil {
-		return nil, fmt.Errorf("failed to create withdraw request: %w", err)
+	// Intent (or the configured default) carries a hook: Stage 4 runs after payout
+	if request.HookCalldata != "" {
+		request.HookStatus = models.HookStatusPending
 	}
 
-	// Lock allocations (idle -> pending)
-	if err := s.allocationRepo.LockForWithdrawal(ctx, input.AllocationIDs, request.ID); err != nil {
-		// Rollback: delete the request
-		s.withdrawRepo.Delete(ctx, request.ID)
-		return nil, fmt.Errorf("failed to lock allocations: %w", err)
+	// Replace the old request, create the new one and lock its allocations (idle -> pending) together:
+	// if the lock fails nothing is written, the old request stays and no half-created request is left behind
+	err = s.transactor.InTransaction(ctx, func(repos repository.Repositories) error {
+		if existingRequest != nil {
+			// Existing request found - since allocations are IDLE (validated above),
+			// this means the previous request failed/was cancelled and allocations were released.
+			// Soft-delete the old request to allow creating a new one with the same nullifier
+			// (it stays available for audits via FindDeleted).
+			// This is safe because:
+			// 1. Allocations are IDLE (not locked/used by any active request)
+			// 2. Nullifier can be reused for IDLE allocations
+			if err := repos.WithdrawRequests.Delete(ctx, existingRequest.ID); err != nil {
+				return fmt.Errorf("failed to delete existing withdraw request %s: %w", existingRequest.ID, err)
+			}
+		}
+		if err := repos.WithdrawRequests.Create(ctx, request); err != nil {
+			return fmt.Errorf("failed to create withdraw request: %w", err)
+		}
+		if err := repos.Allocations.LockForWithdrawal(ctx, input.AllocationIDs, request.ID); err != nil {
+			if errors.Is(err, repository.ErrAllocationLockConflict) {
+				return fmt.Errorf("%w: %v", ErrAllocationsNotIdle, err)
+			}
+			return fmt.Errorf("failed to lock allocations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Auto-trigger ZKVM proof generation (if ZKVM client is available)
 	if s.zkvmClient != nil {
 		log.Printf("🚀 [CreateWithdrawRequest] Auto-triggering ZKVM proof generation for request: %s", request.ID)
-		go s.autoGenerateProofWithSignature(context.Background(), request.ID, input.Signature, input.ChainID)
+		s.startProofGeneration(request.ID, input.Signature, input.ChainID)
 	} else {
 		log.Printf("⚠️ [CreateWithdrawRequest] ZKVM client not set, proof generation will not be auto-triggered")
 		log.Printf("   → Use SetZKVMClient() to enable auto-triggering")
@@ -287,9 +682,9 @@ func (s *WithdrawRequestService) CreateWithdrawRequest(ctx context.Context, inpu
 }
 
 // autoGenerateProofWithSignature automatically generates ZKVM proof for a withdraw request
-// This is called asynchronously after CreateWithdrawRequest
-// signature and chainID are passed separately since they're not stored in WithdrawRequest model
+// This is called asynchronously after CreateWithdrawRequest and RetryProofGeneration
 func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Context, requestID string, signature string, chainID uint32) {
+	ctx = repository.WithTransitionTrigger(ctx, "autoGenerateProof")
 	log.Printf("🔄 [autoGenerateProof] Starting proof generation for request: %s", requestID)
 
 	// Get withdraw request
@@ -320,15 +715,11 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		return
 	}
 
-	var allocations []*models.Check
-	for _, id := range allocationIDs {
-		alloc, err := s.allocationRepo.GetByID(ctx, id)
-		if err != nil {
-			log.Printf("❌ [autoGenerateProof] Failed to get allocation %s: %v", id, err)
-			s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Failed to get allocation %s: %v", id, err))
-			return
-		}
-		allocations = append(allocations, alloc)
+	allocations, err := s.allocationRepo.GetByIDs(ctx, allocationIDs)
+	if err != nil {
+		log.Printf("❌ [autoGenerateProof] Failed to get allocations: %v", err)
+		s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Failed to get allocations: %v", err))
+		return
 	}
 
 	if len(allocations) == 0 {
@@ -338,48 +729,27 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 	}
 
 	// Group allocations by checkbook (support cross-deposit withdrawals)
-	checkbookGroups := make(map[string][]*models.Check) // key: checkbookID, value: allocations
-	for _, alloc := range allocations {
-		checkbookGroups[alloc.CheckbookID] = append(checkbookGroups[alloc.CheckbookID], alloc)
-	}
+	checkbookGroups, checkbookIDs := groupAllocationsByCheckbook(allocations)
 
 	log.Printf("📋 [autoGenerateProof] Allocations grouped into %d checkbook(s)", len(checkbookGroups))
 	for checkbookID, groupAllocs := range checkbookGroups {
 		log.Printf("   - Checkbook %s: %d allocations", checkbookID, len(groupAllocs))
 	}
 
-	// Get all checkbooks and verify they belong to the same user
-	var checkbooks []*models.Checkbook
-	var firstCheckbook *models.Checkbook
-	firstOwnerAddress := ""
-	firstOwnerChainID := uint32(0)
-
-	for checkbookID := range checkbookGroups {
-		checkbook, err := s.checkbookRepo.GetByID(ctx, checkbookID)
-		if err != nil {
-			log.Printf("❌ [autoGenerateProof] Failed to get checkbook %s: %v", checkbookID, err)
-			s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Failed to get checkbook %s: %v", checkbookID, err))
-			return
-		}
-		checkbooks = append(checkbooks, checkbook)
+	// Get all checkbooks (single query, in allocation order) and verify they belong to the same user
+	checkbooks, err := s.checkbookRepo.GetByIDs(ctx, checkbookIDs)
+	if err != nil {
+		log.Printf("❌ [autoGenerateProof] Failed to get checkbooks: %v", err)
+		s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Failed to get checkbooks: %v", err))
+		return
+	}
 
-		if firstCheckbook == nil {
-			firstCheckbook = checkbook
-			firstOwnerAddress = strings.ToLower(checkbook.UserAddress.Data)
-			firstOwnerChainID = checkbook.UserAddress.SLIP44ChainID
-		} else {
-			// Verify owner address matches
-			ownerAddress := strings.ToLower(checkbook.UserAddress.Data)
-			if ownerAddress != firstOwnerAddress || checkbook.UserAddress.SLIP44ChainID != firstOwnerChainID {
-				log.Printf("❌ [autoGenerateProof] Checkbook %s belongs to different user: %s (chain=%d) vs %s (chain=%d)",
-					checkbookID, checkbook.UserAddress.Data, checkbook.UserAddress.SLIP44ChainID,
-					firstCheckbook.UserAddress.Data, firstCheckbook.UserAddress.SLIP44ChainID)
-				s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "",
-					"All checkbooks must belong to the same user")
-				return
-			}
-		}
+	if err := validateProofCheckbooks(checkbooks); err != nil {
+		log.Printf("❌ [autoGenerateProof] %v", err)
+		s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", err.Error())
+		return
 	}
+	firstCheckbook := checkbooks[0]
 
 	log.Printf("✅ [autoGenerateProof] All %d checkbook(s) belong to the same user: %s (chain=%d)",
 		len(checkbooks), firstCheckbook.UserAddress.Data, firstCheckbook.UserAddress.SLIP44ChainID)
@@ -391,95 +761,22 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		return
 	}
 
-	// Verify all checkbooks have commitments
-	for _, cb := range checkbooks {
-		if cb.Commitment == nil || *cb.Commitment == "" {
-			log.Printf("❌ [autoGenerateProof] Checkbook %s has no commitment", cb.ID)
-			s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Checkbook %s has no commitment", cb.ID))
-			return
-		}
-	}
-
-	// Get source token symbol (use first checkbook's token, verify all use same token)
-	sourceTokenSymbol := ""
-	for i, cb := range checkbooks {
-		var tokenKey string
-		if cb.TokenKey != "" {
-			tokenKey = cb.TokenKey
-		} else if s.intentService != nil && cb.TokenAddress != "" {
-			var rawToken models.IntentRawToken
-			err := s.intentService.DB().Where("token_address = ? AND chain_id = ?", strings.ToLower(cb.TokenAddress), cb.SLIP44ChainID).First(&rawToken).Error
-			if err == nil && rawToken.Symbol != "" {
-				tokenKey = rawToken.Symbol
-			} else {
-				tokenKey = "USDT" // Fallback
-			}
-		} else {
-			tokenKey = "USDT" // Fallback
-		}
-
-		if i == 0 {
-			sourceTokenSymbol = tokenKey
-			log.Printf("✅ [autoGenerateProof] Using token key from first checkbook: %s", sourceTokenSymbol)
-		} else if tokenKey != sourceTokenSymbol {
-			log.Printf("⚠️ [autoGenerateProof] Checkbook %s uses different token: %s vs %s (using first)", cb.ID, tokenKey, sourceTokenSymbol)
-			// Continue with first checkbook's token (ZKVM may support mixed tokens, but we'll use first for now)
-		}
-	}
-
-	// Build CommitmentGroups for each checkbook
-	// Use a struct to store commitment group with its deposit_id for sorting
-	type commitmentGroupWithDepositID struct {
-		commitmentGroup types.CommitmentGroupRequest
-		depositID       uint64
-	}
-	commitmentGroupsWithDepositID := make([]commitmentGroupWithDepositID, 0, len(checkbooks))
-
-	for _, checkbook := range checkbooks {
-		// Get allocations for this checkbook
-		checkbookAllocations := checkbookGroups[checkbook.ID]
-
-		// Get ALL allocations from this checkbook (for computing left/right hashes)
-		allCheckbookAllocations, err := s.allocationRepo.FindByCheckbook(ctx, checkbook.ID)
-		if err != nil {
-			log.Printf("❌ [autoGenerateProof] Failed to get all allocations for checkbook %s: %v", checkbook.ID, err)
-			s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Failed to get checkbook allocations: %v", err))
-			return
-		}
-
-		log.Printf("📋 [autoGenerateProof] Checkbook %s: %d total allocations, %d in withdraw request",
-			checkbook.ID, len(allCheckbookAllocations), len(checkbookAllocations))
-
-		// Build CommitmentGroup for this checkbook
-		commitmentGroup, err := s.buildCommitmentGroupForCheckbook(ctx, checkbook, checkbookAllocations, allCheckbookAllocations)
-		if err != nil {
-			log.Printf("❌ [autoGenerateProof] Failed to build CommitmentGroup for checkbook %s: %v", checkbook.ID, err)
-			s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Failed to build CommitmentGroup: %v", err))
-			return
-		}
-
-		commitmentGroupsWithDepositID = append(commitmentGroupsWithDepositID, commitmentGroupWithDepositID{
-			commitmentGroup: *commitmentGroup,
-			depositID:       checkbook.LocalDepositID,
-		})
-		log.Printf("✅ [autoGenerateProof] Built CommitmentGroup for checkbook %s (deposit_id: %d): %d allocations",
-			checkbook.ID, checkbook.LocalDepositID, len(commitmentGroup.Allocations))
+	// Source token symbol: the ZKVM request carries one symbol for all CommitmentGroups, so every checkbook
+	// must hold the same token. Rejected at creation (validateAllocations); re-checked for older requests.
+	sourceTokenSymbol, err := s.sourceTokenSymbol(checkbooks)
+	if err != nil {
+		log.Printf("❌ [autoGenerateProof] %v", err)
+		s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", err.Error())
+		return
 	}
+	log.Printf("✅ [autoGenerateProof] Source token symbol: %s", sourceTokenSymbol)
 
-	// Sort commitment groups by deposit_id (ascending)
-	// Within the same deposit_id, maintain the original order (which is by checkbook)
-	sort.Slice(commitmentGroupsWithDepositID, func(i, j int) bool {
-		if commitmentGroupsWithDepositID[i].depositID != commitmentGroupsWithDepositID[j].depositID {
-			return commitmentGroupsWithDepositID[i].depositID < commitmentGroupsWithDepositID[j].depositID
-		}
-		// If deposit_id is the same, maintain original order (by checkbook ID)
-		return false
-	})
-
-	// Extract sorted commitment groups
-	commitmentGroups := make([]types.CommitmentGroupRequest, 0, len(commitmentGroupsWithDepositID))
-	for _, cg := range commitmentGroupsWithDepositID {
-		commitmentGroups = append(commitmentGroups, cg.commitmentGroup)
+	// Build CommitmentGroups for each checkbook, sorted by deposit_id
+	commitmentGroups, err := s.buildCommitmentGroups(ctx, checkbooks, checkbookGroups)
+	if err != nil {
+		log.Printf("❌ [autoGenerateProof] %v", err)
+		s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", err.Error())
+		return
 	}
 
 	log.Printf("✅ [autoGenerateProof] Built and sorted %d CommitmentGroup(s) by deposit_id for %d checkbook(s)", len(commitmentGroups), len(checkbooks))
@@ -549,9 +846,9 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		Intent:            *intentRequest,
 		Signature:         signatureRequest,
 		SourceTokenSymbol: sourceTokenSymbol,
-		Lang:              0,   // Default to English
-		SourceChainName:   nil, // Optional
-		TargetChainName:   nil, // Optional
+		Lang:              request.ProofLang,
+		SourceChainName:   chainDisplayName(firstCheckbook.SLIP44ChainID),
+		TargetChainName:   chainDisplayName(request.TargetSLIP44ChainID),
 		MinOutput:         nil, // Optional
 	}
 
@@ -573,18 +870,12 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		}
 
 		// 确保 recipient 有 0x 前缀且是 32 字节格式（66 字符：0x + 64 hex）
-		recipient := request.Recipient.Data
-		// 移除可能存在的 0x 前缀，统一处理
-		recipient = strings.TrimPrefix(recipient, "0x")
-		// 补齐到 32 字节（64 hex chars）
-		if len(recipient) < 64 {
-			recipient = strings.Repeat("0", 64-len(recipient)) + recipient
-		} else if len(recipient) > 64 {
-			// 如果超过 64 字符，截取后 64 个字符
-			recipient = recipient[len(recipient)-64:]
-		}
-		// 添加 0x 前缀
-		recipient = "0x" + recipient
+		recipient, err := utils.PadToUniversalHex(request.Recipient.Data)
+		if err != nil {
+			log.Printf("❌ [autoGenerateProof] Invalid recipient address: %v", err)
+			s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", fmt.Sprintf("Invalid recipient address: %v", err))
+			return
+		}
 
 		submissionContext := &WithdrawSubmissionContext{
 			ChainID:           int(firstCheckbook.SLIP44ChainID),
@@ -667,6 +958,7 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 	log.Printf("🔍 [autoGenerateProof] 验证 ZKVM 返回的 nullifiers")
 	log.Printf("🔍 [autoGenerateProof] ========================================")
 
+	var nullifierMismatches []string // descriptions of each mismatch, empty when ZKVM and DB agree
 	if len(zkvmResponse.Nullifiers) == 0 {
 		log.Printf("⚠️ [autoGenerateProof] ZKVM response has no nullifiers array")
 		log.Printf("   This may indicate an issue with the ZKVM service response format")
@@ -689,6 +981,8 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		expectedNullifierNormalized := strings.ToLower(strings.TrimPrefix(expectedNullifier, "0x"))
 
 		if zkvmNullifierNormalized != expectedNullifierNormalized {
+			nullifierMismatches = append(nullifierMismatches,
+				fmt.Sprintf("first nullifier %s != withdraw_nullifier %s", zkvmFirstNullifier, expectedNullifier))
 			log.Printf("❌ [autoGenerateProof] Nullifier 不匹配！")
 			log.Printf("   ZKVM 返回的 nullifier 与数据库中的不一致")
 			log.Printf("   这可能导致链上事件无法匹配到 WithdrawRequest")
@@ -718,6 +1012,8 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 
 		// 验证所有 allocations 的 nullifiers
 		if len(zkvmResponse.Nullifiers) != len(allocations) {
+			nullifierMismatches = append(nullifierMismatches,
+				fmt.Sprintf("%d nullifiers for %d allocations", len(zkvmResponse.Nullifiers), len(allocations)))
 			log.Printf("⚠️ [autoGenerateProof] Nullifiers 数量不匹配：")
 			log.Printf("   ZKVM 返回: %d 个", len(zkvmResponse.Nullifiers))
 			log.Printf("   Allocations: %d 个", len(allocations))
@@ -741,14 +1037,27 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 			}
 
 			if mismatchCount > 0 {
+				nullifierMismatches = append(nullifierMismatches, fmt.Sprintf("%d allocation nullifier(s) differ", mismatchCount))
 				log.Printf("❌ [autoGenerateProof] 发现 %d 个 nullifier 不匹配", mismatchCount)
 			} else {
 				log.Printf("✅ [autoGenerateProof] 所有 nullifiers 验证通过！")
 			}
 		}
+
+		if err := s.withdrawRepo.UpdateNullifierMismatch(ctx, requestID, len(nullifierMismatches) > 0); err != nil {
+			log.Printf("⚠️ [autoGenerateProof] Failed to record nullifier_mismatch: %v", err)
+		}
 	}
 	log.Printf("🔍 [autoGenerateProof] ========================================\n")
 
+	// A proof whose nullifiers differ from the DB cannot be matched to its on-chain events
+	if len(nullifierMismatches) > 0 && s.strictNullifierCheck {
+		proofError := "ZKVM nullifiers do not match the database (strict_nullifier_check): " + strings.Join(nullifierMismatches, "; ")
+		log.Printf("❌ [autoGenerateProof] %s, not saving proof for request %s", proofError, requestID)
+		s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusFailed, "", "", proofError)
+		return
+	}
+
 	// Log preview of data being saved
 	if len(zkvmResponse.ProofData) > 100 {
 		log.Printf("   ProofData preview: %s...", zkvmResponse.ProofData[:100])
@@ -761,12 +1070,17 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		log.Printf("   PublicValues: %s", zkvmResponse.PublicValues)
 	}
 
-	if err := s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusCompleted, zkvmResponse.ProofData, zkvmResponse.PublicValues, ""); err != nil {
+	request, err = s.saveGeneratedProof(ctx, requestID, zkvmResponse.ProofData, zkvmResponse.PublicValues)
+	if errors.Is(err, ErrWithdrawRequestClosed) {
+		log.Printf("⚠️ [autoGenerateProof] Discarding proof for request %s: %v", requestID, err)
+		return
+	}
+	if err != nil {
 		log.Printf("❌ [autoGenerateProof] Failed to save proof: %v", err)
 		return
 	}
 
-	log.Printf("✅ [autoGenerateProof] Proof and PublicValues saved to database successfully")
+	log.Printf("✅ [autoGenerateProof] Proof and PublicValues saved to database successfully, main status: %s", request.Status)
 
 	// ========== 更新 withdraw_nullifier 为 public_values 中的第一个 nullifier ==========
 	// 这是关键修复：链上的 request_id 是 public_values[0]（第一个 nullifier）
@@ -804,23 +1118,6 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 		log.Printf("⚠️ [autoGenerateProof] public_values 中没有 nullifiers")
 	}
 
-	// Update main status
-	// ⚠️ CRITICAL: Do NOT use Update() with the request object from GetByID!
-	// Update() uses Save() which will overwrite ALL fields, including proof and public_values
-	// If GetByID didn't load these fields correctly, Save() will clear them!
-	// Instead, only update the status field using Updates()
-	request.ProofStatus = models.ProofStatusCompleted
-	request.UpdateMainStatus()
-
-	// Use Updates() to only update status field, not the entire record
-	// This prevents accidentally clearing proof and public_values
-	if err := s.withdrawRepo.UpdateStatus(ctx, request.ID, request.Status); err != nil {
-		log.Printf("❌ [autoGenerateProof] Failed to update main status: %v", err)
-		return
-	}
-
-	log.Printf("✅ [autoGenerateProof] Main status updated to: %s (using UpdateStatus to avoid clearing proof/public_values)", request.Status)
-
 	log.Printf("✅ [autoGenerateProof] Proof saved successfully, auto-triggering ExecuteWithdraw for request %s", requestID)
 
 	// Auto-trigger Stage 2: Execute on-chain verification
@@ -833,9 +1130,28 @@ func (s *WithdrawRequestService) autoGenerateProofWithSignature(ctx context.Cont
 	log.Printf("✅ [autoGenerateProof] Full flow completed successfully for request %s", requestID)
 }
 
+// saveGeneratedProof stores a generated proof and recomputes the main status on the current row
+// The request may have been cancelled or manually resolved while its proof was generated: a terminal request keeps
+// its status and ErrWithdrawRequestClosed is returned, so the caller must not execute it.
+func (s *WithdrawRequestService) saveGeneratedProof(ctx context.Context, requestID, proof, publicValues string) (*models.WithdrawRequest, error) {
+	return s.withdrawRepo.Modify(ctx, requestID, func(fresh *models.WithdrawRequest) error {
+		if fresh.IsTerminal() {
+			return fmt.Errorf("%w: status=%s", ErrWithdrawRequestClosed, fresh.Status)
+		}
+		now := time.Now()
+		fresh.ProofStatus = models.ProofStatusCompleted
+		fresh.Proof = proof
+		fresh.PublicValues = publicValues
+		fresh.ProofGeneratedAt = &now
+		fresh.UpdateMainStatus()
+		return nil
+	})
+}
+
 // SubmitProof submits ZK proof for the withdraw request (Stage 1)
 // After proof is saved, automatically triggers Stage 2 (on-chain verification)
 func (s *WithdrawRequestService) SubmitProof(ctx context.Context, requestID string, proof string, publicValues string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "SubmitProof")
 	// Update proof status to in_progress
 	if err := s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusInProgress, "", "", ""); err != nil {
 		return err
@@ -851,12 +1167,10 @@ func (s *WithdrawRequestService) SubmitProof(ctx context.Context, requestID stri
 	}
 
 	// Update main status
-	request, err := s.withdrawRepo.GetByID(ctx, requestID)
-	if err != nil {
-		return err
-	}
-	request.UpdateMainStatus()
-	if err := s.withdrawRepo.Update(ctx, request); err != nil {
+	if _, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		request.UpdateMainStatus()
+		return nil
+	}); err != nil {
 		return err
 	}
 
@@ -879,6 +1193,7 @@ func (s *WithdrawRequestService) SubmitProof(ctx context.Context, requestID stri
 // 2. Manually by frontend using POST /api/v1/withdrawals/:id/execute (retry)
 // 3. By event listener for automatic retry
 func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "ExecuteWithdraw")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -889,57 +1204,53 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 	if request.ProofStatus != models.ProofStatusCompleted {
 		if request.Proof != "" && request.PublicValues != "" {
 			// We have proof data, so proof generation was successful, just status wasn't updated
-			log.Printf("⚠️ [ExecuteWithdraw] proof_status is %s but proof data exists, updating to completed", request.ProofStatus)
+			s.logger.Warn("[ExecuteWithdraw] proof_status is not completed but proof data exists, updating to completed",
+				"request_id", requestID, "proof_status", request.ProofStatus)
 			if err := s.withdrawRepo.UpdateProofStatus(ctx, requestID, models.ProofStatusCompleted, request.Proof, request.PublicValues, ""); err != nil {
-				log.Printf("❌ [ExecuteWithdraw] Failed to update proof_status to completed: %v", err)
+				s.logger.Error("[ExecuteWithdraw] Failed to update proof_status to completed", "request_id", requestID, "error", err)
 				return fmt.Errorf("failed to update proof status: %w", err)
 			}
-			log.Printf("✅ [ExecuteWithdraw] Updated proof_status to completed before submission")
+			s.logger.Info("[ExecuteWithdraw] Updated proof_status to completed before submission", "request_id", requestID)
 		} else {
 			// No proof data, cannot proceed
-			return fmt.Errorf("proof not completed (status: %s) and no proof data available", request.ProofStatus)
+			return fmt.Errorf("%w (status: %s) and no proof data available", ErrProofNotCompleted, request.ProofStatus)
 		}
 	}
 
 	// Check if already successfully executed (prevent duplicate execution)
-	if request.ExecuteStatus == models.ExecuteStatusSuccess {
-		return errors.New("withdraw already executed successfully")
+	if request.IsExecuteFinal() {
+		return ErrAlreadyExecuted
 	}
 
 	// Check if verification already failed (cannot retry)
 	if request.ExecuteStatus == models.ExecuteStatusVerifyFailed {
-		return errors.New("verification failed permanently, cannot retry - please cancel the request")
+		return ErrVerificationFailed
 	}
 
 	// Check if blockchain service is available
 	if s.blockchainService == nil {
-		log.Printf("⚠️ [ExecuteWithdraw] Blockchain service not set, cannot submit transaction")
-		log.Printf("   Service instance: %p", s)
-		log.Printf("   blockchainService field: %p", s.blockchainService)
-		log.Printf("   → Use SetBlockchainService() to enable auto-submission")
-		log.Printf("   → Check if InitializeClients() was called successfully during startup")
+		s.logger.Warn("[ExecuteWithdraw] Blockchain service not set, cannot submit transaction (use SetBlockchainService() to enable auto-submission)",
+			"request_id", requestID)
 		// Update to submit_failed so user can retry when service is available
 		if err := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusSubmitFailed, "", nil, "Blockchain service not configured"); err != nil {
 			return err
 		}
-		return fmt.Errorf("blockchain service not configured")
+		return ErrBlockchainUnavailable
 	}
 
 	// Verify blockchain service has initialized clients
 	clientCount := s.blockchainService.GetClientCount()
-	log.Printf("🔍 [ExecuteWithdraw] Blockchain service available, client count: %d", clientCount)
 	if clientCount == 0 {
-		log.Printf("⚠️ [ExecuteWithdraw] Blockchain service has no initialized clients")
-		log.Printf("   → InitializeClients() may have failed or not been called")
+		s.logger.Warn("[ExecuteWithdraw] Blockchain service has no initialized clients, initializing now", "request_id", requestID)
 		// Try to initialize clients now
 		if err := s.blockchainService.InitializeClients(); err != nil {
-			log.Printf("❌ [ExecuteWithdraw] Failed to initialize clients: %v", err)
+			s.logger.Error("[ExecuteWithdraw] Failed to initialize clients", "request_id", requestID, "error", err)
 			if updateErr := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusSubmitFailed, "", nil, fmt.Sprintf("Failed to initialize blockchain clients: %v", err)); updateErr != nil {
 				return updateErr
 			}
 			return fmt.Errorf("failed to initialize blockchain clients: %w", err)
 		}
-		log.Printf("✅ [ExecuteWithdraw] Successfully initialized blockchain clients")
+		s.logger.Info("[ExecuteWithdraw] Successfully initialized blockchain clients", "request_id", requestID)
 	}
 
 	// Get allocations to extract checkbook and token information
@@ -952,17 +1263,17 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 		return fmt.Errorf("no allocations found for withdraw request")
 	}
 
-	// Get first allocation to get checkbook info
-	firstAllocation, err := s.allocationRepo.GetByID(ctx, allocationIDs[0])
+	// Get allocations and their checkbooks (one query each); the first allocation's checkbook gives chain and token
+	allocations, err := s.allocationRepo.GetByIDs(ctx, allocationIDs)
 	if err != nil {
-		return fmt.Errorf("failed to get first allocation: %w", err)
+		return fmt.Errorf("failed to get allocations: %w", err)
 	}
-
-	// Get checkbook to get chain ID and token info
-	checkbook, err := s.checkbookRepo.GetByID(ctx, firstAllocation.CheckbookID)
+	_, checkbookIDs := groupAllocationsByCheckbook(allocations)
+	checkbooks, err := s.checkbookRepo.GetByIDs(ctx, checkbookIDs)
 	if err != nil {
-		return fmt.Errorf("failed to get checkbook: %w", err)
+		return fmt.Errorf("failed to get checkbooks: %w", err)
 	}
+	checkbook := checkbooks[0]
 
 	// Get chain ID from checkbook (SLIP-44)
 	chainID := int(checkbook.SLIP44ChainID)
@@ -971,43 +1282,16 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 	tokenKey := "USDT" // Default
 	if checkbook.TokenKey != "" {
 		tokenKey = checkbook.TokenKey
-		log.Printf("📋 [retry] Using token key from Checkbook: %s", tokenKey)
 	} else {
-		log.Printf("⚠️ [retry] Checkbook.TokenKey is empty, using default: %s", tokenKey)
+		s.logger.Warn("[ExecuteWithdraw] Checkbook.TokenKey is empty, using default",
+			"request_id", requestID, "checkbook_id", checkbook.ID, "token_key", tokenKey)
 	}
 
 	// Build recipient address (32-byte Universal Address)
 	// 确保 recipient 有 0x 前缀且是 32 字节格式（66 字符：0x + 64 hex）
-	recipientHex := request.Recipient.Data
-	// 移除可能存在的 0x 前缀，统一处理
-	recipientHex = strings.TrimPrefix(recipientHex, "0x")
-	// 补齐到 32 字节（64 hex chars）
-	if len(recipientHex) < 64 {
-		recipientHex = strings.Repeat("0", 64-len(recipientHex)) + recipientHex
-	} else if len(recipientHex) > 64 {
-		// 如果超过 64 字符，截取后 64 个字符
-		recipientHex = recipientHex[len(recipientHex)-64:]
-	}
-	// 添加 0x 前缀
-	recipientHex = "0x" + recipientHex
-
-	// Debug: Check if proof and public values are loaded correctly
-	log.Printf("🔍 [ExecuteWithdraw] Debug - Checking request data:")
-	log.Printf("   request.Proof length: %d bytes", len(request.Proof))
-	log.Printf("   request.PublicValues length: %d bytes", len(request.PublicValues))
-	if len(request.PublicValues) > 0 {
-		previewLen := 100
-		if len(request.PublicValues) < previewLen {
-			previewLen = len(request.PublicValues)
-		}
-		log.Printf("   request.PublicValues preview: %s...", request.PublicValues[:previewLen])
-	}
-	if len(request.Proof) > 0 {
-		previewLen := 100
-		if len(request.Proof) < previewLen {
-			previewLen = len(request.Proof)
-		}
-		log.Printf("   request.Proof preview: %s...", request.Proof[:previewLen])
+	recipientHex, err := utils.PadToUniversalHex(request.Recipient.Data)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
 	}
 
 	// Build blockchain transaction request
@@ -1025,10 +1309,18 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 		PublicValues:      request.PublicValues,    // ZKVM public values (encoded, from zkvmResponse.PublicValues)
 		Token:             request.TokenIdentifier, // Token contract address (for RawToken)
 		TokenKey:          tokenKey,
+		IntentType:        uint8(request.IntentType),
+		AssetID:           request.AssetID, // For AssetToken
 		CheckbookID:       checkbook.ID,
-		CheckID:           firstAllocation.ID,
+		CheckID:           allocations[0].ID,
+		PreviousTxHash:    request.ExecuteTxHash, // a still-pending earlier submission may be replaced
 	}
 
+	// Chain executeWithdraw is sent to, resolved once: simulation, submission, receipt checks, polling and
+	// confirmation depth all use it (management chain by default, the beneficiary chain when routed there)
+	submitChainID := resolveSubmissionChain(submissionWithdraw, int(request.TargetSLIP44ChainID))
+	blockchainReq.SubmitChainID = submitChainID
+
 	// Validate that proof and public values are present
 	if blockchainReq.SP1Proof == "" {
 		return fmt.Errorf("proof data is empty - cannot submit transaction")
@@ -1037,60 +1329,74 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 		return fmt.Errorf("public values is empty - cannot submit transaction. Proof status: %s", request.ProofStatus)
 	}
 
-	// Update execute status to submitted BEFORE submitting transaction
-	log.Printf("🔄 [ExecuteWithdraw] Updating execute_status to 'submitted' for request %s", requestID)
-	log.Printf("   Current execute_status: %s", request.ExecuteStatus)
-	if err := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusSubmitted, "", nil, ""); err != nil {
-		log.Printf("❌ [ExecuteWithdraw] Failed to update execute_status to 'submitted': %v", err)
-		return fmt.Errorf("failed to update execute status to submitted: %w", err)
+	// Optionally simulate via eth_call first (EVM submission chains only): a revert means the proof/nullifier
+	// is known-bad, so mark verify_failed without spending gas
+	if s.executeSimulateFirst && !clients.IsTronChain(uint32(submitChainID)) {
+		if err := s.blockchainService.SimulateWithdraw(blockchainReq); err != nil {
+			if errors.Is(err, ErrSimulationReverted) {
+				s.logger.Error("[ExecuteWithdraw] Simulation reverted, not sending transaction", "request_id", requestID,
+					"status", models.ExecuteStatusVerifyFailed, "error", err)
+				if updateErr := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusVerifyFailed, "", nil, err.Error()); updateErr != nil {
+					s.logger.Error("[ExecuteWithdraw] Failed to update status to verify_failed", "request_id", requestID, "error", updateErr)
+				}
+				if updateErr := s.updateChecksStatusOnFailure(ctx, requestID, models.ExecuteStatusVerifyFailed); updateErr != nil {
+					s.logger.Warn("[ExecuteWithdraw] Failed to update checks status", "request_id", requestID, "error", updateErr)
+				}
+				return fmt.Errorf("verification failed (simulation): %w", err)
+			}
+			// Simulation unavailable (RPC error etc.) - fall through to the normal submission
+			s.logger.Warn("[ExecuteWithdraw] Simulation failed, submitting without simulation", "request_id", requestID, "error", err)
+		}
 	}
-	log.Printf("✅ [ExecuteWithdraw] Successfully updated execute_status to 'submitted' for request %s", requestID)
 
-	// Verify the update by querying the database
-	verifyRequest, verifyErr := s.withdrawRepo.GetByID(ctx, requestID)
-	if verifyErr != nil {
-		log.Printf("⚠️ [ExecuteWithdraw] Failed to verify update: %v", verifyErr)
-	} else {
-		log.Printf("🔍 [ExecuteWithdraw] Verification: execute_status=%s, proof_status=%s", verifyRequest.ExecuteStatus, verifyRequest.ProofStatus)
+	// Update execute status to submitted BEFORE submitting transaction
+	if err := s.claimExecuteSubmission(ctx, requestID); err != nil {
+		s.logger.Error("[ExecuteWithdraw] Failed to update execute_status to submitted", "request_id", requestID, "error", err)
+		return fmt.Errorf("failed to update execute status to submitted: %w", err)
 	}
+	s.logger.Info("[ExecuteWithdraw] Updated execute_status", "request_id", requestID,
+		"from_status", request.ExecuteStatus, "status", models.ExecuteStatusSubmitted)
 
 	// Submit transaction to blockchain
 	// Note: blockchainReq.PublicValues is from ZKVM response (saved in autoGenerateProofWithSignature)
 	// It's the encoded public values that ZKVM service returns, ready to use in executeWithdraw
-	log.Printf("📤 [ExecuteWithdraw] Submitting executeWithdraw transaction for request %s", requestID)
-	log.Printf("   Using PublicValues from ZKVM: %d bytes", len(blockchainReq.PublicValues))
-	log.Printf("   Using Proof from ZKVM: %d bytes", len(blockchainReq.SP1Proof))
-	withdrawResponse, err := s.blockchainService.SubmitWithdraw(blockchainReq)
+	// Route by the chain executeWithdraw is submitted to: EVM or TRON
+	s.logger.Info("[ExecuteWithdraw] Submitting executeWithdraw transaction", "request_id", requestID, "chain_id", submitChainID,
+		"public_values_bytes", len(blockchainReq.PublicValues), "proof_bytes", len(blockchainReq.SP1Proof))
+	chainTxService := s.submitter
+	if chainTxService == nil {
+		chainTxService = chainTransactionServiceFor(uint32(submitChainID), s.blockchainService, s.tronService)
+	}
+	withdrawResponse, err := chainTxService.SubmitWithdraw(blockchainReq)
 	if err != nil {
 		// Check if it's a contract revert (proof invalid, nullifier used, etc.)
 		errorMsg := err.Error()
-		isContractRevert := strings.Contains(errorMsg, "execution reverted") ||
-			strings.Contains(errorMsg, "revert") ||
-			strings.Contains(errorMsg, "invalid proof") ||
-			strings.Contains(errorMsg, "nullifier already used")
+		reason, detail := parseRevertReason(err, nil, nil)
 
-		if isContractRevert {
+		if reason.IsPermanent() {
 			// Proof invalid or nullifier already used - cannot retry
-			log.Printf("❌ [ExecuteWithdraw] Contract revert (verification failed): %v", err)
+			s.logger.Error("[ExecuteWithdraw] Contract revert (verification failed)", "request_id", requestID, "chain_id", submitChainID,
+				"status", models.ExecuteStatusVerifyFailed, "revert_reason", reason, "revert_detail", detail, "error", err)
 			if updateErr := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusVerifyFailed, "", nil, errorMsg); updateErr != nil {
-				log.Printf("❌ [ExecuteWithdraw] Failed to update status to verify_failed: %v", updateErr)
+				s.logger.Error("[ExecuteWithdraw] Failed to update status to verify_failed", "request_id", requestID, "error", updateErr)
 			}
 			// 立即更新关联的 Check 状态为 idle（释放 allocations，因为验证失败不可重试）
 			if updateErr := s.updateChecksStatusOnFailure(ctx, requestID, models.ExecuteStatusVerifyFailed); updateErr != nil {
-				log.Printf("⚠️ [ExecuteWithdraw] Failed to update checks status: %v", updateErr)
+				s.logger.Warn("[ExecuteWithdraw] Failed to update checks status", "request_id", requestID, "error", updateErr)
 			}
 			return fmt.Errorf("verification failed (contract revert): %w", err)
 		} else {
-			// Network/RPC error - can retry
-			log.Printf("⚠️ [ExecuteWithdraw] Network/RPC error (can retry): %v", err)
+			// Network/RPC error (or underfunded signer) - can retry
+			s.logger.Warn("[ExecuteWithdraw] Network/RPC error (can retry)", "request_id", requestID, "chain_id", submitChainID,
+				"status", models.ExecuteStatusSubmitFailed, "revert_reason", reason, "error", err)
 			if updateErr := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusSubmitFailed, "", nil, errorMsg); updateErr != nil {
-				log.Printf("❌ [ExecuteWithdraw] Failed to update status to submit_failed: %v", updateErr)
+				s.logger.Error("[ExecuteWithdraw] Failed to update status to submit_failed", "request_id", requestID, "error", updateErr)
 			}
 			// 立即更新关联的 Check 状态（提交失败，但可以重试，保持 pending 或标记为失败）
 			// 根据业务逻辑，submit_failed 可以重试，所以保持 pending 状态
 			// 但如果需要明确标记失败，可以更新 Check 状态
 			if updateErr := s.updateChecksStatusOnFailure(ctx, requestID, models.ExecuteStatusSubmitFailed); updateErr != nil {
-				log.Printf("⚠️ [ExecuteWithdraw] Failed to update checks status: %v", updateErr)
+				s.logger.Warn("[ExecuteWithdraw] Failed to update checks status", "request_id", requestID, "error", updateErr)
 			}
 			return fmt.Errorf("submit failed (network error): %w", err)
 		}
@@ -1098,146 +1404,118 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 
 	// Transaction submitted successfully
 	txHash := withdrawResponse.TxHash
-	log.Printf("✅ [ExecuteWithdraw] Transaction submitted successfully: %s", txHash)
+	s.logger.Info("[ExecuteWithdraw] Transaction submitted", "request_id", requestID, "chain_id", submitChainID,
+		"tx_hash", txHash, "status", models.ExecuteStatusSubmitted)
 
 	// Update status with TX hash (will update to success/failed after confirmation)
 	if err := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusSubmitted, txHash, nil, ""); err != nil {
-		log.Printf("⚠️ [ExecuteWithdraw] Failed to update TX hash: %v", err)
+		s.logger.Warn("[ExecuteWithdraw] Failed to update TX hash", "request_id", requestID, "tx_hash", txHash, "error", err)
 		// Don't return error - transaction was submitted successfully
 	}
+	// Record the chain it was sent to: the reorg check looks requests up by execute_chain_id
+	executeChainID := uint32(submitChainID)
+	if _, err := s.withdrawRepo.Modify(ctx, requestID, func(fresh *models.WithdrawRequest) error {
+		fresh.ExecuteChainID = &executeChainID
+		return nil
+	}); err != nil {
+		s.logger.Warn("[ExecuteWithdraw] Failed to record execute chain", "request_id", requestID, "chain_id", submitChainID, "error", err)
+	}
 
 	// Check transaction status immediately (quick check, then create polling task)
-	log.Printf("⏳ [ExecuteWithdraw] Checking transaction status: %s", txHash)
-
 	// Get blockchain client to check transaction status
-	const MANAGEMENT_CHAIN_ID = 714 // BSC chain ID
-	client, exists := s.blockchainService.GetClient(MANAGEMENT_CHAIN_ID)
-	if !exists {
-		log.Printf("⚠️ [ExecuteWithdraw] Blockchain client not found for chain %d", MANAGEMENT_CHAIN_ID)
-		log.Printf("   Creating polling task to check transaction status periodically")
+	if clients.IsTronChain(uint32(submitChainID)) {
+		// TRON has no EVM receipts to quick-check: the polling task and event listener confirm it
+		s.logger.Info("[ExecuteWithdraw] TRON transaction, creating polling task", "request_id", requestID,
+			"chain_id", submitChainID, "tx_hash", txHash)
+		s.createExecutePollingTask(requestID, submitChainID, txHash)
+	} else if client, exists := s.blockchainService.GetClient(submitChainID); !exists {
+		s.logger.Warn("[ExecuteWithdraw] Blockchain client not found, creating polling task",
+			"request_id", requestID, "chain_id", submitChainID, "tx_hash", txHash)
 
 		// Create polling task even without client (will use polling service's client)
-		if s.pollingService != nil {
-			pollingConfig := models.PollingTaskConfig{
-				EntityType:    "withdraw_request",
-				EntityID:      requestID,
-				TaskType:      models.PollingWithdrawExecute,
-				ChainID:       MANAGEMENT_CHAIN_ID,
-				TxHash:        txHash,
-				TargetStatus:  string(models.ExecuteStatusSuccess),
-				CurrentStatus: string(models.ExecuteStatusSubmitted),
-				MaxRetries:    180, // 30 minutes (180 * 10 seconds)
-				PollInterval:  10,  // 10 seconds
-			}
-
-			if err := s.pollingService.CreatePollingTask(pollingConfig); err != nil {
-				log.Printf("⚠️ [ExecuteWithdraw] Failed to create polling task: %v", err)
-				log.Printf("   Transaction will be checked by event listener when confirmed")
-			} else {
-				log.Printf("✅ [ExecuteWithdraw] Created polling task to monitor transaction: %s", txHash)
-				log.Printf("   Will poll every %d seconds, max %d retries (total ~%d minutes)",
-					pollingConfig.PollInterval, pollingConfig.MaxRetries,
-					pollingConfig.MaxRetries*pollingConfig.PollInterval/60)
-			}
-		} else {
-			log.Printf("⚠️ [ExecuteWithdraw] Polling service not available, transaction will be checked by event listener when confirmed")
-		}
+		s.createExecutePollingTask(requestID, submitChainID, txHash)
 	} else {
 		// Enhanced quick check: try multiple times with increasing delays
 		// This handles cases where transaction confirms quickly but receipt is not immediately available
-		txHashBytes := common.HexToHash(txHash)
-		var receipt *ethtypes.Receipt
-		var err error
+		// Try with increasing delays (config: withdraw.quick_check_delays, default 2s, 5s, 10s)
+		// Aborts on ctx cancellation (e.g. shutdown); the polling task below takes over
+		receipt, err := s.quickCheckReceipt(ctx, requestID, txHash, client.TransactionReceipt)
+		confirmed := receipt != nil
 		var blockNumber uint64
+		if confirmed {
+			blockNumber = receipt.BlockNumber.Uint64()
+		}
 
-		// Try 3 times with increasing delays: 2s, 5s, 10s
-		quickCheckDelays := []time.Duration{2 * time.Second, 5 * time.Second, 10 * time.Second}
-		confirmed := false
-
-		for i, delay := range quickCheckDelays {
-			log.Printf("⏳ [ExecuteWithdraw] Quick check attempt %d/%d (delay: %v): %s", i+1, len(quickCheckDelays), delay, txHash)
-			time.Sleep(delay)
-
-			ctxQuickCheck, cancel := context.WithTimeout(ctx, 10*time.Second)
-			receipt, err = client.TransactionReceipt(ctxQuickCheck, txHashBytes)
+		// A successful receipt only counts once it has the network's confirmation depth, otherwise keep submitted and poll
+		if confirmed && receipt.Status != 0 {
+			required := requiredConfirmations(submitChainID)
+			ctxBlock, cancel := context.WithTimeout(ctx, 10*time.Second)
+			currentBlock, blockErr := client.BlockNumber(ctxBlock)
 			cancel()
-
-			if err == nil && receipt != nil {
-				// Transaction confirmed - update immediately
-				blockNumber = receipt.BlockNumber.Uint64()
-				confirmed = true
-				log.Printf("✅ [ExecuteWithdraw] Transaction confirmed on attempt %d: %s, block=%d", i+1, txHash, blockNumber)
-				break
-			} else if err != nil {
-				log.Printf("⚠️ [ExecuteWithdraw] Quick check attempt %d failed: %v (transaction may still be pending)", i+1, err)
+			if blockErr != nil || !hasConfirmations(currentBlock, blockNumber, required) {
+				confirmed = false
+				err = blockErr
+				s.logger.Info("[ExecuteWithdraw] Receipt found but not enough confirmations yet", "request_id", requestID,
+					"tx_hash", txHash, "block_number", blockNumber, "current_block", currentBlock, "required_confirmations", required)
 			}
 		}
 
 		if confirmed {
+			s.recordExecuteGasCost(ctx, requestID, receipt)
+
 			// Transaction already confirmed - update immediately
 			if receipt.Status == 0 {
 				// Transaction failed
-				log.Printf("❌ [ExecuteWithdraw] Transaction failed: %s", txHash)
+				s.logger.Error("[ExecuteWithdraw] Transaction reverted on-chain", "request_id", requestID, "chain_id", submitChainID,
+					"tx_hash", txHash, "block_number", blockNumber)
 				if updateErr := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusVerifyFailed, txHash, &blockNumber, "Transaction reverted on-chain"); updateErr != nil {
-					log.Printf("❌ [ExecuteWithdraw] Failed to update status to verify_failed: %v", updateErr)
+					s.logger.Error("[ExecuteWithdraw] Failed to update status to verify_failed", "request_id", requestID, "error", updateErr)
 				} else {
-					log.Printf("✅ [ExecuteWithdraw] Updated execute_status to verify_failed")
+					s.logger.Info("[ExecuteWithdraw] Updated execute_status", "request_id", requestID, "status", models.ExecuteStatusVerifyFailed)
 				}
 			} else {
 				// Transaction succeeded
-				log.Printf("✅ [ExecuteWithdraw] Transaction confirmed successfully: %s, block=%d", txHash, blockNumber)
+				s.logger.Info("[ExecuteWithdraw] Transaction confirmed", "request_id", requestID, "chain_id", submitChainID,
+					"tx_hash", txHash, "block_number", blockNumber)
 				if updateErr := s.withdrawRepo.UpdateExecuteStatus(ctx, requestID, models.ExecuteStatusSuccess, txHash, &blockNumber, ""); updateErr != nil {
-					log.Printf("❌ [ExecuteWithdraw] Failed to update status to success: %v", updateErr)
+					s.logger.Error("[ExecuteWithdraw] Failed to update status to success", "request_id", requestID, "error", updateErr)
 				} else {
-					log.Printf("✅ [ExecuteWithdraw] Updated execute_status to success")
+					s.logger.Info("[ExecuteWithdraw] Updated execute_status", "request_id", requestID, "status", models.ExecuteStatusSuccess)
 
 					// Update main status
-					request.ExecuteStatus = models.ExecuteStatusSuccess
-					request.UpdateMainStatus()
-					if err := s.withdrawRepo.Update(ctx, request); err != nil {
-						log.Printf("⚠️ [ExecuteWithdraw] Failed to update main status: %v", err)
+					updated, err := s.withdrawRepo.Modify(ctx, requestID, func(fresh *models.WithdrawRequest) error {
+						fresh.UpdateMainStatus()
+						return nil
+					})
+					if err != nil {
+						s.logger.Warn("[ExecuteWithdraw] Failed to update main status", "request_id", requestID, "error", err)
+					} else {
+						request = updated
 					}
 				}
 			}
 		} else {
 			// Transaction not confirmed yet after quick checks - create polling task
-			log.Printf("⏳ [ExecuteWithdraw] Transaction not confirmed after quick checks (may still be pending): %v", err)
-			log.Printf("   Creating polling task to check transaction status every 10 seconds")
-
-			if s.pollingService != nil {
-				pollingConfig := models.PollingTaskConfig{
-					EntityType:    "withdraw_request",
-					EntityID:      requestID,
-					TaskType:      models.PollingWithdrawExecute,
-					ChainID:       MANAGEMENT_CHAIN_ID,
-					TxHash:        txHash,
-					TargetStatus:  string(models.ExecuteStatusSuccess),
-					CurrentStatus: string(models.ExecuteStatusSubmitted),
-					MaxRetries:    180, // 30 minutes (180 * 10 seconds)
-					PollInterval:  10,  // 10 seconds
-				}
+			s.logger.Info("[ExecuteWithdraw] Transaction not confirmed after quick checks, creating polling task", "request_id", requestID,
+				"tx_hash", txHash, "poll_interval_seconds", s.executePollInterval, "error", err)
 
-				if err := s.pollingService.CreatePollingTask(pollingConfig); err != nil {
-					log.Printf("⚠️ [ExecuteWithdraw] Failed to create polling task: %v", err)
-					log.Printf("   Transaction will be checked by event listener when confirmed")
-				} else {
-					log.Printf("✅ [ExecuteWithdraw] Created polling task to monitor transaction: %s", txHash)
-					log.Printf("   Will poll every %d seconds, max %d retries (total ~%d minutes)",
-						pollingConfig.PollInterval, pollingConfig.MaxRetries,
-						pollingConfig.MaxRetries*pollingConfig.PollInterval/60)
-				}
-			} else {
-				log.Printf("⚠️ [ExecuteWithdraw] Polling service not available, transaction will be checked by event listener when confirmed")
-			}
+			s.createExecutePollingTask(requestID, submitChainID, txHash)
 		}
 	}
 
-	// Update main status to submitting (if not already updated to success above)
-	if request.ExecuteStatus != models.ExecuteStatusSuccess {
-		request.ExecuteStatus = models.ExecuteStatusSubmitted
-		request.UpdateMainStatus()
-		if err := s.withdrawRepo.Update(ctx, request); err != nil {
-			log.Printf("⚠️ [ExecuteWithdraw] Failed to update main status: %v", err)
+	// Update main status to submitting (if not already updated to a final execute status above)
+	if !request.IsExecuteFinal() {
+		if _, err := s.withdrawRepo.Modify(ctx, requestID, func(fresh *models.WithdrawRequest) error {
+			switch fresh.ExecuteStatus {
+			case models.ExecuteStatusSuccess, models.ExecuteStatusVerifyFailed, models.ExecuteStatusSubmitFailed:
+				// Already final (quick check or event listener), keep it
+			default:
+				fresh.ExecuteStatus = models.ExecuteStatusSubmitted
+			}
+			fresh.UpdateMainStatus()
+			return nil
+		}); err != nil {
+			s.logger.Warn("[ExecuteWithdraw] Failed to update main status", "request_id", requestID, "error", err)
 		}
 	}
 
@@ -1249,9 +1527,98 @@ func (s *WithdrawRequestService) ExecuteWithdraw(ctx context.Context, requestID
 	return nil
 }
 
+// recordExecuteGasCost persists the receipt's gas used and effective gas price (reverted transactions pay gas too)
+// Failures are logged only: the gas cost is accounting data and must not affect the execute status.
+func (s *WithdrawRequestService) recordExecuteGasCost(ctx context.Context, requestID string, receipt *ethtypes.Receipt) {
+	gasPrice := ""
+	if receipt.EffectiveGasPrice != nil {
+		gasPrice = receipt.EffectiveGasPrice.String()
+	}
+	if err := s.withdrawRepo.UpdateExecuteGasCost(ctx, requestID, receipt.GasUsed, gasPrice); err != nil {
+		s.logger.Warn("[ExecuteWithdraw] Failed to record gas cost", "request_id", requestID, "gas_used", receipt.GasUsed, "error", err)
+	}
+}
+
+// chainDisplayName returns the configured network name of a SLIP-44 chain, nil if the chain is not configured
+func chainDisplayName(slip44ChainID uint32) *string {
+	networkConfig, err := config.GetNetworkConfigByChainID(int(slip44ChainID))
+	if err != nil || networkConfig.Name == "" {
+		return nil
+	}
+	name := networkConfig.Name
+	return &name
+}
+
+// quickCheckReceipt polls for an executeWithdraw receipt after each of the configured quick-check delays
+// Returns the receipt once one is found; otherwise nil and the last lookup error (ctx.Err() when ctx is done first).
+func (s *WithdrawRequestService) quickCheckReceipt(
+	ctx context.Context,
+	requestID string,
+	txHash string,
+	fetch func(ctx context.Context, hash common.Hash) (*ethtypes.Receipt, error),
+) (*ethtypes.Receipt, error) {
+	txHashBytes := common.HexToHash(txHash)
+	var err error
+	for i, delay := range s.quickCheckDelays {
+		select {
+		case <-ctx.Done():
+			s.logger.Warn("[ExecuteWithdraw] Quick check aborted", "request_id", requestID, "tx_hash", txHash, "error", ctx.Err())
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		ctxQuickCheck, cancel := context.WithTimeout(ctx, 10*time.Second)
+		var receipt *ethtypes.Receipt
+		receipt, err = fetch(ctxQuickCheck, txHashBytes)
+		cancel()
+
+		if err == nil && receipt != nil {
+			s.logger.Info("[ExecuteWithdraw] Transaction receipt found", "request_id", requestID, "tx_hash", txHash,
+				"attempt", i+1, "delay", delay.String(), "block_number", receipt.BlockNumber.Uint64())
+			return receipt, nil
+		} else if err != nil {
+			s.logger.Warn("[ExecuteWithdraw] Quick check attempt failed (transaction may still be pending)", "request_id", requestID,
+				"tx_hash", txHash, "attempt", i+1, "attempts", len(s.quickCheckDelays), "error", err)
+		}
+	}
+	return nil, err
+}
+
+// createExecutePollingTask creates a polling task to monitor executeWithdraw TX confirmation
+func (s *WithdrawRequestService) createExecutePollingTask(requestID string, chainID int, txHash string) {
+	if s.pollingService == nil {
+		log.Printf("⚠️ [ExecuteWithdraw] Polling service not available, transaction will be checked by event listener when confirmed")
+		return
+	}
+
+	pollingConfig := models.PollingTaskConfig{
+		EntityType:    "withdraw_request",
+		EntityID:      requestID,
+		TaskType:      models.PollingWithdrawExecute,
+		ChainID:       uint32(chainID),
+		TxHash:        txHash,
+		TargetStatus:  string(models.ExecuteStatusSuccess),
+		CurrentStatus: string(models.ExecuteStatusSubmitted),
+		MaxRetries:    s.executePollMaxRetries, // config: withdraw.execute_poll_max_retries (default 180)
+		PollInterval:  s.executePollInterval,   // config: withdraw.execute_poll_interval (default 10 seconds)
+	}
+
+	if err := s.pollingService.CreatePollingTask(pollingConfig); err != nil {
+		log.Printf("⚠️ [ExecuteWithdraw] Failed to create polling task: %v", err)
+		log.Printf("   Transaction will be checked by event listener when confirmed")
+		return
+	}
+
+	log.Printf("✅ [ExecuteWithdraw] Created polling task to monitor transaction: %s", txHash)
+	log.Printf("   Will poll every %d seconds, max %d retries (total ~%d minutes)",
+		pollingConfig.PollInterval, pollingConfig.MaxRetries,
+		pollingConfig.MaxRetries*pollingConfig.PollInterval/60)
+}
+
 // ProcessPayout processes Intent execution (Stage 3)
 // After payout is completed, automatically triggers Stage 4 (Hook) if needed
 func (s *WithdrawRequestService) ProcessPayout(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "ProcessPayout")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1259,7 +1626,7 @@ func (s *WithdrawRequestService) ProcessPayout(ctx context.Context, requestID st
 
 	// Validate: execute must be successful
 	if request.ExecuteStatus != models.ExecuteStatusSuccess {
-		return errors.New("execute not successful")
+		return ErrExecuteNotSuccessful
 	}
 
 	// Update payout status to processing
@@ -1267,30 +1634,37 @@ func (s *WithdrawRequestService) ProcessPayout(ctx context.Context, requestID st
 		return err
 	}
 
-	// In real implementation, this would:
-	// 1. Call multisig to execute Treasury.payout(
-	//      targetChainId,
-	//      IntentManagerAddress,  // 目标：IntentManager 合约
-	//      amount,
-	//      beneficiary,
-	//      hookCalldata
-	//    )
-	// 2. Query LiFi for optimal cross-chain route
-	// 3. Execute bridge transaction
-	// 4. Monitor IntentManager.FundsReceived event
-	// TODO: Integrate MultisigService + LiFi + IntentManager monitoring
+	var txHash string
+	var blockNumber uint64
+	if s.payoutExecutor != nil {
+		// Production: multisig executes Treasury.payout() → IntentManager (LiFi route for cross-chain)
+		var execErr error
+		txHash, blockNumber, execErr = s.payoutExecutor.ExecutePayout(ctx, request)
+		if execErr != nil {
+			log.Printf("❌ [ProcessPayout] Payout execution failed for %s: %v", requestID, execErr)
+			if err := s.withdrawRepo.UpdatePayoutStatus(ctx, requestID, models.PayoutStatusFailed, txHash, nil, execErr.Error()); err != nil {
+				return err
+			}
+			s.appendRetryHistory(ctx, requestID, models.RetryHistoryEntry{
+				Stage: models.RetryStagePayout, Attempt: request.PayoutRetryCount + 1, Error: execErr.Error(), Trigger: "ProcessPayout",
+			})
+			if err := s.refreshMainStatus(ctx, requestID); err != nil {
+				return err
+			}
+			return fmt.Errorf("payout execution failed: %w", execErr)
+		}
+	} else {
+		// No executor configured (local dev): simulate success
+		txHash = "0x" + uuid.New().String()
+		blockNumber = uint64(12346)
+	}
 
-	// Simulate success
-	txHash := "0x" + uuid.New().String()
-	blockNumber := uint64(12346)
 	if err := s.withdrawRepo.UpdatePayoutStatus(ctx, requestID, models.PayoutStatusCompleted, txHash, &blockNumber, ""); err != nil {
 		return err
 	}
 
 	// Update main status
-	request.PayoutStatus = models.PayoutStatusCompleted
-	request.UpdateMainStatus()
-	if err := s.withdrawRepo.Update(ctx, request); err != nil {
+	if err := s.refreshMainStatus(ctx, requestID); err != nil {
 		return err
 	}
 
@@ -1307,10 +1681,20 @@ func (s *WithdrawRequestService) ProcessPayout(ctx context.Context, requestID st
 	return nil
 }
 
+// refreshMainStatus reloads a withdraw request and recomputes its main status from sub-statuses
+func (s *WithdrawRequestService) refreshMainStatus(ctx context.Context, requestID string) error {
+	_, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		request.UpdateMainStatus()
+		return nil
+	})
+	return err
+}
+
 // ProcessHook processes Hook execution (Stage 4 - Optional)
 // Executes the on-chain recorded calldata via IntentManager
 // Note: calldata is retrieved from blockchain (or database cache) for decentralization
 func (s *WithdrawRequestService) ProcessHook(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "ProcessHook")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1318,7 +1702,10 @@ func (s *WithdrawRequestService) ProcessHook(ctx context.Context, requestID stri
 
 	// Validate: payout must be completed (funds already in IntentManager)
 	if request.PayoutStatus != models.PayoutStatusCompleted {
-		return errors.New("payout not completed")
+		return ErrPayoutNotCompleted
+	}
+	if request.HookCalldata == "" {
+		return ErrHookCalldataMissing
 	}
 
 	// Update hook status to processing
@@ -1326,9 +1713,10 @@ func (s *WithdrawRequestService) ProcessHook(ctx context.Context, requestID stri
 		return err
 	}
 
+	log.Printf("🪝 [ProcessHook] Request %s: executing hook calldata (%d bytes)", requestID, (len(request.HookCalldata)-2)/2)
+
 	// In real implementation, this would:
-	// 1. Read hookCalldata from database (cached from blockchain event)
-	//    OR query directly from blockchain: privacyPool.withdrawCalldata(withdrawNullifier)
+	// 1. Use request.HookCalldata (stored from the Intent, or recovered from the chain via UpdateHookCalldata)
 	//    Note: calldata is recorded on-chain during executeWithdraw, ensuring decentralization
 	// 2. Call IntentManager.executeIntent(
 	//      beneficiary,
@@ -1347,41 +1735,329 @@ func (s *WithdrawRequestService) ProcessHook(ctx context.Context, requestID stri
 	}
 
 	// Update main status
-	request.HookStatus = models.HookStatusCompleted
-	request.UpdateMainStatus()
-	return s.withdrawRepo.Update(ctx, request)
+	return s.refreshMainStatus(ctx, requestID)
 }
 
 // CancelWithdrawRequest cancels a withdraw request
 // Rule: Can only cancel if execute_status != success (Stage 1-2 failed)
+// The status change and the release of the allocations commit in one transaction. The cancel is checked against
+// the latest version of the request, so it cannot interleave with claimExecuteSubmission: one of them wins.
 func (s *WithdrawRequestService) CancelWithdrawRequest(ctx context.Context, requestID string) error {
-	request, err := s.withdrawRepo.GetByID(ctx, requestID)
+	ctx = repository.WithTransitionTrigger(ctx, "CancelWithdrawRequest")
+	return s.transactor.InTransaction(ctx, func(repos repository.Repositories) error {
+		request, err := repos.WithdrawRequests.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+			if !request.CanCancel() {
+				return ErrCannotCancel
+			}
+			request.Status = string(models.WithdrawStatusCancelled)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		allocationIDs, err := s.getAllocationIDs(request)
+		if err != nil {
+			return err
+		}
+
+		// Release allocations (pending -> idle), whatever intermediate execute status left them pending
+		return s.releaseCancelledAllocations(ctx, repos.Allocations, request, allocationIDs, "CancelWithdrawRequest")
+	})
+}
+
+// claimExecuteSubmission marks a request execute_status=submitted before executeWithdraw is sent
+// Checked against the latest version of the request: a request cancelled or resolved meanwhile is refused with
+// ErrWithdrawRequestClosed, so no transaction is sent for allocations a cancel already released.
+func (s *WithdrawRequestService) claimExecuteSubmission(ctx context.Context, requestID string) error {
+	_, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		switch models.WithdrawRequestStatus(request.Status) {
+		case models.WithdrawStatusCancelled, models.WithdrawStatusManuallyResolved:
+			return fmt.Errorf("%w: status=%s", ErrWithdrawRequestClosed, request.Status)
+		}
+		switch request.ExecuteStatus {
+		case models.ExecuteStatusSuccess:
+			return ErrAlreadyExecuted
+		case models.ExecuteStatusVerifyFailed:
+			return ErrVerificationFailed
+		}
+		request.ExecuteStatus = models.ExecuteStatusSubmitted
+		return nil
+	})
+	return err
+}
+
+// ExpireWithdrawRequest cancels a request past its ExpiresAt that never started executing and releases its allocations
+// Returns false without error when the request is not (or no longer) expired, e.g. its proof started meanwhile.
+func (s *WithdrawRequestService) ExpireWithdrawRequest(ctx context.Context, requestID string, now time.Time) (bool, error) {
+	ctx = repository.WithTransitionTrigger(ctx, "ExpireWithdrawRequest")
+	// Re-checked against the latest version of the request; the release commits with the status change
+	err := s.transactor.InTransaction(ctx, func(repos repository.Repositories) error {
+		request, err := repos.WithdrawRequests.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+			if !request.IsExpired(now) {
+				return errWithdrawRequestNotExpired
+			}
+			request.Status = string(models.WithdrawStatusCancelled)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		allocationIDs, err := s.getAllocationIDs(request)
+		if err != nil {
+			return err
+		}
+		return s.releaseCancelledAllocations(ctx, repos.Allocations, request, allocationIDs, "ExpireWithdrawRequest")
+	})
+	if errors.Is(err, errWithdrawRequestNotExpired) {
+		return false, nil
+	}
 	if err != nil {
-		return err
+		return false, err
 	}
+	return true, nil
+}
 
-	// Check if can cancel
-	if !request.CanCancel() {
-		return ErrCannotCancel
+// errWithdrawRequestNotExpired the request stopped being expired between the lookup and the update
+var errWithdrawRequestNotExpired = errors.New("withdraw request is not expired")
+
+// releaseCancelledAllocations releases the allocations of a cancelled request (pending -> idle)
+// Both the request's allocation list and every non-used allocation still linked by withdraw_request_id are
+// released: after submit_failed they are deliberately left pending (see updateChecksStatusOnFailure).
+func (s *WithdrawRequestService) releaseCancelledAllocations(ctx context.Context, allocationRepo repository.AllocationRepository, request *models.WithdrawRequest, allocationIDs []string, caller string) error {
+	if err := allocationRepo.ReleaseAllocations(ctx, allocationIDs); err != nil {
+		return fmt.Errorf("failed to release allocations: %w", err)
 	}
+	released, err := allocationRepo.ReleaseByWithdrawRequest(ctx, request.ID)
+	if err != nil {
+		return fmt.Errorf("failed to release allocations: %w", err)
+	}
+	if released > 0 {
+		log.Printf("🔓 [%s] WithdrawRequest %s (execute_status=%s): released %d allocation(s) still linked to the request",
+			caller, request.ID, request.ExecuteStatus, released)
+	}
+	return nil
+}
 
-	// Release allocations (pending -> idle)
-	allocationIDs, err := s.getAllocationIDs(request)
+// ManuallyResolve closes out a stuck withdraw request administratively (no on-chain ManuallyResolved event)
+// Requests that already completed successfully or were already resolved are refused with ErrCannotManuallyResolve.
+// The request's allocations are settled in the same transaction: marked used when executeWithdraw succeeded
+// (their nullifiers are consumed on-chain), otherwise released back to idle.
+func (s *WithdrawRequestService) ManuallyResolve(ctx context.Context, requestID, resolver, note string) (*models.WithdrawRequest, error) {
+	ctx = repository.WithTransitionTrigger(ctx, "ManuallyResolve")
+	var fromStatus string
+	var request *models.WithdrawRequest
+	err := s.transactor.InTransaction(ctx, func(repos repository.Repositories) error {
+		var err error
+		request, err = repos.WithdrawRequests.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+			switch models.WithdrawRequestStatus(request.Status) {
+			case models.WithdrawStatusCompleted, models.WithdrawStatusCompletedWithHookFailed, models.WithdrawStatusManuallyResolved:
+				return fmt.Errorf("%w: status=%s", ErrCannotManuallyResolve, request.Status)
+			}
+			now := time.Now()
+			fromStatus = request.Status
+			request.Status = string(models.WithdrawStatusManuallyResolved)
+			request.ResolvedBy = resolver
+			request.ResolutionNote = note
+			request.ResolvedAt = &now
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		allocationIDs, err := s.getAllocationIDs(request)
+		if err != nil {
+			return err
+		}
+		if request.ExecuteStatus == models.ExecuteStatusSuccess {
+			if err := repos.Allocations.MarkAsUsed(ctx, allocationIDs); err != nil {
+				return fmt.Errorf("failed to mark allocations as used: %w", err)
+			}
+			return nil
+		}
+		return s.releaseCancelledAllocations(ctx, repos.Allocations, request, allocationIDs, "ManuallyResolve")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("[ManuallyResolve] Withdraw request manually resolved", "request_id", requestID,
+		"from_status", fromStatus, "resolver", resolver, "note", note)
+	return request, nil
+}
+
+// CancelWithdrawRequestPartial cancels only a subset of allocations of a withdraw request
+// The given allocations are released back to idle, the rest stay reserved for the request.
+// If all allocations are removed, this cancels the whole request like CancelWithdrawRequest.
+// Only allowed before proof generation started: a proof covers the exact allocation set, so the request is
+// refused with ErrCannotPartialCancel once proof_status is in_progress or completed. Otherwise the proof fields
+// and the stored signature (both made for the old allocation set) are cleared, and the client signs again.
+// The re-check, the request update and the release commit in one transaction.
+func (s *WithdrawRequestService) CancelWithdrawRequestPartial(ctx context.Context, requestID string, allocationIDs []string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "CancelWithdrawRequestPartial")
+	if len(allocationIDs) == 0 {
+		return ErrInvalidAllocations
+	}
+
+	toRelease := make(map[string]bool, len(allocationIDs))
+	for _, id := range allocationIDs {
+		toRelease[id] = true
+	}
+
+	return s.transactor.InTransaction(ctx, func(repos repository.Repositories) error {
+		var releaseIDs, remainingIDs []string
+		request, err := repos.WithdrawRequests.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+			if !request.CanCancel() {
+				return ErrCannotCancel
+			}
+			if request.ProofStatus == models.ProofStatusInProgress || request.ProofStatus == models.ProofStatusCompleted {
+				return fmt.Errorf("%w: proof_status=%s", ErrCannotPartialCancel, request.ProofStatus)
+			}
+
+			currentIDs, err := s.getAllocationIDs(request)
+			if err != nil {
+				return err
+			}
+			current, err := repos.Allocations.GetByIDs(ctx, currentIDs)
+			if err != nil {
+				return fmt.Errorf("failed to get allocations: %w", err)
+			}
+
+			// Validate the subset belongs to this request
+			releaseIDs, remainingIDs = nil, nil
+			var remaining []*models.Check
+			for _, allocation := range current {
+				if toRelease[allocation.ID] {
+					releaseIDs = append(releaseIDs, allocation.ID)
+				} else {
+					remainingIDs = append(remainingIDs, allocation.ID)
+					remaining = append(remaining, allocation)
+				}
+			}
+			if len(releaseIDs) != len(toRelease) {
+				return ErrAllocationNotInRequest
+			}
+
+			// All allocations removed - full cancel
+			if len(remaining) == 0 {
+				request.Status = string(models.WithdrawStatusCancelled)
+				return nil
+			}
+
+			allocationIDsJSON, err := json.Marshal(remainingIDs)
+			if err != nil {
+				return fmt.Errorf("failed to marshal allocation IDs: %w", err)
+			}
+			amount, err := s.calculateTotalAmount(remaining)
+			if err != nil {
+				return err
+			}
+
+			// The on-chain request ID is the first allocation's nullifier (unless overridden for recovery)
+			if request.WithdrawNullifier == current[0].Nullifier {
+				request.WithdrawNullifier = remaining[0].Nullifier
+			}
+			request.AllocationIDs = string(allocationIDsJSON)
+			request.Amount = amount
+			request.ProofStatus = models.ProofStatusPending
+			request.Proof = ""
+			request.PublicValues = ""
+			request.ProofError = ""
+			request.ProofGeneratedAt = nil
+			request.Signature = ""
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if request.Status == string(models.WithdrawStatusCancelled) {
+			return s.releaseCancelledAllocations(ctx, repos.Allocations, request, releaseIDs, "CancelWithdrawRequestPartial")
+		}
+
+		// Release the selected allocations (pending -> idle)
+		if err := repos.Allocations.ReleaseAllocations(ctx, releaseIDs); err != nil {
+			return fmt.Errorf("failed to release allocations: %w", err)
+		}
+		log.Printf("✂️ [CancelWithdrawRequestPartial] WithdrawRequest %s: released %d allocation(s), %d remaining, new amount=%s",
+			requestID, len(releaseIDs), len(remainingIDs), request.Amount)
+		return nil
+	})
+}
+
+// RetryProofGeneration re-enqueues proof generation (Stage 1) using the stored signature
+// Rule: Can only retry a request that is not closed or expired, with proof_status pending or failed and the signature
+// stored on create; anything else returns ErrCannotRetryProof. Used when the process restarted before proof generation
+// started (stuck at pending) or after it failed; a proof stuck in_progress is first failed by the timeout service.
+func (s *WithdrawRequestService) RetryProofGeneration(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "RetryProofGeneration")
+	if s.zkvmClient == nil {
+		return fmt.Errorf("ZKVM client not set")
+	}
+
+	// Checked and reset on the current row: a proof already being generated (in_progress) or a request closed
+	// meanwhile must not get a second ZKVM job
+	var previousProofStatus models.ProofStatus
+	request, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		if request.IsTerminal() || request.IsExpired(time.Now()) {
+			return fmt.Errorf("%w: status=%s", ErrCannotRetryProof, request.Status)
+		}
+		switch request.ProofStatus {
+		case models.ProofStatusPending, models.ProofStatusFailed:
+		default:
+			return fmt.Errorf("%w: proof_status=%s", ErrCannotRetryProof, request.ProofStatus)
+		}
+		if request.Signature == "" {
+			return ErrSignatureNotStored
+		}
+		previousProofStatus = request.ProofStatus
+		// Reset to pending (autoGenerateProofWithSignature only processes pending requests)
+		request.ProofStatus = models.ProofStatusPending
+		request.ProofError = ""
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	if err := s.allocationRepo.ReleaseAllocations(ctx, allocationIDs); err != nil {
-		return fmt.Errorf("failed to release allocations: %w", err)
+
+	log.Printf("🔄 [RetryProofGeneration] Re-triggering ZKVM proof generation for request: %s (previous proof_status=%s)", requestID, previousProofStatus)
+	s.startProofGeneration(requestID, request.Signature, request.SignatureChainID)
+
+	return nil
+}
+
+// ResignWithdrawRequest stores a new user signature for a request whose proof generation has not started and
+// re-triggers proof generation with it
+// A partial cancel clears the signature, which covered the released allocations too; the user signs the reduced
+// request again through this method. ZKVM verifies the signature against the request's allocations.
+func (s *WithdrawRequestService) ResignWithdrawRequest(ctx context.Context, requestID string, signature string, chainID uint32) error {
+	ctx = repository.WithTransitionTrigger(ctx, "ResignWithdrawRequest")
+	if strings.TrimSpace(signature) == "" {
+		return ErrSignatureNotStored
 	}
 
-	// Update status to cancelled
-	request.Status = string(models.WithdrawStatusCancelled)
-	return s.withdrawRepo.Update(ctx, request)
+	_, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		if !request.CanCancel() || request.ProofStatus != models.ProofStatusPending {
+			return fmt.Errorf("%w: status=%s, proof_status=%s", ErrCannotResign, request.Status, request.ProofStatus)
+		}
+		request.Signature = signature
+		request.SignatureChainID = chainID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✍️ [ResignWithdrawRequest] Stored new signature for request %s", requestID)
+	return s.RetryProofGeneration(ctx, requestID)
 }
 
 // RetryPayout manually retries payout (Stage 3)
 // Rule: Can only retry if execute_status = success AND payout_status = failed
 func (s *WithdrawRequestService) RetryPayout(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "RetryPayout")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1392,11 +2068,15 @@ func (s *WithdrawRequestService) RetryPayout(ctx context.Context, requestID stri
 		return ErrCannotRetryPayout
 	}
 
-	// Check retry limit (recommended: 5 times)
-	if request.PayoutRetryCount >= 5 {
-		return ErrMaxRetriesExceeded
+	// Check retry limit (config: withdraw.max_payout_retries, default 5)
+	if err := s.checkRetryLimit(models.RetryStagePayout, request.PayoutRetryCount); err != nil {
+		return err
 	}
 
+	s.appendRetryHistory(ctx, requestID, models.RetryHistoryEntry{
+		Stage: models.RetryStagePayout, Attempt: request.PayoutRetryCount + 1, Error: request.PayoutError, Trigger: "RetryPayout",
+	})
+
 	// Update to processing
 	if err := s.withdrawRepo.UpdatePayoutStatus(ctx, requestID, models.PayoutStatusProcessing, "", nil, ""); err != nil {
 		return err
@@ -1409,6 +2089,7 @@ func (s *WithdrawRequestService) RetryPayout(ctx context.Context, requestID stri
 // RetryHook manually retries Hook purchase (Stage 4)
 // Rule: Can only retry if payout_status = completed AND hook_status = failed
 func (s *WithdrawRequestService) RetryHook(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "RetryHook")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1420,10 +2101,14 @@ func (s *WithdrawRequestService) RetryHook(ctx context.Context, requestID string
 	}
 
 	// Check retry limit
-	if request.HookRetryCount >= 5 {
-		return ErrMaxRetriesExceeded
+	if err := s.checkRetryLimit(models.RetryStageHook, request.HookRetryCount); err != nil {
+		return err
 	}
 
+	s.appendRetryHistory(ctx, requestID, models.RetryHistoryEntry{
+		Stage: models.RetryStageHook, Attempt: request.HookRetryCount + 1, Error: request.HookError, Trigger: "RetryHook",
+	})
+
 	// Retry hook
 	return s.ProcessHook(ctx, requestID)
 }
@@ -1431,6 +2116,7 @@ func (s *WithdrawRequestService) RetryHook(ctx context.Context, requestID string
 // RetryFallback retries a failed fallback transfer
 // This calls multisig service to retry Treasury.retryFallback()
 func (s *WithdrawRequestService) RetryFallback(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "RetryFallback")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return fmt.Errorf("withdraw request not found: %w", err)
@@ -1442,8 +2128,8 @@ func (s *WithdrawRequestService) RetryFallback(ctx context.Context, requestID st
 	}
 
 	// Check retry count
-	if request.FallbackRetryCount >= 5 {
-		return ErrMaxRetriesExceeded
+	if err := s.checkRetryLimit(models.RetryStageFallback, request.FallbackRetryCount); err != nil {
+		return err
 	}
 
 	// TODO: Call multisig service API to execute Treasury.retryFallback(requestId)
@@ -1453,24 +2139,160 @@ func (s *WithdrawRequestService) RetryFallback(ctx context.Context, requestID st
 		return fmt.Errorf("failed to update fallback retry count: %w", err)
 	}
 
+	s.appendRetryHistory(ctx, requestID, models.RetryHistoryEntry{
+		Stage: models.RetryStageFallback, Attempt: request.FallbackRetryCount + 1, Error: request.FallbackError, Trigger: "RetryFallback",
+	})
+
 	return nil
 }
 
+// appendRetryHistory records a retry or failure in the request's retry_history
+// The history is an audit trail only: failing to record it is logged and does not fail the retry.
+func (s *WithdrawRequestService) appendRetryHistory(ctx context.Context, requestID string, entry models.RetryHistoryEntry) {
+	if err := s.withdrawRepo.AppendRetryHistory(ctx, requestID, entry); err != nil {
+		log.Printf("⚠️ [RetryHistory] Failed to record %s attempt %d of %s: %v", entry.Stage, entry.Attempt, requestID, err)
+	}
+}
+
+// GetRetryHistory returns the payout / hook / fallback retries and failures of a withdraw request, oldest first
+func (s *WithdrawRequestService) GetRetryHistory(ctx context.Context, requestID string) ([]models.RetryHistoryEntry, error) {
+	request, err := s.withdrawRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return request.ParseRetryHistory()
+}
+
 // GetWithdrawRequest gets a withdraw request by ID
 func (s *WithdrawRequestService) GetWithdrawRequest(ctx context.Context, requestID string) (*models.WithdrawRequest, error) {
 	return s.withdrawRepo.GetByID(ctx, requestID)
 }
 
+// WithdrawRequestWithAllocations a withdraw request with its allocations and their checkbooks
+type WithdrawRequestWithAllocations struct {
+	Request     *models.WithdrawRequest `json:"request"`
+	Allocations []*models.Check         `json:"allocations"` // AllocationIDs order
+	Checkbooks  []*models.Checkbook     `json:"checkbooks"`  // first-seen order of the allocations
+}
+
+// GetWithdrawRequestWithAllocations gets a withdraw request with its allocations and checkbooks hydrated
+// Returns an error wrapping ErrMalformedAllocationIDs if the stored AllocationIDs JSON cannot be parsed.
+func (s *WithdrawRequestService) GetWithdrawRequestWithAllocations(ctx context.Context, requestID string) (*WithdrawRequestWithAllocations, error) {
+	request, err := s.withdrawRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	allocationIDs, err := s.getAllocationIDs(request)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := s.allocationRepo.GetByIDs(ctx, allocationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations: %w", err)
+	}
+
+	checkbookIDs := make([]string, 0, len(allocations))
+	seen := make(map[string]bool, len(allocations))
+	for _, allocation := range allocations {
+		if !seen[allocation.CheckbookID] {
+			seen[allocation.CheckbookID] = true
+			checkbookIDs = append(checkbookIDs, allocation.CheckbookID)
+		}
+	}
+
+	checkbooks, err := s.checkbookRepo.GetByIDs(ctx, checkbookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkbooks: %w", err)
+	}
+
+	return &WithdrawRequestWithAllocations{
+		Request:     request,
+		Allocations: allocations,
+		Checkbooks:  checkbooks,
+	}, nil
+}
+
 // GetUserWithdrawRequests gets withdraw requests for a user
 func (s *WithdrawRequestService) GetUserWithdrawRequests(ctx context.Context, ownerChainID uint32, ownerData string, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
 	return s.withdrawRepo.FindByOwner(ctx, ownerChainID, ownerData, page, pageSize)
 }
 
+// WithdrawableBalance idle allocation total of one token for a user
+type WithdrawableBalance struct {
+	TokenKey        string `json:"token_key"`
+	Amount          string `json:"amount"`        // management units (18 decimals)
+	NativeAmount    string `json:"native_amount"` // token's source-chain decimals
+	AllocationCount int    `json:"allocation_count"`
+}
+
+// GetWithdrawableBalance sums the user's idle allocations per token key across their checkbooks
+// Only idle allocations can go into a new withdraw; pending/used ones are already taken.
+// Checkbooks without a token key yet (DepositRecorded not processed) are skipped.
+func (s *WithdrawRequestService) GetWithdrawableBalance(ctx context.Context, ownerChainID uint32, ownerData string) (map[string]*WithdrawableBalance, error) {
+	checkbooks, err := s.checkbookRepo.FindByOwner(ctx, ownerChainID, ownerData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find checkbooks: %w", err)
+	}
+
+	totals := make(map[string]*big.Int)
+	balances := make(map[string]*WithdrawableBalance)
+	for _, checkbook := range checkbooks {
+		if checkbook.TokenKey == "" {
+			continue
+		}
+		allocations, err := s.allocationRepo.FindAvailable(ctx, checkbook.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find idle allocations of checkbook %s: %w", checkbook.ID, err)
+		}
+		if len(allocations) == 0 {
+			continue
+		}
+
+		balance, ok := balances[checkbook.TokenKey]
+		if !ok {
+			balance = &WithdrawableBalance{TokenKey: checkbook.TokenKey}
+			balances[checkbook.TokenKey] = balance
+			totals[checkbook.TokenKey] = new(big.Int)
+		}
+		for _, alloc := range allocations {
+			amount, err := parseAllocationAmount(alloc.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("allocation %s: %w", alloc.ID, err)
+			}
+			totals[checkbook.TokenKey].Add(totals[checkbook.TokenKey], amount)
+			balance.AllocationCount++
+		}
+	}
+
+	// Checkbooks of one owner are all on the owner's chain; token ID 0 as for other checkbook amounts
+	converter := utils.NewDecimalConverter()
+	if config.AppConfig != nil && len(config.AppConfig.Tokens.ChainDecimals) > 0 {
+		converter = utils.NewDecimalConverterWithConfig(config.AppConfig.Tokens.ChainDecimals)
+	}
+	for tokenKey, balance := range balances {
+		balance.Amount = totals[tokenKey].String()
+		nativeAmount, err := converter.ConvertFromManagementAmount(balance.Amount, int64(ownerChainID), 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s balance to native decimals: %w", tokenKey, err)
+		}
+		balance.NativeAmount = nativeAmount
+	}
+
+	return balances, nil
+}
+
 // GetBeneficiaryWithdrawRequests gets withdraw requests where the user is the beneficiary
 func (s *WithdrawRequestService) GetBeneficiaryWithdrawRequests(ctx context.Context, beneficiaryChainID uint32, beneficiaryData string, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
 	return s.withdrawRepo.FindByBeneficiary(ctx, beneficiaryChainID, beneficiaryData, page, pageSize)
 }
 
+// GetUserAllWithdrawRequests gets withdraw requests where the user is the owner or the beneficiary (each request once)
+func (s *WithdrawRequestService) GetUserAllWithdrawRequests(ctx context.Context, chainID uint32, data string, page, pageSize int) ([]*models.WithdrawRequest, int64, error) {
+	return s.withdrawRepo.FindByOwnerOrBeneficiary(ctx, chainID, data, page, pageSize)
+}
+
 // RequestPayoutExecution requests backend multisig to execute payout
 // This should be called when execute_status = success but payout hasn't been executed yet
 func (s *WithdrawRequestService) RequestPayoutExecution(ctx context.Context, requestID string) error {
@@ -1481,20 +2303,20 @@ func (s *WithdrawRequestService) RequestPayoutExecution(ctx context.Context, req
 
 	// Validate: execute must be successful
 	if request.ExecuteStatus != models.ExecuteStatusSuccess {
-		return errors.New("execute not successful yet, cannot request payout")
+		return fmt.Errorf("%w yet, cannot request payout", ErrExecuteNotSuccessful)
 	}
 
 	// Check if payout is already completed or processing
-	if request.PayoutStatus == models.PayoutStatusCompleted {
-		return errors.New("payout already completed")
+	if request.IsPayoutFinal() {
+		return ErrPayoutAlreadyCompleted
 	}
 	if request.PayoutStatus == models.PayoutStatusProcessing {
-		return errors.New("payout is already being processed")
+		return fmt.Errorf("payout is %w", ErrAlreadyProcessing)
 	}
 
 	// Check retry limit
-	if request.PayoutRetryCount >= 5 {
-		return ErrMaxRetriesExceeded
+	if err := s.checkRetryLimit(models.RetryStagePayout, request.PayoutRetryCount); err != nil {
+		return err
 	}
 
 	// Trigger payout execution
@@ -1509,6 +2331,7 @@ func (s *WithdrawRequestService) RequestPayoutExecution(ctx context.Context, req
 // ClaimTimeout allows user to claim funds on source chain after timeout
 // This is used when payout fails or times out
 func (s *WithdrawRequestService) ClaimTimeout(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "ClaimTimeout")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1516,12 +2339,12 @@ func (s *WithdrawRequestService) ClaimTimeout(ctx context.Context, requestID str
 
 	// Validate: execute must be successful (nullifiers consumed)
 	if request.ExecuteStatus != models.ExecuteStatusSuccess {
-		return errors.New("cannot claim timeout: execute not successful yet")
+		return fmt.Errorf("cannot claim timeout: %w yet", ErrExecuteNotSuccessful)
 	}
 
 	// Check if payout is already completed
-	if request.PayoutStatus == models.PayoutStatusCompleted {
-		return errors.New("cannot claim timeout: payout already completed")
+	if request.IsPayoutFinal() {
+		return fmt.Errorf("cannot claim timeout: %w", ErrPayoutAlreadyCompleted)
 	}
 
 	// In production, this would:
@@ -1532,9 +2355,14 @@ func (s *WithdrawRequestService) ClaimTimeout(ctx context.Context, requestID str
 
 	// For now, simulate the timeout claim
 	// Update status to indicate timeout was claimed
-	request.PayoutStatus = models.PayoutStatusCompleted
-	request.Status = string(models.WithdrawStatusCompleted)
-	if err := s.withdrawRepo.Update(ctx, request); err != nil {
+	if _, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+		if request.IsPayoutFinal() {
+			return fmt.Errorf("cannot claim timeout: %w", ErrPayoutAlreadyCompleted)
+		}
+		request.PayoutStatus = models.PayoutStatusCompleted
+		request.Status = string(models.WithdrawStatusCompleted)
+		return nil
+	}); err != nil {
 		return err
 	}
 
@@ -1544,6 +2372,7 @@ func (s *WithdrawRequestService) ClaimTimeout(ctx context.Context, requestID str
 // RequestHookPurchase requests direct asset purchase via Hook
 // This can be called to execute Hook purchase after payout completes
 func (s *WithdrawRequestService) RequestHookPurchase(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "RequestHookPurchase")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1551,33 +2380,42 @@ func (s *WithdrawRequestService) RequestHookPurchase(ctx context.Context, reques
 
 	// Validate: payout must be completed
 	if request.PayoutStatus != models.PayoutStatusCompleted {
-		return errors.New("payout not completed yet, cannot purchase asset")
+		return fmt.Errorf("%w yet, cannot purchase asset", ErrPayoutNotCompleted)
 	}
 
 	// Check if hook is already completed or processing
-	if request.HookStatus == models.HookStatusCompleted {
-		return errors.New("hook purchase already completed")
+	if request.IsHookFinal() {
+		return fmt.Errorf("hook purchase already finished (hook_status=%s)", request.HookStatus)
 	}
 	if request.HookStatus == models.HookStatusProcessing {
-		return errors.New("hook purchase is already being processed")
+		return fmt.Errorf("hook purchase is %w", ErrAlreadyProcessing)
 	}
 
 	// Check retry limit
-	if request.HookRetryCount >= 5 {
-		return ErrMaxRetriesExceeded
+	if err := s.checkRetryLimit(models.RetryStageHook, request.HookRetryCount); err != nil {
+		return err
+	}
+
+	// Nothing to purchase without calldata
+	if request.HookCalldata == "" {
+		return ErrHookCalldataMissing
 	}
 
 	// Update hook status to required if it was not_required
 	if request.HookStatus == models.HookStatusNotRequired {
-		request.HookStatus = models.HookStatusPending
-		if err := s.withdrawRepo.Update(ctx, request); err != nil {
+		if _, err := s.withdrawRepo.Modify(ctx, requestID, func(request *models.WithdrawRequest) error {
+			if request.HookStatus == models.HookStatusNotRequired {
+				request.HookStatus = models.HookStatusPending
+			}
+			return nil
+		}); err != nil {
 			return err
 		}
 	}
 
 	// Trigger hook execution
 	// In production, this would:
-	// 1. Read hookCalldata from database
+	// 1. Read request.HookCalldata
 	// 2. Call IntentManager.executeIntent(beneficiary, amount, hookCalldata)
 	// 3. IntentManager executes calldata (Aave/Compound/Uniswap/etc.)
 	return s.ProcessHook(ctx, requestID)
@@ -1586,6 +2424,7 @@ func (s *WithdrawRequestService) RequestHookPurchase(ctx context.Context, reques
 // WithdrawOriginalTokens allows beneficiary to withdraw original tokens from IntentManager
 // This is used when user gives up on Hook after multiple failures
 func (s *WithdrawRequestService) WithdrawOriginalTokens(ctx context.Context, requestID string) error {
+	ctx = repository.WithTransitionTrigger(ctx, "WithdrawOriginalTokens")
 	request, err := s.withdrawRepo.GetByID(ctx, requestID)
 	if err != nil {
 		return err
@@ -1593,17 +2432,17 @@ func (s *WithdrawRequestService) WithdrawOriginalTokens(ctx context.Context, req
 
 	// Validate: payout must be completed (funds in IntentManager)
 	if request.PayoutStatus != models.PayoutStatusCompleted {
-		return errors.New("payout not completed yet, cannot withdraw original tokens")
+		return fmt.Errorf("%w yet, cannot withdraw original tokens", ErrPayoutNotCompleted)
 	}
 
 	// Check if hook already completed
-	if request.HookStatus == models.HookStatusCompleted {
-		return errors.New("hook already completed, tokens have been converted")
+	if request.IsHookFinal() {
+		return fmt.Errorf("hook already finished (hook_status=%s), cannot withdraw original tokens", request.HookStatus)
 	}
 
 	// Check if hook has failed (or not required)
 	if request.HookStatus != models.HookStatusFailed && request.HookStatus != models.HookStatusNotRequired {
-		return errors.New("hook not in failed state, cannot withdraw original tokens")
+		return fmt.Errorf("%w, cannot withdraw original tokens", ErrHookNotFailed)
 	}
 
 	// In production, this would:
@@ -1625,42 +2464,45 @@ func (s *WithdrawRequestService) WithdrawOriginalTokens(ctx context.Context, req
 	}
 
 	// Update main status
-	request.HookStatus = models.HookStatusAbandoned
-	request.UpdateMainStatus()
-	return s.withdrawRepo.Update(ctx, request)
+	return s.refreshMainStatus(ctx, requestID)
 }
 
 // ============ Helper methods ============
 
 // validateAllocations validates that all allocations can be used for withdrawal
-// Now supports allocations from different checkbooks (different deposits) as long as they belong to the same user
-func (s *WithdrawRequestService) validateAllocations(allocations []*models.Check) error {
+// Now supports allocations from different checkbooks (different deposits) as long as they belong to the same user.
+// Returns the referenced checkbooks, loaded in one query, in order of first use by the allocations.
+func (s *WithdrawRequestService) validateAllocations(ctx context.Context, allocations []*models.Check) ([]*models.Checkbook, error) {
 	if len(allocations) == 0 {
-		return ErrInvalidAllocations
+		return nil, ErrInvalidAllocations
 	}
 
 	// Check all allocations are idle
 	for _, alloc := range allocations {
 		if alloc.Status != models.AllocationStatusIdle {
-			return ErrAllocationsNotIdle
+			return nil, ErrAllocationsNotIdle
 		}
 	}
 
-	// Check all allocations belong to the same user (same owner address)
-	// Get first checkbook to get owner address
-	firstCheckbook, err := s.checkbookRepo.GetByID(context.Background(), allocations[0].CheckbookID)
+	// Load each referenced checkbook once
+	_, checkbookIDs := groupAllocationsByCheckbook(allocations)
+	checkbookList, err := s.checkbookRepo.GetByIDs(ctx, checkbookIDs)
 	if err != nil {
-		return fmt.Errorf("failed to get first checkbook: %w", err)
+		return nil, fmt.Errorf("failed to get checkbooks: %w", err)
+	}
+	checkbooks := make(map[string]*models.Checkbook, len(checkbookList))
+	for _, checkbook := range checkbookList {
+		checkbooks[checkbook.ID] = checkbook
 	}
+
+	// Check all allocations belong to the same user (same owner address)
+	firstCheckbook := checkbooks[allocations[0].CheckbookID]
 	firstOwnerAddress := firstCheckbook.UserAddress.Data
 	firstOwnerChainID := firstCheckbook.UserAddress.SLIP44ChainID
 
 	// Verify all other allocations belong to checkbooks with the same owner
 	for i := 1; i < len(allocations); i++ {
-		checkbook, err := s.checkbookRepo.GetByID(context.Background(), allocations[i].CheckbookID)
-		if err != nil {
-			return fmt.Errorf("failed to get checkbook for allocation %s: %w", allocations[i].ID, err)
-		}
+		checkbook := checkbooks[allocations[i].CheckbookID]
 
 		// Compare owner address (case-insensitive for EVM addresses)
 		ownerAddress := checkbook.UserAddress.Data
@@ -1671,43 +2513,269 @@ func (s *WithdrawRequestService) validateAllocations(allocations []*models.Check
 		ownerAddrNormalized := strings.ToLower(ownerAddress)
 
 		if ownerAddrNormalized != firstAddrNormalized || ownerChainID != firstOwnerChainID {
-			return fmt.Errorf("allocations belong to different users: first owner=%s (chain=%d), allocation[%d] owner=%s (chain=%d)",
+			return nil, fmt.Errorf("allocations belong to different users: first owner=%s (chain=%d), allocation[%d] owner=%s (chain=%d)",
 				firstOwnerAddress, firstOwnerChainID, i, ownerAddress, ownerChainID)
 		}
 	}
 
+	// One source token per proof (see sourceTokenSymbol)
+	if _, err := s.sourceTokenSymbol(checkbookList); err != nil {
+		return nil, err
+	}
+
+	if err := validateAllocatableTotals(allocations, checkbooks); err != nil {
+		return nil, err
+	}
+	return checkbookList, nil
+}
+
+// checkbookTokenSymbol the token symbol of a checkbook's deposit: its token key, else the symbol registered for
+// its token address, else USDT
+func (s *WithdrawRequestService) checkbookTokenSymbol(checkbook *models.Checkbook) string {
+	if checkbook.TokenKey != "" {
+		return checkbook.TokenKey
+	}
+	if s.intentService != nil && checkbook.TokenAddress != "" {
+		var rawToken models.IntentRawToken
+		err := s.intentService.DB().Where("token_address = ? AND chain_id = ?", strings.ToLower(checkbook.TokenAddress), checkbook.SLIP44ChainID).First(&rawToken).Error
+		if err == nil && rawToken.Symbol != "" {
+			return rawToken.Symbol
+		}
+	}
+	return "USDT" // Fallback
+}
+
+// sourceTokenSymbol the token symbol shared by all checkbooks of a withdraw
+// ZKVM takes a single source_token_symbol for every CommitmentGroup, so checkbooks of different tokens cannot
+// be combined in one proof; returns ErrMixedTokenAllocations for them.
+func (s *WithdrawRequestService) sourceTokenSymbol(checkbooks []*models.Checkbook) (string, error) {
+	symbol := ""
+	for i, checkbook := range checkbooks {
+		tokenSymbol := s.checkbookTokenSymbol(checkbook)
+		if i == 0 {
+			symbol = tokenSymbol
+		} else if tokenSymbol != symbol {
+			return "", fmt.Errorf("%w: checkbook %s holds %s, checkbook %s holds %s",
+				ErrMixedTokenAllocations, checkbooks[0].ID, symbol, checkbook.ID, tokenSymbol)
+		}
+	}
+	return symbol, nil
+}
+
+// validateAllocatableTotals checks that, per checkbook, the sum of allocation amounts does not exceed
+// the checkbook's AllocatableAmount (guards against inflated allocations producing an invalid proof)
+func validateAllocatableTotals(allocations []*models.Check, checkbooks map[string]*models.Checkbook) error {
+	totals := make(map[string]*big.Int)
+	var checkbookIDs []string // first-seen order, for deterministic errors
+	for _, alloc := range allocations {
+		amount, ok := new(big.Int).SetString(alloc.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			return fmt.Errorf("%w: allocation %s has invalid amount %q", ErrInvalidAllocations, alloc.ID, alloc.Amount)
+		}
+		total, exists := totals[alloc.CheckbookID]
+		if !exists {
+			total = new(big.Int)
+			totals[alloc.CheckbookID] = total
+			checkbookIDs = append(checkbookIDs, alloc.CheckbookID)
+		}
+		total.Add(total, amount)
+	}
+
+	for _, checkbookID := range checkbookIDs {
+		checkbook := checkbooks[checkbookID]
+		allocatable, ok := new(big.Int).SetString(checkbook.AllocatableAmount, 10)
+		if !ok {
+			return fmt.Errorf("%w: checkbook %s has invalid allocatable amount %q", ErrInvalidAllocations, checkbookID, checkbook.AllocatableAmount)
+		}
+		if totals[checkbookID].Cmp(allocatable) > 0 {
+			return fmt.Errorf("%w: allocations for checkbook %s total %s, exceeding allocatable amount %s",
+				ErrAllocationsExceedAllocatable, checkbookID, totals[checkbookID].String(), allocatable.String())
+		}
+	}
+
 	return nil
 }
 
 // calculateTotalAmount calculates total amount from allocations
-func (s *WithdrawRequestService) calculateTotalAmount(allocations []*models.Check) string {
+// Fails if any amount is not a uint256 decimal or the total overflows uint256: a skipped allocation
+// would produce a total that does not match the proof.
+func (s *WithdrawRequestService) calculateTotalAmount(allocations []*models.Check) (string, error) {
 	if len(allocations) == 0 {
-		return "0"
+		return "0", nil
 	}
 
 	// Use big.Int for precision
 	total := new(big.Int)
 	for _, alloc := range allocations {
-		amount, ok := new(big.Int).SetString(alloc.Amount, 10)
-		if !ok {
-			// If parsing fails, skip this allocation
-			continue
+		amount, err := parseAllocationAmount(alloc.Amount)
+		if err != nil {
+			return "", fmt.Errorf("allocation %s: %w", alloc.ID, err)
 		}
 		total.Add(total, amount)
 	}
+	if total.BitLen() > 256 {
+		return "", fmt.Errorf("%w: total %s exceeds uint256", ErrInvalidAllocationAmount, total.String())
+	}
+
+	return total.String(), nil
+}
 
-	return total.String()
+// parseAllocationAmount parses an allocation amount (decimal wei) that must fit the 32-byte uint256 of the allocation hash
+func parseAllocationAmount(amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidAllocationAmount, amount)
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s is negative", ErrInvalidAllocationAmount, amount)
+	}
+	if value.BitLen() > 256 {
+		return nil, fmt.Errorf("%w: %s exceeds uint256", ErrInvalidAllocationAmount, amount)
+	}
+	return value, nil
+}
+
+// AllocationAmountHex converts an allocation amount, decimal or 0x-prefixed hex, to the 32-byte hex (64 chars,
+// no 0x prefix) ZKVM expects; amounts that are not numbers, negative or above uint256 return ErrInvalidAllocationAmount
+func AllocationAmountHex(amount string) (string, error) {
+	if hexDigits, ok := strings.CutPrefix(amount, "0x"); ok {
+		value, ok := new(big.Int).SetString(hexDigits, 16)
+		if !ok || value.Sign() < 0 {
+			return "", fmt.Errorf("%w: %q is not a hex number", ErrInvalidAllocationAmount, amount)
+		}
+		if value.BitLen() > 256 {
+			return "", fmt.Errorf("%w: %s exceeds uint256", ErrInvalidAllocationAmount, amount)
+		}
+		return fmt.Sprintf("%064x", value), nil
+	}
+	value, err := parseAllocationAmount(amount)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%064x", value), nil
 }
 
 // getAllocationIDs extracts allocation IDs from WithdrawRequest
 func (s *WithdrawRequestService) getAllocationIDs(request *models.WithdrawRequest) ([]string, error) {
 	var ids []string
 	if err := json.Unmarshal([]byte(request.AllocationIDs), &ids); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal allocation IDs: %w", err)
+		return nil, fmt.Errorf("%w for request %s: %v", ErrMalformedAllocationIDs, request.ID, err)
 	}
 	return ids, nil
 }
 
+// PreviewCommitmentGroups builds the CommitmentGroups that proof generation would send to ZKVM for these allocations
+// Read-only: no status changes and nothing is enqueued, so the UI can show left/right hashes and
+// commitments-after before the user signs.
+func (s *WithdrawRequestService) PreviewCommitmentGroups(ctx context.Context, allocationIDs []string) ([]types.CommitmentGroupRequest, error) {
+	if len(allocationIDs) == 0 {
+		return nil, fmt.Errorf("allocation IDs are required")
+	}
+
+	allocations, err := s.allocationRepo.GetByIDs(ctx, allocationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocations: %w", err)
+	}
+	if len(allocations) != len(allocationIDs) {
+		return nil, fmt.Errorf("some allocations not found: requested %d, found %d", len(allocationIDs), len(allocations))
+	}
+
+	checkbookGroups, checkbookIDs := groupAllocationsByCheckbook(allocations)
+	checkbooks, err := s.checkbookRepo.GetByIDs(ctx, checkbookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkbooks: %w", err)
+	}
+	if err := validateProofCheckbooks(checkbooks); err != nil {
+		return nil, err
+	}
+
+	return s.buildCommitmentGroups(ctx, checkbooks, checkbookGroups)
+}
+
+// groupAllocationsByCheckbook groups allocations by checkbook, returning the checkbook IDs in allocation order
+func groupAllocationsByCheckbook(allocations []*models.Check) (map[string][]*models.Check, []string) {
+	checkbookGroups := make(map[string][]*models.Check) // key: checkbookID, value: allocations
+	checkbookIDs := make([]string, 0)
+	for _, alloc := range allocations {
+		if _, seen := checkbookGroups[alloc.CheckbookID]; !seen {
+			checkbookIDs = append(checkbookIDs, alloc.CheckbookID)
+		}
+		checkbookGroups[alloc.CheckbookID] = append(checkbookGroups[alloc.CheckbookID], alloc)
+	}
+	return checkbookGroups, checkbookIDs
+}
+
+// validateProofCheckbooks checks that the checkbooks of a proof belong to the same user and all have commitments
+func validateProofCheckbooks(checkbooks []*models.Checkbook) error {
+	if len(checkbooks) == 0 {
+		return fmt.Errorf("no checkbooks found")
+	}
+
+	first := checkbooks[0]
+	firstOwnerAddress := strings.ToLower(first.UserAddress.Data)
+	for _, checkbook := range checkbooks[1:] {
+		if strings.ToLower(checkbook.UserAddress.Data) != firstOwnerAddress || checkbook.UserAddress.SLIP44ChainID != first.UserAddress.SLIP44ChainID {
+			log.Printf("❌ [validateProofCheckbooks] Checkbook %s belongs to different user: %s (chain=%d) vs %s (chain=%d)",
+				checkbook.ID, checkbook.UserAddress.Data, checkbook.UserAddress.SLIP44ChainID,
+				first.UserAddress.Data, first.UserAddress.SLIP44ChainID)
+			return fmt.Errorf("All checkbooks must belong to the same user")
+		}
+	}
+
+	for _, checkbook := range checkbooks {
+		if checkbook.Commitment == nil || *checkbook.Commitment == "" {
+			return fmt.Errorf("Checkbook %s has no commitment", checkbook.ID)
+		}
+	}
+	return nil
+}
+
+// buildCommitmentGroups builds one CommitmentGroup per checkbook, sorted by deposit_id (ascending)
+// Within the same deposit_id the checkbook order is kept.
+func (s *WithdrawRequestService) buildCommitmentGroups(ctx context.Context, checkbooks []*models.Checkbook, checkbookGroups map[string][]*models.Check) ([]types.CommitmentGroupRequest, error) {
+	type commitmentGroupWithDepositID struct {
+		commitmentGroup types.CommitmentGroupRequest
+		depositID       uint64
+	}
+	commitmentGroupsWithDepositID := make([]commitmentGroupWithDepositID, 0, len(checkbooks))
+
+	for _, checkbook := range checkbooks {
+		// Get allocations for this checkbook
+		checkbookAllocations := checkbookGroups[checkbook.ID]
+
+		// Get ALL allocations from this checkbook (for computing left/right hashes)
+		allCheckbookAllocations, err := s.allocationRepo.FindByCheckbook(ctx, checkbook.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get checkbook allocations: %v", err)
+		}
+
+		log.Printf("📋 [buildCommitmentGroups] Checkbook %s: %d total allocations, %d in withdraw request",
+			checkbook.ID, len(allCheckbookAllocations), len(checkbookAllocations))
+
+		commitmentGroup, err := s.buildCommitmentGroupForCheckbook(ctx, checkbook, checkbookAllocations, allCheckbookAllocations)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to build CommitmentGroup: %v", err)
+		}
+
+		commitmentGroupsWithDepositID = append(commitmentGroupsWithDepositID, commitmentGroupWithDepositID{
+			commitmentGroup: *commitmentGroup,
+			depositID:       checkbook.LocalDepositID,
+		})
+		log.Printf("✅ [buildCommitmentGroups] Built CommitmentGroup for checkbook %s (deposit_id: %d): %d allocations",
+			checkbook.ID, checkbook.LocalDepositID, len(commitmentGroup.Allocations))
+	}
+
+	sort.SliceStable(commitmentGroupsWithDepositID, func(i, j int) bool {
+		return commitmentGroupsWithDepositID[i].depositID < commitmentGroupsWithDepositID[j].depositID
+	})
+
+	commitmentGroups := make([]types.CommitmentGroupRequest, 0, len(commitmentGroupsWithDepositID))
+	for _, cg := range commitmentGroupsWithDepositID {
+		commitmentGroups = append(commitmentGroups, cg.commitmentGroup)
+	}
+	return commitmentGroups, nil
+}
+
 // buildCommitmentGroupForCheckbook builds a CommitmentGroup for a specific checkbook and its allocations
 // This helper function is used to support cross-deposit withdrawals (multiple checkbooks)
 func (s *WithdrawRequestService) buildCommitmentGroupForCheckbook(
@@ -1741,24 +2809,12 @@ func (s *WithdrawRequestService) buildCommitmentGroupForCheckbook(
 		}
 	}
 
-	// Get subsequent commitments
+	// Get subsequent commitments (position index, complete regardless of queue length)
 	commitmentsAfter := []string{}
 	if queueRoot != nil {
-		currentRoot := queueRoot.Root
-		maxTraversal := 1000
-		for i := 0; i < maxTraversal; i++ {
-			nextQueueRoot, err := s.queueRootRepo.FindByPreviousRoot(ctx, currentRoot)
-			if err != nil {
-				if err == gorm.ErrRecordNotFound {
-					break
-				}
-				log.Printf("⚠️ [buildCommitmentGroup] Failed to query subsequent queue root: %v", err)
-				break
-			}
-			if nextQueueRoot.CreatedByCommitment != "" {
-				commitmentsAfter = append(commitmentsAfter, nextQueueRoot.CreatedByCommitment)
-			}
-			currentRoot = nextQueueRoot.Root
+		commitmentsAfter, err = s.queueRootRepo.GetCommitmentsAfter(ctx, queueRoot.Root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query subsequent commitments: %w", err)
 		}
 	}
 
@@ -1783,9 +2839,9 @@ func (s *WithdrawRequestService) buildCommitmentGroupForCheckbook(
 		amount string
 	}, len(allCheckbookAllocations))
 	for i, alloc := range allCheckbookAllocations {
-		amountBig, ok := new(big.Int).SetString(alloc.Amount, 10)
-		if !ok {
-			return nil, fmt.Errorf("invalid amount format: %s", alloc.Amount)
+		amountBig, err := parseAllocationAmount(alloc.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("allocation %s: %w", alloc.ID, err)
 		}
 		amountHex := fmt.Sprintf("%064x", amountBig)
 		sortedAllCheckbookAllocations[i] = struct {
@@ -1827,9 +2883,9 @@ func (s *WithdrawRequestService) buildCommitmentGroupForCheckbook(
 	// Build AllocationWithCredentialRequest for each allocation in this checkbook
 	allocationWithCredentialRequests := make([]types.AllocationWithCredentialRequest, len(checkbookAllocations))
 	for i, alloc := range checkbookAllocations {
-		amountBig, ok := new(big.Int).SetString(alloc.Amount, 10)
-		if !ok {
-			return nil, fmt.Errorf("invalid amount format: %s", alloc.Amount)
+		amountBig, err := parseAllocationAmount(alloc.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("allocation %s: %w", alloc.ID, err)
 		}
 		amountHex := fmt.Sprintf("%064x", amountBig)
 
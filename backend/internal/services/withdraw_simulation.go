@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrSimulationReverted executeWithdraw simulation (eth_call) reverted, the transaction would fail on-chain
+var ErrSimulationReverted = errors.New("executeWithdraw simulation reverted")
+
+// withdrawSimulationTimeout timeout for the eth_call
+const withdrawSimulationTimeout = 15 * time.Second
+
+// SimulateWithdraw simulates executeWithdraw on its submission chain via eth_call from the signing address
+// Uses the same calldata and chain as SubmitWithdraw. Returns an error wrapping ErrSimulationReverted with the decoded
+// revert reason if the call reverts; other errors mean the simulation could not be performed.
+func (b *BlockchainTransactionService) SimulateWithdraw(req *WithdrawRequest) error {
+	submitChainID := withdrawSubmitChain(req)
+	networkConfig, err := config.GetNetworkConfigByChainID(submitChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	client, exists := b.lookupClient(submitChainID)
+	if !exists {
+		return fmt.Errorf("submission chain client not initialized for chainID %d", submitChainID)
+	}
+
+	signingAddress, err := b.keyMgmtService.GetSigningAddress(networkConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get signing address: %w", err)
+	}
+
+	zkpayContract, err := getZKPayContractAddress(networkConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get ZKPay contract address: %w", err)
+	}
+	contractAddress := common.HexToAddress(zkpayContract)
+
+	callData, err := b.buildWithdrawCallData(networkConfig, req)
+	if err != nil {
+		return fmt.Errorf("failed to build call data: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), withdrawSimulationTimeout)
+	defer cancel()
+
+	if err := simulateWithdrawCall(ctx, client, common.HexToAddress(signingAddress), contractAddress, callData); err != nil {
+		if errors.Is(err, ErrSimulationReverted) {
+			log.Printf("❌ [SimulateWithdraw] executeWithdraw would revert: CheckID=%s, %v", req.CheckID, err)
+		}
+		return err
+	}
+
+	log.Printf("✅ [SimulateWithdraw] executeWithdraw simulation succeeded: CheckID=%s", req.CheckID)
+	return nil
+}
+
+// simulateWithdrawCall runs the executeWithdraw calldata as an eth_call from the signing address
+// A revert returns an error wrapping ErrSimulationReverted with the decoded reason.
+func simulateWithdrawCall(ctx context.Context, client *ethclient.Client, from, contract common.Address, callData []byte) error {
+	_, err := client.CallContract(ctx, ethereum.CallMsg{
+		From: from,
+		To:   &contract,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		if reason, reverted := decodeRevertReason(err); reverted {
+			return fmt.Errorf("%w: %s", ErrSimulationReverted, reason)
+		}
+		return fmt.Errorf("eth_call failed: %w", err)
+	}
+	return nil
+}
+
+// decodeRevertReason extracts the revert reason from an eth_call error
+// Returns reverted=false if the error is not a revert (e.g. network/RPC failure)
+func decodeRevertReason(err error) (reason string, reverted bool) {
+	if raw, ok := revertData(err); ok {
+		// Error(string) revert; custom errors are returned as raw data
+		if unpacked, unpackErr := abi.UnpackRevert(raw); unpackErr == nil {
+			return unpacked, true
+		}
+		return "custom error " + hexutil.Encode(raw), true
+	}
+
+	if strings.Contains(err.Error(), "execution reverted") {
+		return err.Error(), true
+	}
+	return "", false
+}
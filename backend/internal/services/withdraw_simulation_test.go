@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// newStubCallRPC an RPC endpoint answering every eth_call with the given JSON-RPC error (or "0x" when rpcErr is nil)
+func newStubCallRPC(t *testing.T, rpcErr map[string]interface{}) *ethclient.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if req.Method == "eth_call" && rpcErr != nil {
+			response["error"] = rpcErr
+		} else {
+			response["result"] = "0x"
+		}
+		body, _ := json.Marshal(response)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial stub: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// errorStringRevert ABI-encodes a Solidity Error(string) revert
+func errorStringRevert(t *testing.T, reason string) string {
+	t.Helper()
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(append([]byte{0x08, 0xc3, 0x79, 0xa0}, packed...))
+}
+
+func TestSimulateWithdrawCall(t *testing.T) {
+	tests := []struct {
+		name         string
+		rpcErr       map[string]interface{}
+		wantReverted bool
+		wantReason   string
+	}{
+		{
+			name:   "call succeeds",
+			rpcErr: nil,
+		},
+		{
+			name:         "Error(string) revert",
+			rpcErr:       map[string]interface{}{"code": 3, "message": "execution reverted: invalid proof", "data": errorStringRevert(t, "invalid proof")},
+			wantReverted: true,
+			wantReason:   "invalid proof",
+		},
+		{
+			name:         "custom error revert",
+			rpcErr:       map[string]interface{}{"code": 3, "message": "execution reverted", "data": "0x12345678"},
+			wantReverted: true,
+			wantReason:   "custom error 0x12345678",
+		},
+		{
+			name:         "revert without data",
+			rpcErr:       map[string]interface{}{"code": -32000, "message": "execution reverted"},
+			wantReverted: true,
+			wantReason:   "execution reverted",
+		},
+		{
+			name:   "RPC failure",
+			rpcErr: map[string]interface{}{"code": -32000, "message": "header not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newStubCallRPC(t, tt.rpcErr)
+			err := simulateWithdrawCall(context.Background(), client,
+				common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+				common.HexToAddress("0x00000000000000000000000000000000000000cc"), []byte{0x01})
+
+			switch {
+			case tt.rpcErr == nil:
+				if err != nil {
+					t.Errorf("simulateWithdrawCall: %v, want nil", err)
+				}
+			case tt.wantReverted:
+				if !errors.Is(err, ErrSimulationReverted) || !strings.Contains(err.Error(), tt.wantReason) {
+					t.Errorf("simulateWithdrawCall: %v, want ErrSimulationReverted with %q", err, tt.wantReason)
+				}
+			default:
+				if err == nil || errors.Is(err, ErrSimulationReverted) {
+					t.Errorf("simulateWithdrawCall: %v, want a non-revert error", err)
+				}
+			}
+		})
+	}
+}
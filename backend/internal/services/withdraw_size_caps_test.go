@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCreateWithdrawRequestAllocationCap(t *testing.T) {
+	tests := []struct {
+		name        string
+		allocations int
+		wantErr     error
+	}{
+		{"below the cap", 2, nil},
+		{"at the cap", 3, nil},
+		{"one over the cap", 4, ErrTooManyAllocations},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			amounts := make([]string, tt.allocations)
+			for i := range amounts {
+				amounts[i] = "100"
+			}
+			ids := store.addIdleAllocations("cb1", "0xowner", amounts...)
+			service := newFakeWithdrawService(store)
+			service.maxAllocationsPerWithdraw = 3
+
+			_, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateWithdrawRequest with %d allocations: %v, want %v", tt.allocations, err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(store.requests) != 0 {
+				t.Errorf("%d requests stored after a rejected create", len(store.requests))
+			}
+		})
+	}
+}
+
+func TestCreateWithdrawRequestCheckbookCap(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkbooks []string
+		wantErr    error
+	}{
+		{"below the cap", []string{"cb1"}, nil},
+		{"at the cap", []string{"cb1", "cb2"}, nil},
+		{"one over the cap", []string{"cb1", "cb2", "cb3"}, ErrTooManyCheckbooks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			var ids []string
+			for _, checkbookID := range tt.checkbooks {
+				// Two allocations per checkbook: the cap counts checkbooks, not allocations
+				ids = append(ids, store.addIdleAllocations(checkbookID, "0xowner", "100", "200")...)
+			}
+			service := newFakeWithdrawService(store)
+			service.maxCheckbooksPerWithdraw = 2
+
+			_, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateWithdrawRequest over %d checkbooks: %v, want %v", len(tt.checkbooks), err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				for _, id := range ids {
+					if store.allocation(id).WithdrawRequestID != nil {
+						t.Errorf("allocation %s locked by a rejected create", id)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend/internal/clients"
+	"go-backend/internal/models"
+)
+
+func TestCreateWithdrawRequestRejectsMixedTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  []string // token key per checkbook
+		wantErr error
+	}{
+		{"one token", []string{"USDT", "USDT"}, nil},
+		{"mixed tokens", []string{"USDT", "USDC"}, ErrMixedTokenAllocations},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			var ids []string
+			for i, token := range tt.tokens {
+				checkbookID := fmt.Sprintf("cb%d", i+1)
+				ids = append(ids, store.addIdleAllocations(checkbookID, "0xowner", "100")...)
+				store.checkbooks[checkbookID].TokenKey = token
+			}
+			service := newFakeWithdrawService(store)
+
+			_, err := service.CreateWithdrawRequest(context.Background(), idempotentCreateInput("", ids...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateWithdrawRequest: %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			if len(store.requests) != 0 {
+				t.Errorf("%d requests stored after a rejected create", len(store.requests))
+			}
+			for _, id := range ids {
+				if store.allocation(id).WithdrawRequestID != nil {
+					t.Errorf("allocation %s locked by a rejected create", id)
+				}
+			}
+		})
+	}
+}
+
+// newRecordingZKVMClient a ZKVM client that records withdraw proof requests and fails them,
+// so proof generation stops right after the request is sent
+func newRecordingZKVMClient(t *testing.T) (*clients.ZKVMClient, *[]clients.WithdrawProofRequest) {
+	t.Helper()
+	var received []clients.WithdrawProofRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request clients.WithdrawProofRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode ZKVM request: %v", err)
+		}
+		received = append(received, request)
+		http.Error(w, "recorded", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	return &clients.ZKVMClient{BaseURL: server.URL, Client: server.Client()}, &received
+}
+
+func TestAutoGenerateProofSourceTokenPerGroup(t *testing.T) {
+	store := newFakeStore()
+	ids := append(store.addCommittedCheckbook("cb-a", 1, "100"), store.addCommittedCheckbook("cb-b", 2, "200", "300")...)
+	for _, checkbookID := range []string{"cb-a", "cb-b"} {
+		store.checkbooks[checkbookID].TokenKey = "USDC"
+	}
+	addProofRequest(t, store, "wr", ids)
+	service := newFakeWithdrawService(store)
+	service.queueRootRepo = &fakeQueueRootRepo{}
+	zkvmClient, received := newRecordingZKVMClient(t)
+	service.zkvmClient = zkvmClient
+
+	service.autoGenerateProofWithSignature(context.Background(), "wr", "0xsig", 60)
+
+	if len(*received) != 1 {
+		t.Fatalf("ZKVM received %d requests, want 1", len(*received))
+	}
+	request := (*received)[0]
+	if request.SourceTokenSymbol != "USDC" {
+		t.Errorf("source_token_symbol = %q, want USDC", request.SourceTokenSymbol)
+	}
+	if len(request.CommitmentGroups) != 2 {
+		t.Fatalf("%d commitment groups, want one per checkbook", len(request.CommitmentGroups))
+	}
+	for i, group := range request.CommitmentGroups {
+		for _, allocation := range group.Allocations {
+			if allocation.Credential.TokenKey != "USDC" {
+				t.Errorf("group %d credential token_key = %q, want USDC", i, allocation.Credential.TokenKey)
+			}
+		}
+	}
+}
+
+func TestAutoGenerateProofRejectsMixedTokenRequest(t *testing.T) {
+	// Created before mixed tokens were rejected at creation
+	store := newFakeStore()
+	ids := append(store.addCommittedCheckbook("cb-a", 1, "100"), store.addCommittedCheckbook("cb-b", 2, "200")...)
+	store.checkbooks["cb-b"].TokenKey = "USDC"
+	addProofRequest(t, store, "wr", ids)
+	service := newFakeWithdrawService(store)
+	service.queueRootRepo = &fakeQueueRootRepo{}
+	zkvmClient, received := newRecordingZKVMClient(t)
+	service.zkvmClient = zkvmClient
+
+	service.autoGenerateProofWithSignature(context.Background(), "wr", "0xsig", 60)
+
+	request := store.request("wr")
+	if request.ProofStatus != models.ProofStatusFailed || !strings.Contains(request.ProofError, ErrMixedTokenAllocations.Error()) {
+		t.Errorf("proof_status = %s (%q), want failed for mixed tokens", request.ProofStatus, request.ProofError)
+	}
+	if len(*received) != 0 {
+		t.Errorf("ZKVM received %d requests for a mixed-token withdraw, want none", len(*received))
+	}
+}
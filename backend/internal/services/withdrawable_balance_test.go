@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"go-backend/internal/models"
+	"go-backend/internal/utils"
+)
+
+func TestGetWithdrawableBalanceSumsIdleAllocationsPerToken(t *testing.T) {
+	const owner = "0x000000000000000000000000000000000000000000000000000000000000aaaa"
+	store := newFakeStore()
+
+	// USDT over two checkbooks, one allocation of each already taken
+	store.addIdleAllocations("usdt1", owner, "100", "200", "300")
+	store.allocations["usdt1-3"].Status = models.AllocationStatusPending
+	store.addIdleAllocations("usdt2", owner, "50", "70")
+	store.allocations["usdt2-2"].Status = models.AllocationStatusUsed
+
+	// ETH: one idle, one pending
+	store.addIdleAllocations("eth1", owner, "1000", "2000")
+	store.checkbooks["eth1"].TokenKey = "ETH"
+	store.allocations["eth1-2"].Status = models.AllocationStatusPending
+
+	// Not counted: a checkbook without a token key, one with nothing idle, and another owner's
+	store.addIdleAllocations("untyped", owner, "9999")
+	store.checkbooks["untyped"].TokenKey = ""
+	store.addIdleAllocations("spent", owner, "400")
+	store.checkbooks["spent"].TokenKey = "DAI"
+	store.allocations["spent-1"].Status = models.AllocationStatusUsed
+	store.addIdleAllocations("other", "0xother", "5000")
+
+	service := newFakeWithdrawService(store)
+	balances, err := service.GetWithdrawableBalance(context.Background(), 60, owner)
+	if err != nil {
+		t.Fatalf("GetWithdrawableBalance: %v", err)
+	}
+
+	want := map[string]struct {
+		amount string
+		count  int
+	}{
+		"USDT": {"350", 3},
+		"ETH":  {"1000", 1},
+	}
+	if len(balances) != len(want) {
+		t.Fatalf("balances = %v, want only %d token keys", balances, len(want))
+	}
+	converter := utils.NewDecimalConverter()
+	for tokenKey, w := range want {
+		balance, ok := balances[tokenKey]
+		if !ok {
+			t.Errorf("no %s balance", tokenKey)
+			continue
+		}
+		if balance.TokenKey != tokenKey || balance.Amount != w.amount || balance.AllocationCount != w.count {
+			t.Errorf("%s balance = %+v, want amount %s over %d allocations", tokenKey, balance, w.amount, w.count)
+		}
+		nativeAmount, err := converter.ConvertFromManagementAmount(w.amount, 60, 0)
+		if err != nil {
+			t.Fatalf("ConvertFromManagementAmount: %v", err)
+		}
+		if balance.NativeAmount != nativeAmount {
+			t.Errorf("%s native amount = %s, want %s", tokenKey, balance.NativeAmount, nativeAmount)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package types
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -264,3 +265,59 @@ func ExtractCommitmentRoot(publicValuesHex string) (string, error) {
 	}
 	return parsed.CommitmentRoot, nil
 }
+
+// executeWithdrawV1 is executeWithdraw(bytes proof, bytes encodedPublicValues), recognized when no methods are given
+var executeWithdrawV1 = abi.NewMethod("executeWithdraw", "executeWithdraw", abi.Function, "nonpayable", false, false,
+	abi.Arguments{
+		{Name: "proof", Type: mustNewType("bytes")},
+		{Name: "encodedPublicValues", Type: mustNewType("bytes")},
+	}, nil)
+
+// ParseExecuteWithdrawCallData decodes executeWithdraw transaction input data
+// and parses the encodedPublicValues argument into WithdrawPublicValues
+// methods are the executeWithdraw methods of the known contract versions; the one whose selector matches the
+// call data decodes it. Without methods only executeWithdraw(bytes,bytes) is recognized.
+func ParseExecuteWithdrawCallData(input []byte, methods ...abi.Method) (*WithdrawPublicValues, error) {
+	if len(input) < 4 {
+		return nil, fmt.Errorf("call data too short: %d bytes", len(input))
+	}
+	if len(methods) == 0 {
+		methods = []abi.Method{executeWithdrawV1}
+	}
+
+	var method *abi.Method
+	selectors := make([]string, 0, len(methods))
+	for i := range methods {
+		if bytes.Equal(input[:4], methods[i].ID) {
+			method = &methods[i]
+			break
+		}
+		selectors = append(selectors, methods[i].Sig+" 0x"+hex.EncodeToString(methods[i].ID))
+	}
+	if method == nil {
+		return nil, fmt.Errorf("unexpected method selector 0x%s, expected one of: %s",
+			hex.EncodeToString(input[:4]), strings.Join(selectors, ", "))
+	}
+
+	unpacked, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s call data: %w", method.Sig, err)
+	}
+
+	index := -1
+	for i, input := range method.Inputs {
+		if input.Name == "encodedPublicValues" {
+			index = i
+		}
+	}
+	if index < 0 || index >= len(unpacked) {
+		return nil, fmt.Errorf("%s has no encodedPublicValues argument", method.Sig)
+	}
+
+	encodedPublicValues, ok := unpacked[index].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("encodedPublicValues has unexpected type %T", unpacked[index])
+	}
+
+	return ParseWithdrawPublicValues(hex.EncodeToString(encodedPublicValues))
+}
@@ -153,6 +153,22 @@ func EvmToUniversalAddress(evmAddress string) (string, error) {
 	return "0x" + hex.EncodeToString(universalAddress), nil
 }
 
+// hexDigitsPattern hex digits only (any length)
+var hexDigitsPattern = regexp.MustCompile("^[0-9a-fA-F]+$")
+
+// PadToUniversalHex left-pads a hex address (with or without 0x) to 32 bytes: 0x + 64 hex chars
+// Inputs that are not hex or longer than 32 bytes are rejected rather than truncated.
+func PadToUniversalHex(address string) (string, error) {
+	hexStr := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(address), "0x"), "0X")
+	if !hexDigitsPattern.MatchString(hexStr) {
+		return "", fmt.Errorf("invalid hex address: %q", address)
+	}
+	if len(hexStr) > 64 {
+		return "", fmt.Errorf("address exceeds 32 bytes: %d hex chars (%s)", len(hexStr), address)
+	}
+	return "0x" + strings.Repeat("0", 64-len(hexStr)) + hexStr, nil
+}
+
 // base58Decode Base58
 func base58Decode(input string) ([]byte, error) {
 	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
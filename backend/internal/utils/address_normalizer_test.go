@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPadToUniversalHex(t *testing.T) {
+	evm := "d8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+	full := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name    string
+		address string
+		want    string
+	}{
+		{"short evm address", "0x" + evm, "0x000000000000000000000000" + evm},
+		{"short without prefix", evm, "0x000000000000000000000000" + evm},
+		{"single digit", "0x1", "0x" + strings.Repeat("0", 63) + "1"},
+		{"exactly 32 bytes", "0x" + full, "0x" + full},
+		{"exactly 32 bytes uppercase prefix", "0X" + full, "0x" + full},
+		{"surrounding whitespace", "  0x" + full + " ", "0x" + full},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PadToUniversalHex(tt.address)
+			if err != nil {
+				t.Fatalf("PadToUniversalHex(%q): %v", tt.address, err)
+			}
+			if got != tt.want {
+				t.Errorf("PadToUniversalHex(%q) = %s, want %s", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPadToUniversalHexRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr string
+	}{
+		{"one byte over", "0x" + strings.Repeat("ab", 32) + "cd", "exceeds 32 bytes"},
+		{"one nibble over", "0x1" + strings.Repeat("0", 64), "exceeds 32 bytes"},
+		{"empty", "", "invalid hex"},
+		{"prefix only", "0x", "invalid hex"},
+		{"not hex", "0xzz", "invalid hex"},
+		{"tron base58", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "invalid hex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PadToUniversalHex(tt.address)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("PadToUniversalHex(%q) = %q, %v, want an error containing %q", tt.address, got, err, tt.wantErr)
+			}
+		})
+	}
+}
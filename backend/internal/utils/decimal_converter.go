@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+)
+
+// managementDecimals decimals of management (contract) amounts
+const managementDecimals = 18
+
+// defaultChainDecimals built-in SLIP-44 chainId -> tokenId -> decimals, used when tokens.chainDecimals is not configured
+var defaultChainDecimals = map[int]map[int]int{
+	714: {0: 18, 1: 18, 2: 18, 3: 18}, // BSC: BNB and BEP-20 USDT/USDC/WETH all use 18 decimals
+	60:  {0: 18, 1: 6, 2: 6, 3: 18},   // Ethereum: ETH, USDT, USDC, WETH
+	966: {0: 18, 1: 6, 2: 6, 3: 18},   // Polygon: MATIC, USDT, USDC, WETH
+	195: {0: 6, 1: 6, 2: 6},           // TRON: TRX, USDT-TRC20, USDC-TRC20
+}
+
+// DecimalConverter converts token amounts between their native decimals and management amounts (18 decimals)
+type DecimalConverter struct {
+	chainDecimals map[int64]map[int]int // chainId -> tokenId -> decimals
+}
+
+// NewDecimalConverter creates a converter with the built-in decimals table
+func NewDecimalConverter() *DecimalConverter {
+	return NewDecimalConverterWithConfig(defaultChainDecimals)
+}
+
+// NewDecimalConverterWithConfig creates a converter from the tokens.chainDecimals config (chainId -> tokenId -> decimals)
+func NewDecimalConverterWithConfig(chainDecimals map[int]map[int]int) *DecimalConverter {
+	table := make(map[int64]map[int]int, len(chainDecimals))
+	for chainID, tokens := range chainDecimals {
+		chainTokens := make(map[int]int, len(tokens))
+		for tokenID, decimals := range tokens {
+			chainTokens[tokenID] = decimals
+		}
+		table[int64(chainID)] = chainTokens
+	}
+	return &DecimalConverter{chainDecimals: table}
+}
+
+// GetDecimals returns the native decimals of a token on a chain
+func (d *DecimalConverter) GetDecimals(chainID int64, tokenID int) (int, error) {
+	tokens, ok := d.chainDecimals[chainID]
+	if !ok {
+		return 0, fmt.Errorf("no decimals configured for chain %d", chainID)
+	}
+	decimals, ok := tokens[tokenID]
+	if !ok {
+		return 0, fmt.Errorf("no decimals configured for token %d on chain %d", tokenID, chainID)
+	}
+	return decimals, nil
+}
+
+// ConvertToManagementAmount converts a native token amount to a management amount (18 decimals)
+// Tokens with more than 18 decimals are truncated to management precision; an empty amount returns ErrEmptyAmount.
+// e.g. USDT (6 decimals): "1500000" -> "1500000000000000000"
+func (d *DecimalConverter) ConvertToManagementAmount(amount string, chainID int64, tokenID int) (string, error) {
+	if strings.TrimSpace(amount) == "" {
+		return "", ErrEmptyAmount
+	}
+	decimals, err := d.GetDecimals(chainID, tokenID)
+	if err != nil {
+		return "", err
+	}
+	value, ok := new(big.Int).SetString(strings.TrimSpace(amount), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid amount: %s", amount)
+	}
+	return scaleDecimals(value, decimals, managementDecimals).String(), nil
+}
+
+// LogConversion logs an amount conversion; direction is "to_management" or "from_management"
+func (d *DecimalConverter) LogConversion(from, to string, chainID int64, tokenID int, direction string) {
+	log.Printf("💱 [DecimalConverter] %s: %s -> %s (chain %d, token %d)", direction, from, to, chainID, tokenID)
+}
+
+// scaleDecimals rescales value from one number of decimals to another, truncating lost precision
+func scaleDecimals(value *big.Int, fromDecimals, toDecimals int) *big.Int {
+	scaled := new(big.Int).Set(value)
+	switch {
+	case fromDecimals < toDecimals:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil)
+		scaled.Mul(scaled, scale)
+	case fromDecimals > toDecimals:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil)
+		scaled.Quo(scaled, scale)
+	}
+	return scaled
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ConvertFromManagementAmount converts a management amount (18 decimals) back to the token's native decimals
+// Reverse of ConvertToManagementAmount, using the same config-driven chainId -> tokenId -> decimals table;
+// precision below one native unit is truncated.
+// e.g. USDT (6 decimals): "1500000000000000000" -> "1500000"
+func (d *DecimalConverter) ConvertFromManagementAmount(managementAmount string, chainID int64, tokenID int) (string, error) {
+	amount, ok := new(big.Int).SetString(strings.TrimSpace(managementAmount), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid management amount: %s", managementAmount)
+	}
+	decimals, err := d.GetDecimals(chainID, tokenID)
+	if err != nil {
+		return "", err
+	}
+	return scaleDecimals(amount, managementDecimals, decimals).String(), nil
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvertManagementAmountUSDT(t *testing.T) {
+	converter := NewDecimalConverter()
+
+	tests := []struct {
+		name       string
+		chainID    int64
+		native     string
+		management string
+	}{
+		{"ethereum usdt 6 decimals", 60, "1500000", "1500000000000000000"},
+		{"tron usdt 6 decimals", 195, "1", "1000000000000"},
+		{"bsc usdt 18 decimals", 714, "1500000000000000000", "1500000000000000000"},
+		{"zero", 60, "0", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			management, err := converter.ConvertToManagementAmount(tt.native, tt.chainID, 1)
+			if err != nil {
+				t.Fatalf("ConvertToManagementAmount: %v", err)
+			}
+			if management != tt.management {
+				t.Errorf("to management = %s, want %s", management, tt.management)
+			}
+			native, err := converter.ConvertFromManagementAmount(management, tt.chainID, 1)
+			if err != nil {
+				t.Fatalf("ConvertFromManagementAmount: %v", err)
+			}
+			if native != tt.native {
+				t.Errorf("round trip = %s, want %s", native, tt.native)
+			}
+		})
+	}
+}
+
+func TestConvertFromManagementAmountTruncatesSubUnitPrecision(t *testing.T) {
+	converter := NewDecimalConverter()
+
+	native, err := converter.ConvertFromManagementAmount("1500000999999999999", 60, 1)
+	if err != nil {
+		t.Fatalf("ConvertFromManagementAmount: %v", err)
+	}
+	if native != "1500000" {
+		t.Errorf("native = %s, want 1500000", native)
+	}
+}
+
+func TestConvertManagementAmountUsesConfiguredDecimals(t *testing.T) {
+	converter := NewDecimalConverterWithConfig(map[int]map[int]int{714: {1: 6}})
+
+	native, err := converter.ConvertFromManagementAmount("2000000000000000000", 714, 1)
+	if err != nil {
+		t.Fatalf("ConvertFromManagementAmount: %v", err)
+	}
+	if native != "2000000" {
+		t.Errorf("native = %s, want 2000000", native)
+	}
+	if _, err := converter.ConvertFromManagementAmount("1", 60, 1); err == nil {
+		t.Error("expected an error for a chain missing from the configured table")
+	}
+}
+
+func TestConvertManagementAmountRejectsInvalidInput(t *testing.T) {
+	converter := NewDecimalConverter()
+
+	if _, err := converter.ConvertFromManagementAmount("1.5", 60, 1); err == nil {
+		t.Error("expected an error for a non-integer management amount")
+	}
+	if _, err := converter.ConvertToManagementAmount("", 60, 1); !errors.Is(err, ErrEmptyAmount) {
+		t.Errorf("err = %v, want ErrEmptyAmount", err)
+	}
+	if _, err := converter.ConvertToManagementAmount("1", 60, 99); err == nil {
+		t.Error("expected an error for an unknown token")
+	}
+}
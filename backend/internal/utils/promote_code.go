@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// promoteCodeInvalidChars anything that is not an uppercase letter or digit
+var promoteCodeInvalidChars = regexp.MustCompile("[^A-Z0-9]")
+
+// NormalizePromoteCode canonicalizes a promote code so one logical code is always stored the same way
+// Plain codes are trimmed, uppercased and stripped of anything but A-Z and 0-9 ("  abc-123 " -> "ABC123").
+// The raw bytes6 hex form ("0x...") is kept as hex, lowercased like other stored hex values.
+func NormalizePromoteCode(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.HasPrefix(code, "0x") || strings.HasPrefix(code, "0X") {
+		if digits := code[2:]; digits != "" && hexDigitsPattern.MatchString(digits) {
+			return "0x" + strings.ToLower(digits)
+		}
+	}
+	return promoteCodeInvalidChars.ReplaceAllString(strings.ToUpper(code), "")
+}
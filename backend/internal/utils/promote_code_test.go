@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestNormalizePromoteCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"  abc123 ", "ABC123"},
+		{"ABC123", "ABC123"},
+		{"abc-123", "ABC123"},
+		{"a b_c.1!2?3", "ABC123"},
+		{"", ""},
+		{"   ", ""},
+		{"0xABCDEF012345", "0xabcdef012345"},
+		{" 0XAbCdEf012345 ", "0xabcdef012345"},
+		{"0x", "0X"},         // no hex digits: treated as a plain code
+		{"0xzz12", "0XZZ12"}, // not hex: treated as a plain code
+		{"0x-abc", "0XABC"},  // not hex: treated as a plain code
+	}
+	for _, tt := range tests {
+		if got := NormalizePromoteCode(tt.code); got != tt.want {
+			t.Errorf("NormalizePromoteCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
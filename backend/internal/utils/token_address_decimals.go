@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+)
+
+// ErrEmptyAmount an amount to convert was empty; callers decide the fallback (e.g. "0") instead of storing ""
+var ErrEmptyAmount = errors.New("empty amount")
+
+// TokenAddressDecimalConverter DecimalConverter with an additional chainId -> token address -> decimals table
+// DepositReceived only carries the token address (the tokenId is known from DepositRecorded), so its amount
+// is converted by address instead of assuming tokenId 0.
+type TokenAddressDecimalConverter struct {
+	*DecimalConverter
+	tokenAddressDecimals map[int64]map[string]int // chainId -> canonical token address -> decimals
+}
+
+// NewDecimalConverterWithTokenAddresses creates a converter from the tokens.chainDecimals and tokens.tokenAddressDecimals config
+// An empty chainDecimals table uses the DecimalConverter defaults.
+func NewDecimalConverterWithTokenAddresses(chainDecimals map[int]map[int]int, tokenAddressDecimals map[int]map[string]int) *TokenAddressDecimalConverter {
+	var base *DecimalConverter
+	if len(chainDecimals) > 0 {
+		base = NewDecimalConverterWithConfig(chainDecimals)
+	} else {
+		base = NewDecimalConverter()
+	}
+
+	byAddress := make(map[int64]map[string]int, len(tokenAddressDecimals))
+	for chainID, tokens := range tokenAddressDecimals {
+		chainTokens := make(map[string]int, len(tokens))
+		for address, decimals := range tokens {
+			chainTokens[tokenAddressKey(address, chainID)] = decimals
+		}
+		byAddress[int64(chainID)] = chainTokens
+	}
+
+	return &TokenAddressDecimalConverter{
+		DecimalConverter:     base,
+		tokenAddressDecimals: byAddress,
+	}
+}
+
+// ConvertToManagementAmountByAddress converts a native token amount to a management amount (18 decimals) by token address
+// Falls back to ConvertToManagementAmount with tokenId 0 when the address is not configured; an empty amount returns ErrEmptyAmount.
+// e.g. USDT (6 decimals): "1500000" -> "1500000000000000000"
+func (c *TokenAddressDecimalConverter) ConvertToManagementAmountByAddress(amount string, chainID int64, tokenAddress string) (string, error) {
+	if strings.TrimSpace(amount) == "" {
+		return "", ErrEmptyAmount
+	}
+
+	decimals, ok := c.TokenAddressDecimals(chainID, tokenAddress)
+	if !ok {
+		log.Printf("⚠️ [DecimalConverter] No decimals configured for token %s on chain %d, falling back to tokenId 0", tokenAddress, chainID)
+		return c.ConvertToManagementAmount(amount, chainID, 0)
+	}
+
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	switch {
+	case decimals < managementDecimals:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(managementDecimals-decimals)), nil)
+		value.Mul(value, scale)
+	case decimals > managementDecimals:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-managementDecimals)), nil)
+		value.Quo(value, scale)
+	}
+
+	log.Printf("💱 [DecimalConverter] %s (%d decimals, token %s, chain %d) -> %s (management)",
+		amount, decimals, tokenAddress, chainID, value.String())
+	return value.String(), nil
+}
+
+// ConvertFromManagementAmountByAddress converts a management amount (18 decimals) back to native decimals by token address
+// Reverse of ConvertToManagementAmountByAddress, falling back to tokenId 0 the same way; precision below one native unit
+// is truncated.
+// e.g. USDT (6 decimals): "1500000000000000000" -> "1500000"
+func (c *TokenAddressDecimalConverter) ConvertFromManagementAmountByAddress(managementAmount string, chainID int64, tokenAddress string) (string, error) {
+	decimals, ok := c.TokenAddressDecimals(chainID, tokenAddress)
+	if !ok {
+		return c.ConvertFromManagementAmount(managementAmount, chainID, 0)
+	}
+	amount, ok := new(big.Int).SetString(strings.TrimSpace(managementAmount), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid management amount: %s", managementAmount)
+	}
+	return scaleDecimals(amount, managementDecimals, decimals).String(), nil
+}
+
+// TokenAddressDecimals returns the configured decimals of a token address on a chain
+func (c *TokenAddressDecimalConverter) TokenAddressDecimals(chainID int64, tokenAddress string) (int, bool) {
+	tokens, ok := c.tokenAddressDecimals[chainID]
+	if !ok {
+		return 0, false
+	}
+	decimals, ok := tokens[tokenAddressKey(tokenAddress, int(chainID))]
+	return decimals, ok
+}
+
+// CanonicalTokenAddress the key a token address is looked up by in the tokens.tokenAddressDecimals table
+func CanonicalTokenAddress(address string, chainID int) string {
+	return tokenAddressKey(address, chainID)
+}
+
+// tokenAddressKey canonical lookup key for a token address: 20-byte lowercase hex where possible
+// TRON Base58 and 32-byte Universal Addresses map to the same key as their 20-byte hex form.
+func tokenAddressKey(address string, chainID int) string {
+	normalized := NormalizeAddressForChain(strings.TrimSpace(address), chainID)
+	if IsTronAddress(normalized) {
+		universal, err := TronToUniversalAddress(normalized)
+		if err != nil {
+			return normalized
+		}
+		normalized = universal
+	}
+	if IsUniversalAddress(normalized) {
+		if evm, err := ExtractEvmAddressFromUniversal(normalized); err == nil {
+			return evm
+		}
+	}
+	return strings.ToLower(normalized)
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvertToManagementAmountByAddress(t *testing.T) {
+	converter := NewDecimalConverterWithTokenAddresses(nil, map[int]map[string]int{
+		60:  {"0xdAC17F958D2ee523a2206206994597C13D831ec7": 6},
+		714: {"0x55d398326f99059fF775485246999027B3197955": 18},
+	})
+
+	tests := []struct {
+		name    string
+		chainID int64
+		address string
+		amount  string
+		want    string
+	}{
+		{"ethereum usdt by checksummed address", 60, "0xdAC17F958D2ee523a2206206994597C13D831ec7", "1500000", "1500000000000000000"},
+		{"ethereum usdt by lowercase address", 60, "0xdac17f958d2ee523a2206206994597c13d831ec7", "1500000", "1500000000000000000"},
+		{"bsc usdt keeps 18 decimals", 714, "0x55d398326f99059ff775485246999027b3197955", "1500000000000000000", "1500000000000000000"},
+		{"unknown address falls back to tokenId 0", 60, "0x0000000000000000000000000000000000000001", "1", "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := converter.ConvertToManagementAmountByAddress(tt.amount, tt.chainID, tt.address)
+			if err != nil {
+				t.Fatalf("ConvertToManagementAmountByAddress: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("management = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := converter.ConvertToManagementAmountByAddress(" ", 60, "0xdac17f958d2ee523a2206206994597c13d831ec7"); !errors.Is(err, ErrEmptyAmount) {
+		t.Errorf("err = %v, want ErrEmptyAmount", err)
+	}
+}
+
+func TestConvertFromManagementAmountByAddressReversesConversion(t *testing.T) {
+	converter := NewDecimalConverterWithTokenAddresses(nil, map[int]map[string]int{
+		60: {"0xdAC17F958D2ee523a2206206994597C13D831ec7": 6},
+	})
+
+	for _, native := range []string{"0", "1", "1500000", "123456789012"} {
+		management, err := converter.ConvertToManagementAmountByAddress(native, 60, "0xdac17f958d2ee523a2206206994597c13d831ec7")
+		if err != nil {
+			t.Fatalf("ConvertToManagementAmountByAddress(%s): %v", native, err)
+		}
+		got, err := converter.ConvertFromManagementAmountByAddress(management, 60, "0xdAC17F958D2ee523a2206206994597C13D831ec7")
+		if err != nil || got != native {
+			t.Errorf("round trip of %s = %s, %v, want %s", native, got, err, native)
+		}
+	}
+
+	if got, err := converter.ConvertFromManagementAmountByAddress("1000", 60, "0x0000000000000000000000000000000000000001"); err != nil || got != "1000" {
+		t.Errorf("unknown address = %s, %v, want the tokenId 0 decimals (1000)", got, err)
+	}
+	if _, err := converter.ConvertFromManagementAmountByAddress("1.5", 60, "0xdac17f958d2ee523a2206206994597c13d831ec7"); err == nil {
+		t.Error("non-integer management amount converted, want an error")
+	}
+}
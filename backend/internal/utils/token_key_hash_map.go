@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"sync"
+)
+
+// builtinTokenKeys token keys DepositRecorded tokenKey hashes are resolved against without configuration
+var builtinTokenKeys = []string{"USDT", "USDC", "DAI", "WETH", "WBTC", "ETH", "BNB", "TRX", "MATIC"}
+
+// tokenKeyHashMap normalized keccak256(tokenKey) -> tokenKey for the built-in keys
+var (
+	tokenKeyHashMap     map[string]string
+	tokenKeyHashMapOnce sync.Once
+)
+
+// InitTokenKeyHashMap precomputes the hashes of the built-in token keys (safe to call repeatedly)
+func InitTokenKeyHashMap() {
+	tokenKeyHashMapOnce.Do(func() {
+		hashes := make(map[string]string, len(builtinTokenKeys))
+		for _, key := range builtinTokenKeys {
+			hashes[normalizeTokenKeyHash(TokenKeyHash(key))] = key
+		}
+		tokenKeyHashMap = hashes
+	})
+}
+
+// GetTokenKeyFromHash converts a tokenKey hash (indexed string topic) back to the built-in key
+// Returns the hash unchanged when no built-in key hashes to it.
+func GetTokenKeyFromHash(hash string) string {
+	InitTokenKeyHashMap()
+	if key, ok := tokenKeyHashMap[normalizeTokenKeyHash(hash)]; ok {
+		return key
+	}
+	return hash
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// extraTokenKeys token keys registered at runtime (tokens.tokenKeys), normalized hash -> key
+var extraTokenKeys = struct {
+	sync.RWMutex
+	byHash map[string]string
+}{byHash: make(map[string]string)}
+
+// TokenKeyHash returns keccak256(tokenKey) as 0x-prefixed hex, the value of the indexed tokenKey event topic
+func TokenKeyHash(tokenKey string) string {
+	return crypto.Keccak256Hash([]byte(tokenKey)).Hex()
+}
+
+// RegisterTokenKeys adds token keys on top of the built-in tokenKey hash map (idempotent)
+func RegisterTokenKeys(tokenKeys []string) {
+	extraTokenKeys.Lock()
+	defer extraTokenKeys.Unlock()
+	for _, key := range tokenKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			extraTokenKeys.byHash[normalizeTokenKeyHash(TokenKeyHash(key))] = key
+		}
+	}
+}
+
+// LookupTokenKey converts a tokenKey hash back to its original key, ok=false when no known key hashes to it
+// On a miss the GetTokenKeyFromHash result (typically the hash itself) is returned, so callers can keep
+// their current behavior and only add reporting.
+func LookupTokenKey(hash string) (string, bool) {
+	InitTokenKeyHashMap()
+	normalized := normalizeTokenKeyHash(hash)
+
+	key := GetTokenKeyFromHash(hash)
+	if key != "" && normalizeTokenKeyHash(TokenKeyHash(key)) == normalized {
+		return key, true
+	}
+
+	extraTokenKeys.RLock()
+	extra, ok := extraTokenKeys.byHash[normalized]
+	extraTokenKeys.RUnlock()
+	if ok {
+		return extra, true
+	}
+	return key, false
+}
+
+// IsTokenKeyHash reports whether a value looks like a tokenKey hash (bytes32 hex) rather than a key
+func IsTokenKeyHash(value string) bool {
+	raw := strings.TrimPrefix(strings.ToLower(value), "0x")
+	if len(raw) != 64 {
+		return false
+	}
+	for _, c := range raw {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeTokenKeyHash lowercases a hash and strips the 0x prefix for comparison
+func normalizeTokenKeyHash(hash string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(hash)), "0x")
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+func TestLookupTokenKey(t *testing.T) {
+	if key, ok := LookupTokenKey(TokenKeyHash("USDT")); !ok || key != "USDT" {
+		t.Errorf("built-in USDT = %q, %v", key, ok)
+	}
+
+	custom := TokenKeyHash("TEST-TOKEN-KEY")
+	if key, ok := LookupTokenKey(custom); ok || key != custom {
+		t.Errorf("unregistered key = %q, %v, want the hash back and ok=false", key, ok)
+	}
+	RegisterTokenKeys([]string{" TEST-TOKEN-KEY "})
+	if key, ok := LookupTokenKey(custom); !ok || key != "TEST-TOKEN-KEY" {
+		t.Errorf("registered key = %q, %v", key, ok)
+	}
+}
+
+func TestIsTokenKeyHash(t *testing.T) {
+	if !IsTokenKeyHash(TokenKeyHash("USDC")) {
+		t.Error("keccak256 hash not recognized")
+	}
+	for _, value := range []string{"USDC", "0x1234", "0x" + string(make([]byte, 64))} {
+		if IsTokenKeyHash(value) {
+			t.Errorf("%q recognized as a hash", value)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+-- Drop status_transitions table
+DROP TABLE IF EXISTS status_transitions CASCADE;
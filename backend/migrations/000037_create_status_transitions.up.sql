@@ -0,0 +1,14 @@
+-- Create status_transitions table (status change audit log)
+CREATE TABLE IF NOT EXISTS status_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    trigger_context VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for status_transitions
+CREATE INDEX IF NOT EXISTS idx_status_transitions_entity ON status_transitions(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_status_transitions_created_at ON status_transitions(created_at);
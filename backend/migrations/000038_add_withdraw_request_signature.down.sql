@@ -0,0 +1,2 @@
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS signature_chain_id;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS signature;
@@ -0,0 +1,3 @@
+-- Store user signature on withdraw_requests so proof generation can be retried after a restart
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS signature TEXT;
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS signature_chain_id BIGINT;
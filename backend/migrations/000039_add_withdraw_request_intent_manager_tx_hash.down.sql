@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_withdraw_requests_intent_manager_tx_hash;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS intent_manager_tx_hash;
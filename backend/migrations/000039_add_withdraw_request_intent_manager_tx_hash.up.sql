@@ -0,0 +1,3 @@
+-- Track IntentManager transaction hash separately from Treasury payout tx hash
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS intent_manager_tx_hash VARCHAR(66);
+CREATE INDEX IF NOT EXISTS idx_withdraw_requests_intent_manager_tx_hash ON withdraw_requests(intent_manager_tx_hash);
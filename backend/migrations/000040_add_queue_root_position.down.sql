@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_queue_roots_chain_position;
+ALTER TABLE queue_roots DROP COLUMN IF EXISTS position;
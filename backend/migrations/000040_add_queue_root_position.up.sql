@@ -0,0 +1,19 @@
+-- Materialized position of each queue root in its chain's commitment queue (1 = first root after the all-zero root)
+-- "Commitments after root X" becomes a single indexed range query: position > X.position ORDER BY position
+ALTER TABLE queue_roots ADD COLUMN IF NOT EXISTS position BIGINT;
+CREATE INDEX IF NOT EXISTS idx_queue_roots_chain_position ON queue_roots(chain_id, position);
+
+-- Backfill positions for existing chains by walking the linked list from the all-zero root
+WITH RECURSIVE chain AS (
+    SELECT id, root, chain_id, 1::BIGINT AS position
+    FROM queue_roots
+    WHERE previous_root = '0x0000000000000000000000000000000000000000000000000000000000000000'
+    UNION ALL
+    SELECT q.id, q.root, q.chain_id, chain.position + 1
+    FROM queue_roots q
+    JOIN chain ON q.previous_root = chain.root AND q.chain_id = chain.chain_id
+)
+UPDATE queue_roots
+SET position = chain.position
+FROM chain
+WHERE queue_roots.id = chain.id;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_withdraw_requests_recipient_mismatch;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS recipient_mismatch;
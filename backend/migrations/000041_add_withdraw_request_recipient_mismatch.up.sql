@@ -0,0 +1,3 @@
+-- Flag requests whose WithdrawRequested on-chain recipient differs from the stored recipient
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS recipient_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_withdraw_requests_recipient_mismatch ON withdraw_requests(recipient_mismatch) WHERE recipient_mismatch;
@@ -0,0 +1,2 @@
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS version;
+ALTER TABLE checkbooks DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- Optimistic locking version, incremented on every update of the row
+ALTER TABLE checkbooks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+ALTER TABLE deposit_infos DROP COLUMN IF EXISTS allocatable_remaining;
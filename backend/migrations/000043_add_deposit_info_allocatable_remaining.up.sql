@@ -0,0 +1,14 @@
+-- Track how much of a deposit's allocatable_amount is not yet allocated to checks
+ALTER TABLE deposit_infos ADD COLUMN IF NOT EXISTS allocatable_remaining NUMERIC(78, 0) NOT NULL DEFAULT 0;
+
+-- Backfill: allocatable_amount minus the checks of the deposit's checkbook
+UPDATE deposit_infos d
+SET allocatable_remaining = GREATEST(
+    COALESCE(NULLIF(d.allocatable_amount, '')::numeric, 0) - COALESCE((
+        SELECT SUM(NULLIF(c.amount, '')::numeric)
+        FROM checks c
+        JOIN checkbooks cb ON cb.id = c.checkbook_id
+        WHERE cb.chain_id = d.slip44_chain_id AND cb.local_deposit_id = d.local_deposit_id
+    ), 0),
+    0
+);
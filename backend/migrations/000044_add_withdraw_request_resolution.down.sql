@@ -0,0 +1,3 @@
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS resolution_note;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS resolved_by;
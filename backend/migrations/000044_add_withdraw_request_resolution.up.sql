@@ -0,0 +1,4 @@
+-- Who resolved a manually_resolved WithdrawRequest (admin username or on-chain resolver) and why
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS resolved_by VARCHAR(100);
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS resolution_note TEXT;
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- ZKVM message language requested at creation, reused when proof generation is retried
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS proof_lang SMALLINT NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Soft-deleted rows must be removed before the full unique index can be restored
+DELETE FROM withdraw_requests WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_withdraw_requests_withdraw_nullifier;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_withdraw_requests_withdraw_nullifier ON withdraw_requests(withdraw_nullifier);
+DROP INDEX IF EXISTS idx_withdraw_requests_deleted_at;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS deleted_at;
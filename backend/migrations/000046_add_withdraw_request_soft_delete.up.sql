@@ -0,0 +1,7 @@
+-- Soft delete: a request replaced by CreateWithdrawRequest (same nullifier) is kept for audits
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_withdraw_requests_deleted_at ON withdraw_requests(deleted_at);
+
+-- withdraw_nullifier stays unique among non-deleted requests only
+DROP INDEX IF EXISTS idx_withdraw_requests_withdraw_nullifier;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_withdraw_requests_withdraw_nullifier ON withdraw_requests(withdraw_nullifier) WHERE deleted_at IS NULL;
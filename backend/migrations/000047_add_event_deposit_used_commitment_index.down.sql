@@ -0,0 +1,2 @@
+-- The index is also declared on the model (gorm:"index"), AutoMigrate recreates it
+DROP INDEX IF EXISTS idx_event_deposit_useds_commitment;
@@ -0,0 +1,2 @@
+-- CommitmentRootUpdated processing looks up DepositUsed events by commitment
+CREATE INDEX IF NOT EXISTS idx_event_deposit_useds_commitment ON event_deposit_useds(commitment);
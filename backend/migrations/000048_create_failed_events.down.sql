@@ -0,0 +1,2 @@
+-- Drop failed_events table
+DROP TABLE IF EXISTS failed_events;
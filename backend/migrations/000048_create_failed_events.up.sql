@@ -0,0 +1,19 @@
+-- Create failed_events table (dead-lettered NATS events, replayed with cmd/replay-failed-events)
+CREATE TABLE IF NOT EXISTS failed_events (
+    id BIGSERIAL PRIMARY KEY,
+    message_key VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    replayed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for failed_events
+CREATE UNIQUE INDEX IF NOT EXISTS idx_failed_events_message_key ON failed_events(message_key);
+CREATE INDEX IF NOT EXISTS idx_failed_events_event_type ON failed_events(event_type);
+CREATE INDEX IF NOT EXISTS idx_failed_events_status ON failed_events(status);
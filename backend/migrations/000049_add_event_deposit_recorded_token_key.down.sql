@@ -0,0 +1 @@
+ALTER TABLE event_deposit_recordeds DROP COLUMN IF EXISTS token_key;
@@ -0,0 +1,2 @@
+-- Resolved token key of the DepositRecorded event, lets a late DepositReceived backfill checkbooks.token_key
+ALTER TABLE event_deposit_recordeds ADD COLUMN IF NOT EXISTS token_key VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS execute_gas_price;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS execute_gas_used;
@@ -0,0 +1,3 @@
+-- Actual gas cost of the executeWithdraw transaction, taken from its receipt (accounting)
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS execute_gas_used BIGINT;
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS execute_gas_price VARCHAR(78);
@@ -0,0 +1,2 @@
+ALTER TABLE checkbooks ALTER COLUMN gross_amount DROP NOT NULL;
+ALTER TABLE checkbooks ALTER COLUMN gross_amount DROP DEFAULT;
@@ -0,0 +1,4 @@
+-- Checkbook gross_amount is never empty: backfill missing values with '0' and enforce it
+UPDATE checkbooks SET gross_amount = '0' WHERE gross_amount IS NULL OR gross_amount = '';
+ALTER TABLE checkbooks ALTER COLUMN gross_amount SET DEFAULT '0';
+ALTER TABLE checkbooks ALTER COLUMN gross_amount SET NOT NULL;
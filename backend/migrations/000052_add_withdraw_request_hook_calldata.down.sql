@@ -0,0 +1 @@
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS hook_calldata;
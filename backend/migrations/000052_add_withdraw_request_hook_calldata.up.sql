@@ -0,0 +1,2 @@
+-- Stage 4 hook calldata from the withdraw Intent (0x hex), executed via IntentManager after payout
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS hook_calldata TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_withdraw_requests_idempotency_key;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS idempotency_key;
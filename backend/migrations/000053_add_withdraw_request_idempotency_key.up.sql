@@ -0,0 +1,3 @@
+-- Client idempotency key: a retried CreateWithdrawRequest with the same key returns the existing request
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(128);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_withdraw_requests_idempotency_key ON withdraw_requests(idempotency_key) WHERE deleted_at IS NULL;
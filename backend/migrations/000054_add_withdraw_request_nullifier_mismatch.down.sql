@@ -0,0 +1 @@
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS nullifier_mismatch;
@@ -0,0 +1,2 @@
+-- Set when the nullifiers returned by ZKVM differ from the allocations' nullifiers (monitoring, strict_nullifier_check)
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS nullifier_mismatch BOOLEAN NOT NULL DEFAULT FALSE;
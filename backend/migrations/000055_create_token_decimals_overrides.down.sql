@@ -0,0 +1,2 @@
+-- Drop token_decimals_overrides table
+DROP TABLE IF EXISTS token_decimals_overrides;
@@ -0,0 +1,13 @@
+-- Create token_decimals_overrides table (on-chain token decimals written by cmd/verify-token-decimals)
+CREATE TABLE IF NOT EXISTS token_decimals_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    chain_id INTEGER NOT NULL,
+    token_address VARCHAR(66) NOT NULL,
+    decimals INTEGER NOT NULL,
+    configured_decimals INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One override per token per chain
+CREATE UNIQUE INDEX IF NOT EXISTS idx_token_decimals_overrides_chain_token ON token_decimals_overrides(chain_id, token_address);
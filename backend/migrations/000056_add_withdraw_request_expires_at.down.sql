@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_withdraw_requests_expires_at;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS expires_at;
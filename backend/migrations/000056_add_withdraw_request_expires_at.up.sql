@@ -0,0 +1,3 @@
+-- Deadline for a withdraw request to get its proof, past it the request is cancelled and its allocations released
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_withdraw_requests_expires_at ON withdraw_requests(expires_at);
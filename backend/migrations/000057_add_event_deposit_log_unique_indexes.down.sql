@@ -0,0 +1,4 @@
+-- The indexes are also declared on the models (gorm:"uniqueIndex"), AutoMigrate recreates them
+DROP INDEX IF EXISTS idx_event_deposit_receiveds_chain_tx_log;
+DROP INDEX IF EXISTS idx_event_deposit_recordeds_chain_tx_log;
+DROP INDEX IF EXISTS idx_event_deposit_useds_chain_tx_log;
@@ -0,0 +1,14 @@
+-- Deposit event inserts upsert on (chain_id, transaction_hash, log_index), which needs a unique index.
+-- Drop duplicate rows left by concurrent inserts first, keeping the oldest row of each log.
+DELETE FROM event_deposit_receiveds a USING event_deposit_receiveds b
+WHERE a.chain_id = b.chain_id AND a.transaction_hash = b.transaction_hash AND a.log_index = b.log_index AND a.id > b.id;
+
+DELETE FROM event_deposit_recordeds a USING event_deposit_recordeds b
+WHERE a.chain_id = b.chain_id AND a.transaction_hash = b.transaction_hash AND a.log_index = b.log_index AND a.id > b.id;
+
+DELETE FROM event_deposit_useds a USING event_deposit_useds b
+WHERE a.chain_id = b.chain_id AND a.transaction_hash = b.transaction_hash AND a.log_index = b.log_index AND a.id > b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deposit_receiveds_chain_tx_log ON event_deposit_receiveds(chain_id, transaction_hash, log_index);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deposit_recordeds_chain_tx_log ON event_deposit_recordeds(chain_id, transaction_hash, log_index);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_deposit_useds_chain_tx_log ON event_deposit_useds(chain_id, transaction_hash, log_index);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_withdraw_requests_payout_needs_manual_review;
+ALTER TABLE withdraw_requests DROP COLUMN IF EXISTS payout_needs_manual_review;
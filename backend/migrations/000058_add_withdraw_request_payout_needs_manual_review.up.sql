@@ -0,0 +1,3 @@
+-- Flag requests whose WithdrawExecuted event carried no transaction hash (payout left uncompleted for review)
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS payout_needs_manual_review BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_withdraw_requests_payout_needs_manual_review ON withdraw_requests(payout_needs_manual_review) WHERE payout_needs_manual_review;
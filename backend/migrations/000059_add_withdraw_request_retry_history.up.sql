@@ -0,0 +1,2 @@
+-- Audit trail of payout / hook / fallback retries and failures (JSON array, appended in place)
+ALTER TABLE withdraw_requests ADD COLUMN IF NOT EXISTS retry_history JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_withdraw_requests_owner_idempotency_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_withdraw_requests_idempotency_key ON withdraw_requests(idempotency_key) WHERE deleted_at IS NULL;
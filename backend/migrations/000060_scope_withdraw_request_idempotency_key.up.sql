@@ -0,0 +1,3 @@
+-- Idempotency keys are unique per owner: different owners may pick the same key
+DROP INDEX IF EXISTS idx_withdraw_requests_idempotency_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_withdraw_requests_owner_idempotency_key ON withdraw_requests(owner_chain_id, owner_data, idempotency_key) WHERE deleted_at IS NULL AND idempotency_key IS NOT NULL;